@@ -0,0 +1,155 @@
+package glplus
+
+// Backend is the GL API that every backend (desktop, software, WebGL and
+// mobile) implements with the same names and signatures, following the
+// WebGL 1 / OpenGL ES 2 naming. Each implementation checks it with a
+// compile-time assertion, and TestBackendConformance checks that no backend
+// has a GL method the others lack.
+type Backend interface {
+	GetError() int
+	Flush()
+	Finish()
+	GetSupportedExtensions() []string
+
+	// state
+	Enable(flag int)
+	Disable(flag int)
+	IsEnabled(flag int) bool
+	BlendColor(r, g, b, a float32)
+	BlendFunc(src, dst int)
+	BlendFuncSeparate(srcRGB, dstRGB, srcAlpha, dstAlpha int)
+	BlendEquation(mode int)
+	BlendEquationSeparate(modeRGB, modeAlpha int)
+	ClearColor(r, g, b, a float32)
+	ClearDepth(depth float32)
+	ClearStencil(s int)
+	Clear(flags int)
+	ColorMask(r, g, b, a bool)
+	CullFace(mode int)
+	FrontFace(mode int)
+	DepthFunc(fn int)
+	DepthMask(flag bool)
+	DepthRange(zNear, zFar float32)
+	LineWidth(width float32)
+	PixelStorei(pname, param int)
+	PolygonOffset(factor, units float32)
+	SampleCoverage(value float32, invert bool)
+	Scissor(x, y, width, height int)
+	StencilFunc(fn, ref, mask int)
+	StencilFuncSeparate(face, fn, ref, mask int)
+	Viewport(x, y, width, height int)
+
+	// programs and shaders
+	CreateProgram() *Program
+	DeleteProgram(program *Program)
+	IsProgram(program *Program) bool
+	AttachShader(program *Program, shader *Shader)
+	DetachShader(program *Program, shader *Shader)
+	GetAttachedShaders(program *Program) []*Shader
+	BindAttribLocation(program *Program, index int, name string)
+	LinkProgram(program *Program)
+	ValidateProgram(program *Program)
+	UseProgram(program *Program)
+	GetProgramInfoLog(program *Program) string
+	GetProgramParameterb(program *Program, pname int) bool
+	GetProgramParameteri(program *Program, pname int) int
+	GetActiveAttrib(program *Program, index int) (name string, size, typ int)
+	GetActiveUniform(program *Program, index int) (name string, size, typ int)
+	GetAttribLocation(program *Program, name string) int
+	GetUniformLocation(program *Program, name string) *UniformLocation
+	CreateShader(typ int) *Shader
+	DeleteShader(shader *Shader)
+	IsShader(shader *Shader) bool
+	ShaderSource(shader *Shader, source string)
+	GetShaderSource(shader *Shader) string
+	CompileShader(shader *Shader)
+	GetShaderiv(shader *Shader, pname uint32) bool
+	GetShaderParameterb(shader *Shader, pname int) bool
+	GetShaderParameteri(shader *Shader, pname int) int
+	GetShaderInfoLog(shader *Shader) string
+
+	// uniforms
+	Uniform1f(location *UniformLocation, x float32)
+	Uniform1i(location *UniformLocation, x int)
+	Uniform2f(location *UniformLocation, x, y float32)
+	Uniform2i(location *UniformLocation, x, y int)
+	Uniform3f(location *UniformLocation, x, y, z float32)
+	Uniform3i(location *UniformLocation, x, y, z int)
+	Uniform4f(location *UniformLocation, x, y, z, w float32)
+	Uniform4i(location *UniformLocation, x, y, z, w int)
+	UniformMatrix2fv(location *UniformLocation, transpose bool, value []float32)
+	UniformMatrix3fv(location *UniformLocation, transpose bool, value []float32)
+	UniformMatrix4fv(location *UniformLocation, transpose bool, value []float32)
+
+	// buffers and vertex arrays
+	CreateBuffer() *Buffer
+	DeleteBuffer(buffer *Buffer)
+	IsBuffer(buffer *Buffer) bool
+	BindBuffer(target int, buffer *Buffer)
+	BufferData(target int, data interface{}, usage int)
+	BufferSubData(target int, offset int, data interface{})
+	GetBufferParameter(target, pname int) int
+	CreateVertexArray() *VertexArray
+	DeleteVertexArray(vao *VertexArray)
+	BindVertexArray(vao *VertexArray)
+	EnableVertexAttribArray(index int)
+	DisableVertexAttribArray(index int)
+	VertexAttribPointer(index, size, typ int, normal bool, stride int, offset int)
+	GetVertexAttribOffset(index, pname int) int
+
+	// uniform buffers, when Caps.UniformBuffers
+	BindBufferBase(target, index int, buffer *Buffer)
+	GetUniformBlockIndex(program *Program, name string) int
+	UniformBlockBinding(program *Program, blockIndex, binding int)
+
+	// program binaries, when Caps.ProgramBinary
+	GetProgramBinary(program *Program) (binary []byte, format int)
+	ProgramBinary(program *Program, format int, binary []byte)
+	ProgramParameteri(program *Program, pname, value int)
+
+	// instanced draws, when Caps.Instancing
+	VertexAttribDivisor(index, divisor int)
+	DrawArraysInstanced(mode, first, count, instances int)
+	DrawElementsInstanced(mode, count, typ, offset, instances int)
+
+	// compute and tessellation, when Caps.ComputeShaders and
+	// Caps.TessellationShaders
+	DispatchCompute(x, y, z int)
+	MemoryBarrier(barriers int)
+	PatchParameteri(pname, value int)
+
+	// textures
+	CreateTexture() *Texture
+	DeleteTexture(texture *Texture)
+	IsTexture(texture *Texture) bool
+	ActiveTexture(texture int)
+	BindTexture(target int, texture *Texture)
+	TexImage2D(target, level, internalFormat, width, height, format, kind int, data interface{})
+	TexSubImage2D(target, level, xoffset, yoffset, width, height, format, kind int, data interface{})
+	CopyTexImage2D(target, level, internalFormat, x, y, width, height, border int)
+	CopyTexSubImage2D(target, level, xoffset, yoffset, x, y, width, height int)
+	TexParameteri(target int, pname int, param int)
+	GenerateMipmap(target int)
+
+	// framebuffers
+	CreateFramebuffer() *FrameBuffer
+	DeleteFramebuffer(framebuffer *FrameBuffer)
+	IsFramebuffer(framebuffer *FrameBuffer) bool
+	BindFramebuffer(target int, framebuffer *FrameBuffer)
+	CheckFramebufferStatus(target int) int
+	FramebufferTexture2D(target, attachment, textarget int, texture *Texture, level int)
+	FramebufferRenderbuffer(target, attachment, renderbuffertarget int, renderbuffer *RenderBuffer)
+	GetFramebufferAttachmentParameter(target, attachment, pname int) int
+	CreateRenderbuffer() *RenderBuffer
+	DeleteRenderbuffer(renderbuffer *RenderBuffer)
+	IsRenderbuffer(renderbuffer *RenderBuffer) bool
+	BindRenderbuffer(target int, renderbuffer *RenderBuffer)
+	RenderbufferStorage(target, internalFormat, width, height int)
+	GetRenderbufferParameter(target, pname int) int
+	// ReadPixels reads into a []uint8 or a []float32 depending on typ.
+	ReadPixels(x, y, width, height, format, typ int, pixels interface{})
+
+	// drawing
+	DrawArrays(mode, first, count int)
+	DrawElements(mode, count, typ, offset int)
+}
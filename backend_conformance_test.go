@@ -0,0 +1,238 @@
+package glplus
+
+import (
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// sPlatformMethods are the Context methods that exist on purpose on some
+// platforms only. Anything else missing from a platform fails
+// TestBackendConformance.
+var sPlatformMethods = map[string]string{
+	// native: the desktop and software backends
+	"Version":         "native: the backend name of traces",
+	"QueryCaps":       "native: webgl and mobile query Caps at creation",
+	"Ptr":             "native: go-gl pointers",
+	"DrawBuffer":      "native: no draw buffer selection in GLES 2",
+	"ReadBuffer":      "native: no read buffer selection in GLES 2",
+	"StartTrace":      "native: traces record the Context wrappers",
+	"StopTrace":       "native",
+	"EnableDebug":     "native: KHR_debug or GetError after every call",
+	"DisableDebug":    "native",
+	"DebugError":      "native",
+	"InvalidateState": "native: the state shadow",
+	"StateStats":      "native",
+	"ResetStateStats": "native",
+	"NewRenderTarget": "native: render targets use DrawBuffer and ReadBuffer",
+
+	// webgl and mobile
+	"IsContextLost": "the browser and the app lifecycle lose contexts",
+
+	// webgl: the JavaScript API
+	"GetContextAttributes": "webgl: canvas attributes",
+	"GetExtension":         "webgl and mobile: extension objects",
+	"GetParameter":         "webgl: returns a JavaScript value",
+	"GetShaderParameter":   "webgl: returns a JavaScript value",
+	"GetTexParameter":      "webgl: returns a JavaScript value",
+	"GetUniform":           "webgl: returns a JavaScript value",
+	"GetVertexAttrib":      "webgl: returns a JavaScript value",
+
+	// mobile: stubs of the webgl ones
+	"GetTexParameterfv": "mobile: not implemented",
+	"GetUniformfv":      "mobile: not implemented",
+	"GetVertexAttribfv": "mobile: not implemented",
+}
+
+// sPlatformSignatures are the methods whose signature differs on purpose.
+var sPlatformSignatures = map[string]string{
+	"RestoreContext": "mobile: takes the new DrawContext",
+}
+
+type conformancePlatform struct {
+	name string
+	ctx  build.Context
+}
+
+func conformancePlatforms() []conformancePlatform {
+	desktop := build.Default
+	desktop.GOOS, desktop.BuildTags = "linux", nil
+	webgl := desktop
+	webgl.BuildTags = []string{"netgo"}
+	mobile := desktop
+	mobile.GOOS = "android"
+	return []conformancePlatform{{"desktop", desktop}, {"webgl", webgl}, {"mobile", mobile}}
+}
+
+// packageDecls holds the types and the methods of the files of a platform.
+type packageDecls struct {
+	types   map[string]ast.Expr
+	methods map[string]map[string]*ast.FuncType
+}
+
+func parsePlatform(t *testing.T, ctx build.Context) *packageDecls {
+	infos, err := ioutil.ReadDir(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	decls := &packageDecls{types: make(map[string]ast.Expr), methods: make(map[string]map[string]*ast.FuncType)}
+	fset := token.NewFileSet()
+	for _, info := range infos {
+		name := info.Name()
+		if !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		if ok, err := ctx.MatchFile(".", name); err != nil {
+			t.Fatal(err)
+		} else if !ok {
+			continue
+		}
+		f, err := parser.ParseFile(fset, name, nil, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, decl := range f.Decls {
+			switch d := decl.(type) {
+			case *ast.GenDecl:
+				for _, spec := range d.Specs {
+					if ts, ok := spec.(*ast.TypeSpec); ok {
+						decls.types[ts.Name.Name] = ts.Type
+					}
+				}
+			case *ast.FuncDecl:
+				if d.Recv == nil || !d.Name.IsExported() {
+					continue
+				}
+				recv := d.Recv.List[0].Type
+				if star, ok := recv.(*ast.StarExpr); ok {
+					recv = star.X
+				}
+				ident, ok := recv.(*ast.Ident)
+				if !ok {
+					continue
+				}
+				if decls.methods[ident.Name] == nil {
+					decls.methods[ident.Name] = make(map[string]*ast.FuncType)
+				}
+				decls.methods[ident.Name][d.Name.Name] = d.Type
+			}
+		}
+	}
+	return decls
+}
+
+// methodSet returns the exported methods of a type of the package, with the
+// ones promoted from its embedded fields of the package.
+func (decls *packageDecls) methodSet(name string) map[string]*ast.FuncType {
+	set := make(map[string]*ast.FuncType)
+	var add func(name string)
+	add = func(name string) {
+		for m, typ := range decls.methods[name] {
+			if _, ok := set[m]; !ok {
+				set[m] = typ
+			}
+		}
+		switch typ := decls.types[name].(type) {
+		case *ast.StructType:
+			for _, field := range typ.Fields.List {
+				if len(field.Names) > 0 {
+					continue
+				}
+				embedded := field.Type
+				if star, ok := embedded.(*ast.StarExpr); ok {
+					embedded = star.X
+				}
+				if ident, ok := embedded.(*ast.Ident); ok {
+					add(ident.Name)
+				}
+			}
+		case *ast.InterfaceType:
+			for _, field := range typ.Methods.List {
+				if len(field.Names) == 0 {
+					if ident, ok := field.Type.(*ast.Ident); ok {
+						add(ident.Name)
+					}
+					continue
+				}
+				if _, ok := set[field.Names[0].Name]; !ok && field.Names[0].IsExported() {
+					set[field.Names[0].Name] = field.Type.(*ast.FuncType)
+				}
+			}
+		}
+	}
+	add(name)
+	return set
+}
+
+// signature prints the parameter and result types, without the names.
+func signature(fn *ast.FuncType) string {
+	list := func(fields *ast.FieldList) string {
+		if fields == nil {
+			return ""
+		}
+		var exprs []string
+		for _, field := range fields.List {
+			n := len(field.Names)
+			if n == 0 {
+				n = 1
+			}
+			for i := 0; i < n; i++ {
+				exprs = append(exprs, types.ExprString(field.Type))
+			}
+		}
+		return strings.Join(exprs, ", ")
+	}
+	return "(" + list(fn.Params) + ") (" + list(fn.Results) + ")"
+}
+
+func TestBackendConformance(t *testing.T) {
+	platforms := conformancePlatforms()
+	sets := make([]map[string]*ast.FuncType, len(platforms))
+	all := make(map[string]bool)
+	for i, p := range platforms {
+		sets[i] = parsePlatform(t, p.ctx).methodSet("Context")
+		if len(sets[i]) == 0 {
+			t.Fatalf("%s: no Context methods", p.name)
+		}
+		for m := range sets[i] {
+			all[m] = true
+		}
+	}
+	var names []string
+	for m := range all {
+		names = append(names, m)
+	}
+	sort.Strings(names)
+
+	for _, m := range names {
+		var have, lack []string
+		for i, p := range platforms {
+			if _, ok := sets[i][m]; ok {
+				have = append(have, p.name)
+			} else {
+				lack = append(lack, p.name)
+			}
+		}
+		if len(lack) > 0 {
+			if _, ok := sPlatformMethods[m]; !ok {
+				t.Errorf("%s: %s have it, %s lack it", m, strings.Join(have, " and "), strings.Join(lack, " and "))
+			}
+			continue
+		}
+		if _, ok := sPlatformSignatures[m]; ok {
+			continue
+		}
+		want := signature(sets[0][m])
+		for i, p := range platforms[1:] {
+			if got := signature(sets[i+1][m]); got != want {
+				t.Errorf("%s: %s%s on %s, %s%s on %s", m, m, want, platforms[0].name, m, got, p.name)
+			}
+		}
+	}
+}
@@ -0,0 +1,43 @@
+//+build !netgo,!android
+
+package glplus
+
+import "unsafe"
+
+// Texture one day my become gl. from "engo.io/gl"
+type Texture struct{ uint32 }
+
+// Buffer ...
+type Buffer struct{ uint32 }
+
+// FrameBuffer ...
+type FrameBuffer struct{ uint32 }
+
+// RenderBuffer ...
+type RenderBuffer struct{ uint32 }
+
+// Program ...
+type Program struct{ uint32 }
+
+// UniformLocation ...
+type UniformLocation struct{ int32 }
+
+// Shader ...
+type Shader struct{ uint32 }
+
+// VertexArray ...
+type VertexArray struct{ uint32 }
+
+// NativeBackend is a Backend running in the Go process, the desktop one and
+// the software one. It adds what only a desktop GL has.
+type NativeBackend interface {
+	Backend
+
+	Version() string
+	// QueryCaps returns the limits and the features of the backend.
+	QueryCaps() Caps
+	Ptr(data interface{}) unsafe.Pointer
+
+	DrawBuffer(buf int)
+	ReadBuffer(src int)
+}
@@ -0,0 +1,108 @@
+package glplus
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// fakeBackend records the calls it gets. The embedded interface is nil,
+// calls it does not implement panic.
+type fakeBackend struct {
+	NativeBackend
+
+	calls   []string
+	next    uint32
+	attribs map[string]int
+}
+
+func (f *fakeBackend) record(method string, args ...interface{}) {
+	f.calls = append(f.calls, fmt.Sprintf("%s%v", method, args))
+}
+
+func (f *fakeBackend) handle() uint32 {
+	f.next++
+	return f.next
+}
+
+func (f *fakeBackend) QueryCaps() Caps {
+	return Caps{MaxTextureSize: 4096, MaxVertexAttribs: 16, Uint32Indices: true, VertexArrays: true}
+}
+
+func (f *fakeBackend) CreateProgram() *Program          { return &Program{f.handle()} }
+func (f *fakeBackend) DeleteProgram(program *Program)   {}
+func (f *fakeBackend) CreateShader(typ int) *Shader     { return &Shader{f.handle()} }
+func (f *fakeBackend) ShaderSource(*Shader, string)     {}
+func (f *fakeBackend) CompileShader(*Shader)            {}
+func (f *fakeBackend) GetShaderiv(*Shader, uint32) bool { return true }
+func (f *fakeBackend) AttachShader(*Program, *Shader)   {}
+func (f *fakeBackend) LinkProgram(*Program)             { f.record("LinkProgram") }
+func (f *fakeBackend) GetProgramParameterb(*Program, int) bool {
+	return true
+}
+func (f *fakeBackend) GetProgramParameteri(*Program, int) int { return 0 }
+func (f *fakeBackend) DeleteShader(*Shader)                   {}
+func (f *fakeBackend) UseProgram(program *Program) {
+	f.record("UseProgram")
+}
+func (f *fakeBackend) GetAttribLocation(program *Program, name string) int {
+	return f.attribs[name]
+}
+
+func (f *fakeBackend) CreateBuffer() *Buffer            { return &Buffer{f.handle()} }
+func (f *fakeBackend) DeleteBuffer(*Buffer)             { f.record("DeleteBuffer") }
+func (f *fakeBackend) BindBuffer(target int, b *Buffer) { f.record("BindBuffer", target, b != nil) }
+func (f *fakeBackend) BufferData(target int, data interface{}, usage int) {
+	f.record("BufferData", target, fmt.Sprintf("%T", data))
+}
+func (f *fakeBackend) CreateVertexArray() *VertexArray { return &VertexArray{f.handle()} }
+func (f *fakeBackend) DeleteVertexArray(*VertexArray)  { f.record("DeleteVertexArray") }
+func (f *fakeBackend) BindVertexArray(vao *VertexArray) {
+	f.record("BindVertexArray", vao != nil)
+}
+func (f *fakeBackend) EnableVertexAttribArray(index int) { f.record("EnableVertexAttribArray", index) }
+func (f *fakeBackend) DisableVertexAttribArray(index int) {
+	f.record("DisableVertexAttribArray", index)
+}
+func (f *fakeBackend) VertexAttribPointer(index, size, typ int, normal bool, stride int, offset int) {
+	f.record("VertexAttribPointer", index, size, stride, offset)
+}
+func (f *fakeBackend) DrawElements(mode, count, typ, offset int) {
+	f.record("DrawElements", mode, count, typ, offset)
+}
+
+func TestFakeBackend(t *testing.T) {
+	fake := &fakeBackend{attribs: map[string]int{"position": 3, "uvs": 5}}
+	saved := Gl
+	Gl = NewBackendContext(fake)
+	defer func() { Gl = saved }()
+
+	// the shaders only need to differ from the ones of the other tests
+	prog, err := LoadShaderProgram("// fake vertex shader", "// fake fragment shader", []string{"position", "uvs"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	vbo := NewVBOQuad(prog, 0, 0, 1, 1)
+	prog.UseProgram()
+	vbo.Bind(prog)
+	vbo.Draw()
+	vbo.Unbind(prog)
+	vbo.DeleteVBO()
+	prog.DeleteProgram()
+
+	calls := strings.Join(fake.calls, "\n")
+	for _, want := range []string{
+		fmt.Sprintf("BufferData[%d []float32]", Gl.ARRAY_BUFFER),
+		fmt.Sprintf("BufferData[%d []uint16]", Gl.ELEMENT_ARRAY_BUFFER),
+		"VertexAttribPointer[3 3 20 0]",
+		"VertexAttribPointer[5 2 20 12]",
+		"EnableVertexAttribArray[5]",
+		fmt.Sprintf("DrawElements[%d 6 %d 0]", Gl.TRIANGLES, Gl.UNSIGNED_SHORT),
+		"DeleteVertexArray[]",
+		"UseProgram[]",
+	} {
+		if !strings.Contains(calls, want) {
+			t.Errorf("missing %s in\n%s", want, calls)
+		}
+	}
+}
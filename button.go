@@ -93,47 +93,49 @@ func (b *ButtonProgram) Delete() {
 	}
 }
 
-var sButtonProgramSingleton *ButtonProgram
-
 // Button ...
 type Button struct {
 	Size image.Point
+	ctx  *Context
 }
 
 // Delete ...
 func (b *Button) Delete() {
-	if sButtonProgramSingleton.Decr() {
-		sButtonProgramSingleton.Delete()
-		sButtonProgramSingleton = nil
+	if b.ctx.objects.buttonProgram.Decr() {
+		b.ctx.objects.buttonProgram.Delete()
+		b.ctx.objects.buttonProgram = nil
 	}
 }
 
-// Draw ...
-func (b *Button) Draw(color [4]float32, bg [4]float32, animTime float32, mat mgl32.Mat3) (err error) {
-	program := sButtonProgramSingleton.program
-	vbo := sButtonProgramSingleton.vbo
+// Draw draws the button with the colors of its shader, scaled to its size
+// and transformed by mat, its frame moving with animTime.
+func (b *Button) Draw(animTime float32, mat mgl32.Mat3) (err error) {
+	program := b.ctx.objects.buttonProgram.program
+	vbo := b.ctx.objects.buttonProgram.vbo
 
 	program.UseProgram()
 	vbo.Bind(program)
 
 	var m = mat.Mul3(mgl32.Scale2D(float32(b.Size.X), float32(b.Size.Y)))
 
-	program.ProgramUniformMatrix3fv("ModelviewMatrix", m)
-	program.ProgramUniform4fv("color", color)
-	program.ProgramUniform4fv("bg", bg)
-	program.ProgramUniform1f("animTime", animTime/3)
+	if err = program.ProgramUniformMatrix3fv("ModelviewMatrix", m); err != nil {
+		return err
+	}
+	if err = program.ProgramUniform1f("animTime", animTime/3); err != nil {
+		return err
+	}
 
 	if err = program.ValidateProgram(); err != nil {
 		return err
 	}
 
-	Gl.Disable(Gl.DEPTH_TEST)
-	Gl.Enable(Gl.BLEND)
+	b.ctx.Disable(b.ctx.DEPTH_TEST)
+	b.ctx.Enable(b.ctx.BLEND)
 
 	vbo.Draw()
 
-	Gl.Enable(Gl.DEPTH_TEST)
-	Gl.Disable(Gl.BLEND)
+	b.ctx.Enable(b.ctx.DEPTH_TEST)
+	b.ctx.Disable(b.ctx.BLEND)
 
 	vbo.Unbind(program)
 	program.UnuseProgram()
@@ -141,30 +143,36 @@ func (b *Button) Draw(color [4]float32, bg [4]float32, animTime float32, mat mgl
 	return nil
 }
 
-// NewButton ...
+// NewButton creates a button on the default context Gl.
 func NewButton(size image.Point) (btn *Button, err error) {
-	if sButtonProgramSingleton == nil {
+	return Gl.NewButton(size)
+}
+
+// NewButton ...
+func (c *Context) NewButton(size image.Point) (btn *Button, err error) {
+	if c.objects.buttonProgram == nil {
 		var attribs = []string{
 			"position",
 			"uvs",
 		}
 		var err error
 		var program *GPProgram
-		if program, err = LoadShaderProgram(vertShaderButton, fragShaderButton, attribs); err != nil {
+		if program, err = c.LoadShaderProgram(vertShaderButton, fragShaderButton, attribs); err != nil {
 			return nil, err
 		}
-		sButtonProgramSingleton = &ButtonProgram{
+		c.objects.buttonProgram = &ButtonProgram{
 			vbo: NewVBOQuad(program, 0, 0, 1, 1),
 			ReleasingReferenceCount: NewReferenceCount(),
 			program:                 program,
 		}
 
 	} else {
-		sButtonProgramSingleton.Incr()
+		c.objects.buttonProgram.Incr()
 	}
 
 	var result = &Button{
 		Size: size,
+		ctx:  c,
 	}
 	return result, nil
 }
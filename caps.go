@@ -0,0 +1,123 @@
+package glplus
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aubonbeurre/glplus/glsl"
+)
+
+// Caps are the limits and the optional features of a context, queried once
+// when it is created.
+type Caps struct {
+	MaxTextureSize      int
+	MaxTextureUnits     int
+	MaxVertexAttribs    int
+	MaxRenderbufferSize int
+	// MaxSamples is 0 when multisampled renderbuffers are not available.
+	MaxSamples int
+	// MaxUniformBufferBindings is 0 without UniformBuffers.
+	MaxUniformBufferBindings int
+
+	// Driver is the vendor, renderer and version strings of the driver,
+	// which the program binaries are valid for.
+	Driver string
+
+	// Extensions is sorted.
+	Extensions []string
+
+	// GLSLVersion is 330 for "#version 330", 100 for GLSL ES 1.00 and so
+	// on, ES tells apart the OpenGL ES and WebGL dialects.
+	GLSLVersion int
+	ES          bool
+
+	VertexArrays  bool
+	FloatTextures bool
+	Instancing    bool
+	// Uint32Indices allows UNSIGNED_INT element arrays.
+	Uint32Indices bool
+	// UniformBuffers are core in OpenGL 3.1 and WebGL 2, the golang.org/x/mobile
+	// bindings lack them.
+	UniformBuffers bool
+	// ProgramBinary is set when the driver has at least one program binary
+	// format, for the ProgramBinaryCache.
+	ProgramBinary bool
+	// GeometryShaders, TessellationShaders and ComputeShaders are the stages
+	// of OpenGL 3.2, 4.0 and 4.3 a ProgramBuilder accepts beside the vertex
+	// and fragment ones. OpenGL ES 2 and WebGL have none of them.
+	GeometryShaders     bool
+	TessellationShaders bool
+	ComputeShaders      bool
+}
+
+// HasExtension ...
+func (caps *Caps) HasExtension(name string) bool {
+	i := sort.SearchStrings(caps.Extensions, name)
+	return i < len(caps.Extensions) && caps.Extensions[i] == name
+}
+
+// Dialect is the GLSL the shaders are preprocessed into.
+func (caps *Caps) Dialect() glsl.Dialect {
+	switch {
+	case caps.ES && caps.GLSLVersion >= 300:
+		return glsl.GLSLES300
+	case caps.ES:
+		return glsl.GLSLES100
+	}
+	return glsl.GLSL330
+}
+
+func driverString(vendor, renderer, version string) string {
+	return vendor + "; " + renderer + "; " + version
+}
+
+// setExtensions keeps the extensions and turns on the features they bring
+// to OpenGL ES 2 and WebGL 1.
+func (caps *Caps) setExtensions(extensions []string) {
+	caps.Extensions = append([]string(nil), extensions...)
+	sort.Strings(caps.Extensions)
+
+	caps.VertexArrays = caps.VertexArrays || caps.HasExtension("OES_vertex_array_object") ||
+		caps.HasExtension("GL_OES_vertex_array_object")
+	caps.FloatTextures = caps.FloatTextures || caps.HasExtension("OES_texture_float") ||
+		caps.HasExtension("GL_OES_texture_float")
+	caps.Instancing = caps.Instancing || caps.HasExtension("ANGLE_instanced_arrays") ||
+		caps.HasExtension("GL_EXT_instanced_arrays") || caps.HasExtension("GL_ANGLE_instanced_arrays")
+	caps.Uint32Indices = caps.Uint32Indices || caps.HasExtension("OES_element_index_uint") ||
+		caps.HasExtension("GL_OES_element_index_uint")
+}
+
+// parseGLSLVersion reads SHADING_LANGUAGE_VERSION: "4.10 NVIDIA", "OpenGL ES
+// GLSL ES 3.00" or "WebGL GLSL ES 1.0 (OpenGL ES GLSL ES 1.0 Chromium)".
+func parseGLSLVersion(s string) (version int, es bool) {
+	es = strings.Contains(s, "GLSL ES")
+	for _, field := range strings.Fields(s) {
+		dot := strings.IndexByte(field, '.')
+		if dot <= 0 {
+			continue
+		}
+		major, err := strconv.Atoi(field[:dot])
+		if err != nil {
+			continue
+		}
+		minor := field[dot+1:]
+		for i, r := range minor {
+			if r < '0' || r > '9' {
+				minor = minor[:i]
+				break
+			}
+		}
+		switch len(minor) {
+		case 0:
+			minor = "00"
+		case 1:
+			minor += "0"
+		default:
+			minor = minor[:2]
+		}
+		n, _ := strconv.Atoi(minor)
+		return major*100 + n, es
+	}
+	return 0, es
+}
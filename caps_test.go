@@ -0,0 +1,109 @@
+package glplus
+
+import (
+	"image"
+	"strings"
+	"testing"
+
+	"github.com/aubonbeurre/glplus/glsl"
+)
+
+func TestParseGLSLVersion(t *testing.T) {
+	for _, test := range []struct {
+		s       string
+		version int
+		es      bool
+	}{
+		{"4.10", 410, false},
+		{"4.60 NVIDIA", 460, false},
+		{"OpenGL ES GLSL ES 3.00", 300, true},
+		{"WebGL GLSL ES 1.0 (OpenGL ES GLSL ES 1.0 Chromium)", 100, true},
+		{"WebGL GLSL ES 3.00 (OpenGL ES GLSL ES 3.0 Chromium)", 300, true},
+		{"", 0, false},
+	} {
+		if version, es := parseGLSLVersion(test.s); version != test.version || es != test.es {
+			t.Errorf("%q: got %d %v", test.s, version, es)
+		}
+	}
+}
+
+func TestCapsDialect(t *testing.T) {
+	for _, test := range []struct {
+		caps    Caps
+		dialect glsl.Dialect
+	}{
+		{Caps{GLSLVersion: 410}, glsl.GLSL330},
+		{Caps{GLSLVersion: 100, ES: true}, glsl.GLSLES100},
+		{Caps{GLSLVersion: 300, ES: true}, glsl.GLSLES300},
+		{Caps{}, glsl.GLSL330},
+	} {
+		if dialect := test.caps.Dialect(); dialect != test.dialect {
+			t.Errorf("%+v: got %s", test.caps, dialect)
+		}
+	}
+}
+
+func TestCapsExtensions(t *testing.T) {
+	var caps Caps
+	caps.setExtensions([]string{"OES_texture_float", "ANGLE_instanced_arrays", "WEBGL_lose_context"})
+	if !caps.HasExtension("WEBGL_lose_context") || caps.HasExtension("OES_vertex_array_object") {
+		t.Errorf("extensions %v", caps.Extensions)
+	}
+	if !caps.FloatTextures || !caps.Instancing || caps.VertexArrays || caps.Uint32Indices {
+		t.Errorf("features %+v", caps)
+	}
+}
+
+func TestCapsLimits(t *testing.T) {
+	defer softContext(t)()
+
+	if Gl.Caps.MaxTextureSize != softMaxTextureSize || Gl.Caps.MaxVertexAttribs != softMaxAttribs || !Gl.Caps.Uint32Indices {
+		t.Fatalf("soft caps %+v", Gl.Caps)
+	}
+
+	Gl.Caps.MaxTextureSize = 4
+	if _, err := NewRGBATexture(image.NewRGBA(image.Rect(0, 0, 8, 2)), false, false); err == nil || !strings.Contains(err.Error(), "maximum texture size 4") {
+		t.Errorf("got %v", err)
+	}
+	if _, err := NewRenderTarget(image.Point{8, 8}, false); err == nil || !strings.Contains(err.Error(), "maximum texture size 4") {
+		t.Errorf("got %v", err)
+	}
+	Gl.Caps.MaxTextureSize = softMaxTextureSize
+
+	prog, err := LoadShaderProgram(sVertShaderRestore+"\n// caps", sFragShaderRestore, []string{"position", "uvs"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer prog.DeleteProgram()
+
+	Gl.Caps.Uint32Indices = false
+	small, err := NewVBO(prog, DefaultVBOOptions(), make([]float32, 15), []uint32{0, 1, 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer small.DeleteVBO()
+	if !small.isShort {
+		t.Error("16-bit indices expected")
+	}
+	if _, err := NewVBO(prog, DefaultVBOOptions(), make([]float32, 15), []uint32{0, 1, 70000}); err == nil || !strings.Contains(err.Error(), "OES_element_index_uint") {
+		t.Errorf("got %v", err)
+	}
+	if err := small.Update(make([]float32, 15), []uint32{0, 1, 70000}); err == nil || !strings.Contains(err.Error(), "OES_element_index_uint") {
+		t.Errorf("got %v", err)
+	}
+	if len(small.indices) != 3 || small.numElem != 3 || !small.isShort {
+		t.Errorf("the failed update changed the VBO: %v", small.indices)
+	}
+
+	// a restored context without the 32-bit indices of the lost one
+	Gl.Caps.Uint32Indices = true
+	large, err := NewVBO(prog, DefaultVBOOptions(), make([]float32, 15), []uint32{0, 1, 70000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer large.DeleteVBO()
+	Gl.Caps.Uint32Indices = false
+	if err := large.Restore(); err == nil || !strings.Contains(err.Error(), "OES_element_index_uint") {
+		t.Errorf("got %v", err)
+	}
+}
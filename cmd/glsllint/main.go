@@ -0,0 +1,145 @@
+// Command glsllint checks glplus shaders without a GPU: the sources are
+// translated to GLSL 3.30 and GLSL ES 1.00 and type checked, the attributes
+// and the uniforms are checked against them, the varyings of the stages
+// against each other.
+//
+// Usage:
+//
+//	glsllint [-attribs a,b] [-uniforms u,v] shader.vert shader.frag
+//	glsllint file.go|dir ...
+//
+// With Go files, or the ones of directories, it lints the shaders of their
+// LoadShaderProgram calls. The exit status is 1 when it finds problems.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/aubonbeurre/glplus/glsl"
+)
+
+func main() {
+	attribs := flag.String("attribs", "", "comma separated attributes of the program")
+	uniforms := flag.String("uniforms", "", "comma separated uniforms set from Go")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: glsllint [-attribs a,b] [-uniforms u,v] shader.vert shader.frag\n")
+		fmt.Fprintf(os.Stderr, "       glsllint file.go|dir ...\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var problems []glsl.Problem
+	if isShaders(flag.Args()) {
+		problems = lintShaders(flag.Arg(0), flag.Arg(1), split(*attribs), split(*uniforms))
+	} else {
+		for _, arg := range flag.Args() {
+			files, err := goFiles(arg)
+			if err != nil {
+				log.Fatal(err)
+			}
+			for _, file := range files {
+				found, err := glsl.LintGoFile(file, nil)
+				if err != nil {
+					log.Fatal(err)
+				}
+				problems = append(problems, found...)
+			}
+		}
+	}
+	for _, p := range problems {
+		fmt.Println(p)
+	}
+	if len(problems) > 0 {
+		os.Exit(1)
+	}
+}
+
+// isShaders tells whether the arguments are a vertex and a fragment shader
+// rather than Go files.
+func isShaders(args []string) bool {
+	if len(args) != 2 {
+		return false
+	}
+	for _, arg := range args {
+		if strings.HasSuffix(arg, ".go") {
+			return false
+		}
+		if fi, err := os.Stat(arg); err == nil && fi.IsDir() {
+			return false
+		}
+	}
+	return true
+}
+
+func lintShaders(vertPath, fragPath string, attribs, uniforms []string) []glsl.Problem {
+	vert, err := os.ReadFile(vertPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	frag, err := os.ReadFile(fragPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	problems := glsl.Lint(&glsl.LintProgram{
+		Vertex:   string(vert),
+		Fragment: string(frag),
+		Attribs:  attribs,
+		Uniforms: uniforms,
+		Includer: dirIncluder(filepath.Dir(vertPath)),
+	})
+	for i, p := range problems {
+		switch p.File {
+		case "vertex":
+			problems[i].File = vertPath
+		case "fragment":
+			problems[i].File = fragPath
+		}
+	}
+	return problems
+}
+
+// dirIncluder includes the files of a directory.
+type dirIncluder string
+
+func (dir dirIncluder) Include(name string) (string, error) {
+	b, err := os.ReadFile(filepath.Join(string(dir), filepath.FromSlash(name)))
+	return string(b), err
+}
+
+// goFiles lists a Go file, or the Go files of a directory but the tests.
+func goFiles(path string) ([]string, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !fi.IsDir() {
+		return []string{path}, nil
+	}
+	matches, err := filepath.Glob(filepath.Join(path, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, file := range matches {
+		if !strings.HasSuffix(file, "_test.go") {
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
+
+func split(list string) []string {
+	if list == "" {
+		return nil
+	}
+	return strings.Split(list, ",")
+}
@@ -0,0 +1,48 @@
+// Command gltrace prints a GL call trace recorded with Context.StartTrace.
+//
+// Usage:
+//
+//	gltrace [-stats] trace.jsonl
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/aubonbeurre/glplus"
+)
+
+func main() {
+	stats := flag.Bool("stats", false, "print the number of calls per method")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: gltrace [-stats] trace.jsonl\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	f, err := os.Open(flag.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+
+	trace, err := glplus.ReadTrace(f)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *stats {
+		for _, line := range trace.Stats() {
+			fmt.Println(line)
+		}
+		return
+	}
+	if err = trace.Dump(os.Stdout); err != nil {
+		log.Fatal(err)
+	}
+}
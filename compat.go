@@ -1,4 +1,4 @@
-//+build !netgo,!android
+//+build !netgo,!android,!soft
 
 package glplus
 
@@ -12,647 +12,175 @@ import (
 	"github.com/go-gl/gl/v4.1-core/gl"
 )
 
-// Texture one day my become gl. from "engo.io/gl"
-type Texture struct{ uint32 }
-
-// Buffer ...
-type Buffer struct{ uint32 }
-
-// FrameBuffer ...
-type FrameBuffer struct{ uint32 }
-
-// RenderBuffer ...
-type RenderBuffer struct{ uint32 }
-
-// Program ...
-type Program struct{ uint32 }
-
-// UniformLocation ...
-type UniformLocation struct{ int32 }
-
-// Shader ...
-type Shader struct{ uint32 }
-
-// VertexArray ...
-type VertexArray struct{ uint32 }
-
-type Context struct {
-	ARRAY_BUFFER                                 int
-	ARRAY_BUFFER_BINDING                         int
-	ATTACHED_SHADERS                             int
-	BACK                                         int
-	BLEND                                        int
-	BLEND_COLOR                                  int
-	BLEND_DST_ALPHA                              int
-	BLEND_DST_RGB                                int
-	BLEND_EQUATION                               int
-	BLEND_EQUATION_ALPHA                         int
-	BLEND_EQUATION_RGB                           int
-	BLEND_SRC_ALPHA                              int
-	BLEND_SRC_RGB                                int
-	BLUE_BITS                                    int
-	BOOL                                         int
-	BOOL_VEC2                                    int
-	BOOL_VEC3                                    int
-	BOOL_VEC4                                    int
-	BROWSER_DEFAULT_WEBGL                        int
-	BUFFER_SIZE                                  int
-	BUFFER_USAGE                                 int
-	BYTE                                         int
-	CCW                                          int
-	CLAMP_TO_EDGE                                int
-	CLAMP_TO_BORDER                              int
-	COLOR_ATTACHMENT0                            int
-	COLOR_BUFFER_BIT                             int
-	COLOR_CLEAR_VALUE                            int
-	COLOR_WRITEMASK                              int
-	COMPILE_STATUS                               uint32
-	COMPRESSED_TEXTURE_FORMATS                   int
-	CONSTANT_ALPHA                               int
-	CONSTANT_COLOR                               int
-	CONTEXT_LOST_WEBGL                           int
-	CULL_FACE                                    int
-	CULL_FACE_MODE                               int
-	CURRENT_PROGRAM                              int
-	CURRENT_VERTEX_ATTRIB                        int
-	CW                                           int
-	DECR                                         int
-	DECR_WRAP                                    int
-	DELETE_STATUS                                int
-	DEPTH_ATTACHMENT                             int
-	DEPTH_BITS                                   int
-	DEPTH_BUFFER_BIT                             int
-	DEPTH_CLEAR_VALUE                            int
-	DEPTH_COMPONENT                              int
-	DEPTH_COMPONENT16                            int
-	DEPTH_FUNC                                   int
-	DEPTH_RANGE                                  int
-	DEPTH_STENCIL                                int
-	DEPTH_STENCIL_ATTACHMENT                     int
-	DEPTH_TEST                                   int
-	DEPTH_WRITEMASK                              int
-	DITHER                                       int
-	DONT_CARE                                    int
-	DST_ALPHA                                    int
-	DST_COLOR                                    int
-	DYNAMIC_DRAW                                 int
-	ELEMENT_ARRAY_BUFFER                         int
-	ELEMENT_ARRAY_BUFFER_BINDING                 int
-	EQUAL                                        int
-	FASTEST                                      int
-	FLOAT                                        int
-	FLOAT_MAT2                                   int
-	FLOAT_MAT3                                   int
-	FLOAT_MAT4                                   int
-	FLOAT_VEC2                                   int
-	FLOAT_VEC3                                   int
-	FLOAT_VEC4                                   int
-	FRAGMENT_SHADER                              int
-	FRAMEBUFFER                                  int
-	FRAMEBUFFER_ATTACHMENT_OBJECT_NAME           int
-	FRAMEBUFFER_ATTACHMENT_OBJECT_TYPE           int
-	FRAMEBUFFER_ATTACHMENT_TEXTURE_CUBE_MAP_FACE int
-	FRAMEBUFFER_ATTACHMENT_TEXTURE_LEVEL         int
-	FRAMEBUFFER_BINDING                          int
-	FRAMEBUFFER_COMPLETE                         int
-	FRAMEBUFFER_INCOMPLETE_ATTACHMENT            int
-	FRAMEBUFFER_INCOMPLETE_DIMENSIONS            int
-	FRAMEBUFFER_INCOMPLETE_MISSING_ATTACHMENT    int
-	FRAMEBUFFER_INCOMPLETE_DRAW_BUFFER           int
-	FRAMEBUFFER_INCOMPLETE_READ_BUFFER           int
-	FRAMEBUFFER_UNSUPPORTED                      int
-	FRONT                                        int
-	FRONT_AND_BACK                               int
-	FRONT_FACE                                   int
-	FUNC_ADD                                     int
-	FUNC_REVERSE_SUBTRACT                        int
-	FUNC_SUBTRACT                                int
-	GENERATE_MIPMAP_HINT                         int
-	GEQUAL                                       int
-	GREATER                                      int
-	GREEN_BITS                                   int
-	HIGH_FLOAT                                   int
-	HIGH_INT                                     int
-	INCR                                         int
-	INCR_WRAP                                    int
-	INFO_LOG_LENGTH                              uint32
-	INT                                          int
-	INT_VEC2                                     int
-	INT_VEC3                                     int
-	INT_VEC4                                     int
-	INVALID_ENUM                                 int
-	INVALID_FRAMEBUFFER_OPERATION                int
-	INVALID_OPERATION                            int
-	INVALID_VALUE                                int
-	INVERT                                       int
-	KEEP                                         int
-	LEQUAL                                       int
-	LESS                                         int
-	LINEAR                                       int
-	LINEAR_MIPMAP_LINEAR                         int
-	LINEAR_MIPMAP_NEAREST                        int
-	LINES                                        int
-	LINE_LOOP                                    int
-	LINE_STRIP                                   int
-	LINE_WIDTH                                   int
-	LINK_STATUS                                  int
-	LOW_FLOAT                                    int
-	LOW_INT                                      int
-	LUMINANCE                                    int
-	LUMINANCE_ALPHA                              int
-	MAX_COMBINED_TEXTURE_IMAGE_UNITS             int
-	MAX_CUBE_MAP_TEXTURE_SIZE                    int
-	MAX_FRAGMENT_UNIFORM_VECTORS                 int
-	MAX_RENDERBUFFER_SIZE                        int
-	MAX_TEXTURE_IMAGE_UNITS                      int
-	MAX_TEXTURE_SIZE                             int
-	MAX_VARYING_VECTORS                          int
-	MAX_VERTEX_ATTRIBS                           int
-	MAX_VERTEX_TEXTURE_IMAGE_UNITS               int
-	MAX_VERTEX_UNIFORM_VECTORS                   int
-	MAX_VIEWPORT_DIMS                            int
-	MEDIUM_FLOAT                                 int
-	MEDIUM_INT                                   int
-	MIRRORED_REPEAT                              int
-	MULTISAMPLE                                  int
-	NEAREST                                      int
-	NEAREST_MIPMAP_LINEAR                        int
-	NEAREST_MIPMAP_NEAREST                       int
-	NEVER                                        int
-	NICEST                                       int
-	NONE                                         int
-	NOTEQUAL                                     int
-	NO_ERROR                                     int
-	NUM_COMPRESSED_TEXTURE_FORMATS               int
-	ONE                                          int
-	ONE_MINUS_CONSTANT_ALPHA                     int
-	ONE_MINUS_CONSTANT_COLOR                     int
-	ONE_MINUS_DST_ALPHA                          int
-	ONE_MINUS_DST_COLOR                          int
-	ONE_MINUS_SRC_ALPHA                          int
-	ONE_MINUS_SRC_COLOR                          int
-	OUT_OF_MEMORY                                int
-	PACK_ALIGNMENT                               int
-	POINTS                                       int
-	POLYGON_OFFSET_FACTOR                        int
-	POLYGON_OFFSET_FILL                          int
-	POLYGON_OFFSET_UNITS                         int
-	RED_BITS                                     int
-	RENDERBUFFER                                 int
-	RENDERBUFFER_ALPHA_SIZE                      int
-	RENDERBUFFER_BINDING                         int
-	RENDERBUFFER_BLUE_SIZE                       int
-	RENDERBUFFER_DEPTH_SIZE                      int
-	RENDERBUFFER_GREEN_SIZE                      int
-	RENDERBUFFER_HEIGHT                          int
-	RENDERBUFFER_INTERNAL_FORMAT                 int
-	RENDERBUFFER_RED_SIZE                        int
-	RENDERBUFFER_STENCIL_SIZE                    int
-	RENDERBUFFER_WIDTH                           int
-	RENDERER                                     int
-	REPEAT                                       int
-	REPLACE                                      int
-	RGB                                          int
-	RGB5_A1                                      int
-	RGB565                                       int
-	RGBA                                         int
-	RGBA32F                                      int
-	RGBA4                                        int
-	SAMPLER_2D                                   int
-	SAMPLER_CUBE                                 int
-	SAMPLES                                      int
-	SAMPLE_ALPHA_TO_COVERAGE                     int
-	SAMPLE_BUFFERS                               int
-	SAMPLE_COVERAGE                              int
-	SAMPLE_COVERAGE_INVERT                       int
-	SAMPLE_COVERAGE_VALUE                        int
-	SCISSOR_BOX                                  int
-	SCISSOR_TEST                                 int
-	SHADER_COMPILER                              int
-	SHADER_SOURCE_LENGTH                         int
-	SHADER_TYPE                                  int
-	SHADING_LANGUAGE_VERSION                     int
-	SHORT                                        int
-	SRC_ALPHA                                    int
-	SRC_ALPHA_SATURATE                           int
-	SRC_COLOR                                    int
-	STATIC_DRAW                                  int
-	STENCIL_ATTACHMENT                           int
-	STENCIL_BACK_FAIL                            int
-	STENCIL_BACK_FUNC                            int
-	STENCIL_BACK_PASS_DEPTH_FAIL                 int
-	STENCIL_BACK_PASS_DEPTH_PASS                 int
-	STENCIL_BACK_REF                             int
-	STENCIL_BACK_VALUE_MASK                      int
-	STENCIL_BACK_WRITEMASK                       int
-	STENCIL_BITS                                 int
-	STENCIL_BUFFER_BIT                           int
-	STENCIL_CLEAR_VALUE                          int
-	STENCIL_FAIL                                 int
-	STENCIL_FUNC                                 int
-	STENCIL_INDEX                                int
-	STENCIL_INDEX8                               int
-	STENCIL_PASS_DEPTH_FAIL                      int
-	STENCIL_PASS_DEPTH_PASS                      int
-	STENCIL_REF                                  int
-	STENCIL_TEST                                 int
-	STENCIL_VALUE_MASK                           int
-	STENCIL_WRITEMASK                            int
-	STREAM_DRAW                                  int
-	SUBPIXEL_BITS                                int
-	TEXTURE                                      int
-	TEXTURE0                                     int
-	TEXTURE1                                     int
-	TEXTURE2                                     int
-	TEXTURE3                                     int
-	TEXTURE4                                     int
-	TEXTURE5                                     int
-	TEXTURE6                                     int
-	TEXTURE7                                     int
-	TEXTURE8                                     int
-	TEXTURE9                                     int
-	TEXTURE10                                    int
-	TEXTURE11                                    int
-	TEXTURE12                                    int
-	TEXTURE13                                    int
-	TEXTURE14                                    int
-	TEXTURE15                                    int
-	TEXTURE16                                    int
-	TEXTURE17                                    int
-	TEXTURE18                                    int
-	TEXTURE19                                    int
-	TEXTURE20                                    int
-	TEXTURE21                                    int
-	TEXTURE22                                    int
-	TEXTURE23                                    int
-	TEXTURE24                                    int
-	TEXTURE25                                    int
-	TEXTURE26                                    int
-	TEXTURE27                                    int
-	TEXTURE28                                    int
-	TEXTURE29                                    int
-	TEXTURE30                                    int
-	TEXTURE31                                    int
-	TEXTURE_2D                                   int
-	TEXTURE_BINDING_2D                           int
-	TEXTURE_BINDING_CUBE_MAP                     int
-	TEXTURE_CUBE_MAP                             int
-	TEXTURE_CUBE_MAP_NEGATIVE_X                  int
-	TEXTURE_CUBE_MAP_NEGATIVE_Y                  int
-	TEXTURE_CUBE_MAP_NEGATIVE_Z                  int
-	TEXTURE_CUBE_MAP_POSITIVE_X                  int
-	TEXTURE_CUBE_MAP_POSITIVE_Y                  int
-	TEXTURE_CUBE_MAP_POSITIVE_Z                  int
-	TEXTURE_MAG_FILTER                           int
-	TEXTURE_MIN_FILTER                           int
-	TEXTURE_WRAP_S                               int
-	TEXTURE_WRAP_T                               int
-	TRIANGLES                                    int
-	TRIANGLE_FAN                                 int
-	TRIANGLE_STRIP                               int
-	UNPACK_ALIGNMENT                             int
-	UNPACK_COLORSPACE_CONVERSION_WEBGL           int
-	UNPACK_FLIP_Y_WEBGL                          int
-	UNPACK_PREMULTIPLY_ALPHA_WEBGL               int
-	UNSIGNED_BYTE                                int
-	UNSIGNED_INT                                 int
-	UNSIGNED_SHORT                               int
-	UNSIGNED_SHORT_4_4_4_4                       int
-	UNSIGNED_SHORT_5_5_5_1                       int
-	UNSIGNED_SHORT_5_6_5                         int
-	VALIDATE_STATUS                              int
-	VENDOR                                       int
-	VERSION                                      int
-	VERTEX_ATTRIB_ARRAY_BUFFER_BINDING           int
-	VERTEX_ATTRIB_ARRAY_ENABLED                  int
-	VERTEX_ATTRIB_ARRAY_NORMALIZED               int
-	VERTEX_ATTRIB_ARRAY_POINTER                  int
-	VERTEX_ATTRIB_ARRAY_SIZE                     int
-	VERTEX_ATTRIB_ARRAY_STRIDE                   int
-	VERTEX_ATTRIB_ARRAY_TYPE                     int
-	VERTEX_SHADER                                int
-	VIEWPORT                                     int
-	ZERO                                         int
-	TRUE                                         int
-	R8                                           int
-	RED                                          int
-	R32F                                         int
-}
-
-// NewContext ...
-func NewContext() *Context {
+// desktopBackend forwards to OpenGL 4.1 core through go-gl.
+type desktopBackend struct {
+	// the entry points of 4.2 and 4.3 are nil on the drivers without them,
+	// calling them crashes
+	computeShaders bool
+	memoryBarrier  bool
+	// pending is the error of a call refused instead, GetError returns it
+	// first
+	pending int
+	// report is the KHR_debug callback of EnableDebugOutput, which tells
+	// the refused calls as well
+	report func(msg *DebugMessage)
+}
+
+var _ NativeBackend = (*desktopBackend)(nil)
+
+// NewDesktopBackend initializes go-gl, a GL context must be current.
+func NewDesktopBackend() NativeBackend {
 	if err := gl.Init(); err != nil {
 		log.Fatal(err)
 		panic(err)
 	}
-	return &Context{
-		ARRAY_BUFFER:               gl.ARRAY_BUFFER,
-		ARRAY_BUFFER_BINDING:       gl.ARRAY_BUFFER_BINDING,
-		ATTACHED_SHADERS:           gl.ATTACHED_SHADERS,
-		BACK:                       gl.BACK,
-		BLEND:                      gl.BLEND,
-		BLEND_COLOR:                gl.BLEND_COLOR,
-		BLEND_DST_ALPHA:            gl.BLEND_DST_ALPHA,
-		BLEND_DST_RGB:              gl.BLEND_DST_RGB,
-		BLEND_EQUATION:             gl.BLEND_EQUATION,
-		BLEND_EQUATION_ALPHA:       gl.BLEND_EQUATION_ALPHA,
-		BLEND_EQUATION_RGB:         gl.BLEND_EQUATION_RGB,
-		BLEND_SRC_ALPHA:            gl.BLEND_SRC_ALPHA,
-		BLEND_SRC_RGB:              gl.BLEND_SRC_RGB,
-		BOOL:                       gl.BOOL,
-		BOOL_VEC2:                  gl.BOOL_VEC2,
-		BOOL_VEC3:                  gl.BOOL_VEC3,
-		BOOL_VEC4:                  gl.BOOL_VEC4,
-		BUFFER_SIZE:                gl.BUFFER_SIZE,
-		BUFFER_USAGE:               gl.BUFFER_USAGE,
-		BYTE:                       gl.BYTE,
-		CCW:                        gl.CCW,
-		CLAMP_TO_EDGE:              gl.CLAMP_TO_EDGE,
-		CLAMP_TO_BORDER:            gl.CLAMP_TO_BORDER,
-		COLOR_ATTACHMENT0:          gl.COLOR_ATTACHMENT0,
-		COLOR_BUFFER_BIT:           gl.COLOR_BUFFER_BIT,
-		COLOR_CLEAR_VALUE:          gl.COLOR_CLEAR_VALUE,
-		COLOR_WRITEMASK:            gl.COLOR_WRITEMASK,
-		COMPILE_STATUS:             gl.COMPILE_STATUS,
-		COMPRESSED_TEXTURE_FORMATS: gl.COMPRESSED_TEXTURE_FORMATS,
-		CONSTANT_ALPHA:             gl.CONSTANT_ALPHA,
-		CONSTANT_COLOR:             gl.CONSTANT_COLOR,
-		CULL_FACE:                  gl.CULL_FACE,
-		CULL_FACE_MODE:             gl.CULL_FACE_MODE,
-		CURRENT_PROGRAM:            gl.CURRENT_PROGRAM,
-		CURRENT_VERTEX_ATTRIB:      gl.CURRENT_VERTEX_ATTRIB,
-		CW:                           gl.CW,
-		DECR:                         gl.DECR,
-		DECR_WRAP:                    gl.DECR_WRAP,
-		DELETE_STATUS:                gl.DELETE_STATUS,
-		DEPTH_ATTACHMENT:             gl.DEPTH_ATTACHMENT,
-		DEPTH_BUFFER_BIT:             gl.DEPTH_BUFFER_BIT,
-		DEPTH_CLEAR_VALUE:            gl.DEPTH_CLEAR_VALUE,
-		DEPTH_COMPONENT:              gl.DEPTH_COMPONENT,
-		DEPTH_COMPONENT16:            gl.DEPTH_COMPONENT16,
-		DEPTH_FUNC:                   gl.DEPTH_FUNC,
-		DEPTH_RANGE:                  gl.DEPTH_RANGE,
-		DEPTH_STENCIL:                gl.DEPTH_STENCIL,
-		DEPTH_STENCIL_ATTACHMENT:     gl.DEPTH_STENCIL_ATTACHMENT,
-		DEPTH_TEST:                   gl.DEPTH_TEST,
-		DEPTH_WRITEMASK:              gl.DEPTH_WRITEMASK,
-		DITHER:                       gl.DITHER,
-		DONT_CARE:                    gl.DONT_CARE,
-		DST_ALPHA:                    gl.DST_ALPHA,
-		DST_COLOR:                    gl.DST_COLOR,
-		DYNAMIC_DRAW:                 gl.DYNAMIC_DRAW,
-		ELEMENT_ARRAY_BUFFER:         gl.ELEMENT_ARRAY_BUFFER,
-		ELEMENT_ARRAY_BUFFER_BINDING: gl.ELEMENT_ARRAY_BUFFER_BINDING,
-		EQUAL:                                        gl.EQUAL,
-		FASTEST:                                      gl.FASTEST,
-		FLOAT:                                        gl.FLOAT,
-		FLOAT_MAT2:                                   gl.FLOAT_MAT2,
-		FLOAT_MAT3:                                   gl.FLOAT_MAT3,
-		FLOAT_MAT4:                                   gl.FLOAT_MAT4,
-		FLOAT_VEC2:                                   gl.FLOAT_VEC2,
-		FLOAT_VEC3:                                   gl.FLOAT_VEC3,
-		FLOAT_VEC4:                                   gl.FLOAT_VEC4,
-		FRAGMENT_SHADER:                              gl.FRAGMENT_SHADER,
-		FRAMEBUFFER:                                  gl.FRAMEBUFFER,
-		FRAMEBUFFER_ATTACHMENT_OBJECT_NAME:           gl.FRAMEBUFFER_ATTACHMENT_OBJECT_NAME,
-		FRAMEBUFFER_ATTACHMENT_OBJECT_TYPE:           gl.FRAMEBUFFER_ATTACHMENT_OBJECT_TYPE,
-		FRAMEBUFFER_ATTACHMENT_TEXTURE_CUBE_MAP_FACE: gl.FRAMEBUFFER_ATTACHMENT_TEXTURE_CUBE_MAP_FACE,
-		FRAMEBUFFER_ATTACHMENT_TEXTURE_LEVEL:         gl.FRAMEBUFFER_ATTACHMENT_TEXTURE_LEVEL,
-		FRAMEBUFFER_BINDING:                          gl.FRAMEBUFFER_BINDING,
-		FRAMEBUFFER_COMPLETE:                         gl.FRAMEBUFFER_COMPLETE,
-		FRAMEBUFFER_INCOMPLETE_ATTACHMENT:            gl.FRAMEBUFFER_INCOMPLETE_ATTACHMENT,
-		FRAMEBUFFER_INCOMPLETE_MISSING_ATTACHMENT:    gl.FRAMEBUFFER_INCOMPLETE_MISSING_ATTACHMENT,
-		FRAMEBUFFER_INCOMPLETE_DRAW_BUFFER:           gl.FRAMEBUFFER_INCOMPLETE_DRAW_BUFFER,
-		FRAMEBUFFER_INCOMPLETE_READ_BUFFER:           gl.FRAMEBUFFER_INCOMPLETE_READ_BUFFER,
-		FRAMEBUFFER_UNSUPPORTED:                      gl.FRAMEBUFFER_UNSUPPORTED,
-		FRONT:                         gl.FRONT,
-		FRONT_AND_BACK:                gl.FRONT_AND_BACK,
-		FRONT_FACE:                    gl.FRONT_FACE,
-		FUNC_ADD:                      gl.FUNC_ADD,
-		FUNC_REVERSE_SUBTRACT:         gl.FUNC_REVERSE_SUBTRACT,
-		FUNC_SUBTRACT:                 gl.FUNC_SUBTRACT,
-		GEQUAL:                        gl.GEQUAL,
-		GREATER:                       gl.GREATER,
-		HIGH_FLOAT:                    gl.HIGH_FLOAT,
-		HIGH_INT:                      gl.HIGH_INT,
-		INCR:                          gl.INCR,
-		INCR_WRAP:                     gl.INCR_WRAP,
-		INFO_LOG_LENGTH:               gl.INFO_LOG_LENGTH,
-		INT:                           gl.INT,
-		INT_VEC2:                      gl.INT_VEC2,
-		INT_VEC3:                      gl.INT_VEC3,
-		INT_VEC4:                      gl.INT_VEC4,
-		INVALID_ENUM:                  gl.INVALID_ENUM,
-		INVALID_FRAMEBUFFER_OPERATION: gl.INVALID_FRAMEBUFFER_OPERATION,
-		INVALID_OPERATION:             gl.INVALID_OPERATION,
-		INVALID_VALUE:                 gl.INVALID_VALUE,
-		INVERT:                        gl.INVERT,
-		KEEP:                          gl.KEEP,
-		LEQUAL:                        gl.LEQUAL,
-		LESS:                          gl.LESS,
-		LINEAR:                        gl.LINEAR,
-		LINEAR_MIPMAP_LINEAR:          gl.LINEAR_MIPMAP_LINEAR,
-		LINEAR_MIPMAP_NEAREST:         gl.LINEAR_MIPMAP_NEAREST,
-		LINES:       gl.LINES,
-		LINE_LOOP:   gl.LINE_LOOP,
-		LINE_STRIP:  gl.LINE_STRIP,
-		LINE_WIDTH:  gl.LINE_WIDTH,
-		LINK_STATUS: gl.LINK_STATUS,
-		LOW_FLOAT:   gl.LOW_FLOAT,
-		LOW_INT:     gl.LOW_INT,
-		MAX_COMBINED_TEXTURE_IMAGE_UNITS: gl.MAX_COMBINED_TEXTURE_IMAGE_UNITS,
-		MAX_CUBE_MAP_TEXTURE_SIZE:        gl.MAX_CUBE_MAP_TEXTURE_SIZE,
-		MAX_FRAGMENT_UNIFORM_VECTORS:     gl.MAX_FRAGMENT_UNIFORM_VECTORS,
-		MAX_RENDERBUFFER_SIZE:            gl.MAX_RENDERBUFFER_SIZE,
-		MAX_TEXTURE_IMAGE_UNITS:          gl.MAX_TEXTURE_IMAGE_UNITS,
-		MAX_TEXTURE_SIZE:                 gl.MAX_TEXTURE_SIZE,
-		MAX_VARYING_VECTORS:              gl.MAX_VARYING_VECTORS,
-		MAX_VERTEX_ATTRIBS:               gl.MAX_VERTEX_ATTRIBS,
-		MAX_VERTEX_TEXTURE_IMAGE_UNITS:   gl.MAX_VERTEX_TEXTURE_IMAGE_UNITS,
-		MAX_VERTEX_UNIFORM_VECTORS:       gl.MAX_VERTEX_UNIFORM_VECTORS,
-		MAX_VIEWPORT_DIMS:                gl.MAX_VIEWPORT_DIMS,
-		MEDIUM_FLOAT:                     gl.MEDIUM_FLOAT,
-		MEDIUM_INT:                       gl.MEDIUM_INT,
-		MIRRORED_REPEAT:                  gl.MIRRORED_REPEAT,
-		MULTISAMPLE:                      gl.MULTISAMPLE,
-		NEAREST:                          gl.NEAREST,
-		NEAREST_MIPMAP_LINEAR:            gl.NEAREST_MIPMAP_LINEAR,
-		NEAREST_MIPMAP_NEAREST:           gl.NEAREST_MIPMAP_NEAREST,
-		NEVER:                          gl.NEVER,
-		NICEST:                         gl.NICEST,
-		NONE:                           gl.NONE,
-		NOTEQUAL:                       gl.NOTEQUAL,
-		NO_ERROR:                       gl.NO_ERROR,
-		NUM_COMPRESSED_TEXTURE_FORMATS: gl.NUM_COMPRESSED_TEXTURE_FORMATS,
-		ONE: gl.ONE,
-		ONE_MINUS_CONSTANT_ALPHA:     gl.ONE_MINUS_CONSTANT_ALPHA,
-		ONE_MINUS_CONSTANT_COLOR:     gl.ONE_MINUS_CONSTANT_COLOR,
-		ONE_MINUS_DST_ALPHA:          gl.ONE_MINUS_DST_ALPHA,
-		ONE_MINUS_DST_COLOR:          gl.ONE_MINUS_DST_COLOR,
-		ONE_MINUS_SRC_ALPHA:          gl.ONE_MINUS_SRC_ALPHA,
-		ONE_MINUS_SRC_COLOR:          gl.ONE_MINUS_SRC_COLOR,
-		OUT_OF_MEMORY:                gl.OUT_OF_MEMORY,
-		PACK_ALIGNMENT:               gl.PACK_ALIGNMENT,
-		POINTS:                       gl.POINTS,
-		POLYGON_OFFSET_FACTOR:        gl.POLYGON_OFFSET_FACTOR,
-		POLYGON_OFFSET_FILL:          gl.POLYGON_OFFSET_FILL,
-		POLYGON_OFFSET_UNITS:         gl.POLYGON_OFFSET_UNITS,
-		RENDERBUFFER:                 gl.RENDERBUFFER,
-		RENDERBUFFER_ALPHA_SIZE:      gl.RENDERBUFFER_ALPHA_SIZE,
-		RENDERBUFFER_BINDING:         gl.RENDERBUFFER_BINDING,
-		RENDERBUFFER_BLUE_SIZE:       gl.RENDERBUFFER_BLUE_SIZE,
-		RENDERBUFFER_DEPTH_SIZE:      gl.RENDERBUFFER_DEPTH_SIZE,
-		RENDERBUFFER_GREEN_SIZE:      gl.RENDERBUFFER_GREEN_SIZE,
-		RENDERBUFFER_HEIGHT:          gl.RENDERBUFFER_HEIGHT,
-		RENDERBUFFER_INTERNAL_FORMAT: gl.RENDERBUFFER_INTERNAL_FORMAT,
-		RENDERBUFFER_RED_SIZE:        gl.RENDERBUFFER_RED_SIZE,
-		RENDERBUFFER_STENCIL_SIZE:    gl.RENDERBUFFER_STENCIL_SIZE,
-		RENDERBUFFER_WIDTH:           gl.RENDERBUFFER_WIDTH,
-		RENDERER:                     gl.RENDERER,
-		REPEAT:                       gl.REPEAT,
-		REPLACE:                      gl.REPLACE,
-		RGB:                          gl.RGB,
-		RGB5_A1:                      gl.RGB5_A1,
-		RGB565:                       gl.RGB565,
-		RGBA:                         gl.RGBA,
-		RGBA32F:                      gl.RGBA32F,
-		//RGBA32F:                  0x8814,
-		RGBA4:                    gl.RGBA4,
-		SAMPLER_2D:               gl.SAMPLER_2D,
-		SAMPLER_CUBE:             gl.SAMPLER_CUBE,
-		SAMPLES:                  gl.SAMPLES,
-		SAMPLE_ALPHA_TO_COVERAGE: gl.SAMPLE_ALPHA_TO_COVERAGE,
-		SAMPLE_BUFFERS:           gl.SAMPLE_BUFFERS,
-		SAMPLE_COVERAGE:          gl.SAMPLE_COVERAGE,
-		SAMPLE_COVERAGE_INVERT:   gl.SAMPLE_COVERAGE_INVERT,
-		SAMPLE_COVERAGE_VALUE:    gl.SAMPLE_COVERAGE_VALUE,
-		SCISSOR_BOX:              gl.SCISSOR_BOX,
-		SCISSOR_TEST:             gl.SCISSOR_TEST,
-		SHADER_COMPILER:          gl.SHADER_COMPILER,
-		SHADER_SOURCE_LENGTH:     gl.SHADER_SOURCE_LENGTH,
-		SHADER_TYPE:              gl.SHADER_TYPE,
-		SHADING_LANGUAGE_VERSION: gl.SHADING_LANGUAGE_VERSION,
-		SHORT:                        gl.SHORT,
-		SRC_ALPHA:                    gl.SRC_ALPHA,
-		SRC_ALPHA_SATURATE:           gl.SRC_ALPHA_SATURATE,
-		SRC_COLOR:                    gl.SRC_COLOR,
-		STATIC_DRAW:                  gl.STATIC_DRAW,
-		STENCIL_ATTACHMENT:           gl.STENCIL_ATTACHMENT,
-		STENCIL_BACK_FAIL:            gl.STENCIL_BACK_FAIL,
-		STENCIL_BACK_FUNC:            gl.STENCIL_BACK_FUNC,
-		STENCIL_BACK_PASS_DEPTH_FAIL: gl.STENCIL_BACK_PASS_DEPTH_FAIL,
-		STENCIL_BACK_PASS_DEPTH_PASS: gl.STENCIL_BACK_PASS_DEPTH_PASS,
-		STENCIL_BACK_REF:             gl.STENCIL_BACK_REF,
-		STENCIL_BACK_VALUE_MASK:      gl.STENCIL_BACK_VALUE_MASK,
-		STENCIL_BACK_WRITEMASK:       gl.STENCIL_BACK_WRITEMASK,
-		STENCIL_BUFFER_BIT:           gl.STENCIL_BUFFER_BIT,
-		STENCIL_CLEAR_VALUE:          gl.STENCIL_CLEAR_VALUE,
-		STENCIL_FAIL:                 gl.STENCIL_FAIL,
-		STENCIL_FUNC:                 gl.STENCIL_FUNC,
-		STENCIL_INDEX:                gl.STENCIL_INDEX,
-		STENCIL_INDEX8:               gl.STENCIL_INDEX8,
-		STENCIL_PASS_DEPTH_FAIL:      gl.STENCIL_PASS_DEPTH_FAIL,
-		STENCIL_PASS_DEPTH_PASS:      gl.STENCIL_PASS_DEPTH_PASS,
-		STENCIL_REF:                  gl.STENCIL_REF,
-		STENCIL_TEST:                 gl.STENCIL_TEST,
-		STENCIL_VALUE_MASK:           gl.STENCIL_VALUE_MASK,
-		STENCIL_WRITEMASK:            gl.STENCIL_WRITEMASK,
-		STREAM_DRAW:                  gl.STREAM_DRAW,
-		SUBPIXEL_BITS:                gl.SUBPIXEL_BITS,
-		TEXTURE:                      gl.TEXTURE,
-		TEXTURE0:                     gl.TEXTURE0,
-		TEXTURE1:                     gl.TEXTURE1,
-		TEXTURE2:                     gl.TEXTURE2,
-		TEXTURE3:                     gl.TEXTURE3,
-		TEXTURE4:                     gl.TEXTURE4,
-		TEXTURE5:                     gl.TEXTURE5,
-		TEXTURE6:                     gl.TEXTURE6,
-		TEXTURE7:                     gl.TEXTURE7,
-		TEXTURE8:                     gl.TEXTURE8,
-		TEXTURE9:                     gl.TEXTURE9,
-		TEXTURE10:                    gl.TEXTURE10,
-		TEXTURE11:                    gl.TEXTURE11,
-		TEXTURE12:                    gl.TEXTURE12,
-		TEXTURE13:                    gl.TEXTURE13,
-		TEXTURE14:                    gl.TEXTURE14,
-		TEXTURE15:                    gl.TEXTURE15,
-		TEXTURE16:                    gl.TEXTURE16,
-		TEXTURE17:                    gl.TEXTURE17,
-		TEXTURE18:                    gl.TEXTURE18,
-		TEXTURE19:                    gl.TEXTURE19,
-		TEXTURE20:                    gl.TEXTURE20,
-		TEXTURE21:                    gl.TEXTURE21,
-		TEXTURE22:                    gl.TEXTURE22,
-		TEXTURE23:                    gl.TEXTURE23,
-		TEXTURE24:                    gl.TEXTURE24,
-		TEXTURE25:                    gl.TEXTURE25,
-		TEXTURE26:                    gl.TEXTURE26,
-		TEXTURE27:                    gl.TEXTURE27,
-		TEXTURE28:                    gl.TEXTURE28,
-		TEXTURE29:                    gl.TEXTURE29,
-		TEXTURE30:                    gl.TEXTURE30,
-		TEXTURE31:                    gl.TEXTURE31,
-		TEXTURE_2D:                   gl.TEXTURE_2D,
-		TEXTURE_BINDING_2D:           gl.TEXTURE_BINDING_2D,
-		TEXTURE_BINDING_CUBE_MAP:     gl.TEXTURE_BINDING_CUBE_MAP,
-		TEXTURE_CUBE_MAP:             gl.TEXTURE_CUBE_MAP,
-		TEXTURE_CUBE_MAP_NEGATIVE_X:  gl.TEXTURE_CUBE_MAP_NEGATIVE_X,
-		TEXTURE_CUBE_MAP_NEGATIVE_Y:  gl.TEXTURE_CUBE_MAP_NEGATIVE_Y,
-		TEXTURE_CUBE_MAP_NEGATIVE_Z:  gl.TEXTURE_CUBE_MAP_NEGATIVE_Z,
-		TEXTURE_CUBE_MAP_POSITIVE_X:  gl.TEXTURE_CUBE_MAP_POSITIVE_X,
-		TEXTURE_CUBE_MAP_POSITIVE_Y:  gl.TEXTURE_CUBE_MAP_POSITIVE_Y,
-		TEXTURE_CUBE_MAP_POSITIVE_Z:  gl.TEXTURE_CUBE_MAP_POSITIVE_Z,
-		TEXTURE_MAG_FILTER:           gl.TEXTURE_MAG_FILTER,
-		TEXTURE_MIN_FILTER:           gl.TEXTURE_MIN_FILTER,
-		TEXTURE_WRAP_S:               gl.TEXTURE_WRAP_S,
-		TEXTURE_WRAP_T:               gl.TEXTURE_WRAP_T,
-		TRIANGLES:                    gl.TRIANGLES,
-		TRIANGLE_FAN:                 gl.TRIANGLE_FAN,
-		TRIANGLE_STRIP:               gl.TRIANGLE_STRIP,
-		UNPACK_ALIGNMENT:             gl.UNPACK_ALIGNMENT,
-		UNSIGNED_BYTE:                gl.UNSIGNED_BYTE,
-		UNSIGNED_INT:                 gl.UNSIGNED_INT,
-		UNSIGNED_SHORT:               gl.UNSIGNED_SHORT,
-		UNSIGNED_SHORT_4_4_4_4:       gl.UNSIGNED_SHORT_4_4_4_4,
-		UNSIGNED_SHORT_5_5_5_1:       gl.UNSIGNED_SHORT_5_5_5_1,
-		UNSIGNED_SHORT_5_6_5:         gl.UNSIGNED_SHORT_5_6_5,
-		VALIDATE_STATUS:              gl.VALIDATE_STATUS,
-		VENDOR:                       gl.VENDOR,
-		VERSION:                      gl.VERSION,
-		VERTEX_ATTRIB_ARRAY_BUFFER_BINDING: gl.VERTEX_ATTRIB_ARRAY_BUFFER_BINDING,
-		VERTEX_ATTRIB_ARRAY_ENABLED:        gl.VERTEX_ATTRIB_ARRAY_ENABLED,
-		VERTEX_ATTRIB_ARRAY_NORMALIZED:     gl.VERTEX_ATTRIB_ARRAY_NORMALIZED,
-		VERTEX_ATTRIB_ARRAY_POINTER:        gl.VERTEX_ATTRIB_ARRAY_POINTER,
-		VERTEX_ATTRIB_ARRAY_SIZE:           gl.VERTEX_ATTRIB_ARRAY_SIZE,
-		VERTEX_ATTRIB_ARRAY_STRIDE:         gl.VERTEX_ATTRIB_ARRAY_STRIDE,
-		VERTEX_ATTRIB_ARRAY_TYPE:           gl.VERTEX_ATTRIB_ARRAY_TYPE,
-		VERTEX_SHADER:                      gl.VERTEX_SHADER,
-		VIEWPORT:                           gl.VIEWPORT,
-		ZERO:                               gl.ZERO,
-		TRUE:                               gl.TRUE,
-		R8:                                 gl.R8,
-		RED:                                gl.RED,
-		R32F:                               gl.R32F,
-	}
+	c := &desktopBackend{}
+	caps := c.QueryCaps()
+	c.computeShaders = caps.ComputeShaders
+	c.memoryBarrier = caps.GLSLVersion >= 420 || caps.HasExtension("GL_ARB_shader_image_load_store")
+	return c
 }
 
-func (c *Context) Version() string {
+// NewContext returns a context for the current OpenGL context.
+func NewContext() *Context {
+	return NewBackendContext(NewDesktopBackend())
+}
+
+func (c *desktopBackend) Version() string {
 	return gl.GoStr(gl.GetString(gl.VERSION))
 }
 
-func (c *Context) GetError() int {
+func (c *desktopBackend) QueryCaps() (caps Caps) {
+	getInteger := func(pname uint32) int {
+		var v int32
+		gl.GetIntegerv(pname, &v)
+		return int(v)
+	}
+	caps.MaxTextureSize = getInteger(gl.MAX_TEXTURE_SIZE)
+	caps.MaxTextureUnits = getInteger(gl.MAX_TEXTURE_IMAGE_UNITS)
+	caps.MaxVertexAttribs = getInteger(gl.MAX_VERTEX_ATTRIBS)
+	caps.MaxRenderbufferSize = getInteger(gl.MAX_RENDERBUFFER_SIZE)
+	caps.MaxSamples = getInteger(gl.MAX_SAMPLES)
+	caps.MaxUniformBufferBindings = getInteger(gl.MAX_UNIFORM_BUFFER_BINDINGS)
+
+	extensions := make([]string, getInteger(gl.NUM_EXTENSIONS))
+	for i := range extensions {
+		extensions[i] = gl.GoStr(gl.GetStringi(gl.EXTENSIONS, uint32(i)))
+	}
+	caps.setExtensions(extensions)
+	caps.GLSLVersion, caps.ES = parseGLSLVersion(gl.GoStr(gl.GetString(gl.SHADING_LANGUAGE_VERSION)))
+	caps.Driver = driverString(gl.GoStr(gl.GetString(gl.VENDOR)), gl.GoStr(gl.GetString(gl.RENDERER)), c.Version())
+	caps.ProgramBinary = getInteger(gl.NUM_PROGRAM_BINARY_FORMATS) > 0
+
+	// all core in 4.1
+	caps.VertexArrays = true
+	caps.FloatTextures = true
+	caps.Instancing = true
+	caps.Uint32Indices = true
+	caps.UniformBuffers = true
+	caps.GeometryShaders = true
+	caps.TessellationShaders = true
+	// the GLSL version follows the OpenGL one since 3.30, macOS stops at 4.10
+	caps.ComputeShaders = caps.GLSLVersion >= 430 || caps.HasExtension("GL_ARB_compute_shader")
+	return caps
+}
+
+var _ debugOutput = (*desktopBackend)(nil)
+
+var (
+	sDebugSources = map[uint32]string{
+		gl.DEBUG_SOURCE_API:             "API",
+		gl.DEBUG_SOURCE_WINDOW_SYSTEM:   "WINDOW_SYSTEM",
+		gl.DEBUG_SOURCE_SHADER_COMPILER: "SHADER_COMPILER",
+		gl.DEBUG_SOURCE_THIRD_PARTY:     "THIRD_PARTY",
+		gl.DEBUG_SOURCE_APPLICATION:     "APPLICATION",
+	}
+	sDebugTypes = map[uint32]string{
+		gl.DEBUG_TYPE_ERROR:               "ERROR",
+		gl.DEBUG_TYPE_DEPRECATED_BEHAVIOR: "DEPRECATED_BEHAVIOR",
+		gl.DEBUG_TYPE_UNDEFINED_BEHAVIOR:  "UNDEFINED_BEHAVIOR",
+		gl.DEBUG_TYPE_PORTABILITY:         "PORTABILITY",
+		gl.DEBUG_TYPE_PERFORMANCE:         "PERFORMANCE",
+		gl.DEBUG_TYPE_MARKER:              "MARKER",
+	}
+	sDebugSeverities = map[uint32]DebugSeverity{
+		gl.DEBUG_SEVERITY_HIGH:   DebugHigh,
+		gl.DEBUG_SEVERITY_MEDIUM: DebugMedium,
+		gl.DEBUG_SEVERITY_LOW:    DebugLow,
+	}
+)
+
+// EnableDebugOutput installs a KHR_debug callback, it returns false when
+// the driver does not have it (macOS stops at 4.1).
+func (c *desktopBackend) EnableDebugOutput(report func(msg *DebugMessage)) bool {
+	caps := c.QueryCaps()
+	if !caps.HasExtension("GL_KHR_debug") {
+		return false
+	}
+	c.report = report
+	gl.Enable(gl.DEBUG_OUTPUT)
+	// the callback runs in the failing call, its stack names the caller
+	gl.Enable(gl.DEBUG_OUTPUT_SYNCHRONOUS)
+	gl.DebugMessageCallback(func(source, gltype, id, severity uint32, length int32, message string, userParam unsafe.Pointer) {
+		msg := &DebugMessage{
+			Source:   sDebugSources[source],
+			Type:     sDebugTypes[gltype],
+			ID:       id,
+			Severity: sDebugSeverities[severity],
+			Message:  message,
+		}
+		if msg.Source == "" {
+			msg.Source = "OTHER"
+		}
+		if msg.Type == "" {
+			msg.Type = "OTHER"
+		}
+		report(msg)
+	}, nil)
+	return true
+}
+
+func (c *desktopBackend) DisableDebugOutput() {
+	c.report = nil
+	gl.DebugMessageCallback(nil, nil)
+	gl.Disable(gl.DEBUG_OUTPUT_SYNCHRONOUS)
+	gl.Disable(gl.DEBUG_OUTPUT)
+}
+
+func (c *desktopBackend) GetError() int {
+	if c.pending != gl.NO_ERROR {
+		code := c.pending
+		c.pending = gl.NO_ERROR
+		return code
+	}
 	return int(gl.GetError())
 }
 
-func (c *Context) Ptr(data interface{}) unsafe.Pointer {
+// refuse records INVALID_OPERATION for a call the driver lacks.
+func (c *desktopBackend) refuse(method string) {
+	if c.pending == gl.NO_ERROR {
+		c.pending = gl.INVALID_OPERATION
+	}
+	if c.report != nil {
+		c.report(&DebugMessage{
+			Source:   "API",
+			Type:     "ERROR",
+			ID:       gl.INVALID_OPERATION,
+			Severity: DebugHigh,
+			Message:  method + " is not supported by the driver",
+		})
+	}
+}
+
+func (c *desktopBackend) Ptr(data interface{}) unsafe.Pointer {
 	return gl.Ptr(data)
 }
 
-func (c *Context) DeleteProgram(program *Program) {
+func (c *desktopBackend) DeleteProgram(program *Program) {
 	gl.DeleteProgram(program.uint32)
 }
 
-func (c *Context) GetProgramInfoLog(program *Program) string {
+func (c *desktopBackend) GetProgramInfoLog(program *Program) string {
 	var maxLength int32
 	gl.GetProgramiv(program.uint32, gl.INFO_LOG_LENGTH, &maxLength)
 
@@ -662,7 +190,7 @@ func (c *Context) GetProgramInfoLog(program *Program) string {
 	return string(errorLog)
 }
 
-func (c *Context) ValidateProgram(program *Program) {
+func (c *desktopBackend) ValidateProgram(program *Program) {
 	if program == nil {
 		gl.ValidateProgram(0)
 		return
@@ -670,31 +198,31 @@ func (c *Context) ValidateProgram(program *Program) {
 	gl.ValidateProgram(program.uint32)
 }
 
-func (c *Context) GetProgramParameterb(program *Program, pname int) bool {
+func (c *desktopBackend) GetProgramParameterb(program *Program, pname int) bool {
 	var success int32 = gl.FALSE
 	gl.GetProgramiv(program.uint32, uint32(pname), &success)
 	return success == gl.TRUE
 }
 
-func (c *Context) GetProgramParameteri(program *Program, pname int) int {
+func (c *desktopBackend) GetProgramParameteri(program *Program, pname int) int {
 	var success int32 = gl.FALSE
 	gl.GetProgramiv(program.uint32, uint32(pname), &success)
 	return int(success)
 }
 
-func (c *Context) LinkProgram(program *Program) {
+func (c *desktopBackend) LinkProgram(program *Program) {
 	gl.LinkProgram(program.uint32)
 }
 
-func (c *Context) GetUniformLocation(program *Program, name string) *UniformLocation {
+func (c *desktopBackend) GetUniformLocation(program *Program, name string) *UniformLocation {
 	return &UniformLocation{gl.GetUniformLocation(program.uint32, gl.Str(name+"\x00"))}
 }
 
-func (c *Context) GetAttribLocation(program *Program, name string) int {
+func (c *desktopBackend) GetAttribLocation(program *Program, name string) int {
 	return int(gl.GetAttribLocation(program.uint32, gl.Str(name+"\x00")))
 }
 
-func (c *Context) UseProgram(program *Program) {
+func (c *desktopBackend) UseProgram(program *Program) {
 	if program == nil {
 		gl.UseProgram(0)
 		return
@@ -702,28 +230,28 @@ func (c *Context) UseProgram(program *Program) {
 	gl.UseProgram(program.uint32)
 }
 
-func (c *Context) Uniform1f(location *UniformLocation, x float32) {
+func (c *desktopBackend) Uniform1f(location *UniformLocation, x float32) {
 	gl.Uniform1f(location.int32, x)
 }
 
 // Assigns a integer value to a uniform variable for the current program object.
-func (c *Context) Uniform1i(location *UniformLocation, x int) {
+func (c *desktopBackend) Uniform1i(location *UniformLocation, x int) {
 	gl.Uniform1i(location.int32, int32(x))
 }
 
-func (c *Context) Uniform2f(location *UniformLocation, x, y float32) {
+func (c *desktopBackend) Uniform2f(location *UniformLocation, x, y float32) {
 	gl.Uniform2f(location.int32, x, y)
 }
 
-func (c *Context) Uniform3f(location *UniformLocation, x, y, z float32) {
+func (c *desktopBackend) Uniform3f(location *UniformLocation, x, y, z float32) {
 	gl.Uniform3f(location.int32, x, y, z)
 }
 
-func (c *Context) Uniform4f(location *UniformLocation, x, y, z, w float32) {
+func (c *desktopBackend) Uniform4f(location *UniformLocation, x, y, z, w float32) {
 	gl.Uniform4f(location.int32, x, y, z, w)
 }
 
-func (c *Context) UniformMatrix3fv(location *UniformLocation, transpose bool, value []float32) {
+func (c *desktopBackend) UniformMatrix3fv(location *UniformLocation, transpose bool, value []float32) {
 	// TODO: count value of 1 is currently hardcoded.
 	//       Perhaps it should be len(value) / 16 or something else?
 	//       In OpenGL 2.1 it is a manually supplied parameter, but WebGL does not have it.
@@ -731,7 +259,7 @@ func (c *Context) UniformMatrix3fv(location *UniformLocation, transpose bool, va
 	gl.UniformMatrix3fv(location.int32, 1, transpose, &value[0])
 }
 
-func (c *Context) UniformMatrix4fv(location *UniformLocation, transpose bool, value []float32) {
+func (c *desktopBackend) UniformMatrix4fv(location *UniformLocation, transpose bool, value []float32) {
 	// TODO: count value of 1 is currently hardcoded.
 	//       Perhaps it should be len(value) / 16 or something else?
 	//       In OpenGL 2.1 it is a manually supplied parameter, but WebGL does not have it.
@@ -739,36 +267,36 @@ func (c *Context) UniformMatrix4fv(location *UniformLocation, transpose bool, va
 	gl.UniformMatrix4fv(location.int32, 1, transpose, &value[0])
 }
 
-func (c *Context) CreateProgram() *Program {
+func (c *desktopBackend) CreateProgram() *Program {
 	return &Program{gl.CreateProgram()}
 }
 
-func (c *Context) AttachShader(program *Program, shader *Shader) {
+func (c *desktopBackend) AttachShader(program *Program, shader *Shader) {
 	gl.AttachShader(program.uint32, shader.uint32)
 }
 
-func (c *Context) CreateShader(typ int) *Shader {
+func (c *desktopBackend) CreateShader(typ int) *Shader {
 	shader := &Shader{gl.CreateShader(uint32(typ))}
 	return shader
 }
 
-func (c *Context) ShaderSource(shader *Shader, source string) {
+func (c *desktopBackend) ShaderSource(shader *Shader, source string) {
 	glsource, free := gl.Strs(source + "\x00")
 	gl.ShaderSource(shader.uint32, 1, glsource, nil)
 	free()
 }
 
-func (c *Context) CompileShader(shader *Shader) {
+func (c *desktopBackend) CompileShader(shader *Shader) {
 	gl.CompileShader(shader.uint32)
 }
 
-func (c *Context) GetShaderiv(shader *Shader, pname uint32) bool {
+func (c *desktopBackend) GetShaderiv(shader *Shader, pname uint32) bool {
 	var success int32
 	gl.GetShaderiv(shader.uint32, pname, &success)
 	return success == int32(gl.TRUE)
 }
 
-func (c *Context) GetShaderInfoLog(shader *Shader) string {
+func (c *desktopBackend) GetShaderInfoLog(shader *Shader) string {
 	var maxLength int32
 	gl.GetShaderiv(shader.uint32, gl.INFO_LOG_LENGTH, &maxLength)
 
@@ -778,33 +306,33 @@ func (c *Context) GetShaderInfoLog(shader *Shader) string {
 	return string(errorLog)
 }
 
-func (c *Context) BindAttribLocation(program *Program, index int, name string) {
+func (c *desktopBackend) BindAttribLocation(program *Program, index int, name string) {
 	gl.BindAttribLocation(program.uint32, uint32(index), gl.Str(name+"\x00"))
 }
 
-func (c *Context) DeleteShader(shader *Shader) {
+func (c *desktopBackend) DeleteShader(shader *Shader) {
 	gl.DeleteShader(shader.uint32)
 }
 
-func (c *Context) DeleteTexture(texture *Texture) {
+func (c *desktopBackend) DeleteTexture(texture *Texture) {
 	gl.DeleteTextures(1, &[]uint32{texture.uint32}[0])
 }
 
-func (c *Context) DeleteBuffer(buffer *Buffer) {
+func (c *desktopBackend) DeleteBuffer(buffer *Buffer) {
 	gl.DeleteBuffers(1, &[]uint32{buffer.uint32}[0])
 }
 
-func (c *Context) DeleteVertexArray(vao *VertexArray) {
+func (c *desktopBackend) DeleteVertexArray(vao *VertexArray) {
 	gl.DeleteVertexArrays(1, &[]uint32{vao.uint32}[0])
 }
 
-func (c *Context) CreateVertexArray() *VertexArray {
+func (c *desktopBackend) CreateVertexArray() *VertexArray {
 	var loc uint32
 	gl.GenVertexArrays(1, &loc)
 	return &VertexArray{loc}
 }
 
-func (c *Context) BindVertexArray(vao *VertexArray) {
+func (c *desktopBackend) BindVertexArray(vao *VertexArray) {
 	if vao == nil {
 		gl.BindVertexArray(0)
 		return
@@ -812,41 +340,41 @@ func (c *Context) BindVertexArray(vao *VertexArray) {
 	gl.BindVertexArray(vao.uint32)
 }
 
-func (c *Context) EnableVertexAttribArray(index int) {
+func (c *desktopBackend) EnableVertexAttribArray(index int) {
 	gl.EnableVertexAttribArray(uint32(index))
 }
 
-func (c *Context) DisableVertexAttribArray(index int) {
+func (c *desktopBackend) DisableVertexAttribArray(index int) {
 	gl.DisableVertexAttribArray(uint32(index))
 }
 
-func (c *Context) VertexAttribPointer(index, size, typ int, normal bool, stride int, offset int) {
+func (c *desktopBackend) VertexAttribPointer(index, size, typ int, normal bool, stride int, offset int) {
 	gl.VertexAttribPointer(uint32(index), int32(size), uint32(typ), normal, int32(stride), gl.PtrOffset(offset))
 }
 
-func (c *Context) Enable(flag int) {
+func (c *desktopBackend) Enable(flag int) {
 	gl.Enable(uint32(flag))
 }
 
-func (c *Context) Disable(flag int) {
+func (c *desktopBackend) Disable(flag int) {
 	gl.Disable(uint32(flag))
 }
 
-func (c *Context) BlendFunc(src, dst int) {
+func (c *desktopBackend) BlendFunc(src, dst int) {
 	gl.BlendFunc(uint32(src), uint32(dst))
 }
 
-func (c *Context) BlendEquation(mode int) {
+func (c *desktopBackend) BlendEquation(mode int) {
 	gl.BlendEquation(uint32(mode))
 }
 
-func (c *Context) CreateBuffer() *Buffer {
+func (c *desktopBackend) CreateBuffer() *Buffer {
 	var loc uint32
 	gl.GenBuffers(1, &loc)
 	return &Buffer{loc}
 }
 
-func (c *Context) BindBuffer(target int, buffer *Buffer) {
+func (c *desktopBackend) BindBuffer(target int, buffer *Buffer) {
 	if buffer == nil {
 		gl.BindBuffer(uint32(target), 0)
 		return
@@ -854,34 +382,113 @@ func (c *Context) BindBuffer(target int, buffer *Buffer) {
 	gl.BindBuffer(uint32(target), buffer.uint32)
 }
 
-func (c *Context) BufferData(target int, data interface{}, usage int) {
+func (c *desktopBackend) BindBufferBase(target, index int, buffer *Buffer) {
+	if buffer == nil {
+		gl.BindBufferBase(uint32(target), uint32(index), 0)
+		return
+	}
+	gl.BindBufferBase(uint32(target), uint32(index), buffer.uint32)
+}
+
+func (c *desktopBackend) GetUniformBlockIndex(program *Program, name string) int {
+	return int(int32(gl.GetUniformBlockIndex(program.uint32, gl.Str(name+"\x00"))))
+}
+
+func (c *desktopBackend) UniformBlockBinding(program *Program, blockIndex, binding int) {
+	gl.UniformBlockBinding(program.uint32, uint32(blockIndex), uint32(binding))
+}
+
+func (c *desktopBackend) GetProgramBinary(program *Program) (binary []byte, format int) {
+	var length int32
+	gl.GetProgramiv(program.uint32, gl.PROGRAM_BINARY_LENGTH, &length)
+	if length == 0 {
+		return nil, 0
+	}
+	binary = make([]byte, length)
+	var glformat uint32
+	gl.GetProgramBinary(program.uint32, length, &length, &glformat, gl.Ptr(binary))
+	return binary[:length], int(glformat)
+}
+
+func (c *desktopBackend) VertexAttribDivisor(index, divisor int) {
+	gl.VertexAttribDivisor(uint32(index), uint32(divisor))
+}
+
+func (c *desktopBackend) DrawArraysInstanced(mode, first, count, instances int) {
+	gl.DrawArraysInstanced(uint32(mode), int32(first), int32(count), int32(instances))
+}
+
+func (c *desktopBackend) DrawElementsInstanced(mode, count, typ, offset, instances int) {
+	gl.DrawElementsInstanced(uint32(mode), int32(count), uint32(typ), gl.PtrOffset(offset), int32(instances))
+}
+
+// DispatchCompute is loaded as an extension of 4.1, it is nil when the
+// driver lacks compute shaders: the calls then fail with INVALID_OPERATION.
+func (c *desktopBackend) DispatchCompute(x, y, z int) {
+	if !c.computeShaders {
+		c.refuse("DispatchCompute")
+		return
+	}
+	gl.DispatchCompute(uint32(x), uint32(y), uint32(z))
+}
+
+// MemoryBarrier is loaded as an extension of 4.1 as well.
+func (c *desktopBackend) MemoryBarrier(barriers int) {
+	if !c.memoryBarrier {
+		c.refuse("MemoryBarrier")
+		return
+	}
+	gl.MemoryBarrier(uint32(barriers))
+}
+
+func (c *desktopBackend) PatchParameteri(pname, value int) {
+	gl.PatchParameteri(uint32(pname), int32(value))
+}
+
+func (c *desktopBackend) ProgramParameteri(program *Program, pname, value int) {
+	gl.ProgramParameteri(program.uint32, uint32(pname), int32(value))
+}
+
+func (c *desktopBackend) ProgramBinary(program *Program, format int, binary []byte) {
+	if len(binary) == 0 {
+		gl.ProgramBinary(program.uint32, uint32(format), nil, 0)
+		return
+	}
+	gl.ProgramBinary(program.uint32, uint32(format), gl.Ptr(binary), int32(len(binary)))
+}
+
+func (c *desktopBackend) BufferData(target int, data interface{}, usage int) {
+	if size, ok := data.(int); ok {
+		gl.BufferData(uint32(target), size, nil, uint32(usage))
+		return
+	}
 	s := uintptr(reflect.ValueOf(data).Len()) * reflect.TypeOf(data).Elem().Size()
 	gl.BufferData(uint32(target), int(s), gl.Ptr(data), uint32(usage))
 }
 
-func (c *Context) DrawElements(mode, count, typ, offset int) {
+func (c *desktopBackend) DrawElements(mode, count, typ, offset int) {
 	gl.DrawElements(uint32(mode), int32(count), uint32(typ), gl.PtrOffset(offset))
 }
 
-func (c *Context) ClearColor(r, g, b, a float32) {
+func (c *desktopBackend) ClearColor(r, g, b, a float32) {
 	gl.ClearColor(r, g, b, a)
 }
 
-func (c *Context) Clear(flags int) {
+func (c *desktopBackend) Clear(flags int) {
 	gl.Clear(uint32(flags))
 }
 
-func (c *Context) Viewport(x, y, width, height int) {
+func (c *desktopBackend) Viewport(x, y, width, height int) {
 	gl.Viewport(int32(x), int32(y), int32(width), int32(height))
 }
 
-func (c *Context) CreateTexture() *Texture {
+func (c *desktopBackend) CreateTexture() *Texture {
 	var loc uint32
 	gl.GenTextures(1, &loc)
 	return &Texture{loc}
 }
 
-func (c *Context) BindTexture(target int, texture *Texture) {
+func (c *desktopBackend) BindTexture(target int, texture *Texture) {
 	if texture == nil {
 		gl.BindTexture(uint32(target), 0)
 		return
@@ -889,15 +496,15 @@ func (c *Context) BindTexture(target int, texture *Texture) {
 	gl.BindTexture(uint32(target), texture.uint32)
 }
 
-func (c *Context) ActiveTexture(texture int) {
+func (c *desktopBackend) ActiveTexture(texture int) {
 	gl.ActiveTexture(uint32(texture))
 }
 
-func (c *Context) TexParameteri(target int, pname int, param int) {
+func (c *desktopBackend) TexParameteri(target int, pname int, param int) {
 	gl.TexParameteri(uint32(target), uint32(pname), int32(param))
 }
 
-func (c *Context) TexImage2D(target, level, internalFormat, width, height, format, kind int, data interface{}) {
+func (c *desktopBackend) TexImage2D(target, level, internalFormat, width, height, format, kind int, data interface{}) {
 	if data == nil {
 		gl.TexImage2D(uint32(target), int32(level), int32(internalFormat), int32(width), int32(height), int32(0), uint32(format), uint32(kind), nil)
 		return
@@ -910,17 +517,17 @@ func (c *Context) TexImage2D(target, level, internalFormat, width, height, forma
 	}
 }
 
-func (c *Context) DeleteRenderBuffer(vao *RenderBuffer) {
+func (c *desktopBackend) DeleteRenderbuffer(vao *RenderBuffer) {
 	gl.DeleteRenderbuffers(1, &[]uint32{vao.uint32}[0])
 }
 
-func (c *Context) CreateRenderBuffer() *RenderBuffer {
+func (c *desktopBackend) CreateRenderbuffer() *RenderBuffer {
 	var loc uint32
 	gl.GenRenderbuffers(1, &loc)
 	return &RenderBuffer{loc}
 }
 
-func (c *Context) BindRenderBuffer(target int, vao *RenderBuffer) {
+func (c *desktopBackend) BindRenderbuffer(target int, vao *RenderBuffer) {
 	if vao == nil {
 		gl.BindRenderbuffer(uint32(target), 0)
 		return
@@ -928,17 +535,17 @@ func (c *Context) BindRenderBuffer(target int, vao *RenderBuffer) {
 	gl.BindRenderbuffer(uint32(target), vao.uint32)
 }
 
-func (c *Context) DeleteFrameBuffer(vao *FrameBuffer) {
+func (c *desktopBackend) DeleteFramebuffer(vao *FrameBuffer) {
 	gl.DeleteFramebuffers(1, &[]uint32{vao.uint32}[0])
 }
 
-func (c *Context) CreateFrameBuffer() *FrameBuffer {
+func (c *desktopBackend) CreateFramebuffer() *FrameBuffer {
 	var loc uint32
 	gl.GenFramebuffers(1, &loc)
 	return &FrameBuffer{loc}
 }
 
-func (c *Context) BindFrameBuffer(target int, vao *FrameBuffer) {
+func (c *desktopBackend) BindFramebuffer(target int, vao *FrameBuffer) {
 	if vao == nil {
 		gl.BindFramebuffer(uint32(target), 0)
 		return
@@ -946,38 +553,283 @@ func (c *Context) BindFrameBuffer(target int, vao *FrameBuffer) {
 	gl.BindFramebuffer(uint32(target), vao.uint32)
 }
 
-func (c *Context) FramebufferRenderbuffer(target, attachment, renderbuffertarget int, renderbuffer *RenderBuffer) {
+func (c *desktopBackend) FramebufferRenderbuffer(target, attachment, renderbuffertarget int, renderbuffer *RenderBuffer) {
 	gl.FramebufferRenderbuffer(uint32(target), uint32(attachment), uint32(renderbuffertarget), renderbuffer.uint32)
 }
 
-func (c *Context) CheckFramebufferStatus(target int) int {
+func (c *desktopBackend) CheckFramebufferStatus(target int) int {
 	return int(gl.CheckFramebufferStatus(uint32(target)))
 }
 
-func (c *Context) RenderbufferStorage(target, internalFormat, width, height int) {
+func (c *desktopBackend) RenderbufferStorage(target, internalFormat, width, height int) {
 	gl.RenderbufferStorage(uint32(target), uint32(internalFormat), int32(width), int32(height))
 }
 
-func (c *Context) FramebufferTexture2D(target, attachment, textarget int, texture *Texture, level int) {
+func (c *desktopBackend) FramebufferTexture2D(target, attachment, textarget int, texture *Texture, level int) {
 	gl.FramebufferTexture2D(uint32(target), uint32(attachment), uint32(textarget), uint32(texture.uint32), int32(level))
 }
 
-func (c *Context) DrawBuffer(buf int) {
+func (c *desktopBackend) DrawBuffer(buf int) {
 	gl.DrawBuffer(uint32(buf))
 }
 
-func (c *Context) Flush() {
+func (c *desktopBackend) Flush() {
 	gl.Flush()
 }
 
-func (c *Context) ReadBuffer(src int) {
+func (c *desktopBackend) ReadBuffer(src int) {
 	gl.ReadBuffer(uint32(src))
 }
 
-func (c *Context) ReadPixels(x, y, width, height, format, typ int, pixels unsafe.Pointer) {
-	gl.ReadPixels(int32(x), int32(y), int32(width), int32(height), uint32(format), uint32(typ), pixels)
+func (c *desktopBackend) ReadPixels(x, y, width, height, format, typ int, pixels interface{}) {
+	switch t := pixels.(type) {
+	case []uint8, []float32:
+		gl.ReadPixels(int32(x), int32(y), int32(width), int32(height), uint32(format), uint32(typ), gl.Ptr(t))
+	default:
+		panic(fmt.Errorf("ReadPixels Unknown type %v", t))
+	}
 }
 
-func (c *Context) DrawArrays(mode, first, count int) {
+func (c *desktopBackend) DrawArrays(mode, first, count int) {
 	gl.DrawArrays(uint32(mode), int32(first), int32(count))
 }
+
+func (c *desktopBackend) Finish() {
+	gl.Finish()
+}
+
+func (c *desktopBackend) GetSupportedExtensions() []string {
+	var n int32
+	gl.GetIntegerv(gl.NUM_EXTENSIONS, &n)
+	extensions := make([]string, n)
+	for i := range extensions {
+		extensions[i] = gl.GoStr(gl.GetStringi(gl.EXTENSIONS, uint32(i)))
+	}
+	return extensions
+}
+
+func (c *desktopBackend) IsEnabled(flag int) bool {
+	return gl.IsEnabled(uint32(flag))
+}
+
+func (c *desktopBackend) BlendColor(r, g, b, a float32) {
+	gl.BlendColor(r, g, b, a)
+}
+
+func (c *desktopBackend) BlendFuncSeparate(srcRGB, dstRGB, srcAlpha, dstAlpha int) {
+	gl.BlendFuncSeparate(uint32(srcRGB), uint32(dstRGB), uint32(srcAlpha), uint32(dstAlpha))
+}
+
+func (c *desktopBackend) BlendEquationSeparate(modeRGB, modeAlpha int) {
+	gl.BlendEquationSeparate(uint32(modeRGB), uint32(modeAlpha))
+}
+
+func (c *desktopBackend) ClearDepth(depth float32) {
+	gl.ClearDepthf(depth)
+}
+
+func (c *desktopBackend) ClearStencil(s int) {
+	gl.ClearStencil(int32(s))
+}
+
+func (c *desktopBackend) ColorMask(r, g, b, a bool) {
+	gl.ColorMask(r, g, b, a)
+}
+
+func (c *desktopBackend) CullFace(mode int) {
+	gl.CullFace(uint32(mode))
+}
+
+func (c *desktopBackend) FrontFace(mode int) {
+	gl.FrontFace(uint32(mode))
+}
+
+func (c *desktopBackend) DepthFunc(fn int) {
+	gl.DepthFunc(uint32(fn))
+}
+
+func (c *desktopBackend) DepthMask(flag bool) {
+	gl.DepthMask(flag)
+}
+
+func (c *desktopBackend) DepthRange(zNear, zFar float32) {
+	gl.DepthRangef(zNear, zFar)
+}
+
+func (c *desktopBackend) LineWidth(width float32) {
+	gl.LineWidth(width)
+}
+
+func (c *desktopBackend) PixelStorei(pname, param int) {
+	gl.PixelStorei(uint32(pname), int32(param))
+}
+
+func (c *desktopBackend) PolygonOffset(factor, units float32) {
+	gl.PolygonOffset(factor, units)
+}
+
+func (c *desktopBackend) SampleCoverage(value float32, invert bool) {
+	gl.SampleCoverage(value, invert)
+}
+
+func (c *desktopBackend) Scissor(x, y, width, height int) {
+	gl.Scissor(int32(x), int32(y), int32(width), int32(height))
+}
+
+func (c *desktopBackend) StencilFunc(fn, ref, mask int) {
+	gl.StencilFunc(uint32(fn), int32(ref), uint32(mask))
+}
+
+func (c *desktopBackend) StencilFuncSeparate(face, fn, ref, mask int) {
+	gl.StencilFuncSeparate(uint32(face), uint32(fn), int32(ref), uint32(mask))
+}
+
+func (c *desktopBackend) IsProgram(program *Program) bool {
+	return program != nil && gl.IsProgram(program.uint32)
+}
+
+func (c *desktopBackend) DetachShader(program *Program, shader *Shader) {
+	gl.DetachShader(program.uint32, shader.uint32)
+}
+
+func (c *desktopBackend) GetAttachedShaders(program *Program) []*Shader {
+	var count int32
+	gl.GetProgramiv(program.uint32, gl.ATTACHED_SHADERS, &count)
+	if count == 0 {
+		return nil
+	}
+	ids := make([]uint32, count)
+	gl.GetAttachedShaders(program.uint32, count, &count, &ids[0])
+	shaders := make([]*Shader, count)
+	for i := range shaders {
+		shaders[i] = &Shader{ids[i]}
+	}
+	return shaders
+}
+
+func (c *desktopBackend) GetActiveAttrib(program *Program, index int) (name string, size, typ int) {
+	var maxLength int32
+	gl.GetProgramiv(program.uint32, gl.ACTIVE_ATTRIBUTE_MAX_LENGTH, &maxLength)
+	buf := make([]uint8, maxLength+1)
+	var length, glsize int32
+	var gltype uint32
+	gl.GetActiveAttrib(program.uint32, uint32(index), int32(len(buf)), &length, &glsize, &gltype, &buf[0])
+	return string(buf[:length]), int(glsize), int(gltype)
+}
+
+func (c *desktopBackend) GetActiveUniform(program *Program, index int) (name string, size, typ int) {
+	var maxLength int32
+	gl.GetProgramiv(program.uint32, gl.ACTIVE_UNIFORM_MAX_LENGTH, &maxLength)
+	buf := make([]uint8, maxLength+1)
+	var length, glsize int32
+	var gltype uint32
+	gl.GetActiveUniform(program.uint32, uint32(index), int32(len(buf)), &length, &glsize, &gltype, &buf[0])
+	return string(buf[:length]), int(glsize), int(gltype)
+}
+
+func (c *desktopBackend) IsShader(shader *Shader) bool {
+	return shader != nil && gl.IsShader(shader.uint32)
+}
+
+func (c *desktopBackend) GetShaderSource(shader *Shader) string {
+	var maxLength int32
+	gl.GetShaderiv(shader.uint32, gl.SHADER_SOURCE_LENGTH, &maxLength)
+	if maxLength == 0 {
+		return ""
+	}
+	source := make([]uint8, maxLength)
+	gl.GetShaderSource(shader.uint32, maxLength, &maxLength, &source[0])
+	return string(source[:maxLength])
+}
+
+func (c *desktopBackend) GetShaderParameterb(shader *Shader, pname int) bool {
+	return c.GetShaderParameteri(shader, pname) == gl.TRUE
+}
+
+func (c *desktopBackend) GetShaderParameteri(shader *Shader, pname int) int {
+	var param int32
+	gl.GetShaderiv(shader.uint32, uint32(pname), &param)
+	return int(param)
+}
+
+func (c *desktopBackend) Uniform2i(location *UniformLocation, x, y int) {
+	gl.Uniform2i(location.int32, int32(x), int32(y))
+}
+
+func (c *desktopBackend) Uniform3i(location *UniformLocation, x, y, z int) {
+	gl.Uniform3i(location.int32, int32(x), int32(y), int32(z))
+}
+
+func (c *desktopBackend) Uniform4i(location *UniformLocation, x, y, z, w int) {
+	gl.Uniform4i(location.int32, int32(x), int32(y), int32(z), int32(w))
+}
+
+func (c *desktopBackend) UniformMatrix2fv(location *UniformLocation, transpose bool, value []float32) {
+	gl.UniformMatrix2fv(location.int32, int32(len(value)/4), transpose, &value[0])
+}
+
+func (c *desktopBackend) IsBuffer(buffer *Buffer) bool {
+	return buffer != nil && gl.IsBuffer(buffer.uint32)
+}
+
+func (c *desktopBackend) BufferSubData(target int, offset int, data interface{}) {
+	s := uintptr(reflect.ValueOf(data).Len()) * reflect.TypeOf(data).Elem().Size()
+	gl.BufferSubData(uint32(target), offset, int(s), gl.Ptr(data))
+}
+
+func (c *desktopBackend) GetBufferParameter(target, pname int) int {
+	var param int32
+	gl.GetBufferParameteriv(uint32(target), uint32(pname), &param)
+	return int(param)
+}
+
+func (c *desktopBackend) GetVertexAttribOffset(index, pname int) int {
+	var pointer unsafe.Pointer
+	gl.GetVertexAttribPointerv(uint32(index), uint32(pname), &pointer)
+	return int(uintptr(pointer))
+}
+
+func (c *desktopBackend) IsTexture(texture *Texture) bool {
+	return texture != nil && gl.IsTexture(texture.uint32)
+}
+
+func (c *desktopBackend) TexSubImage2D(target, level, xoffset, yoffset, width, height, format, kind int, data interface{}) {
+	switch t := data.(type) {
+	case []uint8, []float32:
+		gl.TexSubImage2D(uint32(target), int32(level), int32(xoffset), int32(yoffset), int32(width), int32(height), uint32(format), uint32(kind), gl.Ptr(t))
+	default:
+		panic(fmt.Errorf("TexSubImage2D Unknown type %v", t))
+	}
+}
+
+func (c *desktopBackend) CopyTexImage2D(target, level, internalFormat, x, y, width, height, border int) {
+	gl.CopyTexImage2D(uint32(target), int32(level), uint32(internalFormat), int32(x), int32(y), int32(width), int32(height), int32(border))
+}
+
+func (c *desktopBackend) CopyTexSubImage2D(target, level, xoffset, yoffset, x, y, width, height int) {
+	gl.CopyTexSubImage2D(uint32(target), int32(level), int32(xoffset), int32(yoffset), int32(x), int32(y), int32(width), int32(height))
+}
+
+func (c *desktopBackend) GenerateMipmap(target int) {
+	gl.GenerateMipmap(uint32(target))
+}
+
+func (c *desktopBackend) IsFramebuffer(framebuffer *FrameBuffer) bool {
+	return framebuffer != nil && gl.IsFramebuffer(framebuffer.uint32)
+}
+
+func (c *desktopBackend) GetFramebufferAttachmentParameter(target, attachment, pname int) int {
+	var param int32
+	gl.GetFramebufferAttachmentParameteriv(uint32(target), uint32(attachment), uint32(pname), &param)
+	return int(param)
+}
+
+func (c *desktopBackend) IsRenderbuffer(renderbuffer *RenderBuffer) bool {
+	return renderbuffer != nil && gl.IsRenderbuffer(renderbuffer.uint32)
+}
+
+func (c *desktopBackend) GetRenderbufferParameter(target, pname int) int {
+	var param int32
+	gl.GetRenderbufferParameteriv(uint32(target), uint32(pname), &param)
+	return int(param)
+}
@@ -6,6 +6,7 @@ import (
 	"log"
 
 	"reflect"
+	"strings"
 	"unsafe"
 
 	"golang.org/x/mobile/gl"
@@ -20,604 +21,65 @@ type UniformLocation struct{ gl.Uniform }
 type Shader struct{ gl.Shader }
 type VertexArray struct{ gl.VertexArray }
 
+// Context is the mobile backend, it forwards to golang.org/x/mobile/gl.
 type Context struct {
+	Enums
+	ResourceTracker
+	Restorer
+	// Caps are the limits and features of the context.
+	Caps Caps
+
+	objects deviceObjects
+
 	ctx    gl.Context
 	worker gl.Worker
-
-	ARRAY_BUFFER                                 int
-	ARRAY_BUFFER_BINDING                         int
-	ATTACHED_SHADERS                             int
-	BACK                                         int
-	BLEND                                        int
-	BLEND_COLOR                                  int
-	BLEND_DST_ALPHA                              int
-	BLEND_DST_RGB                                int
-	BLEND_EQUATION                               int
-	BLEND_EQUATION_ALPHA                         int
-	BLEND_EQUATION_RGB                           int
-	BLEND_SRC_ALPHA                              int
-	BLEND_SRC_RGB                                int
-	BLUE_BITS                                    int
-	BOOL                                         int
-	BOOL_VEC2                                    int
-	BOOL_VEC3                                    int
-	BOOL_VEC4                                    int
-	BROWSER_DEFAULT_WEBGL                        int
-	BUFFER_SIZE                                  int
-	BUFFER_USAGE                                 int
-	BYTE                                         int
-	CCW                                          int
-	CLAMP_TO_EDGE                                int
-	CLAMP_TO_BORDER                              int
-	COLOR_ATTACHMENT0                            int
-	COLOR_BUFFER_BIT                             int
-	COLOR_CLEAR_VALUE                            int
-	COLOR_WRITEMASK                              int
-	COMPILE_STATUS                               uint32
-	COMPRESSED_TEXTURE_FORMATS                   int
-	CONSTANT_ALPHA                               int
-	CONSTANT_COLOR                               int
-	CONTEXT_LOST_WEBGL                           int
-	CULL_FACE                                    int
-	CULL_FACE_MODE                               int
-	CURRENT_PROGRAM                              int
-	CURRENT_VERTEX_ATTRIB                        int
-	CW                                           int
-	DECR                                         int
-	DECR_WRAP                                    int
-	DELETE_STATUS                                int
-	DEPTH_ATTACHMENT                             int
-	DEPTH_BITS                                   int
-	DEPTH_BUFFER_BIT                             int
-	DEPTH_CLEAR_VALUE                            int
-	DEPTH_COMPONENT                              int
-	DEPTH_COMPONENT16                            int
-	DEPTH_FUNC                                   int
-	DEPTH_RANGE                                  int
-	DEPTH_STENCIL                                int
-	DEPTH_STENCIL_ATTACHMENT                     int
-	DEPTH_TEST                                   int
-	DEPTH_WRITEMASK                              int
-	DITHER                                       int
-	DONT_CARE                                    int
-	DST_ALPHA                                    int
-	DST_COLOR                                    int
-	DYNAMIC_DRAW                                 int
-	ELEMENT_ARRAY_BUFFER                         int
-	ELEMENT_ARRAY_BUFFER_BINDING                 int
-	EQUAL                                        int
-	FASTEST                                      int
-	FLOAT                                        int
-	FLOAT_MAT2                                   int
-	FLOAT_MAT3                                   int
-	FLOAT_MAT4                                   int
-	FLOAT_VEC2                                   int
-	FLOAT_VEC3                                   int
-	FLOAT_VEC4                                   int
-	FRAGMENT_SHADER                              int
-	FRAMEBUFFER                                  int
-	FRAMEBUFFER_ATTACHMENT_OBJECT_NAME           int
-	FRAMEBUFFER_ATTACHMENT_OBJECT_TYPE           int
-	FRAMEBUFFER_ATTACHMENT_TEXTURE_CUBE_MAP_FACE int
-	FRAMEBUFFER_ATTACHMENT_TEXTURE_LEVEL         int
-	FRAMEBUFFER_BINDING                          int
-	FRAMEBUFFER_COMPLETE                         int
-	FRAMEBUFFER_INCOMPLETE_ATTACHMENT            int
-	FRAMEBUFFER_INCOMPLETE_DIMENSIONS            int
-	FRAMEBUFFER_INCOMPLETE_MISSING_ATTACHMENT    int
-	FRAMEBUFFER_UNSUPPORTED                      int
-	FRONT                                        int
-	FRONT_AND_BACK                               int
-	FRONT_FACE                                   int
-	FUNC_ADD                                     int
-	FUNC_REVERSE_SUBTRACT                        int
-	FUNC_SUBTRACT                                int
-	GENERATE_MIPMAP_HINT                         int
-	GEQUAL                                       int
-	GREATER                                      int
-	GREEN_BITS                                   int
-	HIGH_FLOAT                                   int
-	HIGH_INT                                     int
-	INCR                                         int
-	INCR_WRAP                                    int
-	INFO_LOG_LENGTH                              uint32
-	INT                                          int
-	INT_VEC2                                     int
-	INT_VEC3                                     int
-	INT_VEC4                                     int
-	INVALID_ENUM                                 int
-	INVALID_FRAMEBUFFER_OPERATION                int
-	INVALID_OPERATION                            int
-	INVALID_VALUE                                int
-	INVERT                                       int
-	KEEP                                         int
-	LEQUAL                                       int
-	LESS                                         int
-	LINEAR                                       int
-	LINEAR_MIPMAP_LINEAR                         int
-	LINEAR_MIPMAP_NEAREST                        int
-	LINES                                        int
-	LINE_LOOP                                    int
-	LINE_STRIP                                   int
-	LINE_WIDTH                                   int
-	LINK_STATUS                                  int
-	LOW_FLOAT                                    int
-	LOW_INT                                      int
-	LUMINANCE                                    int
-	LUMINANCE_ALPHA                              int
-	MAX_COMBINED_TEXTURE_IMAGE_UNITS             int
-	MAX_CUBE_MAP_TEXTURE_SIZE                    int
-	MAX_FRAGMENT_UNIFORM_VECTORS                 int
-	MAX_RENDERBUFFER_SIZE                        int
-	MAX_TEXTURE_IMAGE_UNITS                      int
-	MAX_TEXTURE_SIZE                             int
-	MAX_VARYING_VECTORS                          int
-	MAX_VERTEX_ATTRIBS                           int
-	MAX_VERTEX_TEXTURE_IMAGE_UNITS               int
-	MAX_VERTEX_UNIFORM_VECTORS                   int
-	MAX_VIEWPORT_DIMS                            int
-	MEDIUM_FLOAT                                 int
-	MEDIUM_INT                                   int
-	MIRRORED_REPEAT                              int
-	MULTISAMPLE                                  int
-	NEAREST                                      int
-	NEAREST_MIPMAP_LINEAR                        int
-	NEAREST_MIPMAP_NEAREST                       int
-	NEVER                                        int
-	NICEST                                       int
-	NONE                                         int
-	NOTEQUAL                                     int
-	NO_ERROR                                     int
-	NUM_COMPRESSED_TEXTURE_FORMATS               int
-	ONE                                          int
-	ONE_MINUS_CONSTANT_ALPHA                     int
-	ONE_MINUS_CONSTANT_COLOR                     int
-	ONE_MINUS_DST_ALPHA                          int
-	ONE_MINUS_DST_COLOR                          int
-	ONE_MINUS_SRC_ALPHA                          int
-	ONE_MINUS_SRC_COLOR                          int
-	OUT_OF_MEMORY                                int
-	PACK_ALIGNMENT                               int
-	POINTS                                       int
-	POLYGON_OFFSET_FACTOR                        int
-	POLYGON_OFFSET_FILL                          int
-	POLYGON_OFFSET_UNITS                         int
-	RED_BITS                                     int
-	RENDERBUFFER                                 int
-	RENDERBUFFER_ALPHA_SIZE                      int
-	RENDERBUFFER_BINDING                         int
-	RENDERBUFFER_BLUE_SIZE                       int
-	RENDERBUFFER_DEPTH_SIZE                      int
-	RENDERBUFFER_GREEN_SIZE                      int
-	RENDERBUFFER_HEIGHT                          int
-	RENDERBUFFER_INTERNAL_FORMAT                 int
-	RENDERBUFFER_RED_SIZE                        int
-	RENDERBUFFER_STENCIL_SIZE                    int
-	RENDERBUFFER_WIDTH                           int
-	RENDERER                                     int
-	REPEAT                                       int
-	REPLACE                                      int
-	RGB                                          int
-	RGB5_A1                                      int
-	RGB565                                       int
-	RGBA                                         int
-	RGBA4                                        int
-	SAMPLER_2D                                   int
-	SAMPLER_CUBE                                 int
-	SAMPLES                                      int
-	SAMPLE_ALPHA_TO_COVERAGE                     int
-	SAMPLE_BUFFERS                               int
-	SAMPLE_COVERAGE                              int
-	SAMPLE_COVERAGE_INVERT                       int
-	SAMPLE_COVERAGE_VALUE                        int
-	SCISSOR_BOX                                  int
-	SCISSOR_TEST                                 int
-	SHADER_COMPILER                              int
-	SHADER_SOURCE_LENGTH                         int
-	SHADER_TYPE                                  int
-	SHADING_LANGUAGE_VERSION                     int
-	SHORT                                        int
-	SRC_ALPHA                                    int
-	SRC_ALPHA_SATURATE                           int
-	SRC_COLOR                                    int
-	STATIC_DRAW                                  int
-	STENCIL_ATTACHMENT                           int
-	STENCIL_BACK_FAIL                            int
-	STENCIL_BACK_FUNC                            int
-	STENCIL_BACK_PASS_DEPTH_FAIL                 int
-	STENCIL_BACK_PASS_DEPTH_PASS                 int
-	STENCIL_BACK_REF                             int
-	STENCIL_BACK_VALUE_MASK                      int
-	STENCIL_BACK_WRITEMASK                       int
-	STENCIL_BITS                                 int
-	STENCIL_BUFFER_BIT                           int
-	STENCIL_CLEAR_VALUE                          int
-	STENCIL_FAIL                                 int
-	STENCIL_FUNC                                 int
-	STENCIL_INDEX                                int
-	STENCIL_INDEX8                               int
-	STENCIL_PASS_DEPTH_FAIL                      int
-	STENCIL_PASS_DEPTH_PASS                      int
-	STENCIL_REF                                  int
-	STENCIL_TEST                                 int
-	STENCIL_VALUE_MASK                           int
-	STENCIL_WRITEMASK                            int
-	STREAM_DRAW                                  int
-	SUBPIXEL_BITS                                int
-	TEXTURE                                      int
-	TEXTURE0                                     int
-	TEXTURE1                                     int
-	TEXTURE2                                     int
-	TEXTURE3                                     int
-	TEXTURE4                                     int
-	TEXTURE5                                     int
-	TEXTURE6                                     int
-	TEXTURE7                                     int
-	TEXTURE8                                     int
-	TEXTURE9                                     int
-	TEXTURE10                                    int
-	TEXTURE11                                    int
-	TEXTURE12                                    int
-	TEXTURE13                                    int
-	TEXTURE14                                    int
-	TEXTURE15                                    int
-	TEXTURE16                                    int
-	TEXTURE17                                    int
-	TEXTURE18                                    int
-	TEXTURE19                                    int
-	TEXTURE20                                    int
-	TEXTURE21                                    int
-	TEXTURE22                                    int
-	TEXTURE23                                    int
-	TEXTURE24                                    int
-	TEXTURE25                                    int
-	TEXTURE26                                    int
-	TEXTURE27                                    int
-	TEXTURE28                                    int
-	TEXTURE29                                    int
-	TEXTURE30                                    int
-	TEXTURE31                                    int
-	TEXTURE_2D                                   int
-	TEXTURE_BINDING_2D                           int
-	TEXTURE_BINDING_CUBE_MAP                     int
-	TEXTURE_CUBE_MAP                             int
-	TEXTURE_CUBE_MAP_NEGATIVE_X                  int
-	TEXTURE_CUBE_MAP_NEGATIVE_Y                  int
-	TEXTURE_CUBE_MAP_NEGATIVE_Z                  int
-	TEXTURE_CUBE_MAP_POSITIVE_X                  int
-	TEXTURE_CUBE_MAP_POSITIVE_Y                  int
-	TEXTURE_CUBE_MAP_POSITIVE_Z                  int
-	TEXTURE_MAG_FILTER                           int
-	TEXTURE_MIN_FILTER                           int
-	TEXTURE_WRAP_S                               int
-	TEXTURE_WRAP_T                               int
-	TRIANGLES                                    int
-	TRIANGLE_FAN                                 int
-	TRIANGLE_STRIP                               int
-	UNPACK_ALIGNMENT                             int
-	UNPACK_COLORSPACE_CONVERSION_WEBGL           int
-	UNPACK_FLIP_Y_WEBGL                          int
-	UNPACK_PREMULTIPLY_ALPHA_WEBGL               int
-	UNSIGNED_BYTE                                int
-	UNSIGNED_INT                                 int
-	UNSIGNED_SHORT                               int
-	UNSIGNED_SHORT_4_4_4_4                       int
-	UNSIGNED_SHORT_5_5_5_1                       int
-	UNSIGNED_SHORT_5_6_5                         int
-	VALIDATE_STATUS                              int
-	VENDOR                                       int
-	VERSION                                      int
-	VERTEX_ATTRIB_ARRAY_BUFFER_BINDING           int
-	VERTEX_ATTRIB_ARRAY_ENABLED                  int
-	VERTEX_ATTRIB_ARRAY_NORMALIZED               int
-	VERTEX_ATTRIB_ARRAY_POINTER                  int
-	VERTEX_ATTRIB_ARRAY_SIZE                     int
-	VERTEX_ATTRIB_ARRAY_STRIDE                   int
-	VERTEX_ATTRIB_ARRAY_TYPE                     int
-	VERTEX_SHADER                                int
-	VIEWPORT                                     int
-	ZERO                                         int
-	TRUE                                         int
-	R8                                           int
-	RED                                          int
-	RGBA32F                                      int
 }
 
+var _ Backend = (*Context)(nil)
+
 func NewContext(DrawContext interface{}) *Context {
-	c := &Context{
-		ARRAY_BUFFER:               gl.ARRAY_BUFFER,
-		ARRAY_BUFFER_BINDING:       gl.ARRAY_BUFFER_BINDING,
-		ATTACHED_SHADERS:           gl.ATTACHED_SHADERS,
-		BACK:                       gl.BACK,
-		BLEND:                      gl.BLEND,
-		BLEND_COLOR:                gl.BLEND_COLOR,
-		BLEND_DST_ALPHA:            gl.BLEND_DST_ALPHA,
-		BLEND_DST_RGB:              gl.BLEND_DST_RGB,
-		BLEND_EQUATION:             gl.BLEND_EQUATION,
-		BLEND_EQUATION_ALPHA:       gl.BLEND_EQUATION_ALPHA,
-		BLEND_EQUATION_RGB:         gl.BLEND_EQUATION_RGB,
-		BLEND_SRC_ALPHA:            gl.BLEND_SRC_ALPHA,
-		BLEND_SRC_RGB:              gl.BLEND_SRC_RGB,
-		BLUE_BITS:                  gl.BLUE_BITS,
-		BOOL:                       gl.BOOL,
-		BOOL_VEC2:                  gl.BOOL_VEC2,
-		BOOL_VEC3:                  gl.BOOL_VEC3,
-		BOOL_VEC4:                  gl.BOOL_VEC4,
-		BUFFER_SIZE:                gl.BUFFER_SIZE,
-		BUFFER_USAGE:               gl.BUFFER_USAGE,
-		BYTE:                       gl.BYTE,
-		CCW:                        gl.CCW,
-		CLAMP_TO_EDGE:              gl.CLAMP_TO_EDGE,
-		COLOR_ATTACHMENT0:          gl.COLOR_ATTACHMENT0,
-		COLOR_BUFFER_BIT:           gl.COLOR_BUFFER_BIT,
-		COLOR_CLEAR_VALUE:          gl.COLOR_CLEAR_VALUE,
-		COLOR_WRITEMASK:            gl.COLOR_WRITEMASK,
-		COMPILE_STATUS:             gl.COMPILE_STATUS,
-		COMPRESSED_TEXTURE_FORMATS: gl.COMPRESSED_TEXTURE_FORMATS,
-		CONSTANT_ALPHA:             gl.CONSTANT_ALPHA,
-		CONSTANT_COLOR:             gl.CONSTANT_COLOR,
-		CULL_FACE:                  gl.CULL_FACE,
-		CULL_FACE_MODE:             gl.CULL_FACE_MODE,
-		CURRENT_PROGRAM:            gl.CURRENT_PROGRAM,
-		CURRENT_VERTEX_ATTRIB:      gl.CURRENT_VERTEX_ATTRIB,
-		CW:                           gl.CW,
-		DECR:                         gl.DECR,
-		DECR_WRAP:                    gl.DECR_WRAP,
-		DELETE_STATUS:                gl.DELETE_STATUS,
-		DEPTH_ATTACHMENT:             gl.DEPTH_ATTACHMENT,
-		DEPTH_BITS:                   gl.DEPTH_BITS,
-		DEPTH_BUFFER_BIT:             gl.DEPTH_BUFFER_BIT,
-		DEPTH_CLEAR_VALUE:            gl.DEPTH_CLEAR_VALUE,
-		DEPTH_COMPONENT:              gl.DEPTH_COMPONENT,
-		DEPTH_COMPONENT16:            gl.DEPTH_COMPONENT16,
-		DEPTH_FUNC:                   gl.DEPTH_FUNC,
-		DEPTH_RANGE:                  gl.DEPTH_RANGE,
-		DEPTH_TEST:                   gl.DEPTH_TEST,
-		DEPTH_WRITEMASK:              gl.DEPTH_WRITEMASK,
-		DITHER:                       gl.DITHER,
-		DONT_CARE:                    gl.DONT_CARE,
-		DST_ALPHA:                    gl.DST_ALPHA,
-		DST_COLOR:                    gl.DST_COLOR,
-		DYNAMIC_DRAW:                 gl.DYNAMIC_DRAW,
-		ELEMENT_ARRAY_BUFFER:         gl.ELEMENT_ARRAY_BUFFER,
-		ELEMENT_ARRAY_BUFFER_BINDING: gl.ELEMENT_ARRAY_BUFFER_BINDING,
-		EQUAL:                                        gl.EQUAL,
-		FASTEST:                                      gl.FASTEST,
-		FLOAT:                                        gl.FLOAT,
-		FLOAT_MAT2:                                   gl.FLOAT_MAT2,
-		FLOAT_MAT3:                                   gl.FLOAT_MAT3,
-		FLOAT_MAT4:                                   gl.FLOAT_MAT4,
-		FLOAT_VEC2:                                   gl.FLOAT_VEC2,
-		FLOAT_VEC3:                                   gl.FLOAT_VEC3,
-		FLOAT_VEC4:                                   gl.FLOAT_VEC4,
-		FRAGMENT_SHADER:                              gl.FRAGMENT_SHADER,
-		FRAMEBUFFER:                                  gl.FRAMEBUFFER,
-		FRAMEBUFFER_ATTACHMENT_OBJECT_NAME:           gl.FRAMEBUFFER_ATTACHMENT_OBJECT_NAME,
-		FRAMEBUFFER_ATTACHMENT_OBJECT_TYPE:           gl.FRAMEBUFFER_ATTACHMENT_OBJECT_TYPE,
-		FRAMEBUFFER_ATTACHMENT_TEXTURE_CUBE_MAP_FACE: gl.FRAMEBUFFER_ATTACHMENT_TEXTURE_CUBE_MAP_FACE,
-		FRAMEBUFFER_ATTACHMENT_TEXTURE_LEVEL:         gl.FRAMEBUFFER_ATTACHMENT_TEXTURE_LEVEL,
-		FRAMEBUFFER_BINDING:                          gl.FRAMEBUFFER_BINDING,
-		FRAMEBUFFER_COMPLETE:                         gl.FRAMEBUFFER_COMPLETE,
-		FRAMEBUFFER_INCOMPLETE_ATTACHMENT:            gl.FRAMEBUFFER_INCOMPLETE_ATTACHMENT,
-		FRAMEBUFFER_INCOMPLETE_MISSING_ATTACHMENT:    gl.FRAMEBUFFER_INCOMPLETE_MISSING_ATTACHMENT,
-		FRAMEBUFFER_UNSUPPORTED:                      gl.FRAMEBUFFER_UNSUPPORTED,
-		FRONT:                         gl.FRONT,
-		FRONT_AND_BACK:                gl.FRONT_AND_BACK,
-		FRONT_FACE:                    gl.FRONT_FACE,
-		FUNC_ADD:                      gl.FUNC_ADD,
-		FUNC_REVERSE_SUBTRACT:         gl.FUNC_REVERSE_SUBTRACT,
-		FUNC_SUBTRACT:                 gl.FUNC_SUBTRACT,
-		GENERATE_MIPMAP_HINT:          gl.GENERATE_MIPMAP_HINT,
-		GEQUAL:                        gl.GEQUAL,
-		GREATER:                       gl.GREATER,
-		GREEN_BITS:                    gl.GREEN_BITS,
-		HIGH_FLOAT:                    gl.HIGH_FLOAT,
-		HIGH_INT:                      gl.HIGH_INT,
-		INCR:                          gl.INCR,
-		INCR_WRAP:                     gl.INCR_WRAP,
-		INFO_LOG_LENGTH:               gl.INFO_LOG_LENGTH,
-		INT:                           gl.INT,
-		INT_VEC2:                      gl.INT_VEC2,
-		INT_VEC3:                      gl.INT_VEC3,
-		INT_VEC4:                      gl.INT_VEC4,
-		INVALID_ENUM:                  gl.INVALID_ENUM,
-		INVALID_FRAMEBUFFER_OPERATION: gl.INVALID_FRAMEBUFFER_OPERATION,
-		INVALID_OPERATION:             gl.INVALID_OPERATION,
-		INVALID_VALUE:                 gl.INVALID_VALUE,
-		INVERT:                        gl.INVERT,
-		KEEP:                          gl.KEEP,
-		LEQUAL:                        gl.LEQUAL,
-		LESS:                          gl.LESS,
-		LINEAR:                        gl.LINEAR,
-		LINEAR_MIPMAP_LINEAR:          gl.LINEAR_MIPMAP_LINEAR,
-		LINEAR_MIPMAP_NEAREST:         gl.LINEAR_MIPMAP_NEAREST,
-		LINES:                            gl.LINES,
-		LINE_LOOP:                        gl.LINE_LOOP,
-		LINE_STRIP:                       gl.LINE_STRIP,
-		LINE_WIDTH:                       gl.LINE_WIDTH,
-		LINK_STATUS:                      gl.LINK_STATUS,
-		LOW_FLOAT:                        gl.LOW_FLOAT,
-		LOW_INT:                          gl.LOW_INT,
-		LUMINANCE:                        gl.LUMINANCE,
-		LUMINANCE_ALPHA:                  gl.LUMINANCE_ALPHA,
-		MAX_COMBINED_TEXTURE_IMAGE_UNITS: gl.MAX_COMBINED_TEXTURE_IMAGE_UNITS,
-		MAX_CUBE_MAP_TEXTURE_SIZE:        gl.MAX_CUBE_MAP_TEXTURE_SIZE,
-		MAX_FRAGMENT_UNIFORM_VECTORS:     gl.MAX_FRAGMENT_UNIFORM_VECTORS,
-		MAX_RENDERBUFFER_SIZE:            gl.MAX_RENDERBUFFER_SIZE,
-		MAX_TEXTURE_IMAGE_UNITS:          gl.MAX_TEXTURE_IMAGE_UNITS,
-		MAX_TEXTURE_SIZE:                 gl.MAX_TEXTURE_SIZE,
-		MAX_VARYING_VECTORS:              gl.MAX_VARYING_VECTORS,
-		MAX_VERTEX_ATTRIBS:               gl.MAX_VERTEX_ATTRIBS,
-		MAX_VERTEX_TEXTURE_IMAGE_UNITS:   gl.MAX_VERTEX_TEXTURE_IMAGE_UNITS,
-		MAX_VERTEX_UNIFORM_VECTORS:       gl.MAX_VERTEX_UNIFORM_VECTORS,
-		MAX_VIEWPORT_DIMS:                gl.MAX_VIEWPORT_DIMS,
-		MEDIUM_FLOAT:                     gl.MEDIUM_FLOAT,
-		MEDIUM_INT:                       gl.MEDIUM_INT,
-		MIRRORED_REPEAT:                  gl.MIRRORED_REPEAT,
-		NEAREST:                          gl.NEAREST,
-		NEAREST_MIPMAP_LINEAR:            gl.NEAREST_MIPMAP_LINEAR,
-		NEAREST_MIPMAP_NEAREST:           gl.NEAREST_MIPMAP_NEAREST,
-		NEVER:                          gl.NEVER,
-		NICEST:                         gl.NICEST,
-		NONE:                           gl.NONE,
-		NOTEQUAL:                       gl.NOTEQUAL,
-		NO_ERROR:                       gl.NO_ERROR,
-		NUM_COMPRESSED_TEXTURE_FORMATS: gl.NUM_COMPRESSED_TEXTURE_FORMATS,
-		ONE: gl.ONE,
-		ONE_MINUS_CONSTANT_ALPHA:     gl.ONE_MINUS_CONSTANT_ALPHA,
-		ONE_MINUS_CONSTANT_COLOR:     gl.ONE_MINUS_CONSTANT_COLOR,
-		ONE_MINUS_DST_ALPHA:          gl.ONE_MINUS_DST_ALPHA,
-		ONE_MINUS_DST_COLOR:          gl.ONE_MINUS_DST_COLOR,
-		ONE_MINUS_SRC_ALPHA:          gl.ONE_MINUS_SRC_ALPHA,
-		ONE_MINUS_SRC_COLOR:          gl.ONE_MINUS_SRC_COLOR,
-		OUT_OF_MEMORY:                gl.OUT_OF_MEMORY,
-		PACK_ALIGNMENT:               gl.PACK_ALIGNMENT,
-		POINTS:                       gl.POINTS,
-		POLYGON_OFFSET_FACTOR:        gl.POLYGON_OFFSET_FACTOR,
-		POLYGON_OFFSET_FILL:          gl.POLYGON_OFFSET_FILL,
-		POLYGON_OFFSET_UNITS:         gl.POLYGON_OFFSET_UNITS,
-		RED_BITS:                     gl.RED_BITS,
-		RENDERBUFFER:                 gl.RENDERBUFFER,
-		RENDERBUFFER_ALPHA_SIZE:      gl.RENDERBUFFER_ALPHA_SIZE,
-		RENDERBUFFER_BINDING:         gl.RENDERBUFFER_BINDING,
-		RENDERBUFFER_BLUE_SIZE:       gl.RENDERBUFFER_BLUE_SIZE,
-		RENDERBUFFER_DEPTH_SIZE:      gl.RENDERBUFFER_DEPTH_SIZE,
-		RENDERBUFFER_GREEN_SIZE:      gl.RENDERBUFFER_GREEN_SIZE,
-		RENDERBUFFER_HEIGHT:          gl.RENDERBUFFER_HEIGHT,
-		RENDERBUFFER_INTERNAL_FORMAT: gl.RENDERBUFFER_INTERNAL_FORMAT,
-		RENDERBUFFER_RED_SIZE:        gl.RENDERBUFFER_RED_SIZE,
-		RENDERBUFFER_STENCIL_SIZE:    gl.RENDERBUFFER_STENCIL_SIZE,
-		RENDERBUFFER_WIDTH:           gl.RENDERBUFFER_WIDTH,
-		RENDERER:                     gl.RENDERER,
-		REPEAT:                       gl.REPEAT,
-		REPLACE:                      gl.REPLACE,
-		RGB:                          gl.RGB,
-		RGB5_A1:                      gl.RGB5_A1,
-		RGB565:                       gl.RGB565,
-		RGBA:                         gl.RGBA,
-		RGBA4:                        gl.RGBA4,
-		SAMPLER_2D:                   gl.SAMPLER_2D,
-		SAMPLER_CUBE:                 gl.SAMPLER_CUBE,
-		SAMPLES:                      gl.SAMPLES,
-		SAMPLE_ALPHA_TO_COVERAGE:     gl.SAMPLE_ALPHA_TO_COVERAGE,
-		SAMPLE_BUFFERS:               gl.SAMPLE_BUFFERS,
-		SAMPLE_COVERAGE:              gl.SAMPLE_COVERAGE,
-		SAMPLE_COVERAGE_INVERT:       gl.SAMPLE_COVERAGE_INVERT,
-		SAMPLE_COVERAGE_VALUE:        gl.SAMPLE_COVERAGE_VALUE,
-		SCISSOR_BOX:                  gl.SCISSOR_BOX,
-		SCISSOR_TEST:                 gl.SCISSOR_TEST,
-		SHADER_COMPILER:              gl.SHADER_COMPILER,
-		SHADER_SOURCE_LENGTH:         gl.SHADER_SOURCE_LENGTH,
-		SHADER_TYPE:                  gl.SHADER_TYPE,
-		SHADING_LANGUAGE_VERSION:     gl.SHADING_LANGUAGE_VERSION,
-		SHORT:                        gl.SHORT,
-		SRC_ALPHA:                    gl.SRC_ALPHA,
-		SRC_ALPHA_SATURATE:           gl.SRC_ALPHA_SATURATE,
-		SRC_COLOR:                    gl.SRC_COLOR,
-		STATIC_DRAW:                  gl.STATIC_DRAW,
-		STENCIL_ATTACHMENT:           gl.STENCIL_ATTACHMENT,
-		STENCIL_BACK_FAIL:            gl.STENCIL_BACK_FAIL,
-		STENCIL_BACK_FUNC:            gl.STENCIL_BACK_FUNC,
-		STENCIL_BACK_PASS_DEPTH_FAIL: gl.STENCIL_BACK_PASS_DEPTH_FAIL,
-		STENCIL_BACK_PASS_DEPTH_PASS: gl.STENCIL_BACK_PASS_DEPTH_PASS,
-		STENCIL_BACK_REF:             gl.STENCIL_BACK_REF,
-		STENCIL_BACK_VALUE_MASK:      gl.STENCIL_BACK_VALUE_MASK,
-		STENCIL_BACK_WRITEMASK:       gl.STENCIL_BACK_WRITEMASK,
-		STENCIL_BITS:                 gl.STENCIL_BITS,
-		STENCIL_BUFFER_BIT:           gl.STENCIL_BUFFER_BIT,
-		STENCIL_CLEAR_VALUE:          gl.STENCIL_CLEAR_VALUE,
-		STENCIL_FAIL:                 gl.STENCIL_FAIL,
-		STENCIL_FUNC:                 gl.STENCIL_FUNC,
-		STENCIL_INDEX8:               gl.STENCIL_INDEX8,
-		STENCIL_PASS_DEPTH_FAIL:      gl.STENCIL_PASS_DEPTH_FAIL,
-		STENCIL_PASS_DEPTH_PASS:      gl.STENCIL_PASS_DEPTH_PASS,
-		STENCIL_REF:                  gl.STENCIL_REF,
-		STENCIL_TEST:                 gl.STENCIL_TEST,
-		STENCIL_VALUE_MASK:           gl.STENCIL_VALUE_MASK,
-		STENCIL_WRITEMASK:            gl.STENCIL_WRITEMASK,
-		STREAM_DRAW:                  gl.STREAM_DRAW,
-		SUBPIXEL_BITS:                gl.SUBPIXEL_BITS,
-		TEXTURE:                      gl.TEXTURE,
-		TEXTURE0:                     gl.TEXTURE0,
-		TEXTURE1:                     gl.TEXTURE1,
-		TEXTURE2:                     gl.TEXTURE2,
-		TEXTURE3:                     gl.TEXTURE3,
-		TEXTURE4:                     gl.TEXTURE4,
-		TEXTURE5:                     gl.TEXTURE5,
-		TEXTURE6:                     gl.TEXTURE6,
-		TEXTURE7:                     gl.TEXTURE7,
-		TEXTURE8:                     gl.TEXTURE8,
-		TEXTURE9:                     gl.TEXTURE9,
-		TEXTURE10:                    gl.TEXTURE10,
-		TEXTURE11:                    gl.TEXTURE11,
-		TEXTURE12:                    gl.TEXTURE12,
-		TEXTURE13:                    gl.TEXTURE13,
-		TEXTURE14:                    gl.TEXTURE14,
-		TEXTURE15:                    gl.TEXTURE15,
-		TEXTURE16:                    gl.TEXTURE16,
-		TEXTURE17:                    gl.TEXTURE17,
-		TEXTURE18:                    gl.TEXTURE18,
-		TEXTURE19:                    gl.TEXTURE19,
-		TEXTURE20:                    gl.TEXTURE20,
-		TEXTURE21:                    gl.TEXTURE21,
-		TEXTURE22:                    gl.TEXTURE22,
-		TEXTURE23:                    gl.TEXTURE23,
-		TEXTURE24:                    gl.TEXTURE24,
-		TEXTURE25:                    gl.TEXTURE25,
-		TEXTURE26:                    gl.TEXTURE26,
-		TEXTURE27:                    gl.TEXTURE27,
-		TEXTURE28:                    gl.TEXTURE28,
-		TEXTURE29:                    gl.TEXTURE29,
-		TEXTURE30:                    gl.TEXTURE30,
-		TEXTURE31:                    gl.TEXTURE31,
-		TEXTURE_2D:                   gl.TEXTURE_2D,
-		TEXTURE_BINDING_2D:           gl.TEXTURE_BINDING_2D,
-		TEXTURE_BINDING_CUBE_MAP:     gl.TEXTURE_BINDING_CUBE_MAP,
-		TEXTURE_CUBE_MAP:             gl.TEXTURE_CUBE_MAP,
-		TEXTURE_CUBE_MAP_NEGATIVE_X:  gl.TEXTURE_CUBE_MAP_NEGATIVE_X,
-		TEXTURE_CUBE_MAP_NEGATIVE_Y:  gl.TEXTURE_CUBE_MAP_NEGATIVE_Y,
-		TEXTURE_CUBE_MAP_NEGATIVE_Z:  gl.TEXTURE_CUBE_MAP_NEGATIVE_Z,
-		TEXTURE_CUBE_MAP_POSITIVE_X:  gl.TEXTURE_CUBE_MAP_POSITIVE_X,
-		TEXTURE_CUBE_MAP_POSITIVE_Y:  gl.TEXTURE_CUBE_MAP_POSITIVE_Y,
-		TEXTURE_CUBE_MAP_POSITIVE_Z:  gl.TEXTURE_CUBE_MAP_POSITIVE_Z,
-		TEXTURE_MAG_FILTER:           gl.TEXTURE_MAG_FILTER,
-		TEXTURE_MIN_FILTER:           gl.TEXTURE_MIN_FILTER,
-		TEXTURE_WRAP_S:               gl.TEXTURE_WRAP_S,
-		TEXTURE_WRAP_T:               gl.TEXTURE_WRAP_T,
-		TRIANGLES:                    gl.TRIANGLES,
-		TRIANGLE_FAN:                 gl.TRIANGLE_FAN,
-		TRIANGLE_STRIP:               gl.TRIANGLE_STRIP,
-		UNPACK_ALIGNMENT:             gl.UNPACK_ALIGNMENT,
-		UNSIGNED_BYTE:                gl.UNSIGNED_BYTE,
-		UNSIGNED_INT:                 gl.UNSIGNED_INT,
-		UNSIGNED_SHORT:               gl.UNSIGNED_SHORT,
-		UNSIGNED_SHORT_4_4_4_4:       gl.UNSIGNED_SHORT_4_4_4_4,
-		UNSIGNED_SHORT_5_5_5_1:       gl.UNSIGNED_SHORT_5_5_5_1,
-		UNSIGNED_SHORT_5_6_5:         gl.UNSIGNED_SHORT_5_6_5,
-		VALIDATE_STATUS:              gl.VALIDATE_STATUS,
-		VENDOR:                       gl.VENDOR,
-		VERSION:                      gl.VERSION,
-		VERTEX_ATTRIB_ARRAY_BUFFER_BINDING: gl.VERTEX_ATTRIB_ARRAY_BUFFER_BINDING,
-		VERTEX_ATTRIB_ARRAY_ENABLED:        gl.VERTEX_ATTRIB_ARRAY_ENABLED,
-		VERTEX_ATTRIB_ARRAY_NORMALIZED:     gl.VERTEX_ATTRIB_ARRAY_NORMALIZED,
-		VERTEX_ATTRIB_ARRAY_POINTER:        gl.VERTEX_ATTRIB_ARRAY_POINTER,
-		VERTEX_ATTRIB_ARRAY_SIZE:           gl.VERTEX_ATTRIB_ARRAY_SIZE,
-		VERTEX_ATTRIB_ARRAY_STRIDE:         gl.VERTEX_ATTRIB_ARRAY_STRIDE,
-		VERTEX_ATTRIB_ARRAY_TYPE:           gl.VERTEX_ATTRIB_ARRAY_TYPE,
-		VERTEX_SHADER:                      gl.VERTEX_SHADER,
-		VIEWPORT:                           gl.VIEWPORT,
-		ZERO:                               gl.ZERO,
-		TRUE:                               gl.TRUE,
-		R8:                                 gl.R8,
-		RED:                                gl.RED,
-		RGBA32F:                            gl.RGBA32F,
-	}
+	c := &Context{Enums: sEnums}
 	//c.Ctx, c.Worker = gl.NewContext()
 	c.ctx = DrawContext.(gl.Context)
+	c.Caps = c.queryCaps()
 
 	return c
 }
 
+// RestoreContext switches to the new DrawContext of a resumed app and
+// re-creates the GPU objects of the registered resources. The objects
+// created with the lost context are forgotten, they do not count as leaks.
+func (c *Context) RestoreContext(DrawContext interface{}) error {
+	c.ctx = DrawContext.(gl.Context)
+	c.Caps = c.queryCaps()
+	c.ResourceTracker = ResourceTracker{}
+	err := c.restore()
+	c.restored(err)
+	return err
+}
+
+func (c *Context) queryCaps() (caps Caps) {
+	caps.MaxTextureSize = c.ctx.GetInteger(gl.MAX_TEXTURE_SIZE)
+	caps.MaxTextureUnits = c.ctx.GetInteger(gl.MAX_TEXTURE_IMAGE_UNITS)
+	caps.MaxVertexAttribs = c.ctx.GetInteger(gl.MAX_VERTEX_ATTRIBS)
+	caps.MaxRenderbufferSize = c.ctx.GetInteger(gl.MAX_RENDERBUFFER_SIZE)
+
+	// core in OpenGL ES 3, extensions in OpenGL ES 2
+	if _, es3 := c.ctx.(gl.Context3); es3 {
+		caps.MaxSamples = c.ctx.GetInteger(gl.MAX_SAMPLES)
+		caps.VertexArrays = true
+		caps.FloatTextures = true
+		caps.Instancing = true
+		caps.Uint32Indices = true
+	}
+	caps.setExtensions(c.GetSupportedExtensions())
+	// the bindings have no instanced draws, whatever the driver has
+	caps.Instancing = false
+	caps.GLSLVersion, caps.ES = parseGLSLVersion(c.ctx.GetString(gl.SHADING_LANGUAGE_VERSION))
+	caps.Driver = driverString(c.ctx.GetString(gl.VENDOR), c.ctx.GetString(gl.RENDERER), c.ctx.GetString(gl.VERSION))
+	return caps
+}
+
 // The GL_BLEND_COLOR may be used to calculate the source and destination blending factors.
 func (c *Context) BlendColor(r, g, b, a float32) {
 	c.ctx.BlendColor(float32(r), float32(g), float32(b), float32(a))
@@ -647,8 +109,8 @@ func (c *Context) BlendFuncSeparate(srcRGB, dstRGB, srcAlpha, dstAlpha int) {
 
 // Sets a function to use to compare incoming pixel depth to the
 // current depth buffer value.
-func (c *Context) DepthFunc(fun int) {
-	c.ctx.DepthFunc(gl.Enum(fun))
+func (c *Context) DepthFunc(fn int) {
+	c.ctx.DepthFunc(gl.Enum(fn))
 }
 
 func (c *Context) SampleCoverage(value float32, invert bool) {
@@ -694,6 +156,62 @@ func (c *Context) BindBuffer(target int, buffer *Buffer) {
 	c.ctx.BindBuffer(gl.Enum(target), buffer.Buffer)
 }
 
+// BindBufferBase is not available: the golang.org/x/mobile bindings have no
+// uniform buffers.
+func (c *Context) BindBufferBase(target, index int, buffer *Buffer) {
+	log.Println("Warning: BindBufferBase is not yet implemented")
+}
+
+// GetUniformBlockIndex is not available, it returns INVALID_INDEX.
+func (c *Context) GetUniformBlockIndex(program *Program, name string) int {
+	log.Println("Warning: GetUniformBlockIndex is not yet implemented")
+	return c.INVALID_INDEX
+}
+
+// UniformBlockBinding is not available.
+func (c *Context) UniformBlockBinding(program *Program, blockIndex, binding int) {
+	log.Println("Warning: UniformBlockBinding is not yet implemented")
+}
+
+func (c *Context) GetProgramBinary(program *Program) (binary []byte, format int) {
+	log.Println("Warning: GetProgramBinary is not yet implemented")
+	return nil, 0
+}
+
+func (c *Context) ProgramBinary(program *Program, format int, binary []byte) {
+	log.Println("Warning: ProgramBinary is not yet implemented")
+}
+
+func (c *Context) ProgramParameteri(program *Program, pname, value int) {
+	log.Println("Warning: ProgramParameteri is not yet implemented")
+}
+
+// The golang.org/x/mobile bindings have no instanced draws.
+func (c *Context) VertexAttribDivisor(index, divisor int) {
+	log.Println("Warning: VertexAttribDivisor is not yet implemented")
+}
+
+func (c *Context) DrawArraysInstanced(mode, first, count, instances int) {
+	log.Println("Warning: DrawArraysInstanced is not yet implemented")
+}
+
+func (c *Context) DrawElementsInstanced(mode, count, typ, offset, instances int) {
+	log.Println("Warning: DrawElementsInstanced is not yet implemented")
+}
+
+// OpenGL ES 2 has no compute nor tessellation shaders.
+func (c *Context) DispatchCompute(x, y, z int) {
+	log.Println("Warning: DispatchCompute is not available in OpenGL ES 2")
+}
+
+func (c *Context) MemoryBarrier(barriers int) {
+	log.Println("Warning: MemoryBarrier is not available in OpenGL ES 2")
+}
+
+func (c *Context) PatchParameteri(pname, value int) {
+	log.Println("Warning: PatchParameteri is not available in OpenGL ES 2")
+}
+
 // Associates a WebGLFramebuffer object with the FRAMEBUFFER bind target.
 func (c *Context) BindFramebuffer(target int, framebuffer *FrameBuffer) {
 	if framebuffer == nil {
@@ -724,50 +242,41 @@ func (c *Context) BindTexture(target int, texture *Texture) {
 // Creates a buffer in memory and initializes it with array data.
 // If no array is provided, the contents of the buffer is initialized to 0.
 func (c *Context) BufferData(target int, data interface{}, usage int) {
-	var b []byte
-	switch arr := data.(type) {
-	case []uint16:
-		fheader := (*reflect.SliceHeader)(unsafe.Pointer(&arr))
-
-		header := (*reflect.SliceHeader)(unsafe.Pointer(&b))
-		header.Cap = 2 * fheader.Cap
-		header.Len = 2 * fheader.Len
-		header.Data = fheader.Data
-
-	case []float32:
-		fheader := (*reflect.SliceHeader)(unsafe.Pointer(&arr))
-
-		header := (*reflect.SliceHeader)(unsafe.Pointer(&b))
-		header.Cap = 4 * fheader.Cap
-		header.Len = 4 * fheader.Len
-		header.Data = fheader.Data
+	if size, ok := data.(int); ok {
+		c.ctx.BufferInit(gl.Enum(target), size, gl.Enum(usage))
+		return
 	}
-
-	c.ctx.BufferData(gl.Enum(target), b, gl.Enum(usage))
+	c.ctx.BufferData(gl.Enum(target), mobileBytes(data), gl.Enum(usage))
 }
 
 // Used to modify or update some or all of a data store for a bound buffer object.
 func (c *Context) BufferSubData(target int, offset int, data interface{}) {
+	c.ctx.BufferSubData(gl.Enum(target), offset, mobileBytes(data))
+}
+
+// mobileBytes returns the memory of a slice of numbers, without copying.
+func mobileBytes(data interface{}) []byte {
 	var b []byte
-	switch arr := data.(type) {
+	var size int
+	switch data.(type) {
+	case nil:
+		return nil
+	case []uint8:
+		return data.([]uint8)
 	case []uint16:
-		fheader := (*reflect.SliceHeader)(unsafe.Pointer(&arr))
-
-		header := (*reflect.SliceHeader)(unsafe.Pointer(&b))
-		header.Cap = 2 * fheader.Cap
-		header.Len = 2 * fheader.Len
-		header.Data = fheader.Data
-
-	case []float32:
-		fheader := (*reflect.SliceHeader)(unsafe.Pointer(&arr))
-
-		header := (*reflect.SliceHeader)(unsafe.Pointer(&b))
-		header.Cap = 4 * fheader.Cap
-		header.Len = 4 * fheader.Len
-		header.Data = fheader.Data
+		size = 2
+	case []uint32, []float32:
+		size = 4
+	default:
+		log.Printf("Warning: %T is not supported on mobile system", data)
+		return nil
 	}
-
-	c.ctx.BufferSubData(gl.Enum(target), offset, b)
+	v := reflect.ValueOf(data)
+	header := (*reflect.SliceHeader)(unsafe.Pointer(&b))
+	header.Cap = size * v.Cap()
+	header.Len = size * v.Len()
+	header.Data = v.Pointer()
+	return b
 }
 
 // Returns whether the currently bound WebGLFramebuffer is complete.
@@ -807,45 +316,56 @@ func (c *Context) CompileShader(shader *Shader) {
 }
 
 // Copies a rectangle of pixels from the current WebGLFramebuffer into a texture image.
-func (c *Context) CopyTexImage2D(target, level, internal, x, y, w, h, border int) {
-	c.ctx.CopyTexImage2D(gl.Enum(target), level, gl.Enum(internal), x, y, w, h, border)
+func (c *Context) CopyTexImage2D(target, level, internalFormat, x, y, width, height, border int) {
+	c.ctx.CopyTexImage2D(gl.Enum(target), level, gl.Enum(internalFormat), x, y, width, height, border)
 }
 
 // Replaces a portion of an existing 2D texture image with data from the current framebuffer.
-func (c *Context) CopyTexSubImage2D(target, level, xoffset, yoffset, x, y, w, h int) {
-	c.ctx.CopyTexSubImage2D(gl.Enum(target), level, xoffset, yoffset, x, y, w, h)
+func (c *Context) CopyTexSubImage2D(target, level, xoffset, yoffset, x, y, width, height int) {
+	c.ctx.CopyTexSubImage2D(gl.Enum(target), level, xoffset, yoffset, x, y, width, height)
 }
 
 // Creates and initializes a WebGLBuffer.
 func (c *Context) CreateBuffer() *Buffer {
-	return &Buffer{c.ctx.CreateBuffer()}
+	res := &Buffer{c.ctx.CreateBuffer()}
+	c.created(res)
+	return res
 }
 
 // Returns a WebGLFramebuffer object.
 func (c *Context) CreateFramebuffer() *FrameBuffer {
-	return &FrameBuffer{c.ctx.CreateFramebuffer()}
+	res := &FrameBuffer{c.ctx.CreateFramebuffer()}
+	c.created(res)
+	return res
 }
 
 // Creates an empty WebGLProgram object to which vector and fragment
 // WebGLShader objects can be bound.
 func (c *Context) CreateProgram() *Program {
-	return &Program{c.ctx.CreateProgram()}
+	res := &Program{c.ctx.CreateProgram()}
+	c.created(res)
+	return res
 }
 
 // Creates and returns a WebGLRenderbuffer object.
 func (c *Context) CreateRenderbuffer() *RenderBuffer {
-	return &RenderBuffer{c.ctx.CreateRenderbuffer()}
+	res := &RenderBuffer{c.ctx.CreateRenderbuffer()}
+	c.created(res)
+	return res
 }
 
 // CreateShader returns an empty vertex or fragment shader object based on the type specified.
 func (c *Context) CreateShader(typ int) *Shader {
 	shader := &Shader{c.ctx.CreateShader(gl.Enum(typ))}
+	c.created(shader)
 	return shader
 }
 
 // Used to generate a WebGLTexture object to which images can be bound.
 func (c *Context) CreateTexture() *Texture {
-	return &Texture{c.ctx.CreateTexture()}
+	res := &Texture{c.ctx.CreateTexture()}
+	c.created(res)
+	return res
 }
 
 // Sets whether or not front, back, or both facing facets are able to be culled.
@@ -855,6 +375,7 @@ func (c *Context) CullFace(mode int) {
 
 // Delete a specific buffer.
 func (c *Context) DeleteBuffer(buffer *Buffer) {
+	c.deleted(buffer)
 	c.ctx.DeleteBuffer(buffer.Buffer)
 }
 
@@ -862,6 +383,7 @@ func (c *Context) DeleteBuffer(buffer *Buffer) {
 // currently bound framebuffer, the default framebuffer will be bound.
 // Deleting a framebuffer detaches all of its attachments.
 func (c *Context) DeleteFramebuffer(framebuffer *FrameBuffer) {
+	c.deleted(framebuffer)
 	c.ctx.DeleteFramebuffer(framebuffer.Framebuffer)
 }
 
@@ -870,15 +392,19 @@ func (c *Context) DeleteFramebuffer(framebuffer *FrameBuffer) {
 // Any shader objects associated with the program will be detached.
 // They will be deleted if they were already flagged for deletion.
 func (c *Context) DeleteProgram(program *Program) {
+	c.deleted(program)
 	c.ctx.DeleteProgram(program.Program)
 }
 
 func (c *Context) DeleteVertexArray(vao *VertexArray) {
+	c.deleted(vao)
 	c.ctx.DeleteVertexArray(vao.VertexArray)
 }
 
 func (c *Context) CreateVertexArray() *VertexArray {
-	return &VertexArray{c.ctx.CreateVertexArray()}
+	res := &VertexArray{c.ctx.CreateVertexArray()}
+	c.created(res)
+	return res
 }
 
 func (c *Context) BindVertexArray(vao *VertexArray) {
@@ -893,16 +419,19 @@ func (c *Context) BindVertexArray(vao *VertexArray) {
 // currently bound, it will become unbound. If the renderbuffer is
 // attached to the currently bound framebuffer, it is detached.
 func (c *Context) DeleteRenderbuffer(renderbuffer *RenderBuffer) {
+	c.deleted(renderbuffer)
 	c.ctx.DeleteRenderbuffer(renderbuffer.Renderbuffer)
 }
 
 // Deletes a specific shader object.
 func (c *Context) DeleteShader(shader *Shader) {
+	c.deleted(shader)
 	c.ctx.DeleteShader(shader.Shader)
 }
 
 // Deletes a specific texture object.
 func (c *Context) DeleteTexture(texture *Texture) {
+	c.deleted(texture)
 	c.ctx.DeleteTexture(texture.Texture)
 }
 
@@ -962,7 +491,7 @@ func (c *Context) Flush() {
 
 // Attaches a WebGLRenderbuffer object as a logical buffer to the
 // currently bound WebGLFramebuffer object.
-func (c *Context) FrameBufferRenderBuffer(target, attachment, renderbufferTarget int, renderbuffer *RenderBuffer) {
+func (c *Context) FramebufferRenderbuffer(target, attachment, renderbufferTarget int, renderbuffer *RenderBuffer) {
 	c.ctx.FramebufferRenderbuffer(gl.Enum(target), gl.Enum(attachment), gl.Enum(renderbufferTarget), renderbuffer.Renderbuffer)
 }
 
@@ -1057,7 +586,7 @@ func (c *Context) GetRenderbufferParameter(target, pname int) int {
 }
 
 // Returns the value of the parameter associated with pname for a shader object.
-func (c *Context) GetShaderParameter(shader *Shader, pname int) int {
+func (c *Context) GetShaderParameteri(shader *Shader, pname int) int {
 	return c.ctx.GetShaderi(shader.Shader, gl.Enum(pname))
 }
 
@@ -1082,7 +611,7 @@ func (c *Context) GetShaderSource(shader *Shader) string {
 
 // Returns a slice of supported extension strings.
 func (c *Context) GetSupportedExtensions() []string {
-	return nil // TODO: extensions dont exist on mobile?
+	return strings.Fields(c.ctx.GetString(gl.EXTENSIONS))
 }
 
 // Returns the value for a parameter on an active texture unit.
@@ -1186,14 +715,11 @@ func (c *Context) PolygonOffset(factor, units float32) {
 	c.ctx.PolygonOffset(factor, units)
 }
 
-/*
-// TODO: Figure out if pixels should be a slice.
-// Reads pixel data into an ArrayBufferView object from a
-// rectangular area in the color buffer of the active frame buffer.
-func (c *Context) ReadPixels(x, y, width, height, format, typ int, pixels js.Object) {
-	c.ctx.ReadPixels(x, y, width, height, format, typ, pixels)
+// Reads pixel data into a []uint8 or a []float32 from a rectangular area
+// in the color buffer of the active frame buffer.
+func (c *Context) ReadPixels(x, y, width, height, format, typ int, pixels interface{}) {
+	c.ctx.ReadPixels(mobileBytes(pixels), x, y, width, height, gl.Enum(format), gl.Enum(typ))
 }
-*/
 
 // Creates or replaces the data store for the currently bound WebGLRenderbuffer object.
 func (c *Context) RenderbufferStorage(target, internalFormat, width, height int) {
@@ -1219,18 +745,7 @@ func (c *Context) TexImage2D(target, level, internalFormat, width, height, forma
 		log.Println("Warning: format and internalFormat should be the same for TexImage2D on mobile system")
 	}
 
-	switch t := data.(type) {
-	case []uint8:
-		buf := make([]byte, 0, len(t))
-		for _, c := range t {
-			buf = append(buf, byte(c))
-		}
-		c.ctx.TexImage2D(gl.Enum(target), level, width, height, gl.Enum(format), gl.Enum(kind), buf)
-	case []float32:
-		c.ctx.TexImage2D(gl.Enum(target), level, width, height, gl.Enum(format), gl.Enum(kind), *(*[]byte)(unsafe.Pointer(&t)))
-	default:
-		log.Println("Warning: TexImage2D does not support your requested type (yet)")
-	}
+	c.ctx.TexImage2D(gl.Enum(target), level, internalFormat, width, height, gl.Enum(format), gl.Enum(kind), mobileBytes(data))
 }
 
 // Sets texture parameters for the current texture unit.
@@ -1238,12 +753,10 @@ func (c *Context) TexParameteri(target int, pname int, param int) {
 	c.ctx.TexParameteri(gl.Enum(target), gl.Enum(pname), param)
 }
 
-/*
-// Replaces a portion of an existing 2D texture image with all of another image.
-func (c *Context) TexSubImage2D(gl.Enum(target), level, xoffset, yoffset, format, typ int, image js.Object) {
-	c.ctx.TexSubImage2D(gl.Enum(target), level, xoffset, yoffset, format, typ, image)
+// Replaces a portion of an existing 2D texture image with pixel data.
+func (c *Context) TexSubImage2D(target, level, xoffset, yoffset, width, height, format, kind int, data interface{}) {
+	c.ctx.TexSubImage2D(gl.Enum(target), level, xoffset, yoffset, width, height, gl.Enum(format), gl.Enum(kind), mobileBytes(data))
 }
-*/
 
 // Assigns a floating point value to a uniform variable for the current program object.
 func (c *Context) Uniform1f(location *UniformLocation, x float32) {
@@ -0,0 +1,1988 @@
+//+build !netgo,!android
+
+package glplus
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"unsafe"
+
+	"github.com/aubonbeurre/glplus/glsl"
+)
+
+// softBackend is the software backend: GL state lives in Go memory, draws are
+// rasterized on the CPU and shaders run through the glsl interpreter.
+type softBackend struct {
+	Enums
+
+	softState
+}
+
+var _ NativeBackend = (*softBackend)(nil)
+
+// Size of the default framebuffer of a software context.
+const (
+	SoftDefaultWidth  = 1024
+	SoftDefaultHeight = 768
+)
+
+// NewSoftBackend returns a software backend with a default framebuffer of
+// width x height pixels.
+func NewSoftBackend(width, height int) NativeBackend {
+	c := &softBackend{Enums: sEnums}
+	c.softState.init(c, width, height)
+	return c
+}
+
+const (
+	softMaxAttribs      = 16
+	softMaxTextureUnits = 32
+	softMaxTextureSize  = 8192
+	// the shaders cannot read uniform blocks, but the buffers can be bound
+	softMaxUniformBufferBindings = 24
+)
+
+type softBuffer struct {
+	data  []byte
+	usage int
+}
+
+type softShader struct {
+	id       uint32
+	typ      int
+	source   string
+	compiled *glsl.Shader
+	log      string
+}
+
+type softProgram struct {
+	shaders   []*softShader
+	bindings  map[string]int
+	linked    *glsl.Program
+	log       string
+	validated bool
+	// binary is the one of the linked program, kept as drivers do when
+	// PROGRAM_BINARY_RETRIEVABLE_HINT was set before the link
+	binary      []byte
+	retrievable bool
+}
+
+// softProgramBinaryFormat is the format of the program binaries of the
+// software backend, which hold the sources the program was linked from.
+const softProgramBinaryFormat = 0x474C50
+
+type softProgramBinary struct {
+	Vertex   string
+	Fragment string
+	Bindings map[string]int
+}
+
+// softImage holds RGBA texels, or depth values when channels is 1. Row 0 is
+// the bottom row, as in GL.
+type softImage struct {
+	width    int
+	height   int
+	channels int
+	float    bool
+	pix      []float32
+}
+
+func (img *softImage) alloc(width, height, channels int, float bool) {
+	img.width, img.height, img.channels, img.float = width, height, channels, float
+	img.pix = make([]float32, width*height*channels)
+}
+
+type softTexture struct {
+	id        uint32
+	img       softImage
+	minFilter int
+	magFilter int
+	wrapS     int
+	wrapT     int
+}
+
+type softRenderbuffer struct {
+	id     uint32
+	img    softImage
+	format int
+}
+
+type softFramebuffer struct {
+	colorTex *softTexture
+	colorRb  *softRenderbuffer
+	depthRb  *softRenderbuffer
+}
+
+func (fb *softFramebuffer) color() *softImage {
+	if fb.colorTex != nil {
+		return &fb.colorTex.img
+	}
+	if fb.colorRb != nil {
+		return &fb.colorRb.img
+	}
+	return nil
+}
+
+func (fb *softFramebuffer) depth() *softImage {
+	if fb.depthRb != nil && fb.depthRb.img.channels == 1 && fb.depthRb.img.pix != nil {
+		return &fb.depthRb.img
+	}
+	return nil
+}
+
+type softAttrib struct {
+	enabled    bool
+	divisor    int
+	buffer     *softBuffer
+	size       int
+	typ        int
+	normalized bool
+	stride     int
+	offset     int
+}
+
+type softVertexArray struct {
+	attribs  [softMaxAttribs]softAttrib
+	elements *softBuffer
+}
+
+// softState is the GL state machine of a software Context.
+type softState struct {
+	lastError int
+	nextID    uint32
+
+	buffers       map[uint32]*softBuffer
+	shaders       map[uint32]*softShader
+	programs      map[uint32]*softProgram
+	textures      map[uint32]*softTexture
+	renderbuffers map[uint32]*softRenderbuffer
+	framebuffers  map[uint32]*softFramebuffer
+	vertexArrays  map[uint32]*softVertexArray
+
+	program       *softProgram
+	vertexArray   *softVertexArray
+	arrayBuffer   *softBuffer
+	uniformBuffer *softBuffer
+	uniformBases  [softMaxUniformBufferBindings]*softBuffer
+	renderbuffer  *softRenderbuffer
+	framebuffer   *softFramebuffer
+	activeTexture int
+	textureUnits  [softMaxTextureUnits]*softTexture
+
+	caps               map[int]bool
+	blendColor         [4]float32
+	blendSrc           int
+	blendDst           int
+	blendSrcAlpha      int
+	blendDstAlpha      int
+	blendEquation      int
+	blendEquationAlpha int
+	clearColor         [4]float32
+	clearDepth         float32
+	clearStencil       int
+	colorMask          [4]bool
+	cullFace           int
+	frontFace          int
+	depthFunc          int
+	depthMask          bool
+	depthRange         [2]float32
+	lineWidth          float32
+	pixelStore         map[int]int
+	polygonOffset      [2]float32
+	sampleCoverage     float32
+	sampleInvert       bool
+	scissor            [4]int
+	stencilFunc        [2][3]int
+	viewport           [4]int
+}
+
+func (s *softState) init(c *softBackend, width, height int) {
+	s.lastError = c.NO_ERROR
+	s.buffers = make(map[uint32]*softBuffer)
+	s.shaders = make(map[uint32]*softShader)
+	s.programs = make(map[uint32]*softProgram)
+	s.textures = make(map[uint32]*softTexture)
+	s.renderbuffers = make(map[uint32]*softRenderbuffer)
+	s.framebuffers = make(map[uint32]*softFramebuffer)
+	s.vertexArrays = make(map[uint32]*softVertexArray)
+	s.caps = make(map[int]bool)
+
+	// the default objects are reachable through the zero handle
+	s.vertexArrays[0] = &softVertexArray{}
+	s.vertexArray = s.vertexArrays[0]
+	screen := &softFramebuffer{colorRb: &softRenderbuffer{}, depthRb: &softRenderbuffer{}}
+	screen.colorRb.img.alloc(width, height, 4, false)
+	screen.depthRb.img.alloc(width, height, 1, true)
+	for i := range screen.depthRb.img.pix {
+		screen.depthRb.img.pix[i] = 1
+	}
+	s.framebuffers[0] = screen
+	s.framebuffer = screen
+
+	s.blendSrc, s.blendSrcAlpha = c.ONE, c.ONE
+	s.blendDst, s.blendDstAlpha = c.ZERO, c.ZERO
+	s.blendEquation, s.blendEquationAlpha = c.FUNC_ADD, c.FUNC_ADD
+	s.clearDepth = 1
+	s.colorMask = [4]bool{true, true, true, true}
+	s.cullFace = c.BACK
+	s.frontFace = c.CCW
+	s.depthFunc = c.LESS
+	s.depthMask = true
+	s.depthRange = [2]float32{0, 1}
+	s.lineWidth = 1
+	s.pixelStore = map[int]int{c.PACK_ALIGNMENT: 4, c.UNPACK_ALIGNMENT: 4}
+	s.sampleCoverage = 1
+	s.scissor = [4]int{0, 0, width, height}
+	s.stencilFunc = [2][3]int{{c.ALWAYS, 0, -1}, {c.ALWAYS, 0, -1}}
+	s.viewport = [4]int{0, 0, width, height}
+}
+
+func (s *softState) genID() uint32 {
+	s.nextID++
+	return s.nextID
+}
+
+// LoseContext drops every object and resets the state, as WebGL does when
+// the context is lost. Handles are not reused, the old ones stay invalid.
+func (c *softBackend) LoseContext() {
+	screen := c.framebuffers[0].color()
+	nextID := c.nextID
+	c.softState = softState{}
+	c.softState.init(c, screen.width, screen.height)
+	c.nextID = nextID
+	c.lastError = c.CONTEXT_LOST_WEBGL
+}
+
+func (c *softBackend) setError(err int) {
+	if c.lastError == c.NO_ERROR {
+		c.lastError = err
+	}
+}
+
+func (c *softBackend) Version() string {
+	return "4.1 glplus software rasterizer"
+}
+
+func (c *softBackend) QueryCaps() Caps {
+	return Caps{
+		MaxTextureSize:      softMaxTextureSize,
+		MaxTextureUnits:     softMaxTextureUnits,
+		MaxVertexAttribs:    softMaxAttribs,
+		MaxRenderbufferSize: softMaxTextureSize,
+		GLSLVersion:         330,
+		VertexArrays:        true,
+		FloatTextures:       true,
+		Instancing:          true,
+		Uint32Indices:       true,
+		Driver:              driverString("glplus", "software rasterizer", c.Version()),
+		ProgramBinary:       true,
+	}
+}
+
+func (c *softBackend) GetError() int {
+	err := c.lastError
+	c.lastError = c.NO_ERROR
+	return err
+}
+
+func (c *softBackend) Ptr(data interface{}) unsafe.Pointer {
+	if data == nil {
+		return nil
+	}
+	v := reflect.ValueOf(data)
+	switch v.Kind() {
+	case reflect.Ptr:
+		return unsafe.Pointer(v.Pointer())
+	case reflect.Slice:
+		if v.Len() == 0 {
+			return nil
+		}
+		return unsafe.Pointer(v.Index(0).UnsafeAddr())
+	}
+	panic(fmt.Errorf("Ptr unsupported type %v", v.Type()))
+}
+
+func (c *softBackend) lookupProgram(program *Program) *softProgram {
+	if program == nil {
+		c.setError(c.INVALID_VALUE)
+		return nil
+	}
+	p, ok := c.programs[program.uint32]
+	if !ok {
+		c.setError(c.INVALID_VALUE)
+	}
+	return p
+}
+
+func (c *softBackend) lookupShader(shader *Shader) *softShader {
+	if shader == nil {
+		c.setError(c.INVALID_VALUE)
+		return nil
+	}
+	s, ok := c.shaders[shader.uint32]
+	if !ok {
+		c.setError(c.INVALID_VALUE)
+	}
+	return s
+}
+
+func (c *softBackend) DeleteProgram(program *Program) {
+	if program == nil {
+		return
+	}
+	delete(c.programs, program.uint32)
+}
+
+func (c *softBackend) GetProgramInfoLog(program *Program) string {
+	if p := c.lookupProgram(program); p != nil {
+		return p.log
+	}
+	return ""
+}
+
+func (c *softBackend) ValidateProgram(program *Program) {
+	if program == nil {
+		return
+	}
+	if p := c.lookupProgram(program); p != nil {
+		p.validated = p.linked != nil
+		if !p.validated {
+			p.log = "error: program is not successfully linked\n"
+		}
+	}
+}
+
+func (c *softBackend) GetProgramParameterb(program *Program, pname int) bool {
+	p := c.lookupProgram(program)
+	if p == nil {
+		return false
+	}
+	switch pname {
+	case c.LINK_STATUS:
+		return p.linked != nil
+	case c.VALIDATE_STATUS:
+		return p.validated
+	case c.DELETE_STATUS:
+		return false
+	}
+	c.setError(c.INVALID_ENUM)
+	return false
+}
+
+func (c *softBackend) GetProgramParameteri(program *Program, pname int) int {
+	p := c.lookupProgram(program)
+	if p == nil {
+		return 0
+	}
+	switch pname {
+	case c.ATTACHED_SHADERS:
+		return len(p.shaders)
+	case c.ACTIVE_ATTRIBUTES, c.ACTIVE_UNIFORMS, c.ACTIVE_ATTRIBUTE_MAX_LENGTH, c.ACTIVE_UNIFORM_MAX_LENGTH:
+		if p.linked == nil {
+			return 0
+		}
+		var names []string
+		if pname == c.ACTIVE_ATTRIBUTES || pname == c.ACTIVE_ATTRIBUTE_MAX_LENGTH {
+			for _, a := range p.linked.Attributes {
+				names = append(names, a.Name)
+			}
+		} else {
+			for _, u := range p.linked.Uniforms {
+				names = append(names, softUniformName(u))
+			}
+		}
+		if pname == c.ACTIVE_ATTRIBUTES || pname == c.ACTIVE_UNIFORMS {
+			return len(names)
+		}
+		var max int
+		for _, name := range names {
+			if len(name)+1 > max {
+				max = len(name) + 1
+			}
+		}
+		return max
+	case c.PROGRAM_BINARY_LENGTH:
+		return len(p.binary)
+	case int(c.INFO_LOG_LENGTH):
+		if p.log == "" {
+			return 0
+		}
+		return len(p.log) + 1
+	case c.LINK_STATUS, c.VALIDATE_STATUS, c.DELETE_STATUS:
+		if c.GetProgramParameterb(program, pname) {
+			return c.TRUE
+		}
+		return 0
+	}
+	c.setError(c.INVALID_ENUM)
+	return 0
+}
+
+func (c *softBackend) LinkProgram(program *Program) {
+	p := c.lookupProgram(program)
+	if p == nil {
+		return
+	}
+	p.linked, p.validated, p.log, p.binary = nil, false, "", nil
+
+	var vs, fs *glsl.Shader
+	var binary softProgramBinary
+	for _, s := range p.shaders {
+		if s.compiled == nil {
+			p.log = "error: linking with uncompiled shader\n"
+			return
+		}
+		if s.typ == c.VERTEX_SHADER {
+			vs, binary.Vertex = s.compiled, s.source
+		} else if s.typ == c.FRAGMENT_SHADER {
+			fs, binary.Fragment = s.compiled, s.source
+		}
+	}
+	linked, err := glsl.Link(vs, fs, p.bindings)
+	if err != nil {
+		p.log = err.Error() + "\n"
+		return
+	}
+	linked.SetSampler(softSampler{c})
+	p.linked = linked
+	if p.retrievable {
+		binary.Bindings = p.bindings
+		p.binary, _ = json.Marshal(&binary)
+	}
+}
+
+func (c *softBackend) GetProgramBinary(program *Program) (binary []byte, format int) {
+	p := c.lookupProgram(program)
+	if p == nil {
+		return nil, 0
+	}
+	if p.linked == nil {
+		c.setError(c.INVALID_OPERATION)
+		return nil, 0
+	}
+	return append([]byte(nil), p.binary...), softProgramBinaryFormat
+}
+
+func (c *softBackend) ProgramParameteri(program *Program, pname, value int) {
+	p := c.lookupProgram(program)
+	if p == nil {
+		return
+	}
+	if pname != c.PROGRAM_BINARY_RETRIEVABLE_HINT {
+		c.setError(c.INVALID_ENUM)
+		return
+	}
+	p.retrievable = value != 0
+}
+
+// ProgramBinary links the sources of a binary again, the shaders attached
+// to the program are left alone.
+func (c *softBackend) ProgramBinary(program *Program, format int, binary []byte) {
+	p := c.lookupProgram(program)
+	if p == nil {
+		return
+	}
+	if format != softProgramBinaryFormat {
+		c.setError(c.INVALID_ENUM)
+		return
+	}
+	p.linked, p.validated, p.log, p.binary = nil, false, "", nil
+
+	var b softProgramBinary
+	if err := json.Unmarshal(binary, &b); err != nil {
+		p.log = "error: invalid program binary\n"
+		return
+	}
+	vs, err := glsl.Compile(glsl.VertexStage, b.Vertex)
+	if err != nil {
+		p.log = "error: invalid program binary\n"
+		return
+	}
+	fs, err := glsl.Compile(glsl.FragmentStage, b.Fragment)
+	if err != nil {
+		p.log = "error: invalid program binary\n"
+		return
+	}
+	linked, err := glsl.Link(vs, fs, b.Bindings)
+	if err != nil {
+		p.log = "error: invalid program binary\n"
+		return
+	}
+	linked.SetSampler(softSampler{c})
+	p.linked = linked
+	p.binary = append([]byte(nil), binary...)
+}
+
+// The rasterizer has the vertex and fragment stages only.
+func (c *softBackend) DispatchCompute(x, y, z int) {
+	c.setError(c.INVALID_OPERATION)
+}
+
+func (c *softBackend) MemoryBarrier(barriers int) {
+	c.setError(c.INVALID_OPERATION)
+}
+
+func (c *softBackend) PatchParameteri(pname, value int) {
+	c.setError(c.INVALID_OPERATION)
+}
+
+func (c *softBackend) GetUniformLocation(program *Program, name string) *UniformLocation {
+	p := c.lookupProgram(program)
+	if p == nil {
+		return &UniformLocation{-1}
+	}
+	if p.linked == nil {
+		c.setError(c.INVALID_OPERATION)
+		return &UniformLocation{-1}
+	}
+	return &UniformLocation{int32(p.linked.UniformLocation(name))}
+}
+
+func (c *softBackend) GetAttribLocation(program *Program, name string) int {
+	p := c.lookupProgram(program)
+	if p == nil {
+		return -1
+	}
+	if p.linked == nil {
+		c.setError(c.INVALID_OPERATION)
+		return -1
+	}
+	return p.linked.AttribLocation(name)
+}
+
+func (c *softBackend) UseProgram(program *Program) {
+	if program == nil {
+		c.program = nil
+		return
+	}
+	p := c.lookupProgram(program)
+	if p == nil {
+		return
+	}
+	if p.linked == nil {
+		c.setError(c.INVALID_OPERATION)
+		return
+	}
+	c.program = p
+}
+
+// uniform stores values in the current program. isInt selects the
+// glUniform*i family, which also sets bools and samplers.
+func (c *softBackend) uniform(location *UniformLocation, isInt bool, values ...float32) {
+	if c.program == nil {
+		c.setError(c.INVALID_OPERATION)
+		return
+	}
+	if location == nil || location.int32 < 0 {
+		return
+	}
+	t, ok := c.program.linked.UniformType(int(location.int32))
+	if !ok || t.Kind.Components() != len(values) {
+		c.setError(c.INVALID_OPERATION)
+		return
+	}
+	scalar := t.Kind.Scalar()
+	if (isInt && scalar == glsl.Float && !t.Kind.IsMatrix()) || (!isInt && (scalar == glsl.Int || t.Kind.IsSampler())) {
+		c.setError(c.INVALID_OPERATION)
+		return
+	}
+	c.program.linked.SetUniform(int(location.int32), values)
+}
+
+func (c *softBackend) Uniform1f(location *UniformLocation, x float32) {
+	c.uniform(location, false, x)
+}
+
+// Assigns a integer value to a uniform variable for the current program object.
+func (c *softBackend) Uniform1i(location *UniformLocation, x int) {
+	c.uniform(location, true, float32(x))
+}
+
+func (c *softBackend) Uniform2f(location *UniformLocation, x, y float32) {
+	c.uniform(location, false, x, y)
+}
+
+func (c *softBackend) Uniform3f(location *UniformLocation, x, y, z float32) {
+	c.uniform(location, false, x, y, z)
+}
+
+func (c *softBackend) Uniform4f(location *UniformLocation, x, y, z, w float32) {
+	c.uniform(location, false, x, y, z, w)
+}
+
+func (c *softBackend) Uniform2i(location *UniformLocation, x, y int) {
+	c.uniform(location, true, float32(x), float32(y))
+}
+
+func (c *softBackend) Uniform3i(location *UniformLocation, x, y, z int) {
+	c.uniform(location, true, float32(x), float32(y), float32(z))
+}
+
+func (c *softBackend) Uniform4i(location *UniformLocation, x, y, z, w int) {
+	c.uniform(location, true, float32(x), float32(y), float32(z), float32(w))
+}
+
+func (c *softBackend) uniformMatrix(location *UniformLocation, n int, transpose bool, value []float32) {
+	if len(value) < n*n {
+		c.setError(c.INVALID_VALUE)
+		return
+	}
+	m := make([]float32, n*n)
+	for col := 0; col < n; col++ {
+		for row := 0; row < n; row++ {
+			if transpose {
+				m[col*n+row] = value[row*n+col]
+			} else {
+				m[col*n+row] = value[col*n+row]
+			}
+		}
+	}
+	c.uniform(location, false, m...)
+}
+
+func (c *softBackend) UniformMatrix2fv(location *UniformLocation, transpose bool, value []float32) {
+	c.uniformMatrix(location, 2, transpose, value)
+}
+
+func (c *softBackend) UniformMatrix3fv(location *UniformLocation, transpose bool, value []float32) {
+	c.uniformMatrix(location, 3, transpose, value)
+}
+
+func (c *softBackend) UniformMatrix4fv(location *UniformLocation, transpose bool, value []float32) {
+	c.uniformMatrix(location, 4, transpose, value)
+}
+
+func (c *softBackend) CreateProgram() *Program {
+	id := c.genID()
+	c.programs[id] = &softProgram{bindings: make(map[string]int)}
+	return &Program{id}
+}
+
+func (c *softBackend) AttachShader(program *Program, shader *Shader) {
+	p, s := c.lookupProgram(program), c.lookupShader(shader)
+	if p == nil || s == nil {
+		return
+	}
+	for _, other := range p.shaders {
+		if other == s {
+			c.setError(c.INVALID_OPERATION)
+			return
+		}
+	}
+	p.shaders = append(p.shaders, s)
+}
+
+func (c *softBackend) DetachShader(program *Program, shader *Shader) {
+	p, s := c.lookupProgram(program), c.lookupShader(shader)
+	if p == nil || s == nil {
+		return
+	}
+	for i, other := range p.shaders {
+		if other == s {
+			p.shaders = append(p.shaders[:i], p.shaders[i+1:]...)
+			return
+		}
+	}
+	c.setError(c.INVALID_OPERATION)
+}
+
+func (c *softBackend) GetAttachedShaders(program *Program) []*Shader {
+	p := c.lookupProgram(program)
+	if p == nil {
+		return nil
+	}
+	var shaders []*Shader
+	for _, s := range p.shaders {
+		shaders = append(shaders, &Shader{s.id})
+	}
+	return shaders
+}
+
+func (c *softBackend) IsProgram(program *Program) bool {
+	if program == nil {
+		return false
+	}
+	_, ok := c.programs[program.uint32]
+	return ok
+}
+
+// softUniformName is the name GetActiveUniform gives, arrays end in [0].
+func softUniformName(u *glsl.Uniform) string {
+	if u.Type.Array > 0 {
+		return u.Name + "[0]"
+	}
+	return u.Name
+}
+
+// typeEnum is the GL type of a GLSL type, as GetActiveUniform returns it.
+func (c *softBackend) typeEnum(kind glsl.Kind) int {
+	switch kind {
+	case glsl.Bool:
+		return c.BOOL
+	case glsl.Int:
+		return c.INT
+	case glsl.BVec2:
+		return c.BOOL_VEC2
+	case glsl.BVec3:
+		return c.BOOL_VEC3
+	case glsl.BVec4:
+		return c.BOOL_VEC4
+	case glsl.IVec2:
+		return c.INT_VEC2
+	case glsl.IVec3:
+		return c.INT_VEC3
+	case glsl.IVec4:
+		return c.INT_VEC4
+	case glsl.Vec2:
+		return c.FLOAT_VEC2
+	case glsl.Vec3:
+		return c.FLOAT_VEC3
+	case glsl.Vec4:
+		return c.FLOAT_VEC4
+	case glsl.Mat2:
+		return c.FLOAT_MAT2
+	case glsl.Mat3:
+		return c.FLOAT_MAT3
+	case glsl.Mat4:
+		return c.FLOAT_MAT4
+	case glsl.Sampler2D:
+		return c.SAMPLER_2D
+	case glsl.SamplerCube:
+		return c.SAMPLER_CUBE
+	}
+	return c.FLOAT
+}
+
+func (c *softBackend) GetActiveAttrib(program *Program, index int) (name string, size, typ int) {
+	p := c.lookupProgram(program)
+	if p == nil {
+		return "", 0, 0
+	}
+	if p.linked == nil || index < 0 || index >= len(p.linked.Attributes) {
+		c.setError(c.INVALID_VALUE)
+		return "", 0, 0
+	}
+	a := p.linked.Attributes[index]
+	size = a.Type.Array
+	if size == 0 {
+		size = 1
+	}
+	return a.Name, size, c.typeEnum(a.Type.Kind)
+}
+
+func (c *softBackend) GetActiveUniform(program *Program, index int) (name string, size, typ int) {
+	p := c.lookupProgram(program)
+	if p == nil {
+		return "", 0, 0
+	}
+	if p.linked == nil || index < 0 || index >= len(p.linked.Uniforms) {
+		c.setError(c.INVALID_VALUE)
+		return "", 0, 0
+	}
+	u := p.linked.Uniforms[index]
+	size = u.Type.Array
+	if size == 0 {
+		size = 1
+	}
+	return softUniformName(u), size, c.typeEnum(u.Type.Kind)
+}
+
+func (c *softBackend) CreateShader(typ int) *Shader {
+	if typ != c.VERTEX_SHADER && typ != c.FRAGMENT_SHADER {
+		c.setError(c.INVALID_ENUM)
+		return &Shader{0}
+	}
+	id := c.genID()
+	c.shaders[id] = &softShader{id: id, typ: typ}
+	return &Shader{id}
+}
+
+func (c *softBackend) ShaderSource(shader *Shader, source string) {
+	if s := c.lookupShader(shader); s != nil {
+		s.source = source
+	}
+}
+
+func (c *softBackend) CompileShader(shader *Shader) {
+	s := c.lookupShader(shader)
+	if s == nil {
+		return
+	}
+	stage := glsl.VertexStage
+	if s.typ == c.FRAGMENT_SHADER {
+		stage = glsl.FragmentStage
+	}
+	var err error
+	s.log = ""
+	if s.compiled, err = glsl.Compile(stage, s.source); err != nil {
+		s.log = err.Error() + "\n"
+	}
+}
+
+func (c *softBackend) GetShaderiv(shader *Shader, pname uint32) bool {
+	s := c.lookupShader(shader)
+	if s == nil {
+		return false
+	}
+	switch pname {
+	case c.COMPILE_STATUS:
+		return s.compiled != nil
+	case uint32(c.DELETE_STATUS):
+		return false
+	}
+	c.setError(c.INVALID_ENUM)
+	return false
+}
+
+func (c *softBackend) GetShaderParameterb(shader *Shader, pname int) bool {
+	return c.GetShaderParameteri(shader, pname) == c.TRUE
+}
+
+func (c *softBackend) GetShaderParameteri(shader *Shader, pname int) int {
+	s := c.lookupShader(shader)
+	if s == nil {
+		return 0
+	}
+	switch pname {
+	case c.SHADER_TYPE:
+		return s.typ
+	case int(c.COMPILE_STATUS):
+		if s.compiled != nil {
+			return c.TRUE
+		}
+		return 0
+	case c.DELETE_STATUS:
+		return 0
+	case int(c.INFO_LOG_LENGTH):
+		if s.log == "" {
+			return 0
+		}
+		return len(s.log) + 1
+	case c.SHADER_SOURCE_LENGTH:
+		if s.source == "" {
+			return 0
+		}
+		return len(s.source) + 1
+	}
+	c.setError(c.INVALID_ENUM)
+	return 0
+}
+
+func (c *softBackend) GetShaderSource(shader *Shader) string {
+	if s := c.lookupShader(shader); s != nil {
+		return s.source
+	}
+	return ""
+}
+
+func (c *softBackend) IsShader(shader *Shader) bool {
+	if shader == nil {
+		return false
+	}
+	_, ok := c.shaders[shader.uint32]
+	return ok
+}
+
+func (c *softBackend) GetShaderInfoLog(shader *Shader) string {
+	if s := c.lookupShader(shader); s != nil {
+		return s.log
+	}
+	return ""
+}
+
+func (c *softBackend) BindAttribLocation(program *Program, index int, name string) {
+	if index < 0 || index >= softMaxAttribs {
+		c.setError(c.INVALID_VALUE)
+		return
+	}
+	if p := c.lookupProgram(program); p != nil {
+		p.bindings[name] = index
+	}
+}
+
+func (c *softBackend) DeleteShader(shader *Shader) {
+	if shader == nil {
+		return
+	}
+	// attached shaders stay referenced by their programs
+	delete(c.shaders, shader.uint32)
+}
+
+func (c *softBackend) DeleteTexture(texture *Texture) {
+	if texture == nil || texture.uint32 == 0 {
+		return
+	}
+	t := c.textures[texture.uint32]
+	for i, bound := range c.textureUnits {
+		if bound == t {
+			c.textureUnits[i] = nil
+		}
+	}
+	delete(c.textures, texture.uint32)
+}
+
+func (c *softBackend) DeleteBuffer(buffer *Buffer) {
+	if buffer == nil || buffer.uint32 == 0 {
+		return
+	}
+	b := c.buffers[buffer.uint32]
+	if c.arrayBuffer == b {
+		c.arrayBuffer = nil
+	}
+	if c.uniformBuffer == b {
+		c.uniformBuffer = nil
+	}
+	for i := range c.uniformBases {
+		if c.uniformBases[i] == b {
+			c.uniformBases[i] = nil
+		}
+	}
+	if c.vertexArray.elements == b {
+		c.vertexArray.elements = nil
+	}
+	delete(c.buffers, buffer.uint32)
+}
+
+func (c *softBackend) DeleteVertexArray(vao *VertexArray) {
+	if vao == nil || vao.uint32 == 0 {
+		return
+	}
+	if c.vertexArray == c.vertexArrays[vao.uint32] {
+		c.vertexArray = c.vertexArrays[0]
+	}
+	delete(c.vertexArrays, vao.uint32)
+}
+
+func (c *softBackend) CreateVertexArray() *VertexArray {
+	id := c.genID()
+	c.vertexArrays[id] = &softVertexArray{}
+	return &VertexArray{id}
+}
+
+func (c *softBackend) BindVertexArray(vao *VertexArray) {
+	var id uint32
+	if vao != nil {
+		id = vao.uint32
+	}
+	v, ok := c.vertexArrays[id]
+	if !ok {
+		c.setError(c.INVALID_OPERATION)
+		return
+	}
+	c.vertexArray = v
+}
+
+func (c *softBackend) EnableVertexAttribArray(index int) {
+	if index < 0 || index >= softMaxAttribs {
+		c.setError(c.INVALID_VALUE)
+		return
+	}
+	c.vertexArray.attribs[index].enabled = true
+}
+
+func (c *softBackend) VertexAttribDivisor(index, divisor int) {
+	if index < 0 || index >= softMaxAttribs || divisor < 0 {
+		c.setError(c.INVALID_VALUE)
+		return
+	}
+	c.vertexArray.attribs[index].divisor = divisor
+}
+
+func (c *softBackend) DisableVertexAttribArray(index int) {
+	if index < 0 || index >= softMaxAttribs {
+		c.setError(c.INVALID_VALUE)
+		return
+	}
+	c.vertexArray.attribs[index].enabled = false
+}
+
+func (c *softBackend) VertexAttribPointer(index, size, typ int, normal bool, stride int, offset int) {
+	if index < 0 || index >= softMaxAttribs || size < 1 || size > 4 || stride < 0 {
+		c.setError(c.INVALID_VALUE)
+		return
+	}
+	if c.typeSize(typ) == 0 {
+		c.setError(c.INVALID_ENUM)
+		return
+	}
+	if c.arrayBuffer == nil && offset != 0 {
+		c.setError(c.INVALID_OPERATION)
+		return
+	}
+	c.vertexArray.attribs[index] = softAttrib{
+		enabled:    c.vertexArray.attribs[index].enabled,
+		divisor:    c.vertexArray.attribs[index].divisor,
+		buffer:     c.arrayBuffer,
+		size:       size,
+		typ:        typ,
+		normalized: normal,
+		stride:     stride,
+		offset:     offset,
+	}
+}
+
+func (c *softBackend) GetVertexAttribOffset(index, pname int) int {
+	if index < 0 || index >= softMaxAttribs {
+		c.setError(c.INVALID_VALUE)
+		return 0
+	}
+	if pname != c.VERTEX_ATTRIB_ARRAY_POINTER {
+		c.setError(c.INVALID_ENUM)
+		return 0
+	}
+	return c.vertexArray.attribs[index].offset
+}
+
+func (c *softBackend) Enable(flag int) {
+	c.caps[flag] = true
+}
+
+func (c *softBackend) Disable(flag int) {
+	c.caps[flag] = false
+}
+
+func (c *softBackend) IsEnabled(flag int) bool {
+	return c.caps[flag]
+}
+
+func (c *softBackend) BlendColor(r, g, b, a float32) {
+	c.blendColor = [4]float32{clamp01(r), clamp01(g), clamp01(b), clamp01(a)}
+}
+
+func (c *softBackend) BlendFunc(src, dst int) {
+	c.BlendFuncSeparate(src, dst, src, dst)
+}
+
+func (c *softBackend) BlendFuncSeparate(srcRGB, dstRGB, srcAlpha, dstAlpha int) {
+	c.blendSrc, c.blendDst = srcRGB, dstRGB
+	c.blendSrcAlpha, c.blendDstAlpha = srcAlpha, dstAlpha
+}
+
+func (c *softBackend) BlendEquation(mode int) {
+	c.BlendEquationSeparate(mode, mode)
+}
+
+func (c *softBackend) BlendEquationSeparate(modeRGB, modeAlpha int) {
+	for _, mode := range []int{modeRGB, modeAlpha} {
+		if mode != c.FUNC_ADD && mode != c.FUNC_SUBTRACT && mode != c.FUNC_REVERSE_SUBTRACT {
+			c.setError(c.INVALID_ENUM)
+			return
+		}
+	}
+	c.blendEquation, c.blendEquationAlpha = modeRGB, modeAlpha
+}
+
+func (c *softBackend) ClearDepth(depth float32) {
+	c.clearDepth = clamp01(depth)
+}
+
+func (c *softBackend) ClearStencil(s int) {
+	// there is no stencil buffer, the value is only kept
+	c.clearStencil = s
+}
+
+func (c *softBackend) ColorMask(r, g, b, a bool) {
+	c.colorMask = [4]bool{r, g, b, a}
+}
+
+func (c *softBackend) CullFace(mode int) {
+	if mode != c.FRONT && mode != c.BACK && mode != c.FRONT_AND_BACK {
+		c.setError(c.INVALID_ENUM)
+		return
+	}
+	c.cullFace = mode
+}
+
+func (c *softBackend) FrontFace(mode int) {
+	if mode != c.CW && mode != c.CCW {
+		c.setError(c.INVALID_ENUM)
+		return
+	}
+	c.frontFace = mode
+}
+
+func (c *softBackend) DepthFunc(fn int) {
+	if fn < c.NEVER || fn > c.ALWAYS {
+		c.setError(c.INVALID_ENUM)
+		return
+	}
+	c.depthFunc = fn
+}
+
+func (c *softBackend) DepthMask(flag bool) {
+	c.depthMask = flag
+}
+
+func (c *softBackend) DepthRange(zNear, zFar float32) {
+	c.depthRange = [2]float32{clamp01(zNear), clamp01(zFar)}
+}
+
+func (c *softBackend) LineWidth(width float32) {
+	if width <= 0 {
+		c.setError(c.INVALID_VALUE)
+		return
+	}
+	// lines are always one pixel wide
+	c.lineWidth = width
+}
+
+func (c *softBackend) PixelStorei(pname, param int) {
+	if pname != c.PACK_ALIGNMENT && pname != c.UNPACK_ALIGNMENT {
+		c.setError(c.INVALID_ENUM)
+		return
+	}
+	if param != 1 && param != 2 && param != 4 && param != 8 {
+		c.setError(c.INVALID_VALUE)
+		return
+	}
+	// rows are always tightly packed
+	c.pixelStore[pname] = param
+}
+
+func (c *softBackend) PolygonOffset(factor, units float32) {
+	// the depth values are not offset, the values are only kept
+	c.polygonOffset = [2]float32{factor, units}
+}
+
+func (c *softBackend) SampleCoverage(value float32, invert bool) {
+	// there is no multisampling, the values are only kept
+	c.sampleCoverage, c.sampleInvert = clamp01(value), invert
+}
+
+func (c *softBackend) Scissor(x, y, width, height int) {
+	if width < 0 || height < 0 {
+		c.setError(c.INVALID_VALUE)
+		return
+	}
+	c.scissor = [4]int{x, y, width, height}
+}
+
+func (c *softBackend) StencilFunc(fn, ref, mask int) {
+	c.StencilFuncSeparate(c.FRONT_AND_BACK, fn, ref, mask)
+}
+
+func (c *softBackend) StencilFuncSeparate(face, fn, ref, mask int) {
+	if face != c.FRONT && face != c.BACK && face != c.FRONT_AND_BACK {
+		c.setError(c.INVALID_ENUM)
+		return
+	}
+	if fn < c.NEVER || fn > c.ALWAYS {
+		c.setError(c.INVALID_ENUM)
+		return
+	}
+	// there is no stencil buffer, the values are only kept
+	if face != c.BACK {
+		c.stencilFunc[0] = [3]int{fn, ref, mask}
+	}
+	if face != c.FRONT {
+		c.stencilFunc[1] = [3]int{fn, ref, mask}
+	}
+}
+
+func (c *softBackend) CreateBuffer() *Buffer {
+	id := c.genID()
+	c.buffers[id] = &softBuffer{}
+	return &Buffer{id}
+}
+
+func (c *softBackend) BindBuffer(target int, buffer *Buffer) {
+	var b *softBuffer
+	if buffer != nil && buffer.uint32 != 0 {
+		var ok bool
+		if b, ok = c.buffers[buffer.uint32]; !ok {
+			c.setError(c.INVALID_OPERATION)
+			return
+		}
+	}
+	switch target {
+	case c.ARRAY_BUFFER:
+		c.arrayBuffer = b
+	case c.ELEMENT_ARRAY_BUFFER:
+		c.vertexArray.elements = b
+	case c.UNIFORM_BUFFER:
+		c.uniformBuffer = b
+	default:
+		c.setError(c.INVALID_ENUM)
+	}
+}
+
+func (c *softBackend) BindBufferBase(target, index int, buffer *Buffer) {
+	if target != c.UNIFORM_BUFFER {
+		c.setError(c.INVALID_ENUM)
+		return
+	}
+	if index < 0 || index >= len(c.uniformBases) {
+		c.setError(c.INVALID_VALUE)
+		return
+	}
+	var b *softBuffer
+	if buffer != nil && buffer.uint32 != 0 {
+		var ok bool
+		if b, ok = c.buffers[buffer.uint32]; !ok {
+			c.setError(c.INVALID_OPERATION)
+			return
+		}
+	}
+	c.uniformBuffer, c.uniformBases[index] = b, b
+}
+
+// GetUniformBlockIndex finds no block: the shaders have none.
+func (c *softBackend) GetUniformBlockIndex(program *Program, name string) int {
+	c.lookupProgram(program)
+	return c.INVALID_INDEX
+}
+
+func (c *softBackend) UniformBlockBinding(program *Program, blockIndex, binding int) {
+	if c.lookupProgram(program) == nil {
+		return
+	}
+	c.setError(c.INVALID_VALUE)
+}
+
+func (c *softBackend) boundBuffer(target int) *softBuffer {
+	switch target {
+	case c.ARRAY_BUFFER:
+		return c.arrayBuffer
+	case c.ELEMENT_ARRAY_BUFFER:
+		return c.vertexArray.elements
+	case c.UNIFORM_BUFFER:
+		return c.uniformBuffer
+	}
+	c.setError(c.INVALID_ENUM)
+	return nil
+}
+
+// softBytes returns a copy of the memory of a slice of numbers.
+func softBytes(data interface{}) []byte {
+	v := reflect.ValueOf(data)
+	if v.Kind() != reflect.Slice {
+		panic(fmt.Errorf("BufferData Unknown type %v", v.Type()))
+	}
+	size := v.Len() * int(v.Type().Elem().Size())
+	if size == 0 {
+		return nil
+	}
+	src := (*[1 << 30]byte)(unsafe.Pointer(v.Index(0).UnsafeAddr()))[:size:size]
+	return append([]byte(nil), src...)
+}
+
+func (c *softBackend) BufferData(target int, data interface{}, usage int) {
+	b := c.boundBuffer(target)
+	if b == nil {
+		c.setError(c.INVALID_OPERATION)
+		return
+	}
+	if size, ok := data.(int); ok {
+		b.data, b.usage = make([]byte, size), usage
+		return
+	}
+	b.data, b.usage = softBytes(data), usage
+}
+
+func (c *softBackend) BufferSubData(target int, offset int, data interface{}) {
+	b := c.boundBuffer(target)
+	if b == nil {
+		c.setError(c.INVALID_OPERATION)
+		return
+	}
+	src := softBytes(data)
+	if offset < 0 || offset+len(src) > len(b.data) {
+		c.setError(c.INVALID_VALUE)
+		return
+	}
+	copy(b.data[offset:], src)
+}
+
+func (c *softBackend) GetBufferParameter(target, pname int) int {
+	b := c.boundBuffer(target)
+	if b == nil {
+		c.setError(c.INVALID_OPERATION)
+		return 0
+	}
+	switch pname {
+	case c.BUFFER_SIZE:
+		return len(b.data)
+	case c.BUFFER_USAGE:
+		return b.usage
+	}
+	c.setError(c.INVALID_ENUM)
+	return 0
+}
+
+func (c *softBackend) IsBuffer(buffer *Buffer) bool {
+	if buffer == nil {
+		return false
+	}
+	_, ok := c.buffers[buffer.uint32]
+	return ok
+}
+
+func (c *softBackend) DrawElements(mode, count, typ, offset int) {
+	c.DrawElementsInstanced(mode, count, typ, offset, 1)
+}
+
+func (c *softBackend) DrawElementsInstanced(mode, count, typ, offset, instances int) {
+	if count < 0 || instances < 0 {
+		c.setError(c.INVALID_VALUE)
+		return
+	}
+	elements := c.vertexArray.elements
+	size := c.typeSize(typ)
+	if typ != c.UNSIGNED_BYTE && typ != c.UNSIGNED_SHORT && typ != c.UNSIGNED_INT {
+		c.setError(c.INVALID_ENUM)
+		return
+	}
+	if elements == nil || offset < 0 || offset+count*size > len(elements.data) {
+		c.setError(c.INVALID_OPERATION)
+		return
+	}
+	indices := make([]int, count)
+	for i := range indices {
+		p := elements.data[offset+i*size:]
+		switch size {
+		case 1:
+			indices[i] = int(p[0])
+		case 2:
+			indices[i] = int(binary.LittleEndian.Uint16(p))
+		default:
+			indices[i] = int(binary.LittleEndian.Uint32(p))
+		}
+	}
+	c.draw(mode, indices, instances)
+}
+
+func (c *softBackend) ClearColor(r, g, b, a float32) {
+	c.clearColor = [4]float32{r, g, b, a}
+}
+
+func (c *softBackend) Clear(flags int) {
+	img := c.framebuffer.color()
+	if img == nil || img.pix == nil {
+		return
+	}
+	x0, y0, x1, y1 := c.scissorBounds()
+	if flags&c.COLOR_BUFFER_BIT != 0 {
+		value := c.clearColor
+		if !img.float {
+			for i := range value {
+				value[i] = clamp01(value[i])
+			}
+		}
+		for y := y0; y < y1; y++ {
+			for x := x0; x < x1; x++ {
+				px := img.pix[(y*img.width+x)*4:]
+				for i := range value {
+					if c.colorMask[i] {
+						px[i] = value[i]
+					}
+				}
+			}
+		}
+	}
+	if flags&c.DEPTH_BUFFER_BIT != 0 && c.depthMask {
+		if depth := c.framebuffer.depth(); depth != nil {
+			for y := y0; y < y1 && y < depth.height; y++ {
+				for x := x0; x < x1 && x < depth.width; x++ {
+					depth.pix[y*depth.width+x] = c.clearDepth
+				}
+			}
+		}
+	}
+}
+
+func (c *softBackend) Viewport(x, y, width, height int) {
+	if width < 0 || height < 0 {
+		c.setError(c.INVALID_VALUE)
+		return
+	}
+	c.viewport = [4]int{x, y, width, height}
+}
+
+func (c *softBackend) CreateTexture() *Texture {
+	id := c.genID()
+	c.textures[id] = &softTexture{
+		id:        id,
+		minFilter: c.NEAREST_MIPMAP_LINEAR,
+		magFilter: c.LINEAR,
+		wrapS:     c.REPEAT,
+		wrapT:     c.REPEAT,
+	}
+	return &Texture{id}
+}
+
+func (c *softBackend) BindTexture(target int, texture *Texture) {
+	if target != c.TEXTURE_2D {
+		c.setError(c.INVALID_ENUM)
+		return
+	}
+	var t *softTexture
+	if texture != nil && texture.uint32 != 0 {
+		var ok bool
+		if t, ok = c.textures[texture.uint32]; !ok {
+			c.setError(c.INVALID_OPERATION)
+			return
+		}
+	}
+	c.textureUnits[c.activeTexture] = t
+}
+
+func (c *softBackend) ActiveTexture(texture int) {
+	unit := texture - c.TEXTURE0
+	if unit < 0 || unit >= softMaxTextureUnits {
+		c.setError(c.INVALID_ENUM)
+		return
+	}
+	c.activeTexture = unit
+}
+
+func (c *softBackend) boundTexture(target int) *softTexture {
+	if target != c.TEXTURE_2D {
+		c.setError(c.INVALID_ENUM)
+		return nil
+	}
+	t := c.textureUnits[c.activeTexture]
+	if t == nil {
+		c.setError(c.INVALID_OPERATION)
+	}
+	return t
+}
+
+func (c *softBackend) TexParameteri(target int, pname int, param int) {
+	t := c.boundTexture(target)
+	if t == nil {
+		return
+	}
+	switch pname {
+	case c.TEXTURE_MIN_FILTER:
+		t.minFilter = param
+	case c.TEXTURE_MAG_FILTER:
+		t.magFilter = param
+	case c.TEXTURE_WRAP_S:
+		t.wrapS = param
+	case c.TEXTURE_WRAP_T:
+		t.wrapT = param
+	default:
+		c.setError(c.INVALID_ENUM)
+	}
+}
+
+func (c *softBackend) TexImage2D(target, level, internalFormat, width, height, format, kind int, data interface{}) {
+	t := c.boundTexture(target)
+	if t == nil {
+		return
+	}
+	if width < 0 || height < 0 || level < 0 || width > softMaxTextureSize || height > softMaxTextureSize {
+		c.setError(c.INVALID_VALUE)
+		return
+	}
+	if level > 0 {
+		// mipmaps are not sampled, the base level is used for minification
+		return
+	}
+	pix, ok := c.texels(width, height, format, kind, data)
+	if !ok {
+		return
+	}
+	img := &t.img
+	img.alloc(width, height, 4, internalFormat == c.RGBA32F || internalFormat == c.R32F)
+	if pix != nil {
+		copy(img.pix, pix)
+	}
+}
+
+// texels converts width x height pixels of data to RGBA, it returns nil for
+// nil data.
+func (c *softBackend) texels(width, height, format, kind int, data interface{}) ([]float32, bool) {
+	var channels int
+	switch format {
+	case c.RED, c.LUMINANCE:
+		channels = 1
+	case c.LUMINANCE_ALPHA:
+		channels = 2
+	case c.RGB:
+		channels = 3
+	case c.RGBA:
+		channels = 4
+	default:
+		c.setError(c.INVALID_ENUM)
+		return nil, false
+	}
+
+	var get func(i int) float32
+	switch pix := data.(type) {
+	case nil:
+		return nil, true
+	case []uint8:
+		if kind != c.UNSIGNED_BYTE || len(pix) < width*height*channels {
+			c.setError(c.INVALID_OPERATION)
+			return nil, false
+		}
+		get = func(i int) float32 { return float32(pix[i]) / 255 }
+	case []float32:
+		if kind != c.FLOAT || len(pix) < width*height*channels {
+			c.setError(c.INVALID_OPERATION)
+			return nil, false
+		}
+		get = func(i int) float32 { return pix[i] }
+	default:
+		panic(fmt.Errorf("TexImage2D Unknown type %v", pix))
+	}
+
+	rgba := make([]float32, width*height*4)
+	for i := 0; i < width*height; i++ {
+		px, j := rgba[i*4:i*4+4], i*channels
+		switch format {
+		case c.RED:
+			px[0], px[1], px[2], px[3] = get(j), 0, 0, 1
+		case c.LUMINANCE:
+			px[0], px[1], px[2], px[3] = get(j), get(j), get(j), 1
+		case c.LUMINANCE_ALPHA:
+			px[0], px[1], px[2], px[3] = get(j), get(j), get(j), get(j+1)
+		case c.RGB:
+			px[0], px[1], px[2], px[3] = get(j), get(j+1), get(j+2), 1
+		default:
+			px[0], px[1], px[2], px[3] = get(j), get(j+1), get(j+2), get(j+3)
+		}
+	}
+	return rgba, true
+}
+
+func (c *softBackend) TexSubImage2D(target, level, xoffset, yoffset, width, height, format, kind int, data interface{}) {
+	t := c.boundTexture(target)
+	if t == nil {
+		return
+	}
+	img := &t.img
+	if level < 0 || xoffset < 0 || yoffset < 0 || width < 0 || height < 0 ||
+		(level == 0 && (xoffset+width > img.width || yoffset+height > img.height)) {
+		c.setError(c.INVALID_VALUE)
+		return
+	}
+	pix, ok := c.texels(width, height, format, kind, data)
+	if !ok || level > 0 || pix == nil {
+		return
+	}
+	for j := 0; j < height; j++ {
+		copy(img.pix[((yoffset+j)*img.width+xoffset)*4:], pix[j*width*4:(j+1)*width*4])
+	}
+}
+
+// readColor returns the RGBA pixels of a rectangle of the read framebuffer,
+// the pixels outside of it are zero.
+func (c *softBackend) readColor(x, y, width, height int) []float32 {
+	img := c.framebuffer.color()
+	if img == nil || img.pix == nil {
+		c.setError(c.INVALID_FRAMEBUFFER_OPERATION)
+		return nil
+	}
+	pix := make([]float32, width*height*4)
+	for j := 0; j < height; j++ {
+		for i := 0; i < width; i++ {
+			if sx, sy := x+i, y+j; sx >= 0 && sy >= 0 && sx < img.width && sy < img.height {
+				copy(pix[(j*width+i)*4:(j*width+i)*4+4], img.pix[(sy*img.width+sx)*4:])
+			}
+		}
+	}
+	return pix
+}
+
+func (c *softBackend) CopyTexImage2D(target, level, internalFormat, x, y, width, height, border int) {
+	t := c.boundTexture(target)
+	if t == nil {
+		return
+	}
+	if width < 0 || height < 0 || level < 0 || border != 0 || width > softMaxTextureSize || height > softMaxTextureSize {
+		c.setError(c.INVALID_VALUE)
+		return
+	}
+	pix := c.readColor(x, y, width, height)
+	if pix == nil || level > 0 {
+		return
+	}
+	t.img.alloc(width, height, 4, internalFormat == c.RGBA32F || internalFormat == c.R32F)
+	copy(t.img.pix, pix)
+}
+
+func (c *softBackend) CopyTexSubImage2D(target, level, xoffset, yoffset, x, y, width, height int) {
+	t := c.boundTexture(target)
+	if t == nil {
+		return
+	}
+	img := &t.img
+	if level < 0 || xoffset < 0 || yoffset < 0 || width < 0 || height < 0 ||
+		(level == 0 && (xoffset+width > img.width || yoffset+height > img.height)) {
+		c.setError(c.INVALID_VALUE)
+		return
+	}
+	pix := c.readColor(x, y, width, height)
+	if pix == nil || level > 0 {
+		return
+	}
+	for j := 0; j < height; j++ {
+		copy(img.pix[((yoffset+j)*img.width+xoffset)*4:], pix[j*width*4:(j+1)*width*4])
+	}
+}
+
+func (c *softBackend) GenerateMipmap(target int) {
+	// mipmaps are not sampled, the base level is used for minification
+	c.boundTexture(target)
+}
+
+func (c *softBackend) IsTexture(texture *Texture) bool {
+	if texture == nil {
+		return false
+	}
+	_, ok := c.textures[texture.uint32]
+	return ok
+}
+
+func (c *softBackend) DeleteRenderbuffer(vao *RenderBuffer) {
+	if vao == nil || vao.uint32 == 0 {
+		return
+	}
+	if c.renderbuffer == c.renderbuffers[vao.uint32] {
+		c.renderbuffer = nil
+	}
+	delete(c.renderbuffers, vao.uint32)
+}
+
+func (c *softBackend) IsRenderbuffer(renderbuffer *RenderBuffer) bool {
+	if renderbuffer == nil {
+		return false
+	}
+	_, ok := c.renderbuffers[renderbuffer.uint32]
+	return ok
+}
+
+func (c *softBackend) GetRenderbufferParameter(target, pname int) int {
+	if target != c.RENDERBUFFER {
+		c.setError(c.INVALID_ENUM)
+		return 0
+	}
+	rb := c.renderbuffer
+	if rb == nil {
+		c.setError(c.INVALID_OPERATION)
+		return 0
+	}
+	switch pname {
+	case c.RENDERBUFFER_WIDTH:
+		return rb.img.width
+	case c.RENDERBUFFER_HEIGHT:
+		return rb.img.height
+	case c.RENDERBUFFER_INTERNAL_FORMAT:
+		return rb.format
+	}
+	c.setError(c.INVALID_ENUM)
+	return 0
+}
+
+func (c *softBackend) CreateRenderbuffer() *RenderBuffer {
+	id := c.genID()
+	c.renderbuffers[id] = &softRenderbuffer{id: id}
+	return &RenderBuffer{id}
+}
+
+func (c *softBackend) BindRenderbuffer(target int, vao *RenderBuffer) {
+	if target != c.RENDERBUFFER {
+		c.setError(c.INVALID_ENUM)
+		return
+	}
+	if vao == nil || vao.uint32 == 0 {
+		c.renderbuffer = nil
+		return
+	}
+	rb, ok := c.renderbuffers[vao.uint32]
+	if !ok {
+		c.setError(c.INVALID_OPERATION)
+		return
+	}
+	c.renderbuffer = rb
+}
+
+func (c *softBackend) DeleteFramebuffer(vao *FrameBuffer) {
+	if vao == nil || vao.uint32 == 0 {
+		return
+	}
+	if c.framebuffer == c.framebuffers[vao.uint32] {
+		c.framebuffer = c.framebuffers[0]
+	}
+	delete(c.framebuffers, vao.uint32)
+}
+
+func (c *softBackend) IsFramebuffer(framebuffer *FrameBuffer) bool {
+	if framebuffer == nil || framebuffer.uint32 == 0 {
+		return false
+	}
+	_, ok := c.framebuffers[framebuffer.uint32]
+	return ok
+}
+
+func (c *softBackend) GetFramebufferAttachmentParameter(target, attachment, pname int) int {
+	fb := c.userFramebuffer(target)
+	if fb == nil {
+		return 0
+	}
+	var objectType, objectName int
+	switch attachment {
+	case c.COLOR_ATTACHMENT0:
+		if fb.colorTex != nil {
+			objectType, objectName = c.TEXTURE, int(fb.colorTex.id)
+		} else if fb.colorRb != nil {
+			objectType, objectName = c.RENDERBUFFER, int(fb.colorRb.id)
+		}
+	case c.DEPTH_ATTACHMENT:
+		if fb.depthRb != nil {
+			objectType, objectName = c.RENDERBUFFER, int(fb.depthRb.id)
+		}
+	default:
+		c.setError(c.INVALID_ENUM)
+		return 0
+	}
+	switch pname {
+	case c.FRAMEBUFFER_ATTACHMENT_OBJECT_TYPE:
+		if objectType == 0 {
+			return c.NONE
+		}
+		return objectType
+	case c.FRAMEBUFFER_ATTACHMENT_OBJECT_NAME:
+		if objectType == 0 {
+			c.setError(c.INVALID_ENUM)
+		}
+		return objectName
+	case c.FRAMEBUFFER_ATTACHMENT_TEXTURE_LEVEL:
+		if objectType != c.TEXTURE {
+			c.setError(c.INVALID_ENUM)
+		}
+		return 0
+	}
+	c.setError(c.INVALID_ENUM)
+	return 0
+}
+
+func (c *softBackend) CreateFramebuffer() *FrameBuffer {
+	id := c.genID()
+	c.framebuffers[id] = &softFramebuffer{}
+	return &FrameBuffer{id}
+}
+
+func (c *softBackend) BindFramebuffer(target int, vao *FrameBuffer) {
+	if target != c.FRAMEBUFFER {
+		c.setError(c.INVALID_ENUM)
+		return
+	}
+	var id uint32
+	if vao != nil {
+		id = vao.uint32
+	}
+	fb, ok := c.framebuffers[id]
+	if !ok {
+		c.setError(c.INVALID_OPERATION)
+		return
+	}
+	c.framebuffer = fb
+}
+
+// userFramebuffer returns the bound framebuffer object, attachments of the
+// default framebuffer can't be changed.
+func (c *softBackend) userFramebuffer(target int) *softFramebuffer {
+	if target != c.FRAMEBUFFER {
+		c.setError(c.INVALID_ENUM)
+		return nil
+	}
+	if c.framebuffer == c.framebuffers[0] {
+		c.setError(c.INVALID_OPERATION)
+		return nil
+	}
+	return c.framebuffer
+}
+
+func (c *softBackend) FramebufferRenderbuffer(target, attachment, renderbuffertarget int, renderbuffer *RenderBuffer) {
+	fb := c.userFramebuffer(target)
+	if fb == nil {
+		return
+	}
+	var rb *softRenderbuffer
+	if renderbuffer != nil && renderbuffer.uint32 != 0 {
+		var ok bool
+		if rb, ok = c.renderbuffers[renderbuffer.uint32]; !ok || renderbuffertarget != c.RENDERBUFFER {
+			c.setError(c.INVALID_OPERATION)
+			return
+		}
+	}
+	switch attachment {
+	case c.COLOR_ATTACHMENT0:
+		fb.colorTex, fb.colorRb = nil, rb
+	case c.DEPTH_ATTACHMENT:
+		fb.depthRb = rb
+	default:
+		c.setError(c.INVALID_ENUM)
+	}
+}
+
+func (c *softBackend) CheckFramebufferStatus(target int) int {
+	if target != c.FRAMEBUFFER {
+		c.setError(c.INVALID_ENUM)
+		return 0
+	}
+	fb := c.framebuffer
+	color := fb.color()
+	if color == nil {
+		return c.FRAMEBUFFER_INCOMPLETE_MISSING_ATTACHMENT
+	}
+	if color.pix == nil || color.channels != 4 {
+		return c.FRAMEBUFFER_INCOMPLETE_ATTACHMENT
+	}
+	if fb.depthRb != nil && fb.depth() == nil {
+		return c.FRAMEBUFFER_INCOMPLETE_ATTACHMENT
+	}
+	return c.FRAMEBUFFER_COMPLETE
+}
+
+func (c *softBackend) RenderbufferStorage(target, internalFormat, width, height int) {
+	if target != c.RENDERBUFFER {
+		c.setError(c.INVALID_ENUM)
+		return
+	}
+	if c.renderbuffer == nil {
+		c.setError(c.INVALID_OPERATION)
+		return
+	}
+	if width < 0 || height < 0 || width > softMaxTextureSize || height > softMaxTextureSize {
+		c.setError(c.INVALID_VALUE)
+		return
+	}
+	img := &c.renderbuffer.img
+	c.renderbuffer.format = internalFormat
+	switch internalFormat {
+	case c.DEPTH_COMPONENT, c.DEPTH_COMPONENT16:
+		img.alloc(width, height, 1, true)
+		for i := range img.pix {
+			img.pix[i] = 1
+		}
+	case c.RGBA32F:
+		img.alloc(width, height, 4, true)
+	default:
+		img.alloc(width, height, 4, false)
+	}
+}
+
+func (c *softBackend) FramebufferTexture2D(target, attachment, textarget int, texture *Texture, level int) {
+	fb := c.userFramebuffer(target)
+	if fb == nil {
+		return
+	}
+	var t *softTexture
+	if texture != nil && texture.uint32 != 0 {
+		var ok bool
+		if t, ok = c.textures[texture.uint32]; !ok || textarget != c.TEXTURE_2D || level != 0 {
+			c.setError(c.INVALID_OPERATION)
+			return
+		}
+	}
+	if attachment != c.COLOR_ATTACHMENT0 {
+		c.setError(c.INVALID_ENUM)
+		return
+	}
+	fb.colorTex, fb.colorRb = t, nil
+}
+
+func (c *softBackend) DrawBuffer(buf int) {
+	if buf != c.COLOR_ATTACHMENT0 && buf != c.BACK && buf != c.FRONT && buf != c.NONE {
+		c.setError(c.INVALID_ENUM)
+	}
+}
+
+func (c *softBackend) Flush() {
+}
+
+func (c *softBackend) Finish() {
+}
+
+func (c *softBackend) GetSupportedExtensions() []string {
+	return nil
+}
+
+func (c *softBackend) ReadBuffer(src int) {
+	if src != c.COLOR_ATTACHMENT0 && src != c.BACK && src != c.FRONT {
+		c.setError(c.INVALID_ENUM)
+	}
+}
+
+func (c *softBackend) ReadPixels(x, y, width, height, format, typ int, pixels interface{}) {
+	if width < 0 || height < 0 {
+		c.setError(c.INVALID_VALUE)
+		return
+	}
+	var channels int
+	switch format {
+	case c.RGBA:
+		channels = 4
+	case c.RGB:
+		channels = 3
+	case c.RED:
+		channels = 1
+	default:
+		c.setError(c.INVALID_ENUM)
+		return
+	}
+	if typ != c.FLOAT && typ != c.UNSIGNED_BYTE {
+		c.setError(c.INVALID_ENUM)
+		return
+	}
+
+	n := width * height * channels
+	floats, _ := pixels.([]float32)
+	bytes, _ := pixels.([]uint8)
+	if (typ == c.FLOAT && len(floats) < n) || (typ == c.UNSIGNED_BYTE && len(bytes) < n) {
+		c.setError(c.INVALID_OPERATION)
+		return
+	}
+	pix := c.readColor(x, y, width, height)
+	if pix == nil {
+		return
+	}
+	for i := 0; i < width*height; i++ {
+		for k := 0; k < channels; k++ {
+			if typ == c.FLOAT {
+				floats[i*channels+k] = pix[i*4+k]
+			} else {
+				bytes[i*channels+k] = uint8(clamp01(pix[i*4+k])*255 + 0.5)
+			}
+		}
+	}
+}
+
+func (c *softBackend) DrawArrays(mode, first, count int) {
+	c.DrawArraysInstanced(mode, first, count, 1)
+}
+
+func (c *softBackend) DrawArraysInstanced(mode, first, count, instances int) {
+	if first < 0 || count < 0 || instances < 0 {
+		c.setError(c.INVALID_VALUE)
+		return
+	}
+	indices := make([]int, count)
+	for i := range indices {
+		indices[i] = first + i
+	}
+	c.draw(mode, indices, instances)
+}
+
+func (c *softBackend) typeSize(typ int) int {
+	switch typ {
+	case c.BYTE, c.UNSIGNED_BYTE:
+		return 1
+	case c.SHORT, c.UNSIGNED_SHORT, c.HALF_FLOAT:
+		return 2
+	case c.INT, c.UNSIGNED_INT, c.FLOAT:
+		return 4
+	}
+	return 0
+}
+
+func clamp01(x float32) float32 {
+	return float32(math.Max(0, math.Min(1, float64(x))))
+}
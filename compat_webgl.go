@@ -10,6 +10,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"strings"
 
 	"github.com/gopherjs/gopherjs/js"
 )
@@ -55,308 +56,28 @@ func DefaultAttributes() *ContextAttributes {
 	return &ContextAttributes{true, true, false, true, true, false}
 }
 
+// Context is the WebGL backend, it forwards to the rendering context of a
+// canvas.
 type Context struct {
+	Enums
 	*js.Object
-	ARRAY_BUFFER                                 int    `js:"ARRAY_BUFFER"`
-	ARRAY_BUFFER_BINDING                         int    `js:"ARRAY_BUFFER_BINDING"`
-	ATTACHED_SHADERS                             int    `js:"ATTACHED_SHADERS"`
-	BACK                                         int    `js:"BACK"`
-	BLEND                                        int    `js:"BLEND"`
-	BLEND_COLOR                                  int    `js:"BLEND_COLOR"`
-	BLEND_DST_ALPHA                              int    `js:"BLEND_DST_ALPHA"`
-	BLEND_DST_RGB                                int    `js:"BLEND_DST_RGB"`
-	BLEND_EQUATION                               int    `js:"BLEND_EQUATION"`
-	BLEND_EQUATION_ALPHA                         int    `js:"BLEND_EQUATION_ALPHA"`
-	BLEND_EQUATION_RGB                           int    `js:"BLEND_EQUATION_RGB"`
-	BLEND_SRC_ALPHA                              int    `js:"BLEND_SRC_ALPHA"`
-	BLEND_SRC_RGB                                int    `js:"BLEND_SRC_RGB"`
-	BLUE_BITS                                    int    `js:"BLUE_BITS"`
-	BOOL                                         int    `js:"BOOL"`
-	BOOL_VEC2                                    int    `js:"BOOL_VEC2"`
-	BOOL_VEC3                                    int    `js:"BOOL_VEC3"`
-	BOOL_VEC4                                    int    `js:"BOOL_VEC4"`
-	BROWSER_DEFAULT_WEBGL                        int    `js:"BROWSER_DEFAULT_WEBGL"`
-	BUFFER_SIZE                                  int    `js:"BUFFER_SIZE"`
-	BUFFER_USAGE                                 int    `js:"BUFFER_USAGE"`
-	BYTE                                         int    `js:"BYTE"`
-	CCW                                          int    `js:"CCW"`
-	CLAMP_TO_EDGE                                int    `js:"CLAMP_TO_EDGE"`
-	CLAMP_TO_BORDER                              int    `js:"CLAMP_TO_BORDER"`
-	COLOR_ATTACHMENT0                            int    `js:"COLOR_ATTACHMENT0"`
-	COLOR_BUFFER_BIT                             int    `js:"COLOR_BUFFER_BIT"`
-	COLOR_CLEAR_VALUE                            int    `js:"COLOR_CLEAR_VALUE"`
-	COLOR_WRITEMASK                              int    `js:"COLOR_WRITEMASK"`
-	COMPILE_STATUS                               uint32 `js:"COMPILE_STATUS"`
-	COMPRESSED_TEXTURE_FORMATS                   int    `js:"COMPRESSED_TEXTURE_FORMATS"`
-	CONSTANT_ALPHA                               int    `js:"CONSTANT_ALPHA"`
-	CONSTANT_COLOR                               int    `js:"CONSTANT_COLOR"`
-	CONTEXT_LOST_WEBGL                           int    `js:"CONTEXT_LOST_WEBGL"`
-	CULL_FACE                                    int    `js:"CULL_FACE"`
-	CULL_FACE_MODE                               int    `js:"CULL_FACE_MODE"`
-	CURRENT_PROGRAM                              int    `js:"CURRENT_PROGRAM"`
-	CURRENT_VERTEX_ATTRIB                        int    `js:"CURRENT_VERTEX_ATTRIB"`
-	CW                                           int    `js:"CW"`
-	DECR                                         int    `js:"DECR"`
-	DECR_WRAP                                    int    `js:"DECR_WRAP"`
-	DELETE_STATUS                                int    `js:"DELETE_STATUS"`
-	DEPTH_ATTACHMENT                             int    `js:"DEPTH_ATTACHMENT"`
-	DEPTH_BITS                                   int    `js:"DEPTH_BITS"`
-	DEPTH_BUFFER_BIT                             int    `js:"DEPTH_BUFFER_BIT"`
-	DEPTH_CLEAR_VALUE                            int    `js:"DEPTH_CLEAR_VALUE"`
-	DEPTH_COMPONENT                              int    `js:"DEPTH_COMPONENT"`
-	DEPTH_COMPONENT16                            int    `js:"DEPTH_COMPONENT16"`
-	DEPTH_FUNC                                   int    `js:"DEPTH_FUNC"`
-	DEPTH_RANGE                                  int    `js:"DEPTH_RANGE"`
-	DEPTH_STENCIL                                int    `js:"DEPTH_STENCIL"`
-	DEPTH_STENCIL_ATTACHMENT                     int    `js:"DEPTH_STENCIL_ATTACHMENT"`
-	DEPTH_TEST                                   int    `js:"DEPTH_TEST"`
-	DEPTH_WRITEMASK                              int    `js:"DEPTH_WRITEMASK"`
-	DITHER                                       int    `js:"DITHER"`
-	DONT_CARE                                    int    `js:"DONT_CARE"`
-	DST_ALPHA                                    int    `js:"DST_ALPHA"`
-	DST_COLOR                                    int    `js:"DST_COLOR"`
-	DYNAMIC_DRAW                                 int    `js:"DYNAMIC_DRAW"`
-	ELEMENT_ARRAY_BUFFER                         int    `js:"ELEMENT_ARRAY_BUFFER"`
-	ELEMENT_ARRAY_BUFFER_BINDING                 int    `js:"ELEMENT_ARRAY_BUFFER_BINDING"`
-	EQUAL                                        int    `js:"EQUAL"`
-	FASTEST                                      int    `js:"FASTEST"`
-	FLOAT                                        int    `js:"FLOAT"`
-	FLOAT_MAT2                                   int    `js:"FLOAT_MAT2"`
-	FLOAT_MAT3                                   int    `js:"FLOAT_MAT3"`
-	FLOAT_MAT4                                   int    `js:"FLOAT_MAT4"`
-	FLOAT_VEC2                                   int    `js:"FLOAT_VEC2"`
-	FLOAT_VEC3                                   int    `js:"FLOAT_VEC3"`
-	FLOAT_VEC4                                   int    `js:"FLOAT_VEC4"`
-	FRAGMENT_SHADER                              int    `js:"FRAGMENT_SHADER"`
-	FRAMEBUFFER                                  int    `js:"FRAMEBUFFER"`
-	FRAMEBUFFER_ATTACHMENT_OBJECT_NAME           int    `js:"FRAMEBUFFER_ATTACHMENT_OBJECT_NAME"`
-	FRAMEBUFFER_ATTACHMENT_OBJECT_TYPE           int    `js:"FRAMEBUFFER_ATTACHMENT_OBJECT_TYPE"`
-	FRAMEBUFFER_ATTACHMENT_TEXTURE_CUBE_MAP_FACE int    `js:"FRAMEBUFFER_ATTACHMENT_TEXTURE_CUBE_MAP_FACE"`
-	FRAMEBUFFER_ATTACHMENT_TEXTURE_LEVEL         int    `js:"FRAMEBUFFER_ATTACHMENT_TEXTURE_LEVEL"`
-	FRAMEBUFFER_BINDING                          int    `js:"FRAMEBUFFER_BINDING"`
-	FRAMEBUFFER_COMPLETE                         int    `js:"FRAMEBUFFER_COMPLETE"`
-	FRAMEBUFFER_INCOMPLETE_ATTACHMENT            int    `js:"FRAMEBUFFER_INCOMPLETE_ATTACHMENT"`
-	FRAMEBUFFER_INCOMPLETE_DIMENSIONS            int    `js:"FRAMEBUFFER_INCOMPLETE_DIMENSIONS"`
-	FRAMEBUFFER_INCOMPLETE_MISSING_ATTACHMENT    int    `js:"FRAMEBUFFER_INCOMPLETE_MISSING_ATTACHMENT"`
-	FRAMEBUFFER_UNSUPPORTED                      int    `js:"FRAMEBUFFER_UNSUPPORTED"`
-	FRONT                                        int    `js:"FRONT"`
-	FRONT_AND_BACK                               int    `js:"FRONT_AND_BACK"`
-	FRONT_FACE                                   int    `js:"FRONT_FACE"`
-	FUNC_ADD                                     int    `js:"FUNC_ADD"`
-	FUNC_REVERSE_SUBTRACT                        int    `js:"FUNC_REVERSE_SUBTRACT"`
-	FUNC_SUBTRACT                                int    `js:"FUNC_SUBTRACT"`
-	GENERATE_MIPMAP_HINT                         int    `js:"GENERATE_MIPMAP_HINT"`
-	GEQUAL                                       int    `js:"GEQUAL"`
-	GREATER                                      int    `js:"GREATER"`
-	GREEN_BITS                                   int    `js:"GREEN_BITS"`
-	HIGH_FLOAT                                   int    `js:"HIGH_FLOAT"`
-	HIGH_INT                                     int    `js:"HIGH_INT"`
-	INCR                                         int    `js:"INCR"`
-	INCR_WRAP                                    int    `js:"INCR_WRAP"`
-	INFO_LOG_LENGTH                              uint32 `js:"INFO_LOG_LENGTH"`
-	INT                                          int    `js:"INT"`
-	INT_VEC2                                     int    `js:"INT_VEC2"`
-	INT_VEC3                                     int    `js:"INT_VEC3"`
-	INT_VEC4                                     int    `js:"INT_VEC4"`
-	INVALID_ENUM                                 int    `js:"INVALID_ENUM"`
-	INVALID_FRAMEBUFFER_OPERATION                int    `js:"INVALID_FRAMEBUFFER_OPERATION"`
-	INVALID_OPERATION                            int    `js:"INVALID_OPERATION"`
-	INVALID_VALUE                                int    `js:"INVALID_VALUE"`
-	INVERT                                       int    `js:"INVERT"`
-	KEEP                                         int    `js:"KEEP"`
-	LEQUAL                                       int    `js:"LEQUAL"`
-	LESS                                         int    `js:"LESS"`
-	LINEAR                                       int    `js:"LINEAR"`
-	LINEAR_MIPMAP_LINEAR                         int    `js:"LINEAR_MIPMAP_LINEAR"`
-	LINEAR_MIPMAP_NEAREST                        int    `js:"LINEAR_MIPMAP_NEAREST"`
-	LINES                                        int    `js:"LINES"`
-	LINE_LOOP                                    int    `js:"LINE_LOOP"`
-	LINE_STRIP                                   int    `js:"LINE_STRIP"`
-	LINE_WIDTH                                   int    `js:"LINE_WIDTH"`
-	LINK_STATUS                                  int    `js:"LINK_STATUS"`
-	LOW_FLOAT                                    int    `js:"LOW_FLOAT"`
-	LOW_INT                                      int    `js:"LOW_INT"`
-	LUMINANCE                                    int    `js:"LUMINANCE"`
-	LUMINANCE_ALPHA                              int    `js:"LUMINANCE_ALPHA"`
-	MAX_COMBINED_TEXTURE_IMAGE_UNITS             int    `js:"MAX_COMBINED_TEXTURE_IMAGE_UNITS"`
-	MAX_CUBE_MAP_TEXTURE_SIZE                    int    `js:"MAX_CUBE_MAP_TEXTURE_SIZE"`
-	MAX_FRAGMENT_UNIFORM_VECTORS                 int    `js:"MAX_FRAGMENT_UNIFORM_VECTORS"`
-	MAX_RENDERBUFFER_SIZE                        int    `js:"MAX_RENDERBUFFER_SIZE"`
-	MAX_TEXTURE_IMAGE_UNITS                      int    `js:"MAX_TEXTURE_IMAGE_UNITS"`
-	MAX_TEXTURE_SIZE                             int    `js:"MAX_TEXTURE_SIZE"`
-	MAX_VARYING_VECTORS                          int    `js:"MAX_VARYING_VECTORS"`
-	MAX_VERTEX_ATTRIBS                           int    `js:"MAX_VERTEX_ATTRIBS"`
-	MAX_VERTEX_TEXTURE_IMAGE_UNITS               int    `js:"MAX_VERTEX_TEXTURE_IMAGE_UNITS"`
-	MAX_VERTEX_UNIFORM_VECTORS                   int    `js:"MAX_VERTEX_UNIFORM_VECTORS"`
-	MAX_VIEWPORT_DIMS                            int    `js:"MAX_VIEWPORT_DIMS"`
-	MEDIUM_FLOAT                                 int    `js:"MEDIUM_FLOAT"`
-	MEDIUM_INT                                   int    `js:"MEDIUM_INT"`
-	MIRRORED_REPEAT                              int    `js:"MIRRORED_REPEAT"`
-	MULTISAMPLE                                  int    `js:"MULTISAMPLE"`
-	NEAREST                                      int    `js:"NEAREST"`
-	NEAREST_MIPMAP_LINEAR                        int    `js:"NEAREST_MIPMAP_LINEAR"`
-	NEAREST_MIPMAP_NEAREST                       int    `js:"NEAREST_MIPMAP_NEAREST"`
-	NEVER                                        int    `js:"NEVER"`
-	NICEST                                       int    `js:"NICEST"`
-	NONE                                         int    `js:"NONE"`
-	NOTEQUAL                                     int    `js:"NOTEQUAL"`
-	NO_ERROR                                     int    `js:"NO_ERROR"`
-	NUM_COMPRESSED_TEXTURE_FORMATS               int    `js:"NUM_COMPRESSED_TEXTURE_FORMATS"`
-	ONE                                          int    `js:"ONE"`
-	ONE_MINUS_CONSTANT_ALPHA                     int    `js:"ONE_MINUS_CONSTANT_ALPHA"`
-	ONE_MINUS_CONSTANT_COLOR                     int    `js:"ONE_MINUS_CONSTANT_COLOR"`
-	ONE_MINUS_DST_ALPHA                          int    `js:"ONE_MINUS_DST_ALPHA"`
-	ONE_MINUS_DST_COLOR                          int    `js:"ONE_MINUS_DST_COLOR"`
-	ONE_MINUS_SRC_ALPHA                          int    `js:"ONE_MINUS_SRC_ALPHA"`
-	ONE_MINUS_SRC_COLOR                          int    `js:"ONE_MINUS_SRC_COLOR"`
-	OUT_OF_MEMORY                                int    `js:"OUT_OF_MEMORY"`
-	PACK_ALIGNMENT                               int    `js:"PACK_ALIGNMENT"`
-	POINTS                                       int    `js:"POINTS"`
-	POLYGON_OFFSET_FACTOR                        int    `js:"POLYGON_OFFSET_FACTOR"`
-	POLYGON_OFFSET_FILL                          int    `js:"POLYGON_OFFSET_FILL"`
-	POLYGON_OFFSET_UNITS                         int    `js:"POLYGON_OFFSET_UNITS"`
-	RED_BITS                                     int    `js:"RED_BITS"`
-	RENDERBUFFER                                 int    `js:"RENDERBUFFER"`
-	RENDERBUFFER_ALPHA_SIZE                      int    `js:"RENDERBUFFER_ALPHA_SIZE"`
-	RENDERBUFFER_BINDING                         int    `js:"RENDERBUFFER_BINDING"`
-	RENDERBUFFER_BLUE_SIZE                       int    `js:"RENDERBUFFER_BLUE_SIZE"`
-	RENDERBUFFER_DEPTH_SIZE                      int    `js:"RENDERBUFFER_DEPTH_SIZE"`
-	RENDERBUFFER_GREEN_SIZE                      int    `js:"RENDERBUFFER_GREEN_SIZE"`
-	RENDERBUFFER_HEIGHT                          int    `js:"RENDERBUFFER_HEIGHT"`
-	RENDERBUFFER_INTERNAL_FORMAT                 int    `js:"RENDERBUFFER_INTERNAL_FORMAT"`
-	RENDERBUFFER_RED_SIZE                        int    `js:"RENDERBUFFER_RED_SIZE"`
-	RENDERBUFFER_STENCIL_SIZE                    int    `js:"RENDERBUFFER_STENCIL_SIZE"`
-	RENDERBUFFER_WIDTH                           int    `js:"RENDERBUFFER_WIDTH"`
-	RENDERER                                     int    `js:"RENDERER"`
-	REPEAT                                       int    `js:"REPEAT"`
-	REPLACE                                      int    `js:"REPLACE"`
-	RGB                                          int    `js:"RGB"`
-	RGB5_A1                                      int    `js:"RGB5_A1"`
-	RGB565                                       int    `js:"RGB565"`
-	RGBA                                         int    `js:"RGBA"`
-	RGBA4                                        int    `js:"RGBA4"`
-	SAMPLER_2D                                   int    `js:"SAMPLER_2D"`
-	SAMPLER_CUBE                                 int    `js:"SAMPLER_CUBE"`
-	SAMPLES                                      int    `js:"SAMPLES"`
-	SAMPLE_ALPHA_TO_COVERAGE                     int    `js:"SAMPLE_ALPHA_TO_COVERAGE"`
-	SAMPLE_BUFFERS                               int    `js:"SAMPLE_BUFFERS"`
-	SAMPLE_COVERAGE                              int    `js:"SAMPLE_COVERAGE"`
-	SAMPLE_COVERAGE_INVERT                       int    `js:"SAMPLE_COVERAGE_INVERT"`
-	SAMPLE_COVERAGE_VALUE                        int    `js:"SAMPLE_COVERAGE_VALUE"`
-	SCISSOR_BOX                                  int    `js:"SCISSOR_BOX"`
-	SCISSOR_TEST                                 int    `js:"SCISSOR_TEST"`
-	SHADER_COMPILER                              int    `js:"SHADER_COMPILER"`
-	SHADER_SOURCE_LENGTH                         int    `js:"SHADER_SOURCE_LENGTH"`
-	SHADER_TYPE                                  int    `js:"SHADER_TYPE"`
-	SHADING_LANGUAGE_VERSION                     int    `js:"SHADING_LANGUAGE_VERSION"`
-	SHORT                                        int    `js:"SHORT"`
-	SRC_ALPHA                                    int    `js:"SRC_ALPHA"`
-	SRC_ALPHA_SATURATE                           int    `js:"SRC_ALPHA_SATURATE"`
-	SRC_COLOR                                    int    `js:"SRC_COLOR"`
-	STATIC_DRAW                                  int    `js:"STATIC_DRAW"`
-	STENCIL_ATTACHMENT                           int    `js:"STENCIL_ATTACHMENT"`
-	STENCIL_BACK_FAIL                            int    `js:"STENCIL_BACK_FAIL"`
-	STENCIL_BACK_FUNC                            int    `js:"STENCIL_BACK_FUNC"`
-	STENCIL_BACK_PASS_DEPTH_FAIL                 int    `js:"STENCIL_BACK_PASS_DEPTH_FAIL"`
-	STENCIL_BACK_PASS_DEPTH_PASS                 int    `js:"STENCIL_BACK_PASS_DEPTH_PASS"`
-	STENCIL_BACK_REF                             int    `js:"STENCIL_BACK_REF"`
-	STENCIL_BACK_VALUE_MASK                      int    `js:"STENCIL_BACK_VALUE_MASK"`
-	STENCIL_BACK_WRITEMASK                       int    `js:"STENCIL_BACK_WRITEMASK"`
-	STENCIL_BITS                                 int    `js:"STENCIL_BITS"`
-	STENCIL_BUFFER_BIT                           int    `js:"STENCIL_BUFFER_BIT"`
-	STENCIL_CLEAR_VALUE                          int    `js:"STENCIL_CLEAR_VALUE"`
-	STENCIL_FAIL                                 int    `js:"STENCIL_FAIL"`
-	STENCIL_FUNC                                 int    `js:"STENCIL_FUNC"`
-	STENCIL_INDEX                                int    `js:"STENCIL_INDEX"`
-	STENCIL_INDEX8                               int    `js:"STENCIL_INDEX8"`
-	STENCIL_PASS_DEPTH_FAIL                      int    `js:"STENCIL_PASS_DEPTH_FAIL"`
-	STENCIL_PASS_DEPTH_PASS                      int    `js:"STENCIL_PASS_DEPTH_PASS"`
-	STENCIL_REF                                  int    `js:"STENCIL_REF"`
-	STENCIL_TEST                                 int    `js:"STENCIL_TEST"`
-	STENCIL_VALUE_MASK                           int    `js:"STENCIL_VALUE_MASK"`
-	STENCIL_WRITEMASK                            int    `js:"STENCIL_WRITEMASK"`
-	STREAM_DRAW                                  int    `js:"STREAM_DRAW"`
-	SUBPIXEL_BITS                                int    `js:"SUBPIXEL_BITS"`
-	TEXTURE                                      int    `js:"TEXTURE"`
-	TEXTURE0                                     int    `js:"TEXTURE0"`
-	TEXTURE1                                     int    `js:"TEXTURE1"`
-	TEXTURE2                                     int    `js:"TEXTURE2"`
-	TEXTURE3                                     int    `js:"TEXTURE3"`
-	TEXTURE4                                     int    `js:"TEXTURE4"`
-	TEXTURE5                                     int    `js:"TEXTURE5"`
-	TEXTURE6                                     int    `js:"TEXTURE6"`
-	TEXTURE7                                     int    `js:"TEXTURE7"`
-	TEXTURE8                                     int    `js:"TEXTURE8"`
-	TEXTURE9                                     int    `js:"TEXTURE9"`
-	TEXTURE10                                    int    `js:"TEXTURE10"`
-	TEXTURE11                                    int    `js:"TEXTURE11"`
-	TEXTURE12                                    int    `js:"TEXTURE12"`
-	TEXTURE13                                    int    `js:"TEXTURE13"`
-	TEXTURE14                                    int    `js:"TEXTURE14"`
-	TEXTURE15                                    int    `js:"TEXTURE15"`
-	TEXTURE16                                    int    `js:"TEXTURE16"`
-	TEXTURE17                                    int    `js:"TEXTURE17"`
-	TEXTURE18                                    int    `js:"TEXTURE18"`
-	TEXTURE19                                    int    `js:"TEXTURE19"`
-	TEXTURE20                                    int    `js:"TEXTURE20"`
-	TEXTURE21                                    int    `js:"TEXTURE21"`
-	TEXTURE22                                    int    `js:"TEXTURE22"`
-	TEXTURE23                                    int    `js:"TEXTURE23"`
-	TEXTURE24                                    int    `js:"TEXTURE24"`
-	TEXTURE25                                    int    `js:"TEXTURE25"`
-	TEXTURE26                                    int    `js:"TEXTURE26"`
-	TEXTURE27                                    int    `js:"TEXTURE27"`
-	TEXTURE28                                    int    `js:"TEXTURE28"`
-	TEXTURE29                                    int    `js:"TEXTURE29"`
-	TEXTURE30                                    int    `js:"TEXTURE30"`
-	TEXTURE31                                    int    `js:"TEXTURE31"`
-	TEXTURE_2D                                   int    `js:"TEXTURE_2D"`
-	TEXTURE_BINDING_2D                           int    `js:"TEXTURE_BINDING_2D"`
-	TEXTURE_BINDING_CUBE_MAP                     int    `js:"TEXTURE_BINDING_CUBE_MAP"`
-	TEXTURE_CUBE_MAP                             int    `js:"TEXTURE_CUBE_MAP"`
-	TEXTURE_CUBE_MAP_NEGATIVE_X                  int    `js:"TEXTURE_CUBE_MAP_NEGATIVE_X"`
-	TEXTURE_CUBE_MAP_NEGATIVE_Y                  int    `js:"TEXTURE_CUBE_MAP_NEGATIVE_Y"`
-	TEXTURE_CUBE_MAP_NEGATIVE_Z                  int    `js:"TEXTURE_CUBE_MAP_NEGATIVE_Z"`
-	TEXTURE_CUBE_MAP_POSITIVE_X                  int    `js:"TEXTURE_CUBE_MAP_POSITIVE_X"`
-	TEXTURE_CUBE_MAP_POSITIVE_Y                  int    `js:"TEXTURE_CUBE_MAP_POSITIVE_Y"`
-	TEXTURE_CUBE_MAP_POSITIVE_Z                  int    `js:"TEXTURE_CUBE_MAP_POSITIVE_Z"`
-	TEXTURE_MAG_FILTER                           int    `js:"TEXTURE_MAG_FILTER"`
-	TEXTURE_MIN_FILTER                           int    `js:"TEXTURE_MIN_FILTER"`
-	TEXTURE_WRAP_S                               int    `js:"TEXTURE_WRAP_S"`
-	TEXTURE_WRAP_T                               int    `js:"TEXTURE_WRAP_T"`
-	TRIANGLES                                    int    `js:"TRIANGLES"`
-	TRIANGLE_FAN                                 int    `js:"TRIANGLE_FAN"`
-	TRIANGLE_STRIP                               int    `js:"TRIANGLE_STRIP"`
-	UNPACK_ALIGNMENT                             int    `js:"UNPACK_ALIGNMENT"`
-	UNPACK_COLORSPACE_CONVERSION_WEBGL           int    `js:"UNPACK_COLORSPACE_CONVERSION_WEBGL"`
-	UNPACK_FLIP_Y_WEBGL                          int    `js:"UNPACK_FLIP_Y_WEBGL"`
-	UNPACK_PREMULTIPLY_ALPHA_WEBGL               int    `js:"UNPACK_PREMULTIPLY_ALPHA_WEBGL"`
-	UNSIGNED_BYTE                                int    `js:"UNSIGNED_BYTE"`
-	UNSIGNED_INT                                 int    `js:"UNSIGNED_INT"`
-	UNSIGNED_SHORT                               int    `js:"UNSIGNED_SHORT"`
-	UNSIGNED_SHORT_4_4_4_4                       int    `js:"UNSIGNED_SHORT_4_4_4_4"`
-	UNSIGNED_SHORT_5_5_5_1                       int    `js:"UNSIGNED_SHORT_5_5_5_1"`
-	UNSIGNED_SHORT_5_6_5                         int    `js:"UNSIGNED_SHORT_5_6_5"`
-	VALIDATE_STATUS                              int    `js:"VALIDATE_STATUS"`
-	VENDOR                                       int    `js:"VENDOR"`
-	VERSION                                      int    `js:"VERSION"`
-	VERTEX_ATTRIB_ARRAY_BUFFER_BINDING           int    `js:"VERTEX_ATTRIB_ARRAY_BUFFER_BINDING"`
-	VERTEX_ATTRIB_ARRAY_ENABLED                  int    `js:"VERTEX_ATTRIB_ARRAY_ENABLED"`
-	VERTEX_ATTRIB_ARRAY_NORMALIZED               int    `js:"VERTEX_ATTRIB_ARRAY_NORMALIZED"`
-	VERTEX_ATTRIB_ARRAY_POINTER                  int    `js:"VERTEX_ATTRIB_ARRAY_POINTER"`
-	VERTEX_ATTRIB_ARRAY_SIZE                     int    `js:"VERTEX_ATTRIB_ARRAY_SIZE"`
-	VERTEX_ATTRIB_ARRAY_STRIDE                   int    `js:"VERTEX_ATTRIB_ARRAY_STRIDE"`
-	VERTEX_ATTRIB_ARRAY_TYPE                     int    `js:"VERTEX_ATTRIB_ARRAY_TYPE"`
-	VERTEX_SHADER                                int    `js:"VERTEX_SHADER"`
-	VIEWPORT                                     int    `js:"VIEWPORT"`
-	ZERO                                         int    `js:"ZERO"`
-	TRUE                                         int    `js:"TRUE"`
-	R8                                           int    `js:"R8"`
-	RED                                          int    `js:"RGBA32F"`
-	RGBA32F                                      int    `js:"R32F"`
-	R32F                                         int    `js:"RED"`
+	ResourceTracker
+	Restorer
+	// Caps are the limits and features of the context.
+	Caps Caps
+
+	// instancedArrays is ANGLE_instanced_arrays on WebGL 1, nil on WebGL 2
+	// where the instanced draws are core
+	instancedArrays *js.Object
+	// vertexArrayObject is OES_vertex_array_object on WebGL 1, nil on WebGL 2
+	// where the vertex arrays are core
+	vertexArrayObject *js.Object
+
+	objects deviceObjects
 }
 
+var _ Backend = (*Context)(nil)
+
 // NewContext takes an HTML5 canvas object and optional context attributes.
 // If an error is returned it means you won't have access to WebGL
 // functionality.
@@ -384,9 +105,62 @@ func NewContext(canvas *js.Object, ca *ContextAttributes) (*Context, error) {
 			return nil, errors.New("Creating a webgl context has failed.")
 		}
 	}
-	ctx := new(Context)
-	ctx.Object = gl
-	return ctx, nil
+	c := &Context{Enums: sEnums, Object: gl}
+	c.Caps = c.queryCaps()
+
+	// the default of a lost context is to never come back, preventing it
+	// asks the browser for webglcontextrestored
+	canvas.Call("addEventListener", "webglcontextlost", func(event *js.Object) {
+		event.Call("preventDefault")
+		c.lost()
+	}, false)
+	canvas.Call("addEventListener", "webglcontextrestored", func(event *js.Object) {
+		c.RestoreContext()
+	}, false)
+	return c, nil
+}
+
+// RestoreContext re-creates the GPU objects of the registered resources, it
+// runs on webglcontextrestored. The objects created before the loss are
+// forgotten, they do not count as leaks.
+func (c *Context) RestoreContext() error {
+	c.Caps = c.queryCaps()
+	c.ResourceTracker = ResourceTracker{}
+	err := c.restore()
+	c.restored(err)
+	return err
+}
+
+func (c *Context) queryCaps() (caps Caps) {
+	caps.MaxTextureSize = c.GetParameter(c.MAX_TEXTURE_SIZE).Int()
+	caps.MaxTextureUnits = c.GetParameter(c.MAX_TEXTURE_IMAGE_UNITS).Int()
+	caps.MaxVertexAttribs = c.GetParameter(c.MAX_VERTEX_ATTRIBS).Int()
+	caps.MaxRenderbufferSize = c.GetParameter(c.MAX_RENDERBUFFER_SIZE).Int()
+
+	// core in WebGL 2, extensions in WebGL 1
+	webgl2 := strings.HasPrefix(c.GetParameter(c.VERSION).String(), "WebGL 2")
+	if webgl2 {
+		caps.MaxSamples = c.GetParameter(c.MAX_SAMPLES).Int()
+		caps.MaxUniformBufferBindings = c.GetParameter(c.MAX_UNIFORM_BUFFER_BINDINGS).Int()
+		caps.VertexArrays = true
+		caps.FloatTextures = true
+		caps.Instancing = true
+		caps.Uint32Indices = true
+		caps.UniformBuffers = true
+	}
+	caps.setExtensions(c.GetSupportedExtensions())
+	c.instancedArrays = nil
+	if !webgl2 && caps.HasExtension("ANGLE_instanced_arrays") {
+		c.instancedArrays = c.GetExtension("ANGLE_instanced_arrays")
+	}
+	c.vertexArrayObject = nil
+	if !webgl2 && caps.HasExtension("OES_vertex_array_object") {
+		c.vertexArrayObject = c.GetExtension("OES_vertex_array_object")
+	}
+	caps.GLSLVersion, caps.ES = parseGLSLVersion(c.GetParameter(c.SHADING_LANGUAGE_VERSION).String())
+	caps.Driver = driverString(c.GetParameter(c.VENDOR).String(), c.GetParameter(c.RENDERER).String(),
+		c.GetParameter(c.VERSION).String())
+	return caps
 }
 
 // Returns the context attributes active on the context. These values might
@@ -407,7 +181,7 @@ func (c *Context) GetContextAttributes() ContextAttributes {
 // PerFragment ---------------------------------------------------------------
 
 // The GL_BLEND_COLOR may be used to calculate the source and destination blending factors.
-func (c *Context) BlendColor(r, g, b, a float64) {
+func (c *Context) BlendColor(r, g, b, a float32) {
 	c.Call("blendColor", r, g, b, a)
 }
 
@@ -435,20 +209,20 @@ func (c *Context) BlendFuncSeparate(srcRGB, dstRGB, srcAlpha, dstAlpha int) {
 
 // Sets a function to use to compare incoming pixel depth to the
 // current depth buffer value.
-func (c *Context) DepthFunc(fun int) {
-	c.Call("depthFunc", fun)
+func (c *Context) DepthFunc(fn int) {
+	c.Call("depthFunc", fn)
 }
 
-func (c *Context) SampleCoverage(value float64, invert bool) {
+func (c *Context) SampleCoverage(value float32, invert bool) {
 	c.Call("sampleCoverage", value, invert)
 }
 
-func (c *Context) StencilFunc(function, ref, mask int) {
-	c.Call("stencilFunc", function, ref, mask)
+func (c *Context) StencilFunc(fn, ref, mask int) {
+	c.Call("stencilFunc", fn, ref, mask)
 }
 
-func (c *Context) StencilFuncSeparate(face, function, ref, mask int) {
-	c.Call("stencilFuncSeparate", face, function, ref, mask)
+func (c *Context) StencilFuncSeparate(face, fn, ref, mask int) {
+	c.Call("stencilFuncSeparate", face, fn, ref, mask)
 }
 
 // public function stencilOp(fail:GLenum, zfail:GLenum, zpass:GLenum) : Void;
@@ -482,6 +256,97 @@ func (c *Context) BindBuffer(target int, buffer *Buffer) {
 	c.Call("bindBuffer", target, buffer.Object)
 }
 
+// Binds a WebGLBuffer to an indexed binding point of target, and to target
+// itself. WebGL 2 only.
+func (c *Context) BindBufferBase(target, index int, buffer *Buffer) {
+	if buffer == nil {
+		c.Call("bindBufferBase", target, index, nil)
+		return
+	}
+	c.Call("bindBufferBase", target, index, buffer.Object)
+}
+
+// Returns the index of a uniform block of a program, or INVALID_INDEX.
+// WebGL 2 only.
+func (c *Context) GetUniformBlockIndex(program *Program, name string) int {
+	index := c.Call("getUniformBlockIndex", program.Object, name).Float()
+	if index == 4294967295 {
+		return c.INVALID_INDEX
+	}
+	return int(index)
+}
+
+// Assigns a binding point to a uniform block of a program. WebGL 2 only.
+func (c *Context) UniformBlockBinding(program *Program, blockIndex, binding int) {
+	c.Call("uniformBlockBinding", program.Object, blockIndex, binding)
+}
+
+// WebGL has no program binaries.
+func (c *Context) GetProgramBinary(program *Program) (binary []byte, format int) {
+	log.Println("Warning: GetProgramBinary is not available in WebGL")
+	return nil, 0
+}
+
+func (c *Context) ProgramBinary(program *Program, format int, binary []byte) {
+	log.Println("Warning: ProgramBinary is not available in WebGL")
+}
+
+func (c *Context) ProgramParameteri(program *Program, pname, value int) {
+	log.Println("Warning: ProgramParameteri is not available in WebGL")
+}
+
+// Sets the number of instances that share a value of a vertex attribute in
+// the instanced draws. WebGL 2 or ANGLE_instanced_arrays.
+func (c *Context) VertexAttribDivisor(index, divisor int) {
+	switch {
+	case c.instancedArrays != nil:
+		c.instancedArrays.Call("vertexAttribDivisorANGLE", index, divisor)
+	case c.Caps.Instancing:
+		c.Call("vertexAttribDivisor", index, divisor)
+	default:
+		log.Println("Warning: VertexAttribDivisor needs WebGL 2 or ANGLE_instanced_arrays")
+	}
+}
+
+// Renders primitives from array data, instances times. WebGL 2 or
+// ANGLE_instanced_arrays.
+func (c *Context) DrawArraysInstanced(mode, first, count, instances int) {
+	switch {
+	case c.instancedArrays != nil:
+		c.instancedArrays.Call("drawArraysInstancedANGLE", mode, first, count, instances)
+	case c.Caps.Instancing:
+		c.Call("drawArraysInstanced", mode, first, count, instances)
+	default:
+		log.Println("Warning: DrawArraysInstanced needs WebGL 2 or ANGLE_instanced_arrays")
+	}
+}
+
+// Renders primitives indexed by element array data, instances times. WebGL
+// 2 or ANGLE_instanced_arrays.
+func (c *Context) DrawElementsInstanced(mode, count, typ, offset, instances int) {
+	switch {
+	case c.instancedArrays != nil:
+		c.instancedArrays.Call("drawElementsInstancedANGLE", mode, count, typ, offset, instances)
+	case c.Caps.Instancing:
+		c.Call("drawElementsInstanced", mode, count, typ, offset, instances)
+	default:
+		log.Println("Warning: DrawElementsInstanced needs WebGL 2 or ANGLE_instanced_arrays")
+	}
+}
+
+// WebGL has no compute nor tessellation shaders.
+func (c *Context) DispatchCompute(x, y, z int) {
+	log.Println("Warning: DispatchCompute is not available in WebGL")
+}
+
+func (c *Context) MemoryBarrier(barriers int) {
+	log.Println("Warning: MemoryBarrier is not available in WebGL")
+}
+
+func (c *Context) PatchParameteri(pname, value int) {
+	log.Println("Warning: PatchParameteri is not available in WebGL")
+}
+
 // Associates a WebGLFramebuffer object with the FRAMEBUFFER bind target.
 func (c *Context) BindFramebuffer(target int, framebuffer *FrameBuffer) {
 	if framebuffer == nil {
@@ -494,10 +359,10 @@ func (c *Context) BindFramebuffer(target int, framebuffer *FrameBuffer) {
 // Binds a WebGLRenderbuffer object to be used for rendering.
 func (c *Context) BindRenderbuffer(target int, renderbuffer *RenderBuffer) {
 	if renderbuffer == nil {
-		c.Call("bindRenderBuffer", target, nil)
+		c.Call("bindRenderbuffer", target, nil)
 		return
 	}
-	c.Call("bindRenderbuffer", target, renderbuffer)
+	c.Call("bindRenderbuffer", target, renderbuffer.Object)
 }
 
 // Binds a named texture object to a target.
@@ -509,16 +374,26 @@ func (c *Context) BindTexture(target int, texture *Texture) {
 	c.Call("bindTexture", target, texture.Object)
 }
 
+// Binds a vertex array object, nil for the default attributes. WebGL 2 or
+// OES_vertex_array_object.
 func (c *Context) BindVertexArray(va *VertexArray) {
-	if va == nil {
-		c.Call("bindVertexArray", nil)
-		return
+	var object *js.Object
+	if va != nil {
+		object = va.Object
+	}
+	switch {
+	case c.vertexArrayObject != nil:
+		c.vertexArrayObject.Call("bindVertexArrayOES", object)
+	case c.Caps.VertexArrays:
+		c.Call("bindVertexArray", object)
+	default:
+		log.Println("Warning: BindVertexArray needs WebGL 2 or OES_vertex_array_object")
 	}
-	c.Call("bindVertexArray", va.Object)
 }
 
 // Creates a buffer in memory and initializes it with array data.
-// If no array is provided, the contents of the buffer is initialized to 0.
+// If no array is provided, the contents of the buffer is initialized to 0,
+// an int being its size.
 func (c *Context) BufferData(target int, data interface{}, usage int) {
 	c.Call("bufferData", target, data, usage)
 }
@@ -545,7 +420,7 @@ func (c *Context) ClearColor(r, g, b, a float32) {
 }
 
 // Clears the depth buffer to a specific value.
-func (c *Context) ClearDepth(depth float64) {
+func (c *Context) ClearDepth(depth float32) {
 	c.Call("clearDepth", depth)
 }
 
@@ -571,48 +446,73 @@ func (c *Context) CompileShader(shader *Shader) {
 }
 
 // Copies a rectangle of pixels from the current WebGLFramebuffer into a texture image.
-func (c *Context) CopyTexImage2D(target, level, internal, x, y, w, h, border int) {
-	c.Call("copyTexImage2D", target, level, internal, x, y, w, h, border)
+func (c *Context) CopyTexImage2D(target, level, internalFormat, x, y, width, height, border int) {
+	c.Call("copyTexImage2D", target, level, internalFormat, x, y, width, height, border)
 }
 
 // Replaces a portion of an existing 2D texture image with data from the current framebuffer.
-func (c *Context) CopyTexSubImage2D(target, level, xoffset, yoffset, x, y, w, h int) {
-	c.Call("copyTexSubImage2D", target, level, xoffset, yoffset, x, y, w, h)
+func (c *Context) CopyTexSubImage2D(target, level, xoffset, yoffset, x, y, width, height int) {
+	c.Call("copyTexSubImage2D", target, level, xoffset, yoffset, x, y, width, height)
 }
 
 // Creates and initializes a WebGLBuffer.
 func (c *Context) CreateBuffer() *Buffer {
-	return &Buffer{c.Call("createBuffer")}
+	res := &Buffer{c.Call("createBuffer")}
+	c.created(res)
+	return res
 }
 
 // Returns a WebGLFramebuffer object.
 func (c *Context) CreateFramebuffer() *FrameBuffer {
-	return &FrameBuffer{c.Call("createFramebuffer")}
+	res := &FrameBuffer{c.Call("createFramebuffer")}
+	c.created(res)
+	return res
 }
 
 // Creates an empty WebGLProgram object to which vector and fragment
 // WebGLShader objects can be bound.
 func (c *Context) CreateProgram() *Program {
-	return &Program{c.Call("createProgram")}
+	res := &Program{c.Call("createProgram")}
+	c.created(res)
+	return res
 }
 
 // Creates and returns a WebGLRenderbuffer object.
 func (c *Context) CreateRenderbuffer() *RenderBuffer {
-	return &RenderBuffer{c.Call("createRenderbuffer")}
+	res := &RenderBuffer{c.Call("createRenderbuffer")}
+	c.created(res)
+	return res
 }
 
 // Returns an empty vertex or fragment shader object based on the type specified.
 func (c *Context) CreateShader(typ int) *Shader {
-	return &Shader{c.Call("createShader", typ)}
+	res := &Shader{c.Call("createShader", typ)}
+	c.created(res)
+	return res
 }
 
 // Used to generate a WebGLTexture object to which images can be bound.
 func (c *Context) CreateTexture() *Texture {
-	return &Texture{c.Call("createTexture")}
+	res := &Texture{c.Call("createTexture")}
+	c.created(res)
+	return res
 }
 
+// Creates a vertex array object. WebGL 2 or OES_vertex_array_object, nil
+// without them.
 func (c *Context) CreateVertexArray() *VertexArray {
-	return &VertexArray{c.Call("createVertexArray")}
+	var res *VertexArray
+	switch {
+	case c.vertexArrayObject != nil:
+		res = &VertexArray{c.vertexArrayObject.Call("createVertexArrayOES")}
+	case c.Caps.VertexArrays:
+		res = &VertexArray{c.Call("createVertexArray")}
+	default:
+		log.Println("Warning: CreateVertexArray needs WebGL 2 or OES_vertex_array_object")
+		return nil
+	}
+	c.created(res)
+	return res
 }
 
 // Sets whether or not front, back, or both facing facets are able to be culled.
@@ -622,6 +522,7 @@ func (c *Context) CullFace(mode int) {
 
 // Delete a specific buffer.
 func (c *Context) DeleteBuffer(buffer *Buffer) {
+	c.deleted(buffer)
 	c.Call("deleteBuffer", buffer)
 }
 
@@ -629,6 +530,7 @@ func (c *Context) DeleteBuffer(buffer *Buffer) {
 // currently bound framebuffer, the default framebuffer will be bound.
 // Deleting a framebuffer detaches all of its attachments.
 func (c *Context) DeleteFramebuffer(framebuffer *FrameBuffer) {
+	c.deleted(framebuffer)
 	c.Call("deleteFramebuffer", framebuffer)
 }
 
@@ -637,6 +539,7 @@ func (c *Context) DeleteFramebuffer(framebuffer *FrameBuffer) {
 // Any shader objects associated with the program will be detached.
 // They will be deleted if they were already flagged for deletion.
 func (c *Context) DeleteProgram(program *Program) {
+	c.deleted(program)
 	c.Call("deleteProgram", program.Object)
 }
 
@@ -644,20 +547,29 @@ func (c *Context) DeleteProgram(program *Program) {
 // currently bound, it will become unbound. If the renderbuffer is
 // attached to the currently bound framebuffer, it is detached.
 func (c *Context) DeleteRenderbuffer(renderbuffer *RenderBuffer) {
+	c.deleted(renderbuffer)
 	c.Call("deleteRenderbuffer", renderbuffer.Object)
 }
 
 // Deletes a specific shader object.
 func (c *Context) DeleteShader(shader *Shader) {
+	c.deleted(shader)
 	c.Call("deleteShader", shader.Object)
 }
 
 // Deletes a specific texture object.
 func (c *Context) DeleteTexture(texture *Texture) {
+	c.deleted(texture)
 	c.Call("deleteTexture", texture.Object)
 }
 
+// Deletes a vertex array object. WebGL 2 or OES_vertex_array_object.
 func (c *Context) DeleteVertexArray(va *VertexArray) {
+	c.deleted(va)
+	if c.vertexArrayObject != nil {
+		c.vertexArrayObject.Call("deleteVertexArrayOES", va.Object)
+		return
+	}
 	c.Call("deleteVertexArray", va.Object)
 }
 
@@ -667,7 +579,7 @@ func (c *Context) DepthMask(flag bool) {
 }
 
 // Sets the depth range for normalized coordinates to canvas or viewport depth coordinates.
-func (c *Context) DepthRange(zNear, zFar float64) {
+func (c *Context) DepthRange(zNear, zFar float32) {
 	c.Call("depthRange", zNear, zFar)
 }
 
@@ -720,8 +632,8 @@ func (c *Context) Flush() {
 
 // Attaches a WebGLRenderbuffer object as a logical buffer to the
 // currently bound WebGLFramebuffer object.
-func (c *Context) FrameBufferRenderBuffer(target, attachment, renderbufferTarget int, renderbuffer *RenderBuffer) {
-	c.Call("framebufferRenderBuffer", target, attachment, renderbufferTarget, renderbuffer)
+func (c *Context) FramebufferRenderbuffer(target, attachment, renderbufferTarget int, renderbuffer *RenderBuffer) {
+	c.Call("framebufferRenderbuffer", target, attachment, renderbufferTarget, renderbuffer)
 }
 
 // Attaches a texture to a WebGLFramebuffer object.
@@ -741,16 +653,18 @@ func (c *Context) GenerateMipmap(target int) {
 	c.Call("generateMipmap", target)
 }
 
-// Returns an WebGLActiveInfo object containing the size, type, and name
-// of a vertex attribute at a specific index position in a program object.
-func (c *Context) GetActiveAttrib(program *Program, index int) string {
-	return c.Call("getActiveAttrib", program.Object, index).String()
+// Returns the name, size and type of a vertex attribute at a specific
+// index position in a program object.
+func (c *Context) GetActiveAttrib(program *Program, index int) (name string, size, typ int) {
+	info := c.Call("getActiveAttrib", program.Object, index)
+	return info.Get("name").String(), info.Get("size").Int(), info.Get("type").Int()
 }
 
-// Returns an WebGLActiveInfo object containing the size, type, and name
-// of a uniform attribute at a specific index position in a program object.
-func (c *Context) GetActiveUniform(program *Program, index int) string {
-	return c.Call("getActiveUniform", program.Object, index).String()
+// Returns the name, size and type of a uniform attribute at a specific
+// index position in a program object.
+func (c *Context) GetActiveUniform(program *Program, index int) (name string, size, typ int) {
+	info := c.Call("getActiveUniform", program.Object, index)
+	return info.Get("name").String(), info.Get("size").Int(), info.Get("type").Int()
 }
 
 // Returns a slice of WebGLShaders bound to a WebGLProgram.
@@ -790,10 +704,10 @@ func (c *Context) GetExtension(name string) *js.Object {
 	return c.Call("getExtension", name)
 }
 
-// TODO: Create type specific variations.
-// Gets a parameter value for a given target and attachment.
-func (c *Context) GetFramebufferAttachmentParameter(target, attachment, pname int) *js.Object {
-	return c.Call("getFramebufferAttachmentParameter", target, attachment, pname)
+// Gets a parameter value for a given target and attachment. The
+// FRAMEBUFFER_ATTACHMENT_OBJECT_NAME object is not a number, it reads as 0.
+func (c *Context) GetFramebufferAttachmentParameter(target, attachment, pname int) int {
+	return c.Call("getFramebufferAttachmentParameter", target, attachment, pname).Int()
 }
 
 // Returns the value of the program parameter that corresponds to a supplied pname
@@ -826,6 +740,12 @@ func (c *Context) GetShaderParameter(shader *Shader, pname int) *js.Object {
 	return c.Call("getShaderParameter", shader.Object, pname)
 }
 
+// Returns the value of the parameter associated with pname for a shader object
+// which is interpreted as an int.
+func (c *Context) GetShaderParameteri(shader *Shader, pname int) int {
+	return c.Call("getShaderParameter", shader.Object, pname).Int()
+}
+
 // Returns the value of the parameter associated with pname for a shader object.
 func (c *Context) GetShaderParameterb(shader *Shader, pname int) bool {
 	return c.Call("getShaderParameter", shader.Object, pname).Bool()
@@ -889,8 +809,8 @@ func (c *Context) GetVertexAttribOffset(index, pname int) int {
 // public function hint(target:GLenum, mode:GLenum) : Void;
 
 // Returns true if buffer is valid, false otherwise.
-func (c *Context) IsBuffer(buffer js.Object) bool {
-	return c.Call("isBuffer", buffer).Bool()
+func (c *Context) IsBuffer(buffer *Buffer) bool {
+	return c.Call("isBuffer", buffer.Object).Bool()
 }
 
 // Returns whether the WebGL context has been lost.
@@ -953,15 +873,20 @@ func (c *Context) PixelStorei(pname, param int) {
 
 // Sets the implementation-specific units and scale factor
 // used to calculate fragment depth values.
-func (c *Context) PolygonOffset(factor, units float64) {
+func (c *Context) PolygonOffset(factor, units float32) {
 	c.Call("polygonOffset", factor, units)
 }
 
-// TODO: Figure out if pixels should be a slice.
-// Reads pixel data into an ArrayBufferView object from a
-// rectangular area in the color buffer of the active frame buffer.
-func (c *Context) ReadPixels(x, y, width, height, format, typ int, pixels js.Object) {
-	c.Call("readPixels", x, y, width, height, format, typ, pixels)
+// Reads pixel data into a []uint8 or a []float32 from a rectangular area
+// in the color buffer of the active frame buffer.
+func (c *Context) ReadPixels(x, y, width, height, format, typ int, pixels interface{}) {
+	switch t := pixels.(type) {
+	case []uint8, []float32:
+		// the typed array shares the memory of the slice
+		c.Call("readPixels", x, y, width, height, format, typ, t)
+	default:
+		panic(fmt.Errorf("ReadPixels Unknown type %v", t))
+	}
 }
 
 // Creates or replaces the data store for the currently bound WebGLRenderbuffer object.
@@ -1001,9 +926,14 @@ func (c *Context) TexParameteri(target int, pname int, param int) {
 	c.Call("texParameteri", target, pname, param)
 }
 
-// Replaces a portion of an existing 2D texture image with all of another image.
-func (c *Context) TexSubImage2D(target, level, xoffset, yoffset, format, typ int, image js.Object) {
-	c.Call("texSubImage2D", target, level, xoffset, yoffset, format, typ, image)
+// Replaces a portion of an existing 2D texture image with pixel data.
+func (c *Context) TexSubImage2D(target, level, xoffset, yoffset, width, height, format, kind int, data interface{}) {
+	switch t := data.(type) {
+	case []uint8, []float32:
+		c.Call("texSubImage2D", target, level, xoffset, yoffset, width, height, format, kind, t)
+	default:
+		panic(fmt.Errorf("TexSubImage2D Unknown type %v", t))
+	}
 }
 
 // Assigns a floating point value to a uniform variable for the current program object.
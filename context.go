@@ -0,0 +1,1659 @@
+//+build !netgo,!android
+
+package glplus
+
+import "io"
+
+// Context is the GL context used by glplus. It forwards every call to its
+// backend, skips the state changes that change nothing, and records the
+// calls while a trace is running.
+type Context struct {
+	// owner is the goroutine of the RenderThread owning the context, read
+	// atomically. It comes first to be 64-bit aligned on 32-bit platforms.
+	owner int64
+
+	Enums
+	NativeBackend
+	// Caps are the limits and features of the backend.
+	Caps Caps
+
+	ResourceTracker
+	Restorer
+
+	objects deviceObjects
+	state   glState
+	tracer  *Tracer
+	debug   *debugState
+}
+
+var _ NativeBackend = (*Context)(nil)
+
+// NewBackendContext returns a context forwarding to backend, which can be a
+// fake in tests.
+func NewBackendContext(backend NativeBackend) *Context {
+	c := &Context{Enums: sEnums, NativeBackend: backend}
+	c.Caps = backend.QueryCaps()
+	c.state.invalidate()
+	return c
+}
+
+// RestoreContext re-creates the GPU objects of the registered resources once
+// the backend lost them. The objects created before the loss are forgotten,
+// they do not count as leaks.
+func (c *Context) RestoreContext() error {
+	c.checkThread()
+	c.Caps = c.NativeBackend.QueryCaps()
+	c.state.invalidate()
+	c.ResourceTracker = ResourceTracker{}
+	err := c.restore()
+	c.restored(err)
+	return err
+}
+
+// StartTrace records every following call into w, see ReadTrace. The
+// shadowed state is forgotten so that the trace sets what it uses.
+func (c *Context) StartTrace(w io.Writer) (err error) {
+	c.checkThread()
+	c.state.invalidate()
+	header := TraceHeader{
+		Version: traceVersion,
+		Backend: c.Version(),
+		Enums:   enumNames(&c.Enums),
+	}
+	c.tracer, err = newTracer(w, header)
+	return err
+}
+
+// StopTrace stops recording and returns the first error met while writing.
+func (c *Context) StopTrace() error {
+	c.checkThread()
+	if c.tracer == nil {
+		return nil
+	}
+	err := c.tracer.err
+	c.tracer = nil
+	return err
+}
+
+// GetError ...
+func (c *Context) GetError() int {
+	c.checkThread()
+	var res int
+	if c.debug != nil && c.debug.pending != c.NO_ERROR {
+		// already read by the debug mode
+		res, c.debug.pending = c.debug.pending, c.NO_ERROR
+	} else {
+		res = c.NativeBackend.GetError()
+	}
+	if c.tracer != nil {
+		c.tracer.record("GetError", res)
+	}
+	return res
+}
+
+// DeleteProgram ...
+func (c *Context) DeleteProgram(program *Program) {
+	c.checkThread()
+	c.NativeBackend.DeleteProgram(program)
+	c.deleted(program)
+	c.state.forgetProgram(program)
+	if c.tracer != nil {
+		c.tracer.record("DeleteProgram", nil, program)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// GetProgramInfoLog ...
+func (c *Context) GetProgramInfoLog(program *Program) string {
+	c.checkThread()
+	res := c.NativeBackend.GetProgramInfoLog(program)
+	if c.tracer != nil {
+		c.tracer.record("GetProgramInfoLog", res, program)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+	return res
+}
+
+// ValidateProgram ...
+func (c *Context) ValidateProgram(program *Program) {
+	c.checkThread()
+	c.NativeBackend.ValidateProgram(program)
+	if c.tracer != nil {
+		c.tracer.record("ValidateProgram", nil, program)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// GetProgramParameterb ...
+func (c *Context) GetProgramParameterb(program *Program, pname int) bool {
+	c.checkThread()
+	res := c.NativeBackend.GetProgramParameterb(program, pname)
+	if c.tracer != nil {
+		c.tracer.record("GetProgramParameterb", res, program, pname)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+	return res
+}
+
+// GetProgramParameteri ...
+func (c *Context) GetProgramParameteri(program *Program, pname int) int {
+	c.checkThread()
+	res := c.NativeBackend.GetProgramParameteri(program, pname)
+	if c.tracer != nil {
+		c.tracer.record("GetProgramParameteri", res, program, pname)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+	return res
+}
+
+// LinkProgram ...
+func (c *Context) LinkProgram(program *Program) {
+	c.checkThread()
+	c.NativeBackend.LinkProgram(program)
+	if c.tracer != nil {
+		c.tracer.record("LinkProgram", nil, program)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// GetUniformLocation ...
+func (c *Context) GetUniformLocation(program *Program, name string) *UniformLocation {
+	c.checkThread()
+	res := c.NativeBackend.GetUniformLocation(program, name)
+	if c.tracer != nil {
+		c.tracer.record("GetUniformLocation", res, program, name)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+	return res
+}
+
+// GetAttribLocation ...
+func (c *Context) GetAttribLocation(program *Program, name string) int {
+	c.checkThread()
+	res := c.NativeBackend.GetAttribLocation(program, name)
+	if c.tracer != nil {
+		c.tracer.record("GetAttribLocation", res, program, name)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+	return res
+}
+
+// UseProgram ...
+func (c *Context) UseProgram(program *Program) {
+	c.checkThread()
+	if c.state.useProgram(program) {
+		return
+	}
+	c.NativeBackend.UseProgram(program)
+	if c.tracer != nil {
+		c.tracer.record("UseProgram", nil, program)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// Uniform1f ...
+func (c *Context) Uniform1f(location *UniformLocation, x float32) {
+	c.checkThread()
+	c.NativeBackend.Uniform1f(location, x)
+	if c.tracer != nil {
+		c.tracer.record("Uniform1f", nil, location, x)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// Uniform1i ...
+func (c *Context) Uniform1i(location *UniformLocation, x int) {
+	c.checkThread()
+	c.NativeBackend.Uniform1i(location, x)
+	if c.tracer != nil {
+		c.tracer.record("Uniform1i", nil, location, x)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// Uniform2f ...
+func (c *Context) Uniform2f(location *UniformLocation, x, y float32) {
+	c.checkThread()
+	c.NativeBackend.Uniform2f(location, x, y)
+	if c.tracer != nil {
+		c.tracer.record("Uniform2f", nil, location, x, y)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// Uniform3f ...
+func (c *Context) Uniform3f(location *UniformLocation, x, y, z float32) {
+	c.checkThread()
+	c.NativeBackend.Uniform3f(location, x, y, z)
+	if c.tracer != nil {
+		c.tracer.record("Uniform3f", nil, location, x, y, z)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// Uniform4f ...
+func (c *Context) Uniform4f(location *UniformLocation, x, y, z, w float32) {
+	c.checkThread()
+	c.NativeBackend.Uniform4f(location, x, y, z, w)
+	if c.tracer != nil {
+		c.tracer.record("Uniform4f", nil, location, x, y, z, w)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// UniformMatrix3fv ...
+func (c *Context) UniformMatrix3fv(location *UniformLocation, transpose bool, value []float32) {
+	c.checkThread()
+	c.NativeBackend.UniformMatrix3fv(location, transpose, value)
+	if c.tracer != nil {
+		c.tracer.record("UniformMatrix3fv", nil, location, transpose, value)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// UniformMatrix4fv ...
+func (c *Context) UniformMatrix4fv(location *UniformLocation, transpose bool, value []float32) {
+	c.checkThread()
+	c.NativeBackend.UniformMatrix4fv(location, transpose, value)
+	if c.tracer != nil {
+		c.tracer.record("UniformMatrix4fv", nil, location, transpose, value)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// CreateProgram ...
+func (c *Context) CreateProgram() *Program {
+	c.checkThread()
+	res := c.NativeBackend.CreateProgram()
+	c.created(res)
+	if c.tracer != nil {
+		c.tracer.record("CreateProgram", res)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+	return res
+}
+
+// AttachShader ...
+func (c *Context) AttachShader(program *Program, shader *Shader) {
+	c.checkThread()
+	c.NativeBackend.AttachShader(program, shader)
+	if c.tracer != nil {
+		c.tracer.record("AttachShader", nil, program, shader)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// CreateShader ...
+func (c *Context) CreateShader(typ int) *Shader {
+	c.checkThread()
+	res := c.NativeBackend.CreateShader(typ)
+	c.created(res)
+	if c.tracer != nil {
+		c.tracer.record("CreateShader", res, typ)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+	return res
+}
+
+// ShaderSource ...
+func (c *Context) ShaderSource(shader *Shader, source string) {
+	c.checkThread()
+	c.NativeBackend.ShaderSource(shader, source)
+	if c.tracer != nil {
+		c.tracer.record("ShaderSource", nil, shader, source)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// CompileShader ...
+func (c *Context) CompileShader(shader *Shader) {
+	c.checkThread()
+	c.NativeBackend.CompileShader(shader)
+	if c.tracer != nil {
+		c.tracer.record("CompileShader", nil, shader)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// GetShaderiv ...
+func (c *Context) GetShaderiv(shader *Shader, pname uint32) bool {
+	c.checkThread()
+	res := c.NativeBackend.GetShaderiv(shader, pname)
+	if c.tracer != nil {
+		c.tracer.record("GetShaderiv", res, shader, pname)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+	return res
+}
+
+// GetShaderInfoLog ...
+func (c *Context) GetShaderInfoLog(shader *Shader) string {
+	c.checkThread()
+	res := c.NativeBackend.GetShaderInfoLog(shader)
+	if c.tracer != nil {
+		c.tracer.record("GetShaderInfoLog", res, shader)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+	return res
+}
+
+// BindAttribLocation ...
+func (c *Context) BindAttribLocation(program *Program, index int, name string) {
+	c.checkThread()
+	c.NativeBackend.BindAttribLocation(program, index, name)
+	if c.tracer != nil {
+		c.tracer.record("BindAttribLocation", nil, program, index, name)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// DeleteShader ...
+func (c *Context) DeleteShader(shader *Shader) {
+	c.checkThread()
+	c.NativeBackend.DeleteShader(shader)
+	c.deleted(shader)
+	if c.tracer != nil {
+		c.tracer.record("DeleteShader", nil, shader)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// DeleteTexture ...
+func (c *Context) DeleteTexture(texture *Texture) {
+	c.checkThread()
+	c.NativeBackend.DeleteTexture(texture)
+	c.deleted(texture)
+	c.state.forgetTexture(texture)
+	if c.tracer != nil {
+		c.tracer.record("DeleteTexture", nil, texture)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// DeleteBuffer ...
+func (c *Context) DeleteBuffer(buffer *Buffer) {
+	c.checkThread()
+	c.NativeBackend.DeleteBuffer(buffer)
+	c.deleted(buffer)
+	c.state.forgetBuffer(buffer)
+	if c.tracer != nil {
+		c.tracer.record("DeleteBuffer", nil, buffer)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// DeleteVertexArray ...
+func (c *Context) DeleteVertexArray(vao *VertexArray) {
+	c.checkThread()
+	c.NativeBackend.DeleteVertexArray(vao)
+	c.deleted(vao)
+	c.state.forgetVertexArray(vao, c.ELEMENT_ARRAY_BUFFER)
+	if c.tracer != nil {
+		c.tracer.record("DeleteVertexArray", nil, vao)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// CreateVertexArray ...
+func (c *Context) CreateVertexArray() *VertexArray {
+	c.checkThread()
+	res := c.NativeBackend.CreateVertexArray()
+	c.created(res)
+	if c.tracer != nil {
+		c.tracer.record("CreateVertexArray", res)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+	return res
+}
+
+// BindVertexArray ...
+func (c *Context) BindVertexArray(vao *VertexArray) {
+	c.checkThread()
+	if c.state.bindVertexArray(vao, c.ELEMENT_ARRAY_BUFFER) {
+		return
+	}
+	c.NativeBackend.BindVertexArray(vao)
+	if c.tracer != nil {
+		c.tracer.record("BindVertexArray", nil, vao)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// EnableVertexAttribArray ...
+func (c *Context) EnableVertexAttribArray(index int) {
+	c.checkThread()
+	c.NativeBackend.EnableVertexAttribArray(index)
+	if c.tracer != nil {
+		c.tracer.record("EnableVertexAttribArray", nil, index)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// DisableVertexAttribArray ...
+func (c *Context) DisableVertexAttribArray(index int) {
+	c.checkThread()
+	c.NativeBackend.DisableVertexAttribArray(index)
+	if c.tracer != nil {
+		c.tracer.record("DisableVertexAttribArray", nil, index)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// VertexAttribPointer ...
+func (c *Context) VertexAttribPointer(index, size, typ int, normal bool, stride int, offset int) {
+	c.checkThread()
+	c.NativeBackend.VertexAttribPointer(index, size, typ, normal, stride, offset)
+	if c.tracer != nil {
+		c.tracer.record("VertexAttribPointer", nil, index, size, typ, normal, stride, offset)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// Enable ...
+func (c *Context) Enable(flag int) {
+	c.checkThread()
+	if c.state.enable("Enable", flag, true) {
+		return
+	}
+	c.NativeBackend.Enable(flag)
+	if c.tracer != nil {
+		c.tracer.record("Enable", nil, flag)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// Disable ...
+func (c *Context) Disable(flag int) {
+	c.checkThread()
+	if c.state.enable("Disable", flag, false) {
+		return
+	}
+	c.NativeBackend.Disable(flag)
+	if c.tracer != nil {
+		c.tracer.record("Disable", nil, flag)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// BlendFunc ...
+func (c *Context) BlendFunc(src, dst int) {
+	c.checkThread()
+	if c.state.blendFunc(src, dst) {
+		return
+	}
+	c.NativeBackend.BlendFunc(src, dst)
+	if c.tracer != nil {
+		c.tracer.record("BlendFunc", nil, src, dst)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// BlendEquation ...
+func (c *Context) BlendEquation(mode int) {
+	c.checkThread()
+	c.NativeBackend.BlendEquation(mode)
+	if c.tracer != nil {
+		c.tracer.record("BlendEquation", nil, mode)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// CreateBuffer ...
+func (c *Context) CreateBuffer() *Buffer {
+	c.checkThread()
+	res := c.NativeBackend.CreateBuffer()
+	c.created(res)
+	if c.tracer != nil {
+		c.tracer.record("CreateBuffer", res)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+	return res
+}
+
+// BindBuffer ...
+func (c *Context) BindBuffer(target int, buffer *Buffer) {
+	c.checkThread()
+	if c.state.bindBuffer(target, buffer) {
+		return
+	}
+	c.NativeBackend.BindBuffer(target, buffer)
+	if c.tracer != nil {
+		c.tracer.record("BindBuffer", nil, target, buffer)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// BufferData creates the store of the buffer bound to target from a slice
+// of numbers. An int data is the size in bytes of a store left
+// uninitialized, which orphans the previous one rather than waiting for the
+// draws that use it.
+func (c *Context) BufferData(target int, data interface{}, usage int) {
+	c.checkThread()
+	c.NativeBackend.BufferData(target, data, usage)
+	if c.tracer != nil {
+		c.tracer.record("BufferData", nil, target, data, usage)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// DrawElements ...
+func (c *Context) DrawElements(mode, count, typ, offset int) {
+	c.checkThread()
+	c.NativeBackend.DrawElements(mode, count, typ, offset)
+	if c.tracer != nil {
+		c.tracer.record("DrawElements", nil, mode, count, typ, offset)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// ClearColor ...
+func (c *Context) ClearColor(r, g, b, a float32) {
+	c.checkThread()
+	c.NativeBackend.ClearColor(r, g, b, a)
+	if c.tracer != nil {
+		c.tracer.record("ClearColor", nil, r, g, b, a)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// Clear ...
+func (c *Context) Clear(flags int) {
+	c.checkThread()
+	c.NativeBackend.Clear(flags)
+	if c.tracer != nil {
+		c.tracer.record("Clear", nil, flags)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// Viewport ...
+func (c *Context) Viewport(x, y, width, height int) {
+	c.checkThread()
+	if c.state.setViewport(x, y, width, height) {
+		return
+	}
+	c.NativeBackend.Viewport(x, y, width, height)
+	if c.tracer != nil {
+		c.tracer.record("Viewport", nil, x, y, width, height)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// CreateTexture ...
+func (c *Context) CreateTexture() *Texture {
+	c.checkThread()
+	res := c.NativeBackend.CreateTexture()
+	c.created(res)
+	if c.tracer != nil {
+		c.tracer.record("CreateTexture", res)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+	return res
+}
+
+// BindTexture ...
+func (c *Context) BindTexture(target int, texture *Texture) {
+	c.checkThread()
+	if c.state.bindTexture(target, texture) {
+		return
+	}
+	c.NativeBackend.BindTexture(target, texture)
+	if c.tracer != nil {
+		c.tracer.record("BindTexture", nil, target, texture)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// ActiveTexture ...
+func (c *Context) ActiveTexture(texture int) {
+	c.checkThread()
+	if c.state.activeTexture(texture) {
+		return
+	}
+	c.NativeBackend.ActiveTexture(texture)
+	if c.tracer != nil {
+		c.tracer.record("ActiveTexture", nil, texture)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// TexParameteri ...
+func (c *Context) TexParameteri(target int, pname int, param int) {
+	c.checkThread()
+	c.NativeBackend.TexParameteri(target, pname, param)
+	if c.tracer != nil {
+		c.tracer.record("TexParameteri", nil, target, pname, param)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// TexImage2D ...
+func (c *Context) TexImage2D(target, level, internalFormat, width, height, format, kind int, data interface{}) {
+	c.checkThread()
+	c.NativeBackend.TexImage2D(target, level, internalFormat, width, height, format, kind, data)
+	if c.tracer != nil {
+		c.tracer.record("TexImage2D", nil, target, level, internalFormat, width, height, format, kind, data)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// DeleteRenderbuffer ...
+func (c *Context) DeleteRenderbuffer(vao *RenderBuffer) {
+	c.checkThread()
+	c.NativeBackend.DeleteRenderbuffer(vao)
+	c.deleted(vao)
+	if c.tracer != nil {
+		c.tracer.record("DeleteRenderbuffer", nil, vao)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// CreateRenderbuffer ...
+func (c *Context) CreateRenderbuffer() *RenderBuffer {
+	c.checkThread()
+	res := c.NativeBackend.CreateRenderbuffer()
+	c.created(res)
+	if c.tracer != nil {
+		c.tracer.record("CreateRenderbuffer", res)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+	return res
+}
+
+// BindRenderbuffer ...
+func (c *Context) BindRenderbuffer(target int, vao *RenderBuffer) {
+	c.checkThread()
+	c.NativeBackend.BindRenderbuffer(target, vao)
+	if c.tracer != nil {
+		c.tracer.record("BindRenderbuffer", nil, target, vao)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// DeleteFramebuffer ...
+func (c *Context) DeleteFramebuffer(vao *FrameBuffer) {
+	c.checkThread()
+	c.NativeBackend.DeleteFramebuffer(vao)
+	c.deleted(vao)
+	if c.tracer != nil {
+		c.tracer.record("DeleteFramebuffer", nil, vao)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// CreateFramebuffer ...
+func (c *Context) CreateFramebuffer() *FrameBuffer {
+	c.checkThread()
+	res := c.NativeBackend.CreateFramebuffer()
+	c.created(res)
+	if c.tracer != nil {
+		c.tracer.record("CreateFramebuffer", res)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+	return res
+}
+
+// BindFramebuffer ...
+func (c *Context) BindFramebuffer(target int, vao *FrameBuffer) {
+	c.checkThread()
+	c.NativeBackend.BindFramebuffer(target, vao)
+	if c.tracer != nil {
+		c.tracer.record("BindFramebuffer", nil, target, vao)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// FramebufferRenderbuffer ...
+func (c *Context) FramebufferRenderbuffer(target, attachment, renderbuffertarget int, renderbuffer *RenderBuffer) {
+	c.checkThread()
+	c.NativeBackend.FramebufferRenderbuffer(target, attachment, renderbuffertarget, renderbuffer)
+	if c.tracer != nil {
+		c.tracer.record("FramebufferRenderbuffer", nil, target, attachment, renderbuffertarget, renderbuffer)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// CheckFramebufferStatus ...
+func (c *Context) CheckFramebufferStatus(target int) int {
+	c.checkThread()
+	res := c.NativeBackend.CheckFramebufferStatus(target)
+	if c.tracer != nil {
+		c.tracer.record("CheckFramebufferStatus", res, target)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+	return res
+}
+
+// RenderbufferStorage ...
+func (c *Context) RenderbufferStorage(target, internalFormat, width, height int) {
+	c.checkThread()
+	c.NativeBackend.RenderbufferStorage(target, internalFormat, width, height)
+	if c.tracer != nil {
+		c.tracer.record("RenderbufferStorage", nil, target, internalFormat, width, height)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// FramebufferTexture2D ...
+func (c *Context) FramebufferTexture2D(target, attachment, textarget int, texture *Texture, level int) {
+	c.checkThread()
+	c.NativeBackend.FramebufferTexture2D(target, attachment, textarget, texture, level)
+	if c.tracer != nil {
+		c.tracer.record("FramebufferTexture2D", nil, target, attachment, textarget, texture, level)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// DrawBuffer ...
+func (c *Context) DrawBuffer(buf int) {
+	c.checkThread()
+	c.NativeBackend.DrawBuffer(buf)
+	if c.tracer != nil {
+		c.tracer.record("DrawBuffer", nil, buf)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// Flush ...
+func (c *Context) Flush() {
+	c.checkThread()
+	c.NativeBackend.Flush()
+	if c.tracer != nil {
+		c.tracer.record("Flush", nil)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// ReadBuffer ...
+func (c *Context) ReadBuffer(src int) {
+	c.checkThread()
+	c.NativeBackend.ReadBuffer(src)
+	if c.tracer != nil {
+		c.tracer.record("ReadBuffer", nil, src)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// ReadPixels ...
+func (c *Context) ReadPixels(x, y, width, height, format, typ int, pixels interface{}) {
+	c.checkThread()
+	c.NativeBackend.ReadPixels(x, y, width, height, format, typ, pixels)
+	if c.tracer != nil {
+		c.tracer.record("ReadPixels", nil, x, y, width, height, format, typ, traceOutput{pixels})
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// DrawArrays ...
+func (c *Context) DrawArrays(mode, first, count int) {
+	c.checkThread()
+	c.NativeBackend.DrawArrays(mode, first, count)
+	if c.tracer != nil {
+		c.tracer.record("DrawArrays", nil, mode, first, count)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// Finish ...
+func (c *Context) Finish() {
+	c.checkThread()
+	c.NativeBackend.Finish()
+	if c.tracer != nil {
+		c.tracer.record("Finish", nil)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// GetSupportedExtensions ...
+func (c *Context) GetSupportedExtensions() []string {
+	c.checkThread()
+	res := c.NativeBackend.GetSupportedExtensions()
+	if c.tracer != nil {
+		c.tracer.record("GetSupportedExtensions", nil)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+	return res
+}
+
+// IsEnabled ...
+func (c *Context) IsEnabled(flag int) bool {
+	c.checkThread()
+	res := c.NativeBackend.IsEnabled(flag)
+	if c.tracer != nil {
+		c.tracer.record("IsEnabled", res, flag)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+	return res
+}
+
+// BlendColor ...
+func (c *Context) BlendColor(r, g, b, a float32) {
+	c.checkThread()
+	c.NativeBackend.BlendColor(r, g, b, a)
+	if c.tracer != nil {
+		c.tracer.record("BlendColor", nil, r, g, b, a)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// BlendFuncSeparate ...
+func (c *Context) BlendFuncSeparate(srcRGB, dstRGB, srcAlpha, dstAlpha int) {
+	c.checkThread()
+	c.state.blendFuncSeparate(srcRGB, dstRGB, srcAlpha, dstAlpha)
+	c.NativeBackend.BlendFuncSeparate(srcRGB, dstRGB, srcAlpha, dstAlpha)
+	if c.tracer != nil {
+		c.tracer.record("BlendFuncSeparate", nil, srcRGB, dstRGB, srcAlpha, dstAlpha)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// BlendEquationSeparate ...
+func (c *Context) BlendEquationSeparate(modeRGB, modeAlpha int) {
+	c.checkThread()
+	c.NativeBackend.BlendEquationSeparate(modeRGB, modeAlpha)
+	if c.tracer != nil {
+		c.tracer.record("BlendEquationSeparate", nil, modeRGB, modeAlpha)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// ClearDepth ...
+func (c *Context) ClearDepth(depth float32) {
+	c.checkThread()
+	c.NativeBackend.ClearDepth(depth)
+	if c.tracer != nil {
+		c.tracer.record("ClearDepth", nil, depth)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// ClearStencil ...
+func (c *Context) ClearStencil(s int) {
+	c.checkThread()
+	c.NativeBackend.ClearStencil(s)
+	if c.tracer != nil {
+		c.tracer.record("ClearStencil", nil, s)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// ColorMask ...
+func (c *Context) ColorMask(r, g, b, a bool) {
+	c.checkThread()
+	c.NativeBackend.ColorMask(r, g, b, a)
+	if c.tracer != nil {
+		c.tracer.record("ColorMask", nil, r, g, b, a)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// CullFace ...
+func (c *Context) CullFace(mode int) {
+	c.checkThread()
+	c.NativeBackend.CullFace(mode)
+	if c.tracer != nil {
+		c.tracer.record("CullFace", nil, mode)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// FrontFace ...
+func (c *Context) FrontFace(mode int) {
+	c.checkThread()
+	c.NativeBackend.FrontFace(mode)
+	if c.tracer != nil {
+		c.tracer.record("FrontFace", nil, mode)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// DepthFunc ...
+func (c *Context) DepthFunc(fn int) {
+	c.checkThread()
+	c.NativeBackend.DepthFunc(fn)
+	if c.tracer != nil {
+		c.tracer.record("DepthFunc", nil, fn)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// DepthMask ...
+func (c *Context) DepthMask(flag bool) {
+	c.checkThread()
+	c.NativeBackend.DepthMask(flag)
+	if c.tracer != nil {
+		c.tracer.record("DepthMask", nil, flag)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// DepthRange ...
+func (c *Context) DepthRange(zNear, zFar float32) {
+	c.checkThread()
+	c.NativeBackend.DepthRange(zNear, zFar)
+	if c.tracer != nil {
+		c.tracer.record("DepthRange", nil, zNear, zFar)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// LineWidth ...
+func (c *Context) LineWidth(width float32) {
+	c.checkThread()
+	c.NativeBackend.LineWidth(width)
+	if c.tracer != nil {
+		c.tracer.record("LineWidth", nil, width)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// PixelStorei ...
+func (c *Context) PixelStorei(pname, param int) {
+	c.checkThread()
+	c.NativeBackend.PixelStorei(pname, param)
+	if c.tracer != nil {
+		c.tracer.record("PixelStorei", nil, pname, param)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// PolygonOffset ...
+func (c *Context) PolygonOffset(factor, units float32) {
+	c.checkThread()
+	c.NativeBackend.PolygonOffset(factor, units)
+	if c.tracer != nil {
+		c.tracer.record("PolygonOffset", nil, factor, units)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// SampleCoverage ...
+func (c *Context) SampleCoverage(value float32, invert bool) {
+	c.checkThread()
+	c.NativeBackend.SampleCoverage(value, invert)
+	if c.tracer != nil {
+		c.tracer.record("SampleCoverage", nil, value, invert)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// Scissor ...
+func (c *Context) Scissor(x, y, width, height int) {
+	c.checkThread()
+	c.NativeBackend.Scissor(x, y, width, height)
+	if c.tracer != nil {
+		c.tracer.record("Scissor", nil, x, y, width, height)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// StencilFunc ...
+func (c *Context) StencilFunc(fn, ref, mask int) {
+	c.checkThread()
+	c.NativeBackend.StencilFunc(fn, ref, mask)
+	if c.tracer != nil {
+		c.tracer.record("StencilFunc", nil, fn, ref, mask)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// StencilFuncSeparate ...
+func (c *Context) StencilFuncSeparate(face, fn, ref, mask int) {
+	c.checkThread()
+	c.NativeBackend.StencilFuncSeparate(face, fn, ref, mask)
+	if c.tracer != nil {
+		c.tracer.record("StencilFuncSeparate", nil, face, fn, ref, mask)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// IsProgram ...
+func (c *Context) IsProgram(program *Program) bool {
+	c.checkThread()
+	res := c.NativeBackend.IsProgram(program)
+	if c.tracer != nil {
+		c.tracer.record("IsProgram", res, program)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+	return res
+}
+
+// DetachShader ...
+func (c *Context) DetachShader(program *Program, shader *Shader) {
+	c.checkThread()
+	c.NativeBackend.DetachShader(program, shader)
+	if c.tracer != nil {
+		c.tracer.record("DetachShader", nil, program, shader)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// GetAttachedShaders ...
+func (c *Context) GetAttachedShaders(program *Program) []*Shader {
+	c.checkThread()
+	res := c.NativeBackend.GetAttachedShaders(program)
+	if c.tracer != nil {
+		c.tracer.record("GetAttachedShaders", nil, program)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+	return res
+}
+
+// GetActiveAttrib ...
+func (c *Context) GetActiveAttrib(program *Program, index int) (name string, size, typ int) {
+	c.checkThread()
+	name, size, typ = c.NativeBackend.GetActiveAttrib(program, index)
+	if c.tracer != nil {
+		c.tracer.record("GetActiveAttrib", name, program, index)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+	return name, size, typ
+}
+
+// GetActiveUniform ...
+func (c *Context) GetActiveUniform(program *Program, index int) (name string, size, typ int) {
+	c.checkThread()
+	name, size, typ = c.NativeBackend.GetActiveUniform(program, index)
+	if c.tracer != nil {
+		c.tracer.record("GetActiveUniform", name, program, index)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+	return name, size, typ
+}
+
+// IsShader ...
+func (c *Context) IsShader(shader *Shader) bool {
+	c.checkThread()
+	res := c.NativeBackend.IsShader(shader)
+	if c.tracer != nil {
+		c.tracer.record("IsShader", res, shader)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+	return res
+}
+
+// GetShaderSource ...
+func (c *Context) GetShaderSource(shader *Shader) string {
+	c.checkThread()
+	res := c.NativeBackend.GetShaderSource(shader)
+	if c.tracer != nil {
+		c.tracer.record("GetShaderSource", res, shader)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+	return res
+}
+
+// GetShaderParameterb ...
+func (c *Context) GetShaderParameterb(shader *Shader, pname int) bool {
+	c.checkThread()
+	res := c.NativeBackend.GetShaderParameterb(shader, pname)
+	if c.tracer != nil {
+		c.tracer.record("GetShaderParameterb", res, shader, pname)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+	return res
+}
+
+// GetShaderParameteri ...
+func (c *Context) GetShaderParameteri(shader *Shader, pname int) int {
+	c.checkThread()
+	res := c.NativeBackend.GetShaderParameteri(shader, pname)
+	if c.tracer != nil {
+		c.tracer.record("GetShaderParameteri", res, shader, pname)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+	return res
+}
+
+// Uniform2i ...
+func (c *Context) Uniform2i(location *UniformLocation, x, y int) {
+	c.checkThread()
+	c.NativeBackend.Uniform2i(location, x, y)
+	if c.tracer != nil {
+		c.tracer.record("Uniform2i", nil, location, x, y)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// Uniform3i ...
+func (c *Context) Uniform3i(location *UniformLocation, x, y, z int) {
+	c.checkThread()
+	c.NativeBackend.Uniform3i(location, x, y, z)
+	if c.tracer != nil {
+		c.tracer.record("Uniform3i", nil, location, x, y, z)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// Uniform4i ...
+func (c *Context) Uniform4i(location *UniformLocation, x, y, z, w int) {
+	c.checkThread()
+	c.NativeBackend.Uniform4i(location, x, y, z, w)
+	if c.tracer != nil {
+		c.tracer.record("Uniform4i", nil, location, x, y, z, w)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// UniformMatrix2fv ...
+func (c *Context) UniformMatrix2fv(location *UniformLocation, transpose bool, value []float32) {
+	c.checkThread()
+	c.NativeBackend.UniformMatrix2fv(location, transpose, value)
+	if c.tracer != nil {
+		c.tracer.record("UniformMatrix2fv", nil, location, transpose, value)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// IsBuffer ...
+func (c *Context) IsBuffer(buffer *Buffer) bool {
+	c.checkThread()
+	res := c.NativeBackend.IsBuffer(buffer)
+	if c.tracer != nil {
+		c.tracer.record("IsBuffer", res, buffer)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+	return res
+}
+
+// BufferSubData ...
+func (c *Context) BufferSubData(target int, offset int, data interface{}) {
+	c.checkThread()
+	c.NativeBackend.BufferSubData(target, offset, data)
+	if c.tracer != nil {
+		c.tracer.record("BufferSubData", nil, target, offset, data)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// GetBufferParameter ...
+func (c *Context) GetBufferParameter(target, pname int) int {
+	c.checkThread()
+	res := c.NativeBackend.GetBufferParameter(target, pname)
+	if c.tracer != nil {
+		c.tracer.record("GetBufferParameter", res, target, pname)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+	return res
+}
+
+// BindBufferBase ...
+func (c *Context) BindBufferBase(target, index int, buffer *Buffer) {
+	c.checkThread()
+	// it binds the generic binding point too
+	c.state.bindBuffer(target, buffer)
+	c.NativeBackend.BindBufferBase(target, index, buffer)
+	if c.tracer != nil {
+		c.tracer.record("BindBufferBase", nil, target, index, buffer)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// GetUniformBlockIndex ...
+func (c *Context) GetUniformBlockIndex(program *Program, name string) int {
+	c.checkThread()
+	res := c.NativeBackend.GetUniformBlockIndex(program, name)
+	if c.tracer != nil {
+		c.tracer.record("GetUniformBlockIndex", res, program, name)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+	return res
+}
+
+// UniformBlockBinding ...
+func (c *Context) UniformBlockBinding(program *Program, blockIndex, binding int) {
+	c.checkThread()
+	c.NativeBackend.UniformBlockBinding(program, blockIndex, binding)
+	if c.tracer != nil {
+		c.tracer.record("UniformBlockBinding", nil, program, blockIndex, binding)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// GetProgramBinary ...
+func (c *Context) GetProgramBinary(program *Program) (binary []byte, format int) {
+	c.checkThread()
+	binary, format = c.NativeBackend.GetProgramBinary(program)
+	if c.tracer != nil {
+		c.tracer.record("GetProgramBinary", format, program)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+	return binary, format
+}
+
+// ProgramBinary ...
+func (c *Context) ProgramBinary(program *Program, format int, binary []byte) {
+	c.checkThread()
+	c.NativeBackend.ProgramBinary(program, format, binary)
+	if c.tracer != nil {
+		c.tracer.record("ProgramBinary", nil, program, format, binary)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// ProgramParameteri sets PROGRAM_BINARY_RETRIEVABLE_HINT, which asks the
+// driver to keep the binary of the program at its next link.
+func (c *Context) ProgramParameteri(program *Program, pname, value int) {
+	c.checkThread()
+	c.NativeBackend.ProgramParameteri(program, pname, value)
+	if c.tracer != nil {
+		c.tracer.record("ProgramParameteri", nil, program, pname, value)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// VertexAttribDivisor makes the attribute at index advance once every
+// divisor instances of the instanced draws, once per vertex when 0.
+func (c *Context) VertexAttribDivisor(index, divisor int) {
+	c.checkThread()
+	c.NativeBackend.VertexAttribDivisor(index, divisor)
+	if c.tracer != nil {
+		c.tracer.record("VertexAttribDivisor", nil, index, divisor)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// DrawArraysInstanced draws the vertices from first instances times.
+func (c *Context) DrawArraysInstanced(mode, first, count, instances int) {
+	c.checkThread()
+	c.NativeBackend.DrawArraysInstanced(mode, first, count, instances)
+	if c.tracer != nil {
+		c.tracer.record("DrawArraysInstanced", nil, mode, first, count, instances)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// DrawElementsInstanced draws the indices at offset instances times.
+func (c *Context) DrawElementsInstanced(mode, count, typ, offset, instances int) {
+	c.checkThread()
+	c.NativeBackend.DrawElementsInstanced(mode, count, typ, offset, instances)
+	if c.tracer != nil {
+		c.tracer.record("DrawElementsInstanced", nil, mode, count, typ, offset, instances)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// DispatchCompute runs the compute program in use on x*y*z work groups. It
+// fails with INVALID_OPERATION without Caps.ComputeShaders.
+func (c *Context) DispatchCompute(x, y, z int) {
+	c.checkThread()
+	c.NativeBackend.DispatchCompute(x, y, z)
+	if c.tracer != nil {
+		c.tracer.record("DispatchCompute", nil, x, y, z)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// MemoryBarrier orders the writes of the shaders before the reads of the
+// barriers bits, SHADER_STORAGE_BARRIER_BIT and so on.
+func (c *Context) MemoryBarrier(barriers int) {
+	c.checkThread()
+	c.NativeBackend.MemoryBarrier(barriers)
+	if c.tracer != nil {
+		c.tracer.record("MemoryBarrier", nil, barriers)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// PatchParameteri sets PATCH_VERTICES, the vertices of the PATCHES a
+// tessellation program draws.
+func (c *Context) PatchParameteri(pname, value int) {
+	c.checkThread()
+	c.NativeBackend.PatchParameteri(pname, value)
+	if c.tracer != nil {
+		c.tracer.record("PatchParameteri", nil, pname, value)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// GetVertexAttribOffset ...
+func (c *Context) GetVertexAttribOffset(index, pname int) int {
+	c.checkThread()
+	res := c.NativeBackend.GetVertexAttribOffset(index, pname)
+	if c.tracer != nil {
+		c.tracer.record("GetVertexAttribOffset", res, index, pname)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+	return res
+}
+
+// IsTexture ...
+func (c *Context) IsTexture(texture *Texture) bool {
+	c.checkThread()
+	res := c.NativeBackend.IsTexture(texture)
+	if c.tracer != nil {
+		c.tracer.record("IsTexture", res, texture)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+	return res
+}
+
+// TexSubImage2D ...
+func (c *Context) TexSubImage2D(target, level, xoffset, yoffset, width, height, format, kind int, data interface{}) {
+	c.checkThread()
+	c.NativeBackend.TexSubImage2D(target, level, xoffset, yoffset, width, height, format, kind, data)
+	if c.tracer != nil {
+		c.tracer.record("TexSubImage2D", nil, target, level, xoffset, yoffset, width, height, format, kind, data)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// CopyTexImage2D ...
+func (c *Context) CopyTexImage2D(target, level, internalFormat, x, y, width, height, border int) {
+	c.checkThread()
+	c.NativeBackend.CopyTexImage2D(target, level, internalFormat, x, y, width, height, border)
+	if c.tracer != nil {
+		c.tracer.record("CopyTexImage2D", nil, target, level, internalFormat, x, y, width, height, border)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// CopyTexSubImage2D ...
+func (c *Context) CopyTexSubImage2D(target, level, xoffset, yoffset, x, y, width, height int) {
+	c.checkThread()
+	c.NativeBackend.CopyTexSubImage2D(target, level, xoffset, yoffset, x, y, width, height)
+	if c.tracer != nil {
+		c.tracer.record("CopyTexSubImage2D", nil, target, level, xoffset, yoffset, x, y, width, height)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// GenerateMipmap ...
+func (c *Context) GenerateMipmap(target int) {
+	c.checkThread()
+	c.NativeBackend.GenerateMipmap(target)
+	if c.tracer != nil {
+		c.tracer.record("GenerateMipmap", nil, target)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+}
+
+// IsFramebuffer ...
+func (c *Context) IsFramebuffer(framebuffer *FrameBuffer) bool {
+	c.checkThread()
+	res := c.NativeBackend.IsFramebuffer(framebuffer)
+	if c.tracer != nil {
+		c.tracer.record("IsFramebuffer", res, framebuffer)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+	return res
+}
+
+// GetFramebufferAttachmentParameter ...
+func (c *Context) GetFramebufferAttachmentParameter(target, attachment, pname int) int {
+	c.checkThread()
+	res := c.NativeBackend.GetFramebufferAttachmentParameter(target, attachment, pname)
+	if c.tracer != nil {
+		c.tracer.record("GetFramebufferAttachmentParameter", res, target, attachment, pname)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+	return res
+}
+
+// IsRenderbuffer ...
+func (c *Context) IsRenderbuffer(renderbuffer *RenderBuffer) bool {
+	c.checkThread()
+	res := c.NativeBackend.IsRenderbuffer(renderbuffer)
+	if c.tracer != nil {
+		c.tracer.record("IsRenderbuffer", res, renderbuffer)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+	return res
+}
+
+// GetRenderbufferParameter ...
+func (c *Context) GetRenderbufferParameter(target, pname int) int {
+	c.checkThread()
+	res := c.NativeBackend.GetRenderbufferParameter(target, pname)
+	if c.tracer != nil {
+		c.tracer.record("GetRenderbufferParameter", res, target, pname)
+	}
+	if c.debug != nil {
+		c.checkError()
+	}
+	return res
+}
@@ -0,0 +1,232 @@
+//+build !netgo,!android
+
+package glplus
+
+import (
+	"fmt"
+	"log"
+	"runtime"
+	"strings"
+)
+
+// DebugSeverity ...
+type DebugSeverity int
+
+// Severities of the debug messages, as in KHR_debug.
+const (
+	DebugNotification DebugSeverity = iota
+	DebugLow
+	DebugMedium
+	DebugHigh
+)
+
+func (s DebugSeverity) String() string {
+	switch s {
+	case DebugLow:
+		return "low"
+	case DebugMedium:
+		return "medium"
+	case DebugHigh:
+		return "high"
+	}
+	return "notification"
+}
+
+// DebugMessage is a message of the GL debug output, or a GL error read by
+// the debug mode when the debug output is not available, ID is then the
+// error code.
+type DebugMessage struct {
+	// Source is API, WINDOW_SYSTEM, SHADER_COMPILER, THIRD_PARTY,
+	// APPLICATION or OTHER.
+	Source string
+	// Type is ERROR, DEPRECATED_BEHAVIOR, UNDEFINED_BEHAVIOR, PORTABILITY,
+	// PERFORMANCE, MARKER or OTHER.
+	Type     string
+	ID       uint32
+	Severity DebugSeverity
+	Message  string
+
+	// Method is the Context method running when the message came.
+	Method string
+	// Stack is the caller of Method.
+	Stack []runtime.Frame
+}
+
+func (m *DebugMessage) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "GL %s %s (%s severity) in Context.%s: %s", m.Source, m.Type, m.Severity, m.Method, m.Message)
+	for _, f := range m.Stack {
+		fmt.Fprintf(&b, "\n\t%s\n\t\t%s:%d", f.Function, f.File, f.Line)
+	}
+	return b.String()
+}
+
+// DebugLogger receives the debug messages.
+type DebugLogger interface {
+	LogDebug(msg *DebugMessage)
+}
+
+// DebugLoggerFunc ...
+type DebugLoggerFunc func(msg *DebugMessage)
+
+// LogDebug ...
+func (f DebugLoggerFunc) LogDebug(msg *DebugMessage) {
+	f(msg)
+}
+
+// StdDebugLogger prints the messages of at least severity Min with the log
+// package.
+type StdDebugLogger struct {
+	Min DebugSeverity
+}
+
+// LogDebug ...
+func (l StdDebugLogger) LogDebug(msg *DebugMessage) {
+	if msg.Severity >= l.Min {
+		log.Println(msg)
+	}
+}
+
+// GLError is a GL error with the Context method that raised it.
+type GLError struct {
+	Method string
+	// Code is the GetError value, 0 when the debug output reported the
+	// error.
+	Code    int
+	Message string
+	Stack   []runtime.Frame
+}
+
+func (e *GLError) Error() string {
+	return fmt.Sprintf("glplus: %s in Context.%s", e.Message, e.Method)
+}
+
+// debugOutput is implemented by the backends having KHR_debug.
+type debugOutput interface {
+	EnableDebugOutput(report func(msg *DebugMessage)) bool
+	DisableDebugOutput()
+}
+
+type debugState struct {
+	logger DebugLogger
+	// native is set when the backend reports through KHR_debug
+	native bool
+	// pending is the error the debug mode read, GetError returns it next
+	pending int
+	// err is the first error since DebugError
+	err *GLError
+}
+
+// EnableDebug reports the GL messages to logger. It uses the debug output of
+// the backend when it has one, otherwise it calls GetError after every call.
+// It returns whether the debug output is used.
+func (c *Context) EnableDebug(logger DebugLogger) (native bool) {
+	c.checkThread()
+	c.DisableDebug()
+	c.debug = &debugState{logger: logger}
+	if out, ok := c.NativeBackend.(debugOutput); ok {
+		c.debug.native = out.EnableDebugOutput(c.reportDebug)
+	}
+	return c.debug.native
+}
+
+// DisableDebug ...
+func (c *Context) DisableDebug() {
+	c.checkThread()
+	if c.debug == nil {
+		return
+	}
+	if c.debug.native {
+		c.NativeBackend.(debugOutput).DisableDebugOutput()
+	}
+	c.debug = nil
+}
+
+// DebugError returns the first GL error since the previous call, nil when
+// there was none or the debug mode is off.
+func (c *Context) DebugError() error {
+	if c.debug == nil || c.debug.err == nil {
+		return nil
+	}
+	err := c.debug.err
+	c.debug.err = nil
+	return err
+}
+
+// checkError reads the GL error after a call, when there is no debug output.
+func (c *Context) checkError() {
+	if c.debug.native {
+		return
+	}
+	code := c.NativeBackend.GetError()
+	if code == c.NO_ERROR {
+		return
+	}
+	if c.debug.pending == c.NO_ERROR {
+		c.debug.pending = code
+	}
+	c.reportDebug(&DebugMessage{
+		Source:   "API",
+		Type:     "ERROR",
+		ID:       uint32(code),
+		Severity: DebugHigh,
+		Message:  errorName(&c.Enums, code),
+	})
+}
+
+func (c *Context) reportDebug(msg *DebugMessage) {
+	msg.Method, msg.Stack = debugCaller()
+	if msg.Type == "ERROR" && c.debug.err == nil {
+		var code int
+		if !c.debug.native {
+			code = int(msg.ID)
+		}
+		c.debug.err = &GLError{Method: msg.Method, Code: code, Message: msg.Message, Stack: msg.Stack}
+	}
+	if c.debug.logger != nil {
+		c.debug.logger.LogDebug(msg)
+	}
+}
+
+// debugCaller returns the innermost Context method on the stack, the one
+// whose GL call raised the message, and the stack above it.
+func debugCaller() (method string, stack []runtime.Frame) {
+	const prefix = "glplus.(*Context)."
+	pc := make([]uintptr, 64)
+	n := runtime.Callers(2, pc)
+	frames := runtime.CallersFrames(pc[:n])
+	for {
+		f, more := frames.Next()
+		if method != "" {
+			stack = append(stack, f)
+		} else if i := strings.Index(f.Function, prefix); i >= 0 {
+			name := f.Function[i+len(prefix):]
+			// skip the closures and the debug mode itself
+			if !strings.Contains(name, ".") && name != "reportDebug" && name != "checkError" {
+				method = name
+			}
+		}
+		if !more {
+			break
+		}
+	}
+	return method, stack
+}
+
+func errorName(enums *Enums, code int) string {
+	switch code {
+	case enums.INVALID_ENUM:
+		return "INVALID_ENUM"
+	case enums.INVALID_VALUE:
+		return "INVALID_VALUE"
+	case enums.INVALID_OPERATION:
+		return "INVALID_OPERATION"
+	case enums.INVALID_FRAMEBUFFER_OPERATION:
+		return "INVALID_FRAMEBUFFER_OPERATION"
+	case enums.OUT_OF_MEMORY:
+		return "OUT_OF_MEMORY"
+	case enums.CONTEXT_LOST_WEBGL:
+		return "CONTEXT_LOST_WEBGL"
+	}
+	return fmt.Sprintf("GL error 0x%x", code)
+}
@@ -0,0 +1,48 @@
+package glplus
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDebugFallback(t *testing.T) {
+	defer softContext(t)()
+
+	var msgs []*DebugMessage
+	if Gl.EnableDebug(DebugLoggerFunc(func(msg *DebugMessage) { msgs = append(msgs, msg) })) {
+		t.Fatal("the soft backend has no debug output")
+	}
+	defer Gl.DisableDebug()
+
+	Gl.ActiveTexture(Gl.TEXTURE0)
+	Gl.BindTexture(Gl.TEXTURE_2D, &Texture{999})
+	Gl.ActiveTexture(Gl.TEXTURE0 + 1)
+
+	if len(msgs) != 1 {
+		t.Fatalf("got %d messages", len(msgs))
+	}
+	msg := msgs[0]
+	if msg.Method != "BindTexture" || msg.Type != "ERROR" || msg.Severity != DebugHigh || msg.Message != "INVALID_OPERATION" {
+		t.Errorf("got %+v", msg)
+	}
+	if len(msg.Stack) == 0 || !strings.HasSuffix(msg.Stack[0].Function, "TestDebugFallback") {
+		t.Errorf("stack %v", msg.Stack)
+	}
+
+	err := Gl.DebugError()
+	if err == nil || err.Error() != "glplus: INVALID_OPERATION in Context.BindTexture" {
+		t.Fatalf("got %v", err)
+	}
+	if err.(*GLError).Code != Gl.INVALID_OPERATION {
+		t.Errorf("code %d", err.(*GLError).Code)
+	}
+	if err = Gl.DebugError(); err != nil {
+		t.Errorf("got %v again", err)
+	}
+
+	// the error read by the debug mode is still returned once
+	if code := Gl.GetError(); code != Gl.INVALID_OPERATION {
+		t.Errorf("GetError %d", code)
+	}
+	checkGlError(t)
+}
@@ -0,0 +1,14 @@
+package glplus
+
+// deviceObjects are the objects shared by the resources of one context: GL
+// objects cannot be used by another context, so each has its own.
+type deviceObjects struct {
+	// progCache holds the linked programs by the MD5 of their sources
+	progCache map[string]*ProgramCache
+	// binaries is the program binary cache, if any
+	binaries *ProgramBinaryCache
+	// objPrograms are shared by the obj renders
+	objPrograms *objPrograms
+	// buttonProgram is shared by the buttons
+	buttonProgram *ButtonProgram
+}
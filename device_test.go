@@ -0,0 +1,93 @@
+package glplus
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func drawTexturedQuad(t *testing.T, c *Context, rgba color.RGBA) (*GPProgram, color.RGBA) {
+	src := image.NewRGBA(image.Rect(0, 0, 1, 1))
+	src.SetRGBA(0, 0, rgba)
+	tex, err := c.NewRGBATexture(src, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tex.DeleteTexture()
+	prog, err := c.LoadShaderProgram(sVertShaderRestore, sFragShaderRestore, []string{"position", "uvs"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	vbo := NewVBOQuad(prog, 0, 0, 1, 1)
+	defer vbo.DeleteVBO()
+
+	prog.UseProgram()
+	tex.BindTexture(0)
+	prog.ProgramUniform1i("tex1", 0)
+	vbo.Bind(prog)
+	vbo.Draw()
+	vbo.Unbind(prog)
+	tex.UnbindTexture(0)
+	prog.UnuseProgram()
+	if err := c.GetError(); err != c.NO_ERROR {
+		t.Fatalf("GL error %d", err)
+	}
+
+	var pixel [4]uint8
+	c.ReadPixels(8, 8, 1, 1, c.RGBA, c.UNSIGNED_BYTE, pixel[:])
+	return prog, color.RGBA{pixel[0], pixel[1], pixel[2], pixel[3]}
+}
+
+func TestMultipleContexts(t *testing.T) {
+	// nothing may fall back to the default context
+	saved := Gl
+	Gl = nil
+	defer func() { Gl = saved }()
+
+	a := NewBackendContext(NewSoftBackend(16, 16))
+	b := NewBackendContext(NewSoftBackend(16, 16))
+
+	red, green := color.RGBA{255, 0, 0, 255}, color.RGBA{0, 255, 0, 255}
+	progA, got := drawTexturedQuad(t, a, red)
+	if got != red {
+		t.Errorf("context a drew %v", got)
+	}
+	progB, got := drawTexturedQuad(t, b, green)
+	if got != green {
+		t.Errorf("context b drew %v", got)
+	}
+	if progA == progB {
+		t.Error("the contexts share a program")
+	}
+	if again, _ := a.LoadShaderProgram(sVertShaderRestore, sFragShaderRestore, nil); again != progA {
+		t.Error("the program cache of a missed")
+	} else {
+		again.DeleteProgram()
+	}
+
+	btnA, err := a.NewButton(image.Point{4, 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	btnB, err := b.NewButton(image.Point{4, 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.objects.buttonProgram == b.objects.buttonProgram {
+		t.Error("the contexts share the button program")
+	}
+
+	btnA.Delete()
+	progA.DeleteProgram()
+	if err := a.CheckLeaks(); err != nil {
+		t.Fatal(err)
+	}
+	if b.objects.buttonProgram == nil || len(b.objects.progCache) != 2 {
+		t.Error("deleting from a released objects of b")
+	}
+	btnB.Delete()
+	progB.DeleteProgram()
+	if err := b.CheckLeaks(); err != nil {
+		t.Fatal(err)
+	}
+}
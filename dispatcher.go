@@ -0,0 +1,183 @@
+//+build !netgo,!android
+
+package glplus
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"runtime"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrRenderThreadClosed is returned for the functions submitted to a closed
+// RenderThread.
+var ErrRenderThreadClosed = errors.New("glplus: RenderThread is closed")
+
+// RenderThread runs functions on a goroutine locked to its OS thread, the
+// one that owns the GL context. Other goroutines submit closures with Call,
+// Go or Batch:
+//
+//	rt := NewRenderThread()
+//	rt.Call(func() {
+//		window.MakeContextCurrent()
+//		Gl = NewContext()
+//	})
+//	rt.Own(Gl)
+//	...
+//	var pixels *image.RGBA
+//	rt.Call(func() { pixels = target.ReadBuffer(w, h) })
+type RenderThread struct {
+	calls chan func()
+	done  chan struct{}
+	id    int64
+
+	// mu guards closed and the sends on calls
+	mu     sync.Mutex
+	closed bool
+}
+
+// NewRenderThread starts the render goroutine.
+func NewRenderThread() *RenderThread {
+	r := &RenderThread{
+		calls: make(chan func(), 64),
+		done:  make(chan struct{}),
+	}
+	started := make(chan struct{})
+	go func() {
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+		r.id = goroutineID()
+		close(started)
+		for f := range r.calls {
+			f()
+		}
+		close(r.done)
+	}()
+	<-started
+	return r
+}
+
+// OnThread reports whether the caller runs on the render goroutine.
+func (r *RenderThread) OnThread() bool {
+	return goroutineID() == r.id
+}
+
+// Call runs f on the render goroutine and waits for it. It runs f right
+// away when called from the render goroutine. A panic in f is raised again
+// in the caller, and Call panics with ErrRenderThreadClosed after Close.
+func (r *RenderThread) Call(f func()) {
+	if err := r.call(f); err != nil {
+		panic(err)
+	}
+}
+
+func (r *RenderThread) call(f func()) error {
+	if r.OnThread() {
+		f()
+		return nil
+	}
+	var recovered interface{}
+	done := make(chan struct{})
+	if err := r.send(func() {
+		defer close(done)
+		defer func() { recovered = recover() }()
+		f()
+	}); err != nil {
+		return err
+	}
+	<-done
+	if recovered != nil {
+		panic(recovered)
+	}
+	return nil
+}
+
+// CallErr runs f on the render goroutine and returns its error, or
+// ErrRenderThreadClosed after Close.
+func (r *RenderThread) CallErr(f func() error) (err error) {
+	if closed := r.call(func() { err = f() }); closed != nil {
+		return closed
+	}
+	return err
+}
+
+// Go queues f on the render goroutine without waiting for it. It returns
+// ErrRenderThreadClosed after Close.
+func (r *RenderThread) Go(f func()) error {
+	if r.OnThread() {
+		f()
+		return nil
+	}
+	return r.send(f)
+}
+
+func (r *RenderThread) send(f func()) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return ErrRenderThreadClosed
+	}
+	r.calls <- f
+	return nil
+}
+
+// Batch runs the functions in order on the render goroutine, in a single
+// round trip, and waits for them.
+func (r *RenderThread) Batch(fs ...func()) {
+	r.Call(func() {
+		for _, f := range fs {
+			f()
+		}
+	})
+}
+
+// Own makes c panic when it is used off the render goroutine while its
+// debug mode is on, see EnableDebug: the check costs a stack header per
+// call.
+func (r *RenderThread) Own(c *Context) {
+	// the render goroutine may be using c
+	atomic.StoreInt64(&c.owner, r.id)
+}
+
+// Close runs the queued functions and stops the render goroutine. The
+// functions submitted after it are refused.
+func (r *RenderThread) Close() {
+	r.mu.Lock()
+	if !r.closed {
+		r.closed = true
+		close(r.calls)
+	}
+	r.mu.Unlock()
+	<-r.done
+}
+
+// goroutineID parses the id of the current goroutine out of its stack
+// header, "goroutine 18 [running]:".
+func goroutineID() int64 {
+	var buf [64]byte
+	n := runtime.Stack(buf[:], false)
+	fields := bytes.Fields(bytes.TrimPrefix(buf[:n], []byte("goroutine ")))
+	if len(fields) == 0 {
+		return 0
+	}
+	id, _ := strconv.ParseInt(string(fields[0]), 10, 64)
+	return id
+}
+
+// checkThread panics when c is owned by a RenderThread and the caller runs
+// on another goroutine, in debug mode.
+func (c *Context) checkThread() {
+	if c.debug == nil {
+		return
+	}
+	owner := atomic.LoadInt64(&c.owner)
+	if owner == 0 {
+		return
+	}
+	if id := goroutineID(); id != owner {
+		panic(fmt.Errorf("glplus: Context used off its render thread (goroutine %d), use RenderThread.Call", id))
+	}
+}
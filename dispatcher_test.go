@@ -0,0 +1,116 @@
+package glplus
+
+import (
+	"image"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestRenderThread(t *testing.T) {
+	rt := NewRenderThread()
+	defer rt.Close()
+
+	var c *Context
+	rt.Call(func() { c = NewBackendContext(NewSoftBackend(16, 16)) })
+	rt.Own(c)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	threads := make(map[int64]bool)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rt.Call(func() {
+				tex := c.CreateTexture()
+				c.BindTexture(c.TEXTURE_2D, tex)
+				c.TexImage2D(c.TEXTURE_2D, 0, c.RGBA, 1, 1, c.RGBA, c.UNSIGNED_BYTE, []uint8{1, 2, 3, 4})
+				c.BindTexture(c.TEXTURE_2D, nil)
+				c.DeleteTexture(tex)
+				mu.Lock()
+				threads[goroutineID()] = true
+				mu.Unlock()
+			})
+		}()
+	}
+	wg.Wait()
+	if len(threads) != 1 {
+		t.Errorf("ran on %d goroutines", len(threads))
+	}
+
+	// results come back to the caller, nested calls do not deadlock
+	var live int
+	rt.Batch(
+		func() { c.CreateBuffer() },
+		func() { rt.Call(func() { live = len(c.LiveResources("Buffer")) }) },
+	)
+	if live != 1 {
+		t.Errorf("live buffers %d", live)
+	}
+	if err := rt.CallErr(func() error { return c.CheckLeaks() }); err == nil {
+		t.Error("expected the buffer to leak")
+	}
+
+	// the owned context refuses calls from other goroutines, in debug mode
+	c.Viewport(0, 0, 1, 1)
+	rt.Call(func() { c.EnableDebug(nil) })
+	// owning the context while the render goroutine uses it
+	rt.Go(func() { c.Viewport(0, 0, 2, 2) })
+	rt.Own(c)
+	rt.Call(func() {})
+	func() {
+		defer func() {
+			r := recover()
+			if err, ok := r.(error); !ok || !strings.Contains(err.Error(), "off its render thread") {
+				t.Errorf("recovered %v", r)
+			}
+		}()
+		c.Viewport(0, 0, 1, 1)
+	}()
+
+	// panics are raised in the caller
+	func() {
+		defer func() {
+			if r := recover(); r != "boom" {
+				t.Errorf("recovered %v", r)
+			}
+		}()
+		rt.Call(func() { panic("boom") })
+	}()
+
+	done := make(chan image.Point, 1)
+	rt.Go(func() { done <- image.Point{1, 2} })
+	if p := <-done; p != (image.Point{1, 2}) {
+		t.Errorf("got %v", p)
+	}
+}
+
+func TestRenderThreadClosed(t *testing.T) {
+	rt := NewRenderThread()
+	ran := false
+	if err := rt.Go(func() { ran = true }); err != nil {
+		t.Fatal(err)
+	}
+	rt.Close()
+	if !ran {
+		t.Error("the queued function did not run")
+	}
+
+	// the functions submitted after Close are refused
+	if err := rt.Go(func() {}); err != ErrRenderThreadClosed {
+		t.Errorf("Go: got %v", err)
+	}
+	if err := rt.CallErr(func() error { return nil }); err != ErrRenderThreadClosed {
+		t.Errorf("CallErr: got %v", err)
+	}
+	func() {
+		defer func() {
+			if r := recover(); r != ErrRenderThreadClosed {
+				t.Errorf("Call: recovered %v", r)
+			}
+		}()
+		rt.Call(func() {})
+	}()
+	rt.Close()
+}
@@ -0,0 +1,673 @@
+package glplus
+
+// Enums holds the GL constants used by glplus. OpenGL, OpenGL ES and WebGL
+// share the same values, so every backend embeds this one table.
+type Enums struct {
+	ACTIVE_ATTRIBUTES                            int
+	ACTIVE_ATTRIBUTE_MAX_LENGTH                  int
+	ACTIVE_UNIFORMS                              int
+	ACTIVE_UNIFORM_MAX_LENGTH                    int
+	ALL_BARRIER_BITS                             int
+	ALWAYS                                       int
+	ARRAY_BUFFER                                 int
+	ARRAY_BUFFER_BINDING                         int
+	ATTACHED_SHADERS                             int
+	BACK                                         int
+	BLEND                                        int
+	BLEND_COLOR                                  int
+	BLEND_DST_ALPHA                              int
+	BLEND_DST_RGB                                int
+	BLEND_EQUATION                               int
+	BLEND_EQUATION_ALPHA                         int
+	BLEND_EQUATION_RGB                           int
+	BLEND_SRC_ALPHA                              int
+	BLEND_SRC_RGB                                int
+	BLUE_BITS                                    int
+	BOOL                                         int
+	BOOL_VEC2                                    int
+	BOOL_VEC3                                    int
+	BOOL_VEC4                                    int
+	BROWSER_DEFAULT_WEBGL                        int
+	BUFFER_SIZE                                  int
+	BUFFER_UPDATE_BARRIER_BIT                    int
+	BUFFER_USAGE                                 int
+	BYTE                                         int
+	CCW                                          int
+	CLAMP_TO_EDGE                                int
+	CLAMP_TO_BORDER                              int
+	COLOR_ATTACHMENT0                            int
+	COLOR_BUFFER_BIT                             int
+	COLOR_CLEAR_VALUE                            int
+	COLOR_WRITEMASK                              int
+	COMPILE_STATUS                               uint32
+	COMPRESSED_TEXTURE_FORMATS                   int
+	COMPUTE_SHADER                               int
+	CONSTANT_ALPHA                               int
+	CONSTANT_COLOR                               int
+	CONTEXT_LOST_WEBGL                           int
+	CULL_FACE                                    int
+	CULL_FACE_MODE                               int
+	CURRENT_PROGRAM                              int
+	CURRENT_VERTEX_ATTRIB                        int
+	CW                                           int
+	DECR                                         int
+	DECR_WRAP                                    int
+	DELETE_STATUS                                int
+	DEPTH_ATTACHMENT                             int
+	DEPTH_BITS                                   int
+	DEPTH_BUFFER_BIT                             int
+	DEPTH_CLEAR_VALUE                            int
+	DEPTH_COMPONENT                              int
+	DEPTH_COMPONENT16                            int
+	DEPTH_FUNC                                   int
+	DEPTH_RANGE                                  int
+	DEPTH_STENCIL                                int
+	DEPTH_STENCIL_ATTACHMENT                     int
+	DEPTH_TEST                                   int
+	DEPTH_WRITEMASK                              int
+	DITHER                                       int
+	DONT_CARE                                    int
+	DST_ALPHA                                    int
+	DST_COLOR                                    int
+	DYNAMIC_DRAW                                 int
+	ELEMENT_ARRAY_BARRIER_BIT                    int
+	ELEMENT_ARRAY_BUFFER                         int
+	ELEMENT_ARRAY_BUFFER_BINDING                 int
+	EQUAL                                        int
+	EXTENSIONS                                   int
+	FASTEST                                      int
+	FLOAT                                        int
+	FLOAT_MAT2                                   int
+	FLOAT_MAT3                                   int
+	FLOAT_MAT4                                   int
+	FLOAT_VEC2                                   int
+	FLOAT_VEC3                                   int
+	FLOAT_VEC4                                   int
+	FRAGMENT_SHADER                              int
+	FRAMEBUFFER                                  int
+	FRAMEBUFFER_ATTACHMENT_OBJECT_NAME           int
+	FRAMEBUFFER_ATTACHMENT_OBJECT_TYPE           int
+	FRAMEBUFFER_ATTACHMENT_TEXTURE_CUBE_MAP_FACE int
+	FRAMEBUFFER_ATTACHMENT_TEXTURE_LEVEL         int
+	FRAMEBUFFER_BARRIER_BIT                      int
+	FRAMEBUFFER_BINDING                          int
+	FRAMEBUFFER_COMPLETE                         int
+	FRAMEBUFFER_INCOMPLETE_ATTACHMENT            int
+	FRAMEBUFFER_INCOMPLETE_DIMENSIONS            int
+	FRAMEBUFFER_INCOMPLETE_MISSING_ATTACHMENT    int
+	FRAMEBUFFER_INCOMPLETE_DRAW_BUFFER           int
+	FRAMEBUFFER_INCOMPLETE_READ_BUFFER           int
+	FRAMEBUFFER_UNSUPPORTED                      int
+	FRONT                                        int
+	FRONT_AND_BACK                               int
+	FRONT_FACE                                   int
+	FUNC_ADD                                     int
+	FUNC_REVERSE_SUBTRACT                        int
+	FUNC_SUBTRACT                                int
+	GENERATE_MIPMAP_HINT                         int
+	GEOMETRY_SHADER                              int
+	GEQUAL                                       int
+	GREATER                                      int
+	GREEN_BITS                                   int
+	HALF_FLOAT                                   int
+	HIGH_FLOAT                                   int
+	HIGH_INT                                     int
+	INCR                                         int
+	INCR_WRAP                                    int
+	INFO_LOG_LENGTH                              uint32
+	INT                                          int
+	INT_VEC2                                     int
+	INT_VEC3                                     int
+	INT_VEC4                                     int
+	INVALID_ENUM                                 int
+	INVALID_FRAMEBUFFER_OPERATION                int
+	INVALID_INDEX                                int
+	INVALID_OPERATION                            int
+	INVALID_VALUE                                int
+	INVERT                                       int
+	KEEP                                         int
+	LEQUAL                                       int
+	LESS                                         int
+	LINEAR                                       int
+	LINEAR_MIPMAP_LINEAR                         int
+	LINEAR_MIPMAP_NEAREST                        int
+	LINES                                        int
+	LINE_LOOP                                    int
+	LINE_STRIP                                   int
+	LINE_WIDTH                                   int
+	LINK_STATUS                                  int
+	LOW_FLOAT                                    int
+	LOW_INT                                      int
+	LUMINANCE                                    int
+	LUMINANCE_ALPHA                              int
+	MAX_COMBINED_TEXTURE_IMAGE_UNITS             int
+	MAX_CUBE_MAP_TEXTURE_SIZE                    int
+	MAX_FRAGMENT_UNIFORM_VECTORS                 int
+	MAX_RENDERBUFFER_SIZE                        int
+	MAX_SAMPLES                                  int
+	MAX_TEXTURE_IMAGE_UNITS                      int
+	MAX_TEXTURE_SIZE                             int
+	MAX_UNIFORM_BUFFER_BINDINGS                  int
+	MAX_VARYING_VECTORS                          int
+	MAX_VERTEX_ATTRIBS                           int
+	MAX_VERTEX_TEXTURE_IMAGE_UNITS               int
+	MAX_VERTEX_UNIFORM_VECTORS                   int
+	MAX_VIEWPORT_DIMS                            int
+	MEDIUM_FLOAT                                 int
+	MEDIUM_INT                                   int
+	MIRRORED_REPEAT                              int
+	MULTISAMPLE                                  int
+	NEAREST                                      int
+	NEAREST_MIPMAP_LINEAR                        int
+	NEAREST_MIPMAP_NEAREST                       int
+	NEVER                                        int
+	NICEST                                       int
+	NONE                                         int
+	NOTEQUAL                                     int
+	NO_ERROR                                     int
+	NUM_COMPRESSED_TEXTURE_FORMATS               int
+	NUM_EXTENSIONS                               int
+	NUM_PROGRAM_BINARY_FORMATS                   int
+	ONE                                          int
+	ONE_MINUS_CONSTANT_ALPHA                     int
+	ONE_MINUS_CONSTANT_COLOR                     int
+	ONE_MINUS_DST_ALPHA                          int
+	ONE_MINUS_DST_COLOR                          int
+	ONE_MINUS_SRC_ALPHA                          int
+	ONE_MINUS_SRC_COLOR                          int
+	OUT_OF_MEMORY                                int
+	PACK_ALIGNMENT                               int
+	PATCHES                                      int
+	PATCH_VERTICES                               int
+	POINTS                                       int
+	POLYGON_OFFSET_FACTOR                        int
+	POLYGON_OFFSET_FILL                          int
+	POLYGON_OFFSET_UNITS                         int
+	PROGRAM_BINARY_FORMATS                       int
+	PROGRAM_BINARY_LENGTH                        int
+	PROGRAM_BINARY_RETRIEVABLE_HINT              int
+	RED_BITS                                     int
+	RENDERBUFFER                                 int
+	RENDERBUFFER_ALPHA_SIZE                      int
+	RENDERBUFFER_BINDING                         int
+	RENDERBUFFER_BLUE_SIZE                       int
+	RENDERBUFFER_DEPTH_SIZE                      int
+	RENDERBUFFER_GREEN_SIZE                      int
+	RENDERBUFFER_HEIGHT                          int
+	RENDERBUFFER_INTERNAL_FORMAT                 int
+	RENDERBUFFER_RED_SIZE                        int
+	RENDERBUFFER_STENCIL_SIZE                    int
+	RENDERBUFFER_WIDTH                           int
+	RENDERER                                     int
+	REPEAT                                       int
+	REPLACE                                      int
+	RGB                                          int
+	RGB5_A1                                      int
+	RGB565                                       int
+	RGBA                                         int
+	RGBA32F                                      int
+	RGBA4                                        int
+	SAMPLER_2D                                   int
+	SAMPLER_CUBE                                 int
+	SAMPLES                                      int
+	SAMPLE_ALPHA_TO_COVERAGE                     int
+	SAMPLE_BUFFERS                               int
+	SAMPLE_COVERAGE                              int
+	SAMPLE_COVERAGE_INVERT                       int
+	SAMPLE_COVERAGE_VALUE                        int
+	SCISSOR_BOX                                  int
+	SCISSOR_TEST                                 int
+	SHADER_COMPILER                              int
+	SHADER_IMAGE_ACCESS_BARRIER_BIT              int
+	SHADER_SOURCE_LENGTH                         int
+	SHADER_STORAGE_BARRIER_BIT                   int
+	SHADER_TYPE                                  int
+	SHADING_LANGUAGE_VERSION                     int
+	SHORT                                        int
+	SRC_ALPHA                                    int
+	SRC_ALPHA_SATURATE                           int
+	SRC_COLOR                                    int
+	STATIC_DRAW                                  int
+	STENCIL_ATTACHMENT                           int
+	STENCIL_BACK_FAIL                            int
+	STENCIL_BACK_FUNC                            int
+	STENCIL_BACK_PASS_DEPTH_FAIL                 int
+	STENCIL_BACK_PASS_DEPTH_PASS                 int
+	STENCIL_BACK_REF                             int
+	STENCIL_BACK_VALUE_MASK                      int
+	STENCIL_BACK_WRITEMASK                       int
+	STENCIL_BITS                                 int
+	STENCIL_BUFFER_BIT                           int
+	STENCIL_CLEAR_VALUE                          int
+	STENCIL_FAIL                                 int
+	STENCIL_FUNC                                 int
+	STENCIL_INDEX                                int
+	STENCIL_INDEX8                               int
+	STENCIL_PASS_DEPTH_FAIL                      int
+	STENCIL_PASS_DEPTH_PASS                      int
+	STENCIL_REF                                  int
+	STENCIL_TEST                                 int
+	STENCIL_VALUE_MASK                           int
+	STENCIL_WRITEMASK                            int
+	STREAM_DRAW                                  int
+	SUBPIXEL_BITS                                int
+	TESS_CONTROL_SHADER                          int
+	TESS_EVALUATION_SHADER                       int
+	TEXTURE                                      int
+	TEXTURE0                                     int
+	TEXTURE1                                     int
+	TEXTURE2                                     int
+	TEXTURE3                                     int
+	TEXTURE4                                     int
+	TEXTURE5                                     int
+	TEXTURE6                                     int
+	TEXTURE7                                     int
+	TEXTURE8                                     int
+	TEXTURE9                                     int
+	TEXTURE10                                    int
+	TEXTURE11                                    int
+	TEXTURE12                                    int
+	TEXTURE13                                    int
+	TEXTURE14                                    int
+	TEXTURE15                                    int
+	TEXTURE16                                    int
+	TEXTURE17                                    int
+	TEXTURE18                                    int
+	TEXTURE19                                    int
+	TEXTURE20                                    int
+	TEXTURE21                                    int
+	TEXTURE22                                    int
+	TEXTURE23                                    int
+	TEXTURE24                                    int
+	TEXTURE25                                    int
+	TEXTURE26                                    int
+	TEXTURE27                                    int
+	TEXTURE28                                    int
+	TEXTURE29                                    int
+	TEXTURE30                                    int
+	TEXTURE31                                    int
+	TEXTURE_2D                                   int
+	TEXTURE_BINDING_2D                           int
+	TEXTURE_BINDING_CUBE_MAP                     int
+	TEXTURE_CUBE_MAP                             int
+	TEXTURE_CUBE_MAP_NEGATIVE_X                  int
+	TEXTURE_CUBE_MAP_NEGATIVE_Y                  int
+	TEXTURE_CUBE_MAP_NEGATIVE_Z                  int
+	TEXTURE_CUBE_MAP_POSITIVE_X                  int
+	TEXTURE_CUBE_MAP_POSITIVE_Y                  int
+	TEXTURE_CUBE_MAP_POSITIVE_Z                  int
+	TEXTURE_FETCH_BARRIER_BIT                    int
+	TEXTURE_MAG_FILTER                           int
+	TEXTURE_MIN_FILTER                           int
+	TEXTURE_WRAP_S                               int
+	TEXTURE_WRAP_T                               int
+	TRIANGLES                                    int
+	TRIANGLE_FAN                                 int
+	TRIANGLE_STRIP                               int
+	UNIFORM_BARRIER_BIT                          int
+	UNIFORM_BUFFER                               int
+	UNPACK_ALIGNMENT                             int
+	UNPACK_COLORSPACE_CONVERSION_WEBGL           int
+	UNPACK_FLIP_Y_WEBGL                          int
+	UNPACK_PREMULTIPLY_ALPHA_WEBGL               int
+	UNSIGNED_BYTE                                int
+	UNSIGNED_INT                                 int
+	UNSIGNED_SHORT                               int
+	UNSIGNED_SHORT_4_4_4_4                       int
+	UNSIGNED_SHORT_5_5_5_1                       int
+	UNSIGNED_SHORT_5_6_5                         int
+	VALIDATE_STATUS                              int
+	VENDOR                                       int
+	VERSION                                      int
+	VERTEX_ATTRIB_ARRAY_BARRIER_BIT              int
+	VERTEX_ATTRIB_ARRAY_BUFFER_BINDING           int
+	VERTEX_ATTRIB_ARRAY_DIVISOR                  int
+	VERTEX_ATTRIB_ARRAY_ENABLED                  int
+	VERTEX_ATTRIB_ARRAY_NORMALIZED               int
+	VERTEX_ATTRIB_ARRAY_POINTER                  int
+	VERTEX_ATTRIB_ARRAY_SIZE                     int
+	VERTEX_ATTRIB_ARRAY_STRIDE                   int
+	VERTEX_ATTRIB_ARRAY_TYPE                     int
+	VERTEX_SHADER                                int
+	VIEWPORT                                     int
+	ZERO                                         int
+	TRUE                                         int
+	R8                                           int
+	RED                                          int
+	R32F                                         int
+}
+
+var sEnums = Enums{
+	ACTIVE_ATTRIBUTES:                  0x8B89,
+	ACTIVE_ATTRIBUTE_MAX_LENGTH:        0x8B8A,
+	ACTIVE_UNIFORMS:                    0x8B86,
+	ACTIVE_UNIFORM_MAX_LENGTH:          0x8B87,
+	ALL_BARRIER_BITS:                   -1, // 0xFFFFFFFF as a GLbitfield
+	ALWAYS:                             0x0207,
+	ARRAY_BUFFER:                       0x8892,
+	ARRAY_BUFFER_BINDING:               0x8894,
+	ATTACHED_SHADERS:                   0x8B85,
+	BACK:                               0x0405,
+	BLEND:                              0x0BE2,
+	BLEND_COLOR:                        0x8005,
+	BLEND_DST_ALPHA:                    0x80CA,
+	BLEND_DST_RGB:                      0x80C8,
+	BLEND_EQUATION:                     0x8009,
+	BLEND_EQUATION_ALPHA:               0x883D,
+	BLEND_EQUATION_RGB:                 0x8009,
+	BLEND_SRC_ALPHA:                    0x80CB,
+	BLEND_SRC_RGB:                      0x80C9,
+	BLUE_BITS:                          0x0D54,
+	BOOL:                               0x8B56,
+	BOOL_VEC2:                          0x8B57,
+	BOOL_VEC3:                          0x8B58,
+	BOOL_VEC4:                          0x8B59,
+	BROWSER_DEFAULT_WEBGL:              0x9244,
+	BUFFER_SIZE:                        0x8764,
+	BUFFER_UPDATE_BARRIER_BIT:          0x00000200,
+	BUFFER_USAGE:                       0x8765,
+	BYTE:                               0x1400,
+	CCW:                                0x0901,
+	CLAMP_TO_EDGE:                      0x812F,
+	CLAMP_TO_BORDER:                    0x812D,
+	COLOR_ATTACHMENT0:                  0x8CE0,
+	COLOR_BUFFER_BIT:                   0x00004000,
+	COLOR_CLEAR_VALUE:                  0x0C22,
+	COLOR_WRITEMASK:                    0x0C23,
+	COMPILE_STATUS:                     0x8B81,
+	COMPRESSED_TEXTURE_FORMATS:         0x86A3,
+	COMPUTE_SHADER:                     0x91B9,
+	CONSTANT_ALPHA:                     0x8003,
+	CONSTANT_COLOR:                     0x8001,
+	CONTEXT_LOST_WEBGL:                 0x9242,
+	CULL_FACE:                          0x0B44,
+	CULL_FACE_MODE:                     0x0B45,
+	CURRENT_PROGRAM:                    0x8B8D,
+	CURRENT_VERTEX_ATTRIB:              0x8626,
+	CW:                                 0x0900,
+	DECR:                               0x1E03,
+	DECR_WRAP:                          0x8508,
+	DELETE_STATUS:                      0x8B80,
+	DEPTH_ATTACHMENT:                   0x8D00,
+	DEPTH_BITS:                         0x0D56,
+	DEPTH_BUFFER_BIT:                   0x00000100,
+	DEPTH_CLEAR_VALUE:                  0x0B73,
+	DEPTH_COMPONENT:                    0x1902,
+	DEPTH_COMPONENT16:                  0x81A5,
+	DEPTH_FUNC:                         0x0B74,
+	DEPTH_RANGE:                        0x0B70,
+	DEPTH_STENCIL:                      0x84F9,
+	DEPTH_STENCIL_ATTACHMENT:           0x821A,
+	DEPTH_TEST:                         0x0B71,
+	DEPTH_WRITEMASK:                    0x0B72,
+	DITHER:                             0x0BD0,
+	DONT_CARE:                          0x1100,
+	DST_ALPHA:                          0x0304,
+	DST_COLOR:                          0x0306,
+	DYNAMIC_DRAW:                       0x88E8,
+	ELEMENT_ARRAY_BARRIER_BIT:          0x00000002,
+	ELEMENT_ARRAY_BUFFER:               0x8893,
+	ELEMENT_ARRAY_BUFFER_BINDING:       0x8895,
+	EQUAL:                              0x0202,
+	EXTENSIONS:                         0x1F03,
+	FASTEST:                            0x1101,
+	FLOAT:                              0x1406,
+	FLOAT_MAT2:                         0x8B5A,
+	FLOAT_MAT3:                         0x8B5B,
+	FLOAT_MAT4:                         0x8B5C,
+	FLOAT_VEC2:                         0x8B50,
+	FLOAT_VEC3:                         0x8B51,
+	FLOAT_VEC4:                         0x8B52,
+	FRAGMENT_SHADER:                    0x8B30,
+	FRAMEBUFFER:                        0x8D40,
+	FRAMEBUFFER_ATTACHMENT_OBJECT_NAME: 0x8CD1,
+	FRAMEBUFFER_ATTACHMENT_OBJECT_TYPE: 0x8CD0,
+	FRAMEBUFFER_ATTACHMENT_TEXTURE_CUBE_MAP_FACE: 0x8CD3,
+	FRAMEBUFFER_ATTACHMENT_TEXTURE_LEVEL:         0x8CD2,
+	FRAMEBUFFER_BARRIER_BIT:                      0x00000400,
+	FRAMEBUFFER_BINDING:                          0x8CA6,
+	FRAMEBUFFER_COMPLETE:                         0x8CD5,
+	FRAMEBUFFER_INCOMPLETE_ATTACHMENT:            0x8CD6,
+	FRAMEBUFFER_INCOMPLETE_DIMENSIONS:            0x8CD9,
+	FRAMEBUFFER_INCOMPLETE_MISSING_ATTACHMENT:    0x8CD7,
+	FRAMEBUFFER_INCOMPLETE_DRAW_BUFFER:           0x8CDB,
+	FRAMEBUFFER_INCOMPLETE_READ_BUFFER:           0x8CDC,
+	FRAMEBUFFER_UNSUPPORTED:                      0x8CDD,
+	FRONT:                                        0x0404,
+	FRONT_AND_BACK:                               0x0408,
+	FRONT_FACE:                                   0x0B46,
+	FUNC_ADD:                                     0x8006,
+	FUNC_REVERSE_SUBTRACT:                        0x800B,
+	FUNC_SUBTRACT:                                0x800A,
+	GENERATE_MIPMAP_HINT:                         0x8192,
+	GEOMETRY_SHADER:                              0x8DD9,
+	GEQUAL:                                       0x0206,
+	GREATER:                                      0x0204,
+	GREEN_BITS:                                   0x0D53,
+	HALF_FLOAT:                                   0x140B,
+	HIGH_FLOAT:                                   0x8DF2,
+	HIGH_INT:                                     0x8DF5,
+	INCR:                                         0x1E02,
+	INCR_WRAP:                                    0x8507,
+	INFO_LOG_LENGTH:                              0x8B84,
+	INT:                                          0x1404,
+	INT_VEC2:                                     0x8B53,
+	INT_VEC3:                                     0x8B54,
+	INT_VEC4:                                     0x8B55,
+	INVALID_ENUM:                                 0x0500,
+	INVALID_FRAMEBUFFER_OPERATION:                0x0506,
+	INVALID_INDEX:                                -1, // 0xFFFFFFFF as a GLuint
+	INVALID_OPERATION:                            0x0502,
+	INVALID_VALUE:                                0x0501,
+	INVERT:                                       0x150A,
+	KEEP:                                         0x1E00,
+	LEQUAL:                                       0x0203,
+	LESS:                                         0x0201,
+	LINEAR:                                       0x2601,
+	LINEAR_MIPMAP_LINEAR:                         0x2703,
+	LINEAR_MIPMAP_NEAREST:                        0x2701,
+	LINES:                                        0x0001,
+	LINE_LOOP:                                    0x0002,
+	LINE_STRIP:                                   0x0003,
+	LINE_WIDTH:                                   0x0B21,
+	LINK_STATUS:                                  0x8B82,
+	LOW_FLOAT:                                    0x8DF0,
+	LOW_INT:                                      0x8DF3,
+	LUMINANCE:                                    0x1909,
+	LUMINANCE_ALPHA:                              0x190A,
+	MAX_COMBINED_TEXTURE_IMAGE_UNITS:             0x8B4D,
+	MAX_CUBE_MAP_TEXTURE_SIZE:                    0x851C,
+	MAX_FRAGMENT_UNIFORM_VECTORS:                 0x8DFD,
+	MAX_RENDERBUFFER_SIZE:                        0x84E8,
+	MAX_SAMPLES:                                  0x8D57,
+	MAX_TEXTURE_IMAGE_UNITS:                      0x8872,
+	MAX_TEXTURE_SIZE:                             0x0D33,
+	MAX_UNIFORM_BUFFER_BINDINGS:                  0x8A2F,
+	MAX_VARYING_VECTORS:                          0x8DFC,
+	MAX_VERTEX_ATTRIBS:                           0x8869,
+	MAX_VERTEX_TEXTURE_IMAGE_UNITS:               0x8B4C,
+	MAX_VERTEX_UNIFORM_VECTORS:                   0x8DFB,
+	MAX_VIEWPORT_DIMS:                            0x0D3A,
+	MEDIUM_FLOAT:                                 0x8DF1,
+	MEDIUM_INT:                                   0x8DF4,
+	MIRRORED_REPEAT:                              0x8370,
+	MULTISAMPLE:                                  0x809D,
+	NEAREST:                                      0x2600,
+	NEAREST_MIPMAP_LINEAR:                        0x2702,
+	NEAREST_MIPMAP_NEAREST:                       0x2700,
+	NEVER:                                        0x0200,
+	NICEST:                                       0x1102,
+	NONE:                                         0,
+	NOTEQUAL:                                     0x0205,
+	NO_ERROR:                                     0,
+	NUM_COMPRESSED_TEXTURE_FORMATS:               0x86A2,
+	NUM_EXTENSIONS:                               0x821D,
+	NUM_PROGRAM_BINARY_FORMATS:                   0x87FE,
+	ONE:                                          1,
+	ONE_MINUS_CONSTANT_ALPHA:                     0x8004,
+	ONE_MINUS_CONSTANT_COLOR:                     0x8002,
+	ONE_MINUS_DST_ALPHA:                          0x0305,
+	ONE_MINUS_DST_COLOR:                          0x0307,
+	ONE_MINUS_SRC_ALPHA:                          0x0303,
+	ONE_MINUS_SRC_COLOR:                          0x0301,
+	OUT_OF_MEMORY:                                0x0505,
+	PACK_ALIGNMENT:                               0x0D05,
+	PATCHES:                                      0x000E,
+	PATCH_VERTICES:                               0x8E72,
+	POINTS:                                       0x0000,
+	POLYGON_OFFSET_FACTOR:                        0x8038,
+	POLYGON_OFFSET_FILL:                          0x8037,
+	POLYGON_OFFSET_UNITS:                         0x2A00,
+	PROGRAM_BINARY_FORMATS:                       0x87FF,
+	PROGRAM_BINARY_LENGTH:                        0x8741,
+	PROGRAM_BINARY_RETRIEVABLE_HINT:              0x8257,
+	RED_BITS:                                     0x0D52,
+	RENDERBUFFER:                                 0x8D41,
+	RENDERBUFFER_ALPHA_SIZE:                      0x8D53,
+	RENDERBUFFER_BINDING:                         0x8CA7,
+	RENDERBUFFER_BLUE_SIZE:                       0x8D52,
+	RENDERBUFFER_DEPTH_SIZE:                      0x8D54,
+	RENDERBUFFER_GREEN_SIZE:                      0x8D51,
+	RENDERBUFFER_HEIGHT:                          0x8D43,
+	RENDERBUFFER_INTERNAL_FORMAT:                 0x8D44,
+	RENDERBUFFER_RED_SIZE:                        0x8D50,
+	RENDERBUFFER_STENCIL_SIZE:                    0x8D55,
+	RENDERBUFFER_WIDTH:                           0x8D42,
+	RENDERER:                                     0x1F01,
+	REPEAT:                                       0x2901,
+	REPLACE:                                      0x1E01,
+	RGB:                                          0x1907,
+	RGB5_A1:                                      0x8057,
+	RGB565:                                       0x8D62,
+	RGBA:                                         0x1908,
+	RGBA32F:                                      0x8814,
+	RGBA4:                                        0x8056,
+	SAMPLER_2D:                                   0x8B5E,
+	SAMPLER_CUBE:                                 0x8B60,
+	SAMPLES:                                      0x80A9,
+	SAMPLE_ALPHA_TO_COVERAGE:                     0x809E,
+	SAMPLE_BUFFERS:                               0x80A8,
+	SAMPLE_COVERAGE:                              0x80A0,
+	SAMPLE_COVERAGE_INVERT:                       0x80AB,
+	SAMPLE_COVERAGE_VALUE:                        0x80AA,
+	SCISSOR_BOX:                                  0x0C10,
+	SCISSOR_TEST:                                 0x0C11,
+	SHADER_COMPILER:                              0x8DFA,
+	SHADER_IMAGE_ACCESS_BARRIER_BIT:              0x00000020,
+	SHADER_SOURCE_LENGTH:                         0x8B88,
+	SHADER_STORAGE_BARRIER_BIT:                   0x00002000,
+	SHADER_TYPE:                                  0x8B4F,
+	SHADING_LANGUAGE_VERSION:                     0x8B8C,
+	SHORT:                                        0x1402,
+	SRC_ALPHA:                                    0x0302,
+	SRC_ALPHA_SATURATE:                           0x0308,
+	SRC_COLOR:                                    0x0300,
+	STATIC_DRAW:                                  0x88E4,
+	STENCIL_ATTACHMENT:                           0x8D20,
+	STENCIL_BACK_FAIL:                            0x8801,
+	STENCIL_BACK_FUNC:                            0x8800,
+	STENCIL_BACK_PASS_DEPTH_FAIL:                 0x8802,
+	STENCIL_BACK_PASS_DEPTH_PASS:                 0x8803,
+	STENCIL_BACK_REF:                             0x8CA3,
+	STENCIL_BACK_VALUE_MASK:                      0x8CA4,
+	STENCIL_BACK_WRITEMASK:                       0x8CA5,
+	STENCIL_BITS:                                 0x0D57,
+	STENCIL_BUFFER_BIT:                           0x00000400,
+	STENCIL_CLEAR_VALUE:                          0x0B91,
+	STENCIL_FAIL:                                 0x0B94,
+	STENCIL_FUNC:                                 0x0B92,
+	STENCIL_INDEX:                                0x1901,
+	STENCIL_INDEX8:                               0x8D48,
+	STENCIL_PASS_DEPTH_FAIL:                      0x0B95,
+	STENCIL_PASS_DEPTH_PASS:                      0x0B96,
+	STENCIL_REF:                                  0x0B97,
+	STENCIL_TEST:                                 0x0B90,
+	STENCIL_VALUE_MASK:                           0x0B93,
+	STENCIL_WRITEMASK:                            0x0B98,
+	STREAM_DRAW:                                  0x88E0,
+	SUBPIXEL_BITS:                                0x0D50,
+	TESS_CONTROL_SHADER:                          0x8E88,
+	TESS_EVALUATION_SHADER:                       0x8E87,
+	TEXTURE:                                      0x1702,
+	TEXTURE0:                                     0x84C0,
+	TEXTURE1:                                     0x84C1,
+	TEXTURE2:                                     0x84C2,
+	TEXTURE3:                                     0x84C3,
+	TEXTURE4:                                     0x84C4,
+	TEXTURE5:                                     0x84C5,
+	TEXTURE6:                                     0x84C6,
+	TEXTURE7:                                     0x84C7,
+	TEXTURE8:                                     0x84C8,
+	TEXTURE9:                                     0x84C9,
+	TEXTURE10:                                    0x84CA,
+	TEXTURE11:                                    0x84CB,
+	TEXTURE12:                                    0x84CC,
+	TEXTURE13:                                    0x84CD,
+	TEXTURE14:                                    0x84CE,
+	TEXTURE15:                                    0x84CF,
+	TEXTURE16:                                    0x84D0,
+	TEXTURE17:                                    0x84D1,
+	TEXTURE18:                                    0x84D2,
+	TEXTURE19:                                    0x84D3,
+	TEXTURE20:                                    0x84D4,
+	TEXTURE21:                                    0x84D5,
+	TEXTURE22:                                    0x84D6,
+	TEXTURE23:                                    0x84D7,
+	TEXTURE24:                                    0x84D8,
+	TEXTURE25:                                    0x84D9,
+	TEXTURE26:                                    0x84DA,
+	TEXTURE27:                                    0x84DB,
+	TEXTURE28:                                    0x84DC,
+	TEXTURE29:                                    0x84DD,
+	TEXTURE30:                                    0x84DE,
+	TEXTURE31:                                    0x84DF,
+	TEXTURE_2D:                                   0x0DE1,
+	TEXTURE_BINDING_2D:                           0x8069,
+	TEXTURE_BINDING_CUBE_MAP:                     0x8514,
+	TEXTURE_CUBE_MAP:                             0x8513,
+	TEXTURE_CUBE_MAP_NEGATIVE_X:                  0x8516,
+	TEXTURE_CUBE_MAP_NEGATIVE_Y:                  0x8518,
+	TEXTURE_CUBE_MAP_NEGATIVE_Z:                  0x851A,
+	TEXTURE_CUBE_MAP_POSITIVE_X:                  0x8515,
+	TEXTURE_CUBE_MAP_POSITIVE_Y:                  0x8517,
+	TEXTURE_CUBE_MAP_POSITIVE_Z:                  0x8519,
+	TEXTURE_FETCH_BARRIER_BIT:                    0x00000008,
+	TEXTURE_MAG_FILTER:                           0x2800,
+	TEXTURE_MIN_FILTER:                           0x2801,
+	TEXTURE_WRAP_S:                               0x2802,
+	TEXTURE_WRAP_T:                               0x2803,
+	TRIANGLES:                                    0x0004,
+	TRIANGLE_FAN:                                 0x0006,
+	TRIANGLE_STRIP:                               0x0005,
+	UNIFORM_BARRIER_BIT:                          0x00000004,
+	UNIFORM_BUFFER:                               0x8A11,
+	UNPACK_ALIGNMENT:                             0x0CF5,
+	UNPACK_COLORSPACE_CONVERSION_WEBGL:           0x9243,
+	UNPACK_FLIP_Y_WEBGL:                          0x9240,
+	UNPACK_PREMULTIPLY_ALPHA_WEBGL:               0x9241,
+	UNSIGNED_BYTE:                                0x1401,
+	UNSIGNED_INT:                                 0x1405,
+	UNSIGNED_SHORT:                               0x1403,
+	UNSIGNED_SHORT_4_4_4_4:                       0x8033,
+	UNSIGNED_SHORT_5_5_5_1:                       0x8034,
+	UNSIGNED_SHORT_5_6_5:                         0x8363,
+	VALIDATE_STATUS:                              0x8B83,
+	VENDOR:                                       0x1F00,
+	VERSION:                                      0x1F02,
+	VERTEX_ATTRIB_ARRAY_BARRIER_BIT:              0x00000001,
+	VERTEX_ATTRIB_ARRAY_BUFFER_BINDING:           0x889F,
+	VERTEX_ATTRIB_ARRAY_DIVISOR:                  0x88FE,
+	VERTEX_ATTRIB_ARRAY_ENABLED:                  0x8622,
+	VERTEX_ATTRIB_ARRAY_NORMALIZED:               0x886A,
+	VERTEX_ATTRIB_ARRAY_POINTER:                  0x8645,
+	VERTEX_ATTRIB_ARRAY_SIZE:                     0x8623,
+	VERTEX_ATTRIB_ARRAY_STRIDE:                   0x8624,
+	VERTEX_ATTRIB_ARRAY_TYPE:                     0x8625,
+	VERTEX_SHADER:                                0x8B31,
+	VIEWPORT:                                     0x0BA2,
+	ZERO:                                         0,
+	TRUE:                                         1,
+	R8:                                           0x8229,
+	RED:                                          0x1903,
+	R32F:                                         0x822E,
+}
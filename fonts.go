@@ -37,7 +37,7 @@ var (
     vec4 col0 = TEXTURE2D(tex1, out_uvs);
     FRAGCOLOR = col0.r * color;
     // Porter duff gl.ONE, gl.ONE_MINUS_SRC_ALPHA
-    FRAGCOLOR = vec4(FRAGCOLOR.r + bg.r * (1.0-FRAGCOLOR.a), FRAGCOLOR.g + bg.g * (1.0-FRAGCOLOR.a), FRAGCOLOR.b + bg.b * (1.0-FRAGCOLOR.a), FRAGCOLOR.a + bg.a * (1-FRAGCOLOR.a));
+    FRAGCOLOR = vec4(FRAGCOLOR.r + bg.r * (1.0-FRAGCOLOR.a), FRAGCOLOR.g + bg.g * (1.0-FRAGCOLOR.a), FRAGCOLOR.b + bg.b * (1.0-FRAGCOLOR.a), FRAGCOLOR.a + bg.a * (1.0-FRAGCOLOR.a));
   }`
 
 	// vertex shader
@@ -75,6 +75,15 @@ func (s *String) DeleteString() {
 	}
 }
 
+// fontUniforms are the uniforms of the font shader, the texture is bound to
+// unit 0.
+type fontUniforms struct {
+	ModelviewMatrix mgl32.Mat3 `glsl:"ModelviewMatrix"`
+	Tex1            int32      `glsl:"tex1"`
+	Color           [4]float32 `glsl:"color"`
+	Bg              [4]float32 `glsl:"bg"`
+}
+
 // Draw ...
 func (s *String) Draw(f *Font, color [4]float32, bg [4]float32, mat mgl32.Mat3, scale float32, offsetX float32, offsetY float32) (err error) {
 	if f.program == nil {
@@ -82,12 +91,14 @@ func (s *String) Draw(f *Font, color [4]float32, bg [4]float32, mat mgl32.Mat3,
 			"position",
 			"uvs",
 		}
-		if f.program, err = LoadShaderProgram(vertShaderFont, fragShaderFont, attribs); err != nil {
+		if f.program, err = f.ctx.LoadShaderProgram(vertShaderFont, fragShaderFont, attribs); err != nil {
 			return (err)
 		}
 	}
 	if s.vbo == nil {
-		s.createVertexBuffer(f)
+		if err = s.createVertexBuffer(f); err != nil {
+			return (err)
+		}
 	}
 
 	f.program.UseProgram()
@@ -96,10 +107,13 @@ func (s *String) Draw(f *Font, color [4]float32, bg [4]float32, mat mgl32.Mat3,
 
 	var matrixfont = mat.Mul3(mgl32.Scale2D(scale, scale))
 	matrixfont = matrixfont.Mul3(mgl32.Translate2D(offsetX, offsetY))
-	f.program.ProgramUniformMatrix3fv("ModelviewMatrix", matrixfont)
-	f.program.ProgramUniform1i("tex1", 0)
-	f.program.ProgramUniform4fv("color", color)
-	f.program.ProgramUniform4fv("bg", bg)
+	if err = f.program.SetUniforms(&fontUniforms{
+		ModelviewMatrix: matrixfont,
+		Color:           color,
+		Bg:              bg,
+	}); err != nil {
+		return err
+	}
 
 	if err = f.program.ValidateProgram(); err != nil {
 		return err
@@ -114,7 +128,7 @@ func (s *String) Draw(f *Font, color [4]float32, bg [4]float32, mat mgl32.Mat3,
 	return nil
 }
 
-func (s *String) createVertexBuffer(f *Font) {
+func (s *String) createVertexBuffer(f *Font) (err error) {
 	n := len(s.Chars)
 
 	verts := make([]float32, n*20)
@@ -190,7 +204,8 @@ func (s *String) createVertexBuffer(f *Font) {
 
 	opt := DefaultVBOOptions()
 	opt.Quads = n
-	s.vbo = NewVBO(f.program, opt, verts[:], indices[:])
+	s.vbo, err = NewVBO(f.program, opt, verts[:], indices[:])
+	return err
 }
 
 // Font ...
@@ -201,6 +216,7 @@ type Font struct {
 
 	program *GPProgram
 	rows    int
+	ctx     *Context
 }
 
 // DeleteFont ...
@@ -272,8 +288,13 @@ func FreeSerif() (*os.File, error) {
 	return os.Open(path.Join(path.Dir(filename), "FreeSerif.ttf"))
 }
 
-// NewFont ...
+// NewFont loads a font on the default context Gl.
 func NewFont(reader io.Reader) (font *Font, err error) {
+	return Gl.NewFont(reader)
+}
+
+// NewFont ...
+func (c *Context) NewFont(reader io.Reader) (font *Font, err error) {
 
 	// Read the font data.
 	var fontBytes []byte
@@ -327,47 +348,52 @@ func NewFont(reader io.Reader) (font *Font, err error) {
 		png.Encode(w, dst) //Encode writes the Image m to w in PNG format.
 	}
 
-	texture := GenTexture(dst.Rect.Size())
-	texture.BindTexture(0)
-	Gl.TexParameteri(Gl.TEXTURE_2D, Gl.TEXTURE_MIN_FILTER, Gl.LINEAR)
-	Gl.TexParameteri(Gl.TEXTURE_2D, Gl.TEXTURE_MAG_FILTER, Gl.LINEAR)
-	Gl.TexParameteri(Gl.TEXTURE_2D, Gl.TEXTURE_WRAP_S, Gl.CLAMP_TO_EDGE)
-	Gl.TexParameteri(Gl.TEXTURE_2D, Gl.TEXTURE_WRAP_T, Gl.CLAMP_TO_EDGE)
-
+	var gray *image.Gray
 	if sUseGray {
-		gray := image.NewGray(dst.Bounds())
+		gray = image.NewGray(dst.Bounds())
 		if gray.Stride != gray.Rect.Size().X {
 			return nil, fmt.Errorf("unsupported stride")
 		}
 		draw.Draw(gray, gray.Bounds(), dst, image.Point{0, 0}, draw.Src)
-
-		Gl.TexImage2D(
-			Gl.TEXTURE_2D,
-			0,
-			Gl.R8,
-			gray.Rect.Size().X,
-			gray.Rect.Size().Y,
-			Gl.RED,
-			Gl.UNSIGNED_BYTE,
-			gray.Pix)
-	} else {
-		Gl.TexImage2D(
-			Gl.TEXTURE_2D,
-			0,
-			Gl.RGBA,
-			dst.Rect.Size().X,
-			dst.Rect.Size().Y,
-			Gl.RGBA,
-			Gl.UNSIGNED_BYTE,
-			dst.Pix)
 	}
-	texture.UnbindTexture(0)
+
+	// the atlas is kept to restore the texture after a lost context
+	texture := c.GenTexture(dst.Rect.Size())
+	texture.SetUpload(func(c *Context) {
+		c.TexParameteri(c.TEXTURE_2D, c.TEXTURE_MIN_FILTER, c.LINEAR)
+		c.TexParameteri(c.TEXTURE_2D, c.TEXTURE_MAG_FILTER, c.LINEAR)
+		c.TexParameteri(c.TEXTURE_2D, c.TEXTURE_WRAP_S, c.CLAMP_TO_EDGE)
+		c.TexParameteri(c.TEXTURE_2D, c.TEXTURE_WRAP_T, c.CLAMP_TO_EDGE)
+
+		if gray != nil {
+			c.TexImage2D(
+				c.TEXTURE_2D,
+				0,
+				c.R8,
+				gray.Rect.Size().X,
+				gray.Rect.Size().Y,
+				c.RED,
+				c.UNSIGNED_BYTE,
+				gray.Pix)
+		} else {
+			c.TexImage2D(
+				c.TEXTURE_2D,
+				0,
+				c.RGBA,
+				dst.Rect.Size().X,
+				dst.Rect.Size().Y,
+				c.RGBA,
+				c.UNSIGNED_BYTE,
+				dst.Pix)
+		}
+	})
 
 	font = &Font{
 		texture:   texture,
 		rows:      16,
 		cellssize: dst.Rect.Size().X / 16,
 		advances:  advances,
+		ctx:       c,
 	}
 
 	return font, nil
@@ -0,0 +1,249 @@
+package glsl
+
+// Storage is the storage qualifier of a variable or parameter.
+type Storage int
+
+// Storage qualifiers
+const (
+	StorageNone Storage = iota
+	StorageConst
+	StorageIn
+	StorageOut
+	StorageInOut
+	StorageUniform
+	StorageAttribute
+	StorageVarying
+)
+
+var sStorageNames = map[Storage]string{
+	StorageNone:      "",
+	StorageConst:     "const",
+	StorageIn:        "in",
+	StorageOut:       "out",
+	StorageInOut:     "inout",
+	StorageUniform:   "uniform",
+	StorageAttribute: "attribute",
+	StorageVarying:   "varying",
+}
+
+func (s Storage) String() string {
+	return sStorageNames[s]
+}
+
+// Pos is a line and column in the shader source.
+type Pos struct {
+	Line int
+	Col  int
+}
+
+// Position ...
+func (p Pos) Position() Pos {
+	return p
+}
+
+// Node ...
+type Node interface {
+	Position() Pos
+}
+
+// Unit is a parsed shader source.
+type Unit struct {
+	Version    int
+	Profile    string
+	Directives []Token
+	Decls      []Node
+}
+
+// VarDecl declares a single variable.
+type VarDecl struct {
+	Pos
+	Storage   Storage
+	Layout    map[string]int
+	Precision string
+	Type      Type
+	Name      string
+	Init      Expr
+}
+
+// Param is a function parameter.
+type Param struct {
+	Pos
+	Storage Storage
+	Type    Type
+	Name    string
+}
+
+// FuncDecl is a function definition or prototype (Body == nil).
+type FuncDecl struct {
+	Pos
+	Ret    Type
+	Name   string
+	Params []*Param
+	Body   *BlockStmt
+}
+
+// PrecisionDecl is a default precision statement.
+type PrecisionDecl struct {
+	Pos
+	Precision string
+	Type      Type
+}
+
+// Stmt ...
+type Stmt interface {
+	Node
+	stmt()
+}
+
+// BlockStmt ...
+type BlockStmt struct {
+	Pos
+	List []Stmt
+}
+
+// DeclStmt ...
+type DeclStmt struct {
+	Pos
+	Vars []*VarDecl
+}
+
+// ExprStmt ...
+type ExprStmt struct {
+	Pos
+	X Expr
+}
+
+// IfStmt ...
+type IfStmt struct {
+	Pos
+	Cond Expr
+	Then Stmt
+	Else Stmt
+}
+
+// ForStmt ...
+type ForStmt struct {
+	Pos
+	Init Stmt
+	Cond Expr
+	Post Expr
+	Body Stmt
+}
+
+// WhileStmt is a while or, when Do is set, a do-while loop.
+type WhileStmt struct {
+	Pos
+	Cond Expr
+	Body Stmt
+	Do   bool
+}
+
+// ReturnStmt ...
+type ReturnStmt struct {
+	Pos
+	X Expr
+}
+
+// BranchStmt is break, continue or discard.
+type BranchStmt struct {
+	Pos
+	Tok string
+}
+
+func (*BlockStmt) stmt()  {}
+func (*DeclStmt) stmt()   {}
+func (*ExprStmt) stmt()   {}
+func (*IfStmt) stmt()     {}
+func (*ForStmt) stmt()    {}
+func (*WhileStmt) stmt()  {}
+func (*ReturnStmt) stmt() {}
+func (*BranchStmt) stmt() {}
+
+// Expr ...
+type Expr interface {
+	Node
+	expr()
+}
+
+// VarRef is a reference to a variable by name.
+type VarRef struct {
+	Pos
+	Name string
+}
+
+// Literal is an int, float or bool constant.
+type Literal struct {
+	Pos
+	Kind  Kind
+	Value float64
+}
+
+// UnaryExpr ...
+type UnaryExpr struct {
+	Pos
+	Op      string
+	X       Expr
+	Postfix bool
+}
+
+// BinaryExpr ...
+type BinaryExpr struct {
+	Pos
+	Op string
+	X  Expr
+	Y  Expr
+}
+
+// AssignExpr ...
+type AssignExpr struct {
+	Pos
+	Op  string
+	LHS Expr
+	RHS Expr
+}
+
+// CondExpr ...
+type CondExpr struct {
+	Pos
+	Cond Expr
+	Then Expr
+	Else Expr
+}
+
+// CallExpr is a function call or a constructor.
+type CallExpr struct {
+	Pos
+	Func string
+	Args []Expr
+}
+
+// FieldExpr is a swizzle.
+type FieldExpr struct {
+	Pos
+	X    Expr
+	Name string
+}
+
+// IndexExpr ...
+type IndexExpr struct {
+	Pos
+	X     Expr
+	Index Expr
+}
+
+// SeqExpr is the comma operator.
+type SeqExpr struct {
+	Pos
+	List []Expr
+}
+
+func (*VarRef) expr()     {}
+func (*Literal) expr()    {}
+func (*UnaryExpr) expr()  {}
+func (*BinaryExpr) expr() {}
+func (*AssignExpr) expr() {}
+func (*CondExpr) expr()   {}
+func (*CallExpr) expr()   {}
+func (*FieldExpr) expr()  {}
+func (*IndexExpr) expr()  {}
+func (*SeqExpr) expr()    {}
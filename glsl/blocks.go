@@ -0,0 +1,104 @@
+package glsl
+
+// blockState is where flattenBlocks stands in a uniform block.
+type blockState int
+
+const (
+	blockOutside blockState = iota
+	// blockMembers is in the braces, before a member
+	blockMembers
+	// blockMember is in the declaration of a member
+	blockMember
+	// blockEnd is after the closing brace
+	blockEnd
+)
+
+// flattenBlocks rewrites the uniform blocks of a line of code as plain
+// uniforms: "layout(std140) uniform Camera { mat4 view; };" becomes
+// "uniform mat4 view;", which the shader reads under the same name. The head
+// of a block, up to its {, must fit on one line.
+func (p *preprocessor) flattenBlocks(toks []ppToken, origin Origin) ([]ppToken, error) {
+	out := make([]ppToken, 0, len(toks)+1)
+	for i := 0; i < len(toks); i++ {
+		tok := toks[i]
+		if tok.kind == ppSpace || tok.kind == ppComment {
+			out = append(out, tok)
+			continue
+		}
+		switch p.block {
+		case blockOutside:
+			if open, name, ok := blockHead(toks, i); ok {
+				p.block, p.blockName = blockMembers, name
+				i = open
+				continue
+			}
+		case blockMembers:
+			if tok.text == "}" {
+				p.block = blockEnd
+				continue
+			}
+			// the layout of a member, such as row_major, is the one of
+			// the buffer
+			if tok.text == "layout" {
+				if closing := skipLayout(toks, i); closing > 0 {
+					i = skipSpace(toks, closing+1) - 1
+					continue
+				}
+			}
+			out = append(out, ppToken{kind: ppIdent, text: "uniform ", col: tok.col})
+			p.block = blockMember
+		case blockMember:
+			if tok.text == ";" {
+				p.block = blockMembers
+			}
+		case blockEnd:
+			if tok.text != ";" {
+				return nil, errorAt(origin, tok.col, "uniform block %s has an instance name, which needs uniform buffers", p.blockName)
+			}
+			p.block = blockOutside
+			continue
+		}
+		out = append(out, tok)
+	}
+	return out, nil
+}
+
+// blockHead matches "layout(...) uniform Name {", the layout being optional,
+// from toks[i], and returns the index of the {.
+func blockHead(toks []ppToken, i int) (open int, name string, ok bool) {
+	if toks[i].text == "layout" {
+		closing := skipLayout(toks, i)
+		if closing < 0 {
+			return 0, "", false
+		}
+		i = skipSpace(toks, closing+1)
+	}
+	if i >= len(toks) || toks[i].text != "uniform" {
+		return 0, "", false
+	}
+	i = skipSpace(toks, i+1)
+	if i >= len(toks) || toks[i].kind != ppIdent {
+		return 0, "", false
+	}
+	name = toks[i].text
+	i = skipSpace(toks, i+1)
+	if i >= len(toks) || toks[i].text != "{" {
+		return 0, "", false
+	}
+	return i, name, true
+}
+
+// skipLayout returns the index of the ) of the layout qualifier at toks[i],
+// or -1.
+func skipLayout(toks []ppToken, i int) int {
+	i = skipSpace(toks, i+1)
+	if i >= len(toks) || toks[i].text != "(" {
+		return -1
+	}
+	for ; i < len(toks); i++ {
+		if toks[i].text == ")" {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,556 @@
+package glsl
+
+import (
+	"fmt"
+	"math"
+)
+
+// Sampler looks up texels for the sampler uniforms of a program.
+type Sampler interface {
+	// Sample2D returns the filtered texel of the 2D texture bound to unit.
+	Sample2D(unit int, s, t, lod float32) [4]float32
+}
+
+type builtin struct {
+	// check validates the argument types and returns the result type,
+	// or a non-empty message when the arguments don't match.
+	check func(args []Type) (Type, string)
+	// eval computes the result; sizes holds the component count of each argument.
+	eval func(e *env, args []val, sizes []int) val
+}
+
+func builtinCall(b *builtin, args []exprFn, types []Type, rt Type) exprFn {
+	sizes := make([]int, len(types))
+	for i, t := range types {
+		sizes[i] = t.Size()
+	}
+	return func(e *env) val {
+		var vals [4]val
+		in := vals[:len(args)]
+		for i, a := range args {
+			in[i] = a(e)
+		}
+		return b.eval(e, in, sizes)
+	}
+}
+
+// genType reports whether t is a float scalar or vector, promoting int types.
+func genType(t Type) (Type, bool) {
+	if t.Array > 0 || t.Kind.IsMatrix() || !t.Kind.IsNumeric() {
+		return t, false
+	}
+	t.Kind = t.Kind.withScalar(Float)
+	return t, true
+}
+
+func isScalarOr(t, gen Type) bool {
+	g, ok := genType(t)
+	return ok && (g == gen || g.Kind == Float)
+}
+
+func argCount(args []Type, n int) string {
+	if len(args) != n {
+		return fmt.Sprintf("expected %d arguments, found %d", n, len(args))
+	}
+	return ""
+}
+
+func badArgs(args []Type) string {
+	return fmt.Sprintf("no matching overload for (%s)", describeArgs(args))
+}
+
+// component returns component i of an argument, broadcasting scalars.
+func component(v val, size, i int) float32 {
+	if size == 1 {
+		return v[0]
+	}
+	return v[i]
+}
+
+func f32(x float64) float32 {
+	return float32(x)
+}
+
+// unaryF: genType f(genType)
+func unaryF(f func(x float64) float64) *builtin {
+	return &builtin{
+		check: func(args []Type) (Type, string) {
+			if msg := argCount(args, 1); msg != "" {
+				return errType, msg
+			}
+			t, ok := genType(args[0])
+			if !ok {
+				return errType, badArgs(args)
+			}
+			return t, ""
+		},
+		eval: func(e *env, a []val, sizes []int) (r val) {
+			for i := 0; i < sizes[0]; i++ {
+				r[i] = f32(f(float64(a[0][i])))
+			}
+			return r
+		},
+	}
+}
+
+// unaryN: genType f(genType), keeping int types (abs, sign)
+func unaryN(f func(x float64) float64) *builtin {
+	b := unaryF(f)
+	b.check = func(args []Type) (Type, string) {
+		if msg := argCount(args, 1); msg != "" {
+			return errType, msg
+		}
+		if _, ok := genType(args[0]); !ok {
+			return errType, badArgs(args)
+		}
+		return args[0], ""
+	}
+	return b
+}
+
+// binaryF: genType f(genType, genType|float)
+func binaryF(scalarY bool, f func(x, y float64) float64) *builtin {
+	return &builtin{
+		check: func(args []Type) (Type, string) {
+			if msg := argCount(args, 2); msg != "" {
+				return errType, msg
+			}
+			t, ok := genType(args[0])
+			if !ok {
+				return errType, badArgs(args)
+			}
+			if y, ok := genType(args[1]); !ok || (y != t && !(scalarY && y.Kind == Float)) {
+				return errType, badArgs(args)
+			}
+			return t, ""
+		},
+		eval: func(e *env, a []val, sizes []int) (r val) {
+			for i := 0; i < sizes[0]; i++ {
+				r[i] = f32(f(float64(a[0][i]), float64(component(a[1], sizes[1], i))))
+			}
+			return r
+		},
+	}
+}
+
+// ternaryF: genType f(A, B, C) where the result follows argument gen and the
+// other arguments may be scalars.
+func ternaryF(gen int, f func(x, y, z float64) float64) *builtin {
+	return &builtin{
+		check: func(args []Type) (Type, string) {
+			if msg := argCount(args, 3); msg != "" {
+				return errType, msg
+			}
+			t, ok := genType(args[gen])
+			if !ok {
+				return errType, badArgs(args)
+			}
+			for i, a := range args {
+				if i != gen && !isScalarOr(a, t) {
+					return errType, badArgs(args)
+				}
+			}
+			return t, ""
+		},
+		eval: func(e *env, a []val, sizes []int) (r val) {
+			for i := 0; i < sizes[gen]; i++ {
+				r[i] = f32(f(
+					float64(component(a[0], sizes[0], i)),
+					float64(component(a[1], sizes[1], i)),
+					float64(component(a[2], sizes[2], i))))
+			}
+			return r
+		},
+	}
+}
+
+// geometric: result f(genType...) with all arguments of the same type
+func geometric(n int, ret func(t Type) Type, f func(a []val, size int) val) *builtin {
+	return &builtin{
+		check: func(args []Type) (Type, string) {
+			if msg := argCount(args, n); msg != "" {
+				return errType, msg
+			}
+			t, ok := genType(args[0])
+			if !ok {
+				return errType, badArgs(args)
+			}
+			for _, a := range args[1:] {
+				if g, ok := genType(a); !ok || g != t {
+					return errType, badArgs(args)
+				}
+			}
+			return ret(t), ""
+		},
+		eval: func(e *env, a []val, sizes []int) val {
+			return f(a, sizes[0])
+		},
+	}
+}
+
+func sameType(t Type) Type {
+	return t
+}
+
+func floatType(t Type) Type {
+	return Type{Kind: Float}
+}
+
+func dot(a, b val, n int) float32 {
+	var s float32
+	for i := 0; i < n; i++ {
+		s += a[i] * b[i]
+	}
+	return s
+}
+
+func length(a val, n int) float32 {
+	return f32(math.Sqrt(float64(dot(a, a, n))))
+}
+
+func normalize(a val, n int) (r val) {
+	l := length(a, n)
+	for i := 0; i < n; i++ {
+		r[i] = a[i] / l
+	}
+	return r
+}
+
+// relational: bvec f(vec, vec)
+func relational(f func(x, y float32) bool) *builtin {
+	return &builtin{
+		check: func(args []Type) (Type, string) {
+			if msg := argCount(args, 2); msg != "" {
+				return errType, msg
+			}
+			x, y := args[0], args[1]
+			if x != y || x.Array > 0 || !x.Kind.IsVector() {
+				return errType, badArgs(args)
+			}
+			return Type{Kind: vectorKind(Bool, x.Kind.Components())}, ""
+		},
+		eval: func(e *env, a []val, sizes []int) (r val) {
+			for i := 0; i < sizes[0]; i++ {
+				r[i] = b2f(f(a[0][i], a[1][i]))
+			}
+			return r
+		},
+	}
+}
+
+func boolReduce(all bool) *builtin {
+	return &builtin{
+		check: func(args []Type) (Type, string) {
+			if msg := argCount(args, 1); msg != "" {
+				return errType, msg
+			}
+			if args[0].Array > 0 || !args[0].Kind.IsVector() || args[0].Kind.Scalar() != Bool {
+				return errType, badArgs(args)
+			}
+			return Type{Kind: Bool}, ""
+		},
+		eval: func(e *env, a []val, sizes []int) val {
+			for i := 0; i < sizes[0]; i++ {
+				if (a[0][i] != 0) != all {
+					return val{0: b2f(!all)}
+				}
+			}
+			return val{0: b2f(all)}
+		},
+	}
+}
+
+func matrixArg(args []Type, n int) (Type, string) {
+	if msg := argCount(args, n); msg != "" {
+		return errType, msg
+	}
+	for _, a := range args {
+		if a != args[0] || !a.Kind.IsMatrix() || a.Array > 0 {
+			return errType, badArgs(args)
+		}
+	}
+	return args[0], ""
+}
+
+// texture: vec4 f(sampler2D, vec2 [, float])
+func texture(withLod bool) *builtin {
+	return &builtin{
+		check: func(args []Type) (Type, string) {
+			if len(args) < 2 || len(args) > 3 || (withLod && len(args) != 3) {
+				return errType, badArgs(args)
+			}
+			switch args[0] {
+			case Type{Kind: Sampler2D}:
+				if args[1] != (Type{Kind: Vec2}) {
+					return errType, badArgs(args)
+				}
+			case Type{Kind: SamplerCube}:
+				if args[1] != (Type{Kind: Vec3}) {
+					return errType, badArgs(args)
+				}
+			default:
+				return errType, badArgs(args)
+			}
+			if len(args) == 3 {
+				if g, ok := genType(args[2]); !ok || g.Kind != Float {
+					return errType, badArgs(args)
+				}
+			}
+			return Type{Kind: Vec4}, ""
+		},
+		eval: func(e *env, a []val, sizes []int) (r val) {
+			// cube maps are not sampled
+			if e.sampler == nil || sizes[1] != 2 {
+				return r
+			}
+			var lod float32
+			if len(a) == 3 {
+				lod = a[2][0]
+			}
+			texel := e.sampler.Sample2D(int(a[0][0]), a[1][0], a[1][1], lod)
+			copy(r[:4], texel[:])
+			return r
+		},
+	}
+}
+
+func sign(x float64) float64 {
+	switch {
+	case x > 0:
+		return 1
+	case x < 0:
+		return -1
+	}
+	return 0
+}
+
+func fract(x float64) float64 {
+	return x - math.Floor(x)
+}
+
+func mod(x, y float64) float64 {
+	return x - y*math.Floor(x/y)
+}
+
+func smoothstep(e0, e1, x float64) float64 {
+	t := math.Min(math.Max((x-e0)/(e1-e0), 0), 1)
+	return t * t * (3 - 2*t)
+}
+
+func zero(x float64) float64 {
+	return 0
+}
+
+var sBuiltins map[string]*builtin
+
+func init() {
+	sBuiltins = map[string]*builtin{
+		"radians":     unaryF(func(x float64) float64 { return x * math.Pi / 180 }),
+		"degrees":     unaryF(func(x float64) float64 { return x * 180 / math.Pi }),
+		"sin":         unaryF(math.Sin),
+		"cos":         unaryF(math.Cos),
+		"tan":         unaryF(math.Tan),
+		"asin":        unaryF(math.Asin),
+		"acos":        unaryF(math.Acos),
+		"exp":         unaryF(math.Exp),
+		"log":         unaryF(math.Log),
+		"exp2":        unaryF(math.Exp2),
+		"log2":        unaryF(math.Log2),
+		"sqrt":        unaryF(math.Sqrt),
+		"inversesqrt": unaryF(func(x float64) float64 { return 1 / math.Sqrt(x) }),
+		"abs":         unaryN(math.Abs),
+		"sign":        unaryN(sign),
+		"floor":       unaryF(math.Floor),
+		"ceil":        unaryF(math.Ceil),
+		"trunc":       unaryF(math.Trunc),
+		"round":       unaryF(math.Round),
+		"fract":       unaryF(fract),
+		"dFdx":        unaryF(zero),
+		"dFdy":        unaryF(zero),
+		"fwidth":      unaryF(zero),
+		"pow":         binaryF(false, math.Pow),
+		"mod":         binaryF(true, mod),
+		"min":         binaryF(true, math.Min),
+		"max":         binaryF(true, math.Max),
+		"clamp": ternaryF(0, func(x, lo, hi float64) float64 {
+			return math.Min(math.Max(x, lo), hi)
+		}),
+		"mix": ternaryF(0, func(x, y, a float64) float64 {
+			return x*(1-a) + y*a
+		}),
+		"smoothstep": ternaryF(2, smoothstep),
+		"length": geometric(1, floatType, func(a []val, n int) val {
+			return val{0: length(a[0], n)}
+		}),
+		"distance": geometric(2, floatType, func(a []val, n int) val {
+			var d val
+			for i := 0; i < n; i++ {
+				d[i] = a[0][i] - a[1][i]
+			}
+			return val{0: length(d, n)}
+		}),
+		"dot": geometric(2, floatType, func(a []val, n int) val {
+			return val{0: dot(a[0], a[1], n)}
+		}),
+		"normalize": geometric(1, sameType, func(a []val, n int) val {
+			return normalize(a[0], n)
+		}),
+		"reflect": geometric(2, sameType, func(a []val, n int) (r val) {
+			d := 2 * dot(a[1], a[0], n)
+			for i := 0; i < n; i++ {
+				r[i] = a[0][i] - d*a[1][i]
+			}
+			return r
+		}),
+		"faceforward": geometric(3, sameType, func(a []val, n int) (r val) {
+			s := float32(1)
+			if dot(a[2], a[1], n) >= 0 {
+				s = -1
+			}
+			for i := 0; i < n; i++ {
+				r[i] = s * a[0][i]
+			}
+			return r
+		}),
+		"cross": {
+			check: func(args []Type) (Type, string) {
+				if msg := argCount(args, 2); msg != "" {
+					return errType, msg
+				}
+				for _, a := range args {
+					if g, ok := genType(a); !ok || g.Kind != Vec3 {
+						return errType, badArgs(args)
+					}
+				}
+				return Type{Kind: Vec3}, ""
+			},
+			eval: func(e *env, a []val, sizes []int) val {
+				x, y := a[0], a[1]
+				return val{x[1]*y[2] - x[2]*y[1], x[2]*y[0] - x[0]*y[2], x[0]*y[1] - x[1]*y[0]}
+			},
+		},
+		"refract": {
+			check: func(args []Type) (Type, string) {
+				if msg := argCount(args, 3); msg != "" {
+					return errType, msg
+				}
+				t, ok := genType(args[0])
+				if g, ok2 := genType(args[1]); !ok || !ok2 || g != t {
+					return errType, badArgs(args)
+				}
+				if g, ok := genType(args[2]); !ok || g.Kind != Float {
+					return errType, badArgs(args)
+				}
+				return t, ""
+			},
+			eval: func(e *env, a []val, sizes []int) (r val) {
+				n := sizes[0]
+				eta := a[2][0]
+				d := dot(a[1], a[0], n)
+				k := 1 - eta*eta*(1-d*d)
+				if k < 0 {
+					return r
+				}
+				s := eta*d + f32(math.Sqrt(float64(k)))
+				for i := 0; i < n; i++ {
+					r[i] = eta*a[0][i] - s*a[1][i]
+				}
+				return r
+			},
+		},
+		"transpose": {
+			check: func(args []Type) (Type, string) { return matrixArg(args, 1) },
+			eval: func(e *env, a []val, sizes []int) (r val) {
+				n := int(math.Sqrt(float64(sizes[0])))
+				for col := 0; col < n; col++ {
+					for row := 0; row < n; row++ {
+						r[row*n+col] = a[0][col*n+row]
+					}
+				}
+				return r
+			},
+		},
+		"matrixCompMult": {
+			check: func(args []Type) (Type, string) { return matrixArg(args, 2) },
+			eval: func(e *env, a []val, sizes []int) (r val) {
+				for i := 0; i < sizes[0]; i++ {
+					r[i] = a[0][i] * a[1][i]
+				}
+				return r
+			},
+		},
+		"lessThan":         relational(func(x, y float32) bool { return x < y }),
+		"lessThanEqual":    relational(func(x, y float32) bool { return x <= y }),
+		"greaterThan":      relational(func(x, y float32) bool { return x > y }),
+		"greaterThanEqual": relational(func(x, y float32) bool { return x >= y }),
+		"equal":            relational(func(x, y float32) bool { return x == y }),
+		"notEqual":         relational(func(x, y float32) bool { return x != y }),
+		"any":              boolReduce(false),
+		"all":              boolReduce(true),
+		"not": {
+			check: func(args []Type) (Type, string) {
+				if msg := argCount(args, 1); msg != "" {
+					return errType, msg
+				}
+				if args[0].Array > 0 || !args[0].Kind.IsVector() || args[0].Kind.Scalar() != Bool {
+					return errType, badArgs(args)
+				}
+				return args[0], ""
+			},
+			eval: func(e *env, a []val, sizes []int) (r val) {
+				for i := 0; i < sizes[0]; i++ {
+					r[i] = b2f(a[0][i] == 0)
+				}
+				return r
+			},
+		},
+		"texture":      texture(false),
+		"texture2D":    texture(false),
+		"textureCube":  texture(false),
+		"textureLod":   texture(true),
+		"texture2DLod": texture(true),
+		"step":         ternaryStep(),
+		"atan": {
+			check: func(args []Type) (Type, string) {
+				if len(args) == 1 {
+					return unaryF(math.Atan).check(args)
+				}
+				return binaryF(false, math.Atan2).check(args)
+			},
+			eval: func(e *env, a []val, sizes []int) (r val) {
+				for i := 0; i < sizes[0]; i++ {
+					if len(a) == 1 {
+						r[i] = f32(math.Atan(float64(a[0][i])))
+					} else {
+						r[i] = f32(math.Atan2(float64(a[0][i]), float64(a[1][i])))
+					}
+				}
+				return r
+			},
+		},
+	}
+}
+
+// step: genType step(genType|float edge, genType x)
+func ternaryStep() *builtin {
+	return &builtin{
+		check: func(args []Type) (Type, string) {
+			if msg := argCount(args, 2); msg != "" {
+				return errType, msg
+			}
+			t, ok := genType(args[1])
+			if !ok || !isScalarOr(args[0], t) {
+				return errType, badArgs(args)
+			}
+			return t, ""
+		},
+		eval: func(e *env, a []val, sizes []int) (r val) {
+			for i := 0; i < sizes[1]; i++ {
+				if a[1][i] >= component(a[0], sizes[0], i) {
+					r[i] = 1
+				}
+			}
+			return r
+		},
+	}
+}
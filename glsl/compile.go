@@ -0,0 +1,1415 @@
+package glsl
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Stage is the pipeline stage a shader is compiled for.
+type Stage int
+
+// Shader stages
+const (
+	VertexStage Stage = iota
+	FragmentStage
+)
+
+func (s Stage) String() string {
+	if s == FragmentStage {
+		return "fragment"
+	}
+	return "vertex"
+}
+
+// val holds the components of any value; matrices are column-major.
+type val [16]float32
+
+type ctl int
+
+const (
+	ctlNext ctl = iota
+	ctlBreak
+	ctlContinue
+	ctlReturn
+	ctlDiscard
+)
+
+type env struct {
+	mem     []float32
+	sampler Sampler
+	ret     val
+	discard bool
+}
+
+type exprFn func(e *env) val
+type stmtFn func(e *env) ctl
+
+// errType marks an expression that already produced an error.
+var errType = Type{Kind: Void, Array: -1}
+
+// Variable is a global of a shader: an input, an output or a uniform.
+type Variable struct {
+	Name    string
+	Type    Type
+	Storage Storage
+	Layout  map[string]int
+	Pos     Pos
+	Builtin bool
+
+	offset   int
+	readOnly bool
+}
+
+type function struct {
+	name   string
+	params []*Variable
+	ret    Type
+	body   stmtFn
+}
+
+// Shader is a parsed, type checked and compiled shader stage.
+type Shader struct {
+	Stage   Stage
+	Unit    *Unit
+	Globals []*Variable
+
+	size     int
+	init     []stmtFn
+	main     *function
+	builtins map[string]*Variable
+}
+
+// Builtin returns a gl_ variable of the shader, nil if the stage has none.
+func (s *Shader) Builtin(name string) *Variable {
+	return s.builtins[name]
+}
+
+// Variables returns the globals with the given storage.
+func (s *Shader) Variables(storage Storage) (vars []*Variable) {
+	for _, v := range s.Globals {
+		if v.Storage == storage && !v.Builtin {
+			vars = append(vars, v)
+		}
+	}
+	return vars
+}
+
+type compiler struct {
+	shader *Shader
+	errs   ErrorList
+	scopes []map[string]*Variable
+	funcs  map[string][]*function
+	fn     *function
+	loops  int
+	// es is set for GLSL ES, which has no implicit conversions
+	es bool
+}
+
+// Compile parses and type checks a shader source.
+func Compile(stage Stage, src string) (*Shader, error) {
+	unit, err := Parse(src)
+	if err != nil {
+		return nil, err
+	}
+	return CompileUnit(stage, unit)
+}
+
+// CompileUnit type checks an already parsed shader.
+func CompileUnit(stage Stage, unit *Unit) (*Shader, error) {
+	c := &compiler{
+		shader: &Shader{Stage: stage, Unit: unit, builtins: make(map[string]*Variable)},
+		funcs:  make(map[string][]*function),
+		es:     unit.Version == 100 || unit.Profile == "es",
+	}
+	c.pushScope()
+	c.declareBuiltins()
+
+	for _, decl := range unit.Decls {
+		switch d := decl.(type) {
+		case *VarDecl:
+			c.globalVar(d)
+		case *FuncDecl:
+			c.funcDecl(d)
+		}
+	}
+
+	for _, fn := range c.funcs["main"] {
+		if len(fn.params) == 0 && fn.body != nil {
+			c.shader.main = fn
+		}
+	}
+	if c.shader.main == nil && len(c.errs) == 0 {
+		c.errs = append(c.errs, &Error{Line: 1, Col: 1, Msg: "no main() function defined"})
+	}
+
+	if err := c.errs.err(); err != nil {
+		return nil, err
+	}
+	return c.shader, nil
+}
+
+func (c *compiler) errorf(pos Pos, format string, args ...interface{}) {
+	c.errs = append(c.errs, &Error{Line: pos.Line, Col: pos.Col, Msg: fmt.Sprintf(format, args...)})
+}
+
+func (c *compiler) pushScope() {
+	c.scopes = append(c.scopes, make(map[string]*Variable))
+}
+
+func (c *compiler) popScope() {
+	c.scopes = c.scopes[:len(c.scopes)-1]
+}
+
+func (c *compiler) alloc(t Type) int {
+	off := c.shader.size
+	c.shader.size += t.Size()
+	return off
+}
+
+func (c *compiler) declare(v *Variable) {
+	scope := c.scopes[len(c.scopes)-1]
+	if _, ok := scope[v.Name]; ok {
+		c.errorf(v.Pos, "'%s' redeclared", v.Name)
+	}
+	if strings.HasPrefix(v.Name, "gl_") && !v.Builtin {
+		c.errorf(v.Pos, "identifier '%s' uses reserved `gl_' prefix", v.Name)
+	}
+	v.offset = c.alloc(v.Type)
+	scope[v.Name] = v
+}
+
+func (c *compiler) lookup(name string) *Variable {
+	for i := len(c.scopes) - 1; i >= 0; i-- {
+		if v, ok := c.scopes[i][name]; ok {
+			return v
+		}
+	}
+	return nil
+}
+
+func (c *compiler) declareBuiltins() {
+	add := func(name string, kind Kind, storage Storage) {
+		v := &Variable{Name: name, Type: Type{Kind: kind}, Storage: storage, Builtin: true, readOnly: storage == StorageIn}
+		c.declare(v)
+		c.shader.builtins[name] = v
+		c.shader.Globals = append(c.shader.Globals, v)
+	}
+	if c.shader.Stage == VertexStage {
+		add("gl_Position", Vec4, StorageOut)
+		add("gl_PointSize", Float, StorageOut)
+		add("gl_VertexID", Int, StorageIn)
+		add("gl_InstanceID", Int, StorageIn)
+	} else {
+		add("gl_FragCoord", Vec4, StorageIn)
+		add("gl_FrontFacing", Bool, StorageIn)
+		add("gl_PointCoord", Vec2, StorageIn)
+		add("gl_FragColor", Vec4, StorageOut)
+	}
+}
+
+// storage normalizes the ES 1.00 attribute/varying qualifiers.
+func (c *compiler) storage(d *VarDecl) Storage {
+	switch d.Storage {
+	case StorageAttribute:
+		if c.shader.Stage != VertexStage {
+			c.errorf(d.Pos, "'attribute' variable '%s' in fragment shader", d.Name)
+		}
+		return StorageIn
+	case StorageVarying:
+		if c.shader.Stage == VertexStage {
+			return StorageOut
+		}
+		return StorageIn
+	case StorageInOut:
+		c.errorf(d.Pos, "'inout' qualifier on global '%s'", d.Name)
+		return StorageNone
+	}
+	return d.Storage
+}
+
+func (c *compiler) globalVar(d *VarDecl) {
+	v := &Variable{
+		Name:    d.Name,
+		Type:    d.Type,
+		Storage: c.storage(d),
+		Layout:  d.Layout,
+		Pos:     d.Pos,
+	}
+	v.readOnly = v.Storage == StorageIn || v.Storage == StorageUniform || v.Storage == StorageConst
+	if v.Type.Kind.IsSampler() && v.Storage != StorageUniform {
+		c.errorf(d.Pos, "sampler '%s' must be a uniform", d.Name)
+	}
+	if d.Init != nil {
+		if v.Storage == StorageIn || v.Storage == StorageOut || v.Storage == StorageUniform {
+			c.errorf(d.Pos, "cannot initialize %s variable '%s'", v.Storage, d.Name)
+		} else if init := c.initializer(v, d); init != nil {
+			c.shader.init = append(c.shader.init, init)
+		}
+	} else if v.Storage == StorageConst {
+		c.errorf(d.Pos, "const variable '%s' must be initialized", d.Name)
+	}
+	c.declare(v)
+	c.shader.Globals = append(c.shader.Globals, v)
+}
+
+func (c *compiler) initializer(v *Variable, d *VarDecl) stmtFn {
+	fn, t := c.expr(d.Init)
+	if t == errType {
+		return nil
+	}
+	if !c.convertible(t, v.Type) {
+		c.errorf(d.Init.Position(), "initializer of type %s cannot be assigned to variable of type %s", t, v.Type)
+		return nil
+	}
+	off, n := &v.offset, v.Type.Size()
+	return func(e *env) ctl {
+		r := fn(e)
+		copy(e.mem[*off:*off+n], r[:n])
+		return ctlNext
+	}
+}
+
+func (c *compiler) funcDecl(d *FuncDecl) {
+	fn := &function{name: d.Name, ret: d.Ret}
+	for _, p := range d.Params {
+		fn.params = append(fn.params, &Variable{Name: p.Name, Type: p.Type, Storage: p.Storage, Pos: p.Pos})
+	}
+
+	// a definition completes an earlier prototype
+	var proto *function
+	for _, other := range c.funcs[d.Name] {
+		if sameParams(other.params, fn.params) {
+			proto = other
+		}
+	}
+	if proto != nil {
+		if proto.ret != fn.ret {
+			c.errorf(d.Pos, "function '%s' redeclared with a different return type", d.Name)
+		}
+		if proto.body != nil && d.Body != nil {
+			c.errorf(d.Pos, "function '%s' redefined", d.Name)
+			return
+		}
+		proto.params = fn.params
+		fn = proto
+	} else {
+		c.funcs[d.Name] = append(c.funcs[d.Name], fn)
+	}
+	if d.Body == nil {
+		return
+	}
+
+	c.fn = fn
+	c.pushScope()
+	for _, p := range fn.params {
+		if p.Name != "" {
+			c.declare(p)
+		} else {
+			p.offset = c.alloc(p.Type)
+		}
+	}
+	body := c.block(d.Body, false)
+	c.popScope()
+	c.fn = nil
+
+	fn.body = body
+}
+
+func sameParams(a, b []*Variable) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Type != b[i].Type {
+			return false
+		}
+	}
+	return true
+}
+
+// Statements ----------------------------------------------------------------
+
+func (c *compiler) block(b *BlockStmt, scope bool) stmtFn {
+	if scope {
+		c.pushScope()
+		defer c.popScope()
+	}
+	var list []stmtFn
+	for _, s := range b.List {
+		if fn := c.stmt(s); fn != nil {
+			list = append(list, fn)
+		}
+	}
+	return func(e *env) ctl {
+		for _, s := range list {
+			if r := s(e); r != ctlNext {
+				return r
+			}
+			if e.discard {
+				return ctlDiscard
+			}
+		}
+		return ctlNext
+	}
+}
+
+func (c *compiler) stmt(s Stmt) stmtFn {
+	switch s := s.(type) {
+	case *BlockStmt:
+		return c.block(s, true)
+	case *DeclStmt:
+		var list []stmtFn
+		for _, d := range s.Vars {
+			v := &Variable{Name: d.Name, Type: d.Type, Storage: d.Storage, Pos: d.Pos}
+			if v.Storage != StorageNone && v.Storage != StorageConst {
+				c.errorf(d.Pos, "%s qualifier on local variable '%s'", v.Storage, d.Name)
+			}
+			if v.Type.Kind.IsSampler() {
+				c.errorf(d.Pos, "sampler '%s' must be a uniform", d.Name)
+			}
+			var init stmtFn
+			if d.Init != nil {
+				init = c.initializer(v, d)
+			} else if v.Storage == StorageConst {
+				c.errorf(d.Pos, "const variable '%s' must be initialized", d.Name)
+			}
+			// the initializer is compiled before the name is in scope
+			c.declare(v)
+			v.readOnly = v.Storage == StorageConst
+			if init != nil {
+				list = append(list, init)
+			}
+		}
+		return func(e *env) ctl {
+			for _, s := range list {
+				s(e)
+			}
+			return ctlNext
+		}
+	case *ExprStmt:
+		fn, _ := c.expr(s.X)
+		return func(e *env) ctl {
+			fn(e)
+			return ctlNext
+		}
+	case *IfStmt:
+		cond := c.condition(s.Cond)
+		then := c.scopedStmt(s.Then)
+		var els stmtFn
+		if s.Else != nil {
+			els = c.scopedStmt(s.Else)
+		}
+		return func(e *env) ctl {
+			if cond(e)[0] != 0 {
+				return then(e)
+			} else if els != nil {
+				return els(e)
+			}
+			return ctlNext
+		}
+	case *ForStmt:
+		c.pushScope()
+		defer c.popScope()
+		var init, body stmtFn
+		var cond, post exprFn
+		if s.Init != nil {
+			init = c.stmt(s.Init)
+		}
+		if s.Cond != nil {
+			cond = c.condition(s.Cond)
+		}
+		if s.Post != nil {
+			post, _ = c.expr(s.Post)
+		}
+		c.loops++
+		body = c.scopedStmt(s.Body)
+		c.loops--
+		return func(e *env) ctl {
+			if init != nil {
+				init(e)
+			}
+			for cond == nil || cond(e)[0] != 0 {
+				switch r := body(e); r {
+				case ctlBreak:
+					return ctlNext
+				case ctlReturn, ctlDiscard:
+					return r
+				}
+				if post != nil {
+					post(e)
+				}
+			}
+			return ctlNext
+		}
+	case *WhileStmt:
+		cond := c.condition(s.Cond)
+		c.loops++
+		body := c.scopedStmt(s.Body)
+		c.loops--
+		do := s.Do
+		return func(e *env) ctl {
+			for first := true; (first && do) || cond(e)[0] != 0; first = false {
+				switch r := body(e); r {
+				case ctlBreak:
+					return ctlNext
+				case ctlReturn, ctlDiscard:
+					return r
+				}
+			}
+			return ctlNext
+		}
+	case *ReturnStmt:
+		ret := c.fn.ret
+		if s.X == nil {
+			if ret.Kind != Void {
+				c.errorf(s.Pos, "'return' with no value, in function returning %s", ret)
+			}
+			return func(e *env) ctl { return ctlReturn }
+		}
+		fn, t := c.expr(s.X)
+		if t != errType && !c.convertible(t, ret) {
+			c.errorf(s.Pos, "'return' with wrong type %s, in function '%s' returning type %s", t, c.fn.name, ret)
+		}
+		return func(e *env) ctl {
+			e.ret = fn(e)
+			return ctlReturn
+		}
+	case *BranchStmt:
+		switch s.Tok {
+		case "discard":
+			if c.shader.Stage != FragmentStage {
+				c.errorf(s.Pos, "discard statement is only allowed in fragment shaders")
+			}
+			return func(e *env) ctl {
+				e.discard = true
+				return ctlDiscard
+			}
+		case "break":
+			if c.loops == 0 {
+				c.errorf(s.Pos, "break statement not within a loop")
+			}
+			return func(e *env) ctl { return ctlBreak }
+		default:
+			if c.loops == 0 {
+				c.errorf(s.Pos, "continue statement not within a loop")
+			}
+			return func(e *env) ctl { return ctlContinue }
+		}
+	}
+	c.errorf(s.Position(), "unsupported statement")
+	return nil
+}
+
+func (c *compiler) scopedStmt(s Stmt) stmtFn {
+	c.pushScope()
+	defer c.popScope()
+	fn := c.stmt(s)
+	if fn == nil {
+		return func(e *env) ctl { return ctlNext }
+	}
+	return fn
+}
+
+func (c *compiler) condition(x Expr) exprFn {
+	fn, t := c.expr(x)
+	if t != errType && t != (Type{Kind: Bool}) {
+		c.errorf(x.Position(), "condition must be a scalar boolean, found %s", t)
+	}
+	return fn
+}
+
+// Types ----------------------------------------------------------------------
+
+// convertible reports whether a value of type from can be implicitly used as
+// to, never in GLSL ES.
+func (c *compiler) convertible(from, to Type) bool {
+	if from == to {
+		return true
+	}
+	if c.es || from.Array != to.Array || from.Kind.IsMatrix() || to.Kind.IsMatrix() {
+		return false
+	}
+	return from.Kind.Scalar() == Int && to.Kind.Scalar() == Float && from.Kind.Components() == to.Kind.Components()
+}
+
+// promote returns the common type of mixed int and float operands, but in
+// GLSL ES, where they stay mixed.
+func (c *compiler) promote(x, y Type) (Type, Type) {
+	if c.es {
+		return x, y
+	}
+	sx, sy := x.Kind.Scalar(), y.Kind.Scalar()
+	if sx == Int && sy == Float {
+		x.Kind = x.Kind.withScalar(Float)
+	} else if sx == Float && sy == Int {
+		y.Kind = y.Kind.withScalar(Float)
+	}
+	return x, y
+}
+
+// Expressions ---------------------------------------------------------------
+
+func constFn(v val) exprFn {
+	return func(e *env) val { return v }
+}
+
+func (c *compiler) expr(x Expr) (exprFn, Type) {
+	switch x := x.(type) {
+	case *Literal:
+		var v val
+		v[0] = float32(x.Value)
+		return constFn(v), Type{Kind: x.Kind}
+	case *VarRef, *FieldExpr, *IndexExpr:
+		if lv, ok := c.lvalue(x, false); ok {
+			if lv.typ == errType {
+				return constFn(val{}), errType
+			}
+			return lv.load(), lv.typ
+		}
+		return c.rvalueAccess(x)
+	case *UnaryExpr:
+		return c.unary(x)
+	case *BinaryExpr:
+		return c.binary(x)
+	case *AssignExpr:
+		return c.assign(x)
+	case *CondExpr:
+		cond := c.condition(x.Cond)
+		then, tt := c.expr(x.Then)
+		els, te := c.expr(x.Else)
+		if tt == errType || te == errType {
+			return constFn(val{}), errType
+		}
+		tt, te = c.promote(tt, te)
+		if tt != te {
+			c.errorf(x.Pos, "second and third operands of ?: must have the same type, found %s and %s", tt, te)
+			return constFn(val{}), errType
+		}
+		return func(e *env) val {
+			if cond(e)[0] != 0 {
+				return then(e)
+			}
+			return els(e)
+		}, tt
+	case *CallExpr:
+		return c.call(x)
+	case *SeqExpr:
+		var list []exprFn
+		var t Type
+		for _, item := range x.List {
+			var fn exprFn
+			fn, t = c.expr(item)
+			list = append(list, fn)
+		}
+		return func(e *env) (r val) {
+			for _, fn := range list {
+				r = fn(e)
+			}
+			return r
+		}, t
+	}
+	c.errorf(x.Position(), "unsupported expression")
+	return constFn(val{}), errType
+}
+
+// lvalue is a reference into shader memory, possibly swizzled.
+type lvalue struct {
+	typ      Type
+	addr     func(e *env) int
+	comps    []int
+	readOnly bool
+	name     string
+}
+
+func (lv *lvalue) load() exprFn {
+	addr, n := lv.addr, lv.typ.Size()
+	if lv.comps == nil {
+		return func(e *env) (r val) {
+			base := addr(e)
+			copy(r[:n], e.mem[base:base+n])
+			return r
+		}
+	}
+	comps := lv.comps
+	return func(e *env) (r val) {
+		base := addr(e)
+		for i, comp := range comps {
+			r[i] = e.mem[base+comp]
+		}
+		return r
+	}
+}
+
+func (lv *lvalue) store(e *env, v val) {
+	base := lv.addr(e)
+	if lv.comps == nil {
+		n := lv.typ.Size()
+		copy(e.mem[base:base+n], v[:n])
+		return
+	}
+	for i, comp := range lv.comps {
+		e.mem[base+comp] = v[i]
+	}
+}
+
+// lvalue resolves identifier, swizzle and index chains rooted at a variable.
+// ok is false when the expression is not rooted at a variable.
+func (c *compiler) lvalue(x Expr, write bool) (lv *lvalue, ok bool) {
+	switch x := x.(type) {
+	case *VarRef:
+		v := c.lookup(x.Name)
+		if v == nil {
+			c.errorf(x.Pos, "'%s' undeclared", x.Name)
+			return &lvalue{typ: errType}, true
+		}
+		off := &v.offset
+		return &lvalue{
+			typ:      v.Type,
+			addr:     func(e *env) int { return *off },
+			readOnly: v.readOnly,
+			name:     v.Name,
+		}, true
+	case *FieldExpr:
+		base, ok := c.lvalue(x.X, write)
+		if !ok || base.typ == errType {
+			return base, ok
+		}
+		comps, t := c.swizzle(x, base.typ)
+		if t == errType {
+			return &lvalue{typ: errType}, true
+		}
+		if write && hasDuplicates(comps) {
+			c.errorf(x.Pos, "duplicate components in swizzle used as l-value")
+		}
+		if base.comps != nil {
+			for i, comp := range comps {
+				comps[i] = base.comps[comp]
+			}
+		}
+		return &lvalue{typ: t, addr: base.addr, comps: comps, readOnly: base.readOnly, name: base.name}, true
+	case *IndexExpr:
+		base, ok := c.lvalue(x.X, write)
+		if !ok || base.typ == errType {
+			return base, ok
+		}
+		idx, it := c.expr(x.Index)
+		if it == errType {
+			return &lvalue{typ: errType}, true
+		}
+		if it.Kind != Int || it.Array > 0 {
+			c.errorf(x.Index.Position(), "array index must be an integer expression")
+			return &lvalue{typ: errType}, true
+		}
+		if base.comps != nil {
+			c.errorf(x.Pos, "cannot index a swizzle")
+			return &lvalue{typ: errType}, true
+		}
+		elem, stride, count := indexing(base.typ)
+		if elem == errType {
+			c.errorf(x.Pos, "cannot index a value of type %s", base.typ)
+			return &lvalue{typ: errType}, true
+		}
+		baseAddr := base.addr
+		return &lvalue{
+			typ: elem,
+			addr: func(e *env) int {
+				i := int(idx(e)[0])
+				if i < 0 {
+					i = 0
+				} else if i >= count {
+					i = count - 1
+				}
+				return baseAddr(e) + i*stride
+			},
+			readOnly: base.readOnly,
+			name:     base.name,
+		}, true
+	}
+	return nil, false
+}
+
+// indexing returns the element type, stride and count of an indexable type.
+func indexing(t Type) (elem Type, stride int, count int) {
+	switch {
+	case t.Array > 0:
+		return t.Elem(), t.Kind.Components(), t.Array
+	case t.Kind.IsMatrix():
+		n := t.Kind.MatrixSize()
+		return Type{Kind: Vec2 + Kind(n-2)}, n, n
+	case t.Kind.IsVector():
+		return Type{Kind: t.Kind.Scalar()}, 1, t.Kind.Components()
+	}
+	return errType, 0, 0
+}
+
+func hasDuplicates(comps []int) bool {
+	for i := range comps {
+		for j := i + 1; j < len(comps); j++ {
+			if comps[i] == comps[j] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+var sSwizzleSets = []string{"xyzw", "rgba", "stpq"}
+
+func (c *compiler) swizzle(x *FieldExpr, t Type) ([]int, Type) {
+	if t.Array > 0 || !(t.Kind.IsVector() || t.Kind.IsScalar()) {
+		c.errorf(x.Pos, "cannot access field '%s' of non-vector type %s", x.Name, t)
+		return nil, errType
+	}
+	n := t.Kind.Components()
+	if len(x.Name) > 4 {
+		c.errorf(x.Pos, "invalid swizzle '%s'", x.Name)
+		return nil, errType
+	}
+	for _, set := range sSwizzleSets {
+		if !strings.ContainsRune(set, rune(x.Name[0])) {
+			continue
+		}
+		comps := make([]int, len(x.Name))
+		for i, r := range x.Name {
+			comps[i] = strings.IndexRune(set, r)
+			if comps[i] < 0 || comps[i] >= n {
+				c.errorf(x.Pos, "invalid swizzle '%s' for type %s", x.Name, t)
+				return nil, errType
+			}
+		}
+		return comps, Type{Kind: vectorKind(t.Kind.Scalar(), len(comps))}
+	}
+	c.errorf(x.Pos, "invalid swizzle '%s'", x.Name)
+	return nil, errType
+}
+
+// rvalueAccess handles swizzles and indexing applied to temporaries.
+func (c *compiler) rvalueAccess(x Expr) (exprFn, Type) {
+	switch x := x.(type) {
+	case *FieldExpr:
+		fn, t := c.expr(x.X)
+		if t == errType {
+			return fn, t
+		}
+		comps, rt := c.swizzle(x, t)
+		if rt == errType {
+			return fn, rt
+		}
+		return func(e *env) (r val) {
+			v := fn(e)
+			for i, comp := range comps {
+				r[i] = v[comp]
+			}
+			return r
+		}, rt
+	case *IndexExpr:
+		fn, t := c.expr(x.X)
+		idx, it := c.expr(x.Index)
+		if t == errType || it == errType {
+			return fn, errType
+		}
+		if it.Kind != Int || it.Array > 0 {
+			c.errorf(x.Index.Position(), "array index must be an integer expression")
+			return fn, errType
+		}
+		elem, stride, count := indexing(t)
+		if elem == errType || t.Array > 0 {
+			c.errorf(x.Pos, "cannot index a value of type %s", t)
+			return fn, errType
+		}
+		n := elem.Size()
+		return func(e *env) (r val) {
+			v := fn(e)
+			i := int(idx(e)[0])
+			if i < 0 {
+				i = 0
+			} else if i >= count {
+				i = count - 1
+			}
+			copy(r[:n], v[i*stride:i*stride+n])
+			return r
+		}, elem
+	}
+	c.errorf(x.Position(), "unsupported expression")
+	return constFn(val{}), errType
+}
+
+func (c *compiler) writable(x Expr) (*lvalue, bool) {
+	lv, ok := c.lvalue(x, true)
+	if !ok {
+		c.errorf(x.Position(), "assignment to a value that is not an l-value")
+		return nil, false
+	}
+	if lv.typ == errType {
+		return nil, false
+	}
+	if lv.readOnly {
+		c.errorf(x.Position(), "assignment to read-only variable '%s'", lv.name)
+		return nil, false
+	}
+	return lv, true
+}
+
+func (c *compiler) unary(x *UnaryExpr) (exprFn, Type) {
+	switch x.Op {
+	case "++", "--":
+		lv, ok := c.writable(x.X)
+		if !ok {
+			return constFn(val{}), errType
+		}
+		if !lv.typ.Kind.IsNumeric() || lv.typ.Array > 0 {
+			c.errorf(x.Pos, "operand of '%s' must be numeric, found %s", x.Op, lv.typ)
+			return constFn(val{}), errType
+		}
+		load, n := lv.load(), lv.typ.Size()
+		var delta float32 = 1
+		if x.Op == "--" {
+			delta = -1
+		}
+		postfix := x.Postfix
+		return func(e *env) val {
+			old := load(e)
+			v := old
+			for i := 0; i < n; i++ {
+				v[i] += delta
+			}
+			lv.store(e, v)
+			if postfix {
+				return old
+			}
+			return v
+		}, lv.typ
+	}
+
+	fn, t := c.expr(x.X)
+	if t == errType {
+		return fn, t
+	}
+	n := t.Size()
+	switch x.Op {
+	case "+":
+		if !t.Kind.IsNumeric() || t.Array > 0 {
+			break
+		}
+		return fn, t
+	case "-":
+		if !t.Kind.IsNumeric() || t.Array > 0 {
+			break
+		}
+		return func(e *env) val {
+			v := fn(e)
+			for i := 0; i < n; i++ {
+				v[i] = -v[i]
+			}
+			return v
+		}, t
+	case "!":
+		if t != (Type{Kind: Bool}) {
+			break
+		}
+		return func(e *env) val {
+			v := fn(e)
+			v[0] = b2f(v[0] == 0)
+			return v
+		}, t
+	case "~":
+		if t.Kind.Scalar() != Int || t.Array > 0 {
+			break
+		}
+		return func(e *env) val {
+			v := fn(e)
+			for i := 0; i < n; i++ {
+				v[i] = float32(^int32(v[i]))
+			}
+			return v
+		}, t
+	}
+	c.errorf(x.Pos, "invalid operand type %s for unary '%s'", t, x.Op)
+	return constFn(val{}), errType
+}
+
+func b2f(b bool) float32 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func (c *compiler) binary(x *BinaryExpr) (exprFn, Type) {
+	fx, tx := c.expr(x.X)
+	fy, ty := c.expr(x.Y)
+	if tx == errType || ty == errType {
+		return constFn(val{}), errType
+	}
+	switch x.Op {
+	case "&&", "||", "^^":
+		if tx != (Type{Kind: Bool}) || ty != (Type{Kind: Bool}) {
+			c.errorf(x.Pos, "operands of '%s' must be scalar booleans, found %s and %s", x.Op, tx, ty)
+			return constFn(val{}), errType
+		}
+		t := Type{Kind: Bool}
+		switch x.Op {
+		case "&&":
+			return func(e *env) val {
+				if fx(e)[0] == 0 {
+					return val{}
+				}
+				return fy(e)
+			}, t
+		case "||":
+			return func(e *env) val {
+				if fx(e)[0] != 0 {
+					return val{0: 1}
+				}
+				return fy(e)
+			}, t
+		}
+		return func(e *env) val {
+			return val{0: b2f((fx(e)[0] != 0) != (fy(e)[0] != 0))}
+		}, t
+	case "==", "!=":
+		tx, ty = c.promote(tx, ty)
+		if tx != ty || tx.Kind.IsSampler() {
+			c.errorf(x.Pos, "operands of '%s' must have the same type, found %s and %s", x.Op, tx, ty)
+			return constFn(val{}), errType
+		}
+		n, eq := tx.Size(), x.Op == "=="
+		return func(e *env) val {
+			a, b := fx(e), fy(e)
+			same := a == b || equalN(a, b, n)
+			return val{0: b2f(same == eq)}
+		}, Type{Kind: Bool}
+	case "<", ">", "<=", ">=":
+		tx, ty = c.promote(tx, ty)
+		if tx != ty || !tx.Kind.IsScalar() || !tx.Kind.IsNumeric() || tx.Array > 0 {
+			c.errorf(x.Pos, "operands of '%s' must be scalar numbers of the same type, found %s and %s", x.Op, tx, ty)
+			return constFn(val{}), errType
+		}
+		var cmp func(a, b float32) bool
+		switch x.Op {
+		case "<":
+			cmp = func(a, b float32) bool { return a < b }
+		case ">":
+			cmp = func(a, b float32) bool { return a > b }
+		case "<=":
+			cmp = func(a, b float32) bool { return a <= b }
+		default:
+			cmp = func(a, b float32) bool { return a >= b }
+		}
+		return func(e *env) val {
+			return val{0: b2f(cmp(fx(e)[0], fy(e)[0]))}
+		}, Type{Kind: Bool}
+	}
+
+	t, op := c.arith(x.Pos, x.Op, tx, ty)
+	if t == errType {
+		return constFn(val{}), errType
+	}
+	return func(e *env) val {
+		return op(fx(e), fy(e))
+	}, t
+}
+
+func equalN(a, b val, n int) bool {
+	for i := 0; i < n; i++ {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// arith type checks an arithmetic or bitwise operator and returns its implementation.
+func (c *compiler) arith(pos Pos, op string, tx, ty Type) (Type, func(a, b val) val) {
+	if tx.Array > 0 || ty.Array > 0 || !tx.Kind.IsNumeric() || !ty.Kind.IsNumeric() {
+		c.errorf(pos, "operands of '%s' must be numeric, found %s and %s", op, tx, ty)
+		return errType, nil
+	}
+	tx, ty = c.promote(tx, ty)
+	kx, ky := tx.Kind, ty.Kind
+	if kx.Scalar() != ky.Scalar() {
+		c.errorf(pos, "operands of '%s' must have the same base type, found %s and %s", op, tx, ty)
+		return errType, nil
+	}
+
+	isInt := kx.Scalar() == Int
+	switch op {
+	case "%", "&", "|", "^", "<<", ">>":
+		if !isInt {
+			c.errorf(pos, "operands of '%s' must be integers, found %s and %s", op, tx, ty)
+			return errType, nil
+		}
+	}
+
+	var rk Kind
+	switch {
+	case kx == ky:
+		rk = kx
+	case kx.IsScalar():
+		rk = ky
+	case ky.IsScalar():
+		rk = kx
+	case op == "*" && kx.IsMatrix() && ky.IsVector() && ky.Components() == kx.MatrixSize():
+		n := kx.MatrixSize()
+		return Type{Kind: ky}, func(m, v val) (r val) {
+			for row := 0; row < n; row++ {
+				var s float32
+				for col := 0; col < n; col++ {
+					s += m[col*n+row] * v[col]
+				}
+				r[row] = s
+			}
+			return r
+		}
+	case op == "*" && kx.IsVector() && ky.IsMatrix() && kx.Components() == ky.MatrixSize():
+		n := ky.MatrixSize()
+		return Type{Kind: kx}, func(v, m val) (r val) {
+			for col := 0; col < n; col++ {
+				var s float32
+				for row := 0; row < n; row++ {
+					s += v[row] * m[col*n+row]
+				}
+				r[col] = s
+			}
+			return r
+		}
+	default:
+		c.errorf(pos, "operands of '%s' have incompatible types %s and %s", op, tx, ty)
+		return errType, nil
+	}
+
+	if op == "*" && kx.IsMatrix() && ky.IsMatrix() {
+		n := kx.MatrixSize()
+		return Type{Kind: rk}, func(a, b val) (r val) {
+			for col := 0; col < n; col++ {
+				for row := 0; row < n; row++ {
+					var s float32
+					for k := 0; k < n; k++ {
+						s += a[k*n+row] * b[col*n+k]
+					}
+					r[col*n+row] = s
+				}
+			}
+			return r
+		}
+	}
+
+	n := rk.Components()
+	sx, sy := 1, 1
+	if kx.IsScalar() {
+		sx = 0
+	}
+	if ky.IsScalar() {
+		sy = 0
+	}
+	var f func(a, b float32) float32
+	switch op {
+	case "+":
+		f = func(a, b float32) float32 { return a + b }
+	case "-":
+		f = func(a, b float32) float32 { return a - b }
+	case "*":
+		f = func(a, b float32) float32 { return a * b }
+	case "/":
+		if isInt {
+			f = func(a, b float32) float32 {
+				if b == 0 {
+					return 0
+				}
+				return float32(int32(a) / int32(b))
+			}
+		} else {
+			f = func(a, b float32) float32 { return a / b }
+		}
+	case "%":
+		f = func(a, b float32) float32 {
+			if b == 0 {
+				return 0
+			}
+			return float32(int32(a) % int32(b))
+		}
+	case "&":
+		f = func(a, b float32) float32 { return float32(int32(a) & int32(b)) }
+	case "|":
+		f = func(a, b float32) float32 { return float32(int32(a) | int32(b)) }
+	case "^":
+		f = func(a, b float32) float32 { return float32(int32(a) ^ int32(b)) }
+	case "<<":
+		f = func(a, b float32) float32 { return float32(int32(a) << uint32(b)) }
+	case ">>":
+		f = func(a, b float32) float32 { return float32(int32(a) >> uint32(b)) }
+	default:
+		c.errorf(pos, "unsupported operator '%s'", op)
+		return errType, nil
+	}
+	return Type{Kind: rk}, func(a, b val) (r val) {
+		for i := 0; i < n; i++ {
+			r[i] = f(a[i*sx], b[i*sy])
+		}
+		return r
+	}
+}
+
+func (c *compiler) assign(x *AssignExpr) (exprFn, Type) {
+	lv, ok := c.writable(x.LHS)
+	rhs, rt := c.expr(x.RHS)
+	if !ok || rt == errType {
+		return constFn(val{}), errType
+	}
+	if x.Op == "=" {
+		if !c.convertible(rt, lv.typ) {
+			c.errorf(x.Pos, "value of type %s cannot be assigned to variable of type %s", rt, lv.typ)
+			return constFn(val{}), errType
+		}
+		return func(e *env) val {
+			v := rhs(e)
+			lv.store(e, v)
+			return v
+		}, lv.typ
+	}
+
+	op := strings.TrimSuffix(x.Op, "=")
+	t, f := c.arith(x.Pos, op, lv.typ, rt)
+	if t == errType {
+		return constFn(val{}), errType
+	}
+	if t.Kind != lv.typ.Kind.withScalar(t.Kind.Scalar()) || !c.convertible(t, lv.typ) {
+		c.errorf(x.Pos, "result of '%s' has type %s, cannot be assigned to %s", x.Op, t, lv.typ)
+		return constFn(val{}), errType
+	}
+	load := lv.load()
+	return func(e *env) val {
+		v := f(load(e), rhs(e))
+		lv.store(e, v)
+		return v
+	}, lv.typ
+}
+
+// Calls ----------------------------------------------------------------------
+
+func (c *compiler) call(x *CallExpr) (exprFn, Type) {
+	args := make([]exprFn, len(x.Args))
+	types := make([]Type, len(x.Args))
+	for i, a := range x.Args {
+		args[i], types[i] = c.expr(a)
+		if types[i] == errType {
+			return constFn(val{}), errType
+		}
+	}
+
+	if kind, ok := sKindByName[x.Func]; ok {
+		return c.constructor(x, Type{Kind: kind}, args, types)
+	}
+
+	if fns, ok := c.funcs[x.Func]; ok {
+		return c.userCall(x, fns, args, types)
+	}
+
+	if b, ok := sBuiltins[x.Func]; ok {
+		rt, msg := b.check(types)
+		if msg != "" {
+			c.errorf(x.Pos, "%s: %s", x.Func, msg)
+			return constFn(val{}), errType
+		}
+		return builtinCall(b, args, types, rt), rt
+	}
+
+	if v := c.lookup(x.Func); v != nil {
+		c.errorf(x.Pos, "'%s' is not a function", x.Func)
+	} else {
+		c.errorf(x.Pos, "no function with name '%s'", x.Func)
+	}
+	return constFn(val{}), errType
+}
+
+func describeArgs(types []Type) string {
+	var names []string
+	for _, t := range types {
+		names = append(names, t.String())
+	}
+	return strings.Join(names, ", ")
+}
+
+func (c *compiler) userCall(x *CallExpr, fns []*function, args []exprFn, types []Type) (exprFn, Type) {
+	var fn *function
+	for _, exact := range []bool{true, false} {
+		for _, candidate := range fns {
+			if len(candidate.params) != len(types) {
+				continue
+			}
+			match := true
+			for i, p := range candidate.params {
+				if (exact && types[i] != p.Type) || (!exact && !c.convertible(types[i], p.Type)) {
+					match = false
+				}
+				if p.Storage != StorageIn && types[i] != p.Type {
+					match = false
+				}
+			}
+			if match {
+				fn = candidate
+				break
+			}
+		}
+		if fn != nil {
+			break
+		}
+	}
+	if fn == nil {
+		c.errorf(x.Pos, "no matching function for call to '%s(%s)'", x.Func, describeArgs(types))
+		return constFn(val{}), errType
+	}
+	if c.fn == fn {
+		c.errorf(x.Pos, "recursive call to '%s'", x.Func)
+		return constFn(val{}), errType
+	}
+
+	// out and inout arguments are written back after the call
+	outs := make([]*lvalue, len(x.Args))
+	for i, p := range fn.params {
+		if p.Storage == StorageOut || p.Storage == StorageInOut {
+			lv, ok := c.writable(x.Args[i])
+			if !ok {
+				return constFn(val{}), errType
+			}
+			outs[i] = lv
+		}
+	}
+
+	params := fn.params
+	return func(e *env) val {
+		var vals [8]val
+		in := vals[:0]
+		if len(args) > len(vals) {
+			in = make([]val, 0, len(args))
+		}
+		for _, a := range args {
+			in = append(in, a(e))
+		}
+		for i, p := range params {
+			n := p.Type.Size()
+			copy(e.mem[p.offset:p.offset+n], in[i][:n])
+		}
+		if fn.body == nil {
+			return val{}
+		}
+		fn.body(e)
+		ret := e.ret
+		for i, lv := range outs {
+			if lv != nil {
+				var v val
+				p := params[i]
+				copy(v[:], e.mem[p.offset:p.offset+p.Type.Size()])
+				lv.store(e, v)
+			}
+		}
+		return ret
+	}, fn.ret
+}
+
+func (c *compiler) constructor(x *CallExpr, t Type, args []exprFn, types []Type) (exprFn, Type) {
+	if len(args) == 0 {
+		c.errorf(x.Pos, "too few arguments to constructor of %s", t)
+		return constFn(val{}), errType
+	}
+	total := 0
+	for i, at := range types {
+		if at.Array > 0 || at.Kind.IsSampler() || at.Kind == Void {
+			c.errorf(x.Args[i].Position(), "invalid argument of type %s to constructor of %s", at, t)
+			return constFn(val{}), errType
+		}
+		total += at.Size()
+	}
+	if t.Kind.IsSampler() || t.Kind == Void {
+		c.errorf(x.Pos, "cannot construct %s", t)
+		return constFn(val{}), errType
+	}
+
+	n := t.Size()
+	scalar := t.Kind.Scalar()
+	convert := func(v float32) float32 {
+		switch scalar {
+		case Int:
+			return float32(int32(v))
+		case Bool:
+			return b2f(v != 0)
+		}
+		return v
+	}
+
+	// single scalar: broadcast, or a diagonal matrix
+	if len(args) == 1 && types[0].Kind.IsScalar() {
+		a := args[0]
+		if t.Kind.IsMatrix() {
+			m := t.Kind.MatrixSize()
+			return func(e *env) (r val) {
+				v := a(e)[0]
+				for i := 0; i < m; i++ {
+					r[i*m+i] = v
+				}
+				return r
+			}, t
+		}
+		return func(e *env) (r val) {
+			v := convert(a(e)[0])
+			for i := 0; i < n; i++ {
+				r[i] = v
+			}
+			return r
+		}, t
+	}
+
+	// matrix from matrix
+	if len(args) == 1 && types[0].Kind.IsMatrix() && t.Kind.IsMatrix() {
+		a := args[0]
+		src, dst := types[0].Kind.MatrixSize(), t.Kind.MatrixSize()
+		return func(e *env) (r val) {
+			v := a(e)
+			for col := 0; col < dst; col++ {
+				for row := 0; row < dst; row++ {
+					if col < src && row < src {
+						r[col*dst+row] = v[col*src+row]
+					} else if col == row {
+						r[col*dst+row] = 1
+					}
+				}
+			}
+			return r
+		}, t
+	}
+
+	if t.Kind.IsMatrix() {
+		for i, at := range types {
+			if at.Kind.IsMatrix() {
+				c.errorf(x.Args[i].Position(), "matrix argument to constructor of %s must be the only argument", t)
+				return constFn(val{}), errType
+			}
+		}
+		if total != n {
+			c.errorf(x.Pos, "wrong number of components to constructor of %s: %d", t, total)
+			return constFn(val{}), errType
+		}
+	} else if total < n {
+		c.errorf(x.Pos, "too few components to constructor of %s: %d", t, total)
+		return constFn(val{}), errType
+	} else if len(args) > 1 && total-types[len(types)-1].Size() >= n {
+		c.errorf(x.Pos, "too many arguments to constructor of %s", t)
+		return constFn(val{}), errType
+	}
+
+	sizes := make([]int, len(types))
+	for i, at := range types {
+		sizes[i] = at.Size()
+	}
+	return func(e *env) (r val) {
+		k := 0
+		for i, a := range args {
+			v := a(e)
+			for j := 0; j < sizes[i] && k < n; j++ {
+				r[k] = convert(v[j])
+				k++
+			}
+		}
+		return r
+	}, t
+}
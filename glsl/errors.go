@@ -0,0 +1,40 @@
+package glsl
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Error is a diagnostic attached to a source position. File is set by the
+// Preprocessor.
+type Error struct {
+	File string
+	Line int
+	Col  int
+	Msg  string
+}
+
+func (e *Error) Error() string {
+	if e.File != "" {
+		return fmt.Sprintf("%s:%d(%d): error: %s", e.File, e.Line, e.Col, e.Msg)
+	}
+	return fmt.Sprintf("0:%d(%d): error: %s", e.Line, e.Col, e.Msg)
+}
+
+// ErrorList ...
+type ErrorList []*Error
+
+func (l ErrorList) Error() string {
+	var msgs []string
+	for _, e := range l {
+		msgs = append(msgs, e.Error())
+	}
+	return strings.Join(msgs, "\n")
+}
+
+func (l ErrorList) err() error {
+	if len(l) == 0 {
+		return nil
+	}
+	return l
+}
@@ -0,0 +1,214 @@
+package glsl
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+const (
+	testVert = `#version 330
+  in vec3 position;
+  in vec3 normal;
+  out vec3 out_normal;
+  uniform mat4 projection;
+  uniform mat4 camera;
+  uniform mat4 model;
+
+  void main()
+  {
+      gl_Position = projection * camera * model * vec4(position, 1);
+      out_normal = normalize(model * vec4(normal, 0)).xyz;
+  }`
+
+	testFrag = `#version 330
+  uniform vec4 color1;
+	uniform vec3 light;
+  in vec3 out_normal;
+  out vec4 colourOut;
+
+  void main(void)
+  {
+  	float cosTheta = clamp(dot(light, normalize(out_normal)), 0.3, 1.0);
+  	colourOut = color1 * cosTheta;
+  }`
+)
+
+func link(t *testing.T, vert, frag string) *Program {
+	vs, err := Compile(VertexStage, vert)
+	if err != nil {
+		t.Fatalf("vertex: %v", err)
+	}
+	fs, err := Compile(FragmentStage, frag)
+	if err != nil {
+		t.Fatalf("fragment: %v", err)
+	}
+	p, err := Link(vs, fs, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func near(a, b float32) bool {
+	return math.Abs(float64(a-b)) < 1e-5
+}
+
+func TestRunProgram(t *testing.T) {
+	p := link(t, testVert, testFrag)
+
+	if loc := p.AttribLocation("position"); loc != 0 {
+		t.Fatalf("position location %d", loc)
+	}
+	if loc := p.AttribLocation("normal"); loc != 1 {
+		t.Fatalf("normal location %d", loc)
+	}
+
+	ident := []float32{1, 0, 0, 0, 0, 1, 0, 0, 0, 0, 1, 0, 0, 0, 0, 1}
+	scale := []float32{2, 0, 0, 0, 0, 2, 0, 0, 0, 0, 2, 0, 0, 0, 0, 1}
+	p.SetUniform(p.UniformLocation("projection"), ident)
+	p.SetUniform(p.UniformLocation("camera"), ident)
+	p.SetUniform(p.UniformLocation("model"), scale)
+	p.SetUniform(p.UniformLocation("light"), []float32{0, 0, 1})
+	p.SetUniform(p.UniformLocation("color1"), []float32{1, 0.5, 0, 1})
+
+	p.SetAttrib(0, [4]float32{0.25, 0.5, 0, 1})
+	p.SetAttrib(1, [4]float32{0, 0, 1, 0})
+
+	out := make([]float32, p.VaryingComponents())
+	pos, _ := p.RunVertex(0, 0, out)
+	if pos != [4]float32{0.5, 1, 0, 1} {
+		t.Fatalf("gl_Position %v", pos)
+	}
+	if !near(out[2], 1) {
+		t.Fatalf("out_normal %v", out)
+	}
+
+	color, discarded := p.RunFragment(out, [4]float32{0.5, 0.5, 0, 1}, true)
+	if discarded || color != [4]float32{1, 0.5, 0, 1} {
+		t.Fatalf("color %v %v", color, discarded)
+	}
+
+	// facing away from the light is clamped to 0.3
+	color, _ = p.RunFragment([]float32{0, 0, -1}, [4]float32{}, true)
+	if !near(color[0], 0.3) {
+		t.Fatalf("color %v", color)
+	}
+}
+
+func TestControlFlow(t *testing.T) {
+	frag := `#version 330
+  in vec2 uv;
+  out vec4 colourOut;
+  uniform float weights[4];
+
+  float sum(int n) {
+    float s = 0.0;
+    for (int i = 0; i < 4; i++) {
+      if (i >= n) break;
+      s += weights[i];
+    }
+    return s;
+  }
+
+  void main() {
+    if (uv.x < 0.0) discard;
+    int n = int(uv.y);
+    colourOut = vec4(sum(n), n > 2 ? 1.0 : 0.0, mod(7.0, 4.0), 1.0);
+  }`
+	vert := `#version 330
+  in vec2 pos;
+  out vec2 uv;
+  void main() { uv = pos; gl_Position = vec4(pos, 0.0, 1.0); }`
+
+	p := link(t, vert, frag)
+	p.SetUniform(p.UniformLocation("weights"), []float32{1, 2, 4, 8})
+	if loc := p.UniformLocation("weights[2]"); loc < 0 {
+		t.Fatal("weights[2] not found")
+	} else {
+		p.SetUniform(loc, []float32{16})
+	}
+
+	color, discarded := p.RunFragment([]float32{1, 3}, [4]float32{}, true)
+	if discarded || color != [4]float32{19, 1, 3, 1} {
+		t.Fatalf("color %v %v", color, discarded)
+	}
+	if _, discarded = p.RunFragment([]float32{-1, 3}, [4]float32{}, true); !discarded {
+		t.Fatal("expected discard")
+	}
+}
+
+func TestESShader(t *testing.T) {
+	vert := `precision highp float;
+  attribute vec4 position;
+  attribute vec2 uvs;
+  varying vec2 out_uvs;
+  uniform mat3 ModelviewMatrix;
+  void main()
+  {
+		gl_Position = vec4(ModelviewMatrix * vec3(position.xy, 1.0), 0.0).xywz;
+  	out_uvs = uvs;
+  }`
+	frag := `precision highp float;
+  varying vec2 out_uvs;
+  uniform sampler2D tex;
+  void main() {
+    gl_FragColor = texture2D(tex, out_uvs);
+  }`
+
+	p := link(t, vert, frag)
+	p.SetUniform(p.UniformLocation("ModelviewMatrix"), []float32{1, 0, 0, 0, 1, 0, 3, 4, 1})
+	p.SetAttrib(p.AttribLocation("position"), [4]float32{1, 2, 0, 1})
+	out := make([]float32, p.VaryingComponents())
+	if pos, _ := p.RunVertex(0, 0, out); pos != [4]float32{4, 6, 0, 1} {
+		t.Fatalf("gl_Position %v", pos)
+	}
+
+	p.SetSampler(samplerFunc(func(unit int, s, t, lod float32) [4]float32 {
+		return [4]float32{float32(unit), s, t, 1}
+	}))
+	p.SetUniform(p.UniformLocation("tex"), []float32{2})
+	if color, _ := p.RunFragment([]float32{0.25, 0.75}, [4]float32{}, true); color != [4]float32{2, 0.25, 0.75, 1} {
+		t.Fatalf("color %v", color)
+	}
+}
+
+type samplerFunc func(unit int, s, t, lod float32) [4]float32
+
+func (f samplerFunc) Sample2D(unit int, s, t, lod float32) [4]float32 {
+	return f(unit, s, t, lod)
+}
+
+func TestErrors(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{"void main() { float x = 1.0 }", "0:1(29): error: syntax error"},
+		{"void main() { y = 1.0; }", "0:1(15): error: 'y' undeclared"},
+		{"void main() { vec3 v = vec2(1.0); }", "cannot be assigned"},
+		{"void f() {}", "no main() function defined"},
+		// GLSL ES has no implicit conversions
+		{"#version 100\nvoid main() { float a = 1.0 - 1; }", "0:2(29): error: operands of '-' must have the same base type, found float and int"},
+		{"#version 300 es\nvoid main() { float a = 1; }", "cannot be assigned"},
+		{"#version 100\nvoid main() { float a = float(1) + max(2.0, 1.0); }", ""},
+	}
+	for _, test := range tests {
+		_, err := Compile(FragmentStage, test.src)
+		if test.want == "" {
+			if err != nil {
+				t.Errorf("%q: %v", test.src, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("%q: got %v, want %q", test.src, err, test.want)
+		}
+	}
+
+	vs, _ := Compile(VertexStage, "out vec3 a; void main() {}")
+	fs, _ := Compile(FragmentStage, "in vec2 a; void main() {}")
+	if _, err := Link(vs, fs, nil); err == nil || !strings.Contains(err.Error(), "varying a") {
+		t.Errorf("link: %v", err)
+	}
+}
@@ -0,0 +1,63 @@
+package glsl
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Diagnostic is a message of the info log of a shader, at a line and a
+// column of the compiled source, 0 when the compiler does not tell.
+type Diagnostic struct {
+	// Severity is "error" or "warning", "" for a line of no known format.
+	Severity string
+	Line     int
+	Col      int
+	Msg      string
+}
+
+var (
+	// NVIDIA: 0(12) : error C1008: undefined variable "foo"
+	sNvidiaLog = regexp.MustCompile(`^\d+\((\d+)\)\s*:\s*(error|warning)\s+(\w+:.*)$`)
+	// Mesa and this package: 0:12(5): error: `foo' undeclared
+	sMesaLog = regexp.MustCompile(`^\d+:(\d+)\((\d+)\):\s*(?:preprocessor\s+)?(error|warning):\s*(.*)$`)
+	// ANGLE, Apple, AMD and Intel: ERROR: 0:12: 'foo' : undeclared identifier
+	sAngleLog = regexp.MustCompile(`^(ERROR|WARNING):\s*\d+:(\d+):\s*(.*)$`)
+	// the lines without position, ERROR: 1 compilation errors.
+	sSeverityLog = regexp.MustCompile(`^(ERROR|WARNING|error|warning):\s*(.*)$`)
+)
+
+// ParseInfoLog parses the info log of a shader, in the formats of NVIDIA,
+// Mesa, ANGLE and the drivers that share it, and this package.
+func ParseInfoLog(log string) []Diagnostic {
+	var res []Diagnostic
+	for _, line := range strings.Split(log, "\n") {
+		line = strings.TrimSpace(strings.TrimRight(line, "\x00"))
+		if line == "" {
+			continue
+		}
+		res = append(res, parseInfoLogLine(line))
+	}
+	return res
+}
+
+func parseInfoLogLine(line string) Diagnostic {
+	if m := sNvidiaLog.FindStringSubmatch(line); m != nil {
+		return Diagnostic{Severity: m[2], Line: atoi(m[1]), Msg: m[3]}
+	}
+	if m := sMesaLog.FindStringSubmatch(line); m != nil {
+		return Diagnostic{Severity: m[3], Line: atoi(m[1]), Col: atoi(m[2]), Msg: m[4]}
+	}
+	if m := sAngleLog.FindStringSubmatch(line); m != nil {
+		return Diagnostic{Severity: strings.ToLower(m[1]), Line: atoi(m[2]), Msg: m[3]}
+	}
+	if m := sSeverityLog.FindStringSubmatch(line); m != nil {
+		return Diagnostic{Severity: strings.ToLower(m[1]), Msg: m[2]}
+	}
+	return Diagnostic{Msg: line}
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
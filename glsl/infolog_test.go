@@ -0,0 +1,59 @@
+package glsl
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseInfoLog(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		log  string
+		want []Diagnostic
+	}{
+		{"nvidia", "0(12) : error C1008: undefined variable \"foo\"\n" +
+			"0(14) : warning C7011: implicit cast from \"int\" to \"float\"\n" +
+			"0(20) : error C0000: syntax error, unexpected '}', expecting ',' or ';' at token \"}\"\n\x00",
+			[]Diagnostic{
+				{"error", 12, 0, "C1008: undefined variable \"foo\""},
+				{"warning", 14, 0, "C7011: implicit cast from \"int\" to \"float\""},
+				{"error", 20, 0, "C0000: syntax error, unexpected '}', expecting ',' or ';' at token \"}\""},
+			}},
+		{"mesa", "0:3(10): error: `foo' undeclared\n" +
+			"0:3(10): error: type mismatch\n" +
+			"0:7(1): warning: `c' used uninitialized\n" +
+			"0:1(10): preprocessor error: #version must appear on the first line\n",
+			[]Diagnostic{
+				{"error", 3, 10, "`foo' undeclared"},
+				{"error", 3, 10, "type mismatch"},
+				{"warning", 7, 1, "`c' used uninitialized"},
+				{"error", 1, 10, "#version must appear on the first line"},
+			}},
+		{"angle", "WARNING: 0:4: extension 'GL_OES_standard_derivatives' is not supported\n" +
+			"ERROR: 0:12: 'foo' : undeclared identifier \n" +
+			"ERROR: 0:12: 'assign' :  cannot convert from 'const float' to 'highp 4-component vector of float'\n" +
+			"ERROR: 2 compilation errors.  No code generated.\n\n",
+			[]Diagnostic{
+				{"warning", 4, 0, "extension 'GL_OES_standard_derivatives' is not supported"},
+				{"error", 12, 0, "'foo' : undeclared identifier"},
+				{"error", 12, 0, "'assign' :  cannot convert from 'const float' to 'highp 4-component vector of float'"},
+				{"error", 0, 0, "2 compilation errors.  No code generated."},
+			}},
+		{"glsl", ErrorList{
+			{Line: 2, Col: 5, Msg: "undeclared identifier foo"},
+			{File: "light.glsl", Line: 1, Col: 1, Msg: "syntax error"},
+		}.Error(),
+			[]Diagnostic{
+				{"error", 2, 5, "undeclared identifier foo"},
+				{"", 0, 0, "light.glsl:1(1): error: syntax error"},
+			}},
+		{"unknown", "Internal error: assembly compile error\n", []Diagnostic{
+			{"", 0, 0, "Internal error: assembly compile error"},
+		}},
+		{"empty", "", nil},
+	} {
+		if got := ParseInfoLog(test.log); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got %q", test.name, got)
+		}
+	}
+}
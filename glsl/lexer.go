@@ -0,0 +1,189 @@
+// Package glsl parses, type checks and interprets the subset of GLSL used by
+// the glplus shaders (GLSL 330 and ES 1.00), for the software Context. Its
+// Preprocessor turns the glplus shader sources into the GLSL of a context.
+package glsl
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TokenKind ...
+type TokenKind int
+
+// Token kinds
+const (
+	EOF TokenKind = iota
+	Ident
+	IntLit
+	FloatLit
+	Punct
+	Directive
+)
+
+// Token ...
+type Token struct {
+	Kind TokenKind
+	Text string
+	Line int
+	Col  int
+}
+
+func (t Token) String() string {
+	if t.Kind == EOF {
+		return "end of file"
+	}
+	return fmt.Sprintf("'%s'", t.Text)
+}
+
+// operators sorted so that the longest match wins
+var sPunctuators = []string{
+	"<<=", ">>=",
+	"++", "--", "<=", ">=", "==", "!=", "&&", "||", "^^", "+=", "-=", "*=", "/=", "%=", "&=", "|=", "^=", "<<", ">>",
+	"(", ")", "[", "]", "{", "}", ".", ",", ";", ":", "?", "=", "+", "-", "*", "/", "%", "<", ">", "!", "~", "&", "|", "^",
+}
+
+// Lex splits a shader source into tokens. Preprocessor lines are returned
+// whole as Directive tokens.
+func Lex(src string) (tokens []Token, err error) {
+	line, col := 1, 1
+	i := 0
+	lineStart := true
+
+	advance := func(n int) {
+		for k := 0; k < n; k++ {
+			if src[i] == '\n' {
+				line++
+				col = 1
+				lineStart = true
+			} else {
+				col++
+			}
+			i++
+		}
+	}
+
+	for i < len(src) {
+		c := src[i]
+		switch {
+		case c == '\n':
+			advance(1)
+			continue
+		case c == ' ' || c == '\t' || c == '\r' || c == '\f' || c == '\v':
+			advance(1)
+			continue
+		case strings.HasPrefix(src[i:], "//"):
+			for i < len(src) && src[i] != '\n' {
+				advance(1)
+			}
+			continue
+		case strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				return nil, &Error{Line: line, Col: col, Msg: "unterminated comment"}
+			}
+			advance(end + 4)
+			continue
+		case c == '#' && lineStart:
+			tokLine, tokCol := line, col
+			start := i
+			for i < len(src) && src[i] != '\n' {
+				// line continuation
+				if src[i] == '\\' && i+1 < len(src) && src[i+1] == '\n' {
+					advance(2)
+					continue
+				}
+				advance(1)
+			}
+			text := strings.Replace(src[start:i], "\\\n", " ", -1)
+			tokens = append(tokens, Token{Kind: Directive, Text: strings.TrimSpace(text), Line: tokLine, Col: tokCol})
+			continue
+		}
+
+		lineStart = false
+		tokLine, tokCol := line, col
+		start := i
+		switch {
+		case isIdentStart(c):
+			for i < len(src) && isIdentChar(src[i]) {
+				advance(1)
+			}
+			tokens = append(tokens, Token{Kind: Ident, Text: src[start:i], Line: tokLine, Col: tokCol})
+		case isDigit(c) || (c == '.' && i+1 < len(src) && isDigit(src[i+1])):
+			kind := lexNumber(src, &i)
+			col += i - start
+			tokens = append(tokens, Token{Kind: kind, Text: src[start:i], Line: tokLine, Col: tokCol})
+		default:
+			var punct string
+			for _, p := range sPunctuators {
+				if strings.HasPrefix(src[i:], p) {
+					punct = p
+					break
+				}
+			}
+			if punct == "" {
+				return nil, &Error{Line: line, Col: col, Msg: fmt.Sprintf("unexpected character '%c'", c)}
+			}
+			advance(len(punct))
+			tokens = append(tokens, Token{Kind: Punct, Text: punct, Line: tokLine, Col: tokCol})
+		}
+	}
+	tokens = append(tokens, Token{Kind: EOF, Line: line, Col: col})
+	return tokens, nil
+}
+
+func lexNumber(src string, pos *int) TokenKind {
+	i := *pos
+	kind := IntLit
+	if strings.HasPrefix(src[i:], "0x") || strings.HasPrefix(src[i:], "0X") {
+		i += 2
+		for i < len(src) && isHexDigit(src[i]) {
+			i++
+		}
+	} else {
+		for i < len(src) && isDigit(src[i]) {
+			i++
+		}
+		if i < len(src) && src[i] == '.' {
+			kind = FloatLit
+			i++
+			for i < len(src) && isDigit(src[i]) {
+				i++
+			}
+		}
+		if i < len(src) && (src[i] == 'e' || src[i] == 'E') {
+			kind = FloatLit
+			i++
+			if i < len(src) && (src[i] == '+' || src[i] == '-') {
+				i++
+			}
+			for i < len(src) && isDigit(src[i]) {
+				i++
+			}
+		}
+	}
+	if i < len(src) && (src[i] == 'f' || src[i] == 'F') {
+		kind = FloatLit
+		i++
+	} else if i < len(src) && (src[i] == 'u' || src[i] == 'U') {
+		i++
+	}
+	*pos = i
+	return kind
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isIdentChar(c byte) bool {
+	return isIdentStart(c) || isDigit(c)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isHexDigit(c byte) bool {
+	return isDigit(c) || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
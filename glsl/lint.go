@@ -0,0 +1,203 @@
+package glsl
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LintProgram is a program for Lint: the glplus sources of its stages, as
+// given to LoadShaderProgram, with the attributes given along and the
+// uniforms the Go code sets.
+type LintProgram struct {
+	Vertex   string
+	Fragment string
+	Attribs  []string
+	// Uniforms are names as in SetUniforms and ProgramUniform1f, "lights[2]"
+	// is the uniform lights.
+	Uniforms []string
+	// Includer finds the #include of the sources, nil for none.
+	Includer Includer
+}
+
+// Problem is a finding of Lint, at a line of the sources as written. File is
+// "vertex" or "fragment" for the sources of the LintProgram, the name of an
+// #include, or "" for a problem of the whole program; Line and Col are 0
+// when unknown.
+type Problem struct {
+	File string
+	Line int
+	Col  int
+	Msg  string
+}
+
+func (p Problem) String() string {
+	var b strings.Builder
+	if p.File != "" {
+		b.WriteString(p.File)
+		if p.Line > 0 {
+			fmt.Fprintf(&b, ":%d", p.Line)
+			if p.Col > 0 {
+				fmt.Fprintf(&b, ":%d", p.Col)
+			}
+		}
+		b.WriteString(": ")
+	}
+	b.WriteString(p.Msg)
+	return b.String()
+}
+
+// LintDialects are the dialects Lint translates the sources to, the
+// desktop one and GLSL ES 1.00, the strictest of the others.
+var LintDialects = []Dialect{GLSL330, GLSLES100}
+
+// Lint translates the sources to each of LintDialects and type checks them,
+// then checks that the attributes are inputs of the vertex stage, that the
+// uniforms are declared and that the inputs of the fragment stage are
+// outputs of the vertex stage of the same type. A problem of one dialect
+// only says which.
+func Lint(prog *LintProgram) []Problem {
+	l := &linter{seen: make(map[Problem]bool)}
+	// the checks of the names need a dialect the stages compile in
+	var inputs, uniforms map[string]bool
+	for i, dialect := range LintDialects {
+		l.dialect = ""
+		if i > 0 {
+			l.dialect = dialect.String()
+		}
+		_, vs := l.compile(dialect, VertexStage, prog.Vertex, prog.Includer)
+		frag, fs := l.compile(dialect, FragmentStage, prog.Fragment, prog.Includer)
+		if vs != nil && inputs == nil {
+			inputs = names(vs.Variables(StorageIn))
+		}
+		if vs != nil && fs != nil {
+			if uniforms == nil {
+				uniforms = names(append(vs.Variables(StorageUniform), fs.Variables(StorageUniform)...))
+			}
+			l.varyings(vs, frag, fs)
+		}
+	}
+	l.dialect = ""
+	if inputs != nil {
+		for _, name := range prog.Attribs {
+			if !inputs[name] {
+				l.report(Problem{Msg: fmt.Sprintf("attribute %s is not an input of the vertex shader", name)})
+			}
+		}
+	}
+	if uniforms != nil {
+		for _, name := range prog.Uniforms {
+			if i := strings.IndexAny(name, "[."); i >= 0 {
+				name = name[:i]
+			}
+			if !uniforms[name] {
+				l.report(Problem{Msg: fmt.Sprintf("uniform %s is set but not declared", name)})
+			}
+		}
+	}
+	return l.problems
+}
+
+func names(vars []*Variable) map[string]bool {
+	res := make(map[string]bool, len(vars))
+	for _, v := range vars {
+		res[v.Name] = true
+	}
+	return res
+}
+
+type linter struct {
+	// dialect is the one the problems are found in, "" for the first
+	dialect  string
+	problems []Problem
+	seen     map[Problem]bool
+}
+
+// report adds a problem, but the ones of another dialect already, whose
+// column may differ.
+func (l *linter) report(p Problem) {
+	key := p
+	key.Col = 0
+	if l.seen[key] {
+		return
+	}
+	l.seen[key] = true
+	if l.dialect != "" {
+		p.Msg += " (" + l.dialect + ")"
+	}
+	l.problems = append(l.problems, p)
+}
+
+func (l *linter) compile(dialect Dialect, stage Stage, src string, includer Includer) (*Output, *Shader) {
+	pp := &Preprocessor{Dialect: dialect, Includer: includer, FlattenUniformBlocks: true}
+	out, err := pp.Preprocess(stage.String(), src)
+	if err != nil {
+		l.errors(nil, err)
+		return nil, nil
+	}
+	s, err := Compile(stage, out.Text)
+	if err != nil {
+		l.errors(out, err)
+		return out, nil
+	}
+	return out, s
+}
+
+// errors reports the errors of the preprocessor, whose lines are the ones
+// of the sources, or of the compiler of out.
+func (l *linter) errors(out *Output, err error) {
+	var errs ErrorList
+	switch err := err.(type) {
+	case ErrorList:
+		errs = err
+	case *Error:
+		errs = ErrorList{err}
+	default:
+		l.report(Problem{Msg: err.Error()})
+		return
+	}
+	for _, e := range errs {
+		if out == nil {
+			l.report(Problem{File: e.File, Line: e.Line, Col: e.Col, Msg: e.Msg})
+		} else {
+			l.report(out.problem(e.Line, e.Col, e.Msg))
+		}
+	}
+}
+
+// varyings checks the inputs of the fragment stage against the outputs of
+// the vertex stage.
+func (l *linter) varyings(vs *Shader, frag *Output, fs *Shader) {
+	outputs := make(map[string]*Variable)
+	for _, v := range vs.Variables(StorageOut) {
+		outputs[v.Name] = v
+	}
+	for _, v := range fs.Variables(StorageIn) {
+		out := outputs[v.Name]
+		switch {
+		case out == nil:
+			l.report(frag.problem(v.Pos.Line, v.Pos.Col, fmt.Sprintf("varying %s is not an output of the vertex shader", v.Name)))
+		case out.Type != v.Type:
+			l.report(frag.problem(v.Pos.Line, v.Pos.Col, fmt.Sprintf("varying %s is %s here and %s in the vertex shader", v.Name, v.Type, out.Type)))
+		}
+	}
+}
+
+// problem is a message at a line and a column of the translated source,
+// mapped back to its origin. The column is kept when the line was not
+// changed.
+func (out *Output) problem(line, col int, msg string) Problem {
+	p := Problem{Msg: msg}
+	if line <= 0 || line > len(out.Lines) {
+		return p
+	}
+	origin := out.Lines[line-1]
+	p.File, p.Line = origin.File, origin.Line
+	if src, ok := out.Sources[origin.File]; ok && origin.Line > 0 {
+		lines := strings.Split(src, "\n")
+		translated := strings.Split(out.Text, "\n")
+		if origin.Line <= len(lines) && lines[origin.Line-1] == translated[line-1] {
+			p.Col = col
+		}
+	}
+	return p
+}
@@ -0,0 +1,99 @@
+package glsl
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	lib := NewLibrary()
+	lib.Register("tint.glsl", "vec4 tint(vec4 c) {\n  return c * 2;\n}\n")
+	problems := Lint(&LintProgram{
+		Vertex: `#version 330
+ATTRIBUTE vec4 position;
+VARYINGOUT vec2 uv;
+layout(std140) uniform Camera { mat4 mvp; };
+void main() {
+  gl_Position = mvp * position;
+  uv = position.xy;
+}
+`,
+		Fragment: `#version 330
+#include "tint.glsl"
+VARYINGIN vec2 uv;
+uniform vec4 lights[2];
+COLOROUT
+void main() {
+  FRAGCOLOR = tint(lights[0] + lights[1]) * uv.x;
+}
+`,
+		Attribs:  []string{"position", "normal"},
+		Uniforms: []string{"mvp", "lights[1]", "fog"},
+		Includer: lib,
+	})
+	want := []Problem{
+		{"tint.glsl", 2, 12, "operands of '*' must have the same base type, found vec4 and int (GLSL ES 1.00)"},
+		{"", 0, 0, "attribute normal is not an input of the vertex shader"},
+		{"", 0, 0, "uniform fog is set but not declared"},
+	}
+	if !reflect.DeepEqual(problems, want) {
+		t.Errorf("got %q", problems)
+	}
+
+	// the names are not checked against the stages that do not compile
+	problems = Lint(&LintProgram{
+		Vertex:   "#version 330\nvoid main() { gl_Position = vec4(x); }\n",
+		Fragment: "#version 330\nvoid main() {}\n",
+		Attribs:  []string{"position"},
+		Uniforms: []string{"color"},
+	})
+	want = []Problem{{"vertex", 2, 34, "'x' undeclared"}}
+	if !reflect.DeepEqual(problems, want) {
+		t.Errorf("got %q", problems)
+	}
+}
+
+func TestLintGoFile(t *testing.T) {
+	problems, err := LintGoFile(filepath.Join("testdata", "button.go"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, p := range problems {
+		got = append(got, p.String())
+	}
+	file := filepath.Join("testdata", "button.go")
+	want := []string{
+		file + ":17: varying out_uvs is vec3 here and vec2 in the vertex shader",
+		file + ":18: varying fade is not an output of the vertex shader",
+		file + ":25: operands of '-' must have the same base type, found int and float (GLSL ES 1.00)",
+		file + ":37:18: attribute uvs is not an input of the vertex shader",
+		file + ":37:18: uniform color is set but not declared",
+		file + ":37:18: uniform bg is set but not declared",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q", got)
+	}
+}
+
+// TestLintGlplus lints the shaders of the package.
+func TestLintGlplus(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("..", "*.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		problems, err := LintGoFile(file, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, p := range problems {
+			t.Error(p)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package glsl
+
+import (
+	"go/ast"
+	goparser "go/parser"
+	"go/token"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// sLintCalls are the functions and methods whose arguments are a vertex
+// source, a fragment source and the attributes.
+var sLintCalls = map[string]bool{
+	"LoadShaderProgram":     true,
+	"NewShaderPermutations": true,
+}
+
+// sUniformCalls are the methods whose first argument is the name of a
+// uniform set from Go.
+var sUniformCalls = map[string]bool{
+	"ProgramUniform1f":        true,
+	"ProgramUniform2f":        true,
+	"ProgramUniform3fv":       true,
+	"ProgramUniform4fv":       true,
+	"ProgramUniform1i":        true,
+	"ProgramUniformMatrix3fv": true,
+	"ProgramUniformMatrix4fv": true,
+	"GetUniformLocation":      true,
+}
+
+// goString is a string constant or variable of a Go file.
+type goString struct {
+	value string
+	pos   token.Position
+	// raw strings keep the lines of the source
+	raw bool
+}
+
+// LintGoFile lints the shaders of a Go file, src being its content, or nil
+// to read it. The programs are the sources and attributes given to the
+// LoadShaderProgram and NewShaderPermutations calls, as string literals or
+// constants and []string literals or variables of the file. When the file
+// loads one program, the uniforms are the ones of its glsl struct tags and
+// of its ProgramUniform calls. The problems are at the lines of the file,
+// but the ones of the includes.
+func LintGoFile(filename string, src interface{}) ([]Problem, error) {
+	fset := token.NewFileSet()
+	file, err := goparser.ParseFile(fset, filename, src, 0)
+	if err != nil {
+		return nil, err
+	}
+	strs := make(map[string]*goString)
+	lists := make(map[string][]string)
+	var calls []*ast.CallExpr
+	var uniforms []string
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.ValueSpec:
+			for i, name := range n.Names {
+				if i < len(n.Values) {
+					collect(fset, name.Name, n.Values[i], strs, lists)
+				}
+			}
+		case *ast.AssignStmt:
+			if len(n.Lhs) == len(n.Rhs) {
+				for i, lhs := range n.Lhs {
+					if id, ok := lhs.(*ast.Ident); ok {
+						collect(fset, id.Name, n.Rhs[i], strs, lists)
+					}
+				}
+			}
+		case *ast.CallExpr:
+			name := callName(n)
+			if sLintCalls[name] && len(n.Args) == 3 {
+				calls = append(calls, n)
+			}
+			if sUniformCalls[name] && len(n.Args) > 0 {
+				if s, ok := stringLit(fset, n.Args[0]); ok {
+					uniforms = append(uniforms, s.value)
+				}
+			}
+		case *ast.Field:
+			if n.Tag == nil {
+				break
+			}
+			tag, _ := strconv.Unquote(n.Tag.Value)
+			name, ok := reflect.StructTag(tag).Lookup("glsl")
+			if !ok || name == "-" || strings.Contains(name, ",optional") {
+				break
+			}
+			uniforms = append(uniforms, strings.Split(name, ",")[0])
+		}
+		return true
+	})
+
+	var problems []Problem
+	for _, call := range calls {
+		vert, vok := resolveString(fset, call.Args[0], strs)
+		frag, fok := resolveString(fset, call.Args[1], strs)
+		if !vok || !fok {
+			continue
+		}
+		prog := &LintProgram{Vertex: vert.value, Fragment: frag.value, Attribs: resolveList(call.Args[2], lists)}
+		if len(calls) == 1 {
+			prog.Uniforms = uniforms
+		}
+		at := fset.Position(call.Pos())
+		for _, p := range Lint(prog) {
+			switch p.File {
+			case "vertex":
+				p = vert.problem(p)
+			case "fragment":
+				p = frag.problem(p)
+			case "":
+				p.File, p.Line, p.Col = at.Filename, at.Line, at.Column
+			}
+			problems = append(problems, p)
+		}
+	}
+	return problems, nil
+}
+
+// problem moves a problem of the source in the string to the Go file.
+func (s *goString) problem(p Problem) Problem {
+	p.File = s.pos.Filename
+	if !s.raw || p.Line == 0 {
+		p.Line, p.Col = s.pos.Line, s.pos.Column
+		return p
+	}
+	if p.Line == 1 && p.Col > 0 {
+		// after the backquote
+		p.Col += s.pos.Column
+	}
+	p.Line += s.pos.Line - 1
+	return p
+}
+
+func collect(fset *token.FileSet, name string, value ast.Expr, strs map[string]*goString, lists map[string][]string) {
+	if s, ok := stringLit(fset, value); ok {
+		strs[name] = s
+		return
+	}
+	if list, ok := stringList(value); ok {
+		lists[name] = list
+	}
+}
+
+func callName(call *ast.CallExpr) string {
+	switch fun := call.Fun.(type) {
+	case *ast.Ident:
+		return fun.Name
+	case *ast.SelectorExpr:
+		return fun.Sel.Name
+	}
+	return ""
+}
+
+func stringLit(fset *token.FileSet, x ast.Expr) (*goString, bool) {
+	lit, ok := x.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return nil, false
+	}
+	value, err := strconv.Unquote(lit.Value)
+	if err != nil {
+		return nil, false
+	}
+	return &goString{value: value, pos: fset.Position(lit.Pos()), raw: lit.Value[0] == '`'}, true
+}
+
+func resolveString(fset *token.FileSet, x ast.Expr, strs map[string]*goString) (*goString, bool) {
+	if id, ok := x.(*ast.Ident); ok {
+		s, ok := strs[id.Name]
+		return s, ok
+	}
+	return stringLit(fset, x)
+}
+
+func stringList(x ast.Expr) ([]string, bool) {
+	lit, ok := x.(*ast.CompositeLit)
+	if !ok {
+		return nil, false
+	}
+	if t, ok := lit.Type.(*ast.ArrayType); !ok || t.Len != nil || !isIdent(t.Elt, "string") {
+		return nil, false
+	}
+	var list []string
+	for _, elt := range lit.Elts {
+		b, ok := elt.(*ast.BasicLit)
+		if !ok || b.Kind != token.STRING {
+			return nil, false
+		}
+		s, err := strconv.Unquote(b.Value)
+		if err != nil {
+			return nil, false
+		}
+		list = append(list, s)
+	}
+	return list, true
+}
+
+func resolveList(x ast.Expr, lists map[string][]string) []string {
+	if id, ok := x.(*ast.Ident); ok {
+		return lists[id.Name]
+	}
+	list, _ := stringList(x)
+	return list
+}
+
+func isIdent(x ast.Expr, name string) bool {
+	id, ok := x.(*ast.Ident)
+	return ok && id.Name == name
+}
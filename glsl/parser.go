@@ -0,0 +1,595 @@
+package glsl
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var sQualifiers = map[string]Storage{
+	"const":     StorageConst,
+	"in":        StorageIn,
+	"out":       StorageOut,
+	"inout":     StorageInOut,
+	"uniform":   StorageUniform,
+	"attribute": StorageAttribute,
+	"varying":   StorageVarying,
+}
+
+var sIgnoredQualifiers = map[string]bool{
+	"invariant":     true,
+	"flat":          true,
+	"smooth":        true,
+	"noperspective": true,
+	"centroid":      true,
+}
+
+var sPrecisions = map[string]bool{
+	"lowp":    true,
+	"mediump": true,
+	"highp":   true,
+}
+
+type parser struct {
+	tokens []Token
+	pos    int
+}
+
+// bailout is used to unwind the parser on the first syntax error.
+type bailout struct {
+	err *Error
+}
+
+// Parse parses a shader source into a Unit.
+func Parse(src string) (unit *Unit, err error) {
+	var tokens []Token
+	if tokens, err = Lex(src); err != nil {
+		return nil, err
+	}
+
+	p := &parser{}
+	unit = &Unit{}
+	for _, tok := range tokens {
+		if tok.Kind == Directive {
+			unit.Directives = append(unit.Directives, tok)
+			fields := strings.Fields(strings.TrimPrefix(tok.Text, "#"))
+			if len(fields) >= 2 && fields[0] == "version" {
+				unit.Version, _ = strconv.Atoi(fields[1])
+				if len(fields) >= 3 {
+					unit.Profile = fields[2]
+				}
+			}
+			continue
+		}
+		p.tokens = append(p.tokens, tok)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			b, ok := r.(bailout)
+			if !ok {
+				panic(r)
+			}
+			unit = nil
+			err = b.err
+		}
+	}()
+
+	for p.peek().Kind != EOF {
+		unit.Decls = append(unit.Decls, p.externalDecl()...)
+	}
+	return unit, nil
+}
+
+func (p *parser) peek() Token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) peekAt(n int) Token {
+	if p.pos+n >= len(p.tokens) {
+		return p.tokens[len(p.tokens)-1]
+	}
+	return p.tokens[p.pos+n]
+}
+
+func (p *parser) next() Token {
+	tok := p.tokens[p.pos]
+	if tok.Kind != EOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) is(text string) bool {
+	tok := p.peek()
+	return (tok.Kind == Punct || tok.Kind == Ident) && tok.Text == text
+}
+
+func (p *parser) accept(text string) bool {
+	if p.is(text) {
+		p.next()
+		return true
+	}
+	return false
+}
+
+func (p *parser) errorf(tok Token, format string, args ...interface{}) {
+	panic(bailout{&Error{Line: tok.Line, Col: tok.Col, Msg: fmt.Sprintf(format, args...)}})
+}
+
+func (p *parser) expect(text string) Token {
+	tok := p.peek()
+	if !p.accept(text) {
+		p.errorf(tok, "syntax error, unexpected %s, expecting '%s'", tok, text)
+	}
+	return tok
+}
+
+func (p *parser) ident() Token {
+	tok := p.next()
+	if tok.Kind != Ident {
+		p.errorf(tok, "syntax error, unexpected %s, expecting identifier", tok)
+	}
+	return tok
+}
+
+func posOf(tok Token) Pos {
+	return Pos{Line: tok.Line, Col: tok.Col}
+}
+
+func isTypeName(tok Token) bool {
+	if tok.Kind != Ident {
+		return false
+	}
+	_, ok := sKindByName[tok.Text]
+	return ok
+}
+
+// qualifiers parses storage, layout, precision and interpolation qualifiers.
+func (p *parser) qualifiers() (storage Storage, layout map[string]int, precision string) {
+	for {
+		tok := p.peek()
+		if tok.Kind != Ident {
+			return
+		}
+		if s, ok := sQualifiers[tok.Text]; ok {
+			p.next()
+			if storage == StorageConst && s == StorageIn {
+				// "const in" parameter
+				continue
+			}
+			storage = s
+		} else if sIgnoredQualifiers[tok.Text] {
+			p.next()
+		} else if sPrecisions[tok.Text] {
+			p.next()
+			precision = tok.Text
+		} else if tok.Text == "layout" {
+			p.next()
+			p.expect("(")
+			if layout == nil {
+				layout = make(map[string]int)
+			}
+			for !p.accept(")") {
+				name := p.ident()
+				value := -1
+				if p.accept("=") {
+					value = p.intConst()
+				}
+				layout[name.Text] = value
+				if !p.is(")") {
+					p.expect(",")
+				}
+			}
+		} else {
+			return
+		}
+	}
+}
+
+func (p *parser) intConst() int {
+	tok := p.next()
+	if tok.Kind != IntLit {
+		p.errorf(tok, "syntax error, unexpected %s, expecting integer constant", tok)
+	}
+	v, err := strconv.ParseInt(strings.TrimRight(tok.Text, "uU"), 0, 64)
+	if err != nil {
+		p.errorf(tok, "invalid integer constant %s", tok)
+	}
+	return int(v)
+}
+
+func (p *parser) typeSpec() Type {
+	tok := p.next()
+	if tok.Kind != Ident {
+		p.errorf(tok, "syntax error, unexpected %s, expecting type", tok)
+	}
+	kind, ok := sKindByName[tok.Text]
+	if !ok {
+		p.errorf(tok, "unknown type '%s'", tok.Text)
+	}
+	t := Type{Kind: kind}
+	if p.accept("[") {
+		t.Array = p.intConst()
+		p.expect("]")
+	}
+	return t
+}
+
+func (p *parser) arraySuffix(t Type) Type {
+	if p.accept("[") {
+		if t.Array > 0 {
+			p.errorf(p.peek(), "arrays of arrays are not supported")
+		}
+		t.Array = p.intConst()
+		p.expect("]")
+	}
+	return t
+}
+
+func (p *parser) externalDecl() []Node {
+	start := p.peek()
+	if start.Kind == Ident && start.Text == "precision" {
+		p.next()
+		prec := p.next()
+		if !sPrecisions[prec.Text] {
+			p.errorf(prec, "syntax error, unexpected %s, expecting precision qualifier", prec)
+		}
+		t := p.typeSpec()
+		p.expect(";")
+		return []Node{&PrecisionDecl{Pos: posOf(start), Precision: prec.Text, Type: t}}
+	}
+
+	storage, layout, precision := p.qualifiers()
+	if p.accept(";") {
+		// layout(...) in; default qualifiers
+		return nil
+	}
+	t := p.typeSpec()
+	name := p.ident()
+
+	if p.is("(") {
+		return []Node{p.funcDecl(t, name)}
+	}
+
+	vars := p.varDecls(storage, layout, precision, t, name)
+	nodes := make([]Node, len(vars))
+	for i, v := range vars {
+		nodes[i] = v
+	}
+	return nodes
+}
+
+func (p *parser) varDecls(storage Storage, layout map[string]int, precision string, t Type, name Token) (vars []*VarDecl) {
+	for {
+		v := &VarDecl{
+			Pos:       posOf(name),
+			Storage:   storage,
+			Layout:    layout,
+			Precision: precision,
+			Type:      p.arraySuffix(t),
+			Name:      name.Text,
+		}
+		if p.accept("=") {
+			v.Init = p.assignExpr()
+		}
+		vars = append(vars, v)
+		if !p.accept(",") {
+			break
+		}
+		name = p.ident()
+	}
+	p.expect(";")
+	return vars
+}
+
+func (p *parser) funcDecl(ret Type, name Token) *FuncDecl {
+	fn := &FuncDecl{Pos: posOf(name), Ret: ret, Name: name.Text}
+	p.expect("(")
+	if p.is("void") && p.peekAt(1).Text == ")" {
+		p.next()
+	}
+	for !p.accept(")") {
+		ptok := p.peek()
+		storage, _, _ := p.qualifiers()
+		if storage == StorageNone || storage == StorageConst {
+			storage = StorageIn
+		}
+		param := &Param{Pos: posOf(ptok), Storage: storage, Type: p.typeSpec()}
+		if p.peek().Kind == Ident {
+			param.Name = p.ident().Text
+			param.Type = p.arraySuffix(param.Type)
+		}
+		fn.Params = append(fn.Params, param)
+		if !p.is(")") {
+			p.expect(",")
+		}
+	}
+	if p.accept(";") {
+		return fn
+	}
+	fn.Body = p.block()
+	return fn
+}
+
+func (p *parser) block() *BlockStmt {
+	start := p.expect("{")
+	b := &BlockStmt{Pos: posOf(start)}
+	for !p.accept("}") {
+		if p.peek().Kind == EOF {
+			p.errorf(p.peek(), "syntax error, unexpected end of file, expecting '}'")
+		}
+		b.List = append(b.List, p.stmt())
+	}
+	return b
+}
+
+func (p *parser) isDeclStart() bool {
+	tok := p.peek()
+	if tok.Kind != Ident {
+		return false
+	}
+	if _, ok := sQualifiers[tok.Text]; ok {
+		return true
+	}
+	if sPrecisions[tok.Text] || sIgnoredQualifiers[tok.Text] || tok.Text == "layout" {
+		return true
+	}
+	if isTypeName(tok) {
+		// vec3(...) is a constructor call
+		next := p.peekAt(1)
+		if next.Kind == Punct && next.Text == "(" {
+			return false
+		}
+		return true
+	}
+	return false
+}
+
+func (p *parser) stmt() Stmt {
+	tok := p.peek()
+	pos := posOf(tok)
+
+	if tok.Kind == Punct {
+		switch tok.Text {
+		case "{":
+			return p.block()
+		case ";":
+			p.next()
+			return &BlockStmt{Pos: pos}
+		}
+	}
+	if tok.Kind == Ident {
+		switch tok.Text {
+		case "if":
+			p.next()
+			p.expect("(")
+			s := &IfStmt{Pos: pos, Cond: p.expr()}
+			p.expect(")")
+			s.Then = p.stmt()
+			if p.accept("else") {
+				s.Else = p.stmt()
+			}
+			return s
+		case "for":
+			p.next()
+			p.expect("(")
+			s := &ForStmt{Pos: pos}
+			if !p.accept(";") {
+				s.Init = p.simpleStmt()
+			}
+			if !p.is(";") {
+				s.Cond = p.expr()
+			}
+			p.expect(";")
+			if !p.is(")") {
+				s.Post = p.expr()
+			}
+			p.expect(")")
+			s.Body = p.stmt()
+			return s
+		case "while":
+			p.next()
+			p.expect("(")
+			s := &WhileStmt{Pos: pos, Cond: p.expr()}
+			p.expect(")")
+			s.Body = p.stmt()
+			return s
+		case "do":
+			p.next()
+			s := &WhileStmt{Pos: pos, Do: true}
+			s.Body = p.stmt()
+			p.expect("while")
+			p.expect("(")
+			s.Cond = p.expr()
+			p.expect(")")
+			p.expect(";")
+			return s
+		case "return":
+			p.next()
+			s := &ReturnStmt{Pos: pos}
+			if !p.is(";") {
+				s.X = p.expr()
+			}
+			p.expect(";")
+			return s
+		case "break", "continue", "discard":
+			p.next()
+			p.expect(";")
+			return &BranchStmt{Pos: pos, Tok: tok.Text}
+		}
+	}
+	return p.simpleStmt()
+}
+
+// simpleStmt is a declaration or an expression statement, including the ';'.
+func (p *parser) simpleStmt() Stmt {
+	tok := p.peek()
+	pos := posOf(tok)
+	if p.isDeclStart() {
+		storage, layout, precision := p.qualifiers()
+		t := p.typeSpec()
+		name := p.ident()
+		return &DeclStmt{Pos: pos, Vars: p.varDecls(storage, layout, precision, t, name)}
+	}
+	s := &ExprStmt{Pos: pos, X: p.expr()}
+	p.expect(";")
+	return s
+}
+
+func (p *parser) expr() Expr {
+	x := p.assignExpr()
+	if p.is(",") {
+		seq := &SeqExpr{Pos: x.Position(), List: []Expr{x}}
+		for p.accept(",") {
+			seq.List = append(seq.List, p.assignExpr())
+		}
+		return seq
+	}
+	return x
+}
+
+var sAssignOps = map[string]bool{
+	"=": true, "+=": true, "-=": true, "*=": true, "/=": true, "%=": true,
+	"<<=": true, ">>=": true, "&=": true, "|=": true, "^=": true,
+}
+
+func (p *parser) assignExpr() Expr {
+	lhs := p.condExpr()
+	tok := p.peek()
+	if tok.Kind == Punct && sAssignOps[tok.Text] {
+		p.next()
+		return &AssignExpr{Pos: posOf(tok), Op: tok.Text, LHS: lhs, RHS: p.assignExpr()}
+	}
+	return lhs
+}
+
+func (p *parser) condExpr() Expr {
+	cond := p.binaryExpr(0)
+	if tok := p.peek(); p.accept("?") {
+		then := p.expr()
+		p.expect(":")
+		return &CondExpr{Pos: posOf(tok), Cond: cond, Then: then, Else: p.assignExpr()}
+	}
+	return cond
+}
+
+// binary operators from lowest to highest precedence
+var sBinaryPrecedence = [][]string{
+	{"||"},
+	{"^^"},
+	{"&&"},
+	{"|"},
+	{"^"},
+	{"&"},
+	{"==", "!="},
+	{"<", ">", "<=", ">="},
+	{"<<", ">>"},
+	{"+", "-"},
+	{"*", "/", "%"},
+}
+
+func (p *parser) binaryExpr(level int) Expr {
+	if level == len(sBinaryPrecedence) {
+		return p.unaryExpr()
+	}
+	x := p.binaryExpr(level + 1)
+	for {
+		tok := p.peek()
+		if tok.Kind != Punct || !contains(sBinaryPrecedence[level], tok.Text) {
+			return x
+		}
+		p.next()
+		x = &BinaryExpr{Pos: posOf(tok), Op: tok.Text, X: x, Y: p.binaryExpr(level + 1)}
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *parser) unaryExpr() Expr {
+	tok := p.peek()
+	if tok.Kind == Punct {
+		switch tok.Text {
+		case "++", "--", "+", "-", "!", "~":
+			p.next()
+			return &UnaryExpr{Pos: posOf(tok), Op: tok.Text, X: p.unaryExpr()}
+		}
+	}
+	return p.postfixExpr()
+}
+
+func (p *parser) postfixExpr() Expr {
+	x := p.primaryExpr()
+	for {
+		tok := p.peek()
+		switch {
+		case p.accept("["):
+			x = &IndexExpr{Pos: posOf(tok), X: x, Index: p.expr()}
+			p.expect("]")
+		case p.accept("."):
+			name := p.ident()
+			x = &FieldExpr{Pos: posOf(name), X: x, Name: name.Text}
+		case p.is("++") || p.is("--"):
+			p.next()
+			x = &UnaryExpr{Pos: posOf(tok), Op: tok.Text, X: x, Postfix: true}
+		default:
+			return x
+		}
+	}
+}
+
+func (p *parser) primaryExpr() Expr {
+	tok := p.next()
+	pos := posOf(tok)
+	switch tok.Kind {
+	case IntLit:
+		v, err := strconv.ParseUint(strings.TrimRight(tok.Text, "uU"), 0, 32)
+		if err != nil {
+			p.errorf(tok, "invalid integer constant %s", tok)
+		}
+		return &Literal{Pos: pos, Kind: Int, Value: float64(v)}
+	case FloatLit:
+		v, err := strconv.ParseFloat(strings.TrimRight(tok.Text, "fF"), 64)
+		if err != nil {
+			p.errorf(tok, "invalid float constant %s", tok)
+		}
+		return &Literal{Pos: pos, Kind: Float, Value: v}
+	case Ident:
+		switch tok.Text {
+		case "true":
+			return &Literal{Pos: pos, Kind: Bool, Value: 1}
+		case "false":
+			return &Literal{Pos: pos, Kind: Bool, Value: 0}
+		}
+		if p.is("(") {
+			p.next()
+			call := &CallExpr{Pos: pos, Func: tok.Text}
+			if p.is("void") && p.peekAt(1).Text == ")" {
+				p.next()
+			}
+			for !p.accept(")") {
+				call.Args = append(call.Args, p.assignExpr())
+				if !p.is(")") {
+					p.expect(",")
+				}
+			}
+			return call
+		}
+		return &VarRef{Pos: pos, Name: tok.Text}
+	case Punct:
+		if tok.Text == "(" {
+			x := p.expr()
+			p.expect(")")
+			return x
+		}
+	}
+	p.errorf(tok, "syntax error, unexpected %s", tok)
+	return nil
+}
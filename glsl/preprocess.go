@@ -0,0 +1,921 @@
+package glsl
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Dialect is a GLSL version the Preprocessor writes.
+type Dialect int
+
+// Dialects
+const (
+	// GLSL330 is GLSL 3.30 core, for OpenGL 3.3 and later.
+	GLSL330 Dialect = iota
+	// GLSLES100 is GLSL ES 1.00, for OpenGL ES 2 and WebGL 1.
+	GLSLES100
+	// GLSLES300 is GLSL ES 3.00, for OpenGL ES 3 and WebGL 2.
+	GLSLES300
+	// GLSL400 is GLSL 4.00 core, for the tessellation shaders of OpenGL 4.
+	GLSL400
+	// GLSL430 is GLSL 4.30 core, for the compute shaders of OpenGL 4.3.
+	GLSL430
+)
+
+func (d Dialect) String() string {
+	switch d {
+	case GLSLES100:
+		return "GLSL ES 1.00"
+	case GLSLES300:
+		return "GLSL ES 3.00"
+	case GLSL400:
+		return "GLSL 4.00"
+	case GLSL430:
+		return "GLSL 4.30"
+	}
+	return "GLSL 3.30"
+}
+
+// Version is the number of the #version directive: 330, 100, 300, 400 or
+// 430.
+func (d Dialect) Version() int {
+	switch d {
+	case GLSLES100:
+		return 100
+	case GLSLES300:
+		return 300
+	case GLSL400:
+		return 400
+	case GLSL430:
+		return 430
+	}
+	return 330
+}
+
+// ES ...
+func (d Dialect) ES() bool {
+	return d == GLSLES100 || d == GLSLES300
+}
+
+func (d Dialect) versionDirective() string {
+	switch d {
+	case GLSLES100:
+		return "#version 100"
+	case GLSLES300:
+		return "#version 300 es"
+	}
+	return "#version " + strconv.Itoa(d.Version()) + " core"
+}
+
+// macros are the glplus words and the macros a driver of the dialect
+// predefines.
+func (d Dialect) macros() map[string]string {
+	m := map[string]string{
+		"__VERSION__": strconv.Itoa(d.Version()),
+		"ATTRIBUTE":   "in",
+		"VARYINGIN":   "in",
+		"VARYINGOUT":  "out",
+		"COLOROUT":    "out vec4 colourOut;",
+		"FRAGCOLOR":   "colourOut",
+		"TEXTURE2D":   "texture",
+	}
+	if d == GLSLES100 {
+		m["ATTRIBUTE"] = "attribute"
+		m["VARYINGIN"] = "varying"
+		m["VARYINGOUT"] = "varying"
+		m["COLOROUT"] = ""
+		m["FRAGCOLOR"] = "gl_FragColor"
+		m["TEXTURE2D"] = "texture2D"
+	}
+	if d.ES() {
+		m["GL_ES"] = "1"
+	}
+	return m
+}
+
+// Includer finds the source of an #include.
+type Includer interface {
+	Include(name string) (src string, err error)
+}
+
+// Library is an Includer of registered sources, safe for concurrent use.
+type Library struct {
+	mu      sync.RWMutex
+	sources map[string]string
+}
+
+// NewLibrary ...
+func NewLibrary() *Library {
+	return &Library{sources: make(map[string]string)}
+}
+
+// Register makes src the source of #include "name".
+func (l *Library) Register(name, src string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sources[name] = src
+}
+
+// Include ...
+func (l *Library) Include(name string) (string, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	src, ok := l.sources[name]
+	if !ok {
+		return "", fmt.Errorf("no source registered as %q", name)
+	}
+	return src, nil
+}
+
+// Preprocessor expands the #include, #define, #undef, #if, #ifdef, #ifndef,
+// #elif, #else and #endif directives of a glplus shader and translates it to
+// a Dialect. The glplus words ATTRIBUTE, VARYINGIN, VARYINGOUT, COLOROUT,
+// FRAGCOLOR and TEXTURE2D are predefined macros, as are __VERSION__ and, for
+// the ES dialects, GL_ES. The #version of the source is replaced by the one
+// of the dialect; #extension, #pragma and #line are kept.
+//
+// The lines of the output match the lines of the source, the ones of the
+// included files aside. A function-like macro call must fit on one line.
+type Preprocessor struct {
+	Dialect Dialect
+	// Includer finds the included sources; #include fails when it is nil.
+	Includer Includer
+	// Defines are defined before the source, NAME or NAME(a, b) to the
+	// replacement.
+	Defines map[string]string
+	// FlattenUniformBlocks declares the members of the uniform blocks as
+	// plain uniforms, for the contexts without uniform buffers; GLSLES100
+	// implies it. The blocks cannot have an instance name then, and their
+	// head, up to the {, must fit on one line.
+	FlattenUniformBlocks bool
+}
+
+// Origin is the file and the line an output line comes from. Line is 0 for
+// a line the Preprocessor adds.
+type Origin struct {
+	File string
+	Line int
+}
+
+// Output is a preprocessed shader.
+type Output struct {
+	Text string
+	// Lines has the Origin of every line of Text.
+	Lines []Origin
+	// Sources are the files read, the ones included too, by name.
+	Sources map[string]string
+}
+
+// Preprocess translates the source of the file name.
+func (pp *Preprocessor) Preprocess(name, src string) (*Output, error) {
+	p := &preprocessor{
+		Preprocessor: pp,
+		macros:       make(map[string]*macro),
+		sources:      make(map[string]string),
+		precision:    pp.Dialect.ES(),
+		flatten:      pp.FlattenUniformBlocks || pp.Dialect == GLSLES100,
+	}
+	for k, v := range pp.Dialect.macros() {
+		p.macros[k] = &macro{body: scanText(v)}
+	}
+	for k, v := range pp.Defines {
+		toks, _ := scanLine(k+" "+v, false)
+		if err := p.define(toks, Origin{File: "<defines>"}); err != nil {
+			return nil, err
+		}
+	}
+
+	// the #version of the dialect takes the place of the one of the source
+	// when it is the first line
+	if strings.HasPrefix(strings.TrimLeft(src, " \t"), "#") {
+		first := strings.TrimLeft(strings.TrimLeft(src, " \t")[1:], " \t")
+		p.versionFirst = strings.HasPrefix(first, "version")
+	}
+	if !p.versionFirst {
+		p.emit(pp.Dialect.versionDirective(), Origin{File: name})
+	}
+	if err := p.file(name, src); err != nil {
+		return nil, err
+	}
+	if p.block != blockOutside {
+		return nil, errorAt(Origin{File: name, Line: strings.Count(strings.TrimSuffix(src, "\n"), "\n") + 1}, 1, "unterminated uniform block %s", p.blockName)
+	}
+	return &Output{Text: p.out.String(), Lines: p.lines, Sources: p.sources}, nil
+}
+
+type macro struct {
+	// params is nil for an object-like macro
+	params []string
+	body   []ppToken
+}
+
+type ppKind int
+
+const (
+	ppSpace ppKind = iota
+	ppComment
+	ppIdent
+	ppNumber
+	ppPunct
+)
+
+type ppToken struct {
+	kind ppKind
+	text string
+	col  int
+}
+
+type cond struct {
+	active bool
+	// taken is set once a branch of the #if was active
+	taken   bool
+	sawElse bool
+}
+
+type preprocessor struct {
+	*Preprocessor
+	macros  map[string]*macro
+	out     strings.Builder
+	lines   []Origin
+	sources map[string]string
+
+	// the files being included, outermost first
+	files        []string
+	versionFirst bool
+	// precision is set until the default precision statement of an ES
+	// dialect is written, in front of the first code token
+	precision bool
+
+	// the uniform block being flattened
+	flatten   bool
+	block     blockState
+	blockName string
+}
+
+func (p *preprocessor) emit(text string, origin Origin) {
+	p.out.WriteString(text)
+	p.out.WriteByte('\n')
+	p.lines = append(p.lines, origin)
+}
+
+func errorAt(origin Origin, col int, format string, args ...interface{}) *Error {
+	return &Error{File: origin.File, Line: origin.Line, Col: col, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *preprocessor) file(name, src string) error {
+	p.files = append(p.files, name)
+	p.sources[name] = src
+	defer func() { p.files = p.files[:len(p.files)-1] }()
+
+	lines := strings.Split(src, "\n")
+	if len(lines) > 1 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	var conds []*cond
+	active := func() bool {
+		return len(conds) == 0 || conds[len(conds)-1].active
+	}
+	inComment := false
+	for n := 0; n < len(lines); n++ {
+		origin := Origin{File: name, Line: n + 1}
+		line := strings.TrimSuffix(lines[n], "\r")
+
+		trimmed := strings.TrimLeft(line, " \t")
+		if inComment || !strings.HasPrefix(trimmed, "#") {
+			var toks []ppToken
+			toks, inComment = scanLine(line, inComment)
+			if !active() {
+				p.emit("", origin)
+				continue
+			}
+			expanded, err := p.expand(toks, nil, origin)
+			if err == nil && p.flatten {
+				expanded, err = p.flattenBlocks(expanded, origin)
+			}
+			if err != nil {
+				return err
+			}
+			p.emitCode(expanded, origin)
+			continue
+		}
+
+		// a directive, with its continuation lines
+		continued := 0
+		for strings.HasSuffix(line, "\\") && n+1 < len(lines) {
+			n++
+			continued++
+			line = line[:len(line)-1] + " " + strings.TrimSuffix(lines[n], "\r")
+		}
+		hash := strings.IndexByte(line, '#')
+		var toks []ppToken
+		toks, inComment = scanLine(line[hash+1:], false)
+		for i := range toks {
+			toks[i].col += hash + 1
+			if toks[i].kind == ppComment {
+				toks[i].kind, toks[i].text = ppSpace, " "
+			}
+		}
+		toks = trimSpace(toks)
+		keyword := ""
+		if len(toks) > 0 && toks[0].kind == ppIdent {
+			keyword = toks[0].text
+			toks = trimSpace(toks[1:])
+		}
+
+		var err error
+		switch keyword {
+		case "if", "ifdef", "ifndef":
+			c := &cond{}
+			if active() {
+				c.active, err = p.condition(keyword, toks, origin, hash+1)
+				c.taken = c.active
+			} else {
+				// a nested #if of an inactive group is never taken
+				c.taken = true
+			}
+			conds = append(conds, c)
+			p.emit("", origin)
+		case "elif", "else":
+			if len(conds) == 0 {
+				return errorAt(origin, hash+1, "#%s without #if", keyword)
+			}
+			c := conds[len(conds)-1]
+			if c.sawElse {
+				return errorAt(origin, hash+1, "#%s after #else", keyword)
+			}
+			c.sawElse = keyword == "else"
+			c.active = false
+			if !c.taken {
+				if keyword == "else" {
+					c.active = true
+				} else {
+					c.active, err = p.condition("if", toks, origin, hash+1)
+				}
+				c.taken = c.active
+			}
+			p.emit("", origin)
+		case "endif":
+			if len(conds) == 0 {
+				return errorAt(origin, hash+1, "#endif without #if")
+			}
+			conds = conds[:len(conds)-1]
+			p.emit("", origin)
+		default:
+			if !active() {
+				p.emit("", origin)
+				break
+			}
+			err = p.directive(keyword, toks, line, origin, hash+1)
+		}
+		if err != nil {
+			return err
+		}
+		for i := 0; i < continued; i++ {
+			p.emit("", Origin{File: name, Line: origin.Line + 1 + i})
+		}
+	}
+	if len(conds) > 0 {
+		return errorAt(Origin{File: name, Line: len(lines)}, 1, "unterminated #if")
+	}
+	return nil
+}
+
+// directive runs a directive of an active group.
+func (p *preprocessor) directive(keyword string, toks []ppToken, line string, origin Origin, col int) error {
+	switch keyword {
+	case "":
+		if len(toks) > 0 {
+			return errorAt(origin, col, "invalid directive %s", toks[0].text)
+		}
+		p.emit("", origin)
+	case "version":
+		if p.versionFirst && len(p.files) == 1 && origin.Line == 1 {
+			p.emit(p.Dialect.versionDirective(), origin)
+		} else {
+			p.emit("", origin)
+		}
+	case "define":
+		p.emit("", origin)
+		return p.define(toks, origin)
+	case "undef":
+		if len(toks) == 0 || toks[0].kind != ppIdent {
+			return errorAt(origin, col, "#undef needs a macro name")
+		}
+		delete(p.macros, toks[0].text)
+		p.emit("", origin)
+	case "include":
+		return p.include(toks, origin, col)
+	case "error":
+		return errorAt(origin, col, "#error %s", joinTokens(toks))
+	case "extension", "pragma", "line":
+		p.emit(line, origin)
+	default:
+		return errorAt(origin, col, "invalid directive #%s", keyword)
+	}
+	return nil
+}
+
+func (p *preprocessor) define(toks []ppToken, origin Origin) error {
+	if len(toks) == 0 || toks[0].kind != ppIdent {
+		return errorAt(origin, 1, "#define needs a macro name")
+	}
+	name := toks[0]
+	if name.text == "defined" {
+		return errorAt(origin, name.col, "'defined' cannot be a macro name")
+	}
+	m := &macro{}
+	rest := toks[1:]
+	// a function-like macro has its '(' right after the name
+	if len(rest) > 0 && rest[0].text == "(" && rest[0].col == name.col+len(name.text) {
+		m.params = []string{}
+		i := 1
+		for {
+			i = skipSpace(rest, i)
+			if i < len(rest) && rest[i].text == ")" && len(m.params) == 0 {
+				break
+			}
+			if i >= len(rest) || rest[i].kind != ppIdent {
+				return errorAt(origin, name.col, "invalid parameters of macro %s", name.text)
+			}
+			m.params = append(m.params, rest[i].text)
+			i = skipSpace(rest, i+1)
+			if i < len(rest) && rest[i].text == ")" {
+				break
+			}
+			if i >= len(rest) || rest[i].text != "," {
+				return errorAt(origin, name.col, "invalid parameters of macro %s", name.text)
+			}
+			i++
+		}
+		rest = rest[i+1:]
+	}
+	m.body = trimSpace(rest)
+	p.macros[name.text] = m
+	return nil
+}
+
+func (p *preprocessor) include(toks []ppToken, origin Origin, col int) error {
+	text := joinTokens(toks)
+	var name string
+	if len(text) >= 2 && ((text[0] == '"' && strings.IndexByte(text[1:], '"') > 0) ||
+		(text[0] == '<' && strings.IndexByte(text[1:], '>') > 0)) {
+		closing := byte('"')
+		if text[0] == '<' {
+			closing = '>'
+		}
+		end := strings.IndexByte(text[1:], closing) + 1
+		name = text[1:end]
+		if strings.TrimSpace(text[end+1:]) != "" {
+			name = ""
+		}
+	}
+	if name == "" {
+		return errorAt(origin, col, "#include expects \"name\" or <name>")
+	}
+	for _, f := range p.files {
+		if f == name {
+			return errorAt(origin, col, "#include cycle: %s -> %s", strings.Join(p.files, " -> "), name)
+		}
+	}
+	if p.Includer == nil {
+		return errorAt(origin, col, "#include %q: no Includer", name)
+	}
+	src, err := p.Includer.Include(name)
+	if err != nil {
+		return errorAt(origin, col, "#include %q: %v", name, err)
+	}
+	return p.file(name, src)
+}
+
+// emitCode writes a line of code, after the default precision statement
+// when it is the first one.
+func (p *preprocessor) emitCode(toks []ppToken, origin Origin) {
+	var b strings.Builder
+	for _, tok := range toks {
+		if p.precision && tok.kind != ppSpace && tok.kind != ppComment {
+			b.WriteString("precision highp float; ")
+			p.precision = false
+		}
+		b.WriteString(tok.text)
+	}
+	p.emit(b.String(), origin)
+}
+
+// expand replaces the macros of toks, but the hidden ones being expanded.
+func (p *preprocessor) expand(toks []ppToken, hide map[string]bool, origin Origin) ([]ppToken, error) {
+	var out []ppToken
+	glue := false
+	for i := 0; i < len(toks); i++ {
+		tok := toks[i]
+		m := p.macros[tok.text]
+		if tok.kind != ppIdent || m == nil || hide[tok.text] {
+			out = appendToken(out, tok, glue)
+			glue = false
+			continue
+		}
+		body := m.body
+		if m.params != nil {
+			open := skipSpace(toks, i+1)
+			if open >= len(toks) || toks[open].text != "(" {
+				out = appendToken(out, tok, glue)
+				glue = false
+				continue
+			}
+			args, end := splitArgs(toks, open)
+			if end < 0 {
+				return nil, errorAt(origin, tok.col, "unterminated argument list invoking macro %s", tok.text)
+			}
+			if len(m.params) == 0 && len(args) == 1 && len(args[0]) == 0 {
+				args = nil
+			}
+			if len(args) != len(m.params) {
+				return nil, errorAt(origin, tok.col, "macro %s takes %d arguments, but %d given", tok.text, len(m.params), len(args))
+			}
+			for k, arg := range args {
+				var err error
+				if args[k], err = p.expand(arg, hide, origin); err != nil {
+					return nil, err
+				}
+			}
+			body = substitute(m, args)
+			i = end
+		}
+
+		inner := map[string]bool{tok.text: true}
+		for k := range hide {
+			inner[k] = true
+		}
+		expanded, err := p.expand(body, inner, origin)
+		if err != nil {
+			return nil, err
+		}
+		for k, e := range expanded {
+			e.col = tok.col
+			out = appendToken(out, e, k == 0)
+		}
+		glue = true
+	}
+	return out, nil
+}
+
+func substitute(m *macro, args [][]ppToken) (body []ppToken) {
+	for _, tok := range m.body {
+		k := -1
+		if tok.kind == ppIdent {
+			for j, param := range m.params {
+				if param == tok.text {
+					k = j
+				}
+			}
+		}
+		if k < 0 {
+			body = append(body, tok)
+		} else {
+			body = append(body, args[k]...)
+		}
+	}
+	return body
+}
+
+// splitArgs splits the arguments of a call at toks[open], which is '(', and
+// returns the index of the ')', or -1.
+func splitArgs(toks []ppToken, open int) (args [][]ppToken, end int) {
+	depth := 0
+	start := open + 1
+	for i := open; i < len(toks); i++ {
+		switch toks[i].text {
+		case "(":
+			depth++
+		case ")":
+			depth--
+			if depth == 0 {
+				return append(args, trimSpace(toks[start:i])), i
+			}
+		case ",":
+			if depth == 1 {
+				args = append(args, trimSpace(toks[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return nil, -1
+}
+
+// appendToken separates with a space the tokens of an expansion that would
+// otherwise join with their neighbour.
+func appendToken(out []ppToken, tok ppToken, boundary bool) []ppToken {
+	if boundary && len(out) > 0 && glues(out[len(out)-1], tok) {
+		out = append(out, ppToken{kind: ppSpace, text: " ", col: tok.col})
+	}
+	return append(out, tok)
+}
+
+func glues(a, b ppToken) bool {
+	word := func(t ppToken) bool { return t.kind == ppIdent || t.kind == ppNumber }
+	if word(a) && word(b) {
+		return true
+	}
+	if a.kind == ppPunct && b.kind == ppPunct {
+		joined := a.text + b.text
+		for _, punct := range sPunctuators {
+			if len(punct) > len(a.text) && strings.HasPrefix(joined, punct) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// condition evaluates an #if, #ifdef or #ifndef.
+func (p *preprocessor) condition(keyword string, toks []ppToken, origin Origin, col int) (bool, error) {
+	if keyword != "if" {
+		if len(toks) == 0 || toks[0].kind != ppIdent {
+			return false, errorAt(origin, col, "#%s needs a macro name", keyword)
+		}
+		_, defined := p.macros[toks[0].text]
+		return defined == (keyword == "ifdef"), nil
+	}
+
+	// defined X and defined(X) are resolved before the expansion
+	var resolved []ppToken
+	for i := 0; i < len(toks); i++ {
+		if toks[i].text != "defined" {
+			resolved = append(resolved, toks[i])
+			continue
+		}
+		j := skipSpace(toks, i+1)
+		paren := j < len(toks) && toks[j].text == "("
+		if paren {
+			j = skipSpace(toks, j+1)
+		}
+		if j >= len(toks) || toks[j].kind != ppIdent {
+			return false, errorAt(origin, toks[i].col, "'defined' needs a macro name")
+		}
+		value := "0"
+		if _, ok := p.macros[toks[j].text]; ok {
+			value = "1"
+		}
+		if paren {
+			j = skipSpace(toks, j+1)
+			if j >= len(toks) || toks[j].text != ")" {
+				return false, errorAt(origin, toks[i].col, "missing ')' after 'defined'")
+			}
+		}
+		resolved = append(resolved, ppToken{kind: ppNumber, text: value, col: toks[i].col})
+		i = j
+	}
+	expanded, err := p.expand(resolved, nil, origin)
+	if err != nil {
+		return false, err
+	}
+
+	e := &ppExpr{origin: origin, col: col}
+	for _, tok := range expanded {
+		if tok.kind != ppSpace && tok.kind != ppComment {
+			e.toks = append(e.toks, tok)
+		}
+	}
+	if len(e.toks) == 0 {
+		return false, errorAt(origin, col, "#if with no expression")
+	}
+	v, err := e.binary(1)
+	if err == nil && e.pos < len(e.toks) {
+		err = e.errorf("unexpected %s in #if", e.toks[e.pos].text)
+	}
+	return v != 0, err
+}
+
+// ppExpr evaluates the integer expression of an #if.
+type ppExpr struct {
+	toks   []ppToken
+	pos    int
+	origin Origin
+	col    int
+}
+
+var sPrecedence = map[string]int{
+	"||": 1, "&&": 2, "|": 3, "^": 4, "&": 5,
+	"==": 6, "!=": 6, "<": 7, ">": 7, "<=": 7, ">=": 7,
+	"<<": 8, ">>": 8, "+": 9, "-": 9, "*": 10, "/": 10, "%": 10,
+}
+
+func (e *ppExpr) errorf(format string, args ...interface{}) error {
+	col := e.col
+	if e.pos < len(e.toks) {
+		col = e.toks[e.pos].col
+	}
+	return errorAt(e.origin, col, format, args...)
+}
+
+func (e *ppExpr) binary(minPrec int) (int64, error) {
+	x, err := e.unary()
+	if err != nil {
+		return 0, err
+	}
+	for e.pos < len(e.toks) {
+		op := e.toks[e.pos].text
+		prec, ok := sPrecedence[op]
+		if !ok || prec < minPrec || e.toks[e.pos].kind != ppPunct {
+			break
+		}
+		e.pos++
+		y, err := e.binary(prec + 1)
+		if err != nil {
+			return 0, err
+		}
+		if x, err = e.apply(op, x, y); err != nil {
+			return 0, err
+		}
+	}
+	return x, nil
+}
+
+func (e *ppExpr) apply(op string, x, y int64) (int64, error) {
+	b := func(v bool) int64 {
+		if v {
+			return 1
+		}
+		return 0
+	}
+	switch op {
+	case "||":
+		return b(x != 0 || y != 0), nil
+	case "&&":
+		return b(x != 0 && y != 0), nil
+	case "|":
+		return x | y, nil
+	case "^":
+		return x ^ y, nil
+	case "&":
+		return x & y, nil
+	case "==":
+		return b(x == y), nil
+	case "!=":
+		return b(x != y), nil
+	case "<":
+		return b(x < y), nil
+	case ">":
+		return b(x > y), nil
+	case "<=":
+		return b(x <= y), nil
+	case ">=":
+		return b(x >= y), nil
+	case "<<":
+		return x << uint(y), nil
+	case ">>":
+		return x >> uint(y), nil
+	case "+":
+		return x + y, nil
+	case "-":
+		return x - y, nil
+	case "*":
+		return x * y, nil
+	}
+	if y == 0 {
+		return 0, errorAt(e.origin, e.col, "division by zero in #if")
+	}
+	if op == "/" {
+		return x / y, nil
+	}
+	return x % y, nil
+}
+
+func (e *ppExpr) unary() (int64, error) {
+	if e.pos >= len(e.toks) {
+		return 0, e.errorf("missing operand in #if")
+	}
+	tok := e.toks[e.pos]
+	e.pos++
+	switch {
+	case tok.text == "+" || tok.text == "-" || tok.text == "!" || tok.text == "~":
+		x, err := e.unary()
+		switch tok.text {
+		case "-":
+			x = -x
+		case "!":
+			if x == 0 {
+				x = 1
+			} else {
+				x = 0
+			}
+		case "~":
+			x = ^x
+		}
+		return x, err
+	case tok.text == "(":
+		x, err := e.binary(1)
+		if err != nil {
+			return 0, err
+		}
+		if e.pos >= len(e.toks) || e.toks[e.pos].text != ")" {
+			return 0, e.errorf("missing ')' in #if")
+		}
+		e.pos++
+		return x, nil
+	case tok.kind == ppNumber:
+		x, err := strconv.ParseInt(strings.TrimRight(tok.text, "uU"), 0, 64)
+		if err != nil {
+			e.pos--
+			return 0, e.errorf("invalid integer %s in #if", tok.text)
+		}
+		return x, nil
+	case tok.kind == ppIdent:
+		// an identifier that is not a macro is 0
+		return 0, nil
+	}
+	e.pos--
+	return 0, e.errorf("unexpected %s in #if", tok.text)
+}
+
+// scanLine splits a line into tokens, spaces and comments included, so that
+// joining them gives back the line. inComment tells whether the line starts
+// in a /* comment, and comment whether the next one does.
+func scanLine(line string, inComment bool) (toks []ppToken, comment bool) {
+	i := 0
+	for i < len(line) {
+		start := i
+		var kind ppKind
+		c := line[i]
+		switch {
+		case inComment || strings.HasPrefix(line[i:], "/*"):
+			if !inComment {
+				i += 2
+			}
+			inComment = true
+			if end := strings.Index(line[i:], "*/"); end >= 0 {
+				i += end + 2
+				inComment = false
+			} else {
+				i = len(line)
+			}
+			kind = ppComment
+		case strings.HasPrefix(line[i:], "//"):
+			i = len(line)
+			kind = ppComment
+		case c == ' ' || c == '\t' || c == '\r' || c == '\f' || c == '\v':
+			for i < len(line) && strings.IndexByte(" \t\r\f\v", line[i]) >= 0 {
+				i++
+			}
+			kind = ppSpace
+		case isIdentStart(c):
+			for i < len(line) && isIdentChar(line[i]) {
+				i++
+			}
+			kind = ppIdent
+		case isDigit(c) || (c == '.' && i+1 < len(line) && isDigit(line[i+1])):
+			for i < len(line) {
+				d := line[i]
+				exponent := (d == '+' || d == '-') && (line[i-1] == 'e' || line[i-1] == 'E') &&
+					!strings.HasPrefix(line[start:], "0x") && !strings.HasPrefix(line[start:], "0X")
+				if !isIdentChar(d) && d != '.' && !exponent {
+					break
+				}
+				i++
+			}
+			kind = ppNumber
+		default:
+			i++
+			for _, punct := range sPunctuators {
+				if strings.HasPrefix(line[start:], punct) {
+					i = start + len(punct)
+					break
+				}
+			}
+			kind = ppPunct
+		}
+		toks = append(toks, ppToken{kind: kind, text: line[start:i], col: start + 1})
+	}
+	return toks, inComment
+}
+
+func scanText(text string) []ppToken {
+	toks, _ := scanLine(text, false)
+	return trimSpace(toks)
+}
+
+func skipSpace(toks []ppToken, i int) int {
+	for i < len(toks) && (toks[i].kind == ppSpace || toks[i].kind == ppComment) {
+		i++
+	}
+	return i
+}
+
+func trimSpace(toks []ppToken) []ppToken {
+	for len(toks) > 0 && (toks[0].kind == ppSpace || toks[0].kind == ppComment) {
+		toks = toks[1:]
+	}
+	for len(toks) > 0 && (toks[len(toks)-1].kind == ppSpace || toks[len(toks)-1].kind == ppComment) {
+		toks = toks[:len(toks)-1]
+	}
+	return toks
+}
+
+func joinTokens(toks []ppToken) string {
+	var b strings.Builder
+	for _, tok := range toks {
+		b.WriteString(tok.text)
+	}
+	return b.String()
+}
@@ -0,0 +1,311 @@
+package glsl
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+const (
+	ppVert = `#version 330
+ATTRIBUTE vec4 position;
+ATTRIBUTE vec2 uvs;
+VARYINGOUT vec2 out_uvs;
+void main()
+{
+  gl_Position = position;
+  out_uvs = uvs;
+}`
+
+	ppFrag = `#version 330
+VARYINGIN vec2 out_uvs;
+uniform sampler2D tex1;
+COLOROUT
+
+void main()
+{
+  FRAGCOLOR = TEXTURE2D(tex1, out_uvs);
+}`
+)
+
+func preprocess(t *testing.T, pp *Preprocessor, src string) *Output {
+	out, err := pp.Preprocess("test.glsl", src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func TestPreprocessDesktopVersions(t *testing.T) {
+	for _, dialect := range []Dialect{GLSL330, GLSL400, GLSL430} {
+		out := preprocess(t, &Preprocessor{Dialect: dialect}, "#version 330\nint v = __VERSION__;\n")
+		want := fmt.Sprintf("#version %d core\nint v = %d;\n", dialect.Version(), dialect.Version())
+		if out.Text != want {
+			t.Errorf("%s: got %q", dialect, out.Text)
+		}
+	}
+}
+
+func TestPreprocessDialects(t *testing.T) {
+	for _, test := range []struct {
+		dialect    Dialect
+		vert, frag string
+	}{
+		{GLSL330, `#version 330 core
+in vec4 position;
+in vec2 uvs;
+out vec2 out_uvs;
+void main()
+`, `#version 330 core
+in vec2 out_uvs;
+uniform sampler2D tex1;
+out vec4 colourOut;
+
+void main()
+{
+  colourOut = texture(tex1, out_uvs);
+}
+`},
+		{GLSLES100, `#version 100
+precision highp float; attribute vec4 position;
+attribute vec2 uvs;
+varying vec2 out_uvs;
+void main()
+`, `#version 100
+precision highp float; varying vec2 out_uvs;
+uniform sampler2D tex1;
+
+
+void main()
+{
+  gl_FragColor = texture2D(tex1, out_uvs);
+}
+`},
+		{GLSLES300, `#version 300 es
+precision highp float; in vec4 position;
+in vec2 uvs;
+out vec2 out_uvs;
+void main()
+`, `#version 300 es
+precision highp float; in vec2 out_uvs;
+uniform sampler2D tex1;
+out vec4 colourOut;
+
+void main()
+{
+  colourOut = texture(tex1, out_uvs);
+}
+`},
+	} {
+		pp := &Preprocessor{Dialect: test.dialect}
+		vert := preprocess(t, pp, ppVert)
+		if !strings.HasPrefix(vert.Text, test.vert) {
+			t.Errorf("%s vertex:\n%s", test.dialect, vert.Text)
+		}
+		frag := preprocess(t, pp, ppFrag)
+		if frag.Text != test.frag {
+			t.Errorf("%s fragment:\n%s", test.dialect, frag.Text)
+		}
+		if len(frag.Lines) != strings.Count(ppFrag, "\n")+1 {
+			t.Errorf("%s: %d lines", test.dialect, len(frag.Lines))
+		}
+
+		// the output is GLSL the software backend compiles
+		vs, err := Compile(VertexStage, vert.Text)
+		if err != nil {
+			t.Fatalf("%s vertex: %v", test.dialect, err)
+		}
+		fs, err := Compile(FragmentStage, frag.Text)
+		if err != nil {
+			t.Fatalf("%s fragment: %v", test.dialect, err)
+		}
+		if _, err := Link(vs, fs, nil); err != nil {
+			t.Fatalf("%s: %v", test.dialect, err)
+		}
+	}
+}
+
+func TestPreprocessTokens(t *testing.T) {
+	src := `// FRAGCOLOR in a comment
+uniform vec4 MY_FRAGCOLOR; /* ATTRIBUTE
+   VARYINGIN */ uniform vec4 FRAGCOLORS;
+void main() { FRAGCOLOR=MY_FRAGCOLOR+FRAGCOLORS; }`
+	out := preprocess(t, &Preprocessor{Dialect: GLSLES100}, src)
+	want := `#version 100
+// FRAGCOLOR in a comment
+precision highp float; uniform vec4 MY_FRAGCOLOR; /* ATTRIBUTE
+   VARYINGIN */ uniform vec4 FRAGCOLORS;
+void main() { gl_FragColor=MY_FRAGCOLOR+FRAGCOLORS; }
+`
+	if out.Text != want {
+		t.Errorf("got:\n%s", out.Text)
+	}
+	// the #version line is added
+	if out.Lines[0] != (Origin{File: "test.glsl"}) || out.Lines[1] != (Origin{File: "test.glsl", Line: 1}) {
+		t.Errorf("lines %v", out.Lines)
+	}
+}
+
+func TestPreprocessConditionals(t *testing.T) {
+	src := `#version 330
+#define LIGHTS 2
+#define SQUARE(x) ((x) * (x))
+#if defined(GL_ES) && __VERSION__ < 300
+float es2;
+#elif LIGHTS > 1 || defined NOPE
+float lights[LIGHTS];
+#else
+float none;
+#endif
+#ifdef FOG
+float fog;
+#endif
+#ifndef FOG
+float y = SQUARE(LIGHTS + 1);
+#endif
+#undef LIGHTS
+#if LIGHTS
+float undefined;
+#endif
+#extension GL_OES_standard_derivatives : enable
+`
+	out := preprocess(t, &Preprocessor{Dialect: GLSL330}, src)
+	// the directives and the inactive lines are left blank
+	want := "#version 330 core\n" + strings.Repeat("\n", 5) +
+		"float lights[2];\n" + strings.Repeat("\n", 7) +
+		"float y = ((2 + 1) * (2 + 1));\n" + strings.Repeat("\n", 5) +
+		"#extension GL_OES_standard_derivatives : enable\n"
+	if out.Text != want {
+		t.Errorf("330:\n%s", out.Text)
+	}
+
+	out = preprocess(t, &Preprocessor{Dialect: GLSLES100, Defines: map[string]string{"FOG": ""}}, src)
+	if !strings.Contains(out.Text, "float es2;") || !strings.Contains(out.Text, "float fog;") ||
+		strings.Contains(out.Text, "float y") {
+		t.Errorf("ES 1.00 with FOG:\n%s", out.Text)
+	}
+}
+
+func TestPreprocessInclude(t *testing.T) {
+	lib := NewLibrary()
+	lib.Register("light.glsl", `#ifndef LIGHT
+#define LIGHT
+#include "consts.glsl"
+float light(vec3 n) { return max(dot(n, DIR), AMBIENT); }
+#endif
+`)
+	lib.Register("consts.glsl", `#define DIR vec3(0.0, 0.0, 1.0)
+const float AMBIENT = 0.3;
+`)
+	src := `#version 330
+#include "light.glsl"
+#include <light.glsl>
+void main() {}
+`
+	out := preprocess(t, &Preprocessor{Dialect: GLSL330, Includer: lib}, src)
+	want := `#version 330 core
+
+
+
+const float AMBIENT = 0.3;
+float light(vec3 n) { return max(dot(n, vec3(0.0, 0.0, 1.0)), AMBIENT); }
+
+
+
+
+
+
+void main() {}
+`
+	if out.Text != want {
+		t.Errorf("got:\n%s", out.Text)
+	}
+	origins := []Origin{
+		{"test.glsl", 1},
+		{"light.glsl", 1}, {"light.glsl", 2}, {"consts.glsl", 1}, {"consts.glsl", 2}, {"light.glsl", 4}, {"light.glsl", 5},
+		{"light.glsl", 1}, {"light.glsl", 2}, {"light.glsl", 3}, {"light.glsl", 4}, {"light.glsl", 5},
+		{"test.glsl", 4},
+	}
+	if len(out.Lines) != len(origins) {
+		t.Fatalf("lines %v", out.Lines)
+	}
+	for i, origin := range origins {
+		if out.Lines[i] != origin {
+			t.Errorf("line %d comes from %v, want %v", i+1, out.Lines[i], origin)
+		}
+	}
+}
+
+func TestPreprocessErrors(t *testing.T) {
+	lib := NewLibrary()
+	lib.Register("a.glsl", "#include \"b.glsl\"\n")
+	lib.Register("b.glsl", "float b;\n#include \"a.glsl\"\n")
+	for _, test := range []struct {
+		src, err string
+	}{
+		{"#include \"nope.glsl\"", `test.glsl:1(1): error: #include "nope.glsl": no source registered as "nope.glsl"`},
+		{"\n#include \"a.glsl\"", `b.glsl:2(1): error: #include cycle: test.glsl -> a.glsl -> b.glsl -> a.glsl`},
+		{"#ifdef X\n", `test.glsl:1(1): error: unterminated #if`},
+		{"#else\n", `test.glsl:1(1): error: #else without #if`},
+		{"#if 1\n#else\n#elif 1\n#endif\n", `test.glsl:3(1): error: #elif after #else`},
+		{"#if 1 +\n#endif", `test.glsl:1(1): error: missing operand in #if`},
+		{"#if 1 / 0\n#endif", `test.glsl:1(1): error: division by zero in #if`},
+		{"#define F(a, b) a\nF(1)", `test.glsl:2(1): error: macro F takes 2 arguments, but 1 given`},
+		{"#define F(a) a\nF(1", `test.glsl:2(1): error: unterminated argument list invoking macro F`},
+		{"#error no ES 1.00", `test.glsl:1(1): error: #error no ES 1.00`},
+		{"#foo", `test.glsl:1(1): error: invalid directive #foo`},
+	} {
+		_, err := (&Preprocessor{Includer: lib}).Preprocess("test.glsl", test.src)
+		if err == nil || err.Error() != test.err {
+			t.Errorf("%q: got %v, want %s", test.src, err, test.err)
+		}
+	}
+}
+
+func TestPreprocessUniformBlocks(t *testing.T) {
+	src := `#version 330
+layout(std140) uniform Camera {
+  mat4 view;
+  layout(row_major) mat4 proj; vec3 light,
+    dir;
+};
+ATTRIBUTE vec4 position;
+void main() { gl_Position = proj * view * position + vec4(light + dir, 0.0); }
+`
+	out := preprocess(t, &Preprocessor{Dialect: GLSL330}, src)
+	if !strings.Contains(out.Text, "layout(std140) uniform Camera {") {
+		t.Errorf("330:\n%s", out.Text)
+	}
+
+	out = preprocess(t, &Preprocessor{Dialect: GLSLES100}, src)
+	want := `#version 100
+
+  precision highp float; uniform mat4 view;
+  uniform mat4 proj; uniform vec3 light,
+    dir;
+
+attribute vec4 position;
+void main() { gl_Position = proj * view * position + vec4(light + dir, 0.0); }
+`
+	if out.Text != want {
+		t.Errorf("ES 1.00:\n%s", out.Text)
+	}
+
+	// the software backend has no uniform buffers
+	out = preprocess(t, &Preprocessor{Dialect: GLSL330, FlattenUniformBlocks: true}, src)
+	if _, err := Compile(VertexStage, out.Text); err != nil {
+		t.Errorf("%v\n%s", err, out.Text)
+	}
+
+	for _, test := range []struct {
+		src, err string
+	}{
+		{"uniform Light { vec3 dir; } light;", "test.glsl:1(29): error: uniform block Light has an instance name, which needs uniform buffers"},
+		{"uniform Light {\nvec3 dir;\n", "test.glsl:2(1): error: unterminated uniform block Light"},
+	} {
+		_, err := (&Preprocessor{Dialect: GLSLES100}).Preprocess("test.glsl", test.src)
+		if err == nil || err.Error() != test.err {
+			t.Errorf("%q: got %v, want %s", test.src, err, test.err)
+		}
+	}
+}
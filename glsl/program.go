@@ -0,0 +1,347 @@
+package glsl
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Attribute is an active vertex shader input.
+type Attribute struct {
+	Name     string
+	Type     Type
+	Location int
+
+	v *Variable
+}
+
+// Uniform is an active uniform, shared by both stages.
+type Uniform struct {
+	Name string
+	Type Type
+
+	vars []*Variable
+	envs []*env
+}
+
+// Varying is a value written by the vertex stage and read by the fragment stage.
+type Varying struct {
+	Name string
+	Type Type
+
+	vs, fs *Variable
+}
+
+type uniformLocation struct {
+	u    *Uniform
+	elem int
+}
+
+// Program is a linked vertex and fragment shader pair that can be executed.
+type Program struct {
+	Attributes []*Attribute
+	Uniforms   []*Uniform
+	Varyings   []*Varying
+
+	vs, fs     *Shader
+	vsEnv      *env
+	fsEnv      *env
+	locations  []uniformLocation
+	colorOut   *Variable
+	components int
+}
+
+// Link checks that the stages agree and assigns attribute locations.
+// bindings gives explicit locations, as with glBindAttribLocation.
+func Link(vs, fs *Shader, bindings map[string]int) (*Program, error) {
+	if vs == nil || vs.Stage != VertexStage || fs == nil || fs.Stage != FragmentStage {
+		return nil, fmt.Errorf("error: program needs a vertex and a fragment shader")
+	}
+	p := &Program{
+		vs:    vs,
+		fs:    fs,
+		vsEnv: &env{mem: make([]float32, vs.size)},
+		fsEnv: &env{mem: make([]float32, fs.size)},
+	}
+	var errs []string
+
+	// varyings
+	outs := make(map[string]*Variable)
+	for _, v := range vs.Variables(StorageOut) {
+		outs[v.Name] = v
+	}
+	for _, v := range fs.Variables(StorageIn) {
+		out, ok := outs[v.Name]
+		if !ok {
+			errs = append(errs, fmt.Sprintf("error: fragment shader varying %s not written by vertex shader", v.Name))
+			continue
+		}
+		if out.Type != v.Type {
+			errs = append(errs, fmt.Sprintf("error: %s varying %s has type %s, but the vertex shader declares %s", fs.Stage, v.Name, v.Type, out.Type))
+			continue
+		}
+		p.Varyings = append(p.Varyings, &Varying{Name: v.Name, Type: v.Type, vs: out, fs: v})
+		p.components += v.Type.Size()
+	}
+
+	// fragment output
+	fsOuts := fs.Variables(StorageOut)
+	switch len(fsOuts) {
+	case 0:
+		p.colorOut = fs.Builtin("gl_FragColor")
+	case 1:
+		p.colorOut = fsOuts[0]
+	default:
+		for _, v := range fsOuts {
+			if loc, ok := v.Layout["location"]; ok && loc == 0 {
+				p.colorOut = v
+			}
+		}
+		if p.colorOut == nil {
+			p.colorOut = fsOuts[0]
+		}
+	}
+
+	// uniforms
+	byName := make(map[string]*Uniform)
+	for _, s := range []*Shader{vs, fs} {
+		e := p.vsEnv
+		if s == fs {
+			e = p.fsEnv
+		}
+		for _, v := range s.Variables(StorageUniform) {
+			u, ok := byName[v.Name]
+			if !ok {
+				u = &Uniform{Name: v.Name, Type: v.Type}
+				byName[v.Name] = u
+				p.Uniforms = append(p.Uniforms, u)
+			} else if u.Type != v.Type {
+				errs = append(errs, fmt.Sprintf("error: uniform %s declared as %s in the vertex shader and %s in the fragment shader", v.Name, u.Type, v.Type))
+				continue
+			}
+			u.vars = append(u.vars, v)
+			u.envs = append(u.envs, e)
+		}
+	}
+	for _, u := range p.Uniforms {
+		count := u.Type.Array
+		if count == 0 {
+			count = 1
+		}
+		for i := 0; i < count; i++ {
+			p.locations = append(p.locations, uniformLocation{u: u, elem: i})
+		}
+	}
+
+	// attribute locations: explicit bindings and layouts first, then in order
+	used := make(map[int]bool)
+	ins := vs.Variables(StorageIn)
+	for _, v := range ins {
+		a := &Attribute{Name: v.Name, Type: v.Type, Location: -1, v: v}
+		if loc, ok := bindings[v.Name]; ok {
+			a.Location = loc
+		} else if loc, ok := v.Layout["location"]; ok {
+			a.Location = loc
+		}
+		if a.Location >= 0 {
+			for i := 0; i < attribSlots(v.Type); i++ {
+				used[a.Location+i] = true
+			}
+		}
+		p.Attributes = append(p.Attributes, a)
+	}
+	next := 0
+	for _, a := range p.Attributes {
+		if a.Location >= 0 {
+			continue
+		}
+		for !freeSlots(used, next, attribSlots(a.Type)) {
+			next++
+		}
+		a.Location = next
+		for i := 0; i < attribSlots(a.Type); i++ {
+			used[next+i] = true
+		}
+	}
+	sort.SliceStable(p.Attributes, func(i, j int) bool {
+		return p.Attributes[i].Location < p.Attributes[j].Location
+	})
+
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("%s", strings.Join(errs, "\n"))
+	}
+	return p, nil
+}
+
+// attribSlots is the number of attribute locations used by a type.
+func attribSlots(t Type) int {
+	n := 1
+	if t.Kind.IsMatrix() {
+		n = t.Kind.MatrixSize()
+	}
+	if t.Array > 0 {
+		n *= t.Array
+	}
+	return n
+}
+
+func freeSlots(used map[int]bool, start, n int) bool {
+	for i := start; i < start+n; i++ {
+		if used[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// AttribLocation returns the location of an active attribute, or -1.
+func (p *Program) AttribLocation(name string) int {
+	for _, a := range p.Attributes {
+		if a.Name == name {
+			return a.Location
+		}
+	}
+	return -1
+}
+
+// UniformLocation returns the location of a uniform, or -1. Array elements can
+// be addressed as "name[i]".
+func (p *Program) UniformLocation(name string) int {
+	elem := 0
+	if i := strings.IndexByte(name, '['); i >= 0 && strings.HasSuffix(name, "]") {
+		var err error
+		if elem, err = strconv.Atoi(name[i+1 : len(name)-1]); err != nil {
+			return -1
+		}
+		name = name[:i]
+	}
+	for loc, l := range p.locations {
+		if l.u.Name == name && l.elem == elem {
+			return loc
+		}
+	}
+	return -1
+}
+
+// UniformType returns the type of the uniform element at a location.
+func (p *Program) UniformType(loc int) (Type, bool) {
+	if loc < 0 || loc >= len(p.locations) {
+		return Type{}, false
+	}
+	return p.locations[loc].u.Type.Elem(), true
+}
+
+// SetUniform writes values starting at a location. Values past the end of an
+// array are ignored.
+func (p *Program) SetUniform(loc int, values []float32) {
+	if loc < 0 || loc >= len(p.locations) {
+		return
+	}
+	l := p.locations[loc]
+	size := l.u.Type.Kind.Components()
+	start := l.elem * size
+	end := l.u.Type.Size()
+	if start+len(values) < end {
+		end = start + len(values)
+	}
+	for i, v := range l.u.vars {
+		copy(l.u.envs[i].mem[v.offset+start:v.offset+end], values)
+	}
+}
+
+// Uniform reads back the values of the uniform element at a location.
+func (p *Program) Uniform(loc int) []float32 {
+	if loc < 0 || loc >= len(p.locations) {
+		return nil
+	}
+	l := p.locations[loc]
+	v := l.u.vars[0]
+	size := l.u.Type.Kind.Components()
+	start := v.offset + l.elem*size
+	return append([]float32(nil), l.u.envs[0].mem[start:start+size]...)
+}
+
+// SetSampler sets the texture lookup used by both stages.
+func (p *Program) SetSampler(s Sampler) {
+	p.vsEnv.sampler = s
+	p.fsEnv.sampler = s
+}
+
+// VaryingComponents is the number of floats written by RunVertex.
+func (p *Program) VaryingComponents() int {
+	return p.components
+}
+
+// SetAttrib sets the value of the attribute slot at location. Matrix
+// attributes use one slot per column.
+func (p *Program) SetAttrib(loc int, value [4]float32) {
+	for _, a := range p.Attributes {
+		slots := attribSlots(a.Type)
+		if loc < a.Location || loc >= a.Location+slots {
+			continue
+		}
+		col := a.Type.Kind.Components()
+		if a.Type.Kind.IsMatrix() {
+			col = a.Type.Kind.MatrixSize()
+		}
+		off := a.v.offset + (loc-a.Location)*col
+		copy(p.vsEnv.mem[off:off+col], value[:col])
+		return
+	}
+}
+
+func run(s *Shader, e *env) bool {
+	e.discard = false
+	for _, init := range s.init {
+		init(e)
+	}
+	s.main.body(e)
+	return e.discard
+}
+
+// RunVertex executes the vertex stage with the current attributes and writes
+// the varyings to out, which must hold VaryingComponents floats.
+func (p *Program) RunVertex(vertexID, instanceID int, out []float32) (position [4]float32, pointSize float32) {
+	e := p.vsEnv
+	e.mem[p.vs.Builtin("gl_VertexID").offset] = float32(vertexID)
+	e.mem[p.vs.Builtin("gl_InstanceID").offset] = float32(instanceID)
+	pointSize = 1
+	e.mem[p.vs.Builtin("gl_PointSize").offset] = pointSize
+	run(p.vs, e)
+
+	pos := p.vs.Builtin("gl_Position").offset
+	copy(position[:], e.mem[pos:pos+4])
+	pointSize = e.mem[p.vs.Builtin("gl_PointSize").offset]
+	k := 0
+	for _, v := range p.Varyings {
+		n := v.Type.Size()
+		copy(out[k:k+n], e.mem[v.vs.offset:v.vs.offset+n])
+		k += n
+	}
+	return position, pointSize
+}
+
+// RunFragment executes the fragment stage for interpolated varyings.
+func (p *Program) RunFragment(in []float32, fragCoord [4]float32, frontFacing bool) (color [4]float32, discarded bool) {
+	e := p.fsEnv
+	k := 0
+	for _, v := range p.Varyings {
+		n := v.Type.Size()
+		copy(e.mem[v.fs.offset:v.fs.offset+n], in[k:k+n])
+		k += n
+	}
+	coord := p.fs.Builtin("gl_FragCoord").offset
+	copy(e.mem[coord:coord+4], fragCoord[:])
+	e.mem[p.fs.Builtin("gl_FrontFacing").offset] = b2f(frontFacing)
+	out := p.colorOut.offset
+	for i := 0; i < 4; i++ {
+		e.mem[out+i] = 0
+	}
+
+	if run(p.fs, e) {
+		return color, true
+	}
+	copy(color[:], e.mem[out:out+p.colorOut.Type.Size()])
+	return color, false
+}
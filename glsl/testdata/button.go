@@ -0,0 +1,43 @@
+package button
+
+import "github.com/aubonbeurre/glplus"
+
+const (
+	vertShaderButton = `#version 330
+  ATTRIBUTE vec4 position;
+  VARYINGOUT vec2 out_uvs;
+  uniform mat3 ModelviewMatrix;
+  void main()
+  {
+    gl_Position = vec4(ModelviewMatrix * vec3(position.xy, 1.0), 0.0).xywz;
+    out_uvs = position.xy;
+  }`
+
+	fragShaderButton = `#version 330
+  VARYINGIN vec3 out_uvs;
+  VARYINGIN float fade;
+  uniform float animTime;
+  COLOROUT
+
+  void main(){
+    float d = length(out_uvs) - animTime;
+    FRAGCOLOR = vec4(d) * fade;
+    FRAGCOLOR.a = 1-FRAGCOLOR.a;
+  }`
+)
+
+type buttonUniforms struct {
+	Color [4]float32 `glsl:"color"`
+	Bg    [4]float32 `glsl:"bg"`
+	Glow  float32    `glsl:"glow,optional"`
+}
+
+func newButton() (*glplus.GPProgram, error) {
+	attribs := []string{"position", "uvs"}
+	program, err := glplus.LoadShaderProgram(vertShaderButton, fragShaderButton, attribs)
+	if err != nil {
+		return nil, err
+	}
+	err = program.ProgramUniform1f("animTime", 0.5)
+	return program, err
+}
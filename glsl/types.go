@@ -0,0 +1,181 @@
+package glsl
+
+import "fmt"
+
+// Kind is the basic type of a value.
+type Kind int
+
+// Basic types
+const (
+	Void Kind = iota
+	Bool
+	Int
+	Float
+	BVec2
+	BVec3
+	BVec4
+	IVec2
+	IVec3
+	IVec4
+	Vec2
+	Vec3
+	Vec4
+	Mat2
+	Mat3
+	Mat4
+	Sampler2D
+	SamplerCube
+)
+
+var sKindNames = map[Kind]string{
+	Void:        "void",
+	Bool:        "bool",
+	Int:         "int",
+	Float:       "float",
+	BVec2:       "bvec2",
+	BVec3:       "bvec3",
+	BVec4:       "bvec4",
+	IVec2:       "ivec2",
+	IVec3:       "ivec3",
+	IVec4:       "ivec4",
+	Vec2:        "vec2",
+	Vec3:        "vec3",
+	Vec4:        "vec4",
+	Mat2:        "mat2",
+	Mat3:        "mat3",
+	Mat4:        "mat4",
+	Sampler2D:   "sampler2D",
+	SamplerCube: "samplerCube",
+}
+
+var sKindByName = func() map[string]Kind {
+	m := make(map[string]Kind)
+	for k, name := range sKindNames {
+		m[name] = k
+	}
+	m["uint"] = Int
+	return m
+}()
+
+func (k Kind) String() string {
+	return sKindNames[k]
+}
+
+// Type is a basic type, optionally an array of it.
+type Type struct {
+	Kind  Kind
+	Array int
+}
+
+func (t Type) String() string {
+	if t.Array > 0 {
+		return fmt.Sprintf("%s[%d]", t.Kind, t.Array)
+	}
+	return t.Kind.String()
+}
+
+// Elem returns the element type of an array.
+func (t Type) Elem() Type {
+	return Type{Kind: t.Kind}
+}
+
+// Components is the number of float slots used by a single element.
+func (k Kind) Components() int {
+	switch k {
+	case Void:
+		return 0
+	case BVec2, IVec2, Vec2:
+		return 2
+	case BVec3, IVec3, Vec3:
+		return 3
+	case BVec4, IVec4, Vec4, Mat2:
+		return 4
+	case Mat3:
+		return 9
+	case Mat4:
+		return 16
+	}
+	return 1
+}
+
+// Size is the number of float slots used by a value of this type.
+func (t Type) Size() int {
+	if t.Array > 0 {
+		return t.Kind.Components() * t.Array
+	}
+	return t.Kind.Components()
+}
+
+// Scalar returns the scalar kind of a vector or matrix.
+func (k Kind) Scalar() Kind {
+	switch k {
+	case BVec2, BVec3, BVec4:
+		return Bool
+	case IVec2, IVec3, IVec4:
+		return Int
+	case Vec2, Vec3, Vec4, Mat2, Mat3, Mat4:
+		return Float
+	}
+	return k
+}
+
+// IsScalar ...
+func (k Kind) IsScalar() bool {
+	return k == Bool || k == Int || k == Float
+}
+
+// IsVector ...
+func (k Kind) IsVector() bool {
+	return k >= BVec2 && k <= Vec4
+}
+
+// IsMatrix ...
+func (k Kind) IsMatrix() bool {
+	return k >= Mat2 && k <= Mat4
+}
+
+// IsSampler ...
+func (k Kind) IsSampler() bool {
+	return k == Sampler2D || k == SamplerCube
+}
+
+// IsNumeric ...
+func (k Kind) IsNumeric() bool {
+	s := k.Scalar()
+	return s == Int || s == Float
+}
+
+// MatrixSize is the number of columns (and rows) of a matrix.
+func (k Kind) MatrixSize() int {
+	switch k {
+	case Mat2:
+		return 2
+	case Mat3:
+		return 3
+	case Mat4:
+		return 4
+	}
+	return 0
+}
+
+// vectorKind returns the vector kind with the given scalar and size.
+func vectorKind(scalar Kind, n int) Kind {
+	if n == 1 {
+		return scalar
+	}
+	switch scalar {
+	case Bool:
+		return BVec2 + Kind(n-2)
+	case Int:
+		return IVec2 + Kind(n-2)
+	}
+	return Vec2 + Kind(n-2)
+}
+
+// withScalar returns k with its scalar type replaced.
+func (k Kind) withScalar(scalar Kind) Kind {
+	if k.IsMatrix() {
+		return k
+	}
+	return vectorKind(scalar, k.Components())
+}
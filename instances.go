@@ -0,0 +1,131 @@
+package glplus
+
+// InstanceBuffer holds the attributes of the instances of the instanced
+// draws, bound in the vertex array of a VBO:
+//
+//	instances := NewInstanceBuffer(&VertexLayout{Attribs: []VertexAttrib{
+//		{Name: "instanceModel", Count: 16},
+//		{Name: "instanceColor", Type: Uint8Norm, Count: 4},
+//	}}, data)
+//	vbo.Bind(prog)
+//	instances.Bind(prog)
+//	vbo.DrawInstanced(instances.Count())
+//	instances.Unbind(prog)
+//	vbo.Unbind(prog)
+//
+// The data are the float components of the attributes of every instance in
+// order, 20 per instance here, as the vertices of NewVBO.
+type InstanceBuffer struct {
+	ctx     *Context
+	layout  *VertexLayout
+	streams []*Buffer
+	count   int
+
+	// the data, kept to restore the buffers
+	data []float32
+}
+
+// NewInstanceBuffer creates the buffers on the default context Gl.
+func NewInstanceBuffer(layout *VertexLayout, data []float32) *InstanceBuffer {
+	return Gl.NewInstanceBuffer(layout, data)
+}
+
+// NewInstanceBuffer creates the buffers of the instances.
+func (c *Context) NewInstanceBuffer(layout *VertexLayout, data []float32) *InstanceBuffer {
+	b := &InstanceBuffer{ctx: c, layout: layout, data: data}
+	b.create()
+	c.Register(b)
+	return b
+}
+
+// Restore re-creates the buffers after the context was lost.
+func (b *InstanceBuffer) Restore() error {
+	b.create()
+	return nil
+}
+
+func (b *InstanceBuffer) create() {
+	b.streams = make([]*Buffer, b.layout.Streams())
+	for i := range b.streams {
+		b.streams[i] = b.ctx.CreateBuffer()
+	}
+	b.upload()
+}
+
+// Delete ...
+func (b *InstanceBuffer) Delete() {
+	for _, buffer := range b.streams {
+		b.ctx.DeleteBuffer(buffer)
+	}
+	b.ctx.Unregister(b)
+}
+
+// Count is the number of instances.
+func (b *InstanceBuffer) Count() int {
+	return b.count
+}
+
+// Update replaces the instances. Their buffers get a new store, which
+// orphans the one the draws of the previous frame may still read.
+func (b *InstanceBuffer) Update(data []float32) {
+	b.data = data
+	b.upload()
+}
+
+func (b *InstanceBuffer) upload() {
+	for stream, data := range b.layout.pack(b.data) {
+		b.ctx.BindBuffer(b.ctx.ARRAY_BUFFER, b.streams[stream])
+		if dataSize(data) == 0 {
+			// no slice to point at
+			data = 0
+		}
+		b.ctx.BufferData(b.ctx.ARRAY_BUFFER, data, b.ctx.STREAM_DRAW)
+	}
+	b.ctx.BindBuffer(b.ctx.ARRAY_BUFFER, nil)
+	b.count = 0
+	if components := b.layout.Components(); components > 0 {
+		b.count = len(b.data) / components
+	}
+}
+
+// Bind points the attributes of prog at the instances, in the vertex array
+// bound by VBO.Bind.
+func (b *InstanceBuffer) Bind(prog *GPProgram) {
+	if b.count == 0 {
+		// no store to point at, nor instance to draw
+		return
+	}
+	offsets, strides := b.layout.Offsets()
+	for stream, buffer := range b.streams {
+		b.ctx.BindBuffer(b.ctx.ARRAY_BUFFER, buffer)
+		for i, a := range b.layout.Attribs {
+			loc := prog.GetAttribLocation(a.Name)
+			if a.Stream != stream || loc < 0 {
+				continue
+			}
+			a.pointer(b.ctx, loc, strides[stream], offsets[i])
+			divisor := a.Divisor
+			if divisor <= 0 {
+				divisor = 1
+			}
+			n, _ := a.columns()
+			for col := 0; col < n; col++ {
+				b.ctx.VertexAttribDivisor(loc+col, divisor)
+				b.ctx.EnableVertexAttribArray(loc + col)
+			}
+		}
+	}
+	b.ctx.BindBuffer(b.ctx.ARRAY_BUFFER, nil)
+}
+
+// Unbind makes the attributes of prog per vertex again, before VBO.Unbind.
+func (b *InstanceBuffer) Unbind(prog *GPProgram) {
+	for _, a := range b.layout.Attribs {
+		loc := prog.GetAttribLocation(a.Name)
+		n, _ := a.columns()
+		for col := 0; loc >= 0 && col < n; col++ {
+			b.ctx.VertexAttribDivisor(loc+col, 0)
+			b.ctx.DisableVertexAttribArray(loc + col)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package glplus
+
+import (
+	"os"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+var (
+	sVertShaderInstance = `#version 330
+  ATTRIBUTE vec2 position;
+  ATTRIBUTE vec2 offset;
+  ATTRIBUTE vec4 color;
+  VARYINGOUT vec4 out_color;
+
+  void main()
+  {
+      gl_Position = vec4(position + offset, 0, 1);
+      out_color = color;
+  }`
+
+	sFragShaderInstance = `#version 330
+  VARYINGIN vec4 out_color;
+  COLOROUT
+
+  void main(void)
+  {
+  	FRAGCOLOR = out_color;
+  }`
+)
+
+func TestInstanceBuffer(t *testing.T) {
+	v, restore := validatorContext(t)
+	defer restore()
+
+	prog, err := LoadShaderProgram(sVertShaderInstance, sFragShaderInstance, []string{"position"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer prog.DeleteProgram()
+
+	// the left half of the viewport, moved by the offset of the instances
+	quad := []float32{-1, -1, 0, -1, 0, 1, -1, 1}
+	vbo, err := NewVBO(prog, VBOOptions{Vertex: 2}, quad, []uint32{0, 1, 2, 2, 3, 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer vbo.DeleteVBO()
+	instances := NewInstanceBuffer(&VertexLayout{Attribs: []VertexAttrib{
+		{Name: "offset", Count: 2},
+		{Name: "color", Type: Uint8Norm, Count: 4, Stream: 1},
+	}}, []float32{
+		0, 0, 0, 1, 0, 1,
+		1, 0, 1, 0, 0, 1,
+	})
+	defer instances.Delete()
+
+	// draw returns the colors of the left and right halves
+	draw := func() (left, right [4]uint8) {
+		t.Helper()
+		Gl.ClearColor(0, 0, 1, 1)
+		Gl.Clear(Gl.COLOR_BUFFER_BIT)
+		prog.UseProgram()
+		vbo.Bind(prog)
+		instances.Bind(prog)
+		vbo.DrawInstanced(instances.Count())
+		instances.Unbind(prog)
+		vbo.Unbind(prog)
+		prog.UnuseProgram()
+		if errs := v.Errors(); len(errs) != 0 {
+			t.Fatal(errs)
+		}
+		pixels := make([]uint8, 16*16*4)
+		Gl.ReadPixels(0, 0, 16, 16, Gl.RGBA, Gl.UNSIGNED_BYTE, pixels)
+		copy(left[:], pixels[(8*16+4)*4:])
+		copy(right[:], pixels[(8*16+12)*4:])
+		return left, right
+	}
+
+	green, red, blue := [4]uint8{0, 255, 0, 255}, [4]uint8{255, 0, 0, 255}, [4]uint8{0, 0, 255, 255}
+	if left, right := draw(); left != green || right != red {
+		t.Errorf("two instances: got %v %v", left, right)
+	}
+	instances.Update([]float32{1, 0, 0, 1, 0, 1})
+	if instances.Count() != 1 {
+		t.Errorf("got %d instances", instances.Count())
+	}
+	if left, right := draw(); left != blue || right != green {
+		t.Errorf("one instance: got %v %v", left, right)
+	}
+	instances.Update(nil)
+	if left, right := draw(); left != blue || right != blue {
+		t.Errorf("no instance: got %v %v", left, right)
+	}
+}
+
+func TestObjRenderDrawInstanced(t *testing.T) {
+	_, restore := validatorContext(t)
+	defer restore()
+
+	fd, err := os.Open("windarrow.obj")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fd.Close()
+	objs, err := LoadObj(fd, &ObjOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	objrender := NewObjVBO(objs[0], false)
+	defer objrender.Delete()
+
+	// a red arrow on the left and a green one on the right, widened to
+	// cover a few pixels
+	models := []mgl32.Mat4{
+		mgl32.Translate3D(-0.5, 0, 0).Mul4(mgl32.Scale3D(2.5, 1.8, 1)).Mul4(objrender.NormalizedMat()),
+		mgl32.Translate3D(0.5, 0, 0).Mul4(mgl32.Scale3D(2.5, 1.8, 1)).Mul4(objrender.NormalizedMat()),
+	}
+	colors := []mgl32.Vec4{{1, 0, 0, 1}, {0, 1, 0, 1}}
+	for _, instancing := range []bool{true, false} {
+		Gl.Caps.Instancing = instancing
+		Gl.ClearColor(0, 0, 0, 1)
+		Gl.Clear(Gl.COLOR_BUFFER_BIT)
+		objrender.DrawInstanced(MakeDefaultMaterial(), mgl32.Ident4(), mgl32.Ident4(), mgl32.Ident4(), mgl32.Vec3{0, 0, 1}, 0, nil, models, colors)
+		checkGlError(t)
+
+		pixels := make([]uint8, 16*16*4)
+		Gl.ReadPixels(0, 0, 16, 16, Gl.RGBA, Gl.UNSIGNED_BYTE, pixels)
+		var reds, greens int
+		for i := 0; i < 16*16; i++ {
+			p := pixels[i*4 : i*4+4]
+			left := i%16 < 8
+			switch {
+			case p[0] != 0 && p[1] == 0 && left:
+				reds++
+			case p[1] != 0 && p[0] == 0 && !left:
+				greens++
+			case p[0] != 0 || p[1] != 0:
+				t.Fatalf("instancing %v: pixel %d, %d is %v", instancing, i%16, i/16, p)
+			}
+		}
+		if reds == 0 || greens == 0 {
+			t.Errorf("instancing %v: %d red and %d green pixels", instancing, reds, greens)
+		}
+
+		// the instances without a color are white
+		Gl.Clear(Gl.COLOR_BUFFER_BIT)
+		objrender.DrawInstanced(MakeDefaultMaterial(), mgl32.Ident4(), mgl32.Ident4(), mgl32.Ident4(), mgl32.Vec3{0, 0, 1}, 0, nil, models, colors[:1])
+		checkGlError(t)
+		Gl.ReadPixels(0, 0, 16, 16, Gl.RGBA, Gl.UNSIGNED_BYTE, pixels)
+		var whites int
+		for i := 0; i < 16*16; i++ {
+			if p := pixels[i*4 : i*4+4]; p[0] != 0 && p[1] != 0 && i%16 >= 8 {
+				whites++
+			}
+		}
+		if whites == 0 {
+			t.Errorf("instancing %v: no white pixels", instancing)
+		}
+	}
+}
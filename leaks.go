@@ -0,0 +1,112 @@
+package glplus
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// Resource is a GL object created through a Context and not deleted yet.
+type Resource struct {
+	// Kind is the handle type: Texture, Buffer, VertexArray, Program,
+	// Shader, FrameBuffer or RenderBuffer.
+	Kind   string
+	Handle interface{}
+	// Stack is where the object was created.
+	Stack []runtime.Frame
+
+	seq int
+}
+
+func (r *Resource) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s created at", r.Kind)
+	for _, f := range r.Stack {
+		fmt.Fprintf(&b, "\n\t%s\n\t\t%s:%d", f.Function, f.File, f.Line)
+	}
+	return b.String()
+}
+
+// ResourceTracker records the GL objects a Context creates, with their
+// creation stack, until they are deleted.
+type ResourceTracker struct {
+	live map[interface{}]*Resource
+	seq  int
+}
+
+func (t *ResourceTracker) created(handle interface{}) {
+	if reflect.ValueOf(handle).IsNil() {
+		return
+	}
+	if t.live == nil {
+		t.live = make(map[interface{}]*Resource)
+	}
+	t.seq++
+	t.live[handle] = &Resource{
+		Kind:   reflect.TypeOf(handle).Elem().Name(),
+		Handle: handle,
+		Stack:  callerStack(),
+		seq:    t.seq,
+	}
+}
+
+func (t *ResourceTracker) deleted(handle interface{}) {
+	delete(t.live, handle)
+}
+
+// LiveResources returns the live objects of a kind, all of them when kind
+// is empty, oldest first.
+func (t *ResourceTracker) LiveResources(kind string) []*Resource {
+	var res []*Resource
+	for _, r := range t.live {
+		if kind == "" || r.Kind == kind {
+			res = append(res, r)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].seq < res[j].seq })
+	return res
+}
+
+// CheckLeaks returns an error listing the live objects, nil when every
+// created object was deleted. Tests call it at teardown.
+func (t *ResourceTracker) CheckLeaks() error {
+	live := t.LiveResources("")
+	if len(live) == 0 {
+		return nil
+	}
+	counts := make(map[string]int)
+	msgs := make([]string, len(live))
+	for i, r := range live {
+		counts[r.Kind]++
+		msgs[i] = r.String()
+	}
+	var kinds []string
+	for kind, n := range counts {
+		kinds = append(kinds, fmt.Sprintf("%d %s", n, kind))
+	}
+	sort.Strings(kinds)
+	return fmt.Errorf("glplus: leaked %s\n%s", strings.Join(kinds, ", "), strings.Join(msgs, "\n"))
+}
+
+// callerStack returns the stack above the Context and its backends.
+func callerStack() []runtime.Frame {
+	pc := make([]uintptr, 32)
+	n := runtime.Callers(3, pc)
+	frames := runtime.CallersFrames(pc[:n])
+	var stack []runtime.Frame
+	for {
+		f, more := frames.Next()
+		inside := strings.Contains(f.Function, "glplus.(*Validator)") ||
+			strings.Contains(f.Function, "glplus.(*Context)") ||
+			strings.Contains(f.Function, "glplus.(*ResourceTracker)")
+		if !inside || len(stack) > 0 {
+			stack = append(stack, f)
+		}
+		if !more {
+			break
+		}
+	}
+	return stack
+}
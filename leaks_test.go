@@ -0,0 +1,82 @@
+package glplus
+
+import (
+	"image"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func softContext(t *testing.T) func() {
+	saved := Gl
+	Gl = NewBackendContext(NewSoftBackend(16, 16))
+	return func() { Gl = saved }
+}
+
+func TestCheckLeaks(t *testing.T) {
+	defer softContext(t)()
+
+	tex := GenTexture(image.Point{1, 1})
+	buf := Gl.CreateBuffer()
+	Gl.DeleteBuffer(buf)
+
+	err := Gl.CheckLeaks()
+	if err == nil || !strings.Contains(err.Error(), "leaked 1 Texture") || !strings.Contains(err.Error(), "glplus.GenTexture") {
+		t.Fatalf("got %v", err)
+	}
+	if live := Gl.LiveResources("Texture"); len(live) != 1 || live[0].Handle != tex.Handle() {
+		t.Errorf("live textures %v", live)
+	}
+	if live := Gl.LiveResources("Buffer"); len(live) != 0 {
+		t.Errorf("live buffers %v", live)
+	}
+
+	tex.DeleteTexture()
+	if err = Gl.CheckLeaks(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNoLeaks(t *testing.T) {
+	defer softContext(t)()
+
+	fontReader, err := os.Open("FreeSerif.ttf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fontReader.Close()
+	font, err := NewFont(fontReader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	str := font.NewString("leak")
+	if err = str.Draw(font, [4]float32{1, 1, 1, 1}, [4]float32{}, mgl32.Ident3(), 1, 0, 0); err != nil {
+		t.Fatal(err)
+	}
+	str.DeleteString()
+	font.DeleteFont()
+
+	fd, err := os.Open("windarrow.obj")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fd.Close()
+	objs, err := LoadObj(fd, &ObjOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	objrender := NewObjVBO(objs[0], false)
+	objrender.Delete()
+
+	button, err := NewButton(image.Point{10, 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	button.Delete()
+
+	if err = Gl.CheckLeaks(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,14 @@
+//+build soft
+
+package glplus
+
+import (
+	"os"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	Gl = NewContext()
+
+	os.Exit(m.Run())
+}
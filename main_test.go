@@ -0,0 +1,41 @@
+//+build !soft
+
+package glplus
+
+import (
+	"os"
+	"testing"
+
+	"github.com/go-gl/glfw3/v3.2/glfw"
+)
+
+func TestMain(m *testing.M) {
+	var err error
+	if err = glfw.Init(); err != nil {
+		panic(err)
+	}
+
+	defer glfw.Terminate()
+
+	glfw.WindowHint(glfw.ContextVersionMajor, 4)
+	glfw.WindowHint(glfw.ContextVersionMinor, 1)
+	glfw.WindowHint(glfw.OpenGLForwardCompatible, glfw.True)
+	glfw.WindowHint(glfw.OpenGLProfile, glfw.OpenGLCoreProfile)
+	glfw.WindowHint(glfw.Visible, glfw.False)
+
+	glfw.WindowHint(glfw.Samples, 4)
+
+	// do the actual window creation
+	var window *glfw.Window
+	window, err = glfw.CreateWindow(1024, 768, "test", nil, nil)
+	if err != nil {
+		panic(err)
+	}
+
+	window.MakeContextCurrent()
+
+	Gl = NewContext()
+
+	// call flag.Parse() here if TestMain uses flags
+	os.Exit(m.Run())
+}
@@ -17,3 +17,11 @@ func MakeDefaultMaterial() *Material {
 		Ambient:   []float32{0.2, 0.2, 0.2, 0.1},
 	}
 }
+
+// materialUniforms are the uniforms GPProgram.Material sets.
+type materialUniforms struct {
+	Ambient   [4]float32 `glsl:"ambient"`
+	Shininess float32    `glsl:"shininess"`
+	Specular  [4]float32 `glsl:"specular"`
+	Diffuse   [4]float32 `glsl:"diffuse"`
+}
@@ -7,11 +7,26 @@ import (
 )
 
 var (
+	// sVertShaderObj and sFragShaderObj are the obj shaders: plain, with
+	// TEXTURE, mapping the uvs of a texture, and with COLOR_TABLE, looking
+	// up the color of the u of the vertices. INSTANCED reads the model and
+	// the color of the instanced draws from the attributes of the instances.
 	sVertShaderObj = `#version 330
   ATTRIBUTE vec3 position;
+#ifdef TEXTURE
+	ATTRIBUTE vec2 uvs;
+	VARYINGOUT vec2 out_uvs;
+#else
 	ATTRIBUTE float uvs;
-	ATTRIBUTE vec3 normal;
 	VARYINGOUT float out_uvs;
+#endif
+	ATTRIBUTE vec3 normal;
+#ifdef INSTANCED
+	ATTRIBUTE mat4 instanceModel;
+	ATTRIBUTE vec4 instanceColor;
+#else
+	uniform vec4 instanceColor;
+#endif
 	VARYINGOUT vec4 out_color;
 	uniform vec3 light;
 	uniform mat4 mProjViewModel;
@@ -24,18 +39,26 @@ var (
 
   void main()
   {
+#ifdef INSTANCED
+		mat4 viewModel = mViewModel * instanceModel;
+		mat4 projViewModel = mProjViewModel * instanceModel;
+#else
+		mat4 viewModel = mViewModel;
+		mat4 projViewModel = mProjViewModel;
+#endif
+
 		// set the specular term to black
 		vec4 spec = vec4(0.0);
 
 		vec3 l_dir = normalize(mView * vec4(light, 0)).xyz;
-		vec3 n = normalize(mViewModel * vec4(normal, 0)).xyz;
+		vec3 n = normalize(viewModel * vec4(normal, 0)).xyz;
 		float intensity = max(dot(n, l_dir), 0.0);
 
 		// if the vertex is lit compute the specular term
 		if (intensity > 0.0) {
 
 				// compute position in camera space
-				vec3 pos = vec3(mViewModel * vec4(position, 1)).xyz;
+				vec3 pos = vec3(viewModel * vec4(position, 1)).xyz;
 				// compute eye vector and normalize it
 				vec3 eye = normalize(-pos);
 				// compute the half vector
@@ -46,102 +69,63 @@ var (
 				spec = specular * pow(intSpec, shininess);
 		}
 		// add the specular term
-		out_color = max(intensity *  diffuse + spec, ambient);
+		out_color = max(intensity *  diffuse + spec, ambient) * instanceColor;
 
 		out_uvs = uvs;
-		gl_Position = mProjViewModel * vec4(position, 1.0);
+		gl_Position = projViewModel * vec4(position, 1.0);
   }`
 
 	sFragShaderObj = `#version 330
-	VARYINGIN float out_uvs;
-	VARYINGIN vec4 out_color;
-  COLOROUT
-
-  void main(void)
-  {
-		FRAGCOLOR = out_color;
-  }`
-
-	sVertShaderObjTex = `#version 330
-	ATTRIBUTE vec3 position;
-	ATTRIBUTE vec2 uvs;
-	ATTRIBUTE vec3 normal;
-	VARYINGOUT vec2 out_uvs;
-	VARYINGOUT vec4 out_color;
-	uniform vec3 light;
-	uniform mat4 mProjViewModel;
-	uniform mat4 mViewModel;
-	uniform mat4 mView;
-	uniform vec4 ambient;
-	uniform float shininess;
-	uniform vec4 specular;
-	uniform vec4 diffuse;
-
-	void main()
-	{
-		// set the specular term to black
-		vec4 spec = vec4(0.0);
-
-		vec3 l_dir = normalize(mView * vec4(light, 0)).xyz;
-		vec3 n = normalize(mViewModel * vec4(normal, 0)).xyz;
-		float intensity = max(dot(n, l_dir), 0.0);
-
-		// if the vertex is lit compute the specular term
-		if (intensity > 0.0) {
-
-				// compute position in camera space
-				vec3 pos = vec3(mViewModel * vec4(position, 1)).xyz;
-				// compute eye vector and normalize it
-				vec3 eye = normalize(-pos);
-				// compute the half vector
-				vec3 h = normalize(l_dir + eye);
-
-				// compute the specular term into spec
-				float intSpec = max(dot(h,n), 0.0);
-				spec = specular * pow(intSpec, shininess);
-		}
-		// add the specular term
-		out_color = max(intensity *  diffuse + spec, ambient);
-
-		out_uvs = uvs;
-		gl_Position = mProjViewModel * vec4(position, 1.0);
-	}`
-
-	sFragShaderObjTex = `#version 330
+#if defined(TEXTURE) || defined(COLOR_TABLE)
 	uniform sampler2D tex1;
+#endif
+#ifdef TEXTURE
 	uniform mat3 matuv;
-  VARYINGIN vec2 out_uvs;
+	VARYINGIN vec2 out_uvs;
+#else
+	VARYINGIN float out_uvs;
+#endif
 	VARYINGIN vec4 out_color;
   COLOROUT
 
   void main(void)
   {
+#if defined(TEXTURE)
 		vec2 new_uvs = vec2(1.0-out_uvs.x, out_uvs.y);
 		new_uvs = (matuv * vec3(new_uvs, 1)).xy;
 		vec4 texcolor = TEXTURE2D(tex1, new_uvs);
 		FRAGCOLOR = out_color * texcolor;
-  }`
-
-	sFragShaderObjColorTable = `#version 330
-	uniform sampler2D tex1;
-  VARYINGIN float out_uvs;
-	VARYINGIN vec4 out_color;
-  COLOROUT
-
-  void main(void)
-  {
+#elif defined(COLOR_TABLE)
 		vec4 texcolor = TEXTURE2D(tex1, vec2(out_uvs, 0));
 		FRAGCOLOR = out_color * texcolor;
+#else
+		FRAGCOLOR = out_color;
+#endif
   }`
 )
 
+// objPrograms are the permutations of the obj shaders of a context.
+type objPrograms struct {
+	ReleasingReferenceCount
+	*ShaderPermutations
+}
+
 // ObjRender ...
 type ObjRender struct {
 	Obj *Obj
+	ctx *Context
 
 	progCoord *GPProgram
 	vbo       *VBO
 	tex       *GPTexture
+
+	// the program and the buffers of DrawInstanced, created by its first
+	// call. The linker places the attributes of the program, so it has a VBO
+	// of its own.
+	progInstanced *GPProgram
+	vboInstanced  *VBO
+	instances     *InstanceBuffer
+	defines       Defines
 }
 
 // ObjsRender ...
@@ -187,21 +171,39 @@ func (m *ObjsRender) Draw(material *Material, camera, projection, model mgl32.Ma
 	}
 }
 
-// NewObjsVBO ...
+// DrawInstanced draws the objects once per model, see ObjRender.DrawInstanced.
+func (m *ObjsRender) DrawInstanced(material *Material, camera, projection, model mgl32.Mat4, light mgl32.Vec3, uvAngle float64, tex *GPTexture, models []mgl32.Mat4, colors []mgl32.Vec4) {
+	for _, obj := range m.Objs {
+		obj.DrawInstanced(material, camera, projection, model, light, uvAngle, tex, models, colors)
+	}
+}
+
+// NewObjsVBO creates the objects on the default context Gl.
 func NewObjsVBO(objs []*Obj, hasColorTable bool) (m *ObjsRender) {
+	return Gl.NewObjsVBO(objs, hasColorTable)
+}
+
+// NewObjsVBO ...
+func (c *Context) NewObjsVBO(objs []*Obj, hasColorTable bool) (m *ObjsRender) {
 	m = &ObjsRender{}
 	for _, obj := range objs {
-		m.Objs = append(m.Objs, NewObjVBO(obj, hasColorTable))
+		m.Objs = append(m.Objs, c.NewObjVBO(obj, hasColorTable))
 	}
 	return m
 }
 
-// NewObjVBO ...
+// NewObjVBO creates the object on the default context Gl.
 func NewObjVBO(obj *Obj, hasColorTable bool) (m *ObjRender) {
+	return Gl.NewObjVBO(obj, hasColorTable)
+}
+
+// NewObjVBO ...
+func (c *Context) NewObjVBO(obj *Obj, hasColorTable bool) (m *ObjRender) {
 	var err error
 
 	m = &ObjRender{
 		Obj: obj,
+		ctx: c,
 	}
 
 	var attribs = []string{
@@ -209,20 +211,28 @@ func NewObjVBO(obj *Obj, hasColorTable bool) (m *ObjRender) {
 		"uvs",
 		"normal",
 	}
-	if obj.TexImg != nil {
-		if m.progCoord, err = LoadShaderProgram(sVertShaderObjTex, sFragShaderObjTex, attribs); err != nil {
-			panic(err)
+	if c.objects.objPrograms == nil {
+		c.objects.objPrograms = &objPrograms{
+			ReleasingReferenceCount: NewReferenceCount(),
+			ShaderPermutations:      c.NewShaderPermutations(sVertShaderObj, sFragShaderObj, attribs),
 		}
+	} else {
+		c.objects.objPrograms.Incr()
+	}
 
-		if m.tex, err = NewRGBATexture(obj.TexImg, true, false); err != nil {
-			panic(err)
-		}
+	defines := Defines{}
+	if obj.TexImg != nil {
+		defines["TEXTURE"] = ""
 	} else if hasColorTable {
-		if m.progCoord, err = LoadShaderProgram(sVertShaderObj, sFragShaderObjColorTable, attribs); err != nil {
-			panic(err)
-		}
-	} else {
-		if m.progCoord, err = LoadShaderProgram(sVertShaderObj, sFragShaderObj, attribs); err != nil {
+		defines["COLOR_TABLE"] = ""
+	}
+	m.defines = defines
+	if m.progCoord, err = c.objects.objPrograms.Program(defines); err != nil {
+		panic(err)
+	}
+
+	if obj.TexImg != nil {
+		if m.tex, err = c.NewRGBATexture(obj.TexImg, true, false); err != nil {
 			panic(err)
 		}
 	}
@@ -231,15 +241,24 @@ func NewObjVBO(obj *Obj, hasColorTable bool) (m *ObjRender) {
 	if obj.TexImg == nil {
 		opt.UV = 1
 	}
-	m.vbo = NewVBO(m.progCoord, opt, obj.ObjVertices, nil)
+	if m.vbo, err = NewVBO(m.progCoord, opt, obj.ObjVertices, nil); err != nil {
+		panic(err)
+	}
 
 	return m
 }
 
 // Delete ...
 func (m *ObjRender) Delete() {
-	m.progCoord.DeleteProgram()
+	if programs := m.ctx.objects.objPrograms; programs.Decr() {
+		programs.Delete()
+		m.ctx.objects.objPrograms = nil
+	}
 	m.vbo.DeleteVBO()
+	if m.instances != nil {
+		m.vboInstanced.DeleteVBO()
+		m.instances.Delete()
+	}
 	if m.tex != nil {
 		m.tex.DeleteTexture()
 	}
@@ -250,45 +269,123 @@ func (m *ObjRender) NormalizedMat() (mres mgl32.Mat4) {
 	return m.Obj.NormalizedMat()
 }
 
+// objUniforms are the uniforms of the obj shaders but the material. Only the
+// textured shader maps the uvs.
+type objUniforms struct {
+	ViewModel     mgl32.Mat4 `glsl:"mViewModel"`
+	ProjViewModel mgl32.Mat4 `glsl:"mProjViewModel"`
+	View          mgl32.Mat4 `glsl:"mView"`
+	Light         mgl32.Vec3 `glsl:"light"`
+	UV            mgl32.Mat3 `glsl:"matuv,optional"`
+	Tex1          int32      `glsl:"tex1,optional"`
+	Color         mgl32.Vec4 `glsl:"instanceColor,optional"`
+}
+
 // Draw ...
 func (m *ObjRender) Draw(material *Material, camera, projection, model mgl32.Mat4, light mgl32.Vec3, uvAngle float64, tex *GPTexture) {
-	m.progCoord.UseProgram()
+	m.render(m.progCoord, m.vbo, material, camera, projection, model, light, uvAngle, tex, mgl32.Vec4{1, 1, 1, 1}, nil)
+}
+
+// objInstanceLayout are the attributes of the instances of DrawInstanced.
+var objInstanceLayout = VertexLayout{Attribs: []VertexAttrib{
+	{Name: "instanceModel", Count: 16},
+	{Name: "instanceColor", Count: 4},
+}}
+
+// DrawInstanced draws the object once per model, in a single draw when the
+// context has Caps.Instancing. An instance draws with model.Mul4(models[i]),
+// and its color multiplies the lit color, white for the instances past the
+// end of colors, all of them when it is nil.
+func (m *ObjRender) DrawInstanced(material *Material, camera, projection, model mgl32.Mat4, light mgl32.Vec3, uvAngle float64, tex *GPTexture, models []mgl32.Mat4, colors []mgl32.Vec4) {
+	color := func(i int) mgl32.Vec4 {
+		if i >= len(colors) {
+			return mgl32.Vec4{1, 1, 1, 1}
+		}
+		return colors[i]
+	}
+	if !m.ctx.Caps.Instancing {
+		for i := range models {
+			m.render(m.progCoord, m.vbo, material, camera, projection, model.Mul4(models[i]), light, uvAngle, tex, color(i), nil)
+		}
+		return
+	}
+
+	data := make([]float32, 0, len(models)*objInstanceLayout.Components())
+	for i := range models {
+		c := color(i)
+		data = append(data, models[i][:]...)
+		data = append(data, c[:]...)
+	}
+	if m.instances == nil {
+		defines := Defines{"INSTANCED": ""}
+		for name, value := range m.defines {
+			defines[name] = value
+		}
+		var err error
+		if m.progInstanced, err = m.ctx.objects.objPrograms.Program(defines); err != nil {
+			panic(err)
+		}
+		if m.vboInstanced, err = NewVBO(m.progInstanced, m.vbo.options, m.Obj.ObjVertices, nil); err != nil {
+			panic(err)
+		}
+		m.instances = m.ctx.NewInstanceBuffer(&objInstanceLayout, data)
+	} else {
+		m.instances.Update(data)
+	}
+	m.render(m.progInstanced, m.vboInstanced, material, camera, projection, model, light, uvAngle, tex, mgl32.Vec4{}, m.instances)
+}
+
+// render draws the object with prog and its vbo, once per instance when
+// instances is not nil.
+func (m *ObjRender) render(prog *GPProgram, vbo *VBO, material *Material, camera, projection, model mgl32.Mat4, light mgl32.Vec3, uvAngle float64, tex *GPTexture, color mgl32.Vec4, instances *InstanceBuffer) {
+	prog.UseProgram()
 
 	matuv := mgl32.Translate2D(0.5, 0.5)
 	matuv = matuv.Mul3(mgl32.Rotate3DZ(-float32(uvAngle)))
 	matuv = matuv.Mul3(mgl32.Translate2D(-0.5, -0.5))
 
 	mViewModel := camera.Mul4(model)
-	mProjViewModel := projection.Mul4(mViewModel)
-	m.progCoord.ProgramUniformMatrix4fv("mViewModel", mViewModel)
-	m.progCoord.ProgramUniformMatrix4fv("mProjViewModel", mProjViewModel)
-	m.progCoord.ProgramUniformMatrix4fv("mView", camera)
-	m.progCoord.ProgramUniform3fv("light", light)
-	m.progCoord.ProgramUniformMatrix3fv("matuv", matuv)
+	uniforms := objUniforms{
+		ViewModel:     mViewModel,
+		ProjViewModel: projection.Mul4(mViewModel),
+		View:          camera,
+		Light:         light,
+		UV:            matuv,
+		Color:         color,
+	}
 
 	if m.tex != nil {
 		m.tex.BindTexture(0)
-		m.progCoord.ProgramUniform1i("tex1", 0)
 	} else if tex != nil {
 		tex.BindTexture(0)
-		m.progCoord.ProgramUniform1i("tex1", 0)
 	}
 
-	m.vbo.Bind(m.progCoord)
-	m.progCoord.Material(material)
-
-	var err error
-	if err = m.progCoord.ValidateProgram(); err != nil {
-		panic(err)
+	vbo.Bind(prog)
+	if instances != nil {
+		instances.Bind(prog)
+	}
+	for _, err := range []error{
+		prog.SetUniforms(&uniforms),
+		prog.Material(material),
+		prog.ValidateProgram(),
+	} {
+		if err != nil {
+			panic(err)
+		}
 	}
-	m.vbo.Draw()
-	m.vbo.Unbind(m.progCoord)
+	if instances != nil {
+		vbo.DrawInstanced(instances.Count())
+		instances.Unbind(prog)
+	} else {
+		vbo.Draw()
+	}
+	vbo.Unbind(prog)
 
 	if m.tex != nil {
 		m.tex.UnbindTexture(0)
 	} else if tex != nil {
-		m.tex.UnbindTexture(0)
+		tex.UnbindTexture(0)
 	}
 
-	m.progCoord.UnuseProgram()
+	prog.UnuseProgram()
 }
@@ -0,0 +1,136 @@
+package glplus
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Defines are the macros of a shader permutation, NAME or NAME(a, b) to the
+// replacement, "" for a flag tested with #ifdef.
+type Defines map[string]string
+
+// String is the key of the permutation: the sorted names, with their
+// replacement when it is not empty, "FOG=2 TEXTURE".
+func (d Defines) String() string {
+	keys := make([]string, 0, len(d))
+	for name, value := range d {
+		if value != "" {
+			name += "=" + value
+		}
+		keys = append(keys, name)
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, " ")
+}
+
+func (d Defines) copy() Defines {
+	res := make(Defines, len(d))
+	for name, value := range d {
+		res[name] = value
+	}
+	return res
+}
+
+// ShaderPermutations builds the permutations of one vertex and fragment
+// source, whose features are #ifdef blocks:
+//
+//	#ifdef TEXTURE
+//	uniform sampler2D tex1;
+//	#endif
+//
+// A permutation is compiled the first time Program asks for it, or by
+// Precompile, and kept until Delete. The permutations whose translated
+// sources are the same share their program.
+type ShaderPermutations struct {
+	ctx      *Context
+	vert     string
+	frag     string
+	attribs  []string
+	programs map[string]*shaderPermutation
+}
+
+type shaderPermutation struct {
+	defines Defines
+	program *GPProgram
+}
+
+// NewShaderPermutations creates the permutations of the sources on the
+// default context Gl.
+func NewShaderPermutations(vertShader, fragShader string, attribs []string) *ShaderPermutations {
+	return Gl.NewShaderPermutations(vertShader, fragShader, attribs)
+}
+
+// NewShaderPermutations creates the permutations of the sources, none of
+// them compiled yet.
+func (c *Context) NewShaderPermutations(vertShader, fragShader string, attribs []string) *ShaderPermutations {
+	return &ShaderPermutations{
+		ctx:      c,
+		vert:     vertShader,
+		frag:     fragShader,
+		attribs:  attribs,
+		programs: make(map[string]*shaderPermutation),
+	}
+}
+
+// Program returns the permutation of defines, compiling it the first time.
+// The program belongs to the permutations: it is deleted by Delete, not by
+// the caller.
+func (s *ShaderPermutations) Program(defines Defines) (*GPProgram, error) {
+	key := defines.String()
+	if perm := s.programs[key]; perm != nil {
+		return perm.program, nil
+	}
+	p, err := s.load(defines)
+	if err != nil {
+		return nil, fmt.Errorf("glplus: permutation [%s]: %w", key, err)
+	}
+	s.programs[key] = &shaderPermutation{defines: defines.copy(), program: p}
+	return p, nil
+}
+
+func (s *ShaderPermutations) load(defines Defines) (*GPProgram, error) {
+	c := s.ctx
+	vert, err := c.preprocess(VertexStage, "vertex", s.vert, sShaderIncludes, defines)
+	if err != nil {
+		return nil, err
+	}
+	frag, err := c.preprocess(FragmentStage, "fragment", s.frag, sShaderIncludes, defines)
+	if err != nil {
+		return nil, err
+	}
+	return c.loadProgram(vertexFragment(vert, frag), s.attribs)
+}
+
+// Precompile compiles the permutations of sets that are not yet, at the
+// start of an application for example, and returns the first error.
+func (s *ShaderPermutations) Precompile(sets ...Defines) error {
+	for _, defines := range sets {
+		if _, err := s.Program(defines); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Permutations lists the compiled permutations, sorted by key.
+func (s *ShaderPermutations) Permutations() []Defines {
+	keys := make([]string, 0, len(s.programs))
+	for key := range s.programs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	res := make([]Defines, len(keys))
+	for i, key := range keys {
+		res[i] = s.programs[key].defines.copy()
+	}
+	return res
+}
+
+// Delete releases the programs of the permutations.
+func (s *ShaderPermutations) Delete() {
+	for key, perm := range s.programs {
+		perm.program.DeleteProgram()
+		delete(s.programs, key)
+	}
+}
@@ -0,0 +1,152 @@
+package glplus
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const (
+	sVertShaderPermutations = `#version 330
+  ATTRIBUTE vec4 position;
+  uniform mat4 mvp;
+#ifdef FOG
+  VARYINGOUT float depth;
+#endif
+  void main()
+  {
+    gl_Position = mvp * position;
+#ifdef FOG
+    depth = gl_Position.z;
+#endif
+  }`
+
+	sFragShaderPermutations = `#version 330
+  uniform vec4 color;
+#ifdef FOG
+  VARYINGIN float depth;
+  uniform vec4 fogColor;
+#endif
+  COLOROUT
+  void main()
+  {
+    vec4 c = color * SCALE;
+#ifdef FOG
+    c = mix(c, fogColor, clamp(depth, 0.0, 1.0));
+#endif
+    FRAGCOLOR = c;
+  }`
+)
+
+func TestDefinesString(t *testing.T) {
+	if s := (Defines{"TEXTURE": "", "FOG": "2", "A(x)": "x"}).String(); s != "A(x)=x FOG=2 TEXTURE" {
+		t.Errorf("got %q", s)
+	}
+	if s := (Defines{}).String(); s != "" {
+		t.Errorf("got %q", s)
+	}
+}
+
+func TestShaderPermutations(t *testing.T) {
+	saved := Gl
+	defer func() { Gl = saved }()
+	counter := &compileCounter{NativeBackend: NewSoftBackend(16, 16)}
+	Gl = NewBackendContext(counter)
+
+	perms := NewShaderPermutations(sVertShaderPermutations, sFragShaderPermutations, []string{"position"})
+	if counter.compiles != 0 || len(perms.Permutations()) != 0 {
+		t.Fatalf("compiled before asked")
+	}
+
+	plain, err := perms.Program(Defines{"SCALE": "1.0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defines := Defines{"FOG": "", "SCALE": "1.0"}
+	fog, err := perms.Program(defines)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plain == fog || plain.ActiveUniform("fogColor") != nil || fog.ActiveUniform("fogColor") == nil {
+		t.Errorf("permutations not told apart")
+	}
+	// the defines are copied
+	defines["SCALE"] = "2.0"
+	again, err := perms.Program(Defines{"SCALE": "1.0", "FOG": ""})
+	if err != nil || again != fog || counter.compiles != 4 {
+		t.Errorf("compiled again: %d compiles, %v", counter.compiles, err)
+	}
+
+	if err := perms.Precompile(Defines{"SCALE": "0.5"}, Defines{"SCALE": "1.0"}, Defines{"SCALE": "0.5", "FOG": ""}); err != nil {
+		t.Fatal(err)
+	}
+	want := []Defines{
+		{"FOG": "", "SCALE": "0.5"},
+		{"FOG": "", "SCALE": "1.0"},
+		{"SCALE": "0.5"},
+		{"SCALE": "1.0"},
+	}
+	if got := perms.Permutations(); !reflect.DeepEqual(got, want) {
+		t.Errorf("permutations %v", got)
+	}
+	if counter.compiles != 8 {
+		t.Errorf("%d compiles", counter.compiles)
+	}
+
+	// SCALE is not defined
+	if _, err := perms.Program(nil); err == nil || !strings.HasPrefix(err.Error(), "glplus: permutation []: Failed to compile") {
+		t.Errorf("got %v", err)
+	}
+	if err := perms.Precompile(Defines{"FOG": ""}); err == nil || !strings.HasPrefix(err.Error(), "glplus: permutation [FOG]: Failed to compile") {
+		t.Errorf("got %v", err)
+	}
+	if len(perms.Permutations()) != 4 {
+		t.Errorf("failed permutations listed")
+	}
+
+	perms.Delete()
+	if len(perms.Permutations()) != 0 {
+		t.Errorf("not deleted")
+	}
+	if err := Gl.CheckLeaks(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestObjPermutations(t *testing.T) {
+	defer softContext(t)()
+
+	fd, err := os.Open("windarrow.obj")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fd.Close()
+	objs, err := LoadObj(fd, &ObjOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain := NewObjVBO(objs[0], false)
+	table := NewObjVBO(objs[0], true)
+	other := NewObjVBO(objs[0], true)
+	if table.progCoord != other.progCoord || plain.progCoord == table.progCoord {
+		t.Errorf("programs not shared")
+	}
+	want := []Defines{{}, {"COLOR_TABLE": ""}}
+	if got := Gl.objects.objPrograms.Permutations(); !reflect.DeepEqual(got, want) {
+		t.Errorf("permutations %v", got)
+	}
+	if table.progCoord.ActiveUniform("tex1") == nil || plain.progCoord.ActiveUniform("tex1") != nil {
+		t.Errorf("color table not told apart")
+	}
+
+	plain.Delete()
+	table.Delete()
+	other.Delete()
+	if Gl.objects.objPrograms != nil {
+		t.Errorf("obj programs kept")
+	}
+	if err := Gl.CheckLeaks(); err != nil {
+		t.Error(err)
+	}
+}
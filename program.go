@@ -2,24 +2,41 @@ package glplus
 
 import (
 	"fmt"
-	"runtime"
-	"strings"
+
+	"github.com/aubonbeurre/glplus/glsl"
 )
 
 // GPProgram ...
 type GPProgram struct {
 	prog *Program
+	ctx  *Context
 
 	uniforms map[string]*UniformLocation
 	attribs  []string
 	hash     string
+
+	// reflected after the link
+	activeUniforms []*UniformInfo
+	uniformInfos   map[string]*UniformInfo
+	activeAttribs  []*AttribInfo
+
+	// the SetUniforms bindings and last uploads
+	bindings map[bindingKey]*uniformBinding
+	uploaded map[string]*uploadedValue
+	// the uniform blocks bound to buffers
+	blocks map[string]*boundBlock
+
+	// the translated sources, kept to restore the program
+	sources []stageSource
 }
 
 // DeleteProgram ...
 func (p *GPProgram) DeleteProgram() {
-	if cache := sProgCache[p.hash]; cache != nil {
+	progCache := p.ctx.objects.progCache
+	if cache := progCache[p.hash]; cache != nil {
 		if cache.Decr() {
 			//fmt.Printf("Delete %s\n", p.hash)
+			delete(progCache, p.hash)
 			cache.Delete()
 		}
 	}
@@ -27,14 +44,14 @@ func (p *GPProgram) DeleteProgram() {
 
 // GetProgramInfoLog ...
 func (p *GPProgram) GetProgramInfoLog() string {
-	return Gl.GetProgramInfoLog(p.prog)
+	return p.ctx.GetProgramInfoLog(p.prog)
 }
 
 // ValidateProgram ...
 func (p *GPProgram) ValidateProgram() error {
-	Gl.ValidateProgram(p.prog)
+	p.ctx.ValidateProgram(p.prog)
 
-	if !Gl.GetProgramParameterb(p.prog, Gl.VALIDATE_STATUS) {
+	if !p.ctx.GetProgramParameterb(p.prog, p.ctx.VALIDATE_STATUS) {
 		return fmt.Errorf("Failed to validate the program!\n%s", p.GetProgramInfoLog())
 	}
 	return nil
@@ -48,78 +65,114 @@ func (p *GPProgram) GetUniformLocation(s string) *UniformLocation {
 		return res
 	}
 
-	res = Gl.GetUniformLocation(p.prog, s)
+	res = p.ctx.GetUniformLocation(p.prog, s)
 	p.uniforms[s] = res
 	return res
 }
 
 // GetAttribLocation ...
 func (p *GPProgram) GetAttribLocation(s string) int {
-	return Gl.GetAttribLocation(p.prog, s)
+	return p.ctx.GetAttribLocation(p.prog, s)
 }
 
 // UseProgram ...
 func (p *GPProgram) UseProgram() {
-	Gl.UseProgram(p.prog)
+	p.ctx.UseProgram(p.prog)
+	if len(p.blocks) > 0 && !p.ctx.Caps.UniformBuffers {
+		p.updateBlocks()
+	}
 }
 
 // UnuseProgram ...
 func (p *GPProgram) UnuseProgram() {
-	Gl.UseProgram(nil)
+	p.ctx.UseProgram(nil)
 }
 
-// Material ...
-func (p *GPProgram) Material(m *Material) {
+// Material sets the ambient, shininess, specular and diffuse uniforms.
+func (p *GPProgram) Material(m *Material) error {
 	toSlice4 := func(in []float32) (res [4]float32) {
 		return [4]float32{in[0], in[1], in[2], in[3]}
 	}
 
-	p.ProgramUniform4fv("ambient", toSlice4(m.Ambient))
-	p.ProgramUniform1f("shininess", m.Shininess)
-	p.ProgramUniform4fv("specular", toSlice4(m.Specular))
-	p.ProgramUniform4fv("diffuse", toSlice4(m.Diffuse))
+	return p.SetUniforms(&materialUniforms{
+		Ambient:   toSlice4(m.Ambient),
+		Shininess: m.Shininess,
+		Specular:  toSlice4(m.Specular),
+		Diffuse:   toSlice4(m.Diffuse),
+	})
 }
 
-// ProgramUniform1f ...
-func (p *GPProgram) ProgramUniform1f(uniform string, value float32) {
-	var uniformloc = p.GetUniformLocation(uniform)
-	Gl.Uniform1f(uniformloc, value)
+// The ProgramUniform setters fail when the uniform is not active or not of
+// their type. An array element is named as in "lights[2]".
+
+// ProgramUniform1f sets a float or a bool.
+func (p *GPProgram) ProgramUniform1f(uniform string, value float32) error {
+	info, elem, err := p.uniform(uniform, p.ctx.FLOAT, p.ctx.BOOL)
+	if err != nil {
+		return err
+	}
+	p.ctx.Uniform1f(p.location(info, elem), value)
+	return nil
 }
 
 // ProgramUniform2f ...
-func (p *GPProgram) ProgramUniform2f(uniform string, v0 float32, v1 float32) {
-	var uniformloc = p.GetUniformLocation(uniform)
-	Gl.Uniform2f(uniformloc, v0, v1)
+func (p *GPProgram) ProgramUniform2f(uniform string, v0 float32, v1 float32) error {
+	info, elem, err := p.uniform(uniform, p.ctx.FLOAT_VEC2)
+	if err != nil {
+		return err
+	}
+	p.ctx.Uniform2f(p.location(info, elem), v0, v1)
+	return nil
 }
 
 // ProgramUniform4fv ...
-func (p *GPProgram) ProgramUniform4fv(uniform string, value [4]float32) {
-	var uniformloc = p.GetUniformLocation(uniform)
-	Gl.Uniform4f(uniformloc, value[0], value[1], value[2], value[3])
+func (p *GPProgram) ProgramUniform4fv(uniform string, value [4]float32) error {
+	info, elem, err := p.uniform(uniform, p.ctx.FLOAT_VEC4)
+	if err != nil {
+		return err
+	}
+	p.ctx.Uniform4f(p.location(info, elem), value[0], value[1], value[2], value[3])
+	return nil
 }
 
 // ProgramUniform3fv ...
-func (p *GPProgram) ProgramUniform3fv(uniform string, value [3]float32) {
-	var uniformloc = p.GetUniformLocation(uniform)
-	Gl.Uniform3f(uniformloc, value[0], value[1], value[2])
+func (p *GPProgram) ProgramUniform3fv(uniform string, value [3]float32) error {
+	info, elem, err := p.uniform(uniform, p.ctx.FLOAT_VEC3)
+	if err != nil {
+		return err
+	}
+	p.ctx.Uniform3f(p.location(info, elem), value[0], value[1], value[2])
+	return nil
 }
 
-// ProgramUniform1i ...
-func (p *GPProgram) ProgramUniform1i(uniform string, value int) {
-	var uniformloc = p.GetUniformLocation(uniform)
-	Gl.Uniform1i(uniformloc, value)
+// ProgramUniform1i sets an int, a bool or a sampler.
+func (p *GPProgram) ProgramUniform1i(uniform string, value int) error {
+	info, elem, err := p.uniform(uniform, p.ctx.INT, p.ctx.BOOL, p.ctx.SAMPLER_2D, p.ctx.SAMPLER_CUBE)
+	if err != nil {
+		return err
+	}
+	p.ctx.Uniform1i(p.location(info, elem), value)
+	return nil
 }
 
 // ProgramUniformMatrix4fv ...
-func (p *GPProgram) ProgramUniformMatrix4fv(uniform string, matrix [16]float32) {
-	var uniformloc = p.GetUniformLocation(uniform)
-	Gl.UniformMatrix4fv(uniformloc, false, matrix[:])
+func (p *GPProgram) ProgramUniformMatrix4fv(uniform string, matrix [16]float32) error {
+	info, elem, err := p.uniform(uniform, p.ctx.FLOAT_MAT4)
+	if err != nil {
+		return err
+	}
+	p.ctx.UniformMatrix4fv(p.location(info, elem), false, matrix[:])
+	return nil
 }
 
 // ProgramUniformMatrix3fv ...
-func (p *GPProgram) ProgramUniformMatrix3fv(uniform string, matrix [9]float32) {
-	var uniformloc = p.GetUniformLocation(uniform)
-	Gl.UniformMatrix3fv(uniformloc, false, matrix[:])
+func (p *GPProgram) ProgramUniformMatrix3fv(uniform string, matrix [9]float32) error {
+	info, elem, err := p.uniform(uniform, p.ctx.FLOAT_MAT3)
+	if err != nil {
+		return err
+	}
+	p.ctx.UniformMatrix3fv(p.location(info, elem), false, matrix[:])
+	return nil
 }
 
 // GetShaderInfoLog ...
@@ -132,102 +185,178 @@ func ShaderSource(shader *Shader, src string) {
 	Gl.ShaderSource(shader, src)
 }
 
-// LoadShaderProgram ... loads shader objects and then attaches them to a program
+// LoadShaderProgram loads a program on the default context Gl.
 func LoadShaderProgram(vertShader string, fragShader string, attribs []string) (*GPProgram, error) {
+	return Gl.LoadShaderProgram(vertShader, fragShader, attribs)
+}
+
+// LoadShaderProgram ... loads shader objects and then attaches them to a program
+func (c *Context) LoadShaderProgram(vertShader string, fragShader string, attribs []string) (*GPProgram, error) {
+	vert, err := c.preprocess(VertexStage, "vertex", vertShader, sShaderIncludes, nil)
+	if err != nil {
+		return nil, err
+	}
+	frag, err := c.preprocess(FragmentStage, "fragment", fragShader, sShaderIncludes, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.loadProgram(vertexFragment(vert, frag), attribs)
+}
+
+// loadProgram builds the program of translated sources, sorted by stage, or
+// finds it in the cache.
+func (c *Context) loadProgram(sources []stageSource, attribs []string) (*GPProgram, error) {
 	// query program cache
-	if sProgCache == nil {
-		sProgCache = make(map[string]*ProgramCache)
+	if c.objects.progCache == nil {
+		c.objects.progCache = make(map[string]*ProgramCache)
 	}
-	hash := getMD5Hash(vertShader, fragShader)
-	if cache := sProgCache[hash]; cache != nil {
+	hash := programHash(sources)
+	if cache := c.objects.progCache[hash]; cache != nil {
 		cache.Incr()
 		//fmt.Printf("Hit %s\n", cache.program.hash)
 		return cache.program, nil
 	}
 
-	// create the program
-	var prog = Gl.CreateProgram()
 	var p = &GPProgram{
-		prog:     prog,
-		uniforms: make(map[string]*UniformLocation),
-		attribs:  attribs,
-		hash:     hash,
+		ctx:     c,
+		attribs: attribs,
+		hash:    hash,
+		sources: sources,
 	}
-
-	if runtime.GOARCH == "js" || runtime.GOOS == "android" {
-		vertShader = strings.Replace(vertShader, "#version 330\n", "precision highp float;\n", -1)
-		vertShader = strings.Replace(vertShader, "ATTRIBUTE", "attribute", -1)
-		vertShader = strings.Replace(vertShader, "VARYINGOUT", "varying", -1)
-		vertShader = strings.Replace(vertShader, "TEXTURE2D", "texture2D", -1)
-	} else {
-		vertShader = strings.Replace(vertShader, "ATTRIBUTE", "in", -1)
-		vertShader = strings.Replace(vertShader, "VARYINGOUT", "out", -1)
-		vertShader = strings.Replace(vertShader, "TEXTURE2D", "texture", -1)
+	if err := p.build(); err != nil {
+		return nil, err
 	}
 
-	if runtime.GOARCH == "js" || runtime.GOOS == "android" {
-		fragShader = strings.Replace(fragShader, "#version 330\n", "precision highp float;\n", -1)
-		fragShader = strings.Replace(fragShader, "VARYINGIN", "varying", -1)
-		fragShader = strings.Replace(fragShader, "COLOROUT", "", -1)
-		fragShader = strings.Replace(fragShader, "FRAGCOLOR", "gl_FragColor", -1)
-		fragShader = strings.Replace(fragShader, "TEXTURE2D", "texture2D", -1)
-	} else {
-		fragShader = strings.Replace(fragShader, "ATTRIBUTE", "attribute", -1)
-		fragShader = strings.Replace(fragShader, "VARYINGIN", "in", -1)
-		fragShader = strings.Replace(fragShader, "COLOROUT", "out vec4 colourOut;", -1)
-		fragShader = strings.Replace(fragShader, "FRAGCOLOR", "colourOut", -1)
-		fragShader = strings.Replace(fragShader, "TEXTURE2D", "texture", -1)
+	// insert in prog cache
+	c.objects.progCache[hash] = &ProgramCache{
+		ReleasingReferenceCount: NewReferenceCount(),
+		program:                 p,
 	}
+	c.Register(p)
 
-	// create the vertex shader
-	var vs = Gl.CreateShader(Gl.VERTEX_SHADER)
-	ShaderSource(vs, vertShader)
-	Gl.CompileShader(vs)
+	//fmt.Printf("Create %s\n", p.hash)
+	return p, nil
+}
 
-	if !Gl.GetShaderiv(vs, Gl.COMPILE_STATUS) {
-		fmt.Println(vertShader)
-		return nil, fmt.Errorf("Failed to compile the vertex shader!\n%s", GetShaderInfoLog(vs))
-	}
+// sShaderIncludes are the sources the shaders can #include.
+var sShaderIncludes = glsl.NewLibrary()
 
-	// create the fragment shader
-	var fs = Gl.CreateShader(Gl.FRAGMENT_SHADER)
-	ShaderSource(fs, fragShader)
-	Gl.CompileShader(fs)
+// RegisterShaderInclude makes src the source of #include "name" in the
+// shaders of every context.
+func RegisterShaderInclude(name, src string) {
+	sShaderIncludes.Register(name, src)
+}
 
-	if !Gl.GetShaderiv(fs, Gl.COMPILE_STATUS) {
-		fmt.Println(fragShader)
-		return nil, fmt.Errorf("Failed to compile the fragment shader!\n%s", GetShaderInfoLog(fs))
+// preprocess translates the glplus source of a shader stage, read from
+// file, to the GLSL of the context, with defines.
+func (c *Context) preprocess(stage ShaderStage, file, src string, includer glsl.Includer, defines Defines) (*glsl.Output, error) {
+	pp := &glsl.Preprocessor{
+		Dialect:              c.Caps.stageDialect(stage),
+		Includer:             includer,
+		Defines:              defines,
+		FlattenUniformBlocks: !c.Caps.UniformBuffers,
+	}
+	out, err := pp.Preprocess(file, src)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to preprocess the %s shader!\n%v", stage, err)
 	}
+	return out, nil
+}
+
+// build compiles and links the sources into a new program.
+func (p *GPProgram) build() error {
+	prog, err := p.ctx.linkProgram(p.sources)
+	if err != nil {
+		return err
+	}
+	p.prog = prog
+	return p.linked()
+}
 
-	// attach the shaders to the program and link
-	Gl.AttachShader(prog, vs)
-	Gl.AttachShader(prog, fs)
+// linked reflects a new program and binds its blocks.
+func (p *GPProgram) linked() error {
+	p.uniforms = make(map[string]*UniformLocation)
+	p.queryActive()
+	return p.rebindBlocks()
+}
+
+// linkProgram links translated sources, from the program binary cache when
+// it has them.
+func (c *Context) linkProgram(sources []stageSource) (*Program, error) {
+	if c.objects.binaries == nil || !c.Caps.ProgramBinary {
+		return c.compileProgram(sources)
+	}
+	hash := programHash(sources)
+	if prog := c.loadProgramBinary(hash); prog != nil {
+		return prog, nil
+	}
+	prog, err := c.compileProgram(sources)
+	if err != nil {
+		return nil, err
+	}
+	c.storeProgramBinary(hash, prog)
+	return prog, nil
+}
 
-	Gl.LinkProgram(prog)
+// compileProgram compiles and links translated sources. Nothing is left
+// behind when they fail to.
+func (c *Context) compileProgram(sources []stageSource) (*Program, error) {
+	shaders := make([]*Shader, 0, len(sources))
+	for _, src := range sources {
+		shader, err := c.compileShader(c.shaderType(src.stage), src.stage.String(), src.out)
+		if err != nil {
+			return nil, err
+		}
+		defer c.DeleteShader(shader)
+		shaders = append(shaders, shader)
+	}
 
-	if !Gl.GetProgramParameterb(p.prog, Gl.LINK_STATUS) {
-		return nil, fmt.Errorf("Failed to link the program!\n%s", p.GetProgramInfoLog())
+	// attach the shaders to the program and link, the shaders are deleted
+	// with it
+	prog := c.CreateProgram()
+	for _, shader := range shaders {
+		c.AttachShader(prog, shader)
+	}
+	if c.objects.binaries != nil && c.Caps.ProgramBinary {
+		// drivers may keep no binary for the cache without the hint
+		c.ProgramParameteri(prog, c.PROGRAM_BINARY_RETRIEVABLE_HINT, c.TRUE)
 	}
 
-	// at this point the shaders can be deleted
-	Gl.DeleteShader(vs)
-	Gl.DeleteShader(fs)
+	c.LinkProgram(prog)
 
-	// insert in prog cache
-	sProgCache[hash] = &ProgramCache{
-		ReleasingReferenceCount: NewReferenceCount(),
-		program:                 p,
+	if !c.GetProgramParameterb(prog, c.LINK_STATUS) {
+		err := fmt.Errorf("Failed to link the program!\n%s", c.GetProgramInfoLog(prog))
+		c.DeleteProgram(prog)
+		return nil, err
 	}
+	return prog, nil
+}
 
-	//fmt.Printf("Create %s\n", p.hash)
-	return p, nil
+// compileShader compiles a translated source, whose errors are a
+// *ShaderCompileError.
+func (c *Context) compileShader(typ int, stage string, src *glsl.Output) (*Shader, error) {
+	shader := c.CreateShader(typ)
+	c.ShaderSource(shader, src.Text)
+	c.CompileShader(shader)
+
+	if !c.GetShaderiv(shader, c.COMPILE_STATUS) {
+		err := newShaderCompileError(stage, src, c.GetShaderInfoLog(shader))
+		c.DeleteShader(shader)
+		return nil, err
+	}
+	return shader, nil
+}
+
+// Restore compiles the program again after the context was lost.
+func (p *GPProgram) Restore() error {
+	return p.build()
 }
 
 // GetAttribs ...
 func (p *GPProgram) GetAttribs() (attribs map[string]int) {
 	attribs = make(map[string]int)
 	for _, attr := range p.attribs {
-		attribs[attr] = Gl.GetAttribLocation(p.prog, attr)
+		attribs[attr] = p.ctx.GetAttribLocation(p.prog, attr)
 	}
 	return attribs
 }
@@ -0,0 +1,160 @@
+package glplus
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+// ProgramBinaryCache keeps the linked programs in a directory, to load them
+// at the next start without compiling their shaders, on the drivers with
+// Caps.ProgramBinary. An entry is keyed by the MD5 of the translated sources
+// of the program and by Caps.Driver: the entries of another driver, the
+// corrupted ones and the ones the driver rejects are removed, and the
+// programs compiled from their sources again.
+type ProgramBinaryCache struct {
+	dir string
+}
+
+// NewProgramBinaryCache creates dir when it does not exist.
+func NewProgramBinaryCache(dir string) (*ProgramBinaryCache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &ProgramBinaryCache{dir: dir}, nil
+}
+
+// SetProgramBinaryCache makes the programs of the context load from and
+// store into b, nil turning the cache off.
+func (c *Context) SetProgramBinaryCache(b *ProgramBinaryCache) {
+	c.objects.binaries = b
+}
+
+// errStaleProgramBinary is the error of an entry of another driver.
+var errStaleProgramBinary = errors.New("glplus: program binary of another driver")
+
+func (b *ProgramBinaryCache) path(hash string) string {
+	return filepath.Join(b.dir, hash+".bin")
+}
+
+// load reads the binary of the program of hash for driver.
+func (b *ProgramBinaryCache) load(hash, driver string) (format int, data []byte, err error) {
+	entry, err := os.ReadFile(b.path(hash))
+	if err != nil {
+		return 0, nil, err
+	}
+	entryDriver, format, data, err := decodeProgramBinary(entry)
+	if err != nil {
+		return 0, nil, err
+	}
+	if entryDriver != driver {
+		return 0, nil, errStaleProgramBinary
+	}
+	return format, data, nil
+}
+
+// store writes the binary of the program of hash for driver, in a temporary
+// file renamed over the entry so that a reader never gets half of it.
+func (b *ProgramBinaryCache) store(hash, driver string, format int, data []byte) error {
+	f, err := os.CreateTemp(b.dir, hash+".*.tmp")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(encodeProgramBinary(driver, format, data))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), b.path(hash))
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
+}
+
+func (b *ProgramBinaryCache) remove(hash string) {
+	os.Remove(b.path(hash))
+}
+
+// sProgramBinaryMagic starts the entries, followed by the version of their
+// layout.
+var sProgramBinaryMagic = []byte("GLPB")
+
+const programBinaryVersion = 1
+
+// encodeProgramBinary lays out an entry, little-endian: the magic, the
+// version, the format, the length of the driver and the driver, the MD5 of
+// the binary and the binary.
+func encodeProgramBinary(driver string, format int, data []byte) []byte {
+	var buf bytes.Buffer
+	buf.Write(sProgramBinaryMagic)
+	binary.Write(&buf, binary.LittleEndian, uint32(programBinaryVersion))
+	binary.Write(&buf, binary.LittleEndian, uint32(format))
+	binary.Write(&buf, binary.LittleEndian, uint32(len(driver)))
+	buf.WriteString(driver)
+	sum := md5.Sum(data)
+	buf.Write(sum[:])
+	buf.Write(data)
+	return buf.Bytes()
+}
+
+func decodeProgramBinary(entry []byte) (driver string, format int, data []byte, err error) {
+	const header = 4 + 3*4
+	if len(entry) < header || !bytes.Equal(entry[:4], sProgramBinaryMagic) {
+		return "", 0, nil, errors.New("glplus: not a program binary")
+	}
+	if version := binary.LittleEndian.Uint32(entry[4:]); version != programBinaryVersion {
+		return "", 0, nil, fmt.Errorf("glplus: program binary version %d, want %d", version, programBinaryVersion)
+	}
+	format = int(binary.LittleEndian.Uint32(entry[8:]))
+	n := int(binary.LittleEndian.Uint32(entry[12:]))
+	rest := entry[header:]
+	if n > len(rest) || len(rest)-n < md5.Size {
+		return "", 0, nil, errors.New("glplus: truncated program binary")
+	}
+	driver, rest = string(rest[:n]), rest[n:]
+	data = rest[md5.Size:]
+	if sum := md5.Sum(data); !bytes.Equal(sum[:], rest[:md5.Size]) {
+		return "", 0, nil, errors.New("glplus: corrupted program binary")
+	}
+	return driver, format, data, nil
+}
+
+// loadProgramBinary links the program of hash from the cache, nil when it
+// is not there or when the driver rejects it.
+func (c *Context) loadProgramBinary(hash string) *Program {
+	format, data, err := c.objects.binaries.load(hash, c.Caps.Driver)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			c.objects.binaries.remove(hash)
+		}
+		return nil
+	}
+	prog := c.CreateProgram()
+	c.ProgramBinary(prog, format, data)
+	if !c.GetProgramParameterb(prog, c.LINK_STATUS) {
+		c.DeleteProgram(prog)
+		c.objects.binaries.remove(hash)
+		return nil
+	}
+	return prog
+}
+
+// storeProgramBinary writes a program linked from its sources to the cache.
+// The cache only saves time: a failure is logged, the program is fine.
+func (c *Context) storeProgramBinary(hash string, prog *Program) {
+	data, format := c.GetProgramBinary(prog)
+	if len(data) == 0 {
+		return
+	}
+	if err := c.objects.binaries.store(hash, c.Caps.Driver, format, data); err != nil {
+		log.Printf("glplus: storing the program binary: %v", err)
+	}
+}
@@ -0,0 +1,160 @@
+package glplus
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestProgramBinaryEncoding(t *testing.T) {
+	entry := encodeProgramBinary("vendor; renderer; 4.1", 0x1234, []byte("binary"))
+	driver, format, data, err := decodeProgramBinary(entry)
+	if err != nil || driver != "vendor; renderer; 4.1" || format != 0x1234 || string(data) != "binary" {
+		t.Fatalf("got %q, %#x, %q, %v", driver, format, data, err)
+	}
+
+	corrupted := append([]byte(nil), entry...)
+	corrupted[len(corrupted)-1] ^= 1
+	version := append([]byte(nil), entry...)
+	version[4] = 2
+	for _, test := range []struct {
+		entry []byte
+		err   string
+	}{
+		{nil, "glplus: not a program binary"},
+		{[]byte("GLPC" + string(entry[4:])), "glplus: not a program binary"},
+		{version, "glplus: program binary version 2, want 1"},
+		{entry[:20], "glplus: truncated program binary"},
+		{entry[:len(entry)-len("binary")-1], "glplus: truncated program binary"},
+		{corrupted, "glplus: corrupted program binary"},
+	} {
+		if _, _, _, err := decodeProgramBinary(test.entry); err == nil || err.Error() != test.err {
+			t.Errorf("%q: got %v, want %s", test.entry, err, test.err)
+		}
+	}
+}
+
+func TestProgramBinaryCache(t *testing.T) {
+	b, err := NewProgramBinaryCache(filepath.Join(t.TempDir(), "programs"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := b.load("hash", "driver"); !os.IsNotExist(err) {
+		t.Errorf("got %v", err)
+	}
+	if err := b.store("hash", "driver", 1, []byte("binary")); err != nil {
+		t.Fatal(err)
+	}
+	if format, data, err := b.load("hash", "driver"); err != nil || format != 1 || string(data) != "binary" {
+		t.Errorf("got %d, %q, %v", format, data, err)
+	}
+	if _, _, err := b.load("hash", "driver 2"); err != errStaleProgramBinary {
+		t.Errorf("got %v", err)
+	}
+	files, _ := filepath.Glob(filepath.Join(b.dir, "*"))
+	if len(files) != 1 || filepath.Base(files[0]) != "hash.bin" {
+		t.Errorf("files %v", files)
+	}
+}
+
+// compileCounter counts the shaders the backend compiles.
+type compileCounter struct {
+	NativeBackend
+	compiles int
+}
+
+func (c *compileCounter) CompileShader(shader *Shader) {
+	c.compiles++
+	c.NativeBackend.CompileShader(shader)
+}
+
+func TestProgramBinaryContext(t *testing.T) {
+	saved := Gl
+	defer func() { Gl = saved }()
+	dir := t.TempDir()
+	start := func() *compileCounter {
+		counter := &compileCounter{NativeBackend: NewSoftBackend(16, 16)}
+		Gl = NewBackendContext(counter)
+		cache, err := NewProgramBinaryCache(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		Gl.SetProgramBinaryCache(cache)
+		return counter
+	}
+	load := func() {
+		t.Helper()
+		prog, err := LoadShaderProgram(sVertShaderUniforms, sFragShaderUniforms, []string{"position", "uvs"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if prog.ActiveUniform("weights") == nil {
+			t.Errorf("uniforms %v", prog.ActiveUniforms())
+		}
+		prog.DeleteProgram()
+		if err := Gl.CheckLeaks(); err != nil {
+			t.Error(err)
+		}
+	}
+	entries := func() []string {
+		files, _ := filepath.Glob(filepath.Join(dir, "*"))
+		return files
+	}
+
+	// compiled and stored at the first start, loaded at the next ones
+	counter := start()
+	load()
+	if counter.compiles != 2 || len(entries()) != 1 {
+		t.Fatalf("%d compiles, entries %v", counter.compiles, entries())
+	}
+	counter = start()
+	load()
+	if counter.compiles != 0 {
+		t.Errorf("%d compiles from the cache", counter.compiles)
+	}
+
+	// a binary the driver rejects is replaced
+	entry := entries()[0]
+	os.WriteFile(entry, encodeProgramBinary(Gl.Caps.Driver, softProgramBinaryFormat, []byte("{}")), 0644)
+	counter = start()
+	load()
+	if counter.compiles != 2 {
+		t.Errorf("%d compiles from a rejected binary", counter.compiles)
+	}
+	counter = start()
+	load()
+	if counter.compiles != 0 {
+		t.Errorf("%d compiles once replaced", counter.compiles)
+	}
+
+	// as is the one of another driver, or a corrupted one
+	counter = start()
+	Gl.Caps.Driver += " updated"
+	load()
+	if counter.compiles != 2 {
+		t.Errorf("%d compiles from a stale binary", counter.compiles)
+	}
+	os.WriteFile(entry, []byte("GLPB"), 0644)
+	counter = start()
+	load()
+	if counter.compiles != 2 || len(entries()) != 1 {
+		t.Errorf("%d compiles from a corrupted binary, entries %v", counter.compiles, entries())
+	}
+
+	// a program restored after a context loss loads from the cache
+	prog, err := LoadShaderProgram(sVertShaderUniforms, sFragShaderUniforms, []string{"position", "uvs"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer prog.DeleteProgram()
+	counter.compiles = 0
+	if err := Gl.RestoreContext(); err != nil {
+		t.Fatal(err)
+	}
+	if counter.compiles != 0 || prog.ActiveUniform("weights") == nil {
+		t.Errorf("%d compiles to restore", counter.compiles)
+	}
+	if err := Gl.GetError(); err != Gl.NO_ERROR {
+		t.Errorf("GL error 0x%X", err)
+	}
+}
@@ -5,8 +5,6 @@ import (
 	"encoding/hex"
 )
 
-var sProgCache map[string]*ProgramCache
-
 // ProgramCache ...
 type ProgramCache struct {
 	ReleasingReferenceCount
@@ -17,7 +15,9 @@ type ProgramCache struct {
 // Delete ...
 func (b *ProgramCache) Delete() {
 	if b.program != nil {
-		b.program.DeleteProgram()
+		b.program.ctx.DeleteProgram(b.program.prog)
+		b.program.ctx.Unregister(b.program)
+		b.program = nil
 	}
 }
 
@@ -27,3 +27,17 @@ func getMD5Hash(text1 string, text2 string) string {
 	hasher.Write([]byte(text2))
 	return hex.EncodeToString(hasher.Sum(nil))
 }
+
+// programHash is the key of translated sources in the program cache: the
+// one of a vertex and fragment program is getMD5Hash of their texts, the
+// other programs hash the names of their stages too.
+func programHash(sources []stageSource) string {
+	if len(sources) == 2 && sources[0].stage == VertexStage && sources[1].stage == FragmentStage {
+		return getMD5Hash(sources[0].out.Text, sources[1].out.Text)
+	}
+	hasher := md5.New()
+	for _, src := range sources {
+		hasher.Write([]byte(src.stage.String() + "\x00" + src.out.Text + "\x00"))
+	}
+	return hex.EncodeToString(hasher.Sum(nil))
+}
@@ -0,0 +1,216 @@
+package glplus
+
+import (
+	"fmt"
+	"io/fs"
+	"log"
+	"time"
+
+	"github.com/aubonbeurre/glplus/glsl"
+)
+
+// ShaderReloader loads programs from shader files, in an fs.FS such as
+// os.DirFS("shaders"), and builds them again when the files, or the ones they
+// #include, change. A program whose new sources fail to build keeps its last
+// good version, and OnError gets the error.
+//
+// The reloaded programs are the GPProgram values Load returned, shared through
+// the program cache as the ones of LoadShaderProgram: whoever holds one draws
+// with the new version.
+type ShaderReloader struct {
+	// OnError is called with the errors of the reloads. They are logged
+	// when it is nil.
+	OnError func(p *GPProgram, err error)
+	// Interval is the least time between two checks of the files by Poll,
+	// which checks them at every call when it is 0.
+	Interval time.Duration
+
+	ctx      *Context
+	fsys     fs.FS
+	programs []*reloadedProgram
+	polled   time.Time
+}
+
+// reloadedProgram is a program of a reloader, with the files it was last
+// built from.
+type reloadedProgram struct {
+	program  *GPProgram
+	vertPath string
+	fragPath string
+	files    map[string]string
+}
+
+// NewShaderReloader creates a reloader of the shader files of fsys on the
+// default context Gl.
+func NewShaderReloader(fsys fs.FS) *ShaderReloader {
+	return Gl.NewShaderReloader(fsys)
+}
+
+// NewShaderReloader creates a reloader of the shader files of fsys.
+func (c *Context) NewShaderReloader(fsys fs.FS) *ShaderReloader {
+	return &ShaderReloader{ctx: c, fsys: fsys}
+}
+
+// Load loads a program from the vertex and fragment shader files, as
+// LoadShaderProgram does from sources. An #include is a file of fsys, the
+// path being from its root, or else a source registered with
+// RegisterShaderInclude.
+func (r *ShaderReloader) Load(vertPath, fragPath string, attribs []string) (*GPProgram, error) {
+	vert, frag, files, err := r.read(vertPath, fragPath)
+	if err != nil {
+		return nil, err
+	}
+	p, err := r.ctx.loadProgram(vertexFragment(vert, frag), attribs)
+	if err != nil {
+		return nil, err
+	}
+	for _, rp := range r.programs {
+		if rp.program == p && rp.vertPath == vertPath && rp.fragPath == fragPath {
+			return p, nil
+		}
+	}
+	r.programs = append(r.programs, &reloadedProgram{
+		program:  p,
+		vertPath: vertPath,
+		fragPath: fragPath,
+		files:    files,
+	})
+	return p, nil
+}
+
+// Poll builds again the programs whose files changed since they were last
+// read, and returns how many it did. It runs GL calls: call it on the thread
+// of the context, once per frame. The programs deleted since the last Poll
+// are forgotten.
+func (r *ShaderReloader) Poll() int {
+	if r.Interval > 0 {
+		now := time.Now()
+		if now.Sub(r.polled) < r.Interval {
+			return 0
+		}
+		r.polled = now
+	}
+	reloaded := 0
+	programs := r.programs[:0]
+	for _, rp := range r.programs {
+		if !r.ctx.cachedProgram(rp.program) {
+			continue
+		}
+		programs = append(programs, rp)
+		if !r.changed(rp) {
+			continue
+		}
+		if err := r.reload(rp); err != nil {
+			err = fmt.Errorf("glplus: reloading %s and %s: %w", rp.vertPath, rp.fragPath, err)
+			if r.OnError != nil {
+				r.OnError(rp.program, err)
+			} else {
+				log.Println(err)
+			}
+			continue
+		}
+		reloaded++
+	}
+	r.programs = programs
+	return reloaded
+}
+
+// changed tells whether one of the files of a program changed. A file that
+// cannot be read reads as empty.
+func (r *ShaderReloader) changed(rp *reloadedProgram) bool {
+	for path, src := range rp.files {
+		b, _ := fs.ReadFile(r.fsys, path)
+		if string(b) != src {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *ShaderReloader) reload(rp *reloadedProgram) error {
+	vert, frag, files, err := r.read(rp.vertPath, rp.fragPath)
+	// a failure is reported once, until the files change again
+	rp.files = files
+	if err != nil {
+		return err
+	}
+	return rp.program.rebuild(vertexFragment(vert, frag))
+}
+
+// read reads and translates the sources of a program, and returns the files
+// they were read from.
+func (r *ShaderReloader) read(vertPath, fragPath string) (vert, frag *glsl.Output, files map[string]string, err error) {
+	includer := &fileIncluder{fsys: r.fsys, files: make(map[string]string)}
+	src, err := includer.read(vertPath)
+	if err != nil {
+		return nil, nil, includer.files, err
+	}
+	if vert, err = r.ctx.preprocess(VertexStage, vertPath, src, includer, nil); err != nil {
+		return nil, nil, includer.files, err
+	}
+	if src, err = includer.read(fragPath); err != nil {
+		return nil, nil, includer.files, err
+	}
+	if frag, err = r.ctx.preprocess(FragmentStage, fragPath, src, includer, nil); err != nil {
+		return nil, nil, includer.files, err
+	}
+	return vert, frag, includer.files, nil
+}
+
+// fileIncluder includes the files of a file system, or else the registered
+// sources, and records the files it read.
+type fileIncluder struct {
+	fsys  fs.FS
+	files map[string]string
+}
+
+func (i *fileIncluder) read(path string) (string, error) {
+	b, err := fs.ReadFile(i.fsys, path)
+	// a missing file is recorded too, to be read when it shows up
+	i.files[path] = string(b)
+	return string(b), err
+}
+
+// Include ...
+func (i *fileIncluder) Include(name string) (string, error) {
+	if !fs.ValidPath(name) {
+		return sShaderIncludes.Include(name)
+	}
+	src, err := i.read(name)
+	if err != nil {
+		return sShaderIncludes.Include(name)
+	}
+	return src, nil
+}
+
+// cachedProgram tells whether p is in the program cache, not deleted yet.
+func (c *Context) cachedProgram(p *GPProgram) bool {
+	cache := c.objects.progCache[p.hash]
+	return cache != nil && cache.program == p
+}
+
+// rebuild links new translated sources in place of the ones of the program,
+// which is left as it was when they fail to build.
+func (p *GPProgram) rebuild(sources []stageSource) error {
+	if sameSources(sources, p.sources) {
+		return nil
+	}
+	c := p.ctx
+	prog, err := c.linkProgram(sources)
+	if err != nil {
+		return err
+	}
+	c.DeleteProgram(p.prog)
+	p.prog, p.sources = prog, sources
+
+	// the cache finds the program by its new sources, unless another
+	// program has them already
+	cache := c.objects.progCache[p.hash]
+	delete(c.objects.progCache, p.hash)
+	p.hash = programHash(sources)
+	if c.objects.progCache[p.hash] != nil {
+		p.hash = fmt.Sprintf("%s@%p", p.hash, p)
+	}
+	c.objects.progCache[p.hash] = cache
+	return p.linked()
+}
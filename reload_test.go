@@ -0,0 +1,128 @@
+package glplus
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+const (
+	sVertShaderFile = `#version 330
+  #include "reload/transform.glsl"
+  ATTRIBUTE vec4 position;
+  void main()
+  {
+    gl_Position = transform(position);
+  }`
+
+	sFragShaderFile = `#version 330
+  uniform vec4 color;
+  COLOROUT
+  void main()
+  {
+    FRAGCOLOR = color;
+  }`
+)
+
+func TestShaderReloader(t *testing.T) {
+	defer softContext(t)()
+
+	fsys := fstest.MapFS{
+		"obj.vert":              {Data: []byte(sVertShaderFile)},
+		"obj.frag":              {Data: []byte(sFragShaderFile)},
+		"reload/transform.glsl": {Data: []byte("uniform mat4 mvp;\nvec4 transform(vec4 p) { return mvp * p; }\n")},
+	}
+	var errs []error
+	r := NewShaderReloader(fsys)
+	r.OnError = func(p *GPProgram, err error) { errs = append(errs, err) }
+
+	prog, err := r.Load("obj.vert", "obj.frag", []string{"position"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// LoadShaderProgram shares it
+	RegisterShaderInclude("reload/transform.glsl", string(fsys["reload/transform.glsl"].Data))
+	same, err := LoadShaderProgram(sVertShaderFile, sFragShaderFile, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if same != prog {
+		t.Errorf("not shared with LoadShaderProgram")
+	}
+	same.DeleteProgram()
+
+	if n := r.Poll(); n != 0 {
+		t.Errorf("%d reloaded, unchanged", n)
+	}
+
+	// a change of the fragment shader
+	old := prog.prog
+	alpha := strings.NewReplacer("color;", "color;\n  uniform float alpha;", "= color", "= color * alpha").Replace(sFragShaderFile)
+	fsys["obj.frag"] = &fstest.MapFile{Data: []byte(alpha)}
+	if n := r.Poll(); n != 1 || len(errs) != 0 {
+		t.Fatalf("%d reloaded, %v", n, errs)
+	}
+	if prog.prog == old || prog.ActiveUniform("alpha") == nil {
+		t.Errorf("not reloaded, uniforms %v", prog.ActiveUniforms())
+	}
+	if live := Gl.LiveResources("Program"); len(live) != 1 || live[0].Handle != prog.prog {
+		t.Errorf("live programs %v", live)
+	}
+
+	// a broken include keeps the last good program, and is reported once
+	good := prog.prog
+	fsys["reload/transform.glsl"] = &fstest.MapFile{Data: []byte("vec4 transform(vec4 p) { return mvp * p; }\n")}
+	if n := r.Poll(); n != 0 || len(errs) != 1 {
+		t.Fatalf("%d reloaded, %v", n, errs)
+	}
+	if msg := errs[0].Error(); !strings.HasPrefix(msg, "glplus: reloading obj.vert and obj.frag: Failed to compile the vertex shader!") {
+		t.Errorf("got %v", msg)
+	}
+	if prog.prog != good || prog.ActiveUniform("mvp") == nil {
+		t.Errorf("last good program lost")
+	}
+	r.Poll()
+	if len(errs) != 1 {
+		t.Errorf("reported again: %v", errs)
+	}
+	if live := Gl.LiveResources("Program"); len(live) != 1 {
+		t.Errorf("live programs %v", live)
+	}
+	prog.UseProgram()
+	if err := Gl.GetError(); err != Gl.NO_ERROR {
+		t.Errorf("GL error 0x%X", err)
+	}
+
+	// a missing file is reported, then read when it shows up
+	delete(fsys, "reload/transform.glsl")
+	delete(fsys, "obj.frag")
+	r.Poll()
+	if len(errs) != 2 || !strings.Contains(errs[1].Error(), "obj.frag") {
+		t.Errorf("got %v", errs)
+	}
+	fsys["obj.frag"] = &fstest.MapFile{Data: []byte(sFragShaderFile)}
+	if n := r.Poll(); n != 1 || len(errs) != 2 {
+		t.Fatalf("%d reloaded, %v", n, errs)
+	}
+	// from the registered include
+	if prog.ActiveUniform("alpha") != nil || prog.ActiveUniform("mvp") == nil {
+		t.Errorf("uniforms %v", prog.ActiveUniforms())
+	}
+
+	// the cache finds the program by its new sources
+	same, err = LoadShaderProgram(sVertShaderFile, sFragShaderFile, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if same != prog {
+		t.Errorf("reloaded program not found in the cache")
+	}
+	same.DeleteProgram()
+	prog.DeleteProgram()
+	if err := Gl.CheckLeaks(); err != nil {
+		t.Error(err)
+	}
+	if len(r.programs) != 1 || r.Poll() != 0 || len(r.programs) != 0 {
+		t.Errorf("deleted program not forgotten")
+	}
+}
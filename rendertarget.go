@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"image"
 	"image/color"
-	"unsafe"
 )
 
 // RenderTarget ...
@@ -16,63 +15,73 @@ type RenderTarget struct {
 	zbuffer  *RenderBuffer
 	hasDepth bool
 	Tex      *GPTexture
+	ctx      *Context
 }
 
 // Delete ...
 func (r *RenderTarget) Delete() {
 	if r.fbuffer != nil {
-		Gl.DeleteFrameBuffer(r.fbuffer)
+		r.ctx.DeleteFramebuffer(r.fbuffer)
 	}
 	if r.rbuffer != nil {
-		Gl.DeleteRenderBuffer(r.rbuffer)
+		r.ctx.DeleteRenderbuffer(r.rbuffer)
 	}
 	if r.zbuffer != nil {
-		Gl.DeleteRenderBuffer(r.zbuffer)
+		r.ctx.DeleteRenderbuffer(r.zbuffer)
 	}
 	if r.Tex != nil {
 		r.Tex.DeleteTexture()
 	}
+	r.ctx.Unregister(r)
 }
 
 // EnsureSize ...
-func (r *RenderTarget) EnsureSize(size image.Point) {
+func (r *RenderTarget) EnsureSize(size image.Point) error {
+	if size.X > r.ctx.Caps.MaxTextureSize || size.Y > r.ctx.Caps.MaxTextureSize {
+		return fmt.Errorf("glplus: render target %dx%d exceeds the maximum texture size %d", size.X, size.Y, r.ctx.Caps.MaxTextureSize)
+	}
+	if r.hasDepth && (size.X > r.ctx.Caps.MaxRenderbufferSize || size.Y > r.ctx.Caps.MaxRenderbufferSize) {
+		return fmt.Errorf("glplus: render target %dx%d exceeds the maximum renderbuffer size %d", size.X, size.Y, r.ctx.Caps.MaxRenderbufferSize)
+	}
 	if r.Tex == nil || r.Tex.Size.X != size.X || r.Tex.Size.Y != size.Y {
 		if r.Tex != nil {
 			r.Tex.DeleteTexture()
 		}
-		r.Tex = GenTexture(image.Point{size.X, size.Y})
-		r.Tex.BindTexture(0)
-		Gl.TexParameteri(Gl.TEXTURE_2D, Gl.TEXTURE_MIN_FILTER, Gl.NEAREST)
-		Gl.TexParameteri(Gl.TEXTURE_2D, Gl.TEXTURE_MAG_FILTER, Gl.NEAREST)
-		Gl.TexParameteri(Gl.TEXTURE_2D, Gl.TEXTURE_WRAP_S, Gl.CLAMP_TO_EDGE)
-		Gl.TexParameteri(Gl.TEXTURE_2D, Gl.TEXTURE_WRAP_T, Gl.CLAMP_TO_EDGE)
-		Gl.TexImage2D(
-			Gl.TEXTURE_2D,
-			0,
-			Gl.RGBA,
-			size.X,
-			size.Y,
-			Gl.RGBA,
-			Gl.UNSIGNED_BYTE,
-			nil)
-		r.Tex.UnbindTexture(0)
+		r.Tex = r.ctx.GenTexture(image.Point{size.X, size.Y})
+		// the content is not kept, a restored target is rendered again
+		r.Tex.SetUpload(func(c *Context) {
+			c.TexParameteri(c.TEXTURE_2D, c.TEXTURE_MIN_FILTER, c.NEAREST)
+			c.TexParameteri(c.TEXTURE_2D, c.TEXTURE_MAG_FILTER, c.NEAREST)
+			c.TexParameteri(c.TEXTURE_2D, c.TEXTURE_WRAP_S, c.CLAMP_TO_EDGE)
+			c.TexParameteri(c.TEXTURE_2D, c.TEXTURE_WRAP_T, c.CLAMP_TO_EDGE)
+			c.TexImage2D(
+				c.TEXTURE_2D,
+				0,
+				c.RGBA,
+				size.X,
+				size.Y,
+				c.RGBA,
+				c.UNSIGNED_BYTE,
+				nil)
+		})
 	}
+	return nil
 }
 
-func checkFramebufferStatus() string {
-	status := Gl.CheckFramebufferStatus(Gl.FRAMEBUFFER)
+func checkFramebufferStatus(c *Context) string {
+	status := c.CheckFramebufferStatus(c.FRAMEBUFFER)
 	switch status {
-	case Gl.FRAMEBUFFER_COMPLETE:
+	case c.FRAMEBUFFER_COMPLETE:
 		return "OK"
-	case Gl.FRAMEBUFFER_INCOMPLETE_ATTACHMENT:
+	case c.FRAMEBUFFER_INCOMPLETE_ATTACHMENT:
 		return "Framebuffer incomplete, incomplete attachment!"
-	case Gl.FRAMEBUFFER_INCOMPLETE_MISSING_ATTACHMENT:
+	case c.FRAMEBUFFER_INCOMPLETE_MISSING_ATTACHMENT:
 		return "Framebuffer incomplete, missing attachment!"
-	case Gl.FRAMEBUFFER_INCOMPLETE_DRAW_BUFFER:
+	case c.FRAMEBUFFER_INCOMPLETE_DRAW_BUFFER:
 		return "Framebuffer incomplete, missing draw buffer!"
-	case Gl.FRAMEBUFFER_INCOMPLETE_READ_BUFFER:
+	case c.FRAMEBUFFER_INCOMPLETE_READ_BUFFER:
 		return "Framebuffer incomplete, missing read buffer!"
-	case Gl.FRAMEBUFFER_UNSUPPORTED:
+	case c.FRAMEBUFFER_UNSUPPORTED:
 		return "Unsupported framebuffer format!"
 	}
 	panic(fmt.Errorf("Unknown error %d", status))
@@ -81,38 +90,38 @@ func checkFramebufferStatus() string {
 // Bind ...
 func (r *RenderTarget) Bind(tex *GPTexture) {
 	// Bind the frame-buffer object and attach to it a render-buffer object set up as a depth-buffer.
-	Gl.BindFrameBuffer(Gl.FRAMEBUFFER, r.fbuffer)
+	r.ctx.BindFramebuffer(r.ctx.FRAMEBUFFER, r.fbuffer)
 
 	if r.hasDepth {
-		Gl.BindRenderBuffer(Gl.RENDERBUFFER, r.zbuffer)
-		Gl.RenderbufferStorage(Gl.RENDERBUFFER, Gl.DEPTH_COMPONENT, tex.Size.X, tex.Size.Y)
+		r.ctx.BindRenderbuffer(r.ctx.RENDERBUFFER, r.zbuffer)
+		r.ctx.RenderbufferStorage(r.ctx.RENDERBUFFER, r.ctx.DEPTH_COMPONENT, tex.Size.X, tex.Size.Y)
 	}
 
-	Gl.FramebufferTexture2D(Gl.FRAMEBUFFER, Gl.COLOR_ATTACHMENT0, Gl.TEXTURE_2D, tex.Handle(), 0)
+	r.ctx.FramebufferTexture2D(r.ctx.FRAMEBUFFER, r.ctx.COLOR_ATTACHMENT0, r.ctx.TEXTURE_2D, tex.Handle(), 0)
 
 	// Set the render target - primary surface
-	Gl.DrawBuffer(Gl.COLOR_ATTACHMENT0)
+	r.ctx.DrawBuffer(r.ctx.COLOR_ATTACHMENT0)
 
-	status := checkFramebufferStatus()
+	status := checkFramebufferStatus(r.ctx)
 	if status != "OK" {
-		Gl.BindFrameBuffer(Gl.FRAMEBUFFER, nil)
+		r.ctx.BindFramebuffer(r.ctx.FRAMEBUFFER, nil)
 		panic(fmt.Errorf("Canot continue error %s", status))
 	}
 }
 
 // Unbind ...
 func (r *RenderTarget) Unbind(tex *GPTexture) {
-	Gl.BindFrameBuffer(Gl.FRAMEBUFFER, nil)
-	Gl.Flush()
+	r.ctx.BindFramebuffer(r.ctx.FRAMEBUFFER, nil)
+	r.ctx.Flush()
 }
 
 // ReadBuffer ...
 func (r *RenderTarget) ReadBuffer(w, h int) (newImage *image.RGBA) {
-	Gl.Flush()
-	Gl.ReadBuffer(Gl.COLOR_ATTACHMENT0)
+	r.ctx.Flush()
+	r.ctx.ReadBuffer(r.ctx.COLOR_ATTACHMENT0)
 
 	var pix = make([]float32, w*h*4)
-	Gl.ReadPixels(0, 0, w, h, Gl.RGBA, Gl.FLOAT, unsafe.Pointer(&pix[0]))
+	r.ctx.ReadPixels(0, 0, w, h, r.ctx.RGBA, r.ctx.FLOAT, pix)
 
 	newImage = image.NewRGBA(image.Rect(0, 0, w, h))
 	for j := 0; j < h; j++ {
@@ -124,38 +133,57 @@ func (r *RenderTarget) ReadBuffer(w, h int) (newImage *image.RGBA) {
 	return newImage
 }
 
-// NewRenderTarget ...
-func NewRenderTarget(hasDepth bool) (r *RenderTarget) {
+// NewRenderTarget creates a target on the default context Gl.
+func NewRenderTarget(size image.Point, hasDepth bool) (r *RenderTarget, err error) {
+	return Gl.NewRenderTarget(size, hasDepth)
+}
+
+// NewRenderTarget returns a target of size, it fails when size exceeds the
+// limits in c.Caps.
+func (c *Context) NewRenderTarget(size image.Point, hasDepth bool) (r *RenderTarget, err error) {
+	r = &RenderTarget{
+		hasDepth: hasDepth,
+		ctx:      c,
+	}
+	if err = r.EnsureSize(size); err != nil {
+		return nil, err
+	}
+	r.create()
+	c.Register(r)
+	return r, nil
+}
+
+// Restore re-creates the buffers after the context was lost.
+func (r *RenderTarget) Restore() error {
+	r.create()
+	return nil
+}
+
+func (r *RenderTarget) create() {
 	const msaa = 4
 	var rbuffer, zbuffer *RenderBuffer
 	//now create the color render buffer
-	rbuffer = Gl.CreateRenderBuffer()
-	Gl.BindRenderBuffer(Gl.RENDERBUFFER, rbuffer)
-	//Gl.RenderbufferStorageMultisample(Gl.RENDERBUFFER, msaa, GL_RGB8, width, height);
-
-	if hasDepth {
-		zbuffer = Gl.CreateRenderBuffer()
-		Gl.BindRenderBuffer(Gl.RENDERBUFFER, zbuffer)
-		//Gl.RenderbufferStorageMultisample(Gl.RENDERBUFFER, msaa, GL_DEPTH_COMPONENT, width, height);
+	rbuffer = r.ctx.CreateRenderbuffer()
+	r.ctx.BindRenderbuffer(r.ctx.RENDERBUFFER, rbuffer)
+	//r.ctx.RenderbufferStorageMultisample(r.ctx.RENDERBUFFER, msaa, GL_RGB8, width, height);
+
+	if r.hasDepth {
+		zbuffer = r.ctx.CreateRenderbuffer()
+		r.ctx.BindRenderbuffer(r.ctx.RENDERBUFFER, zbuffer)
+		//r.ctx.RenderbufferStorageMultisample(r.ctx.RENDERBUFFER, msaa, GL_DEPTH_COMPONENT, width, height);
 	}
 
 	var fbuffer *FrameBuffer
 	//create the color buffer to render to
-	fbuffer = Gl.CreateFrameBuffer()
-	Gl.BindFrameBuffer(Gl.FRAMEBUFFER, fbuffer)
+	fbuffer = r.ctx.CreateFramebuffer()
+	r.ctx.BindFramebuffer(r.ctx.FRAMEBUFFER, fbuffer)
 
-	Gl.FramebufferRenderbuffer(Gl.FRAMEBUFFER, Gl.COLOR_ATTACHMENT0, Gl.RENDERBUFFER, rbuffer)
-	if hasDepth {
-		Gl.FramebufferRenderbuffer(Gl.FRAMEBUFFER, Gl.DEPTH_ATTACHMENT, Gl.RENDERBUFFER, zbuffer)
+	r.ctx.FramebufferRenderbuffer(r.ctx.FRAMEBUFFER, r.ctx.COLOR_ATTACHMENT0, r.ctx.RENDERBUFFER, rbuffer)
+	if r.hasDepth {
+		r.ctx.FramebufferRenderbuffer(r.ctx.FRAMEBUFFER, r.ctx.DEPTH_ATTACHMENT, r.ctx.RENDERBUFFER, zbuffer)
 	}
 
-	Gl.BindFrameBuffer(Gl.FRAMEBUFFER, nil)
+	r.ctx.BindFramebuffer(r.ctx.FRAMEBUFFER, nil)
 
-	r = &RenderTarget{
-		fbuffer:  fbuffer,
-		rbuffer:  rbuffer,
-		zbuffer:  zbuffer,
-		hasDepth: hasDepth,
-	}
-	return r
+	r.fbuffer, r.rbuffer, r.zbuffer = fbuffer, rbuffer, zbuffer
 }
@@ -0,0 +1,90 @@
+package glplus
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Restorable is a resource that can re-create its GPU objects, from the CPU
+// data it retained, after the context was lost.
+type Restorable interface {
+	Restore() error
+}
+
+// Restorer keeps the resources of a Context that must be re-created when the
+// context comes back after a loss: a WebGL canvas losing its context, or an
+// Android app paused then resumed. GPTexture, VBO, GPProgram, the textures
+// and strings of a Font and RenderTarget register themselves, other
+// resources can register a Restorable or a rebuild callback with OnRestore.
+type Restorer struct {
+	// OnLost is called when the context is lost.
+	OnLost func()
+	// OnRestored is called once the resources were re-created, with the
+	// error of RestoreContext.
+	OnRestored func(err error)
+
+	restorables map[Restorable]int
+	seq         int
+}
+
+// Register adds r to the resources re-created by RestoreContext.
+func (r *Restorer) Register(res Restorable) {
+	if r.restorables == nil {
+		r.restorables = make(map[Restorable]int)
+	}
+	if _, ok := r.restorables[res]; ok {
+		return
+	}
+	r.seq++
+	r.restorables[res] = r.seq
+}
+
+// Unregister removes r, resources call it when they are deleted.
+func (r *Restorer) Unregister(res Restorable) {
+	delete(r.restorables, res)
+}
+
+type restoreFunc struct {
+	rebuild func() error
+}
+
+func (f *restoreFunc) Restore() error {
+	return f.rebuild()
+}
+
+// OnRestore registers a rebuild callback, the returned function unregisters
+// it.
+func (r *Restorer) OnRestore(rebuild func() error) (cancel func()) {
+	f := &restoreFunc{rebuild}
+	r.Register(f)
+	return func() { r.Unregister(f) }
+}
+
+// restore re-creates the resources in registration order, so a program is
+// back before the vertex arrays that read its attributes. It goes on after
+// an error and returns the first one.
+func (r *Restorer) restore() (err error) {
+	res := make([]Restorable, 0, len(r.restorables))
+	for k := range r.restorables {
+		res = append(res, k)
+	}
+	sort.Slice(res, func(i, j int) bool { return r.restorables[res[i]] < r.restorables[res[j]] })
+	for _, k := range res {
+		if e := k.Restore(); e != nil && err == nil {
+			err = fmt.Errorf("glplus: cannot restore %T: %v", k, e)
+		}
+	}
+	return err
+}
+
+func (r *Restorer) lost() {
+	if r.OnLost != nil {
+		r.OnLost()
+	}
+}
+
+func (r *Restorer) restored(err error) {
+	if r.OnRestored != nil {
+		r.OnRestored(err)
+	}
+}
@@ -0,0 +1,221 @@
+package glplus
+
+import (
+	"image"
+	"image/color"
+	"os"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+var (
+	sVertShaderRestore = `#version 330
+  ATTRIBUTE vec3 position;
+  ATTRIBUTE vec2 uvs;
+  VARYINGOUT vec2 out_uvs;
+
+  void main()
+  {
+      gl_Position = vec4(position.xy * 2.0 - 1.0, 0.0, 1.0);
+      out_uvs = uvs;
+  }`
+
+	sFragShaderRestore = `#version 330
+  uniform sampler2D tex1;
+  VARYINGIN vec2 out_uvs;
+  COLOROUT
+
+  void main(void)
+  {
+  	FRAGCOLOR = TEXTURE2D(tex1, out_uvs);
+  }`
+)
+
+func drawRestoreQuad(t *testing.T, rt *RenderTarget, tex *GPTexture, prog *GPProgram, vbo *VBO, size int) *image.RGBA {
+	rt.Bind(rt.Tex)
+	Gl.Viewport(0, 0, size, size)
+	Gl.ClearColor(0, 0, 0, 1)
+	Gl.Clear(Gl.COLOR_BUFFER_BIT | Gl.DEPTH_BUFFER_BIT)
+
+	prog.UseProgram()
+	tex.BindTexture(0)
+	prog.ProgramUniform1i("tex1", 0)
+	vbo.Bind(prog)
+	vbo.Draw()
+	vbo.Unbind(prog)
+	tex.UnbindTexture(0)
+	prog.UnuseProgram()
+	checkGlError(t)
+
+	img := rt.ReadBuffer(size, size)
+	rt.Unbind(rt.Tex)
+	return img
+}
+
+func TestRestoreContext(t *testing.T) {
+	defer softContext(t)()
+	soft := Gl.NativeBackend.(*softBackend)
+
+	const size = 16
+	rt, err := NewRenderTarget(image.Point{size, size}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	src := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	src.SetRGBA(0, 0, color.RGBA{255, 0, 0, 255})
+	src.SetRGBA(1, 0, color.RGBA{0, 255, 0, 255})
+	src.SetRGBA(0, 1, color.RGBA{0, 0, 255, 255})
+	src.SetRGBA(1, 1, color.RGBA{255, 255, 255, 255})
+	tex, err := NewRGBATexture(src, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	prog, err := LoadShaderProgram(sVertShaderRestore, sFragShaderRestore, []string{"position", "uvs"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	vbo := NewVBOQuad(prog, 0, 0, 1, 1)
+
+	fontReader, err := os.Open("FreeSerif.ttf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fontReader.Close()
+	font, err := NewFont(fontReader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	str := font.NewString("lost")
+	if err = str.Draw(font, [4]float32{1, 1, 1, 1}, [4]float32{}, mgl32.Ident3(), 1, 0, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	var rebuilt int
+	cancel := Gl.OnRestore(func() error {
+		rebuilt++
+		return nil
+	})
+	var restored bool
+	Gl.OnRestored = func(err error) { restored = err == nil }
+
+	want := drawRestoreQuad(t, rt, tex, prog, vbo, size)
+	if c := want.RGBAAt(size/4, size/4); c != src.RGBAAt(1, 0) {
+		t.Fatalf("quadrant %v", c)
+	}
+
+	soft.LoseContext()
+	if err := Gl.GetError(); err != Gl.CONTEXT_LOST_WEBGL {
+		t.Fatalf("got error %d after the loss", err)
+	}
+	prog.UseProgram()
+	if err := Gl.GetError(); err == Gl.NO_ERROR {
+		t.Fatal("the program survived the loss")
+	}
+
+	if err = Gl.RestoreContext(); err != nil {
+		t.Fatal(err)
+	}
+	if rebuilt != 1 || !restored {
+		t.Errorf("rebuilt %d times, restored %v", rebuilt, restored)
+	}
+
+	if got := drawRestoreQuad(t, rt, tex, prog, vbo, size); string(got.Pix) != string(want.Pix) {
+		t.Errorf("restored quad differs")
+	}
+	if err = str.Draw(font, [4]float32{1, 1, 1, 1}, [4]float32{}, mgl32.Ident3(), 1, 0, 0); err != nil {
+		t.Fatal(err)
+	}
+	checkGlError(t)
+
+	cancel()
+	str.DeleteString()
+	font.DeleteFont()
+	vbo.DeleteVBO()
+	prog.DeleteProgram()
+	tex.DeleteTexture()
+	rt.Delete()
+	if err = Gl.CheckLeaks(); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(Gl.restorables); n != 0 {
+		t.Errorf("%d resources still registered", n)
+	}
+}
+
+func TestRestoreVBOData(t *testing.T) {
+	defer softContext(t)()
+	soft := Gl.NativeBackend.(*softBackend)
+
+	const size = 16
+	rt, err := NewRenderTarget(image.Point{size, size}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rt.Delete()
+	src := image.NewRGBA(image.Rect(0, 0, 1, 1))
+	src.SetRGBA(0, 0, color.RGBA{0, 255, 0, 255})
+	tex, err := NewRGBATexture(src, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tex.DeleteTexture()
+	prog, err := LoadShaderProgram(sVertShaderRestore+"\n// data", sFragShaderRestore, []string{"position", "uvs"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer prog.DeleteProgram()
+
+	// the caller reuses its slices after NewVBO and Update
+	verts := []float32{
+		0, 0, 0, 0, 0,
+		0.5, 0, 0, 0, 0,
+		0.5, 1, 0, 0, 0,
+		0, 1, 0, 0, 0,
+	}
+	indices := []uint32{0, 1, 2, 2, 3, 0}
+	vbo, err := NewVBO(prog, DefaultVBOOptions(), verts, indices)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer vbo.DeleteVBO()
+	for i := range verts {
+		verts[i] = 0
+	}
+	for i := range indices {
+		indices[i] = 0
+	}
+	left := drawRestoreQuad(t, rt, tex, prog, vbo, size)
+	if c := left.RGBAAt(size/4, size/2); c.G != 255 {
+		t.Fatalf("left half %v", c)
+	}
+
+	updated := []float32{
+		0.5, 0, 0, 0, 0,
+		1, 0, 0, 0, 0,
+		1, 1, 0, 0, 0,
+		0.5, 1, 0, 0, 0,
+	}
+	if err := vbo.Update(updated, []uint32{0, 1, 2, 2, 3, 0}); err != nil {
+		t.Fatal(err)
+	}
+	want := drawRestoreQuad(t, rt, tex, prog, vbo, size)
+	for i := range updated {
+		updated[i] = 0
+	}
+
+	soft.LoseContext()
+	if err := Gl.GetError(); err != Gl.CONTEXT_LOST_WEBGL {
+		t.Fatalf("got error %d after the loss", err)
+	}
+	if err = Gl.RestoreContext(); err != nil {
+		t.Fatal(err)
+	}
+	if got := drawRestoreQuad(t, rt, tex, prog, vbo, size); string(got.Pix) != string(want.Pix) {
+		t.Errorf("the restored VBO does not hold the data last uploaded")
+	}
+	if c := want.RGBAAt(3*size/4, size/2); c.G != 255 {
+		t.Errorf("right half %v", c)
+	}
+}
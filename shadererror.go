@@ -0,0 +1,144 @@
+package glplus
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aubonbeurre/glplus/glsl"
+)
+
+// ShaderDiagnostic is a message of the compiler at a line of the sources as
+// written, before preprocessing: File is "vertex" or "fragment" for the
+// source given to LoadShaderProgram, the path of a shader file or the name
+// of an #include. Line and Col are 0 when unknown; Col is only known when the
+// preprocessor left the line as it was.
+type ShaderDiagnostic struct {
+	Severity string
+	File     string
+	Line     int
+	Col      int
+	Msg      string
+}
+
+func (d ShaderDiagnostic) String() string {
+	var b strings.Builder
+	if d.File != "" {
+		b.WriteString(d.File)
+		if d.Line > 0 {
+			fmt.Fprintf(&b, ":%d", d.Line)
+			if d.Col > 0 {
+				fmt.Fprintf(&b, ":%d", d.Col)
+			}
+		}
+		b.WriteString(": ")
+	}
+	if d.Severity != "" {
+		b.WriteString(d.Severity + ": ")
+	}
+	b.WriteString(d.Msg)
+	return b.String()
+}
+
+// ShaderCompileError is the error of a shader that fails to compile, with
+// the diagnostics of its info log.
+type ShaderCompileError struct {
+	// Stage is the String of the ShaderStage, "vertex", "fragment" and so
+	// on.
+	Stage       string
+	Diagnostics []ShaderDiagnostic
+	// Log is the info log, whose lines are the ones of the translated
+	// source.
+	Log string
+
+	// sources are the files of the diagnostics
+	sources map[string]string
+}
+
+// newShaderCompileError maps the lines of the info log of a translated
+// source back to the lines of the files it came from.
+func newShaderCompileError(stage string, src *glsl.Output, log string) *ShaderCompileError {
+	e := &ShaderCompileError{Stage: stage, Log: log, sources: src.Sources}
+	translated := strings.Split(src.Text, "\n")
+	for _, d := range glsl.ParseInfoLog(log) {
+		diag := ShaderDiagnostic{Severity: d.Severity, Msg: d.Msg}
+		if d.Line > 0 && d.Line <= len(src.Lines) {
+			origin := src.Lines[d.Line-1]
+			diag.File, diag.Line = origin.File, origin.Line
+			if line, ok := e.sourceLine(origin.File, origin.Line); ok && line == translated[d.Line-1] {
+				diag.Col = d.Col
+			}
+		}
+		e.Diagnostics = append(e.Diagnostics, diag)
+	}
+	return e
+}
+
+// sourceLine returns the line n of a file, from 1.
+func (e *ShaderCompileError) sourceLine(file string, n int) (string, bool) {
+	src, ok := e.sources[file]
+	if !ok || n <= 0 {
+		return "", false
+	}
+	lines := strings.Split(strings.TrimSuffix(src, "\n"), "\n")
+	if n > len(lines) {
+		return "", false
+	}
+	return lines[n-1], true
+}
+
+func (e *ShaderCompileError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Failed to compile the %s shader!", e.Stage)
+	if len(e.Diagnostics) == 0 {
+		b.WriteString("\n" + e.Log)
+	}
+	for _, d := range e.Diagnostics {
+		b.WriteString("\n" + d.String())
+	}
+	return b.String()
+}
+
+// sPrettyContext is the number of lines Pretty shows around a diagnostic.
+const sPrettyContext = 2
+
+// Pretty is Error with the lines around each diagnostic, and a caret under
+// its column:
+//
+//	light.glsl:3:10: error: undeclared identifier foo
+//	    1 | uniform vec3 light;
+//	    2 | vec3 lit(vec3 n) {
+//	    3 |   return foo * dot(n, light);
+//	      |          ^
+//	    4 | }
+func (e *ShaderCompileError) Pretty() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Failed to compile the %s shader!\n", e.Stage)
+	if len(e.Diagnostics) == 0 {
+		b.WriteString(e.Log)
+	}
+	for _, d := range e.Diagnostics {
+		b.WriteString(d.String() + "\n")
+		if _, ok := e.sourceLine(d.File, d.Line); !ok {
+			continue
+		}
+		for n := d.Line - sPrettyContext; n <= d.Line+sPrettyContext; n++ {
+			line, ok := e.sourceLine(d.File, n)
+			if !ok {
+				continue
+			}
+			fmt.Fprintf(&b, "%5d | %s\n", n, line)
+			if n == d.Line && d.Col > 0 && d.Col <= len(line)+1 {
+				// the tabs before the column are kept, for the caret to
+				// line up
+				indent := []byte(line[:d.Col-1])
+				for i, c := range indent {
+					if c != '\t' {
+						indent[i] = ' '
+					}
+				}
+				fmt.Fprintf(&b, "      | %s^\n", indent)
+			}
+		}
+	}
+	return b.String()
+}
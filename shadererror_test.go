@@ -0,0 +1,106 @@
+package glplus
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/aubonbeurre/glplus/glsl"
+)
+
+func TestShaderCompileError(t *testing.T) {
+	defer softContext(t)()
+	RegisterShaderInclude("test/error/light.glsl", "uniform vec3 light;\nvec3 lit(vec3 n) {\n\treturn foo * dot(n, light);\n}\n")
+
+	_, err := LoadShaderProgram(`#version 330
+#include "test/error/light.glsl"
+ATTRIBUTE vec4 position;
+void main()
+{
+  gl_Position = position * lit(vec3(0.0)).x;
+}`, sFragShaderFile, nil)
+	var compileErr *ShaderCompileError
+	if !errors.As(err, &compileErr) {
+		t.Fatalf("got %v", err)
+	}
+	want := []ShaderDiagnostic{{Severity: "error", File: "test/error/light.glsl", Line: 3, Col: 9, Msg: "'foo' undeclared"}}
+	if compileErr.Stage != "vertex" || !reflect.DeepEqual(compileErr.Diagnostics, want) {
+		t.Errorf("%s: %v", compileErr.Stage, compileErr.Diagnostics)
+	}
+	if msg := err.Error(); msg != "Failed to compile the vertex shader!\ntest/error/light.glsl:3:9: error: 'foo' undeclared" {
+		t.Errorf("got %q", msg)
+	}
+	if pretty := compileErr.Pretty(); pretty != `Failed to compile the vertex shader!
+test/error/light.glsl:3:9: error: 'foo' undeclared
+    1 | uniform vec3 light;
+    2 | vec3 lit(vec3 n) {
+    3 | 	return foo * dot(n, light);
+      | 	       ^
+    4 | }
+` {
+		t.Errorf("got\n%s", pretty)
+	}
+
+	// the column of a line changed by a macro is not known
+	_, err = NewShaderPermutations(sVertShaderPermutations, `#version 330
+COLOROUT
+void main()
+{
+  FRAGCOLOR = vec4(1.0) * bar;
+}`, nil).Program(Defines{})
+	if !errors.As(err, &compileErr) {
+		t.Fatalf("got %v", err)
+	}
+	want = []ShaderDiagnostic{{Severity: "error", File: "fragment", Line: 5, Msg: "'bar' undeclared"}}
+	if compileErr.Stage != "fragment" || !reflect.DeepEqual(compileErr.Diagnostics, want) {
+		t.Errorf("%s: %v", compileErr.Stage, compileErr.Diagnostics)
+	}
+	if pretty := compileErr.Pretty(); pretty != `Failed to compile the fragment shader!
+fragment:5: error: 'bar' undeclared
+    3 | void main()
+    4 | {
+    5 |   FRAGCOLOR = vec4(1.0) * bar;
+    6 | }
+` {
+		t.Errorf("got\n%s", pretty)
+	}
+}
+
+func TestShaderCompileErrorMapping(t *testing.T) {
+	pp := &glsl.Preprocessor{Dialect: glsl.GLSL330}
+	out, err := pp.Preprocess("obj.frag", "#version 330\n#define SCALE(x) (x * 2.0)\nuniform float f;\nvoid main() {\n  float g = SCALE(f) + h;\n}\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		log  string
+		want []ShaderDiagnostic
+	}{
+		// NVIDIA has no column
+		{"0(5) : error C1008: undefined variable \"h\"\n", []ShaderDiagnostic{
+			{Severity: "error", File: "obj.frag", Line: 5, Msg: "C1008: undefined variable \"h\""},
+		}},
+		// the columns of a line the macro changed and of the #version line
+		// are not known
+		{"0:5(24): error: `h' undeclared\n0:1(1): warning: core\n0:3(15): warning: unused\n", []ShaderDiagnostic{
+			{Severity: "error", File: "obj.frag", Line: 5, Msg: "`h' undeclared"},
+			{Severity: "warning", File: "obj.frag", Line: 1, Msg: "core"},
+			{Severity: "warning", File: "obj.frag", Line: 3, Col: 15, Msg: "unused"},
+		}},
+		{"ERROR: 0:3: 'f' : redefinition\nERROR: 0:99: out of the source\nERROR: 2 compilation errors.  No code generated.\n", []ShaderDiagnostic{
+			{Severity: "error", File: "obj.frag", Line: 3, Msg: "'f' : redefinition"},
+			{Severity: "error", Msg: "out of the source"},
+			{Severity: "error", Msg: "2 compilation errors.  No code generated."},
+		}},
+	} {
+		e := newShaderCompileError("fragment", out, test.log)
+		if !reflect.DeepEqual(e.Diagnostics, test.want) {
+			t.Errorf("%q: got %v", test.log, e.Diagnostics)
+		}
+	}
+
+	e := newShaderCompileError("fragment", out, "Internal error\n")
+	if msg := e.Error(); msg != "Failed to compile the fragment shader!\nInternal error" {
+		t.Errorf("got %q", msg)
+	}
+}
@@ -0,0 +1,9 @@
+//+build soft
+
+package glplus
+
+// NewContext returns a software context, the soft build tag makes the
+// software backend the default one.
+func NewContext() *Context {
+	return NewBackendContext(NewSoftBackend(SoftDefaultWidth, SoftDefaultHeight))
+}
@@ -0,0 +1,227 @@
+//+build soft
+
+package glplus
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+var (
+	sVertShaderTex = `#version 330
+  ATTRIBUTE vec3 position;
+  ATTRIBUTE vec2 uvs;
+  VARYINGOUT vec2 out_uvs;
+
+  void main()
+  {
+      gl_Position = vec4(position.xy * 2.0 - 1.0, 0.0, 1.0);
+      out_uvs = uvs;
+  }`
+
+	sFragShaderTex = `#version 330
+  uniform sampler2D tex1;
+  VARYINGIN vec2 out_uvs;
+  COLOROUT
+
+  void main(void)
+  {
+  	FRAGCOLOR = TEXTURE2D(tex1, out_uvs);
+  }`
+)
+
+func renderTarget(t *testing.T, size int) *RenderTarget {
+	rt, err := NewRenderTarget(image.Point{size, size}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rt.Bind(rt.Tex)
+	Gl.Viewport(0, 0, size, size)
+	Gl.ClearColor(0, 0, 1, 1)
+	Gl.Clear(Gl.COLOR_BUFFER_BIT | Gl.DEPTH_BUFFER_BIT)
+	checkGlError(t)
+	return rt
+}
+
+func TestSoftRenderCube(t *testing.T) {
+	const size = 32
+	rt := renderTarget(t, size)
+	defer rt.Delete()
+
+	prog, err := LoadShaderProgram(sVertShaderCoordMarker, sFragShaderCoordMarker, []string{"position", "normal"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer prog.DeleteProgram()
+
+	vbo := NewVBOCubeNormal(prog, 0, 0, 0, 0.5, 0.5, 0.5)
+	defer vbo.DeleteVBO()
+
+	prog.UseProgram()
+	prog.ProgramUniformMatrix4fv("projection", mgl32.Ident4())
+	prog.ProgramUniformMatrix4fv("camera", mgl32.Ident4())
+	prog.ProgramUniformMatrix4fv("model", mgl32.Ident4())
+	prog.ProgramUniform3fv("light", mgl32.Vec3{0, 0, -1})
+	prog.ProgramUniform4fv("color1", [4]float32{1, 0, 0, 1})
+
+	Gl.Enable(Gl.DEPTH_TEST)
+	vbo.Bind(prog)
+	vbo.Draw()
+	vbo.Unbind(prog)
+	prog.UnuseProgram()
+	Gl.Disable(Gl.DEPTH_TEST)
+	checkGlError(t)
+
+	img := rt.ReadBuffer(size, size)
+	rt.Unbind(rt.Tex)
+
+	// the face at z=-0.5 is the nearest and faces the light
+	if c := img.RGBAAt(size/2, size/2); c != (color.RGBA{255, 0, 0, 255}) {
+		t.Errorf("center %v", c)
+	}
+	if c := img.RGBAAt(1, 1); c != (color.RGBA{0, 0, 255, 255}) {
+		t.Errorf("corner %v", c)
+	}
+}
+
+func TestSoftRenderTexture(t *testing.T) {
+	const size = 32
+	rt := renderTarget(t, size)
+	defer rt.Delete()
+
+	src := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	src.SetRGBA(0, 0, color.RGBA{255, 0, 0, 255})
+	src.SetRGBA(1, 0, color.RGBA{0, 255, 0, 255})
+	src.SetRGBA(0, 1, color.RGBA{0, 0, 255, 255})
+	src.SetRGBA(1, 1, color.RGBA{255, 255, 255, 255})
+	tex, err := NewRGBATexture(src, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tex.DeleteTexture()
+
+	prog, err := LoadShaderProgram(sVertShaderTex, sFragShaderTex, []string{"position", "uvs"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer prog.DeleteProgram()
+
+	vbo := NewVBOQuad(prog, 0, 0, 1, 1)
+	defer vbo.DeleteVBO()
+
+	prog.UseProgram()
+	tex.BindTexture(0)
+	prog.ProgramUniform1i("tex1", 0)
+	vbo.Bind(prog)
+	vbo.Draw()
+	vbo.Unbind(prog)
+	tex.UnbindTexture(0)
+	prog.UnuseProgram()
+	checkGlError(t)
+
+	img := rt.ReadBuffer(size, size)
+	rt.Unbind(rt.Tex)
+
+	// NewVBOQuad mirrors u
+	for _, p := range []image.Point{{0, 0}, {1, 0}, {0, 1}, {1, 1}} {
+		x, y := p.X*size/2+size/4, p.Y*size/2+size/4
+		if got, want := img.RGBAAt(x, y), src.RGBAAt(1-p.X, p.Y); got != want {
+			t.Errorf("quadrant %v: got %v, want %v", p, got, want)
+		}
+	}
+}
+
+const (
+	sVertShaderLayout = `#version 330
+  ATTRIBUTE vec2 position;
+  ATTRIBUTE vec4 color;
+  ATTRIBUTE float shade;
+  VARYINGOUT vec4 out_color;
+
+  void main()
+  {
+      gl_Position = vec4(position * 2.0 - 1.0, 0.0, 1.0);
+      out_color = vec4(color.rgb * shade, color.a);
+  }`
+
+	sFragShaderLayout = `#version 330
+  VARYINGIN vec4 out_color;
+  COLOROUT
+
+  void main(void)
+  {
+  	FRAGCOLOR = out_color;
+  }`
+)
+
+func TestSoftRenderLayout(t *testing.T) {
+	const size = 16
+	rt := renderTarget(t, size)
+	defer rt.Delete()
+
+	prog, err := LoadShaderProgram(sVertShaderLayout, sFragShaderLayout, []string{"position", "color", "shade"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer prog.DeleteProgram()
+
+	opt := VBOOptions{Layout: &VertexLayout{Attribs: []VertexAttrib{
+		{Name: "position", Type: Int16, Count: 2},
+		{Name: "color", Type: Uint8Norm, Count: 4},
+		{Name: "shade", Type: Float16, Count: 1, Stream: 1},
+	}}}
+	// two triangles covering the target, red at half shade
+	var verts []float32
+	for _, p := range [][2]float32{{0, 0}, {1, 0}, {1, 1}, {1, 1}, {0, 1}, {0, 0}} {
+		verts = append(verts, p[0], p[1], 1, 0, 0, 1, 0.5)
+	}
+	vbo, err := NewVBO(prog, opt, verts, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer vbo.DeleteVBO()
+
+	prog.UseProgram()
+	vbo.Bind(prog)
+	vbo.Draw()
+	vbo.Unbind(prog)
+	prog.UnuseProgram()
+	checkGlError(t)
+
+	img := rt.ReadBuffer(size, size)
+	rt.Unbind(rt.Tex)
+	for _, p := range []image.Point{{1, 1}, {size - 2, 1}, {1, size - 2}, {size - 2, size - 2}} {
+		if got := img.RGBAAt(p.X, p.Y); got != (color.RGBA{127, 0, 0, 255}) {
+			t.Errorf("%v: got %v", p, got)
+		}
+	}
+}
+
+func readPixels(c *Context, size int) []uint8 {
+	pixels := make([]uint8, size*size*4)
+	c.ReadPixels(0, 0, size, size, c.RGBA, c.UNSIGNED_BYTE, pixels)
+	return pixels
+}
+
+func TestSoftReplay(t *testing.T) {
+	const size = 16
+	trace := traceQuad(t, size)
+	want := readPixels(Gl, size)
+	if want[0] != 0 || want[2] != 255 {
+		t.Fatalf("corner %v", want[:4])
+	}
+
+	c := NewContext()
+	if err := trace.Replay(c); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.GetError(); err != c.NO_ERROR {
+		t.Fatalf("replay error %d", err)
+	}
+	if got := readPixels(c, size); string(got) != string(want) {
+		t.Errorf("replayed pixels differ")
+	}
+}
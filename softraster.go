@@ -0,0 +1,639 @@
+//+build !netgo,!android
+
+package glplus
+
+import (
+	"encoding/binary"
+	"math"
+
+	"github.com/aubonbeurre/glplus/glsl"
+)
+
+// softVertex is a shaded vertex in clip space.
+type softVertex struct {
+	pos  [4]float32
+	vary []float32
+}
+
+// softSampler resolves sampler uniforms to the textures bound on each unit.
+type softSampler struct {
+	c *softBackend
+}
+
+func (s softSampler) Sample2D(unit int, u, v, lod float32) [4]float32 {
+	if unit < 0 || unit >= softMaxTextureUnits {
+		return [4]float32{0, 0, 0, 1}
+	}
+	t := s.c.textureUnits[unit]
+	if t == nil || t.img.pix == nil || t.img.width == 0 || t.img.height == 0 {
+		return [4]float32{0, 0, 0, 1}
+	}
+	filter := t.magFilter
+	if lod > 0 {
+		filter = t.minFilter
+	}
+	c := s.c
+	img := &t.img
+	if filter == c.NEAREST || filter == c.NEAREST_MIPMAP_NEAREST || filter == c.NEAREST_MIPMAP_LINEAR {
+		i := int(math.Floor(float64(u) * float64(img.width)))
+		j := int(math.Floor(float64(v) * float64(img.height)))
+		return c.texel(t, i, j)
+	}
+
+	x := float64(u)*float64(img.width) - 0.5
+	y := float64(v)*float64(img.height) - 0.5
+	x0, y0 := math.Floor(x), math.Floor(y)
+	fx, fy := float32(x-x0), float32(y-y0)
+	i, j := int(x0), int(y0)
+	t00, t10 := c.texel(t, i, j), c.texel(t, i+1, j)
+	t01, t11 := c.texel(t, i, j+1), c.texel(t, i+1, j+1)
+	var r [4]float32
+	for k := range r {
+		r[k] = (t00[k]*(1-fx)+t10[k]*fx)*(1-fy) + (t01[k]*(1-fx)+t11[k]*fx)*fy
+	}
+	return r
+}
+
+// wrap maps a texel coordinate into [0, size), or -1 for the border color.
+func (c *softBackend) wrap(mode, i, size int) int {
+	switch mode {
+	case c.CLAMP_TO_EDGE:
+		if i < 0 {
+			return 0
+		}
+		if i >= size {
+			return size - 1
+		}
+		return i
+	case c.CLAMP_TO_BORDER:
+		if i < 0 || i >= size {
+			return -1
+		}
+		return i
+	case c.MIRRORED_REPEAT:
+		period := 2 * size
+		i = ((i % period) + period) % period
+		if i >= size {
+			i = period - 1 - i
+		}
+		return i
+	}
+	return ((i % size) + size) % size
+}
+
+func (c *softBackend) texel(t *softTexture, i, j int) (r [4]float32) {
+	i = c.wrap(t.wrapS, i, t.img.width)
+	j = c.wrap(t.wrapT, j, t.img.height)
+	if i < 0 || j < 0 {
+		return r
+	}
+	copy(r[:], t.img.pix[(j*t.img.width+i)*4:])
+	return r
+}
+
+// fetch reads one component of a vertex attribute.
+func (c *softBackend) fetch(a *softAttrib, p []byte) float32 {
+	switch a.typ {
+	case c.FLOAT:
+		return math.Float32frombits(binary.LittleEndian.Uint32(p))
+	case c.HALF_FLOAT:
+		return halfToFloat32(binary.LittleEndian.Uint16(p))
+	case c.UNSIGNED_BYTE:
+		if a.normalized {
+			return float32(p[0]) / math.MaxUint8
+		}
+		return float32(p[0])
+	case c.BYTE:
+		if a.normalized {
+			return float32(math.Max(float64(int8(p[0]))/math.MaxInt8, -1))
+		}
+		return float32(int8(p[0]))
+	case c.UNSIGNED_SHORT:
+		v := binary.LittleEndian.Uint16(p)
+		if a.normalized {
+			return float32(v) / math.MaxUint16
+		}
+		return float32(v)
+	case c.SHORT:
+		v := int16(binary.LittleEndian.Uint16(p))
+		if a.normalized {
+			return float32(math.Max(float64(v)/math.MaxInt16, -1))
+		}
+		return float32(v)
+	case c.UNSIGNED_INT:
+		v := binary.LittleEndian.Uint32(p)
+		if a.normalized {
+			return float32(float64(v) / math.MaxUint32)
+		}
+		return float32(v)
+	case c.INT:
+		v := int32(binary.LittleEndian.Uint32(p))
+		if a.normalized {
+			return float32(math.Max(float64(v)/math.MaxInt32, -1))
+		}
+		return float32(v)
+	}
+	return 0
+}
+
+// halfToFloat32 decodes an IEEE 754 half float.
+func halfToFloat32(h uint16) float32 {
+	sign := uint32(h>>15) << 31
+	exp := uint32(h>>10) & 0x1f
+	mant := uint32(h) & 0x3ff
+	switch {
+	case exp == 0x1f:
+		// infinities and NaNs
+		return math.Float32frombits(sign | 0xff<<23 | mant<<13)
+	case exp != 0:
+		return math.Float32frombits(sign | (exp+127-15)<<23 | mant<<13)
+	case mant == 0:
+		return math.Float32frombits(sign)
+	}
+	// subnormal
+	v := float32(mant) / (1 << 24)
+	if sign != 0 {
+		v = -v
+	}
+	return v
+}
+
+// attribSlots is the number of vertex attribute locations used by a type.
+func attribSlots(t glsl.Type) int {
+	n := 1
+	if t.Kind.IsMatrix() {
+		n = t.Kind.MatrixSize()
+	}
+	if t.Array > 0 {
+		n *= t.Array
+	}
+	return n
+}
+
+// shade runs the vertex shader for one vertex; false means an attribute
+// reads past the end of its buffer.
+func (c *softBackend) shade(prog *glsl.Program, index, instance int) (v softVertex, ok bool) {
+	for _, a := range prog.Attributes {
+		for slot := 0; slot < attribSlots(a.Type); slot++ {
+			loc := a.Location + slot
+			value := [4]float32{0, 0, 0, 1}
+			if loc < softMaxAttribs && c.vertexArray.attribs[loc].enabled {
+				attrib := &c.vertexArray.attribs[loc]
+				size := c.typeSize(attrib.typ)
+				stride := attrib.stride
+				if stride == 0 {
+					stride = attrib.size * size
+				}
+				element := index
+				if attrib.divisor != 0 {
+					element = instance / attrib.divisor
+				}
+				start := attrib.offset + element*stride
+				if attrib.buffer == nil || start+attrib.size*size > len(attrib.buffer.data) {
+					return v, false
+				}
+				for k := 0; k < attrib.size; k++ {
+					value[k] = c.fetch(attrib, attrib.buffer.data[start+k*size:])
+				}
+			}
+			prog.SetAttrib(loc, value)
+		}
+	}
+	v.vary = make([]float32, prog.VaryingComponents())
+	v.pos, _ = prog.RunVertex(index, instance, v.vary)
+	return v, true
+}
+
+// draw rasterizes the primitives of the vertices at indices once per
+// instance, in order.
+func (c *softBackend) draw(mode int, indices []int, instances int) {
+	if c.program == nil {
+		c.setError(c.INVALID_OPERATION)
+		return
+	}
+	if c.CheckFramebufferStatus(c.FRAMEBUFFER) != c.FRAMEBUFFER_COMPLETE {
+		c.setError(c.INVALID_FRAMEBUFFER_OPERATION)
+		return
+	}
+
+	prog := c.program.linked
+	for instance := 0; instance < instances; instance++ {
+		shaded := make(map[int]softVertex)
+		verts := make([]softVertex, len(indices))
+		for i, index := range indices {
+			v, ok := shaded[index]
+			if !ok {
+				if v, ok = c.shade(prog, index, instance); !ok {
+					c.setError(c.INVALID_OPERATION)
+					return
+				}
+				shaded[index] = v
+			}
+			verts[i] = v
+		}
+		if !c.primitives(prog, mode, verts) {
+			c.setError(c.INVALID_ENUM)
+			return
+		}
+	}
+}
+
+// primitives rasterizes shaded vertices, false for an unknown mode.
+func (c *softBackend) primitives(prog *glsl.Program, mode int, verts []softVertex) bool {
+	switch mode {
+	case c.TRIANGLES:
+		for i := 0; i+2 < len(verts); i += 3 {
+			c.triangle(prog, verts[i], verts[i+1], verts[i+2])
+		}
+	case c.TRIANGLE_STRIP:
+		for i := 2; i < len(verts); i++ {
+			if i%2 == 0 {
+				c.triangle(prog, verts[i-2], verts[i-1], verts[i])
+			} else {
+				c.triangle(prog, verts[i-1], verts[i-2], verts[i])
+			}
+		}
+	case c.TRIANGLE_FAN:
+		for i := 2; i < len(verts); i++ {
+			c.triangle(prog, verts[0], verts[i-1], verts[i])
+		}
+	case c.LINES:
+		for i := 0; i+1 < len(verts); i += 2 {
+			c.line(prog, verts[i], verts[i+1])
+		}
+	case c.LINE_STRIP, c.LINE_LOOP:
+		for i := 1; i < len(verts); i++ {
+			c.line(prog, verts[i-1], verts[i])
+		}
+		if mode == c.LINE_LOOP && len(verts) > 2 {
+			c.line(prog, verts[len(verts)-1], verts[0])
+		}
+	case c.POINTS:
+		for _, v := range verts {
+			c.point(prog, v)
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// softPlanes are the clip volume planes as dot products with (x, y, z, w).
+// The first one keeps w away from zero.
+var softPlanes = [...][4]float32{
+	{0, 0, 0, 1},
+	{1, 0, 0, 1},
+	{-1, 0, 0, 1},
+	{0, 1, 0, 1},
+	{0, -1, 0, 1},
+	{0, 0, 1, 1},
+	{0, 0, -1, 1},
+}
+
+const softMinW = 1e-5
+
+func lerpVertex(a, b softVertex, t float32) softVertex {
+	r := softVertex{vary: make([]float32, len(a.vary))}
+	for i := range r.pos {
+		r.pos[i] = a.pos[i] + (b.pos[i]-a.pos[i])*t
+	}
+	for i := range r.vary {
+		r.vary[i] = a.vary[i] + (b.vary[i]-a.vary[i])*t
+	}
+	return r
+}
+
+// clip cuts a polygon against the clip volume (Sutherland-Hodgman).
+func clip(poly []softVertex) []softVertex {
+	for n, plane := range softPlanes {
+		if len(poly) == 0 {
+			break
+		}
+		dist := func(v softVertex) float32 {
+			d := plane[0]*v.pos[0] + plane[1]*v.pos[1] + plane[2]*v.pos[2] + plane[3]*v.pos[3]
+			if n == 0 {
+				d -= softMinW
+			}
+			return d
+		}
+		var out []softVertex
+		for i, cur := range poly {
+			prev := poly[(i+len(poly)-1)%len(poly)]
+			dc, dp := dist(cur), dist(prev)
+			if (dc >= 0) != (dp >= 0) {
+				out = append(out, lerpVertex(prev, cur, dp/(dp-dc)))
+			}
+			if dc >= 0 {
+				out = append(out, cur)
+			}
+		}
+		poly = out
+	}
+	return poly
+}
+
+// softWindow is a vertex after the perspective divide and viewport transform.
+// Varyings are premultiplied by 1/w for perspective correct interpolation.
+type softWindow struct {
+	x, y, z, invW float32
+	vary          []float32
+}
+
+func (c *softBackend) toWindow(v softVertex) softWindow {
+	invW := 1 / v.pos[3]
+	vp := c.viewport
+	w := softWindow{
+		x:    (v.pos[0]*invW+1)*0.5*float32(vp[2]) + float32(vp[0]),
+		y:    (v.pos[1]*invW+1)*0.5*float32(vp[3]) + float32(vp[1]),
+		z:    c.depthRange[0] + (v.pos[2]*invW+1)*0.5*(c.depthRange[1]-c.depthRange[0]),
+		invW: invW,
+		vary: make([]float32, len(v.vary)),
+	}
+	for i, x := range v.vary {
+		w.vary[i] = x * invW
+	}
+	return w
+}
+
+func edge(a, b softWindow, x, y float32) float32 {
+	return (b.x-a.x)*(y-a.y) - (b.y-a.y)*(x-a.x)
+}
+
+// topLeft tells whether pixels exactly on the edge a->b of a counter-clockwise
+// triangle belong to it.
+func topLeft(a, b softWindow) bool {
+	return b.y < a.y || (a.y == b.y && b.x < a.x)
+}
+
+// bounds returns the pixel rectangle of the framebuffer inside the viewport
+// and the scissor box.
+func (c *softBackend) bounds() (x0, y0, x1, y1 int) {
+	x0, y0, x1, y1 = c.scissorBounds()
+	vp := c.viewport
+	if x0 < vp[0] {
+		x0 = vp[0]
+	}
+	if y0 < vp[1] {
+		y0 = vp[1]
+	}
+	if x1 > vp[0]+vp[2] {
+		x1 = vp[0] + vp[2]
+	}
+	if y1 > vp[1]+vp[3] {
+		y1 = vp[1] + vp[3]
+	}
+	return
+}
+
+// scissorBounds returns the pixel rectangle of the framebuffer inside the
+// scissor box, when the scissor test is on.
+func (c *softBackend) scissorBounds() (x0, y0, x1, y1 int) {
+	img := c.framebuffer.color()
+	x1, y1 = img.width, img.height
+	if c.caps[c.SCISSOR_TEST] {
+		sc := c.scissor
+		if sc[0] > x0 {
+			x0 = sc[0]
+		}
+		if sc[1] > y0 {
+			y0 = sc[1]
+		}
+		if sc[0]+sc[2] < x1 {
+			x1 = sc[0] + sc[2]
+		}
+		if sc[1]+sc[3] < y1 {
+			y1 = sc[1] + sc[3]
+		}
+	}
+	return
+}
+
+func (c *softBackend) triangle(prog *glsl.Program, a, b, d softVertex) {
+	poly := clip([]softVertex{a, b, d})
+	if len(poly) < 3 {
+		return
+	}
+	win := make([]softWindow, len(poly))
+	for i, v := range poly {
+		win[i] = c.toWindow(v)
+	}
+	for i := 2; i < len(win); i++ {
+		c.rasterTriangle(prog, win[0], win[i-1], win[i])
+	}
+}
+
+func (c *softBackend) rasterTriangle(prog *glsl.Program, v0, v1, v2 softWindow) {
+	area := edge(v0, v1, v2.x, v2.y)
+	if area == 0 {
+		return
+	}
+	ccw := area > 0
+	front := ccw == (c.frontFace == c.CCW)
+	if c.caps[c.CULL_FACE] && (c.cullFace == c.FRONT_AND_BACK || front == (c.cullFace == c.FRONT)) {
+		return
+	}
+	if !ccw {
+		v1, v2 = v2, v1
+		area = -area
+	}
+
+	bx0, by0, bx1, by1 := c.bounds()
+	minX := int(math.Floor(float64(min3(v0.x, v1.x, v2.x))))
+	maxX := int(math.Ceil(float64(max3(v0.x, v1.x, v2.x))))
+	minY := int(math.Floor(float64(min3(v0.y, v1.y, v2.y))))
+	maxY := int(math.Ceil(float64(max3(v0.y, v1.y, v2.y))))
+	if minX < bx0 {
+		minX = bx0
+	}
+	if minY < by0 {
+		minY = by0
+	}
+	if maxX > bx1 {
+		maxX = bx1
+	}
+	if maxY > by1 {
+		maxY = by1
+	}
+
+	tl0, tl1, tl2 := topLeft(v1, v2), topLeft(v2, v0), topLeft(v0, v1)
+	vary := make([]float32, len(v0.vary))
+	for py := minY; py < maxY; py++ {
+		for px := minX; px < maxX; px++ {
+			x, y := float32(px)+0.5, float32(py)+0.5
+			w0, w1, w2 := edge(v1, v2, x, y), edge(v2, v0, x, y), edge(v0, v1, x, y)
+			if w0 < 0 || w1 < 0 || w2 < 0 || (w0 == 0 && !tl0) || (w1 == 0 && !tl1) || (w2 == 0 && !tl2) {
+				continue
+			}
+			b0, b1, b2 := w0/area, w1/area, w2/area
+			z := b0*v0.z + b1*v1.z + b2*v2.z
+			invW := b0*v0.invW + b1*v1.invW + b2*v2.invW
+			for i := range vary {
+				vary[i] = (b0*v0.vary[i] + b1*v1.vary[i] + b2*v2.vary[i]) / invW
+			}
+			c.fragment(prog, px, py, z, invW, vary, front)
+		}
+	}
+}
+
+func (c *softBackend) line(prog *glsl.Program, a, b softVertex) {
+	if a.pos[3] < softMinW || b.pos[3] < softMinW {
+		return
+	}
+	wa, wb := c.toWindow(a), c.toWindow(b)
+	dx, dy := wb.x-wa.x, wb.y-wa.y
+	steps := int(math.Ceil(math.Max(math.Abs(float64(dx)), math.Abs(float64(dy)))))
+	if steps == 0 {
+		steps = 1
+	}
+	bx0, by0, bx1, by1 := c.bounds()
+	vary := make([]float32, len(wa.vary))
+	for s := 0; s < steps; s++ {
+		t := (float32(s) + 0.5) / float32(steps)
+		px := int(math.Floor(float64(wa.x + dx*t)))
+		py := int(math.Floor(float64(wa.y + dy*t)))
+		if px < bx0 || py < by0 || px >= bx1 || py >= by1 {
+			continue
+		}
+		invW := wa.invW + (wb.invW-wa.invW)*t
+		for i := range vary {
+			vary[i] = (wa.vary[i] + (wb.vary[i]-wa.vary[i])*t) / invW
+		}
+		c.fragment(prog, px, py, wa.z+(wb.z-wa.z)*t, invW, vary, true)
+	}
+}
+
+func (c *softBackend) point(prog *glsl.Program, v softVertex) {
+	for _, plane := range softPlanes[1:] {
+		if plane[0]*v.pos[0]+plane[1]*v.pos[1]+plane[2]*v.pos[2]+plane[3]*v.pos[3] < 0 {
+			return
+		}
+	}
+	if v.pos[3] < softMinW {
+		return
+	}
+	w := c.toWindow(v)
+	bx0, by0, bx1, by1 := c.bounds()
+	px, py := int(math.Floor(float64(w.x))), int(math.Floor(float64(w.y)))
+	if px >= bx0 && py >= by0 && px < bx1 && py < by1 {
+		c.fragment(prog, px, py, w.z, w.invW, v.vary, true)
+	}
+}
+
+// fragment shades one pixel, runs the depth test and blends the result.
+func (c *softBackend) fragment(prog *glsl.Program, px, py int, z, invW float32, vary []float32, front bool) {
+	img := c.framebuffer.color()
+	depth := c.framebuffer.depth()
+	testDepth := c.caps[c.DEPTH_TEST] && depth != nil && px < depth.width && py < depth.height
+	if testDepth && !c.depthPass(z, depth.pix[py*depth.width+px]) {
+		return
+	}
+
+	color, discarded := prog.RunFragment(vary, [4]float32{float32(px) + 0.5, float32(py) + 0.5, z, invW}, front)
+	if discarded {
+		return
+	}
+	if testDepth && c.depthMask {
+		depth.pix[py*depth.width+px] = z
+	}
+
+	dst := img.pix[(py*img.width+px)*4 : (py*img.width+px)*4+4]
+	if c.caps[c.BLEND] {
+		color = c.blend(color, dst)
+	}
+	for i := range color {
+		if !c.colorMask[i] {
+			continue
+		}
+		if !img.float {
+			color[i] = clamp01(color[i])
+		}
+		dst[i] = color[i]
+	}
+}
+
+func (c *softBackend) depthPass(z, stored float32) bool {
+	switch c.depthFunc {
+	case c.NEVER:
+		return false
+	case c.LESS:
+		return z < stored
+	case c.EQUAL:
+		return z == stored
+	case c.LEQUAL:
+		return z <= stored
+	case c.GREATER:
+		return z > stored
+	case c.NOTEQUAL:
+		return z != stored
+	case c.GEQUAL:
+		return z >= stored
+	}
+	return true
+}
+
+func (c *softBackend) blendFactor(factor int, src, dst []float32, i int) float32 {
+	switch factor {
+	case c.ZERO:
+		return 0
+	case c.ONE:
+		return 1
+	case c.SRC_COLOR:
+		return src[i]
+	case c.ONE_MINUS_SRC_COLOR:
+		return 1 - src[i]
+	case c.DST_COLOR:
+		return dst[i]
+	case c.ONE_MINUS_DST_COLOR:
+		return 1 - dst[i]
+	case c.SRC_ALPHA:
+		return src[3]
+	case c.ONE_MINUS_SRC_ALPHA:
+		return 1 - src[3]
+	case c.DST_ALPHA:
+		return dst[3]
+	case c.ONE_MINUS_DST_ALPHA:
+		return 1 - dst[3]
+	case c.SRC_ALPHA_SATURATE:
+		if i == 3 {
+			return 1
+		}
+		return float32(math.Min(float64(src[3]), float64(1-dst[3])))
+	case c.CONSTANT_COLOR:
+		return c.blendColor[i]
+	case c.ONE_MINUS_CONSTANT_COLOR:
+		return 1 - c.blendColor[i]
+	case c.CONSTANT_ALPHA:
+		return c.blendColor[3]
+	case c.ONE_MINUS_CONSTANT_ALPHA:
+		return 1 - c.blendColor[3]
+	}
+	return 0
+}
+
+func (c *softBackend) blend(src [4]float32, dst []float32) (r [4]float32) {
+	for i := range r {
+		srcFactor, dstFactor, equation := c.blendSrc, c.blendDst, c.blendEquation
+		if i == 3 {
+			srcFactor, dstFactor, equation = c.blendSrcAlpha, c.blendDstAlpha, c.blendEquationAlpha
+		}
+		s := src[i] * c.blendFactor(srcFactor, src[:], dst, i)
+		d := dst[i] * c.blendFactor(dstFactor, src[:], dst, i)
+		switch equation {
+		case c.FUNC_SUBTRACT:
+			r[i] = s - d
+		case c.FUNC_REVERSE_SUBTRACT:
+			r[i] = d - s
+		default:
+			r[i] = s + d
+		}
+	}
+	return r
+}
+
+func min3(a, b, c float32) float32 {
+	return float32(math.Min(float64(a), math.Min(float64(b), float64(c))))
+}
+
+func max3(a, b, c float32) float32 {
+	return float32(math.Max(float64(a), math.Max(float64(b), float64(c))))
+}
@@ -0,0 +1,198 @@
+package glplus
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/aubonbeurre/glplus/glsl"
+)
+
+// ShaderStage is a stage of the pipeline a shader runs at.
+type ShaderStage int
+
+// The stages, in the order of the pipeline.
+const (
+	VertexStage ShaderStage = iota
+	TessControlStage
+	TessEvaluationStage
+	GeometryStage
+	FragmentStage
+	ComputeStage
+)
+
+func (s ShaderStage) String() string {
+	switch s {
+	case VertexStage:
+		return "vertex"
+	case TessControlStage:
+		return "tessellation control"
+	case TessEvaluationStage:
+		return "tessellation evaluation"
+	case GeometryStage:
+		return "geometry"
+	case FragmentStage:
+		return "fragment"
+	case ComputeStage:
+		return "compute"
+	}
+	return fmt.Sprintf("ShaderStage(%d)", int(s))
+}
+
+// shaderType is the type of CreateShader for the stage.
+func (c *Context) shaderType(s ShaderStage) int {
+	switch s {
+	case TessControlStage:
+		return c.TESS_CONTROL_SHADER
+	case TessEvaluationStage:
+		return c.TESS_EVALUATION_SHADER
+	case GeometryStage:
+		return c.GEOMETRY_SHADER
+	case FragmentStage:
+		return c.FRAGMENT_SHADER
+	case ComputeStage:
+		return c.COMPUTE_SHADER
+	}
+	return c.VERTEX_SHADER
+}
+
+// supports tells whether the context can run a stage, the vertex and
+// fragment ones always.
+func (caps *Caps) supports(s ShaderStage) bool {
+	switch s {
+	case TessControlStage, TessEvaluationStage:
+		return caps.TessellationShaders
+	case GeometryStage:
+		return caps.GeometryShaders
+	case ComputeStage:
+		return caps.ComputeShaders
+	}
+	return true
+}
+
+// stageDialect is the GLSL a stage is preprocessed into: the tessellation
+// and compute stages need the #version of OpenGL 4.0 and 4.3.
+func (caps *Caps) stageDialect(s ShaderStage) glsl.Dialect {
+	if !caps.ES {
+		switch s {
+		case TessControlStage, TessEvaluationStage:
+			return glsl.GLSL400
+		case ComputeStage:
+			return glsl.GLSL430
+		}
+	}
+	return caps.Dialect()
+}
+
+// stageSource is the translated source of a stage of a program.
+type stageSource struct {
+	stage ShaderStage
+	out   *glsl.Output
+}
+
+func vertexFragment(vert, frag *glsl.Output) []stageSource {
+	return []stageSource{{VertexStage, vert}, {FragmentStage, frag}}
+}
+
+func sameSources(a, b []stageSource) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].stage != b[i].stage || a[i].out.Text != b[i].out.Text {
+			return false
+		}
+	}
+	return true
+}
+
+// ProgramBuilder builds a program of any set of stages:
+//
+//	p, err := NewProgramBuilder().
+//		Stage(VertexStage, vert).
+//		Stage(GeometryStage, geom).
+//		Stage(FragmentStage, frag).
+//		Attribs("position").
+//		Build()
+//
+// A compute program has the compute stage only. The programs share the
+// cache of LoadShaderProgram.
+type ProgramBuilder struct {
+	ctx     *Context
+	sources map[ShaderStage]string
+	attribs []string
+}
+
+// NewProgramBuilder starts a program on the default context Gl.
+func NewProgramBuilder() *ProgramBuilder {
+	return Gl.NewProgramBuilder()
+}
+
+// NewProgramBuilder starts a program of no stage.
+func (c *Context) NewProgramBuilder() *ProgramBuilder {
+	return &ProgramBuilder{ctx: c, sources: make(map[ShaderStage]string)}
+}
+
+// Stage sets the source of a stage, in place of the one it had.
+func (b *ProgramBuilder) Stage(stage ShaderStage, src string) *ProgramBuilder {
+	b.sources[stage] = src
+	return b
+}
+
+// Attribs sets the attributes of the program, as LoadShaderProgram does.
+func (b *ProgramBuilder) Attribs(attribs ...string) *ProgramBuilder {
+	b.attribs = attribs
+	return b
+}
+
+// Build checks the stages against each other and the context, and loads the
+// program. The compile errors are a *ShaderCompileError, whose Stage tells
+// which source failed.
+func (b *ProgramBuilder) Build() (*GPProgram, error) {
+	c := b.ctx
+	stages := make([]ShaderStage, 0, len(b.sources))
+	for stage := range b.sources {
+		stages = append(stages, stage)
+	}
+	sort.Slice(stages, func(i, j int) bool { return stages[i] < stages[j] })
+	if err := c.checkStages(stages); err != nil {
+		return nil, err
+	}
+
+	sources := make([]stageSource, len(stages))
+	for i, stage := range stages {
+		out, err := c.preprocess(stage, stage.String(), b.sources[stage], sShaderIncludes, nil)
+		if err != nil {
+			return nil, err
+		}
+		sources[i] = stageSource{stage, out}
+	}
+	return c.loadProgram(sources, b.attribs)
+}
+
+// checkStages tells whether sorted stages make a program the context runs.
+func (c *Context) checkStages(stages []ShaderStage) error {
+	has := make(map[ShaderStage]bool, len(stages))
+	for _, stage := range stages {
+		if stage < VertexStage || stage > ComputeStage {
+			return fmt.Errorf("glplus: unknown shader stage %d", int(stage))
+		}
+		if !c.Caps.supports(stage) {
+			return fmt.Errorf("glplus: %s shaders are not supported by the context", stage)
+		}
+		has[stage] = true
+	}
+	switch {
+	case len(stages) == 0:
+		return errors.New("glplus: a program needs a stage")
+	case has[ComputeStage] && len(stages) > 1:
+		return errors.New("glplus: a compute program has no other stage")
+	case has[ComputeStage]:
+		return nil
+	case !has[VertexStage]:
+		return errors.New("glplus: a program needs a vertex stage")
+	case has[TessControlStage] && !has[TessEvaluationStage]:
+		return errors.New("glplus: a tessellation control stage needs a tessellation evaluation stage")
+	}
+	return nil
+}
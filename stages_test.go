@@ -0,0 +1,170 @@
+package glplus
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// stageBackend is a fakeBackend that has every stage, and fails to compile
+// the sources saying "broken".
+type stageBackend struct {
+	*fakeBackend
+
+	sources map[uint32]string
+}
+
+func (f *stageBackend) QueryCaps() Caps {
+	caps := f.fakeBackend.QueryCaps()
+	caps.GLSLVersion = 430
+	caps.GeometryShaders = true
+	caps.TessellationShaders = true
+	caps.ComputeShaders = true
+	return caps
+}
+
+func (f *stageBackend) CreateShader(typ int) *Shader {
+	f.record("CreateShader", typ)
+	return f.fakeBackend.CreateShader(typ)
+}
+func (f *stageBackend) ShaderSource(shader *Shader, src string) {
+	f.sources[shader.uint32] = src
+	f.record("ShaderSource", strings.SplitN(src, "\n", 2)[0])
+}
+func (f *stageBackend) GetShaderiv(shader *Shader, pname uint32) bool {
+	return !strings.Contains(f.sources[shader.uint32], "broken")
+}
+func (f *stageBackend) GetShaderInfoLog(*Shader) string {
+	return "0:2(3): error: broken\n"
+}
+func (f *stageBackend) DispatchCompute(x, y, z int) { f.record("DispatchCompute", x, y, z) }
+func (f *stageBackend) MemoryBarrier(barriers int)  { f.record("MemoryBarrier", barriers) }
+func (f *stageBackend) PatchParameteri(pname, value int) {
+	f.record("PatchParameteri", pname, value)
+}
+
+func TestProgramBuilderStages(t *testing.T) {
+	fake := &stageBackend{fakeBackend: &fakeBackend{}, sources: make(map[uint32]string)}
+	c := NewBackendContext(fake)
+
+	compute, err := c.NewProgramBuilder().Stage(ComputeStage, "#version 430\nlayout(local_size_x = 64) in;\nvoid main() {}\n").Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	compute.UseProgram()
+	c.DispatchCompute(4, 2, 1)
+	c.MemoryBarrier(c.SHADER_STORAGE_BARRIER_BIT)
+
+	tess, err := c.NewProgramBuilder().
+		Stage(FragmentStage, "// tess fragment").
+		Stage(TessEvaluationStage, "// tess evaluation").
+		Stage(GeometryStage, "// tess geometry").
+		Stage(TessControlStage, "// tess control").
+		Stage(VertexStage, "// tess vertex").
+		Attribs("position").
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.PatchParameteri(c.PATCH_VERTICES, 3)
+
+	calls := strings.Join(fake.calls, "\n")
+	for _, want := range []string{
+		fmt.Sprintf("CreateShader[%d]\nShaderSource[#version 430 core]", c.COMPUTE_SHADER),
+		fmt.Sprintf("DispatchCompute[4 2 1]\nMemoryBarrier[%d]", c.SHADER_STORAGE_BARRIER_BIT),
+		// in the order of the pipeline
+		fmt.Sprintf("CreateShader[%d]\nShaderSource[#version 330 core]\n", c.VERTEX_SHADER) +
+			fmt.Sprintf("CreateShader[%d]\nShaderSource[#version 400 core]\n", c.TESS_CONTROL_SHADER) +
+			fmt.Sprintf("CreateShader[%d]\nShaderSource[#version 400 core]\n", c.TESS_EVALUATION_SHADER) +
+			fmt.Sprintf("CreateShader[%d]\nShaderSource[#version 330 core]\n", c.GEOMETRY_SHADER) +
+			fmt.Sprintf("CreateShader[%d]\nShaderSource[#version 330 core]\n", c.FRAGMENT_SHADER),
+		fmt.Sprintf("PatchParameteri[%d 3]", c.PATCH_VERTICES),
+	} {
+		if !strings.Contains(calls, want) {
+			t.Errorf("missing %s in\n%s", want, calls)
+		}
+	}
+
+	// the same stages in another order are the same program
+	again, err := c.NewProgramBuilder().
+		Stage(VertexStage, "// tess vertex").
+		Stage(TessControlStage, "// tess control").
+		Stage(TessEvaluationStage, "// tess evaluation").
+		Stage(GeometryStage, "// tess geometry").
+		Stage(FragmentStage, "// tess fragment").
+		Build()
+	if err != nil || again != tess {
+		t.Errorf("not cached: %v", err)
+	}
+
+	_, err = c.NewProgramBuilder().
+		Stage(VertexStage, "// fine vertex").
+		Stage(GeometryStage, "#version 330\n  broken geometry\n").
+		Stage(FragmentStage, "// fine fragment").
+		Build()
+	var compileErr *ShaderCompileError
+	if !errors.As(err, &compileErr) || compileErr.Stage != "geometry" {
+		t.Fatalf("got %v", err)
+	}
+	if msg := err.Error(); msg != "Failed to compile the geometry shader!\ngeometry:2:3: error: broken" {
+		t.Errorf("got %q", msg)
+	}
+}
+
+func TestProgramBuilderChecks(t *testing.T) {
+	fake := &stageBackend{fakeBackend: &fakeBackend{}, sources: make(map[uint32]string)}
+	c := NewBackendContext(fake)
+	for _, test := range []struct {
+		stages []ShaderStage
+		want   string
+	}{
+		{nil, "glplus: a program needs a stage"},
+		{[]ShaderStage{FragmentStage}, "glplus: a program needs a vertex stage"},
+		{[]ShaderStage{VertexStage, ComputeStage}, "glplus: a compute program has no other stage"},
+		{[]ShaderStage{VertexStage, TessControlStage, FragmentStage}, "glplus: a tessellation control stage needs a tessellation evaluation stage"},
+		{[]ShaderStage{VertexStage, ShaderStage(9)}, "glplus: unknown shader stage 9"},
+	} {
+		b := c.NewProgramBuilder()
+		for _, stage := range test.stages {
+			b.Stage(stage, "// "+stage.String())
+		}
+		if _, err := b.Build(); err == nil || err.Error() != test.want {
+			t.Errorf("%v: got %v", test.stages, err)
+		}
+	}
+}
+
+func TestProgramBuilderSoft(t *testing.T) {
+	defer softContext(t)()
+
+	// the rasterizer has no other stage
+	for _, stage := range []ShaderStage{TessControlStage, TessEvaluationStage, GeometryStage, ComputeStage} {
+		_, err := NewProgramBuilder().Stage(VertexStage, sVertShaderPermutations).Stage(stage, "").Build()
+		if want := "glplus: " + stage.String() + " shaders are not supported by the context"; err == nil || err.Error() != want {
+			t.Errorf("%s: got %v", stage, err)
+		}
+	}
+
+	// a vertex and fragment program is the one of LoadShaderProgram
+	built, err := NewProgramBuilder().
+		Stage(VertexStage, sVertShaderUniforms).
+		Stage(FragmentStage, sFragShaderUniforms).
+		Attribs("position", "uvs").
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadShaderProgram(sVertShaderUniforms, sFragShaderUniforms, []string{"position", "uvs"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if built != loaded {
+		t.Errorf("program not shared")
+	}
+	built.DeleteProgram()
+	loaded.DeleteProgram()
+	if err := Gl.CheckLeaks(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,223 @@
+//+build !netgo,!android
+
+package glplus
+
+// StateStats counts, per method, the state changes sent to the driver and
+// the ones skipped because they would not have changed anything.
+type StateStats struct {
+	Issued  map[string]int
+	Skipped map[string]int
+}
+
+// TotalSkipped ...
+func (s StateStats) TotalSkipped() (total int) {
+	for _, n := range s.Skipped {
+		total += n
+	}
+	return total
+}
+
+// TotalIssued ...
+func (s StateStats) TotalIssued() (total int) {
+	for _, n := range s.Issued {
+		total += n
+	}
+	return total
+}
+
+type textureBinding struct {
+	unit, target int
+}
+
+// glState shadows the GL state set through a Context. A missing map entry
+// or a false known flag means the state is unknown, the next call is then
+// always issued.
+type glState struct {
+	program      *Program
+	programKnown bool
+
+	vao      *VertexArray
+	vaoKnown bool
+
+	buffers map[int]*Buffer
+
+	unit      int
+	unitKnown bool
+	textures  map[textureBinding]*Texture
+
+	caps map[int]bool
+
+	blend      [2]int
+	blendKnown bool
+
+	viewport      [4]int
+	viewportKnown bool
+
+	stats StateStats
+}
+
+func (s *glState) invalidate() {
+	stats := s.stats
+	*s = glState{stats: stats}
+	s.buffers = make(map[int]*Buffer)
+	s.textures = make(map[textureBinding]*Texture)
+	s.caps = make(map[int]bool)
+	if s.stats.Issued == nil {
+		s.resetStats()
+	}
+}
+
+func (s *glState) resetStats() {
+	s.stats = StateStats{
+		Issued:  make(map[string]int),
+		Skipped: make(map[string]int),
+	}
+}
+
+// count records whether a call to method is redundant and returns it.
+func (s *glState) count(method string, redundant bool) bool {
+	if redundant {
+		s.stats.Skipped[method]++
+	} else {
+		s.stats.Issued[method]++
+	}
+	return redundant
+}
+
+func (s *glState) useProgram(program *Program) bool {
+	if s.count("UseProgram", s.programKnown && s.program == program) {
+		return true
+	}
+	s.program, s.programKnown = program, true
+	return false
+}
+
+func (s *glState) bindVertexArray(vao *VertexArray, elementArrayBuffer int) bool {
+	if s.count("BindVertexArray", s.vaoKnown && s.vao == vao) {
+		return true
+	}
+	s.vao, s.vaoKnown = vao, true
+	// the element array binding belongs to the vertex array
+	delete(s.buffers, elementArrayBuffer)
+	return false
+}
+
+func (s *glState) bindBuffer(target int, buffer *Buffer) bool {
+	if bound, ok := s.buffers[target]; s.count("BindBuffer", ok && bound == buffer) {
+		return true
+	}
+	s.buffers[target] = buffer
+	return false
+}
+
+func (s *glState) activeTexture(unit int) bool {
+	if s.count("ActiveTexture", s.unitKnown && s.unit == unit) {
+		return true
+	}
+	s.unit, s.unitKnown = unit, true
+	return false
+}
+
+func (s *glState) bindTexture(target int, texture *Texture) bool {
+	if !s.unitKnown {
+		s.count("BindTexture", false)
+		return false
+	}
+	key := textureBinding{s.unit, target}
+	if bound, ok := s.textures[key]; s.count("BindTexture", ok && bound == texture) {
+		return true
+	}
+	s.textures[key] = texture
+	return false
+}
+
+func (s *glState) enable(method string, flag int, enabled bool) bool {
+	if on, ok := s.caps[flag]; s.count(method, ok && on == enabled) {
+		return true
+	}
+	s.caps[flag] = enabled
+	return false
+}
+
+func (s *glState) blendFunc(src, dst int) bool {
+	blend := [2]int{src, dst}
+	if s.count("BlendFunc", s.blendKnown && s.blend == blend) {
+		return true
+	}
+	s.blend, s.blendKnown = blend, true
+	return false
+}
+
+// blendFuncSeparate keeps the BlendFunc shadow when both factors agree.
+func (s *glState) blendFuncSeparate(srcRGB, dstRGB, srcAlpha, dstAlpha int) {
+	s.blend = [2]int{srcRGB, dstRGB}
+	s.blendKnown = srcRGB == srcAlpha && dstRGB == dstAlpha
+}
+
+func (s *glState) setViewport(x, y, width, height int) bool {
+	viewport := [4]int{x, y, width, height}
+	if s.count("Viewport", s.viewportKnown && s.viewport == viewport) {
+		return true
+	}
+	s.viewport, s.viewportKnown = viewport, true
+	return false
+}
+
+// forget drops the bindings of deleted objects, GL resets them to 0.
+func (s *glState) forgetProgram(program *Program) {
+	if s.program == program {
+		s.programKnown = false
+	}
+}
+
+func (s *glState) forgetVertexArray(vao *VertexArray, elementArrayBuffer int) {
+	if s.vao == vao {
+		s.vaoKnown = false
+		// the element array binding went with the vertex array
+		delete(s.buffers, elementArrayBuffer)
+	}
+}
+
+func (s *glState) forgetBuffer(buffer *Buffer) {
+	for target, bound := range s.buffers {
+		if bound == buffer {
+			delete(s.buffers, target)
+		}
+	}
+}
+
+func (s *glState) forgetTexture(texture *Texture) {
+	for key, bound := range s.textures {
+		if bound == texture {
+			delete(s.textures, key)
+		}
+	}
+}
+
+// InvalidateState forgets the shadowed GL state, call it after code that
+// uses GL directly (the imgui renderer for example) so that the next
+// bindings are issued again.
+func (c *Context) InvalidateState() {
+	c.state.invalidate()
+}
+
+// StateStats returns the number of state changes issued and skipped since
+// the context was created or ResetStateStats was called.
+func (c *Context) StateStats() StateStats {
+	stats := StateStats{
+		Issued:  make(map[string]int),
+		Skipped: make(map[string]int),
+	}
+	for k, v := range c.state.stats.Issued {
+		stats.Issued[k] = v
+	}
+	for k, v := range c.state.stats.Skipped {
+		stats.Skipped[k] = v
+	}
+	return stats
+}
+
+// ResetStateStats ...
+func (c *Context) ResetStateStats() {
+	c.state.resetStats()
+}
@@ -0,0 +1,97 @@
+package glplus
+
+import "testing"
+
+func TestStateShadowing(t *testing.T) {
+	Gl.InvalidateState()
+	Gl.ResetStateStats()
+
+	buf := Gl.CreateBuffer()
+	defer Gl.DeleteBuffer(buf)
+	tex := Gl.CreateTexture()
+	defer Gl.DeleteTexture(tex)
+
+	for i := 0; i < 3; i++ {
+		Gl.BindBuffer(Gl.ARRAY_BUFFER, buf)
+		Gl.ActiveTexture(Gl.TEXTURE0)
+		Gl.BindTexture(Gl.TEXTURE_2D, tex)
+		Gl.Enable(Gl.BLEND)
+		Gl.BlendFunc(Gl.SRC_ALPHA, Gl.ONE_MINUS_SRC_ALPHA)
+	}
+	// another unit has its own binding
+	Gl.ActiveTexture(Gl.TEXTURE1)
+	Gl.BindTexture(Gl.TEXTURE_2D, tex)
+	Gl.BindTexture(Gl.TEXTURE_2D, nil)
+	Gl.ActiveTexture(Gl.TEXTURE0)
+	Gl.BindTexture(Gl.TEXTURE_2D, nil)
+	Gl.Disable(Gl.BLEND)
+	Gl.BindBuffer(Gl.ARRAY_BUFFER, nil)
+	checkGlError(t)
+
+	stats := Gl.StateStats()
+	for method, want := range map[string][2]int{
+		"BindBuffer":    {2, 2},
+		"ActiveTexture": {3, 2},
+		"BindTexture":   {4, 2},
+		"Enable":        {1, 2},
+		"Disable":       {1, 0},
+		"BlendFunc":     {1, 2},
+	} {
+		if got := [2]int{stats.Issued[method], stats.Skipped[method]}; got != want {
+			t.Errorf("%s issued/skipped %v, want %v", method, got, want)
+		}
+	}
+
+	// after an invalidate everything is issued again
+	Gl.InvalidateState()
+	Gl.ResetStateStats()
+	Gl.Enable(Gl.BLEND)
+	Gl.Disable(Gl.BLEND)
+	if stats = Gl.StateStats(); stats.TotalIssued() != 2 || stats.TotalSkipped() != 0 {
+		t.Errorf("after invalidate: %v", stats)
+	}
+}
+
+func TestStateVertexArray(t *testing.T) {
+	Gl.InvalidateState()
+	Gl.ResetStateStats()
+
+	vao := Gl.CreateVertexArray()
+	vao2 := Gl.CreateVertexArray()
+	buf := Gl.CreateBuffer()
+	Gl.BindVertexArray(vao)
+	Gl.BindBuffer(Gl.ELEMENT_ARRAY_BUFFER, buf)
+	Gl.BindVertexArray(vao2)
+	// the element array binding of vao2 is not buf
+	Gl.BindBuffer(Gl.ELEMENT_ARRAY_BUFFER, buf)
+	Gl.BindBuffer(Gl.ELEMENT_ARRAY_BUFFER, nil)
+	Gl.BindVertexArray(nil)
+	Gl.DeleteBuffer(buf)
+	Gl.DeleteVertexArray(vao)
+	Gl.DeleteVertexArray(vao2)
+	checkGlError(t)
+
+	if stats := Gl.StateStats(); stats.Issued["BindBuffer"] != 3 {
+		t.Errorf("BindBuffer issued %d times", stats.Issued["BindBuffer"])
+	}
+}
+
+func TestStateDeleteVertexArray(t *testing.T) {
+	Gl.InvalidateState()
+	Gl.ResetStateStats()
+
+	vao := Gl.CreateVertexArray()
+	buf := Gl.CreateBuffer()
+	Gl.BindVertexArray(vao)
+	Gl.BindBuffer(Gl.ELEMENT_ARRAY_BUFFER, buf)
+	// deleting the bound vertex array takes its element array binding
+	Gl.DeleteVertexArray(vao)
+	Gl.BindBuffer(Gl.ELEMENT_ARRAY_BUFFER, buf)
+	Gl.BindBuffer(Gl.ELEMENT_ARRAY_BUFFER, nil)
+	Gl.DeleteBuffer(buf)
+	checkGlError(t)
+
+	if stats := Gl.StateStats(); stats.Issued["BindBuffer"] != 3 {
+		t.Errorf("BindBuffer issued %d times", stats.Issued["BindBuffer"])
+	}
+}
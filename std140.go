@@ -0,0 +1,201 @@
+package glplus
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+var (
+	sMat2Type = reflect.TypeOf(mgl32.Mat2{})
+	sMat3Type = reflect.TypeOf(mgl32.Mat3{})
+	sMat4Type = reflect.TypeOf(mgl32.Mat4{})
+)
+
+// std140Type is the GLSL type of a member of a uniform block: a scalar, a
+// vector of rows components, a column-major matrix of cols columns, or an
+// array of count of one of them.
+type std140Type struct {
+	// scalar is reflect.Float32, Int32, Uint32 or Bool
+	scalar reflect.Kind
+	rows   int
+	cols   int
+	count  int
+}
+
+// std140TypeOf maps a Go type to a member type: float32, int32, uint32 and
+// bool are scalars, arrays of 2 to 4 scalars vectors, mgl32.Mat2, Mat3 and
+// Mat4 matrices, and other arrays are arrays.
+func std140TypeOf(t reflect.Type) (std140Type, bool) {
+	switch t {
+	case sMat2Type:
+		return std140Type{scalar: reflect.Float32, rows: 2, cols: 2}, true
+	case sMat3Type:
+		return std140Type{scalar: reflect.Float32, rows: 3, cols: 3}, true
+	case sMat4Type:
+		return std140Type{scalar: reflect.Float32, rows: 4, cols: 4}, true
+	}
+	if scalar, ok := std140Scalar(t.Kind()); ok {
+		return std140Type{scalar: scalar, rows: 1, cols: 1}, true
+	}
+	if t.Kind() != reflect.Array || t.Len() == 0 {
+		return std140Type{}, false
+	}
+	if scalar, ok := std140Scalar(t.Elem().Kind()); ok && t.Len() >= 2 && t.Len() <= 4 {
+		return std140Type{scalar: scalar, rows: t.Len(), cols: 1}, true
+	}
+	elem, ok := std140TypeOf(t.Elem())
+	if !ok || elem.count > 0 {
+		return std140Type{}, false
+	}
+	elem.count = t.Len()
+	return elem, true
+}
+
+func std140Scalar(kind reflect.Kind) (reflect.Kind, bool) {
+	switch kind {
+	case reflect.Float32, reflect.Float64:
+		return reflect.Float32, true
+	case reflect.Int, reflect.Int32:
+		return reflect.Int32, true
+	case reflect.Uint, reflect.Uint32:
+		return reflect.Uint32, true
+	case reflect.Bool:
+		return reflect.Bool, true
+	}
+	return reflect.Invalid, false
+}
+
+// elemAlign and elemSize are the alignment and the size of an element,
+// following the rules 1 to 5 of the std140 layout: the columns of a matrix
+// are laid out as an array of vectors.
+func (t std140Type) elemAlign() int {
+	if t.cols > 1 || t.rows > 2 {
+		return 16
+	}
+	return 4 * t.rows
+}
+
+func (t std140Type) elemSize() int {
+	if t.cols > 1 {
+		return 16 * t.cols
+	}
+	return 4 * t.rows
+}
+
+// stride is the distance between the elements of an array, rounded up to a
+// vec4.
+func (t std140Type) stride() int {
+	return roundUp(t.elemSize(), 16)
+}
+
+func (t std140Type) align() int {
+	if t.count > 0 {
+		return 16
+	}
+	return t.elemAlign()
+}
+
+func (t std140Type) size() int {
+	if t.count > 0 {
+		return t.count * t.stride()
+	}
+	return t.elemSize()
+}
+
+// encode writes an element v at the start of buf.
+func (t std140Type) encode(v reflect.Value, buf []byte) {
+	switch {
+	case t.cols > 1:
+		for c := 0; c < t.cols; c++ {
+			for r := 0; r < t.rows; r++ {
+				putStd140Scalar(buf[c*16+r*4:], t.scalar, v.Index(c*t.rows+r))
+			}
+		}
+	case t.rows > 1:
+		for r := 0; r < t.rows; r++ {
+			putStd140Scalar(buf[r*4:], t.scalar, v.Index(r))
+		}
+	default:
+		putStd140Scalar(buf, t.scalar, v)
+	}
+}
+
+// putStd140Scalar writes a 4 bytes scalar, little-endian as the GPUs glplus
+// runs on.
+func putStd140Scalar(buf []byte, scalar reflect.Kind, v reflect.Value) {
+	var bits uint32
+	switch scalar {
+	case reflect.Float32:
+		bits = math.Float32bits(float32(v.Float()))
+	case reflect.Int32:
+		bits = uint32(int32(v.Int()))
+	case reflect.Uint32:
+		bits = uint32(v.Uint())
+	case reflect.Bool:
+		if v.Bool() {
+			bits = 1
+		}
+	}
+	binary.LittleEndian.PutUint32(buf, bits)
+}
+
+func roundUp(n, align int) int {
+	return (n + align - 1) / align * align
+}
+
+// std140Member is a member of a uniform block, the field of index.
+type std140Member struct {
+	name   string
+	index  []int
+	offset int
+	typ    std140Type
+}
+
+// std140Layout lays out the tagged fields of a struct, as SetUniforms binds
+// them, as the members of a std140 uniform block, in the order of the
+// fields.
+type std140Layout struct {
+	members []std140Member
+	// size is rounded up to a vec4
+	size int
+}
+
+func newStd140Layout(t reflect.Type) (*std140Layout, error) {
+	l := &std140Layout{}
+	offset := 0
+	err := taggedFields(t, nil, func(owner reflect.Type, sf reflect.StructField, index []int, name string, optional bool) error {
+		typ, ok := std140TypeOf(sf.Type)
+		if !ok {
+			return fmt.Errorf("glplus: field %s.%s: %s has no std140 layout", owner, sf.Name, sf.Type)
+		}
+		offset = roundUp(offset, typ.align())
+		l.members = append(l.members, std140Member{name: name, index: index, offset: offset, typ: typ})
+		offset += typ.size()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	l.size = roundUp(offset, 16)
+	return l, nil
+}
+
+// encode writes the fields of the struct v into buf, which is size bytes
+// long. The padding is left alone.
+func (l *std140Layout) encode(v reflect.Value, buf []byte) {
+	for _, m := range l.members {
+		field := v.FieldByIndex(m.index)
+		if m.typ.count == 0 {
+			m.typ.encode(field, buf[m.offset:])
+			continue
+		}
+		stride := m.typ.stride()
+		for i := 0; i < m.typ.count; i++ {
+			m.typ.encode(field.Index(i), buf[m.offset+i*stride:])
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package glplus
+
+import (
+	"encoding/binary"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+type testStd140Inner struct {
+	D mgl32.Vec2 `glsl:"d"`
+}
+
+type testStd140 struct {
+	A float32    `glsl:"a"`
+	B mgl32.Vec3 `glsl:"b"`
+	C float32    `glsl:"c"`
+	testStd140Inner
+	E mgl32.Mat3    `glsl:"e"`
+	F [5]float32    `glsl:"f"`
+	G bool          `glsl:"g"`
+	H [2]mgl32.Vec3 `glsl:"h"`
+	I [2]int32      `glsl:"i"`
+	M mgl32.Mat2    `glsl:"m,optional"`
+	J uint32        `glsl:"j"`
+	K float32       `glsl:"-"`
+	L float32
+}
+
+func TestStd140Layout(t *testing.T) {
+	l, err := newStd140Layout(reflect.TypeOf(testStd140{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	offsets := map[string]int{
+		"a": 0,
+		"b": 16, // a vec3 is aligned as a vec4
+		"c": 28, // but a float fits after it
+		"d": 32,
+		"e": 48,  // 3 vec4 columns
+		"f": 96,  // the elements of an array are vec4s
+		"g": 176, // 96 + 5*16
+		"h": 192,
+		"i": 224,
+		"m": 240,
+		"j": 272,
+	}
+	if len(l.members) != len(offsets) {
+		t.Errorf("%d members", len(l.members))
+	}
+	for _, m := range l.members {
+		if want, ok := offsets[m.name]; !ok || m.offset != want {
+			t.Errorf("%s at %d, want %d", m.name, m.offset, want)
+		}
+	}
+	if l.size != 288 {
+		t.Errorf("size %d", l.size)
+	}
+
+	v := testStd140{
+		A:               1,
+		B:               mgl32.Vec3{2, 3, 4},
+		C:               5,
+		testStd140Inner: testStd140Inner{D: mgl32.Vec2{6, 7}},
+		E:               mgl32.Mat3{1, 2, 3, 4, 5, 6, 7, 8, 9},
+		F:               [5]float32{10, 11, 12, 13, 14},
+		G:               true,
+		H:               [2]mgl32.Vec3{{15, 16, 17}, {18, 19, 20}},
+		I:               [2]int32{-1, 21},
+		M:               mgl32.Mat2{22, 23, 24, 25},
+		J:               26,
+		K:               99,
+		L:               99,
+	}
+	buf := make([]byte, l.size)
+	l.encode(reflect.ValueOf(v), buf)
+	float := func(offset int) float32 {
+		return math.Float32frombits(binary.LittleEndian.Uint32(buf[offset:]))
+	}
+	word := func(offset int) uint32 {
+		return binary.LittleEndian.Uint32(buf[offset:])
+	}
+	for offset, want := range map[int]float32{
+		0: 1, 16: 2, 24: 4, 28: 5, 32: 6, 36: 7,
+		48: 1, 56: 3, 64: 4, 80: 7, 88: 9, // the columns of e
+		96: 10, 112: 11, 160: 14,
+		192: 15, 200: 17, 208: 18,
+		240: 22, 244: 23, 256: 24, 260: 25,
+	} {
+		if got := float(offset); got != want {
+			t.Errorf("float at %d = %v, want %v", offset, got, want)
+		}
+	}
+	for offset, want := range map[int]uint32{176: 1, 224: 0xFFFFFFFF, 228: 21, 272: 26} {
+		if got := word(offset); got != want {
+			t.Errorf("word at %d = %#x, want %#x", offset, got, want)
+		}
+	}
+	// the padding is left alone
+	for _, offset := range []int{4, 12, 60, 100, 276, 284} {
+		if got := word(offset); got != 0 {
+			t.Errorf("padding at %d = %#x", offset, got)
+		}
+	}
+}
+
+func TestStd140Errors(t *testing.T) {
+	for _, test := range []struct {
+		value interface{}
+		err   string
+	}{
+		{struct {
+			S []float32 `glsl:"s"`
+		}{}, "glplus: field struct { S []float32 \"glsl:\\\"s\\\"\" }.S: []float32 has no std140 layout"},
+		{testColor{}, ""},
+		{testRequired{}, "glplus: field glplus.testRequired.F: unknown option \"required\""},
+	} {
+		_, err := newStd140Layout(reflect.TypeOf(test.value))
+		if (err == nil && test.err != "") || (err != nil && err.Error() != test.err) {
+			t.Errorf("%T: got %v, want %q", test.value, err, test.err)
+		}
+	}
+	for _, typ := range []interface{}{"", [2][5]float32{}, [0]float32{}, struct{}{}, int64(0)} {
+		if _, ok := std140TypeOf(reflect.TypeOf(typ)); ok {
+			t.Errorf("%T has a std140 layout", typ)
+		}
+	}
+}
@@ -12,14 +12,45 @@ import (
 type GPTexture struct {
 	texture *Texture
 	Size    image.Point
+
+	ctx *Context
+	// upload specifies the parameters and texels of the bound texture, it
+	// runs again when the texture is restored
+	upload func(c *Context)
 }
 
-// GenTexture ...
+// GenTexture creates a texture on the default context Gl.
 func GenTexture(size image.Point) (texture *GPTexture) {
-	texture = &GPTexture{texture: Gl.CreateTexture(), Size: size}
+	return Gl.GenTexture(size)
+}
+
+// GenTexture ...
+func (c *Context) GenTexture(size image.Point) (texture *GPTexture) {
+	texture = &GPTexture{texture: c.CreateTexture(), Size: size, ctx: c}
+	c.Register(texture)
 	return texture
 }
 
+// SetUpload sets up the texture with upload, which specifies the parameters
+// and the texels of the bound texture, and keeps it to restore the texture.
+func (t *GPTexture) SetUpload(upload func(c *Context)) {
+	t.upload = upload
+	t.BindTexture(0)
+	upload(t.ctx)
+	t.UnbindTexture(0)
+}
+
+// Restore re-creates the texture after the context was lost.
+func (t *GPTexture) Restore() error {
+	t.texture = t.ctx.CreateTexture()
+	if t.upload != nil {
+		t.BindTexture(0)
+		t.upload(t.ctx)
+		t.UnbindTexture(0)
+	}
+	return nil
+}
+
 // Handle ...
 func (t *GPTexture) Handle() *Texture {
 	return t.texture
@@ -28,61 +59,78 @@ func (t *GPTexture) Handle() *Texture {
 // DeleteTexture ...
 func (t *GPTexture) DeleteTexture() {
 	if t.texture != nil {
-		Gl.DeleteTexture(t.texture)
+		t.ctx.DeleteTexture(t.texture)
 	}
+	t.ctx.Unregister(t)
 }
 
 // BindTexture ...
 func (t *GPTexture) BindTexture(unit int) {
-	Gl.ActiveTexture(Gl.TEXTURE0 + unit)
-	Gl.BindTexture(Gl.TEXTURE_2D, t.texture)
+	t.ctx.ActiveTexture(t.ctx.TEXTURE0 + unit)
+	t.ctx.BindTexture(t.ctx.TEXTURE_2D, t.texture)
 }
 
 // UnbindTexture ...
 func (t *GPTexture) UnbindTexture(unit int) {
-	Gl.ActiveTexture(Gl.TEXTURE0 + unit)
-	Gl.BindTexture(Gl.TEXTURE_2D, nil)
+	t.ctx.ActiveTexture(t.ctx.TEXTURE0 + unit)
+	t.ctx.BindTexture(t.ctx.TEXTURE_2D, nil)
 }
 
-// NewRGBATexture ...
+// NewRGBATexture creates a texture on the default context Gl.
 func NewRGBATexture(rgba *image.RGBA, linear, repeat bool) (texture *GPTexture, err error) {
+	return Gl.NewRGBATexture(rgba, linear, repeat)
+}
+
+// NewRGBATexture ...
+func (c *Context) NewRGBATexture(rgba *image.RGBA, linear, repeat bool) (texture *GPTexture, err error) {
 	if rgba.Stride != rgba.Rect.Size().X*4 {
 		return nil, fmt.Errorf("unsupported stride")
 	}
+	if size := rgba.Rect.Size(); size.X > c.Caps.MaxTextureSize || size.Y > c.Caps.MaxTextureSize {
+		return nil, fmt.Errorf("glplus: texture %dx%d exceeds the maximum texture size %d", size.X, size.Y, c.Caps.MaxTextureSize)
+	}
 
-	texture = GenTexture(rgba.Rect.Size())
+	texture = c.GenTexture(rgba.Rect.Size())
+	texture.SetUpload(func(c *Context) {
+		uploadRGBA(c, rgba, linear, repeat)
+	})
 
-	texture.BindTexture(0)
+	return texture, nil
+}
+
+func uploadRGBA(c *Context, rgba *image.RGBA, linear, repeat bool) {
 	if linear {
-		Gl.TexParameteri(Gl.TEXTURE_2D, Gl.TEXTURE_MIN_FILTER, Gl.LINEAR)
-		Gl.TexParameteri(Gl.TEXTURE_2D, Gl.TEXTURE_MAG_FILTER, Gl.LINEAR)
+		c.TexParameteri(c.TEXTURE_2D, c.TEXTURE_MIN_FILTER, c.LINEAR)
+		c.TexParameteri(c.TEXTURE_2D, c.TEXTURE_MAG_FILTER, c.LINEAR)
 	} else {
-		Gl.TexParameteri(Gl.TEXTURE_2D, Gl.TEXTURE_MIN_FILTER, Gl.NEAREST)
-		Gl.TexParameteri(Gl.TEXTURE_2D, Gl.TEXTURE_MAG_FILTER, Gl.NEAREST)
+		c.TexParameteri(c.TEXTURE_2D, c.TEXTURE_MIN_FILTER, c.NEAREST)
+		c.TexParameteri(c.TEXTURE_2D, c.TEXTURE_MAG_FILTER, c.NEAREST)
 	}
 	if repeat {
-		Gl.TexParameteri(Gl.TEXTURE_2D, Gl.TEXTURE_WRAP_S, Gl.REPEAT)
-		Gl.TexParameteri(Gl.TEXTURE_2D, Gl.TEXTURE_WRAP_T, Gl.REPEAT)
+		c.TexParameteri(c.TEXTURE_2D, c.TEXTURE_WRAP_S, c.REPEAT)
+		c.TexParameteri(c.TEXTURE_2D, c.TEXTURE_WRAP_T, c.REPEAT)
 	} else {
-		Gl.TexParameteri(Gl.TEXTURE_2D, Gl.TEXTURE_WRAP_S, Gl.CLAMP_TO_EDGE)
-		Gl.TexParameteri(Gl.TEXTURE_2D, Gl.TEXTURE_WRAP_T, Gl.CLAMP_TO_EDGE)
+		c.TexParameteri(c.TEXTURE_2D, c.TEXTURE_WRAP_S, c.CLAMP_TO_EDGE)
+		c.TexParameteri(c.TEXTURE_2D, c.TEXTURE_WRAP_T, c.CLAMP_TO_EDGE)
 	}
-	Gl.TexImage2D(
-		Gl.TEXTURE_2D,
+	c.TexImage2D(
+		c.TEXTURE_2D,
 		0,
-		Gl.RGBA,
+		c.RGBA,
 		rgba.Rect.Size().X,
 		rgba.Rect.Size().Y,
-		Gl.RGBA,
-		Gl.UNSIGNED_BYTE,
+		c.RGBA,
+		c.UNSIGNED_BYTE,
 		rgba.Pix)
-	texture.UnbindTexture(0)
+}
 
-	return texture, nil
+// LoadTexture loads a texture on the default context Gl.
+func LoadTexture(file string, linear, repeat bool) (texture *GPTexture, img image.Image, err error) {
+	return Gl.LoadTexture(file, linear, repeat)
 }
 
 // LoadTexture ...
-func LoadTexture(file string, linear, repeat bool) (texture *GPTexture, img image.Image, err error) {
+func (c *Context) LoadTexture(file string, linear, repeat bool) (texture *GPTexture, img image.Image, err error) {
 	var imgFile *os.File
 	if imgFile, err = os.Open(file); err != nil {
 		return nil, img, err
@@ -98,7 +146,7 @@ func LoadTexture(file string, linear, repeat bool) (texture *GPTexture, img imag
 	rgba = image.NewRGBA(img.Bounds())
 	draw.Draw(rgba, rgba.Bounds(), img, image.Point{0, 0}, draw.Src)
 
-	if texture, err = NewRGBATexture(rgba, linear, repeat); err != nil {
+	if texture, err = c.NewRGBATexture(rgba, linear, repeat); err != nil {
 		return nil, img, err
 	}
 
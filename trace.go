@@ -0,0 +1,433 @@
+package glplus
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+const traceVersion = 2
+
+// TraceHeader is the first record of a trace.
+type TraceHeader struct {
+	Version int    `json:"version"`
+	Backend string `json:"backend"`
+	// Enums names the GL constants of the recording backend, for dumps.
+	Enums map[int]string `json:"enums,omitempty"`
+}
+
+// TraceValue is an argument or a result of a recorded call. Handles are
+// numbered per trace, 0 being nil; slices keep their little-endian bytes.
+type TraceValue struct {
+	Type  string  `json:"t"`
+	Int   int64   `json:"i,omitempty"`
+	Float float64 `json:"f,omitempty"`
+	Bool  bool    `json:"b,omitempty"`
+	Str   string  `json:"s,omitempty"`
+	Data  []byte  `json:"d,omitempty"`
+}
+
+// TraceCall is a recorded Context call.
+type TraceCall struct {
+	Method string       `json:"m"`
+	Args   []TraceValue `json:"a,omitempty"`
+	Result *TraceValue  `json:"r,omitempty"`
+}
+
+// Trace is a recorded sequence of Context calls.
+type Trace struct {
+	Header TraceHeader
+	Calls  []TraceCall
+}
+
+// traceOutput stands for a slice the call writes into, like the pixels of
+// ReadPixels: only its type and length are recorded, the replay allocates it.
+type traceOutput struct {
+	slice interface{}
+}
+
+var sTraceHandles = map[reflect.Type]string{
+	reflect.TypeOf((*Texture)(nil)):         "Texture",
+	reflect.TypeOf((*Buffer)(nil)):          "Buffer",
+	reflect.TypeOf((*FrameBuffer)(nil)):     "FrameBuffer",
+	reflect.TypeOf((*RenderBuffer)(nil)):    "RenderBuffer",
+	reflect.TypeOf((*Program)(nil)):         "Program",
+	reflect.TypeOf((*UniformLocation)(nil)): "UniformLocation",
+	reflect.TypeOf((*Shader)(nil)):          "Shader",
+	reflect.TypeOf((*VertexArray)(nil)):     "VertexArray",
+}
+
+// Tracer writes the calls of a Context as JSON lines.
+type Tracer struct {
+	enc  *json.Encoder
+	ids  map[interface{}]int64
+	next int64
+	err  error
+}
+
+func newTracer(w io.Writer, header TraceHeader) (*Tracer, error) {
+	t := &Tracer{enc: json.NewEncoder(w), ids: make(map[interface{}]int64)}
+	if err := t.enc.Encode(header); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (t *Tracer) record(method string, result interface{}, args ...interface{}) {
+	call := TraceCall{Method: method, Args: make([]TraceValue, len(args))}
+	for i, arg := range args {
+		call.Args[i] = t.value(arg)
+	}
+	if result != nil {
+		res := t.value(result)
+		call.Result = &res
+	}
+	if err := t.enc.Encode(call); err != nil && t.err == nil {
+		t.err = err
+	}
+}
+
+func (t *Tracer) value(v interface{}) TraceValue {
+	switch x := v.(type) {
+	case nil:
+		return TraceValue{Type: "nil"}
+	case int:
+		return TraceValue{Type: "int", Int: int64(x)}
+	case uint32:
+		return TraceValue{Type: "uint32", Int: int64(x)}
+	case float32:
+		return TraceValue{Type: "float32", Float: float64(x)}
+	case bool:
+		return TraceValue{Type: "bool", Bool: x}
+	case string:
+		return TraceValue{Type: "string", Str: x}
+	case traceOutput:
+		return TraceValue{Type: "out" + reflect.TypeOf(x.slice).String(), Int: int64(reflect.ValueOf(x.slice).Len())}
+	case []uint8:
+		return TraceValue{Type: "[]uint8", Data: append([]byte(nil), x...)}
+	case []uint16:
+		data := make([]byte, 2*len(x))
+		for i, e := range x {
+			binary.LittleEndian.PutUint16(data[2*i:], e)
+		}
+		return TraceValue{Type: "[]uint16", Data: data}
+	case []uint32:
+		data := make([]byte, 4*len(x))
+		for i, e := range x {
+			binary.LittleEndian.PutUint32(data[4*i:], e)
+		}
+		return TraceValue{Type: "[]uint32", Data: data}
+	case []float32:
+		data := make([]byte, 4*len(x))
+		for i, e := range x {
+			binary.LittleEndian.PutUint32(data[4*i:], math.Float32bits(e))
+		}
+		return TraceValue{Type: "[]float32", Data: data}
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Slice {
+		// the other slices of numbers, as the data of BufferData, keep their
+		// bytes
+		var data bytes.Buffer
+		if err := binary.Write(&data, binary.LittleEndian, v); err == nil {
+			return TraceValue{Type: "[]uint8", Data: data.Bytes()}
+		}
+	}
+	name, ok := sTraceHandles[rv.Type()]
+	if !ok {
+		// the call went through, the trace is what fails
+		if t.err == nil {
+			t.err = fmt.Errorf("trace: unsupported type %T", v)
+		}
+		return TraceValue{Type: "nil"}
+	}
+	if rv.IsNil() {
+		return TraceValue{Type: name}
+	}
+	id, ok := t.ids[v]
+	if !ok {
+		t.next++
+		id = t.next
+		t.ids[v] = id
+	}
+	return TraceValue{Type: name, Int: id}
+}
+
+// enumNames maps the values of the int fields of a backend to their names.
+func enumNames(backend interface{}) map[int]string {
+	names := make(map[int]string)
+	v := reflect.Indirect(reflect.ValueOf(backend))
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Type().Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		var value int
+		switch f.Type.Kind() {
+		case reflect.Int:
+			value = int(v.Field(i).Int())
+		case reflect.Uint32:
+			value = int(v.Field(i).Uint())
+		default:
+			continue
+		}
+		if name, ok := names[value]; !ok || f.Name < name {
+			names[value] = f.Name
+		}
+	}
+	return names
+}
+
+// ReadTrace reads a trace written by Context.StartTrace.
+func ReadTrace(r io.Reader) (*Trace, error) {
+	dec := json.NewDecoder(bufio.NewReader(r))
+	var t Trace
+	if err := dec.Decode(&t.Header); err != nil {
+		return nil, fmt.Errorf("trace header: %v", err)
+	}
+	if t.Header.Version != traceVersion {
+		return nil, fmt.Errorf("unsupported trace version %d", t.Header.Version)
+	}
+	for {
+		var call TraceCall
+		if err := dec.Decode(&call); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("trace call %d: %v", len(t.Calls), err)
+		}
+		t.Calls = append(t.Calls, call)
+	}
+	return &t, nil
+}
+
+// sTracePlaceholders create the objects standing for the handles a trace
+// uses without creating them.
+var sTracePlaceholders = map[string]func(c *Context) interface{}{
+	"Texture":      func(c *Context) interface{} { return c.CreateTexture() },
+	"Buffer":       func(c *Context) interface{} { return c.CreateBuffer() },
+	"FrameBuffer":  func(c *Context) interface{} { return c.CreateFramebuffer() },
+	"RenderBuffer": func(c *Context) interface{} { return c.CreateRenderbuffer() },
+	"Program":      func(c *Context) interface{} { return c.CreateProgram() },
+	"Shader":       func(c *Context) interface{} { return c.CreateShader(c.VERTEX_SHADER) },
+	"VertexArray":  func(c *Context) interface{} { return c.CreateVertexArray() },
+}
+
+// Replay issues the calls of the trace on c. Handles created during the
+// replay stand for the recorded ones. The handles created before the trace
+// started, as when it records one frame, stand for new empty objects, and a
+// location of a uniform for the one no uniform has: the calls replay, the
+// data uploaded before the trace does not.
+func (t *Trace) Replay(c *Context) error {
+	objects := make(map[string]reflect.Value)
+	placeholder := func(v TraceValue, typ reflect.Type) reflect.Value {
+		obj := reflect.Zero(typ)
+		if create, ok := sTracePlaceholders[v.Type]; ok {
+			if created := create(c); created != nil {
+				obj = reflect.ValueOf(created)
+			}
+		}
+		objects[v.key()] = obj
+		return obj
+	}
+	ctx := reflect.ValueOf(c)
+	for n, call := range t.Calls {
+		m := ctx.MethodByName(call.Method)
+		if !m.IsValid() {
+			return fmt.Errorf("trace call %d: no method %s", n, call.Method)
+		}
+		if m.Type().NumIn() != len(call.Args) {
+			return fmt.Errorf("trace call %d: %s takes %d arguments, got %d", n, call.Method, m.Type().NumIn(), len(call.Args))
+		}
+		args := make([]reflect.Value, len(call.Args))
+		for i, arg := range call.Args {
+			v, err := arg.value(m.Type().In(i), objects, placeholder)
+			if err != nil {
+				return fmt.Errorf("trace call %d: %s: %v", n, call.Method, err)
+			}
+			args[i] = v
+		}
+		res := m.Call(args)
+		if call.Result != nil && call.Result.Int != 0 && len(res) == 1 {
+			if _, ok := sTraceHandles[res[0].Type()]; ok {
+				objects[call.Result.key()] = res[0]
+			}
+		}
+	}
+	return nil
+}
+
+func (v TraceValue) key() string {
+	return fmt.Sprintf("%s#%d", v.Type, v.Int)
+}
+
+func (v TraceValue) value(typ reflect.Type, objects map[string]reflect.Value, placeholder func(TraceValue, reflect.Type) reflect.Value) (reflect.Value, error) {
+	if name, ok := sTraceHandles[typ]; ok {
+		if v.Int == 0 {
+			return reflect.Zero(typ), nil
+		}
+		if v.Type != name {
+			return reflect.Value{}, fmt.Errorf("%s does not fit a %s argument", v.Type, typ)
+		}
+		obj, ok := objects[v.key()]
+		if !ok {
+			obj = placeholder(v, typ)
+		}
+		return obj, nil
+	}
+
+	var x interface{}
+	switch v.Type {
+	case "nil":
+		return reflect.Zero(typ), nil
+	case "int":
+		x = int(v.Int)
+	case "uint32":
+		x = uint32(v.Int)
+	case "float32":
+		x = float32(v.Float)
+	case "bool":
+		x = v.Bool
+	case "string":
+		x = v.Str
+	case "out[]uint8":
+		x = make([]uint8, v.Int)
+	case "out[]float32":
+		x = make([]float32, v.Int)
+	case "[]uint8":
+		x = append([]uint8{}, v.Data...)
+	case "[]uint16":
+		s := make([]uint16, len(v.Data)/2)
+		for i := range s {
+			s[i] = binary.LittleEndian.Uint16(v.Data[2*i:])
+		}
+		x = s
+	case "[]uint32":
+		s := make([]uint32, len(v.Data)/4)
+		for i := range s {
+			s[i] = binary.LittleEndian.Uint32(v.Data[4*i:])
+		}
+		x = s
+	case "[]float32":
+		s := make([]float32, len(v.Data)/4)
+		for i := range s {
+			s[i] = math.Float32frombits(binary.LittleEndian.Uint32(v.Data[4*i:]))
+		}
+		x = s
+	default:
+		return reflect.Value{}, fmt.Errorf("unknown value type %s", v.Type)
+	}
+	rv := reflect.ValueOf(x)
+	if !rv.Type().AssignableTo(typ) {
+		return rv, fmt.Errorf("%s does not fit a %s argument", v.Type, typ)
+	}
+	return rv, nil
+}
+
+// Format prints a value, naming GL constants found in enums.
+func (v TraceValue) Format(enums map[int]string) string {
+	switch v.Type {
+	case "nil":
+		return "nil"
+	case "int", "uint32":
+		// small values are more often counts and sizes than constants
+		if name, ok := enums[int(v.Int)]; ok && v.Int >= 0x100 {
+			return name
+		}
+		return fmt.Sprint(v.Int)
+	case "float32":
+		return fmt.Sprint(float32(v.Float))
+	case "bool":
+		return fmt.Sprint(v.Bool)
+	case "string":
+		if len(v.Str) > 40 {
+			return fmt.Sprintf("%q...(%d bytes)", v.Str[:40], len(v.Str))
+		}
+		return fmt.Sprintf("%q", v.Str)
+	case "out[]uint8", "out[]float32":
+		return fmt.Sprintf("%s(%d)", v.Type[3:], v.Int)
+	case "[]uint8", "[]uint16", "[]uint32", "[]float32":
+		return v.formatSlice()
+	}
+	if v.Int == 0 {
+		return v.Type + "(nil)"
+	}
+	return v.key()
+}
+
+func (v TraceValue) formatSlice() string {
+	const preview = 8
+	elems, err := v.value(reflect.TypeOf((*interface{})(nil)).Elem(), nil, nil)
+	if err != nil {
+		return v.Type
+	}
+	s := reflect.ValueOf(elems.Interface())
+	var items []string
+	for i := 0; i < s.Len() && i < preview; i++ {
+		items = append(items, fmt.Sprint(s.Index(i).Interface()))
+	}
+	if s.Len() > preview {
+		items = append(items, "...")
+	}
+	return fmt.Sprintf("%s(%d){%s}", v.Type, s.Len(), strings.Join(items, ", "))
+}
+
+// String prints a call the way it would be written in Go.
+func (call TraceCall) String() string {
+	return call.Format(nil)
+}
+
+// Format prints a call, naming GL constants found in enums.
+func (call TraceCall) Format(enums map[int]string) string {
+	args := make([]string, len(call.Args))
+	for i, arg := range call.Args {
+		args[i] = arg.Format(enums)
+	}
+	s := fmt.Sprintf("%s(%s)", call.Method, strings.Join(args, ", "))
+	if call.Result != nil {
+		s += " = " + call.Result.Format(enums)
+	}
+	return s
+}
+
+// Dump prints the trace, one numbered call per line.
+func (t *Trace) Dump(w io.Writer) error {
+	if _, err := fmt.Fprintf(w, "# %s, %d calls\n", t.Header.Backend, len(t.Calls)); err != nil {
+		return err
+	}
+	for n, call := range t.Calls {
+		if _, err := fmt.Fprintf(w, "%6d %s\n", n, call.Format(t.Header.Enums)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Stats counts the calls of the trace per method, most frequent first.
+func (t *Trace) Stats() []string {
+	counts := make(map[string]int)
+	for _, call := range t.Calls {
+		counts[call.Method]++
+	}
+	var methods []string
+	for m := range counts {
+		methods = append(methods, m)
+	}
+	sort.Slice(methods, func(i, j int) bool {
+		if counts[methods[i]] != counts[methods[j]] {
+			return counts[methods[i]] > counts[methods[j]]
+		}
+		return methods[i] < methods[j]
+	})
+	for i, m := range methods {
+		methods[i] = fmt.Sprintf("%6d %s", counts[m], m)
+	}
+	return methods
+}
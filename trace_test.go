@@ -0,0 +1,179 @@
+package glplus
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// sFragShaderTrace is not used by other tests so that the trace creates
+// its program instead of finding it in the program cache.
+var sFragShaderTrace = `#version 330
+  // traced by %s
+  uniform vec4 color1;
+  VARYINGIN vec3 out_normal;
+  COLOROUT
+
+  void main(void)
+  {
+  	FRAGCOLOR = color1;
+  }`
+
+func traceQuad(t *testing.T, size int) *Trace {
+	frag := fmt.Sprintf(sFragShaderTrace, t.Name())
+
+	var buf bytes.Buffer
+	if err := Gl.StartTrace(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	prog, err := LoadShaderProgram(sVertShaderCoordMarker, frag, []string{"position", "normal"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	vbo := NewVBOCubeNormal(prog, 0, 0, 0, 0.5, 0.5, 0.5)
+
+	Gl.Viewport(0, 0, size, size)
+	Gl.ClearColor(0, 0, 1, 1)
+	Gl.Clear(Gl.COLOR_BUFFER_BIT)
+	prog.UseProgram()
+	prog.ProgramUniformMatrix4fv("projection", mgl32.Ident4())
+	prog.ProgramUniformMatrix4fv("camera", mgl32.Ident4())
+	prog.ProgramUniformMatrix4fv("model", mgl32.Ident4())
+	prog.ProgramUniform4fv("color1", [4]float32{0, 1, 0, 1})
+	vbo.Bind(prog)
+	vbo.Draw()
+	vbo.Unbind(prog)
+	prog.UnuseProgram()
+
+	vbo.DeleteVBO()
+	prog.DeleteProgram()
+
+	if err = Gl.StopTrace(); err != nil {
+		t.Fatal(err)
+	}
+	checkGlError(t)
+
+	trace, err := ReadTrace(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return trace
+}
+
+func TestTrace(t *testing.T) {
+	trace := traceQuad(t, 16)
+
+	var dump bytes.Buffer
+	if err := trace.Dump(&dump); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"CreateProgram() = Program#",
+		"BindBuffer(ARRAY_BUFFER, Buffer#",
+		"Clear(",
+		"ClearColor(0, 0, 1, 1)",
+		"UniformMatrix4fv(UniformLocation#",
+	} {
+		if !strings.Contains(dump.String(), want) {
+			t.Errorf("dump misses %q:\n%s", want, dump.String())
+		}
+	}
+
+	if err := trace.Replay(Gl); err != nil {
+		t.Fatal(err)
+	}
+	checkGlError(t)
+}
+
+func TestTraceMidSession(t *testing.T) {
+	prog, err := LoadShaderProgram(sVertShaderCoordMarker, fmt.Sprintf(sFragShaderTrace, t.Name()), []string{"position", "normal"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer prog.DeleteProgram()
+	vbo := NewVBOCubeNormal(prog, 0, 0, 0, 0.5, 0.5, 0.5)
+	defer vbo.DeleteVBO()
+
+	// one frame, drawn with the objects created before the trace
+	var buf bytes.Buffer
+	if err := Gl.StartTrace(&buf); err != nil {
+		t.Fatal(err)
+	}
+	prog.UseProgram()
+	prog.ProgramUniform4fv("color1", [4]float32{0, 1, 0, 1})
+	vbo.Bind(prog)
+	vbo.Draw()
+	vbo.Unbind(prog)
+	prog.UnuseProgram()
+	if err := Gl.StopTrace(); err != nil {
+		t.Fatal(err)
+	}
+	checkGlError(t)
+
+	trace, err := ReadTrace(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := trace.Replay(Gl); err != nil {
+		t.Fatal(err)
+	}
+	// the empty program stands for the recorded one
+	for Gl.GetError() != Gl.NO_ERROR {
+	}
+}
+
+func TestTraceSlices(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Gl.StartTrace(&buf); err != nil {
+		t.Fatal(err)
+	}
+	b := Gl.CreateBuffer()
+	Gl.BindBuffer(Gl.ARRAY_BUFFER, b)
+	Gl.BufferData(Gl.ARRAY_BUFFER, []int32{1, -1}, Gl.STATIC_DRAW)
+	Gl.BufferSubData(Gl.ARRAY_BUFFER, 0, []mgl32.Vec2{{1, 0}})
+	Gl.BindBuffer(Gl.ARRAY_BUFFER, nil)
+	Gl.DeleteBuffer(b)
+	if err := Gl.StopTrace(); err != nil {
+		t.Fatal(err)
+	}
+	checkGlError(t)
+
+	trace, err := ReadTrace(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, call := range trace.Calls {
+		switch call.Method {
+		case "BufferData":
+			if data := call.Args[1]; data.Type != "[]uint8" || !bytes.Equal(data.Data, []byte{1, 0, 0, 0, 0xff, 0xff, 0xff, 0xff}) {
+				t.Errorf("BufferData recorded %v", data)
+			}
+		case "BufferSubData":
+			if data := call.Args[2]; data.Type != "[]uint8" || !bytes.Equal(data.Data, []byte{0, 0, 0x80, 0x3f, 0, 0, 0, 0}) {
+				t.Errorf("BufferSubData recorded %v", data)
+			}
+		}
+	}
+	if err := trace.Replay(Gl); err != nil {
+		t.Fatal(err)
+	}
+	checkGlError(t)
+
+	// a slice without a fixed size fails the trace, not the call
+	if err := Gl.StartTrace(&buf); err != nil {
+		t.Fatal(err)
+	}
+	b = Gl.CreateBuffer()
+	Gl.BindBuffer(Gl.ARRAY_BUFFER, b)
+	Gl.BufferData(Gl.ARRAY_BUFFER, []int{1}, Gl.STATIC_DRAW)
+	Gl.BindBuffer(Gl.ARRAY_BUFFER, nil)
+	Gl.DeleteBuffer(b)
+	if err := Gl.StopTrace(); err == nil || err.Error() != "trace: unsupported type []int" {
+		t.Errorf("got %v", err)
+	}
+	checkGlError(t)
+}
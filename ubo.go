@@ -0,0 +1,213 @@
+package glplus
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+)
+
+// UniformBuffer holds a struct in a uniform buffer object, laid out with
+// std140, for the uniform blocks bound to it with GPProgram.BindUniformBlock.
+// The fields are tagged with the members of the block, in the order of the
+// declaration:
+//
+//	type Camera struct {
+//		View  mgl32.Mat4 `glsl:"mView"`
+//		Light mgl32.Vec3 `glsl:"light"`
+//	}
+//
+//	layout(std140) uniform Camera {
+//		mat4 mView;
+//		vec3 light;
+//	};
+//
+// A field is a float32, int32, uint32 or bool, an array of 2 to 4 of them for
+// a vector, an mgl32.Mat2, Mat3 or Mat4, or an array of one of those.
+//
+// Without Caps.UniformBuffers (WebGL 1, OpenGL ES 2 and the mobile bindings)
+// the shaders declare the members of the blocks as plain uniforms, and a
+// program bound to the buffer sets them when it is used after an Update.
+type UniformBuffer struct {
+	ctx     *Context
+	buffer  *Buffer
+	binding int
+
+	typ    reflect.Type
+	layout *std140Layout
+	// the encoded struct, and the one of the next Update
+	data []byte
+	next []byte
+	// the struct, for the contexts without uniform buffers
+	value   reflect.Value
+	version int
+}
+
+// NewUniformBuffer creates a uniform buffer on the default context Gl.
+func NewUniformBuffer(binding int, v interface{}) (*UniformBuffer, error) {
+	return Gl.NewUniformBuffer(binding, v)
+}
+
+// NewUniformBuffer creates a uniform buffer holding the struct v, or the one
+// v points to, bound to the binding point binding.
+func (c *Context) NewUniformBuffer(binding int, v interface{}) (*UniformBuffer, error) {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("glplus: uniform buffer of %T, not a struct", v)
+	}
+	layout, err := newStd140Layout(rv.Type())
+	if err != nil {
+		return nil, err
+	}
+	if binding < 0 || (c.Caps.UniformBuffers && binding >= c.Caps.MaxUniformBufferBindings) {
+		return nil, fmt.Errorf("glplus: uniform buffer binding %d out of range [0, %d)", binding, c.Caps.MaxUniformBufferBindings)
+	}
+	u := &UniformBuffer{
+		ctx:     c,
+		binding: binding,
+		typ:     rv.Type(),
+		layout:  layout,
+		data:    make([]byte, layout.size),
+		next:    make([]byte, layout.size),
+		value:   reflect.New(rv.Type()).Elem(),
+	}
+	u.value.Set(rv)
+	layout.encode(rv, u.data)
+	if c.Caps.UniformBuffers {
+		u.create()
+	}
+	c.Register(u)
+	return u, nil
+}
+
+func (u *UniformBuffer) create() {
+	c := u.ctx
+	u.buffer = c.CreateBuffer()
+	c.BindBuffer(c.UNIFORM_BUFFER, u.buffer)
+	c.BufferData(c.UNIFORM_BUFFER, u.data, c.DYNAMIC_DRAW)
+	c.BindBufferBase(c.UNIFORM_BUFFER, u.binding, u.buffer)
+}
+
+// Binding is the binding point of the buffer.
+func (u *UniformBuffer) Binding() int {
+	return u.binding
+}
+
+// Size is the size of the std140 layout, in bytes.
+func (u *UniformBuffer) Size() int {
+	return u.layout.size
+}
+
+// Update sets a new value of the struct, of the type of the one the buffer
+// was created with, and uploads it unless it is unchanged.
+func (u *UniformBuffer) Update(v interface{}) error {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if !rv.IsValid() || rv.Type() != u.typ {
+		return fmt.Errorf("glplus: uniform buffer of %s, updated with %T", u.typ, v)
+	}
+	u.layout.encode(rv, u.next)
+	if bytes.Equal(u.next, u.data) {
+		return nil
+	}
+	u.data, u.next = u.next, u.data
+	u.value.Set(rv)
+	u.version++
+	if u.buffer != nil {
+		c := u.ctx
+		c.BindBuffer(c.UNIFORM_BUFFER, u.buffer)
+		c.BufferSubData(c.UNIFORM_BUFFER, 0, u.data)
+	}
+	return nil
+}
+
+// Delete ...
+func (u *UniformBuffer) Delete() {
+	if u.buffer != nil {
+		u.ctx.DeleteBuffer(u.buffer)
+		u.buffer = nil
+	}
+	u.ctx.Unregister(u)
+}
+
+// Restore re-creates the buffer after the context was lost.
+func (u *UniformBuffer) Restore() error {
+	u.buffer = nil
+	if u.ctx.Caps.UniformBuffers {
+		u.create()
+	}
+	return nil
+}
+
+// boundBlock is a uniform block of a program bound to a buffer.
+type boundBlock struct {
+	buffer *UniformBuffer
+	// version is the one of the buffer the uniforms were set from, without
+	// uniform buffers; -1 until they are
+	version int
+}
+
+// BindUniformBlock binds the uniform block name of the program to the
+// binding point of u. Without uniform buffers, where the members of the
+// block are plain uniforms, UseProgram sets the ones the program uses from
+// the struct of u when it was updated.
+func (p *GPProgram) BindUniformBlock(name string, u *UniformBuffer) error {
+	if p.ctx.Caps.UniformBuffers {
+		if err := p.bindBlock(name, u); err != nil {
+			return err
+		}
+	} else {
+		b, err := p.binding(u.typ, true)
+		if err != nil {
+			return err
+		}
+		// the fields have a fixed size, they fit for good
+		if err := p.checkFields(b, u.value); err != nil {
+			return err
+		}
+	}
+	if p.blocks == nil {
+		p.blocks = make(map[string]*boundBlock)
+	}
+	p.blocks[name] = &boundBlock{buffer: u, version: -1}
+	return nil
+}
+
+func (p *GPProgram) bindBlock(name string, u *UniformBuffer) error {
+	index := p.ctx.GetUniformBlockIndex(p.prog, name)
+	if index == p.ctx.INVALID_INDEX {
+		return fmt.Errorf("glplus: no active uniform block %s", name)
+	}
+	p.ctx.UniformBlockBinding(p.prog, index, u.binding)
+	return nil
+}
+
+// rebindBlocks binds the blocks of a program built again.
+func (p *GPProgram) rebindBlocks() error {
+	for name, b := range p.blocks {
+		b.version = -1
+		if p.ctx.Caps.UniformBuffers {
+			if err := p.bindBlock(name, b.buffer); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// updateBlocks sets the uniforms of the blocks whose buffer was updated
+// since the program was last used, without uniform buffers.
+func (p *GPProgram) updateBlocks() {
+	for _, b := range p.blocks {
+		if b.version == b.buffer.version {
+			continue
+		}
+		binding, err := p.binding(b.buffer.typ, true)
+		if err == nil {
+			err = p.uploadFields(binding, b.buffer.value)
+		}
+		if err != nil {
+			// BindUniformBlock checked the fields
+			panic(err)
+		}
+		b.version = b.buffer.version
+	}
+}
@@ -0,0 +1,166 @@
+package glplus
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+const (
+	sVertShaderBlock = `#version 330
+  layout(std140) uniform Camera {
+    mat4 mView;
+    vec3 light;
+    float unused;
+  };
+  ATTRIBUTE vec4 position;
+  VARYINGOUT float shade;
+  void main()
+  {
+    gl_Position = mView * position;
+    shade = dot(light, vec3(0.0, 0.0, 1.0));
+  }`
+
+	sFragShaderBlock = `#version 330
+  VARYINGIN float shade;
+  COLOROUT
+  void main()
+  {
+    FRAGCOLOR = vec4(shade);
+  }`
+)
+
+type testCamera struct {
+	View   mgl32.Mat4 `glsl:"mView"`
+	Light  mgl32.Vec3 `glsl:"light"`
+	Unused float32    `glsl:"unused"`
+}
+
+// TestUniformBufferFallback runs without uniform buffers, as WebGL 1: the
+// members of the blocks are plain uniforms.
+func TestUniformBufferFallback(t *testing.T) {
+	defer softContext(t)()
+
+	prog, err := LoadShaderProgram(sVertShaderBlock, sFragShaderBlock, []string{"position"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer prog.DeleteProgram()
+	if prog.ActiveUniform("mView") == nil {
+		t.Fatalf("uniforms %v", prog.ActiveUniforms())
+	}
+
+	camera := testCamera{View: mgl32.Translate3D(1, 2, 3), Light: mgl32.Vec3{4, 5, 6}}
+	ubo, err := NewUniformBuffer(0, &camera)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ubo.Delete()
+	if err := prog.BindUniformBlock("Camera", ubo); err != nil {
+		t.Fatal(err)
+	}
+	uniform := func(name string) []float32 {
+		return Gl.NativeBackend.(*softBackend).program.linked.Uniform(int(prog.GetUniformLocation(name).int32))
+	}
+
+	prog.UseProgram()
+	if got := uniform("mView"); !reflect.DeepEqual(got, camera.View[:]) {
+		t.Errorf("mView = %v", got)
+	}
+	if got := uniform("light"); !reflect.DeepEqual(got, camera.Light[:]) {
+		t.Errorf("light = %v", got)
+	}
+
+	camera.Light = mgl32.Vec3{7, 8, 9}
+	if err := ubo.Update(camera); err != nil {
+		t.Fatal(err)
+	}
+	prog.UnuseProgram()
+	prog.UseProgram()
+	if got := uniform("light"); !reflect.DeepEqual(got, camera.Light[:]) {
+		t.Errorf("light = %v after Update", got)
+	}
+
+	if err := ubo.Update(&struct{}{}); err == nil || err.Error() != "glplus: uniform buffer of glplus.testCamera, updated with *struct {}" {
+		t.Errorf("got %v", err)
+	}
+	if err := prog.BindUniformBlock("Camera", &UniformBuffer{typ: reflect.TypeOf(testRequired{})}); err == nil ||
+		err.Error() != "glplus: field glplus.testRequired.F: unknown option \"required\"" {
+		t.Errorf("got %v", err)
+	}
+
+	// the members a program lacks are skipped
+	other, err := LoadShaderProgram(sVertShaderUniforms, sFragShaderUniforms, []string{"position", "uvs"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer other.DeleteProgram()
+	if err := other.BindUniformBlock("Camera", ubo); err != nil {
+		t.Error(err)
+	}
+	other.UseProgram()
+
+	if err := Gl.GetError(); err != Gl.NO_ERROR {
+		t.Errorf("GL error 0x%X", err)
+	}
+}
+
+// TestUniformBuffer uploads the std140 layout to the buffers of the software
+// backend, which its shaders cannot read.
+func TestUniformBuffer(t *testing.T) {
+	defer softContext(t)()
+	Gl.Caps.UniformBuffers = true
+	Gl.Caps.MaxUniformBufferBindings = softMaxUniformBufferBindings
+	soft := Gl.NativeBackend.(*softBackend)
+
+	camera := testCamera{View: mgl32.Ident4(), Light: mgl32.Vec3{1, 2, 3}, Unused: 4}
+	ubo, err := NewUniformBuffer(3, camera)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ubo.Size() != 80 || ubo.Binding() != 3 {
+		t.Errorf("size %d, binding %d", ubo.Size(), ubo.Binding())
+	}
+	want := make([]byte, 80)
+	ubo.layout.encode(reflect.ValueOf(camera), want)
+	if bound := soft.uniformBases[3]; bound == nil || !bytes.Equal(bound.data, want) {
+		t.Errorf("binding 3 holds %v", bound)
+	}
+
+	camera.Unused = 5
+	if err := ubo.Update(&camera); err != nil {
+		t.Fatal(err)
+	}
+	ubo.layout.encode(reflect.ValueOf(camera), want)
+	if !bytes.Equal(soft.uniformBases[3].data, want) {
+		t.Errorf("not updated")
+	}
+
+	// the software shaders have no blocks
+	prog, err := LoadShaderProgram(sVertShaderUniforms, sFragShaderUniforms, []string{"position", "uvs"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer prog.DeleteProgram()
+	if err := prog.BindUniformBlock("Camera", ubo); err == nil || err.Error() != "glplus: no active uniform block Camera" {
+		t.Errorf("got %v", err)
+	}
+
+	if _, err := NewUniformBuffer(softMaxUniformBufferBindings, camera); err == nil ||
+		err.Error() != "glplus: uniform buffer binding 24 out of range [0, 24)" {
+		t.Errorf("got %v", err)
+	}
+	if _, err := NewUniformBuffer(0, 3); err == nil || err.Error() != "glplus: uniform buffer of int, not a struct" {
+		t.Errorf("got %v", err)
+	}
+
+	ubo.Delete()
+	if soft.uniformBases[3] != nil {
+		t.Errorf("deleted buffer still bound")
+	}
+	if err := Gl.GetError(); err != Gl.NO_ERROR {
+		t.Errorf("GL error 0x%X", err)
+	}
+}
@@ -0,0 +1,292 @@
+package glplus
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// UniformInfo is an active uniform of a linked program. Type is a GL type
+// such as FLOAT_VEC4 or SAMPLER_2D, Size the length of an array and 1
+// otherwise. The [0] GL appends to array names is dropped.
+type UniformInfo struct {
+	Name     string
+	Type     int
+	Size     int
+	Location *UniformLocation
+}
+
+// AttribInfo is an active attribute of a linked program.
+type AttribInfo struct {
+	Name     string
+	Type     int
+	Size     int
+	Location int
+}
+
+// queryActive reflects the active uniforms and attributes of the linked
+// program.
+func (p *GPProgram) queryActive() {
+	c := p.ctx
+	p.activeUniforms = nil
+	p.uniformInfos = make(map[string]*UniformInfo)
+	p.bindings = make(map[bindingKey]*uniformBinding)
+	p.uploaded = make(map[string]*uploadedValue)
+	for i, n := 0, c.GetProgramParameteri(p.prog, c.ACTIVE_UNIFORMS); i < n; i++ {
+		name, size, typ := c.GetActiveUniform(p.prog, i)
+		name = strings.TrimSuffix(name, "[0]")
+		info := &UniformInfo{Name: name, Type: typ, Size: size, Location: p.GetUniformLocation(name)}
+		p.activeUniforms = append(p.activeUniforms, info)
+		p.uniformInfos[name] = info
+	}
+
+	p.activeAttribs = nil
+	for i, n := 0, c.GetProgramParameteri(p.prog, c.ACTIVE_ATTRIBUTES); i < n; i++ {
+		name, size, typ := c.GetActiveAttrib(p.prog, i)
+		p.activeAttribs = append(p.activeAttribs, &AttribInfo{Name: name, Type: typ, Size: size, Location: c.GetAttribLocation(p.prog, name)})
+	}
+}
+
+// ActiveUniforms are the uniforms the program uses, in the order of
+// GetActiveUniform. A uniform the compiler optimized out is not active.
+func (p *GPProgram) ActiveUniforms() []*UniformInfo {
+	return p.activeUniforms
+}
+
+// ActiveUniform returns the active uniform name, or nil.
+func (p *GPProgram) ActiveUniform(name string) *UniformInfo {
+	return p.uniformInfos[name]
+}
+
+// ActiveAttribs are the attributes the program uses.
+func (p *GPProgram) ActiveAttribs() []*AttribInfo {
+	return p.activeAttribs
+}
+
+// uniform finds the active uniform of name, or of the element name is, as
+// in "lights[2]", and checks that its type is one of types. The setters call
+// it before they set the uniform, so SetUniforms forgets its last upload.
+func (p *GPProgram) uniform(name string, types ...int) (info *UniformInfo, elem int, err error) {
+	if info, elem, err = p.findUniform(name); err != nil {
+		return nil, 0, err
+	}
+	delete(p.uploaded, info.Name)
+	if len(types) == 0 {
+		return info, elem, nil
+	}
+	for _, typ := range types {
+		if info.Type == typ {
+			return info, elem, nil
+		}
+	}
+	return nil, 0, fmt.Errorf("glplus: uniform %s has type %s, not %s", name, p.ctx.typeName(info.Type), p.ctx.typeName(types[0]))
+}
+
+// findUniform finds the active uniform of name, or of the element name is.
+func (p *GPProgram) findUniform(name string) (info *UniformInfo, elem int, err error) {
+	base := name
+	if i := strings.IndexByte(name, '['); i > 0 && strings.HasSuffix(name, "]") {
+		if elem, err = strconv.Atoi(name[i+1 : len(name)-1]); err != nil {
+			return nil, 0, fmt.Errorf("glplus: uniform %s: invalid index", name)
+		}
+		base = name[:i]
+	}
+	if info = p.uniformInfos[base]; info == nil {
+		return nil, 0, fmt.Errorf("glplus: no active uniform %s", base)
+	}
+	if elem < 0 || elem >= info.Size {
+		return nil, 0, fmt.Errorf("glplus: uniform %s: index out of range [0, %d)", name, info.Size)
+	}
+	return info, elem, nil
+}
+
+// location is the location of an element of an active uniform.
+func (p *GPProgram) location(info *UniformInfo, elem int) *UniformLocation {
+	if elem == 0 {
+		return info.Location
+	}
+	return p.GetUniformLocation(fmt.Sprintf("%s[%d]", info.Name, elem))
+}
+
+// SetUniform sets the active uniform name, which can be an array element
+// as in "lights[2]", dispatching on its reflected type. value is a number, a
+// bool, or an array or a slice of them for vectors, column-major matrices and
+// arrays: float32(0.5), [4]float32{...}, mgl32.Mat2{...}, []int32{1, 2} for
+// an ivec2, [][3]float32 for a vec3 array. An array can be set partially,
+// from the element named on.
+func (p *GPProgram) SetUniform(name string, value interface{}) error {
+	info, elem, err := p.uniform(name)
+	if err != nil {
+		return err
+	}
+	values, floats, ok := flattenUniform(reflect.ValueOf(value), nil, false)
+	if !ok {
+		return fmt.Errorf("glplus: uniform %s: unsupported value %T", name, value)
+	}
+	n, isInt, err := p.checkValues(name, info, elem, values, floats)
+	if err != nil {
+		return err
+	}
+	for k := 0; k < len(values)/n; k++ {
+		p.setUniform(p.location(info, elem+k), info.Type, isInt, values[k*n:(k+1)*n])
+	}
+	return nil
+}
+
+// checkValues checks that flattened values fit a uniform from the element
+// elem on, and returns the number of values of an element.
+func (p *GPProgram) checkValues(name string, info *UniformInfo, elem int, values []float64, floats bool) (n int, isInt bool, err error) {
+	n, isInt = p.ctx.components(info.Type)
+	if n == 0 {
+		return 0, false, fmt.Errorf("glplus: uniform %s: unsupported type %s", name, p.ctx.typeName(info.Type))
+	}
+	if floats && isInt && !p.ctx.isBoolType(info.Type) {
+		return 0, false, fmt.Errorf("glplus: uniform %s has type %s, not float", name, p.ctx.typeName(info.Type))
+	}
+	count := len(values) / n
+	if len(values)%n != 0 || count == 0 || elem+count > info.Size {
+		return 0, false, fmt.Errorf("glplus: uniform %s has type %s, %d values do not fit", name, p.ctx.typeName(info.Type), len(values))
+	}
+	return n, isInt, nil
+}
+
+func (p *GPProgram) setUniform(location *UniformLocation, typ int, isInt bool, v []float64) {
+	c := p.ctx
+	if typ == c.FLOAT_MAT2 || typ == c.FLOAT_MAT3 || typ == c.FLOAT_MAT4 {
+		m := make([]float32, len(v))
+		for i := range v {
+			m[i] = float32(v[i])
+		}
+		switch typ {
+		case c.FLOAT_MAT2:
+			c.UniformMatrix2fv(location, false, m)
+		case c.FLOAT_MAT3:
+			c.UniformMatrix3fv(location, false, m)
+		default:
+			c.UniformMatrix4fv(location, false, m)
+		}
+		return
+	}
+	if isInt {
+		switch len(v) {
+		case 1:
+			c.Uniform1i(location, int(v[0]))
+		case 2:
+			c.Uniform2i(location, int(v[0]), int(v[1]))
+		case 3:
+			c.Uniform3i(location, int(v[0]), int(v[1]), int(v[2]))
+		default:
+			c.Uniform4i(location, int(v[0]), int(v[1]), int(v[2]), int(v[3]))
+		}
+		return
+	}
+	switch len(v) {
+	case 1:
+		c.Uniform1f(location, float32(v[0]))
+	case 2:
+		c.Uniform2f(location, float32(v[0]), float32(v[1]))
+	case 3:
+		c.Uniform3f(location, float32(v[0]), float32(v[1]), float32(v[2]))
+	default:
+		c.Uniform4f(location, float32(v[0]), float32(v[1]), float32(v[2]), float32(v[3]))
+	}
+}
+
+// flattenUniform appends the numbers of v, and tells whether some are
+// floats.
+func flattenUniform(v reflect.Value, values []float64, floats bool) ([]float64, bool, bool) {
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return append(values, v.Float()), true, true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return append(values, float64(v.Int())), floats, true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return append(values, float64(v.Uint())), floats, true
+	case reflect.Bool:
+		if v.Bool() {
+			return append(values, 1), floats, true
+		}
+		return append(values, 0), floats, true
+	case reflect.Array, reflect.Slice:
+		ok := true
+		for i := 0; i < v.Len() && ok; i++ {
+			values, floats, ok = flattenUniform(v.Index(i), values, floats)
+		}
+		return values, floats, ok
+	}
+	return values, floats, false
+}
+
+// components is the number of values of a uniform of a GL type, and
+// whether the glUniform*i family sets it; 0 for an unknown type.
+func (e *Enums) components(typ int) (n int, isInt bool) {
+	switch typ {
+	case e.FLOAT:
+		return 1, false
+	case e.FLOAT_VEC2:
+		return 2, false
+	case e.FLOAT_VEC3:
+		return 3, false
+	case e.FLOAT_VEC4, e.FLOAT_MAT2:
+		return 4, false
+	case e.FLOAT_MAT3:
+		return 9, false
+	case e.FLOAT_MAT4:
+		return 16, false
+	case e.INT, e.BOOL, e.SAMPLER_2D, e.SAMPLER_CUBE:
+		return 1, true
+	case e.INT_VEC2, e.BOOL_VEC2:
+		return 2, true
+	case e.INT_VEC3, e.BOOL_VEC3:
+		return 3, true
+	case e.INT_VEC4, e.BOOL_VEC4:
+		return 4, true
+	}
+	return 0, false
+}
+
+func (e *Enums) isBoolType(typ int) bool {
+	return typ == e.BOOL || typ == e.BOOL_VEC2 || typ == e.BOOL_VEC3 || typ == e.BOOL_VEC4
+}
+
+// typeName is the GLSL name of a GL type.
+func (e *Enums) typeName(typ int) string {
+	switch typ {
+	case e.FLOAT:
+		return "float"
+	case e.FLOAT_VEC2:
+		return "vec2"
+	case e.FLOAT_VEC3:
+		return "vec3"
+	case e.FLOAT_VEC4:
+		return "vec4"
+	case e.FLOAT_MAT2:
+		return "mat2"
+	case e.FLOAT_MAT3:
+		return "mat3"
+	case e.FLOAT_MAT4:
+		return "mat4"
+	case e.INT:
+		return "int"
+	case e.INT_VEC2:
+		return "ivec2"
+	case e.INT_VEC3:
+		return "ivec3"
+	case e.INT_VEC4:
+		return "ivec4"
+	case e.BOOL:
+		return "bool"
+	case e.BOOL_VEC2:
+		return "bvec2"
+	case e.BOOL_VEC3:
+		return "bvec3"
+	case e.BOOL_VEC4:
+		return "bvec4"
+	case e.SAMPLER_2D:
+		return "sampler2D"
+	case e.SAMPLER_CUBE:
+		return "samplerCube"
+	}
+	return fmt.Sprintf("type 0x%X", typ)
+}
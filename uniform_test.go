@@ -0,0 +1,183 @@
+package glplus
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+const (
+	sVertShaderUniforms = `#version 330
+  ATTRIBUTE vec4 position;
+  ATTRIBUTE vec2 uvs;
+  VARYINGOUT vec2 out_uvs;
+  uniform mat4 m4;
+  uniform mat3 m3;
+  uniform mat2 m2;
+  uniform vec2 offsets[2];
+  void main()
+  {
+    vec3 p = m3 * vec3(m2 * position.xy + offsets[0] + offsets[1], 1.0);
+    gl_Position = m4 * vec4(p, 1.0);
+    out_uvs = uvs;
+  }`
+
+	sFragShaderUniforms = `#version 330
+  VARYINGIN vec2 out_uvs;
+  uniform sampler2D tex1;
+  uniform float f;
+  uniform vec3 v3;
+  uniform vec4 v4;
+  uniform int i;
+  uniform ivec3 iv;
+  uniform bool b;
+  uniform float weights[3];
+  COLOROUT
+
+  void main()
+  {
+    vec4 c = TEXTURE2D(tex1, out_uvs) * v4 + vec4(v3 * f, weights[0] + weights[1] + weights[2]);
+    if (b && i + iv.x + iv.y + iv.z > 0) {
+      c = vec4(1.0);
+    }
+    FRAGCOLOR = c;
+  }`
+)
+
+func TestActiveUniforms(t *testing.T) {
+	defer softContext(t)()
+
+	prog, err := LoadShaderProgram(sVertShaderUniforms, sFragShaderUniforms, []string{"position", "uvs"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer prog.DeleteProgram()
+
+	for _, want := range []UniformInfo{
+		{Name: "m4", Type: Gl.FLOAT_MAT4, Size: 1},
+		{Name: "offsets", Type: Gl.FLOAT_VEC2, Size: 2},
+		{Name: "tex1", Type: Gl.SAMPLER_2D, Size: 1},
+		{Name: "iv", Type: Gl.INT_VEC3, Size: 1},
+		{Name: "b", Type: Gl.BOOL, Size: 1},
+		{Name: "weights", Type: Gl.FLOAT, Size: 3},
+	} {
+		u := prog.ActiveUniform(want.Name)
+		if u == nil || u.Type != want.Type || u.Size != want.Size || u.Location != prog.GetUniformLocation(want.Name) {
+			t.Errorf("%s: got %+v", want.Name, u)
+		}
+	}
+	if n := len(prog.ActiveUniforms()); n != 12 {
+		t.Errorf("%d active uniforms", n)
+	}
+	if prog.ActiveUniform("color") != nil {
+		t.Errorf("color is active")
+	}
+
+	attribs := prog.ActiveAttribs()
+	if len(attribs) != 2 || *attribs[0] != (AttribInfo{Name: "position", Type: Gl.FLOAT_VEC4, Size: 1, Location: 0}) ||
+		*attribs[1] != (AttribInfo{Name: "uvs", Type: Gl.FLOAT_VEC2, Size: 1, Location: 1}) {
+		t.Errorf("attribs %+v %+v", attribs[0], attribs[1])
+	}
+}
+
+func TestSetUniform(t *testing.T) {
+	defer softContext(t)()
+
+	prog, err := LoadShaderProgram(sVertShaderUniforms, sFragShaderUniforms, []string{"position", "uvs"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer prog.DeleteProgram()
+	prog.UseProgram()
+	linked := Gl.NativeBackend.(*softBackend).program.linked
+
+	for _, test := range []struct {
+		name  string
+		value interface{}
+		want  []float32
+	}{
+		{"f", float32(0.5), []float32{0.5}},
+		{"f", 2, []float32{2}},
+		{"v3", mgl32.Vec3{1, 2, 3}, []float32{1, 2, 3}},
+		{"v4", []float64{1, 2, 3, 4}, []float32{1, 2, 3, 4}},
+		{"i", 7, []float32{7}},
+		{"iv", [3]int32{1, 2, 3}, []float32{1, 2, 3}},
+		{"b", true, []float32{1}},
+		{"tex1", 2, []float32{2}},
+		{"m2", mgl32.Mat2{1, 2, 3, 4}, []float32{1, 2, 3, 4}},
+		{"m3", mgl32.Ident3(), []float32{1, 0, 0, 0, 1, 0, 0, 0, 1}},
+		{"weights", []float32{1, 2, 3}, []float32{1}},
+		{"weights[2]", float32(9), []float32{9}},
+		{"offsets", [][2]float32{{1, 2}, {3, 4}}, []float32{1, 2}},
+		{"offsets[1]", mgl32.Vec2{5, 6}, []float32{5, 6}},
+	} {
+		if err := prog.SetUniform(test.name, test.value); err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		loc := prog.GetUniformLocation(test.name)
+		if got := linked.Uniform(int(loc.int32)); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s = %v, want %v", test.name, got, test.want)
+		}
+	}
+	if got := linked.Uniform(int(prog.GetUniformLocation("weights[1]").int32)); !reflect.DeepEqual(got, []float32{2}) {
+		t.Errorf("weights[1] = %v", got)
+	}
+	if err := Gl.GetError(); err != Gl.NO_ERROR {
+		t.Errorf("GL error 0x%X", err)
+	}
+
+	for _, test := range []struct {
+		name  string
+		value interface{}
+		err   string
+	}{
+		{"color", [4]float32{}, "glplus: no active uniform color"},
+		{"i", 1.5, "glplus: uniform i has type int, not float"},
+		{"tex1", float32(0), "glplus: uniform tex1 has type sampler2D, not float"},
+		{"v3", []float32{1, 2}, "glplus: uniform v3 has type vec3, 2 values do not fit"},
+		{"weights", []float32{1, 2, 3, 4}, "glplus: uniform weights has type float, 4 values do not fit"},
+		{"weights[1]", []float32{1, 2, 3}, "glplus: uniform weights[1] has type float, 3 values do not fit"},
+		{"weights[3]", float32(1), "glplus: uniform weights[3]: index out of range [0, 3)"},
+		{"f", "one", "glplus: uniform f: unsupported value string"},
+	} {
+		if err := prog.SetUniform(test.name, test.value); err == nil || err.Error() != test.err {
+			t.Errorf("%s: got %v, want %s", test.name, err, test.err)
+		}
+	}
+}
+
+func TestProgramUniformErrors(t *testing.T) {
+	defer softContext(t)()
+
+	prog, err := LoadShaderProgram(sVertShaderUniforms, sFragShaderUniforms, []string{"position", "uvs"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer prog.DeleteProgram()
+	prog.UseProgram()
+
+	if err := prog.ProgramUniform4fv("v4", [4]float32{1, 2, 3, 4}); err != nil {
+		t.Error(err)
+	}
+	if err := prog.ProgramUniform1i("b", 1); err != nil {
+		t.Error(err)
+	}
+	if err := prog.ProgramUniform1f("weights[2]", 1); err != nil {
+		t.Error(err)
+	}
+	for _, test := range []struct {
+		err  error
+		want string
+	}{
+		{prog.ProgramUniform4fv("color", [4]float32{}), "glplus: no active uniform color"},
+		{prog.ProgramUniform1f("v4", 1), "glplus: uniform v4 has type vec4, not float"},
+		{prog.ProgramUniformMatrix4fv("m3", mgl32.Ident4()), "glplus: uniform m3 has type mat3, not mat4"},
+		{prog.ProgramUniform1i("f", 0), "glplus: uniform f has type float, not int"},
+	} {
+		if test.err == nil || test.err.Error() != test.want {
+			t.Errorf("got %v, want %s", test.err, test.want)
+		}
+	}
+}
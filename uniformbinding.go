@@ -0,0 +1,211 @@
+package glplus
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// uniformField binds a struct field to an active uniform, from the element
+// elem on.
+type uniformField struct {
+	index     []int
+	name      string
+	info      *UniformInfo
+	elem      int
+	locations []*UniformLocation
+
+	// scratch for the flattened value
+	values []float64
+}
+
+// uniformBinding binds the tagged fields of a struct type to the uniforms of
+// a program.
+type uniformBinding struct {
+	fields []*uniformField
+}
+
+// uploadedValue is the value SetUniforms uploaded last to a uniform, through
+// the field named name.
+type uploadedValue struct {
+	name   string
+	values []float64
+}
+
+// SetUniforms sets the uniforms of the fields of a struct, or of a pointer to
+// one, tagged with the name of their uniform:
+//
+//	type ObjUniforms struct {
+//		ProjViewModel mgl32.Mat4 `glsl:"mProjViewModel"`
+//		Light         mgl32.Vec3 `glsl:"light"`
+//		UV            mgl32.Mat3 `glsl:"matuv,optional"`
+//	}
+//
+// Fields are converted as by SetUniform. The uniform of an optional field may
+// be inactive, the field is then skipped. Untagged fields and fields tagged
+// "-" are ignored, the fields of an untagged embedded struct are set.
+//
+// The fields are bound to the locations once per program and struct type, and
+// a value equal to the one SetUniforms set last is not uploaded again; the
+// setters of GPProgram are seen, values set through the Context directly are
+// not. The program must be in use.
+func (p *GPProgram) SetUniforms(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("glplus: SetUniforms of %T, not a struct", v)
+	}
+	b, err := p.binding(rv.Type(), false)
+	if err != nil {
+		return err
+	}
+	return p.uploadFields(b, rv)
+}
+
+// bindingKey tells apart the bindings of the uniform buffers, whose fields
+// are all optional.
+type bindingKey struct {
+	t        reflect.Type
+	optional bool
+}
+
+// binding returns the binding of the struct type t, resolving it the first
+// time. With optional, every field is optional.
+func (p *GPProgram) binding(t reflect.Type, optional bool) (*uniformBinding, error) {
+	key := bindingKey{t, optional}
+	if b := p.bindings[key]; b != nil {
+		return b, nil
+	}
+	b := &uniformBinding{}
+	err := taggedFields(t, nil, func(owner reflect.Type, sf reflect.StructField, index []int, name string, opt bool) error {
+		info, elem, err := p.findUniform(name)
+		if err != nil {
+			if (optional || opt) && p.uniformInfos[uniformBase(name)] == nil {
+				return nil
+			}
+			return fmt.Errorf("%v (field %s.%s)", err, owner, sf.Name)
+		}
+		f := &uniformField{index: index, name: name, info: info, elem: elem}
+		for k := elem; k < info.Size; k++ {
+			f.locations = append(f.locations, p.location(info, k))
+		}
+		b.fields = append(b.fields, f)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	p.bindings[key] = b
+	return b, nil
+}
+
+// taggedFields calls fn with the fields of the struct type t tagged with a
+// uniform name, in order, walking the untagged embedded structs. index is the
+// index of t in the outer struct.
+func taggedFields(t reflect.Type, index []int, fn func(owner reflect.Type, sf reflect.StructField, index []int, name string, optional bool) error) error {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		fieldIndex := append(append([]int(nil), index...), i)
+		tag, ok := sf.Tag.Lookup("glsl")
+		if !ok {
+			if sf.Anonymous && sf.Type.Kind() == reflect.Struct {
+				if err := taggedFields(sf.Type, fieldIndex, fn); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		name, optional := tag, false
+		if comma := strings.IndexByte(tag, ','); comma >= 0 {
+			name = tag[:comma]
+			if opt := tag[comma+1:]; opt == "optional" {
+				optional = true
+			} else {
+				return fmt.Errorf("glplus: field %s.%s: unknown option %q", t, sf.Name, opt)
+			}
+		}
+		if name == "-" {
+			continue
+		}
+		if err := fn(t, sf, fieldIndex, name, optional); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// uploadFields sets the uniforms of the fields of the struct v.
+func (p *GPProgram) uploadFields(b *uniformBinding, v reflect.Value) error {
+	for _, f := range b.fields {
+		if err := p.uploadField(f, v.FieldByIndex(f.index)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkFields checks that the fields of the struct v fit their uniforms,
+// without setting them.
+func (p *GPProgram) checkFields(b *uniformBinding, v reflect.Value) error {
+	for _, f := range b.fields {
+		field := v.FieldByIndex(f.index)
+		values, floats, ok := flattenUniform(field, nil, false)
+		if !ok {
+			return fmt.Errorf("glplus: uniform %s: unsupported value %s", f.name, field.Type())
+		}
+		if _, _, err := p.checkValues(f.name, f.info, f.elem, values, floats); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// uploadField sets the uniform of a field unless SetUniforms set the same
+// value last.
+func (p *GPProgram) uploadField(f *uniformField, v reflect.Value) error {
+	values, floats, ok := flattenUniform(v, f.values[:0], false)
+	if !ok {
+		return fmt.Errorf("glplus: uniform %s: unsupported value %s", f.name, v.Type())
+	}
+	f.values = values
+	n, isInt, err := p.checkValues(f.name, f.info, f.elem, values, floats)
+	if err != nil {
+		return err
+	}
+	last := p.uploaded[f.info.Name]
+	if last != nil && last.name == f.name && equalValues(last.values, values) {
+		return nil
+	}
+	for k := 0; k < len(values)/n; k++ {
+		p.setUniform(f.locations[k], f.info.Type, isInt, values[k*n:(k+1)*n])
+	}
+	if last == nil {
+		last = &uploadedValue{}
+		p.uploaded[f.info.Name] = last
+	}
+	last.name = f.name
+	last.values = append(last.values[:0], values...)
+	return nil
+}
+
+// uniformBase is the name of the uniform of an array element name.
+func uniformBase(name string) string {
+	if i := strings.IndexByte(name, '['); i > 0 {
+		return name[:i]
+	}
+	return name
+}
+
+func equalValues(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,137 @@
+package glplus
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+type testLights struct {
+	Offsets [2]mgl32.Vec2 `glsl:"offsets"`
+	Weight  float32       `glsl:"weights[1]"`
+}
+
+type testUniforms struct {
+	testLights
+	M3       mgl32.Mat3 `glsl:"m3"`
+	F        float32    `glsl:"f"`
+	IV       [3]int32   `glsl:"iv"`
+	B        bool       `glsl:"b"`
+	Color    [4]float32 `glsl:"color,optional"`
+	Ignored  float32    `glsl:"-"`
+	Untagged float32
+}
+
+type testColor struct {
+	Color [4]float32 `glsl:"color"`
+}
+
+type testRequired struct {
+	F float32 `glsl:"f,required"`
+}
+
+func TestSetUniforms(t *testing.T) {
+	defer softContext(t)()
+
+	prog, err := LoadShaderProgram(sVertShaderUniforms, sFragShaderUniforms, []string{"position", "uvs"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer prog.DeleteProgram()
+	prog.UseProgram()
+	linked := Gl.NativeBackend.(*softBackend).program.linked
+	uniform := func(name string) []float32 {
+		return linked.Uniform(int(prog.GetUniformLocation(name).int32))
+	}
+
+	u := testUniforms{
+		testLights: testLights{Offsets: [2]mgl32.Vec2{{1, 2}, {3, 4}}, Weight: 5},
+		M3:         mgl32.Ident3(),
+		F:          0.5,
+		IV:         [3]int32{1, 2, 3},
+		B:          true,
+	}
+	if err := prog.SetUniforms(&u); err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string][]float32{
+		"offsets[0]": {1, 2},
+		"offsets[1]": {3, 4},
+		"weights[1]": {5},
+		"m3":         {1, 0, 0, 0, 1, 0, 0, 0, 1},
+		"f":          {0.5},
+		"iv":         {1, 2, 3},
+		"b":          {1},
+	} {
+		if got := uniform(name); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s = %v, want %v", name, got, want)
+		}
+	}
+
+	// an unchanged value is not uploaded again, a changed one is
+	Gl.Uniform1f(prog.GetUniformLocation("f"), 3)
+	Gl.Uniform1i(prog.GetUniformLocation("b"), 0)
+	u.B = false
+	if err := prog.SetUniforms(u); err != nil {
+		t.Fatal(err)
+	}
+	if got := uniform("f"); !reflect.DeepEqual(got, []float32{3}) {
+		t.Errorf("f = %v, uploaded again", got)
+	}
+	if got := uniform("b"); !reflect.DeepEqual(got, []float32{0}) {
+		t.Errorf("b = %v", got)
+	}
+
+	// the setters of the program are seen
+	if err := prog.ProgramUniform1f("f", 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := prog.SetUniforms(&u); err != nil {
+		t.Fatal(err)
+	}
+	if got := uniform("f"); !reflect.DeepEqual(got, []float32{0.5}) {
+		t.Errorf("f = %v, not uploaded after ProgramUniform1f", got)
+	}
+
+	// the binding is resolved once
+	if n := len(prog.bindings); n != 1 {
+		t.Errorf("%d bindings", n)
+	}
+	if err := Gl.GetError(); err != Gl.NO_ERROR {
+		t.Errorf("GL error 0x%X", err)
+	}
+}
+
+func TestSetUniformsErrors(t *testing.T) {
+	defer softContext(t)()
+
+	prog, err := LoadShaderProgram(sVertShaderUniforms, sFragShaderUniforms, []string{"position", "uvs"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer prog.DeleteProgram()
+	prog.UseProgram()
+
+	for _, test := range []struct {
+		value interface{}
+		err   string
+	}{
+		{3, "glplus: SetUniforms of int, not a struct"},
+		{testColor{}, "glplus: no active uniform color (field glplus.testColor.Color)"},
+		{&testRequired{}, "glplus: field glplus.testRequired.F: unknown option \"required\""},
+		{struct {
+			I float32 `glsl:"i"`
+		}{1.5}, "glplus: uniform i has type int, not float"},
+		{struct {
+			W [2]float32 `glsl:"weights[2]"`
+		}{}, "glplus: uniform weights[2] has type float, 2 values do not fit"},
+		{struct {
+			F string `glsl:"f"`
+		}{}, "glplus: uniform f: unsupported value string"},
+	} {
+		if err := prog.SetUniforms(test.value); err == nil || err.Error() != test.err {
+			t.Errorf("%T: got %v, want %s", test.value, err, test.err)
+		}
+	}
+}
@@ -3,6 +3,9 @@ package glplus
 import "sync/atomic"
 
 // Gl may become engo.Gl (Gl = glplus.NewContext())
+// It is the default context of the package functions, LoadShaderProgram for
+// example; the methods of the same name, ctx.LoadShaderProgram, create the
+// resources on another context. A resource keeps using its context.
 var Gl *Context
 
 // ReferenceCountable ...
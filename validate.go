@@ -0,0 +1,567 @@
+//+build !netgo,!android
+
+package glplus
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// ValidationError is an API misuse found by a Validator.
+type ValidationError struct {
+	Method string
+	Msg    string
+	// Stack is the stack of the caller, starting at the function calling
+	// the Context.
+	Stack []runtime.Frame
+}
+
+func (e *ValidationError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "glplus: %s: %s", e.Method, e.Msg)
+	for _, f := range e.Stack {
+		fmt.Fprintf(&b, "\n\t%s\n\t\t%s:%d", f.Function, f.File, f.Line)
+	}
+	return b.String()
+}
+
+// Validator is a NativeBackend checking calls for semantic errors before
+// they reach the wrapped backend. Use it with NewBackendContext:
+//
+//	v := NewValidator(NewDesktopBackend())
+//	Gl = NewBackendContext(v)
+//
+// The calls are still forwarded, the violations are collected in Errors.
+type Validator struct {
+	NativeBackend
+
+	// OnError is called for every violation when set, to panic or log.
+	OnError func(err *ValidationError)
+
+	errs    []error
+	deleted map[interface{}]bool
+
+	program     *Program
+	arrayBuffer *Buffer
+	// requireVAO is set for the core profiles, which draw nothing without a
+	// vertex array; the others have a default one
+	requireVAO  bool
+	vao         *VertexArray
+	vaos        map[*VertexArray]*validatorVAO
+	buffers     map[*Buffer]*validatorBuffer
+}
+
+type validatorAttrib struct {
+	enabled bool
+	divisor int
+	set     bool
+	buffer  *Buffer
+	size    int
+	typ     int
+	stride  int
+	offset  int
+}
+
+type validatorVAO struct {
+	elementBuffer *Buffer
+	attribs       map[int]*validatorAttrib
+}
+
+type validatorBuffer struct {
+	size     int
+	maxIndex int
+}
+
+var _ NativeBackend = (*Validator)(nil)
+
+// NewValidator wraps backend.
+func NewValidator(backend NativeBackend) *Validator {
+	v := &Validator{
+		NativeBackend: backend,
+		deleted:       make(map[interface{}]bool),
+		vaos:          make(map[*VertexArray]*validatorVAO),
+		buffers:       make(map[*Buffer]*validatorBuffer),
+	}
+	caps := backend.QueryCaps()
+	v.requireVAO = caps.VertexArrays && !caps.ES
+	return v
+}
+
+// Errors returns the violations found since the last Reset.
+func (v *Validator) Errors() []error {
+	return v.errs
+}
+
+// Reset forgets the violations found so far.
+func (v *Validator) Reset() {
+	v.errs = nil
+}
+
+func (v *Validator) fail(method string, format string, args ...interface{}) {
+	err := &ValidationError{
+		Method: method,
+		Msg:    fmt.Sprintf(format, args...),
+		Stack:  callerStack(),
+	}
+	v.errs = append(v.errs, err)
+	if v.OnError != nil {
+		v.OnError(err)
+	}
+}
+
+// alive reports an error for each deleted object in objs.
+func (v *Validator) alive(method string, objs ...interface{}) bool {
+	ok := true
+	for _, obj := range objs {
+		if v.deleted[obj] {
+			v.fail(method, "%s used after it was deleted", reflect.TypeOf(obj).Elem().Name())
+			ok = false
+		}
+	}
+	return ok
+}
+
+func (v *Validator) delete(method string, obj interface{}) {
+	if reflect.ValueOf(obj).IsNil() {
+		return
+	}
+	if v.deleted[obj] {
+		v.fail(method, "%s deleted twice", reflect.TypeOf(obj).Elem().Name())
+		return
+	}
+	v.deleted[obj] = true
+}
+
+func (v *Validator) currentVAO() *validatorVAO {
+	state := v.vaos[v.vao]
+	if state == nil {
+		state = &validatorVAO{attribs: make(map[int]*validatorAttrib)}
+		v.vaos[v.vao] = state
+	}
+	return state
+}
+
+func (v *Validator) attrib(index int) *validatorAttrib {
+	vao := v.currentVAO()
+	a := vao.attribs[index]
+	if a == nil {
+		a = &validatorAttrib{}
+		vao.attribs[index] = a
+	}
+	return a
+}
+
+func (v *Validator) needProgram(method string) {
+	if v.program == nil {
+		v.fail(method, "no program in use")
+	}
+}
+
+func typeSize(typ int) int {
+	switch typ {
+	case sEnums.BYTE, sEnums.UNSIGNED_BYTE:
+		return 1
+	case sEnums.SHORT, sEnums.UNSIGNED_SHORT, sEnums.HALF_FLOAT:
+		return 2
+	}
+	return 4
+}
+
+// maxIndex returns the largest index of an element array, -1 for other data.
+func maxIndex(data interface{}) int {
+	max := -1
+	switch t := data.(type) {
+	case []uint8:
+		for _, i := range t {
+			if int(i) > max {
+				max = int(i)
+			}
+		}
+	case []uint16:
+		for _, i := range t {
+			if int(i) > max {
+				max = int(i)
+			}
+		}
+	case []uint32:
+		for _, i := range t {
+			if int(i) > max {
+				max = int(i)
+			}
+		}
+	}
+	return max
+}
+
+// DeleteProgram ...
+func (v *Validator) DeleteProgram(program *Program) {
+	v.delete("DeleteProgram", program)
+	v.NativeBackend.DeleteProgram(program)
+}
+
+// AttachShader ...
+func (v *Validator) AttachShader(program *Program, shader *Shader) {
+	v.alive("AttachShader", program, shader)
+	v.NativeBackend.AttachShader(program, shader)
+}
+
+// BindAttribLocation ...
+func (v *Validator) BindAttribLocation(program *Program, index int, name string) {
+	v.alive("BindAttribLocation", program)
+	v.NativeBackend.BindAttribLocation(program, index, name)
+}
+
+// LinkProgram ...
+func (v *Validator) LinkProgram(program *Program) {
+	v.alive("LinkProgram", program)
+	v.NativeBackend.LinkProgram(program)
+}
+
+// ValidateProgram ...
+func (v *Validator) ValidateProgram(program *Program) {
+	v.alive("ValidateProgram", program)
+	v.NativeBackend.ValidateProgram(program)
+}
+
+// UseProgram ...
+func (v *Validator) UseProgram(program *Program) {
+	if v.alive("UseProgram", program) {
+		v.program = program
+	}
+	v.NativeBackend.UseProgram(program)
+}
+
+// GetAttribLocation ...
+func (v *Validator) GetAttribLocation(program *Program, name string) int {
+	v.alive("GetAttribLocation", program)
+	return v.NativeBackend.GetAttribLocation(program, name)
+}
+
+// GetUniformLocation ...
+func (v *Validator) GetUniformLocation(program *Program, name string) *UniformLocation {
+	v.alive("GetUniformLocation", program)
+	return v.NativeBackend.GetUniformLocation(program, name)
+}
+
+// DeleteShader ...
+func (v *Validator) DeleteShader(shader *Shader) {
+	v.delete("DeleteShader", shader)
+	v.NativeBackend.DeleteShader(shader)
+}
+
+// ShaderSource ...
+func (v *Validator) ShaderSource(shader *Shader, source string) {
+	v.alive("ShaderSource", shader)
+	v.NativeBackend.ShaderSource(shader, source)
+}
+
+// CompileShader ...
+func (v *Validator) CompileShader(shader *Shader) {
+	v.alive("CompileShader", shader)
+	v.NativeBackend.CompileShader(shader)
+}
+
+// Uniform1f ...
+func (v *Validator) Uniform1f(location *UniformLocation, x float32) {
+	v.needProgram("Uniform1f")
+	v.NativeBackend.Uniform1f(location, x)
+}
+
+// Uniform1i ...
+func (v *Validator) Uniform1i(location *UniformLocation, x int) {
+	v.needProgram("Uniform1i")
+	v.NativeBackend.Uniform1i(location, x)
+}
+
+// Uniform2f ...
+func (v *Validator) Uniform2f(location *UniformLocation, x, y float32) {
+	v.needProgram("Uniform2f")
+	v.NativeBackend.Uniform2f(location, x, y)
+}
+
+// Uniform3f ...
+func (v *Validator) Uniform3f(location *UniformLocation, x, y, z float32) {
+	v.needProgram("Uniform3f")
+	v.NativeBackend.Uniform3f(location, x, y, z)
+}
+
+// Uniform4f ...
+func (v *Validator) Uniform4f(location *UniformLocation, x, y, z, w float32) {
+	v.needProgram("Uniform4f")
+	v.NativeBackend.Uniform4f(location, x, y, z, w)
+}
+
+// UniformMatrix3fv ...
+func (v *Validator) UniformMatrix3fv(location *UniformLocation, transpose bool, value []float32) {
+	v.needProgram("UniformMatrix3fv")
+	v.NativeBackend.UniformMatrix3fv(location, transpose, value)
+}
+
+// UniformMatrix4fv ...
+func (v *Validator) UniformMatrix4fv(location *UniformLocation, transpose bool, value []float32) {
+	v.needProgram("UniformMatrix4fv")
+	v.NativeBackend.UniformMatrix4fv(location, transpose, value)
+}
+
+// DeleteBuffer ...
+func (v *Validator) DeleteBuffer(buffer *Buffer) {
+	v.delete("DeleteBuffer", buffer)
+	if v.arrayBuffer == buffer {
+		v.arrayBuffer = nil
+	}
+	if vao := v.currentVAO(); vao.elementBuffer == buffer {
+		vao.elementBuffer = nil
+	}
+	v.NativeBackend.DeleteBuffer(buffer)
+}
+
+// BindBuffer ...
+func (v *Validator) BindBuffer(target int, buffer *Buffer) {
+	if v.alive("BindBuffer", buffer) {
+		switch target {
+		case sEnums.ARRAY_BUFFER:
+			v.arrayBuffer = buffer
+		case sEnums.ELEMENT_ARRAY_BUFFER:
+			v.currentVAO().elementBuffer = buffer
+		}
+	}
+	v.NativeBackend.BindBuffer(target, buffer)
+}
+
+func (v *Validator) boundBuffer(target int) *Buffer {
+	switch target {
+	case sEnums.ARRAY_BUFFER:
+		return v.arrayBuffer
+	case sEnums.ELEMENT_ARRAY_BUFFER:
+		return v.currentVAO().elementBuffer
+	}
+	return nil
+}
+
+// BufferData ...
+func (v *Validator) BufferData(target int, data interface{}, usage int) {
+	buffer := v.boundBuffer(target)
+	if buffer == nil {
+		v.fail("BufferData", "no buffer bound to the target")
+	} else {
+		v.buffers[buffer] = &validatorBuffer{size: dataSize(data), maxIndex: maxIndex(data)}
+	}
+	v.NativeBackend.BufferData(target, data, usage)
+}
+
+// BufferSubData ...
+func (v *Validator) BufferSubData(target int, offset int, data interface{}) {
+	buffer := v.boundBuffer(target)
+	switch {
+	case target != sEnums.ARRAY_BUFFER && target != sEnums.ELEMENT_ARRAY_BUFFER:
+		// the other targets are not tracked
+	case buffer == nil:
+		v.fail("BufferSubData", "no buffer bound to the target")
+	case v.buffers[buffer] == nil:
+		v.fail("BufferSubData", "buffer without data")
+	default:
+		b := v.buffers[buffer]
+		if end := offset + dataSize(data); offset < 0 || end > b.size {
+			v.fail("BufferSubData", "bytes %d to %d are out of the %d of the buffer", offset, end, b.size)
+		}
+		// the indices replaced may have been the largest
+		if max := maxIndex(data); max > b.maxIndex {
+			b.maxIndex = max
+		}
+	}
+	v.NativeBackend.BufferSubData(target, offset, data)
+}
+
+// DeleteVertexArray ...
+func (v *Validator) DeleteVertexArray(vao *VertexArray) {
+	v.delete("DeleteVertexArray", vao)
+	if v.vao == vao {
+		v.vao = nil
+	}
+	v.NativeBackend.DeleteVertexArray(vao)
+}
+
+// BindVertexArray ...
+func (v *Validator) BindVertexArray(vao *VertexArray) {
+	if v.alive("BindVertexArray", vao) {
+		v.vao = vao
+	}
+	v.NativeBackend.BindVertexArray(vao)
+}
+
+// EnableVertexAttribArray ...
+func (v *Validator) EnableVertexAttribArray(index int) {
+	if index < 0 {
+		v.fail("EnableVertexAttribArray", "invalid attribute %d", index)
+	} else {
+		v.attrib(index).enabled = true
+	}
+	v.NativeBackend.EnableVertexAttribArray(index)
+}
+
+// VertexAttribDivisor ...
+func (v *Validator) VertexAttribDivisor(index, divisor int) {
+	if index < 0 || divisor < 0 {
+		v.fail("VertexAttribDivisor", "invalid attribute %d or divisor %d", index, divisor)
+	} else {
+		v.attrib(index).divisor = divisor
+	}
+	v.NativeBackend.VertexAttribDivisor(index, divisor)
+}
+
+// DisableVertexAttribArray ...
+func (v *Validator) DisableVertexAttribArray(index int) {
+	if index >= 0 {
+		v.attrib(index).enabled = false
+	}
+	v.NativeBackend.DisableVertexAttribArray(index)
+}
+
+// VertexAttribPointer ...
+func (v *Validator) VertexAttribPointer(index, size, typ int, normal bool, stride int, offset int) {
+	switch {
+	case index < 0:
+		v.fail("VertexAttribPointer", "invalid attribute %d", index)
+	case v.arrayBuffer == nil:
+		v.fail("VertexAttribPointer", "no array buffer bound")
+	default:
+		step := stride
+		if step == 0 {
+			step = size * typeSize(typ)
+		}
+		if b := v.buffers[v.arrayBuffer]; b != nil && offset+size*typeSize(typ) > b.size {
+			v.fail("VertexAttribPointer", "attribute %d reads %d bytes at offset %d of a %d bytes buffer", index, size*typeSize(typ), offset, b.size)
+		}
+		a := v.attrib(index)
+		a.set, a.buffer, a.size, a.typ, a.stride, a.offset = true, v.arrayBuffer, size, typ, step, offset
+	}
+	v.NativeBackend.VertexAttribPointer(index, size, typ, normal, stride, offset)
+}
+
+// DeleteTexture ...
+func (v *Validator) DeleteTexture(texture *Texture) {
+	v.delete("DeleteTexture", texture)
+	v.NativeBackend.DeleteTexture(texture)
+}
+
+// BindTexture ...
+func (v *Validator) BindTexture(target int, texture *Texture) {
+	v.alive("BindTexture", texture)
+	v.NativeBackend.BindTexture(target, texture)
+}
+
+// FramebufferTexture2D ...
+func (v *Validator) FramebufferTexture2D(target, attachment, textarget int, texture *Texture, level int) {
+	v.alive("FramebufferTexture2D", texture)
+	v.NativeBackend.FramebufferTexture2D(target, attachment, textarget, texture, level)
+}
+
+// DeleteFramebuffer ...
+func (v *Validator) DeleteFramebuffer(framebuffer *FrameBuffer) {
+	v.delete("DeleteFramebuffer", framebuffer)
+	v.NativeBackend.DeleteFramebuffer(framebuffer)
+}
+
+// BindFramebuffer ...
+func (v *Validator) BindFramebuffer(target int, framebuffer *FrameBuffer) {
+	v.alive("BindFramebuffer", framebuffer)
+	v.NativeBackend.BindFramebuffer(target, framebuffer)
+}
+
+// DeleteRenderbuffer ...
+func (v *Validator) DeleteRenderbuffer(renderbuffer *RenderBuffer) {
+	v.delete("DeleteRenderbuffer", renderbuffer)
+	v.NativeBackend.DeleteRenderbuffer(renderbuffer)
+}
+
+// BindRenderbuffer ...
+func (v *Validator) BindRenderbuffer(target int, renderbuffer *RenderBuffer) {
+	v.alive("BindRenderbuffer", renderbuffer)
+	v.NativeBackend.BindRenderbuffer(target, renderbuffer)
+}
+
+// FramebufferRenderbuffer ...
+func (v *Validator) FramebufferRenderbuffer(target, attachment, renderbuffertarget int, renderbuffer *RenderBuffer) {
+	v.alive("FramebufferRenderbuffer", renderbuffer)
+	v.NativeBackend.FramebufferRenderbuffer(target, attachment, renderbuffertarget, renderbuffer)
+}
+
+// checkDraw checks the vertices first to first+count-1 can be fetched.
+func (v *Validator) checkDraw(method string, first, count, instances int) {
+	v.needProgram(method)
+	if v.vao == nil && v.requireVAO {
+		v.fail(method, "no vertex array bound")
+		return
+	}
+	vao := v.currentVAO()
+	for index, a := range vao.attribs {
+		if !a.enabled {
+			continue
+		}
+		if !a.set {
+			v.fail(method, "attribute %d is enabled but VertexAttribPointer was never called", index)
+			continue
+		}
+		if !v.alive(method, a.buffer) || count == 0 || instances == 0 {
+			continue
+		}
+		b := v.buffers[a.buffer]
+		if b == nil {
+			v.fail(method, "attribute %d reads a buffer without data", index)
+			continue
+		}
+		// the attributes of the instances read one element per divisor
+		// instances
+		last := first + count - 1
+		if a.divisor != 0 {
+			last = (instances - 1) / a.divisor
+		}
+		if need := a.offset + last*a.stride + a.size*typeSize(a.typ); need > b.size {
+			v.fail(method, "attribute %d needs %d bytes, its buffer holds %d", index, need, b.size)
+		}
+	}
+}
+
+// DrawArrays ...
+func (v *Validator) DrawArrays(mode, first, count int) {
+	v.checkDraw("DrawArrays", first, count, 1)
+	v.NativeBackend.DrawArrays(mode, first, count)
+}
+
+// DrawArraysInstanced ...
+func (v *Validator) DrawArraysInstanced(mode, first, count, instances int) {
+	v.checkDraw("DrawArraysInstanced", first, count, instances)
+	v.NativeBackend.DrawArraysInstanced(mode, first, count, instances)
+}
+
+// DrawElements ...
+func (v *Validator) DrawElements(mode, count, typ, offset int) {
+	v.checkElements("DrawElements", count, typ, offset, 1)
+	v.NativeBackend.DrawElements(mode, count, typ, offset)
+}
+
+// DrawElementsInstanced ...
+func (v *Validator) DrawElementsInstanced(mode, count, typ, offset, instances int) {
+	v.checkElements("DrawElementsInstanced", count, typ, offset, instances)
+	v.NativeBackend.DrawElementsInstanced(mode, count, typ, offset, instances)
+}
+
+func (v *Validator) checkElements(method string, count, typ, offset, instances int) {
+	elements := v.currentVAO().elementBuffer
+	switch {
+	case elements == nil:
+		v.needProgram(method)
+		v.fail(method, "no element array buffer bound")
+	case !v.alive(method, elements):
+	case v.buffers[elements] == nil:
+		v.fail(method, "element array buffer without data")
+	default:
+		b := v.buffers[elements]
+		if need := offset + count*typeSize(typ); need > b.size {
+			v.fail(method, "%d indices need %d bytes, the element array buffer holds %d", count, need, b.size)
+		}
+		v.checkDraw(method, 0, b.maxIndex+1, instances)
+	}
+}
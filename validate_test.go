@@ -0,0 +1,184 @@
+package glplus
+
+import (
+	"image"
+	"strings"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func validatorContext(t *testing.T) (*Validator, func()) {
+	v := NewValidator(NewSoftBackend(16, 16))
+	saved := Gl
+	Gl = NewBackendContext(v)
+	return v, func() { Gl = saved }
+}
+
+func expectViolation(t *testing.T, v *Validator, method, msg, caller string) {
+	t.Helper()
+	errs := v.Errors()
+	v.Reset()
+	if len(errs) != 1 {
+		t.Fatalf("got %d violations, want 1: %v", len(errs), errs)
+	}
+	err := errs[0].(*ValidationError)
+	if err.Method != method || !strings.Contains(err.Msg, msg) {
+		t.Errorf("got %s: %s, want %s: %s", err.Method, err.Msg, method, msg)
+	}
+	if len(err.Stack) == 0 || !strings.HasSuffix(err.Stack[0].Function, caller) {
+		t.Errorf("stack does not start at %s:\n%v", caller, err)
+	}
+}
+
+func TestValidatorDraw(t *testing.T) {
+	v, restore := validatorContext(t)
+	defer restore()
+
+	prog, err := LoadShaderProgram(sVertShaderCoordMarker+"\n// validated", sFragShaderCoordMarker, []string{"position", "normal"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	vbo := NewVBOCubeNormal(prog, 0, 0, 0, 1, 1, 1)
+
+	prog.UseProgram()
+	prog.ProgramUniformMatrix4fv("model", mgl32.Ident4())
+	vbo.Bind(prog)
+	vbo.Draw()
+	vbo.Unbind(prog)
+	prog.UnuseProgram()
+	if errs := v.Errors(); len(errs) != 0 {
+		t.Fatalf("valid draw: %v", errs)
+	}
+
+	prog.ProgramUniformMatrix4fv("model", mgl32.Ident4())
+	expectViolation(t, v, "UniformMatrix4fv", "no program in use", "(*GPProgram).ProgramUniformMatrix4fv")
+
+	// an enabled attribute without pointer
+	prog.UseProgram()
+	vbo.Bind(prog)
+	Gl.EnableVertexAttribArray(7)
+	vbo.Draw()
+	expectViolation(t, v, "DrawElements", "attribute 7 is enabled", "(*VBO).Draw")
+	Gl.DisableVertexAttribArray(7)
+	vbo.Unbind(prog)
+
+	vbo.DeleteVBO()
+	vbo.Bind(prog)
+	if errs := v.Errors(); len(errs) != 2 {
+		t.Fatalf("bind after delete: %v", errs)
+	}
+	v.Reset()
+	Gl.BindVertexArray(nil)
+	prog.UnuseProgram()
+}
+
+// esBackend is the software backend as OpenGL ES 2 without
+// OES_vertex_array_object, which draws from the default vertex array.
+type esBackend struct {
+	NativeBackend
+}
+
+func (b *esBackend) QueryCaps() Caps {
+	caps := b.NativeBackend.QueryCaps()
+	caps.ES, caps.VertexArrays = true, false
+	return caps
+}
+
+func TestValidatorDefaultVertexArray(t *testing.T) {
+	v := NewValidator(&esBackend{NewSoftBackend(16, 16)})
+	saved := Gl
+	Gl = NewBackendContext(v)
+	defer func() { Gl = saved }()
+
+	prog, err := LoadShaderProgram(sVertShaderCoordMarker+"\n// default vertex array", sFragShaderCoordMarker, []string{"position", "normal"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer prog.DeleteProgram()
+	vbo := NewVBOCubeNormal(prog, 0, 0, 0, 1, 1, 1)
+	defer vbo.DeleteVBO()
+
+	prog.UseProgram()
+	vbo.Bind(prog)
+	vbo.Draw()
+	if errs := v.Errors(); len(errs) != 0 {
+		t.Fatalf("valid draw: %v", errs)
+	}
+	// the attributes of the default vertex array are checked as well
+	Gl.EnableVertexAttribArray(7)
+	vbo.Draw()
+	expectViolation(t, v, "DrawElements", "attribute 7 is enabled", "(*VBO).Draw")
+	Gl.DisableVertexAttribArray(7)
+	vbo.Unbind(prog)
+	prog.UnuseProgram()
+}
+
+func TestValidatorBufferSize(t *testing.T) {
+	v, restore := validatorContext(t)
+	defer restore()
+
+	prog, err := LoadShaderProgram(sVertShaderCoordMarker+"\n// validated size", sFragShaderCoordMarker, []string{"position", "normal"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	vao := Gl.CreateVertexArray()
+	buf := Gl.CreateBuffer()
+	Gl.BindVertexArray(vao)
+	Gl.BindBuffer(Gl.ARRAY_BUFFER, buf)
+	// 3 vertices of position and normal need 72 bytes
+	Gl.BufferData(Gl.ARRAY_BUFFER, make([]float32, 15), Gl.STATIC_DRAW)
+	Gl.VertexAttribPointer(0, 3, Gl.FLOAT, false, 24, 0)
+	Gl.VertexAttribPointer(1, 3, Gl.FLOAT, false, 24, 12)
+	Gl.EnableVertexAttribArray(0)
+	Gl.EnableVertexAttribArray(1)
+	prog.UseProgram()
+	Gl.DrawArrays(Gl.TRIANGLES, 0, 3)
+	if errs := v.Errors(); len(errs) != 1 || !strings.Contains(errs[0].Error(), "attribute 1 needs 72 bytes, its buffer holds 60") {
+		t.Errorf("got %v", errs)
+	}
+	v.Reset()
+
+	// a store of 72 bytes, without data
+	Gl.BufferData(Gl.ARRAY_BUFFER, 72, Gl.STREAM_DRAW)
+	Gl.BufferSubData(Gl.ARRAY_BUFFER, 24, make([]float32, 12))
+	Gl.DrawArrays(Gl.TRIANGLES, 0, 3)
+	if errs := v.Errors(); len(errs) != 0 {
+		t.Errorf("got %v", errs)
+	}
+	Gl.BufferSubData(Gl.ARRAY_BUFFER, 48, make([]float32, 12))
+	expectViolation(t, v, "BufferSubData", "bytes 48 to 96 are out of the 72 of the buffer", "TestValidatorBufferSize")
+
+	// the normals of 3 instances
+	Gl.VertexAttribDivisor(1, 1)
+	Gl.DrawArraysInstanced(Gl.TRIANGLES, 0, 3, 3)
+	if errs := v.Errors(); len(errs) != 0 {
+		t.Errorf("got %v", errs)
+	}
+	Gl.DrawArraysInstanced(Gl.TRIANGLES, 0, 3, 4)
+	expectViolation(t, v, "DrawArraysInstanced", "attribute 1 needs 96 bytes, its buffer holds 72", "TestValidatorBufferSize")
+	Gl.VertexAttribDivisor(1, 0)
+
+	Gl.DeleteBuffer(buf)
+	Gl.DeleteVertexArray(vao)
+	Gl.DeleteBuffer(buf)
+	expectViolation(t, v, "DeleteBuffer", "Buffer deleted twice", "TestValidatorBufferSize")
+	prog.UnuseProgram()
+}
+
+func TestValidatorDeleted(t *testing.T) {
+	v, restore := validatorContext(t)
+	defer restore()
+
+	tex := GenTexture(image.Point{4, 4})
+	tex.DeleteTexture()
+	tex.BindTexture(0)
+	expectViolation(t, v, "BindTexture", "Texture used after it was deleted", "(*GPTexture).BindTexture")
+	tex.UnbindTexture(0)
+
+	fb := Gl.CreateFramebuffer()
+	Gl.DeleteFramebuffer(fb)
+	Gl.BindFramebuffer(Gl.FRAMEBUFFER, fb)
+	expectViolation(t, v, "BindFramebuffer", "FrameBuffer used after it was deleted", "TestValidatorDeleted")
+	Gl.BindFramebuffer(Gl.FRAMEBUFFER, nil)
+}
@@ -1,7 +1,9 @@
 package glplus
 
 import (
+	"fmt"
 	"math"
+	"reflect"
 )
 
 // VBOOptions ...
@@ -10,7 +12,19 @@ type VBOOptions struct {
 	Normals int
 	UV      int
 	IsStrip bool
-	Quads   int
+	// Quads other than 0 draws triangles, as many quads of 6 indices as the
+	// data uploaded has.
+	Quads int
+	// Layout describes the vertices in place of Vertex, Normals and UV.
+	Layout *VertexLayout
+	// Usage is the usage of the buffers, Gl.STATIC_DRAW when 0. A VBO
+	// updated every frame is Gl.DYNAMIC_DRAW or Gl.STREAM_DRAW.
+	Usage int
+	// Frames > 1 makes the buffers a ring of as many regions: every Update
+	// writes the next region while the draws of the previous frames may
+	// still read theirs, and the buffers are orphaned when the ring wraps
+	// around.
+	Frames int
 }
 
 // DefaultVBOOptions ...
@@ -24,179 +38,414 @@ func DefaultVBOOptions() VBOOptions {
 // VBO ...
 type VBO struct {
 	vao        *VertexArray
-	vboVerts   *Buffer
+	vboStreams []*Buffer
 	vboIndices *Buffer
 	numElem    int
 	isShort    bool
+	// the quads drawn, those of the data last uploaded
+	quads int
+
+	// the vertices and indices a region of the buffers holds, and the
+	// region of the ring drawn
+	capacity      int
+	indexCapacity int
+	region        int
 
 	options VBOOptions
 	attribs map[string]int
+
+	ctx *Context
+	// copies of the data last uploaded, kept to restore the buffers
+	prog    *GPProgram
+	verts   []float32
+	indices []uint32
 }
 
 // DeleteVBO ...
 func (v *VBO) DeleteVBO() {
-	if v.vboVerts != nil {
-		Gl.DeleteBuffer(v.vboVerts)
+	for _, buffer := range v.vboStreams {
+		v.ctx.DeleteBuffer(buffer)
 	}
 	if v.vboIndices != nil {
-		Gl.DeleteBuffer(v.vboIndices)
+		v.ctx.DeleteBuffer(v.vboIndices)
 	}
 	if v.vao != nil {
-		Gl.DeleteVertexArray(v.vao)
+		v.ctx.DeleteVertexArray(v.vao)
 	}
+	v.ctx.Unregister(v)
 }
 
 // Bind ...
 func (v *VBO) Bind(prog *GPProgram) {
-	Gl.BindVertexArray(v.vao)
-	attribs := prog.GetAttribs()
-
-	if v.options.Vertex != 0 {
-		Gl.EnableVertexAttribArray(attribs["position"])
-	}
-	if v.options.UV != 0 {
-		Gl.EnableVertexAttribArray(attribs["uvs"])
+	v.bindVertexArray(v.vao)
+	layout := v.options.layout()
+	locs := v.locations(prog)
+	if v.vao == nil {
+		// without a VAO the pointers are context state
+		for stream, buffer := range v.vboStreams {
+			v.ctx.BindBuffer(v.ctx.ARRAY_BUFFER, buffer)
+			v.point(stream, locs)
+		}
 	}
-	if v.options.Normals != 0 {
-		Gl.EnableVertexAttribArray(attribs["normal"])
+	for i, loc := range locs {
+		n, _ := layout.Attribs[i].columns()
+		for col := 0; loc >= 0 && col < n; col++ {
+			v.ctx.EnableVertexAttribArray(loc + col)
+		}
 	}
 	if v.vboIndices != nil {
-		Gl.BindBuffer(Gl.ELEMENT_ARRAY_BUFFER, v.vboIndices)
-	} else {
-		Gl.BindBuffer(Gl.ARRAY_BUFFER, v.vboVerts)
+		v.ctx.BindBuffer(v.ctx.ELEMENT_ARRAY_BUFFER, v.vboIndices)
+	} else if len(v.vboStreams) > 0 {
+		v.ctx.BindBuffer(v.ctx.ARRAY_BUFFER, v.vboStreams[0])
 	}
 }
 
 // Unbind ...
 func (v *VBO) Unbind(prog *GPProgram) {
-	attribs := prog.GetAttribs()
-
 	if v.vboIndices != nil {
-		Gl.BindBuffer(Gl.ELEMENT_ARRAY_BUFFER, nil)
+		v.ctx.BindBuffer(v.ctx.ELEMENT_ARRAY_BUFFER, nil)
 	} else {
-		Gl.BindBuffer(Gl.ARRAY_BUFFER, nil)
+		v.ctx.BindBuffer(v.ctx.ARRAY_BUFFER, nil)
 	}
-	if v.options.Vertex != 0 {
-		Gl.DisableVertexAttribArray(attribs["position"])
+	layout := v.options.layout()
+	for i, loc := range v.locations(prog) {
+		n, _ := layout.Attribs[i].columns()
+		for col := 0; loc >= 0 && col < n; col++ {
+			v.ctx.DisableVertexAttribArray(loc + col)
+		}
 	}
-	if v.options.UV != 0 {
-		Gl.DisableVertexAttribArray(attribs["uvs"])
+	v.bindVertexArray(nil)
+}
+
+// bindVertexArray binds vao, or nil, when the VBO has one: WebGL 1 and
+// OpenGL ES 2 may lack them, see Caps.VertexArrays.
+func (v *VBO) bindVertexArray(vao *VertexArray) {
+	if v.vao != nil {
+		v.ctx.BindVertexArray(vao)
 	}
-	if v.options.Normals != 0 {
-		Gl.DisableVertexAttribArray(attribs["normal"])
+}
+
+// point points the attributes of the stream at locs at its bound buffer,
+// in the current region.
+func (v *VBO) point(stream int, locs []int) {
+	layout := v.options.layout()
+	offsets, strides := layout.Offsets()
+	start := v.region * v.capacity * strides[stream]
+	for i, a := range layout.Attribs {
+		if a.Stream == stream && locs[i] >= 0 {
+			a.pointer(v.ctx, locs[i], strides[stream], start+offsets[i])
+		}
+	}
+}
+
+// locations are the locations of the attributes of the layout in prog, -1
+// for the ones it does not have.
+func (v *VBO) locations(prog *GPProgram) []int {
+	layout := v.options.layout()
+	locs := make([]int, len(layout.Attribs))
+	for i, a := range layout.Attribs {
+		locs[i] = prog.GetAttribLocation(a.Name)
 	}
-	Gl.BindVertexArray(nil)
+	return locs
 }
 
 func (v *VBO) elemType() int {
 	if v.isShort {
-		return Gl.UNSIGNED_SHORT
+		return v.ctx.UNSIGNED_SHORT
 	}
 
-	return Gl.UNSIGNED_INT
+	return v.ctx.UNSIGNED_INT
+}
+
+// indexOffset is the offset of the indices of the region drawn.
+func (v *VBO) indexOffset() int {
+	if v.isShort {
+		return v.region * v.indexCapacity * 2
+	}
+	return v.region * v.indexCapacity * 4
+}
+
+func (v *VBO) usage() int {
+	if v.options.Usage != 0 {
+		return v.options.Usage
+	}
+	return v.ctx.STATIC_DRAW
+}
+
+func (v *VBO) frames() int {
+	if v.options.Frames > 1 {
+		return v.options.Frames
+	}
+	return 1
+}
+
+// primitives are the mode and the number of vertices or indices drawn.
+func (v *VBO) primitives() (mode, count int) {
+	if v.options.Quads != 0 {
+		return v.ctx.TRIANGLES, v.quads * 6
+	} else if v.options.IsStrip {
+		return v.ctx.TRIANGLE_STRIP, v.numElem
+	}
+	return v.ctx.TRIANGLES, v.numElem
 }
 
 // Draw ...
 func (v *VBO) Draw() {
+	mode, count := v.primitives()
 	if v.vboIndices != nil {
-		if v.options.Quads != 0 {
-			Gl.DrawElements(Gl.TRIANGLES, v.options.Quads*6, v.elemType(), 0)
-		} else if v.options.IsStrip {
-			Gl.DrawElements(Gl.TRIANGLE_STRIP, v.numElem, v.elemType(), 0)
-		} else {
-			Gl.DrawElements(Gl.TRIANGLES, v.numElem, v.elemType(), 0)
-		}
+		v.ctx.DrawElements(mode, count, v.elemType(), v.indexOffset())
+	} else {
+		v.ctx.DrawArrays(mode, 0, count)
+	}
+}
+
+// DrawInstanced draws the VBO instances times, with the attributes of the
+// InstanceBuffer bound after it. It needs Caps.Instancing.
+func (v *VBO) DrawInstanced(instances int) {
+	mode, count := v.primitives()
+	if v.vboIndices != nil {
+		v.ctx.DrawElementsInstanced(mode, count, v.elemType(), v.indexOffset(), instances)
 	} else {
-		if v.options.Quads != 0 {
-			Gl.DrawArrays(Gl.TRIANGLES, 0, v.options.Quads*6)
-		} else if v.options.IsStrip {
-			Gl.DrawArrays(Gl.TRIANGLE_STRIP, 0, v.numElem)
-		} else {
-			Gl.DrawArrays(Gl.TRIANGLES, 0, v.numElem)
+		v.ctx.DrawArraysInstanced(mode, 0, count, instances)
+	}
+}
+
+// load allocates the buffers for the vertices and indices of the VBO.
+func (v *VBO) load() {
+	// no store yet
+	v.capacity, v.indexCapacity, v.region = -1, -1, 0
+	v.upload(v.verts, v.indices, v.isShort)
+}
+
+// checkIndices tells whether the indices fit 16 bits, and fails when they
+// need 32 bits the context does not have.
+func (v *VBO) checkIndices(indices []uint32) (isShort bool, err error) {
+	var maxIndex uint32
+	for _, ind := range indices {
+		if ind > maxIndex {
+			maxIndex = ind
 		}
 	}
+	if maxIndex >= math.MaxUint16 && !v.ctx.Caps.Uint32Indices {
+		return false, fmt.Errorf("glplus: index %d does not fit 16 bits and 32-bit indices are not supported (OES_element_index_uint)", maxIndex)
+	}
+	return maxIndex < math.MaxUint16, nil
 }
 
-// Load ...
-func (v *VBO) load(prog *GPProgram, verts []float32, indices []uint32) {
-	attribs := prog.GetAttribs()
+// upload writes the vertices and indices to the next region of the ring,
+// or over the previous ones without a ring. Buffers too small for them are
+// reallocated with room to grow. isShort is the type of the indices, see
+// checkIndices.
+func (v *VBO) upload(verts []float32, indices []uint32, isShort bool) {
+	layout := v.options.layout()
+	count := layout.count(verts)
+	isShort = v.vboIndices == nil || isShort
+	frames := v.frames()
+
+	grow := count > v.capacity || len(indices) > v.indexCapacity || (v.isShort && !isShort)
+	switch {
+	case grow && v.capacity < 0:
+		v.capacity, v.indexCapacity = count, len(indices)
+	case grow:
+		v.capacity, v.indexCapacity = grown(count, v.capacity), grown(len(indices), v.indexCapacity)
+	}
+	if grow {
+		v.region = 0
+	} else {
+		v.region = (v.region + 1) % frames
+	}
+	// a ring starting over takes a new store rather than waiting for the
+	// draws of the first region
+	orphan := grow || (frames > 1 && v.region == 0)
+	v.isShort = isShort
 
-	Gl.BindVertexArray(v.vao)
-	Gl.BindBuffer(Gl.ARRAY_BUFFER, v.vboVerts)
+	v.bindVertexArray(v.vao)
 	if v.vboIndices != nil {
-		Gl.BindBuffer(Gl.ELEMENT_ARRAY_BUFFER, v.vboIndices)
+		v.ctx.BindBuffer(v.ctx.ELEMENT_ARRAY_BUFFER, v.vboIndices)
 	}
 
-	// load our data up and bind it to the 'position' shader attribute
-	Gl.BufferData(Gl.ARRAY_BUFFER, verts, Gl.STATIC_DRAW)
+	// load the data of every stream and point the attributes of the VAO at
+	// it, Bind points them without one
+	locs := v.locations(v.prog)
+	_, strides := layout.Offsets()
+	for stream, data := range layout.pack(verts) {
+		start := v.region * v.capacity * strides[stream]
+		v.ctx.BindBuffer(v.ctx.ARRAY_BUFFER, v.vboStreams[stream])
+		v.bufferData(v.ctx.ARRAY_BUFFER, data, start, frames*v.capacity*strides[stream], orphan)
+		if v.vao != nil {
+			v.point(stream, locs)
+		}
+	}
 
 	if v.vboIndices != nil {
-		if len(indices) < math.MaxUint16 {
-			v.isShort = true
+		size := 4
+		var data interface{} = indices
+		if v.isShort {
+			size = 2
 			uindices := make([]uint16, len(indices))
 			for i, ind := range indices {
 				uindices[i] = uint16(ind)
 			}
-			Gl.BufferData(Gl.ELEMENT_ARRAY_BUFFER, uindices, Gl.STATIC_DRAW)
-		} else {
-			Gl.BufferData(Gl.ELEMENT_ARRAY_BUFFER, indices, Gl.STATIC_DRAW)
+			data = uindices
 		}
+		v.bufferData(v.ctx.ELEMENT_ARRAY_BUFFER, data, v.indexOffset(), frames*v.indexCapacity*size, orphan)
 	}
 
-	var numElemsPerVertex = v.options.Vertex + v.options.UV + v.options.Normals
-	var totalSize = numElemsPerVertex * 4
-	var offset int
-	if v.options.Vertex != 0 {
-		Gl.VertexAttribPointer(attribs["position"], v.options.Vertex, Gl.FLOAT, false, totalSize, offset)
-		offset += v.options.Vertex * 4
+	v.ctx.BindBuffer(v.ctx.ARRAY_BUFFER, nil)
+	if v.vboIndices != nil {
+		v.ctx.BindBuffer(v.ctx.ELEMENT_ARRAY_BUFFER, nil)
 	}
-	if v.options.UV != 0 {
-		Gl.VertexAttribPointer(attribs["uvs"], v.options.UV, Gl.FLOAT, false, totalSize, offset)
-		offset += v.options.UV * 4
+	v.bindVertexArray(nil)
+
+	if v.vboIndices != nil {
+		v.numElem = len(indices)
+	} else {
+		v.numElem = count
 	}
-	if v.options.Normals != 0 {
-		Gl.VertexAttribPointer(attribs["normal"], v.options.Normals, Gl.FLOAT, false, totalSize, offset)
+	if v.options.Quads != 0 {
+		v.quads = v.numElem / 6
 	}
+}
 
-	Gl.BindBuffer(Gl.ARRAY_BUFFER, nil)
-	if v.vboIndices != nil {
-		Gl.BindBuffer(Gl.ELEMENT_ARRAY_BUFFER, nil)
+// grown doubles a capacity, to need at least.
+func grown(need, capacity int) int {
+	if need > 2*capacity {
+		return need
 	}
-	Gl.BindVertexArray(nil)
+	return 2 * capacity
+}
 
-	if v.vboIndices != nil {
-		v.numElem = len(indices)
+// bufferData writes data at offset in the buffer bound to target, in a new
+// store of size bytes when orphan.
+func (v *VBO) bufferData(target int, data interface{}, offset, size int, orphan bool) {
+	n := dataSize(data)
+	if orphan && offset == 0 && n == size {
+		v.ctx.BufferData(target, data, v.usage())
+		return
+	}
+	if orphan {
+		v.ctx.BufferData(target, size, v.usage())
+	}
+	if n > 0 {
+		v.ctx.BufferSubData(target, offset, data)
+	}
+}
+
+// dataSize is the size in bytes of the data of BufferData or BufferSubData.
+func dataSize(data interface{}) int {
+	if size, ok := data.(int); ok {
+		return size
+	}
+	if rv := reflect.ValueOf(data); rv.Kind() == reflect.Slice {
+		return rv.Len() * int(rv.Type().Elem().Size())
+	}
+	return 0
+}
+
+// Update replaces the vertices, and the indices unless nil, and the counts
+// drawn. The buffers grow when they are too small; a VBO with Frames writes
+// the next region of its ring, without waiting for the draws of the
+// previous ones. Like NewVBO it keeps a copy of the data. The VBO is left as
+// it was when the indices need 32 bits the context does not have.
+func (v *VBO) Update(verts []float32, indices []uint32) error {
+	if indices == nil {
+		indices = v.indices
 	} else {
-		v.numElem = len(verts) / numElemsPerVertex
+		indices = append(make([]uint32, 0, len(indices)), indices...)
+	}
+	isShort, err := v.checkIndices(indices)
+	if err != nil {
+		return err
 	}
+	if indices != nil && v.vboIndices == nil {
+		v.vboIndices = v.ctx.CreateBuffer()
+		v.indexCapacity = -1
+	}
+	v.verts, v.indices = append(v.verts[:0], verts...), indices
+	v.upload(v.verts, indices, isShort)
+	return nil
 }
 
-// NewVBO ...
-func NewVBO(prog *GPProgram, options VBOOptions, verts []float32, indices []uint32) (vbo *VBO) {
-	// create and bind the required VAO object
-	var vao *VertexArray
-	vao = Gl.CreateVertexArray()
-	Gl.BindVertexArray(vao)
-
-	// create a VBO to hold the vertex data
-	var vboVerts *Buffer
-	var vboIndices *Buffer
-	vboVerts = Gl.CreateBuffer()
+// UpdateVertices replaces the vertices from the first one, in place with
+// BufferSubData. They must lie within the vertices of the VBO. A VBO with
+// Frames writes all of its vertices to the next region of the ring instead.
+func (v *VBO) UpdateVertices(first int, verts []float32) error {
+	layout := v.options.layout()
+	components := layout.Components()
+	if first < 0 || len(verts) != layout.count(verts)*components || (first*components+len(verts)) > len(v.verts) {
+		return fmt.Errorf("glplus: %d floats from vertex %d are out of the %d vertices of the VBO", len(verts), first, layout.count(v.verts))
+	}
+	copy(v.verts[first*components:], verts)
+	if v.frames() > 1 {
+		v.upload(v.verts, v.indices, v.isShort)
+		return nil
+	}
+
+	_, strides := layout.Offsets()
+	for stream, data := range layout.pack(verts) {
+		v.ctx.BindBuffer(v.ctx.ARRAY_BUFFER, v.vboStreams[stream])
+		v.ctx.BufferSubData(v.ctx.ARRAY_BUFFER, first*strides[stream], data)
+	}
+	v.ctx.BindBuffer(v.ctx.ARRAY_BUFFER, nil)
+	return nil
+}
+
+// NewVBO creates the buffers on the context of prog. It keeps a copy of verts
+// and indices to restore them after the context was lost, the caller may
+// reuse its slices. It fails for an invalid layout, and when the indices need
+// 32 bits the context does not have, see Caps.Uint32Indices.
+func NewVBO(prog *GPProgram, options VBOOptions, verts []float32, indices []uint32) (*VBO, error) {
+	if err := options.layout().validate(); err != nil {
+		return nil, err
+	}
+	vbo := &VBO{
+		ctx:     prog.ctx,
+		options: options,
+		prog:    prog,
+		verts:   append([]float32(nil), verts...),
+	}
 	if indices != nil {
-		vboIndices = Gl.CreateBuffer()
+		vbo.indices = append(make([]uint32, 0, len(indices)), indices...)
+	}
+	if err := vbo.create(); err != nil {
+		return nil, err
+	}
+	vbo.ctx.Register(vbo)
+	return vbo, nil
+}
+
+// Restore re-creates the buffers after the context was lost.
+func (v *VBO) Restore() error {
+	return v.create()
+}
+
+func (v *VBO) create() error {
+	// the restored context may lack the 32-bit indices of the lost one
+	isShort, err := v.checkIndices(v.indices)
+	if err != nil {
+		return err
 	}
+	v.isShort = isShort
 
-	vbo = &VBO{vao: vao,
-		vboVerts:   vboVerts,
-		vboIndices: vboIndices,
-		numElem:    0,
-		options:    options,
+	// create and bind the VAO object, when the context has them
+	v.vao = nil
+	if v.ctx.Caps.VertexArrays {
+		v.vao = v.ctx.CreateVertexArray()
 	}
-	Gl.BindVertexArray(nil)
+	v.bindVertexArray(v.vao)
 
-	vbo.load(prog, verts, indices)
+	// create a VBO per stream to hold the vertex data
+	v.vboStreams = make([]*Buffer, v.options.layout().Streams())
+	for i := range v.vboStreams {
+		v.vboStreams[i] = v.ctx.CreateBuffer()
+	}
+	if v.indices != nil {
+		v.vboIndices = v.ctx.CreateBuffer()
+	}
+	v.bindVertexArray(nil)
 
-	return vbo
+	v.load()
+	return nil
 }
 
 // NewVBOQuad ...
@@ -214,8 +463,7 @@ func NewVBOQuad(prog *GPProgram, x float32, y float32, w float32, h float32) (vb
 		2, 3, 0,
 	}
 
-	vbo = NewVBO(prog, DefaultVBOOptions(), verts[:], indices[:])
-	return vbo
+	return mustVBO(NewVBO(prog, DefaultVBOOptions(), verts[:], indices[:]))
 }
 
 // NewVBOCube ...
@@ -262,8 +510,7 @@ func NewVBOCube(prog *GPProgram, x float32, y float32, z float32, u float32, v f
 		6, 7, 3,
 	}
 
-	vbo = NewVBO(prog, DefaultVBOOptions(), verts[:], indices[:])
-	return vbo
+	return mustVBO(NewVBO(prog, DefaultVBOOptions(), verts[:], indices[:]))
 }
 
 // NewVBOCubeNormal ...
@@ -327,7 +574,14 @@ func NewVBOCubeNormal(prog *GPProgram, x float32, y float32, z float32, u float3
 	opt.IsStrip = true
 	opt.Normals = 3
 	opt.UV = 0
-	vbo = NewVBO(prog, opt, verts[:], indices[:])
+	return mustVBO(NewVBO(prog, opt, verts[:], indices[:]))
+}
 
+// mustVBO panics on the error of NewVBO, for the VBOs whose few indices
+// always fit 16 bits.
+func mustVBO(vbo *VBO, err error) *VBO {
+	if err != nil {
+		panic(err)
+	}
 	return vbo
 }
@@ -5,7 +5,6 @@ import (
 	"os"
 	"testing"
 
-	"github.com/go-gl/glfw3/v3.2/glfw"
 	"github.com/go-gl/mathgl/mgl32"
 )
 
@@ -127,33 +126,208 @@ func TestAll(t *testing.T) {
 	//t.Run("RenderFont", subtestRenderFont) TODO: CRASH
 }
 
-func TestMain(m *testing.M) {
-	var err error
-	if err = glfw.Init(); err != nil {
-		panic(err)
+// updateQuad is a quad of position and normal from x0 to x1, all the
+// height of the viewport.
+func updateQuad(x0, x1 float32) []float32 {
+	return []float32{
+		x0, -1, 0, 0, 0, 1,
+		x1, -1, 0, 0, 0, 1,
+		x1, 1, 0, 0, 0, 1,
+		x0, 1, 0, 0, 0, 1,
 	}
+}
 
-	defer glfw.Terminate()
+func TestVBOUpdate(t *testing.T) {
+	v, restore := validatorContext(t)
+	defer restore()
 
-	glfw.WindowHint(glfw.ContextVersionMajor, 4)
-	glfw.WindowHint(glfw.ContextVersionMinor, 1)
-	glfw.WindowHint(glfw.OpenGLForwardCompatible, glfw.True)
-	glfw.WindowHint(glfw.OpenGLProfile, glfw.OpenGLCoreProfile)
-	glfw.WindowHint(glfw.Visible, glfw.False)
+	prog, err := LoadShaderProgram(sVertShaderCoordMarker+"\n// updated", sFragShaderCoordMarker, []string{"position", "normal"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer prog.DeleteProgram()
+	quadIndices := []uint32{0, 1, 2, 2, 3, 0}
+
+	// draw returns whether the left and right halves are drawn
+	draw := func(vbo *VBO) (left, right bool) {
+		t.Helper()
+		Gl.ClearColor(0, 0, 1, 1)
+		Gl.Clear(Gl.COLOR_BUFFER_BIT)
+		prog.UseProgram()
+		for _, name := range []string{"projection", "camera", "model"} {
+			prog.ProgramUniformMatrix4fv(name, mgl32.Ident4())
+		}
+		prog.ProgramUniform3fv("light", [3]float32{0, 0, 1})
+		prog.ProgramUniform4fv("color1", [4]float32{0, 1, 0, 1})
+		vbo.Bind(prog)
+		vbo.Draw()
+		vbo.Unbind(prog)
+		prog.UnuseProgram()
+		if errs := v.Errors(); len(errs) != 0 {
+			t.Fatal(errs)
+		}
+		pixels := make([]uint8, 16*16*4)
+		Gl.ReadPixels(0, 0, 16, 16, Gl.RGBA, Gl.UNSIGNED_BYTE, pixels)
+		green := func(x int) bool { return pixels[(8*16+x)*4+1] == 255 }
+		return green(4), green(12)
+	}
 
-	glfw.WindowHint(glfw.Samples, 4)
+	opt := VBOOptions{Vertex: 3, Normals: 3, Usage: Gl.STREAM_DRAW, Frames: 3}
+	ring, err := NewVBO(prog, opt, updateQuad(-1, 0), quadIndices)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ring.DeleteVBO()
+	if left, right := draw(ring); !left || right {
+		t.Errorf("load: got %v %v", left, right)
+	}
+	if err := ring.Update(updateQuad(0, 1), nil); err != nil {
+		t.Fatal(err)
+	}
+	if left, right := draw(ring); left || !right {
+		t.Errorf("update: got %v %v", left, right)
+	}
+	// two quads grow the buffers
+	if err := ring.Update(append(updateQuad(-1, 0), updateQuad(0, 1)...), append(quadIndices, 4, 5, 6, 6, 7, 4)); err != nil {
+		t.Fatal(err)
+	}
+	if left, right := draw(ring); !left || !right {
+		t.Errorf("grow: got %v %v", left, right)
+	}
+	// around the ring, the quads drawn taking turns in the indices
+	empty := make([]float32, 24)
+	for frame := 0; frame < 4; frame++ {
+		verts, indices := append(updateQuad(-1, 0), empty...), quadIndices
+		if frame%2 == 1 {
+			verts, indices = append(empty, updateQuad(0, 1)...), []uint32{4, 5, 6, 6, 7, 4}
+		}
+		if err := ring.Update(verts, indices); err != nil {
+			t.Fatal(err)
+		}
+		if left, right := draw(ring); left != (frame%2 == 0) || right != (frame%2 == 1) {
+			t.Errorf("frame %d: got %v %v", frame, left, right)
+		}
+	}
 
-	// do the actual window creation
-	var window *glfw.Window
-	window, err = glfw.CreateWindow(1024, 768, "test", nil, nil)
+	// the quads drawn follow the data, the options stay as given
+	opt.Quads = 1
+	quads, err := NewVBO(prog, opt, updateQuad(-1, 0), quadIndices)
 	if err != nil {
-		panic(err)
+		t.Fatal(err)
+	}
+	defer quads.DeleteVBO()
+	if err := quads.Update(append(updateQuad(-1, 0), updateQuad(0, 1)...), append(quadIndices, 4, 5, 6, 6, 7, 4)); err != nil {
+		t.Fatal(err)
+	}
+	if left, right := draw(quads); !left || !right {
+		t.Errorf("quads: got %v %v", left, right)
+	}
+	if quads.options.Quads != 1 {
+		t.Errorf("options changed to %d quads", quads.options.Quads)
+	}
+	opt.Quads = 0
+
+	// two quads as triangles, the right one empty
+	triangles := func(x0, x1 float32) []float32 {
+		quad := updateQuad(x0, x1)
+		var verts []float32
+		for _, i := range quadIndices {
+			verts = append(verts, quad[i*6:i*6+6]...)
+		}
+		return verts
+	}
+	opt.Usage, opt.Frames = Gl.DYNAMIC_DRAW, 0
+	vbo, err := NewVBO(prog, opt, append(triangles(-1, 0), make([]float32, 36)...), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer vbo.DeleteVBO()
+	if err := vbo.UpdateVertices(6, triangles(0, 1)); err != nil {
+		t.Fatal(err)
+	}
+	if left, right := draw(vbo); !left || !right {
+		t.Errorf("update vertices: got %v %v", left, right)
+	}
+	if err := vbo.UpdateVertices(0, make([]float32, 36)); err != nil {
+		t.Fatal(err)
+	}
+	if left, right := draw(vbo); left || !right {
+		t.Errorf("update first vertices: got %v %v", left, right)
+	}
+	if err := vbo.UpdateVertices(9, triangles(0, 1)); err == nil || err.Error() != "glplus: 36 floats from vertex 9 are out of the 12 vertices of the VBO" {
+		t.Errorf("got %v", err)
+	}
+
+	// a layout without attributes has no vertex to draw
+	none, err := NewVBO(prog, VBOOptions{}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer none.DeleteVBO()
+	if err := none.Update(nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := none.UpdateVertices(0, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := none.UpdateVertices(0, triangles(0, 1)); err == nil || err.Error() != "glplus: 36 floats from vertex 0 are out of the 0 vertices of the VBO" {
+		t.Errorf("got %v", err)
 	}
+	if left, right := draw(none); left || right {
+		t.Errorf("empty layout: got %v %v", left, right)
+	}
+}
 
-	window.MakeContextCurrent()
+func TestVBOWithoutVertexArrays(t *testing.T) {
+	defer softContext(t)()
+	// as WebGL 1 without OES_vertex_array_object
+	Gl.Caps.VertexArrays = false
 
-	Gl = NewContext()
+	prog, err := LoadShaderProgram(sVertShaderCoordMarker+"\n// without vao", sFragShaderCoordMarker, []string{"position", "normal"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer prog.DeleteProgram()
+
+	// draw returns whether the left and right halves are drawn
+	draw := func(vbo *VBO) (left, right bool) {
+		t.Helper()
+		Gl.ClearColor(0, 0, 1, 1)
+		Gl.Clear(Gl.COLOR_BUFFER_BIT)
+		prog.UseProgram()
+		for _, name := range []string{"projection", "camera", "model"} {
+			prog.ProgramUniformMatrix4fv(name, mgl32.Ident4())
+		}
+		prog.ProgramUniform3fv("light", [3]float32{0, 0, 1})
+		prog.ProgramUniform4fv("color1", [4]float32{0, 1, 0, 1})
+		vbo.Bind(prog)
+		vbo.Draw()
+		vbo.Unbind(prog)
+		prog.UnuseProgram()
+		checkGlError(t)
+		pixels := make([]uint8, 16*16*4)
+		Gl.ReadPixels(0, 0, 16, 16, Gl.RGBA, Gl.UNSIGNED_BYTE, pixels)
+		green := func(x int) bool { return pixels[(8*16+x)*4+1] == 255 }
+		return green(4), green(12)
+	}
 
-	// call flag.Parse() here if TestMain uses flags
-	os.Exit(m.Run())
+	opt := VBOOptions{Vertex: 3, Normals: 3, Usage: Gl.STREAM_DRAW, Frames: 2}
+	vbo, err := NewVBO(prog, opt, updateQuad(-1, 0), []uint32{0, 1, 2, 2, 3, 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer vbo.DeleteVBO()
+	if vbo.vao != nil {
+		t.Fatal("a vertex array without Caps.VertexArrays")
+	}
+	if left, right := draw(vbo); !left || right {
+		t.Errorf("load: got %v %v", left, right)
+	}
+	// the next region of the ring
+	if err := vbo.Update(updateQuad(0, 1), nil); err != nil {
+		t.Fatal(err)
+	}
+	if left, right := draw(vbo); left || !right {
+		t.Errorf("update: got %v %v", left, right)
+	}
 }
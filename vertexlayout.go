@@ -0,0 +1,366 @@
+package glplus
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// VertexType is the type of the components of a vertex attribute in its
+// buffer. The shaders read them as floats whatever it is.
+type VertexType int
+
+// The vertex types. The normalized ones map their range to 0..1, or -1..1
+// when signed: colors in Uint8Norm for example. The other integers are
+// converted to the floats of the numbers they are by VertexAttribPointer,
+// the indices of bones for example; there is no VertexAttribIPointer, so the
+// shaders declare them float or vec, not int or ivec.
+const (
+	Float32 VertexType = iota
+	// Float16 needs OpenGL 3, OpenGL ES 3 or WebGL 2.
+	Float16
+	Int8Norm
+	Uint8Norm
+	Int16Norm
+	Uint16Norm
+	Int8
+	Uint8
+	Int16
+	Uint16
+	Int32
+)
+
+func (t VertexType) String() string {
+	switch t {
+	case Float32:
+		return "Float32"
+	case Float16:
+		return "Float16"
+	case Int8Norm:
+		return "Int8Norm"
+	case Uint8Norm:
+		return "Uint8Norm"
+	case Int16Norm:
+		return "Int16Norm"
+	case Uint16Norm:
+		return "Uint16Norm"
+	case Int8:
+		return "Int8"
+	case Uint8:
+		return "Uint8"
+	case Int16:
+		return "Int16"
+	case Uint16:
+		return "Uint16"
+	case Int32:
+		return "Int32"
+	}
+	return fmt.Sprintf("VertexType(%d)", int(t))
+}
+
+// Size is the bytes of a component.
+func (t VertexType) Size() int {
+	switch t {
+	case Int8Norm, Uint8Norm, Int8, Uint8:
+		return 1
+	case Float16, Int16Norm, Uint16Norm, Int16, Uint16:
+		return 2
+	}
+	return 4
+}
+
+func (t VertexType) normalized() bool {
+	switch t {
+	case Int8Norm, Uint8Norm, Int16Norm, Uint16Norm:
+		return true
+	}
+	return false
+}
+
+// glType is the type of VertexAttribPointer.
+func (t VertexType) glType(c *Context) int {
+	switch t {
+	case Float16:
+		return c.HALF_FLOAT
+	case Int8Norm, Int8:
+		return c.BYTE
+	case Uint8Norm, Uint8:
+		return c.UNSIGNED_BYTE
+	case Int16Norm, Int16:
+		return c.SHORT
+	case Uint16Norm, Uint16:
+		return c.UNSIGNED_SHORT
+	case Int32:
+		return c.INT
+	}
+	return c.FLOAT
+}
+
+// put encodes a component, clamped to the range of the type.
+func (t VertexType) put(b []byte, v float32) {
+	clamp := func(lo, hi float64) float64 {
+		return math.Max(lo, math.Min(hi, math.Round(float64(v))))
+	}
+	switch t {
+	case Float32:
+		binary.LittleEndian.PutUint32(b, math.Float32bits(v))
+	case Float16:
+		binary.LittleEndian.PutUint16(b, float32ToHalf(v))
+	case Int8Norm:
+		v *= math.MaxInt8
+		b[0] = byte(int8(clamp(-math.MaxInt8, math.MaxInt8)))
+	case Uint8Norm:
+		v *= math.MaxUint8
+		b[0] = byte(clamp(0, math.MaxUint8))
+	case Int16Norm:
+		v *= math.MaxInt16
+		binary.LittleEndian.PutUint16(b, uint16(int16(clamp(-math.MaxInt16, math.MaxInt16))))
+	case Uint16Norm:
+		v *= math.MaxUint16
+		binary.LittleEndian.PutUint16(b, uint16(clamp(0, math.MaxUint16)))
+	case Int8:
+		b[0] = byte(int8(clamp(math.MinInt8, math.MaxInt8)))
+	case Uint8:
+		b[0] = byte(clamp(0, math.MaxUint8))
+	case Int16:
+		binary.LittleEndian.PutUint16(b, uint16(int16(clamp(math.MinInt16, math.MaxInt16))))
+	case Uint16:
+		binary.LittleEndian.PutUint16(b, uint16(clamp(0, math.MaxUint16)))
+	case Int32:
+		binary.LittleEndian.PutUint32(b, uint32(int32(clamp(math.MinInt32, math.MaxInt32))))
+	}
+}
+
+// float32ToHalf encodes an IEEE 754 half float, rounding to the nearest
+// even.
+func float32ToHalf(f float32) uint16 {
+	bits := math.Float32bits(f)
+	sign := uint16(bits>>16) & 0x8000
+	exp := int(bits>>23&0xff) - 127 + 15
+	mant := bits & 0x7fffff
+	switch {
+	case bits&0x7fffffff > 0x7f800000:
+		// NaN
+		return sign | 0x7e00
+	case exp >= 0x1f:
+		// too large, infinities
+		return sign | 0x7c00
+	case exp <= 0:
+		// subnormal
+		if exp < -10 {
+			return sign
+		}
+		mant |= 0x800000
+		shift := uint(14 - exp)
+		half := uint16(mant >> shift)
+		rem, mid := mant&(1<<shift-1), uint32(1)<<(shift-1)
+		if rem > mid || (rem == mid && half&1 != 0) {
+			half++
+		}
+		return sign | half
+	}
+	half := uint16(exp)<<10 | uint16(mant>>13)
+	// a carry into the exponent rounds up to the next power of 2, or to
+	// infinity
+	if rem := mant & 0x1fff; rem > 0x1000 || (rem == 0x1000 && half&1 != 0) {
+		half++
+	}
+	return sign | half
+}
+
+// VertexAttrib is an attribute of a VertexLayout.
+type VertexAttrib struct {
+	// Name is the attribute of the program.
+	Name string
+	Type VertexType
+	// Count is the number of components, 1 to 4, or 9 and 16 for a mat3 and
+	// a mat4, whose columns take a location each.
+	Count int
+	// Stream is the buffer of the attribute, from 0. The attributes of a
+	// stream are interleaved, in their order in the layout.
+	Stream int
+	// Divisor is the number of instances that share a value of an
+	// attribute of an InstanceBuffer, 1 when 0.
+	Divisor int
+}
+
+// columns are the locations the attribute takes, of size components.
+func (a *VertexAttrib) columns() (n, size int) {
+	switch a.Count {
+	case 9:
+		return 3, 3
+	case 16:
+		return 4, 4
+	}
+	return 1, a.Count
+}
+
+// pointer sets the VertexAttribPointer of the columns of the attribute at
+// loc, at offset in the bound array buffer.
+func (a *VertexAttrib) pointer(c *Context, loc, stride, offset int) {
+	n, size := a.columns()
+	for col := 0; col < n; col++ {
+		c.VertexAttribPointer(loc+col, size, a.Type.glType(c), a.Type.normalized(), stride, offset+col*size*a.Type.Size())
+	}
+}
+
+// VertexLayout describes the vertices of a VBO:
+//
+//	layout := &VertexLayout{Attribs: []VertexAttrib{
+//		{Name: "position", Count: 3},
+//		{Name: "uvs", Count: 2},
+//		{Name: "color", Type: Uint8Norm, Count: 4},
+//		{Name: "weights", Type: Float16, Count: 4, Stream: 1},
+//	}}
+//
+// The vertices given to NewVBO are the float components of the attributes,
+// in order, 13 per vertex here; they are converted to their types and split
+// into the streams.
+type VertexLayout struct {
+	Attribs []VertexAttrib
+}
+
+// validate checks the counts, the types and the streams of the attributes:
+// the streams from 0 to Streams()-1 have attributes each.
+func (l *VertexLayout) validate() error {
+	used := make([]bool, l.Streams())
+	for _, a := range l.Attribs {
+		switch {
+		case a.Count != 9 && a.Count != 16 && (a.Count < 1 || a.Count > 4):
+			return fmt.Errorf("glplus: attribute %s has %d components, not 1 to 4, 9 or 16", a.Name, a.Count)
+		case a.Type < Float32 || a.Type > Int32:
+			return fmt.Errorf("glplus: attribute %s has an unknown type %v", a.Name, a.Type)
+		case a.Stream < 0:
+			return fmt.Errorf("glplus: attribute %s has a negative stream %d", a.Name, a.Stream)
+		}
+		used[a.Stream] = true
+	}
+	for stream, ok := range used {
+		if !ok {
+			return fmt.Errorf("glplus: stream %d of the layout has no attributes", stream)
+		}
+	}
+	return nil
+}
+
+// Components is the number of floats of a vertex in the data of NewVBO.
+func (l *VertexLayout) Components() int {
+	n := 0
+	for _, a := range l.Attribs {
+		n += a.Count
+	}
+	return n
+}
+
+// Streams is the number of buffers.
+func (l *VertexLayout) Streams() int {
+	n := 0
+	for _, a := range l.Attribs {
+		if a.Stream >= n {
+			n = a.Stream + 1
+		}
+	}
+	return n
+}
+
+// Offsets returns the byte offset of every attribute in its stream, and the
+// stride of every stream. The attributes are aligned to their component
+// size and the vertices to 4 bytes, as WebGL wants.
+func (l *VertexLayout) Offsets() (offsets, strides []int) {
+	offsets = make([]int, len(l.Attribs))
+	strides = make([]int, l.Streams())
+	for i, a := range l.Attribs {
+		size := a.Type.Size()
+		offset := (strides[a.Stream] + size - 1) / size * size
+		offsets[i] = offset
+		strides[a.Stream] = offset + a.Count*size
+	}
+	for i := range strides {
+		strides[i] = (strides[i] + 3) / 4 * 4
+	}
+	return offsets, strides
+}
+
+// floatStream tells whether a stream holds floats only, which are buffered
+// as they are.
+func (l *VertexLayout) floatStream(stream int) bool {
+	for _, a := range l.Attribs {
+		if a.Stream == stream && a.Type != Float32 {
+			return false
+		}
+	}
+	return true
+}
+
+// count is the number of vertices of verts, 0 for a layout without
+// attributes.
+func (l *VertexLayout) count(verts []float32) int {
+	if components := l.Components(); components > 0 {
+		return len(verts) / components
+	}
+	return 0
+}
+
+// pack converts the float components of vertices to the data of every
+// stream, a []float32 or a []uint8.
+func (l *VertexLayout) pack(verts []float32) []interface{} {
+	offsets, strides := l.Offsets()
+	components := l.Components()
+	count := l.count(verts)
+	if l.Streams() == 1 && l.floatStream(0) && strides[0] == components*4 {
+		return []interface{}{verts}
+	}
+
+	data := make([][]byte, len(strides))
+	for i, stride := range strides {
+		data[i] = make([]byte, count*stride)
+	}
+	for v := 0; v < count; v++ {
+		src := verts[v*components:]
+		for i, a := range l.Attribs {
+			size := a.Type.Size()
+			dst := data[a.Stream][v*strides[a.Stream]+offsets[i]:]
+			for k := 0; k < a.Count; k++ {
+				a.Type.put(dst[k*size:], src[k])
+			}
+			src = src[a.Count:]
+		}
+	}
+	res := make([]interface{}, len(data))
+	for i, b := range data {
+		if l.floatStream(i) {
+			res[i] = bytesToFloats(b)
+		} else {
+			res[i] = b
+		}
+	}
+	return res
+}
+
+func bytesToFloats(b []byte) []float32 {
+	res := make([]float32, len(b)/4)
+	for i := range res {
+		res[i] = math.Float32frombits(binary.LittleEndian.Uint32(b[i*4:]))
+	}
+	return res
+}
+
+// layout is the VertexLayout of the options, the one of Vertex, UV and
+// Normals floats bound to "position", "uvs" and "normal" when they have
+// none.
+func (o *VBOOptions) layout() *VertexLayout {
+	if o.Layout != nil {
+		return o.Layout
+	}
+	l := &VertexLayout{}
+	if o.Vertex != 0 {
+		l.Attribs = append(l.Attribs, VertexAttrib{Name: "position", Count: o.Vertex})
+	}
+	if o.UV != 0 {
+		l.Attribs = append(l.Attribs, VertexAttrib{Name: "uvs", Count: o.UV})
+	}
+	if o.Normals != 0 {
+		l.Attribs = append(l.Attribs, VertexAttrib{Name: "normal", Count: o.Normals})
+	}
+	return l
+}
@@ -0,0 +1,142 @@
+package glplus
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestVertexLayoutOffsets(t *testing.T) {
+	layout := &VertexLayout{Attribs: []VertexAttrib{
+		{Name: "position", Count: 3},
+		{Name: "color", Type: Uint8Norm, Count: 3},
+		{Name: "uv2", Type: Float16, Count: 2},
+		{Name: "bones", Type: Uint8, Count: 4, Stream: 1},
+		{Name: "weights", Type: Int16Norm, Count: 3, Stream: 1},
+	}}
+	offsets, strides := layout.Offsets()
+	if want := []int{0, 12, 16, 0, 4}; !reflect.DeepEqual(offsets, want) {
+		t.Errorf("offsets %v", offsets)
+	}
+	if want := []int{20, 12}; !reflect.DeepEqual(strides, want) {
+		t.Errorf("strides %v", strides)
+	}
+	if layout.Components() != 15 || layout.Streams() != 2 {
+		t.Errorf("%d components, %d streams", layout.Components(), layout.Streams())
+	}
+
+	data := layout.pack([]float32{
+		1, 2, 3, 1, 0.5, -1, 0.25, 65536, 3, 300, -2, 1, -1, 0.5,
+		0,
+	})
+	// a vertex is not split
+	if len(data) != 2 || len(data[0].([]uint8)) != 20 || len(data[1].([]uint8)) != 12 {
+		t.Fatalf("data %v", data)
+	}
+	first := data[0].([]uint8)
+	if got := first[12:20]; !reflect.DeepEqual(got, []uint8{255, 128, 0, 0, 0x00, 0x34, 0x00, 0x7c}) {
+		t.Errorf("color and uv2 %v", got)
+	}
+	if got := data[1].([]uint8); !reflect.DeepEqual(got, []uint8{3, 255, 0, 1, 0x01, 0x80, 0x00, 0x40, 0, 0, 0, 0}) {
+		t.Errorf("bones and weights %v", got)
+	}
+}
+
+func TestFloat32ToHalf(t *testing.T) {
+	for _, test := range []struct {
+		f    float32
+		half uint16
+	}{
+		{0, 0},
+		{1, 0x3c00},
+		{-2, 0xc000},
+		{0.5, 0x3800},
+		{65504, 0x7bff},
+		{65520, 0x7c00},
+		{float32(math.Inf(-1)), 0xfc00},
+		{5.960464477539063e-08, 0x0001},
+		{6.097555160522461e-05, 0x03ff},
+		// halfway, to the even
+		{1 + 1.0/2048, 0x3c00},
+		{1 + 3.0/2048, 0x3c02},
+	} {
+		if half := float32ToHalf(test.f); half != test.half {
+			t.Errorf("%g: %#04x, want %#04x", test.f, half, test.half)
+		}
+		if f := halfToFloat32(test.half); f != test.f && test.f != 65520 && test.f < 1.0001 {
+			t.Errorf("%#04x: %g", test.half, f)
+		}
+	}
+	if half := float32ToHalf(float32(math.NaN())); halfToFloat32(half) == halfToFloat32(half) {
+		t.Errorf("NaN %#04x", half)
+	}
+}
+
+func TestVBOLayout(t *testing.T) {
+	fake := &fakeBackend{attribs: map[string]int{"position": 1, "color": 2, "weights": 4}}
+	c := NewBackendContext(fake)
+	prog, err := c.LoadShaderProgram("// layout vertex shader", "// layout fragment shader", []string{"position", "color", "weights"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	opt := DefaultVBOOptions()
+	opt.Layout = &VertexLayout{Attribs: []VertexAttrib{
+		{Name: "position", Count: 3},
+		{Name: "color", Type: Uint8Norm, Count: 4},
+		{Name: "weights", Type: Float16, Count: 4, Stream: 1},
+	}}
+	vbo, err := NewVBO(prog, opt, make([]float32, 3*11), []uint32{0, 1, 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	vbo.Bind(prog)
+	vbo.Draw()
+	vbo.Unbind(prog)
+	vbo.DeleteVBO()
+
+	calls := strings.Join(fake.calls, "\n")
+	for _, want := range []string{
+		fmt.Sprintf("BufferData[%d []uint8]\nVertexAttribPointer[1 3 16 0]\nVertexAttribPointer[2 4 16 12]\n", c.ARRAY_BUFFER) +
+			fmt.Sprintf("BindBuffer[%d true]\nBufferData[%d []uint8]\nVertexAttribPointer[4 4 8 0]", c.ARRAY_BUFFER, c.ARRAY_BUFFER),
+		"EnableVertexAttribArray[4]",
+		"DisableVertexAttribArray[2]",
+		"DeleteBuffer[]\nDeleteBuffer[]\nDeleteBuffer[]\nDeleteVertexArray[]",
+	} {
+		if !strings.Contains(calls, want) {
+			t.Errorf("missing %s in\n%s", want, calls)
+		}
+	}
+}
+
+func TestVertexLayoutValidate(t *testing.T) {
+	for _, test := range []struct {
+		attribs []VertexAttrib
+		err     string
+	}{
+		{[]VertexAttrib{{Name: "position", Count: 3}, {Name: "model", Count: 16, Stream: 1}, {Name: "normal", Count: 9, Stream: 1}}, ""},
+		{[]VertexAttrib{{Name: "position"}}, "attribute position has 0 components"},
+		{[]VertexAttrib{{Name: "position", Count: 5}}, "attribute position has 5 components"},
+		{[]VertexAttrib{{Name: "model", Count: 12}}, "attribute model has 12 components"},
+		{[]VertexAttrib{{Name: "color", Type: Int32 + 1, Count: 4}}, "attribute color has an unknown type VertexType(11)"},
+		{[]VertexAttrib{{Name: "uvs", Count: 2, Stream: -1}}, "attribute uvs has a negative stream -1"},
+		{[]VertexAttrib{{Name: "position", Count: 3}, {Name: "weights", Count: 4, Stream: 2}}, "stream 1 of the layout has no attributes"},
+	} {
+		err := (&VertexLayout{Attribs: test.attribs}).validate()
+		if (err == nil) != (test.err == "") || (err != nil && !strings.Contains(err.Error(), test.err)) {
+			t.Errorf("%v: got %v, want %q", test.attribs, err, test.err)
+		}
+	}
+
+	c := NewBackendContext(&fakeBackend{})
+	prog, err := c.LoadShaderProgram("// layout vertex shader", "// layout fragment shader", []string{"position"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	opt := DefaultVBOOptions()
+	opt.Layout = &VertexLayout{Attribs: []VertexAttrib{{Name: "position", Count: 3, Stream: 1}}}
+	if _, err := NewVBO(prog, opt, make([]float32, 9), nil); err == nil || !strings.Contains(err.Error(), "stream 0 of the layout has no attributes") {
+		t.Errorf("got %v", err)
+	}
+}